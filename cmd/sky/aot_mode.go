@@ -10,6 +10,9 @@ import (
 	"github.com/mburakmmm/sky-lang/internal/ast"
 )
 
+// defaultTarget is the backend sky build uses without --target
+const defaultTarget = "llvm"
+
 func compileAOT(program *ast.Program, outputPath string) error {
 	compiler := aot.NewCompiler()
 	compiler.SetOptimization(true)
@@ -8,6 +8,9 @@ import (
 	"github.com/mburakmmm/sky-lang/internal/ast"
 )
 
+// defaultTarget is the backend sky build uses without --target
+const defaultTarget = "go"
+
 func compileAOT(program *ast.Program, outputPath string) error {
 	return fmt.Errorf("AOT compilation not available: build with -tags llvm")
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mburakmmm/sky-lang/internal/bundle"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+	"github.com/mburakmmm/sky-lang/internal/parser"
+	"github.com/mburakmmm/sky-lang/internal/vm"
+)
+
+// runBundled runs the program bundled into this executable, if there is
+// one, and exits; it returns when the executable is a plain sky
+func runBundled() {
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	b, err := bundle.Open(exe)
+	if errors.Is(err, bundle.ErrNoBundle) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.error", err))
+		os.Exit(1)
+	}
+
+	l := lexer.New(string(b.Source), b.Main)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.parse_errors"))
+		for _, err := range p.Errors() {
+			fmt.Fprintf(os.Stderr, "  - %s\n", err)
+		}
+		os.Exit(1)
+	}
+	modules, err := b.FS()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.error", err))
+		os.Exit(1)
+	}
+
+	// Same engine as sky run: the interpreter, promoting hot functions
+	interp := interpreter.New()
+	interp.SetSourceFile(b.Main)
+	interp.SetModuleFS(modules)
+	tier := vm.NewTier(program)
+	tier.SetSourceFile(b.Main)
+	interp.SetTier(tier, nil)
+	if err := interp.Eval(program); err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.runtime_error", diag.Annotate(err)))
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// buildBundle writes an executable running filename with the runtime
+// binary, this sky when runtime is empty
+func buildBundle(filename, runtime, output string) error {
+	b, err := bundle.Collect(filename)
+	if err != nil {
+		return err
+	}
+	if runtime == "" {
+		if runtime, err = os.Executable(); err != nil {
+			return fmt.Errorf(i18n.T("cli.missing_runtime"), err)
+		}
+	}
+	if err := bundle.Build(runtime, b, output); err != nil {
+		return err
+	}
+	if len(b.Modules) > 0 {
+		fmt.Println(i18n.T("cli.bundled", len(b.Modules)))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/vm"
+)
+
+// compileCommand compiles a SKY program to a .skyc bytecode file
+func compileCommand(args []string) {
+	level, args, err := optLevelFlag(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.error", err))
+		os.Exit(1)
+	}
+	pgoFile, args, err := stringFlag(args, "--pgo")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.error", err))
+		os.Exit(1)
+	}
+
+	var filename, output string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "-o":
+			if i+1 >= len(args) {
+				fmt.Fprintln(os.Stderr, i18n.T("cli.output_flag"))
+				os.Exit(1)
+			}
+			output = args[i+1]
+			i++
+		case filename == "":
+			filename = args[i]
+		default:
+			fmt.Fprintln(os.Stderr, i18n.T("cli.unexpected_argument", args[i]))
+			os.Exit(1)
+		}
+	}
+
+	if filename == "" {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.no_input"))
+		fmt.Fprintln(os.Stderr, "Usage: sky compile [-O0|-O1|-O2] [--pgo=profile.json] <file.sky> [-o file.skyc]")
+		os.Exit(1)
+	}
+	if output == "" {
+		output = strings.TrimSuffix(filename, ".sky") + ".skyc"
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.read_file", err))
+		os.Exit(1)
+	}
+
+	// A profile applies to -O0 code, which is optimized afterwards
+	compileLevel := level
+	if pgoFile != "" {
+		compileLevel = 0
+	}
+	bytecode, err := compileSource(filename, content, compileLevel, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	bytecode.SourceHash = vm.HashSource(content)
+	if pgoFile != "" {
+		if err := applyProfile(bytecode, pgoFile); err != nil {
+			fmt.Fprintln(os.Stderr, i18n.T("cli.error", err))
+			os.Exit(1)
+		}
+		vm.Optimize(bytecode, level)
+	}
+
+	if err := vm.WriteSkyc(output, bytecode); err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.write_named", output, err))
+		os.Exit(1)
+	}
+}
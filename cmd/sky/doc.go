@@ -5,11 +5,12 @@ import (
 	"os"
 
 	"github.com/mburakmmm/sky-lang/internal/docgen"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 )
 
 func docCommand(args []string) {
 	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "Error: no input files specified")
+		fmt.Fprintln(os.Stderr, i18n.T("cli.no_inputs"))
 		fmt.Fprintln(os.Stderr, "Usage: sky doc <files...>")
 		os.Exit(1)
 	}
@@ -19,13 +20,13 @@ func docCommand(args []string) {
 	for _, filename := range args {
 		content, err := os.ReadFile(filename)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", filename, err)
+			fmt.Fprintln(os.Stderr, i18n.T("cli.read_named", filename, err))
 			os.Exit(1)
 		}
 
 		doc, err := docgen.GenerateDoc(filename, string(content))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error generating docs for %s: %v\n", filename, err)
+			fmt.Fprintln(os.Stderr, i18n.T("cli.doc_error", filename, err))
 			os.Exit(1)
 		}
 
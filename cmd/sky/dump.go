@@ -7,14 +7,18 @@ import (
 	"strings"
 
 	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/ir"
 	"github.com/mburakmmm/sky-lang/internal/lexer"
+	"github.com/mburakmmm/sky-lang/internal/optimizer"
 	"github.com/mburakmmm/sky-lang/internal/parser"
+	"github.com/mburakmmm/sky-lang/internal/sema"
 )
 
 func dumpCommand(args []string) {
 	if len(args) < 2 {
 		fmt.Fprintln(os.Stderr, "Usage: sky dump --tokens <file>")
 		fmt.Fprintln(os.Stderr, "       sky dump --ast <file>")
+		fmt.Fprintln(os.Stderr, "       sky dump --ir <file>")
 		os.Exit(1)
 	}
 
@@ -27,7 +31,9 @@ func dumpCommand(args []string) {
 	case "--ast", "-a":
 		dumpAST(filename)
 	case "--bytecode", "-b":
-		dumpBytecodeCommand(filename)
+		dumpBytecodeCommand(args[1:])
+	case "--ir":
+		dumpIRCommand(args[1:])
 	case "--json":
 		if len(args) < 3 {
 			fmt.Fprintln(os.Stderr, "Usage: sky dump --json <--tokens|--ast> <file>")
@@ -49,6 +55,7 @@ func dumpCommand(args []string) {
 		fmt.Fprintln(os.Stderr, "Usage: sky dump --tokens <file>")
 		fmt.Fprintln(os.Stderr, "       sky dump --ast <file>")
 		fmt.Fprintln(os.Stderr, "       sky dump --bytecode <file>")
+		fmt.Fprintln(os.Stderr, "       sky dump --ir <file>")
 		os.Exit(1)
 	}
 }
@@ -188,13 +195,73 @@ func dumpAST(filename string) {
 	fmt.Println("\n=== End of AST ===")
 }
 
-func dumpBytecodeCommand(filename string) {
-	if err := dumpBytecode(filename); err != nil {
+func dumpBytecodeCommand(args []string) {
+	level, args, err := optLevelFlag(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: sky dump --bytecode [-O0|-O1|-O2] <file>")
+		os.Exit(1)
+	}
+	if err := dumpBytecode(args[0], level); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func dumpIRCommand(args []string) {
+	level, args, err := optLevelFlag(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: sky dump --ir [-O0|-O1] <file>")
+		os.Exit(1)
+	}
+	if err := dumpIR(args[0], level); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// dumpIR shows the SSA IR of a file, optimized above level 0
+func dumpIR(filename string, level int) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	l := lexer.New(string(content), filename)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return fmt.Errorf("parse errors:\n%v", strings.Join(p.Errors(), "\n"))
+	}
+
+	// The checker types the values; type errors do not stop the dump
+	checker := sema.NewChecker()
+	checker.SetSourceFile(filename)
+	checker.Check(program)
+
+	module, err := ir.Lower(program, filename, checker.Types())
+	if err != nil {
+		return err
+	}
+	passes := optimizer.NewIRPasses(level)
+	passes.Verify = true
+	if err := passes.Run(module); err != nil {
+		return err
+	}
+	if err := module.Verify(); err != nil {
+		return err
+	}
+	module.Fprint(os.Stdout)
+	return nil
+}
+
 func printAST(node ast.Node, indent int) {
 	if node == nil {
 		return
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+)
+
+// explainCommand prints the long-form explanation of a diagnostic code,
+// or the list of codes when none is given, in the language of the locale
+func explainCommand(args []string) {
+	lang := string(i18n.Current())
+	if len(args) == 0 {
+		for _, entry := range diag.Entries() {
+			title, _ := entry.Text(lang)
+			fmt.Printf("%s  %-8s %s\n", entry.Code, i18n.T("cli.severity_"+entry.Severity()), title)
+		}
+		fmt.Printf("\n%s\n", i18n.T("cli.explain_usage"))
+		return
+	}
+
+	for i, code := range args {
+		entry, ok := diag.Lookup(code)
+		if !ok {
+			fmt.Fprintln(os.Stderr, i18n.T("cli.unknown_code", code))
+			fmt.Fprintln(os.Stderr, i18n.T("cli.list_codes"))
+			os.Exit(1)
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		title, explanation := entry.Text(lang)
+		fmt.Printf("%s: %s (%s)\n", entry.Code, title, i18n.T("cli.severity_"+entry.Severity()))
+		fmt.Print(explanation)
+		if !strings.HasSuffix(explanation, "\n") {
+			fmt.Println()
+		}
+		fmt.Printf("\n%s\n", i18n.T("cli.suppress_hint", entry.Code))
+	}
+}
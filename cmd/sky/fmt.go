@@ -6,11 +6,12 @@ import (
 	"path/filepath"
 
 	"github.com/mburakmmm/sky-lang/internal/formatter"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 )
 
 func fmtCommand(args []string) {
 	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "Error: no input files specified")
+		fmt.Fprintln(os.Stderr, i18n.T("cli.no_inputs"))
 		fmt.Fprintln(os.Stderr, "Usage: sky fmt <files...>")
 		os.Exit(1)
 	}
@@ -27,7 +28,7 @@ func fmtCommand(args []string) {
 	}
 
 	if len(files) == 0 {
-		fmt.Fprintln(os.Stderr, "Error: no input files specified")
+		fmt.Fprintln(os.Stderr, i18n.T("cli.no_inputs"))
 		os.Exit(1)
 	}
 
@@ -37,33 +38,33 @@ func fmtCommand(args []string) {
 		// Read file
 		content, err := os.ReadFile(filename)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", filename, err)
+			fmt.Fprintln(os.Stderr, i18n.T("cli.read_named", filename, err))
 			os.Exit(1)
 		}
 
 		// Format
 		formatted, err := formatter.FormatFile(filename, string(content))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error formatting %s: %v\n", filename, err)
+			fmt.Fprintln(os.Stderr, i18n.T("cli.format_error", filename, err))
 			os.Exit(1)
 		}
 
 		if check {
 			// Check mode: compare without writing
 			if string(content) != formatted {
-				fmt.Fprintf(os.Stderr, "%s: not formatted\n", filename)
+				fmt.Fprintln(os.Stderr, i18n.T("cli.not_formatted", filename))
 				hasChanges = true
 			}
 		} else {
 			// Write mode: save formatted output
 			if string(content) != formatted {
 				if err := os.WriteFile(filename, []byte(formatted), 0644); err != nil {
-					fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", filename, err)
+					fmt.Fprintln(os.Stderr, i18n.T("cli.write_named", filename, err))
 					os.Exit(1)
 				}
-				fmt.Printf("Formatted: %s\n", filename)
+				fmt.Println(i18n.T("cli.formatted", filename))
 			} else {
-				fmt.Printf("Already formatted: %s\n", filename)
+				fmt.Println(i18n.T("cli.already_formatted", filename))
 			}
 		}
 	}
@@ -88,16 +89,16 @@ func fmtAllCommand() {
 	})
 
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error finding files: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.T("cli.find_files", err))
 		os.Exit(1)
 	}
 
 	if len(skyFiles) == 0 {
-		fmt.Println("No .sky files found")
+		fmt.Println(i18n.T("cli.no_sky_files"))
 		return
 	}
 
-	fmt.Printf("Formatting %d files...\n", len(skyFiles))
+	fmt.Println(i18n.T("cli.formatting", len(skyFiles)))
 	fmtCommand(skyFiles)
 }
 
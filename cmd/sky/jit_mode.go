@@ -30,6 +30,7 @@ func runWithJIT(filename string) error {
 
 	// Semantic check
 	checker := sema.NewChecker()
+	checker.SetSourceFile(filename)
 	errors := checker.Check(program)
 	if len(errors) > 0 {
 		return fmt.Errorf("semantic errors: %v", errors)
@@ -3,39 +3,58 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 	"github.com/mburakmmm/sky-lang/internal/linter"
 )
 
 func lintCommand(args []string) {
+	if len(args) == 1 && args[0] == "--rules" {
+		listLintRules()
+		return
+	}
 	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "Error: no input files specified")
+		fmt.Fprintln(os.Stderr, i18n.T("cli.no_inputs"))
 		fmt.Fprintln(os.Stderr, "Usage: sky lint <files...>")
+		fmt.Fprintln(os.Stderr, "       sky lint --rules")
 		os.Exit(1)
 	}
 
 	hasErrors := false
+	configs := make(map[string]*linter.Config) // by directory
 
 	for _, filename := range args {
 		content, err := os.ReadFile(filename)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", filename, err)
+			fmt.Fprintln(os.Stderr, i18n.T("cli.read_named", filename, err))
 			os.Exit(1)
 		}
 
-		issues, err := linter.LintFile(filename, string(content))
+		dir := filepath.Dir(filename)
+		config, ok := configs[dir]
+		if !ok {
+			if config, err = linter.FindConfig(dir); err != nil {
+				fmt.Fprintln(os.Stderr, i18n.T("cli.error", err))
+				os.Exit(1)
+			}
+			configs[dir] = config
+		}
+
+		issues, err := linter.LintFile(filename, string(content), config)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error linting %s: %v\n", filename, err)
+			fmt.Fprintln(os.Stderr, i18n.T("cli.lint_error", filename, err))
 			os.Exit(1)
 		}
 
 		if len(issues) > 0 {
 			for _, issue := range issues {
-				fmt.Printf("%s:%d:%d: %s [%s] %s\n",
+				fmt.Printf("%s:%d:%d: %s [%s] %s [%s]\n",
 					issue.File, issue.Line, issue.Column,
-					issue.Severity, issue.Rule, issue.Message)
+					issue.Severity, issue.Rule, issue.Message, issue.Code)
 
-				if issue.Severity == "error" {
+				if issue.Severity == linter.SeverityError {
 					hasErrors = true
 				}
 			}
@@ -48,3 +67,42 @@ func lintCommand(args []string) {
 		os.Exit(1)
 	}
 }
+
+// listLintRules prints the registered rules, including those of plugins
+// named in the config of the current directory
+func listLintRules() {
+	config, err := linter.FindConfig(".")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.error", err))
+		os.Exit(1)
+	}
+
+	for _, rule := range linter.Rules() {
+		severity := rule.Severity
+		if config != nil && config.Rules[rule.ID].Severity != "" {
+			severity = config.Rules[rule.ID].Severity
+		}
+		fmt.Printf("%-24s %-8s %-6s %s\n", rule.ID, severity, rule.Code, rule.Description)
+
+		names := make([]string, 0, len(rule.Options))
+		for name := range rule.Options {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value := rule.Options[name]
+			if config != nil {
+				if v, ok := config.Rules[rule.ID].Options[name]; ok {
+					value = v
+				}
+			}
+			fmt.Printf("%-24s   %s = %#v\n", "", name, value)
+		}
+	}
+
+	if config != nil {
+		fmt.Println("\n" + i18n.T("cli.lint_config", config.Path))
+	} else {
+		fmt.Println("\n" + i18n.T("cli.lint_no_config", linter.ConfigFile, linter.ManifestFile))
+	}
+}
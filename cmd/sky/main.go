@@ -7,16 +7,23 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/cgen"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/gogen"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 	"github.com/mburakmmm/sky-lang/internal/interpreter"
 	"github.com/mburakmmm/sky-lang/internal/lexer"
 	"github.com/mburakmmm/sky-lang/internal/parser"
 	"github.com/mburakmmm/sky-lang/internal/sema"
+	"github.com/mburakmmm/sky-lang/internal/vm"
 )
 
 const version = "0.1.0"
 
 func main() {
+	// An executable built with sky build --bundle runs its program
+	runBundled()
+
 	if len(os.Args) < 2 {
 		printUsage()
 		os.Exit(1)
@@ -29,6 +36,8 @@ func main() {
 		runCommand(os.Args[2:])
 	case "build":
 		buildCommand(os.Args[2:])
+	case "compile":
+		compileCommand(os.Args[2:])
 	case "test":
 		testCommand(os.Args[2:])
 	case "repl":
@@ -43,12 +52,14 @@ func main() {
 		lintCommand(os.Args[2:])
 	case "doc":
 		docCommand(os.Args[2:])
+	case "explain":
+		explainCommand(os.Args[2:])
 	case "version", "--version", "-v":
 		fmt.Printf("SKY version %s\n", version)
 	case "help", "--help", "-h":
 		printHelp()
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
+		fmt.Fprintln(os.Stderr, i18n.T("cli.unknown_command", command))
 		printUsage()
 		os.Exit(1)
 	}
@@ -60,10 +71,13 @@ func printUsage() {
 Commands:
   run <file>        Run a SKY program (JIT)
   build <file>      Build a SKY program (AOT)
+  compile <file>    Compile to bytecode (.skyc)
   test              Run tests
   repl              Start interactive REPL
   dump <options>    Dump lexer/parser output
   check <file>      Check semantics without running
+  lint <files...>   Lint SKY programs
+  explain <code>    Explain a diagnostic code
   version           Show version
   help              Show this help
 
@@ -82,12 +96,56 @@ USAGE:
 
 COMMANDS:
   run <file>              Run a SKY program using JIT compilation
+  run --trace-tiers <file>  Show hot functions moving from the interpreter
+                          to the bytecode VM
+  run --runtime-typecheck <file>
+                          Check arguments, return values and let bindings
+                          against their type annotations while running and
+                          raise a TypeError on mismatch (also with --vm)
   build <file>            Compile to native binary (AOT)
+  build --target=go|c|llvm <file>
+                          Pick the backend: go transpiles to Go and needs
+                          only the go toolchain, c emits C99 built with
+                          the system cc, llvm needs -tags llvm
+                          (default: llvm when built with it, else go)
+  build --bundle <file> [-o out]
+                          Build a self-contained executable: the sky
+                          runtime with the program and every module it
+                          imports (wing dependencies and std included);
+                          --runtime=<sky> picks another prebuilt runtime,
+                          e.g. one built for another OS
+  build --target=c --lib <file> [-o libname.a]
+                          Build a C static library and a name.h header
+                          exporting the public top-level functions
+  compile <file> [-o out] Compile to a .skyc bytecode file
+  run --pgo-record=<profile> <file>
+                          Run on the VM and record a profile (JSON)
+  compile --pgo=<profile> <file>
+                          Inline, specialise and lay out code using a
+                          recorded profile
+  dump --bytecode <file>  Show VM bytecode (-O0, -O1 or -O2 picks the
+                          optimization level, default -O1; also for
+                          run --vm and compile)
+  dump --ir <file>        Show the SSA IR (-O0 as lowered, -O1 after
+                          constant propagation and dead code removal)
   test [path]             Run test files
   repl                    Start interactive REPL
   dump --tokens <file>    Show lexer tokens
   dump --ast <file>       Show AST structure
   check <file>            Type check without execution
+  check --strict-null <file>  Also reject possibly-nil values where nil is not allowed
+  check --show-types <file>   Also list function signatures with inferred return types
+  lint <files...>         Report lint issues; rules are set up in
+                          .skylint.toml or the [lint] table of
+                          sky.project.toml, found from the file's
+                          directory upwards, and
+                          "# sky-lint: disable=rule" turns rules off
+                          for a line
+  lint --rules            List the rules with their codes, severities
+                          and options (plugin rules included)
+  explain [code]          Explain a diagnostic code such as E0102, or list
+                          them all; "# sky-ignore: E0102" on a line (or
+                          alone on the line above) suppresses that code
   version                 Show version information
   help                    Show this help message
 
@@ -96,7 +154,10 @@ EXAMPLES:
   sky dump --tokens hello.sky       # Show tokens
   sky dump --ast hello.sky          # Show AST
   sky check myprogram.sky           # Type check
+  sky explain E0102                 # Explain an error code
   sky build -o myapp main.sky       # Build binary
+  sky compile main.sky -o main.skyc # Compile to bytecode
+  sky run main.skyc                 # Run compiled bytecode
 
 For more information, visit: https://github.com/mburakmmm/sky-lang
 `)
@@ -104,11 +165,35 @@ For more information, visit: https://github.com/mburakmmm/sky-lang
 
 func runCommand(args []string) {
 	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "Error: no input file specified")
+		fmt.Fprintln(os.Stderr, i18n.T("cli.no_input"))
 		fmt.Fprintln(os.Stderr, "Usage: sky run [--vm|--jit] <file>")
 		os.Exit(1)
 	}
 
+	// Tier transitions of hot functions are printed to stderr
+	traceTiers, args := boolFlag(args, "--trace-tiers")
+
+	// Values are checked against parameter, return and let annotations
+	typeChecks, args := boolFlag(args, "--runtime-typecheck")
+
+	// A profile for sky compile --pgo is recorded on the VM
+	pgoRecord, args, err := stringFlag(args, "--pgo-record")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.error", err))
+		os.Exit(1)
+	}
+
+	// Optimization level of bytecode compiled for the VM
+	level, args, err := optLevelFlag(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.error", err))
+		os.Exit(1)
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.no_input"))
+		os.Exit(1)
+	}
+
 	// Check for mode flags
 	useVMMode := false
 	useJITMode := false
@@ -116,7 +201,7 @@ func runCommand(args []string) {
 
 	if args[0] == "--vm" {
 		if len(args) < 2 {
-			fmt.Fprintln(os.Stderr, "Error: no input file specified")
+			fmt.Fprintln(os.Stderr, i18n.T("cli.no_input"))
 			fmt.Fprintln(os.Stderr, "Usage: sky run --vm <file>")
 			os.Exit(1)
 		}
@@ -124,7 +209,7 @@ func runCommand(args []string) {
 		filename = args[1]
 	} else if args[0] == "--jit" {
 		if len(args) < 2 {
-			fmt.Fprintln(os.Stderr, "Error: no input file specified")
+			fmt.Fprintln(os.Stderr, i18n.T("cli.no_input"))
 			fmt.Fprintln(os.Stderr, "Usage: sky run --jit <file>")
 			os.Exit(1)
 		}
@@ -132,6 +217,24 @@ func runCommand(args []string) {
 		filename = args[1]
 	}
 
+	// Compiled bytecode always runs on the VM
+	if strings.HasSuffix(filename, ".skyc") {
+		useVMMode = true
+	}
+
+	if typeChecks && (useJITMode || pgoRecord != "" || strings.HasSuffix(filename, ".skyc")) {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.runtime_typecheck"))
+		os.Exit(1)
+	}
+
+	if pgoRecord != "" {
+		if err := recordProfile(filename, pgoRecord); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Use JIT mode if requested (LLVM backend)
 	if useJITMode {
 		if err := runWithJIT(filename); err != nil {
@@ -143,8 +246,8 @@ func runCommand(args []string) {
 
 	// Use VM mode if requested (better recursion support)
 	if useVMMode {
-		if err := runWithVM(filename); err != nil {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
+		if err := runWithVM(filename, level, typeChecks); err != nil {
+			fmt.Fprintln(os.Stderr, diag.Annotate(err))
 			os.Exit(1)
 		}
 		return
@@ -154,7 +257,7 @@ func runCommand(args []string) {
 	filename = args[0]
 	content, err := os.ReadFile(filename)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.T("cli.read_file", err))
 		os.Exit(1)
 	}
 
@@ -165,54 +268,145 @@ func runCommand(args []string) {
 
 	// Parser hataları
 	if len(p.Errors()) > 0 {
-		fmt.Fprintln(os.Stderr, "Parse errors:")
+		fmt.Fprintln(os.Stderr, i18n.T("cli.parse_errors"))
 		for _, err := range p.Errors() {
 			fmt.Fprintf(os.Stderr, "  - %s\n", err)
 		}
 		os.Exit(1)
 	}
 
-	// Semantic checker (skip if imports present, as they're resolved at runtime)
-	hasImports := false
-	for _, stmt := range program.Statements {
-		if _, ok := stmt.(*ast.ImportStatement); ok {
-			hasImports = true
-			break
-		}
-	}
-
-	if !hasImports {
-		checker := sema.NewChecker()
-		errors := checker.Check(program)
+	// Semantic checker, following imports like the interpreter does
+	checker := sema.NewChecker()
+	checker.SetSourceFile(filename)
+	checker.SetSource(string(content))
+	errors := checker.Check(program)
 
-		if len(errors) > 0 {
-			fmt.Fprintln(os.Stderr, "Semantic errors:")
-			for _, err := range errors {
-				fmt.Fprintf(os.Stderr, "  - %s\n", err)
-			}
-			os.Exit(1)
+	if len(errors) > 0 {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.semantic_errors"))
+		for _, err := range errors {
+			fmt.Fprintf(os.Stderr, "  - %s\n", diag.Annotate(err))
 		}
+		os.Exit(1)
 	}
 
-	// Interpreter
+	// Interpreter; hot functions are promoted to the bytecode VM
 	interp := interpreter.New()
 	interp.SetSourceFile(filename)
+	interp.SetTypeChecks(typeChecks)
+	tier := vm.NewTier(program)
+	tier.SetSourceFile(filename)
+	tier.SetTypeChecks(typeChecks)
+	if traceTiers {
+		interp.SetTier(tier, os.Stderr)
+	} else {
+		interp.SetTier(tier, nil)
+	}
 	err = interp.Eval(program)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Runtime error: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.T("cli.runtime_error", diag.Annotate(err)))
 		os.Exit(1)
 	}
 }
 
+// boolFlag removes flag from args and reports whether it was given
+func boolFlag(args []string, flag string) (bool, []string) {
+	found := false
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == flag {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return found, rest
+}
+
+// stringFlag removes flag from args, given as "flag=value" or "flag value",
+// and returns its value, "" when the flag is not given
+func stringFlag(args []string, flag string) (string, []string, error) {
+	value := ""
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case strings.HasPrefix(args[i], flag+"="):
+			value = strings.TrimPrefix(args[i], flag+"=")
+		case args[i] == flag:
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf(i18n.T("cli.requires_value"), flag)
+			}
+			value = args[i+1]
+			i++
+		default:
+			rest = append(rest, args[i])
+			continue
+		}
+		if value == "" {
+			return "", nil, fmt.Errorf(i18n.T("cli.requires_value"), flag)
+		}
+	}
+	return value, rest, nil
+}
+
 func buildCommand(args []string) {
 	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "Error: no input file specified")
-		fmt.Fprintln(os.Stderr, "Usage: sky build [-o output] <file>")
+		fmt.Fprintln(os.Stderr, i18n.T("cli.no_input"))
+		fmt.Fprintln(os.Stderr, "Usage: sky build [--target=go|c|llvm] [--lib] [-o output] <file>")
+		fmt.Fprintln(os.Stderr, "       sky build --bundle [--runtime=sky] [-o output] <file>")
+		os.Exit(1)
+	}
+
+	bundled, args := boolFlag(args, "--bundle")
+	runtime, args, err := stringFlag(args, "--runtime")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.error", err))
+		os.Exit(1)
+	}
+	if runtime != "" && !bundled {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.runtime_needs_bundle"))
+		os.Exit(1)
+	}
+
+	lib, args := boolFlag(args, "--lib")
+	target, args, err := stringFlag(args, "--target")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.error", err))
+		os.Exit(1)
+	}
+	if bundled && (target != "" || lib) {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.bundle_flags"))
+		os.Exit(1)
+	}
+	if target == "" {
+		target = defaultTarget
+	}
+	if target != "go" && target != "c" && target != "llvm" {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.unknown_target", target))
+		os.Exit(1)
+	}
+	// Only the C backend produces libraries
+	if lib && target != "c" {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.lib_needs_c"))
+		os.Exit(1)
+	}
+
+	// Profiles are applied to bytecode; the native backends compile the AST
+	pgoFile, args, err := stringFlag(args, "--pgo")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.error", err))
+		os.Exit(1)
+	}
+	if pgoFile != "" {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.native_profiles"))
+		os.Exit(1)
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.no_input"))
 		os.Exit(1)
 	}
 
 	// Parse flags
-	outputFile := "a.out"
+	outputFile := ""
 	filename := args[0]
 
 	for i := 0; i < len(args); i++ {
@@ -223,13 +417,19 @@ func buildCommand(args []string) {
 			filename = args[i]
 		}
 	}
+	if outputFile == "" {
+		outputFile = "a.out"
+		if lib {
+			outputFile = "lib" + strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)) + ".a"
+		}
+	}
 
-	fmt.Printf("Building %s...\n", filename)
+	fmt.Println(i18n.T("cli.building", filename))
 
 	// Read and parse file
 	content, err := os.ReadFile(filename)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.T("cli.read_file", err))
 		os.Exit(1)
 	}
 
@@ -238,7 +438,7 @@ func buildCommand(args []string) {
 	program := p.ParseProgram()
 
 	if len(p.Errors()) > 0 {
-		fmt.Fprintln(os.Stderr, "Parse errors:")
+		fmt.Fprintln(os.Stderr, i18n.T("cli.parse_errors"))
 		for _, err := range p.Errors() {
 			fmt.Fprintf(os.Stderr, "  - %s\n", err)
 		}
@@ -246,34 +446,38 @@ func buildCommand(args []string) {
 	}
 
 	// Semantic check
-	hasImports := false
-	for _, stmt := range program.Statements {
-		if _, ok := stmt.(*ast.ImportStatement); ok {
-			hasImports = true
-			break
-		}
-	}
-
-	if !hasImports {
-		checker := sema.NewChecker()
-		errors := checker.Check(program)
+	checker := sema.NewChecker()
+	checker.SetSourceFile(filename)
+	checker.SetSource(string(content))
+	errors := checker.Check(program)
 
-		if len(errors) > 0 {
-			fmt.Fprintln(os.Stderr, "Semantic errors:")
-			for _, err := range errors {
-				fmt.Fprintf(os.Stderr, "  - %s\n", err)
-			}
-			os.Exit(1)
+	if len(errors) > 0 {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.semantic_errors"))
+		for _, err := range errors {
+			fmt.Fprintf(os.Stderr, "  - %s\n", diag.Annotate(err))
 		}
+		os.Exit(1)
 	}
 
 	// AOT compile
-	if err := compileAOT(program, outputFile); err != nil {
-		fmt.Fprintf(os.Stderr, "Build error: %v\n", err)
+	switch {
+	case bundled:
+		err = buildBundle(filename, runtime, outputFile)
+	case target == "go":
+		err = gogen.Build(program, filename, outputFile)
+	case target == "c" && lib:
+		err = cgen.BuildLibrary(program, filename, outputFile)
+	case target == "c":
+		err = cgen.Build(program, filename, outputFile)
+	default:
+		err = compileAOT(program, outputFile)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.build_error", err))
 		os.Exit(1)
 	}
 
-	fmt.Printf("Successfully built: %s\n", outputFile)
+	fmt.Println(i18n.T("cli.built", outputFile))
 }
 
 func testCommand(args []string) {
@@ -291,17 +495,17 @@ func testCommand(args []string) {
 		testDir = args[0]
 	}
 
-	fmt.Printf("Running tests in %s/\n\n", testDir)
+	fmt.Printf("%s\n\n", i18n.T("cli.running_tests_in", testDir))
 
 	// Find all .sky test files
 	testFiles, err := filepath.Glob(filepath.Join(testDir, "*.sky"))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error finding test files: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.T("cli.find_test_files", err))
 		os.Exit(1)
 	}
 
 	if len(testFiles) == 0 {
-		fmt.Printf("No test files found in %s/\n", testDir)
+		fmt.Println(i18n.T("cli.no_test_files_in", testDir))
 		return
 	}
 
@@ -312,12 +516,12 @@ func testCommand(args []string) {
 		// Expected output file
 		expectedFile := strings.TrimSuffix(testFile, ".sky") + ".expected"
 
-		fmt.Printf("Testing %s... ", filepath.Base(testFile))
+		fmt.Print(i18n.T("cli.testing", filepath.Base(testFile)))
 
 		// Run test
 		result, err := runTest(testFile)
 		if err != nil {
-			fmt.Printf("❌ FAIL: %v\n", err)
+			fmt.Println(i18n.T("cli.fail", err))
 			failed++
 			continue
 		}
@@ -326,19 +530,19 @@ func testCommand(args []string) {
 		if _, err := os.Stat(expectedFile); err == nil {
 			expected, _ := os.ReadFile(expectedFile)
 			if string(expected) != result {
-				fmt.Printf("❌ FAIL: output mismatch\n")
-				fmt.Printf("  Expected: %s\n", string(expected))
-				fmt.Printf("  Got: %s\n", result)
+				fmt.Println(i18n.T("cli.output_mismatch"))
+				fmt.Println(i18n.T("cli.expected", string(expected)))
+				fmt.Println(i18n.T("cli.got", result))
 				failed++
 				continue
 			}
 		}
 
-		fmt.Println("✅ PASS")
+		fmt.Println(i18n.T("cli.pass"))
 		passed++
 	}
 
-	fmt.Printf("\n%d passed, %d failed\n", passed, failed)
+	fmt.Printf("\n%s\n", i18n.T("cli.test_counts", passed, failed))
 
 	if failed > 0 {
 		os.Exit(1)
@@ -364,15 +568,17 @@ func runTest(filename string) (string, error) {
 	if len(p.Errors()) > 0 {
 		w.Close()
 		os.Stdout = oldStdout
-		return "", fmt.Errorf("parse error: %v", p.Errors())
+		return "", fmt.Errorf(i18n.T("cli.parse_error"), p.Errors())
 	}
 
 	checker := sema.NewChecker()
+	checker.SetSourceFile(filename)
+	checker.SetSource(string(content))
 	errors := checker.Check(program)
 	if len(errors) > 0 {
 		w.Close()
 		os.Stdout = oldStdout
-		return "", fmt.Errorf("semantic error: %v", errors)
+		return "", fmt.Errorf(i18n.T("cli.semantic_error"), errors)
 	}
 
 	interp := interpreter.New()
@@ -392,20 +598,33 @@ func runTest(filename string) (string, error) {
 // replCommand defined in repl.go
 
 func checkCommand(args []string) {
-	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "Error: no input file specified")
-		fmt.Fprintln(os.Stderr, "Usage: sky check <file>")
+	// Parse flags
+	strictNull := false
+	showTypes := false
+	filename := ""
+	for _, arg := range args {
+		if arg == "--strict-null" {
+			strictNull = true
+		} else if arg == "--show-types" {
+			showTypes = true
+		} else if filename == "" {
+			filename = arg
+		}
+	}
+
+	if filename == "" {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.no_input"))
+		fmt.Fprintln(os.Stderr, "Usage: sky check [--strict-null] [--show-types] <file>")
 		os.Exit(1)
 	}
 
-	filename := args[0]
 	content, err := os.ReadFile(filename)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.T("cli.read_file", err))
 		os.Exit(1)
 	}
 
-	fmt.Printf("Checking %s...\n\n", filename)
+	fmt.Printf("%s\n\n", i18n.T("cli.checking", filename))
 
 	// Lexer & Parser
 	l := lexer.New(string(content), filename)
@@ -414,7 +633,7 @@ func checkCommand(args []string) {
 
 	// Parser hataları
 	if len(p.Errors()) > 0 {
-		fmt.Println("Parse errors:")
+		fmt.Println(i18n.T("cli.parse_errors"))
 		for _, err := range p.Errors() {
 			fmt.Printf("  - %s\n", err)
 		}
@@ -423,16 +642,49 @@ func checkCommand(args []string) {
 
 	// Semantic checker
 	checker := sema.NewChecker()
+	checker.SetSourceFile(filename)
+	checker.SetSource(string(content))
+	checker.SetStrictNull(strictNull)
 	errors := checker.Check(program)
 
+	if showTypes {
+		printFunctionTypes(checker.Functions())
+	}
+
+	// Warnings do not fail the check
+	if warnings := checker.Warnings(); len(warnings) > 0 {
+		fmt.Println(i18n.T("cli.warnings"))
+		for _, warning := range warnings {
+			fmt.Printf("  - %s\n", diag.Annotate(warning))
+		}
+		fmt.Println()
+	}
+
 	if len(errors) > 0 {
-		fmt.Println("Semantic errors:")
+		fmt.Println(i18n.T("cli.semantic_errors"))
 		for _, err := range errors {
-			fmt.Printf("  - %s\n", err)
+			fmt.Printf("  - %s\n", diag.Annotate(err))
 		}
-		fmt.Printf("\n❌ Found %d error(s)\n", len(errors))
+		fmt.Printf("\n%s\n", i18n.T("cli.found_errors", len(errors)))
 		os.Exit(1)
 	}
 
-	fmt.Println("✅ No errors found")
+	fmt.Println(i18n.T("cli.no_errors"))
+}
+
+// printFunctionTypes lists function signatures as the checker sees them,
+// marking return types inferred from the body
+func printFunctionTypes(functions []sema.FunctionInfo) {
+	if len(functions) == 0 {
+		return
+	}
+	fmt.Println(i18n.T("cli.types"))
+	for _, f := range functions {
+		note := ""
+		if f.ReturnInferred {
+			note = "  (inferred)"
+		}
+		fmt.Printf("  %d: %s%s\n", f.Node.Token.Line, f.Signature(), note)
+	}
+	fmt.Println()
 }
@@ -6,6 +6,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 	"github.com/mburakmmm/sky-lang/internal/interpreter"
 	"github.com/mburakmmm/sky-lang/internal/lexer"
 	"github.com/mburakmmm/sky-lang/internal/parser"
@@ -17,7 +19,7 @@ const replContinue = "...  "
 
 func replCommand(args []string) {
 	fmt.Printf("SKY REPL v%s\n", version)
-	fmt.Println("Type 'exit' or 'quit' to exit, 'help' for help")
+	fmt.Println(i18n.T("cli.repl_hint"))
 	fmt.Println()
 
 	interp := interpreter.New()
@@ -45,7 +47,7 @@ func replCommand(args []string) {
 		if !inBlock {
 			switch strings.TrimSpace(line) {
 			case "exit", "quit":
-				fmt.Println("Goodbye!")
+				fmt.Println(i18n.T("cli.goodbye"))
 				return
 			case "help":
 				printReplHelp()
@@ -89,7 +91,7 @@ func replCommand(args []string) {
 		if !inBlock {
 			result, err := evalREPL(lineBuffer, interp)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				fmt.Fprintln(os.Stderr, i18n.T("cli.error", err))
 			} else if result != "" {
 				fmt.Println(result)
 			}
@@ -107,7 +109,7 @@ func evalREPL(input string, interp *interpreter.Interpreter) (string, error) {
 	program := p.ParseProgram()
 
 	if len(p.Errors()) > 0 {
-		return "", fmt.Errorf("parse error: %s", strings.Join(p.Errors(), ", "))
+		return "", fmt.Errorf(i18n.T("cli.parse_error"), strings.Join(p.Errors(), ", "))
 	}
 
 	// Semantic check
@@ -116,9 +118,9 @@ func evalREPL(input string, interp *interpreter.Interpreter) (string, error) {
 	if len(errors) > 0 {
 		errorMsgs := make([]string, len(errors))
 		for i, err := range errors {
-			errorMsgs[i] = err.Error()
+			errorMsgs[i] = diag.Annotate(err)
 		}
-		return "", fmt.Errorf("semantic error: %s", strings.Join(errorMsgs, ", "))
+		return "", fmt.Errorf(i18n.T("cli.semantic_error"), strings.Join(errorMsgs, ", "))
 	}
 
 	// Evaluate
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 	"github.com/mburakmmm/sky-lang/internal/interpreter"
 	"github.com/mburakmmm/sky-lang/internal/lexer"
 	"github.com/mburakmmm/sky-lang/internal/parser"
@@ -114,24 +115,14 @@ func (tr *TestRunner) runSingleTest(file string) TestResult {
 		return result
 	}
 
-	// Check for imports (skip semantic check if imports present)
-	hasImports := false
-	for _, stmt := range program.Statements {
-		if _, ok := stmt.(*ast.ImportStatement); ok {
-			hasImports = true
-			break
-		}
-	}
-
-	if !hasImports {
-		// Semantic check
-		checker := sema.NewChecker()
-		errors := checker.Check(program)
-		if len(errors) > 0 {
-			result.Error = fmt.Sprintf("Semantic errors: %v", errors)
-			result.Duration = time.Since(start)
-			return result
-		}
+	// Semantic check, following imports like the interpreter does
+	checker := sema.NewChecker()
+	checker.SetSourceFile(file)
+	errors := checker.Check(program)
+	if len(errors) > 0 {
+		result.Error = fmt.Sprintf("Semantic errors: %v", errors)
+		result.Duration = time.Since(start)
+		return result
 	}
 
 	// Calculate coverage if requested
@@ -287,22 +278,22 @@ func runEnhancedTests(args []string) {
 	})
 
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error finding test files: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.T("cli.find_test_files", err))
 		os.Exit(1)
 	}
 
 	if len(testFiles) == 0 {
-		fmt.Printf("No test files found in %s\n", testDir)
+		fmt.Println(i18n.T("cli.no_test_files", testDir))
 		return
 	}
 
-	fmt.Printf("Running %d test(s)...\n", len(testFiles))
+	fmt.Println(i18n.T("cli.running_tests", len(testFiles)))
 
 	runner := NewTestRunner(parallel, coverage, verbose)
 	err = runner.RunTests(testFiles)
 
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error running tests: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.T("cli.run_tests_error", err))
 		os.Exit(1)
 	}
 
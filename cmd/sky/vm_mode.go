@@ -3,60 +3,209 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 	"github.com/mburakmmm/sky-lang/internal/lexer"
+	"github.com/mburakmmm/sky-lang/internal/optimizer"
 	"github.com/mburakmmm/sky-lang/internal/parser"
 	"github.com/mburakmmm/sky-lang/internal/sema"
 	"github.com/mburakmmm/sky-lang/internal/vm"
 )
 
-// runWithVM runs SKY program using bytecode VM (for recursion support)
-func runWithVM(filename string) error {
-	// Read file
+// runWithVM runs SKY program using bytecode VM (for recursion support).
+// .skyc files are run directly; sources are compiled through the bytecode
+// cache so unchanged files skip compilation. They are still parsed and
+// checked: the cache is keyed by the file alone, and an imported module
+// may have changed since. Sources compiled with runtime type checks are
+// not cached.
+func runWithVM(filename string, level int, typeChecks bool) error {
+	cache := vm.DefaultCache()
+	if typeChecks {
+		cache = nil
+	}
+
+	var bytecode *vm.Bytecode
+	if strings.HasSuffix(filename, ".skyc") {
+		bc, err := vm.ReadSkyc(filename)
+		if err != nil {
+			return fmt.Errorf(i18n.T("cli.read_file_error"), err)
+		}
+		bytecode = bc
+	} else {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf(i18n.T("cli.read_file_error"), err)
+		}
+
+		program, err := checkSource(filename, content)
+		if err != nil {
+			return err
+		}
+		hash := vm.HashSource(content)
+		bc, ok := cache.Load(hash, level, false)
+		if !ok {
+			bc, err = compileProgram(program, level, typeChecks)
+			if err != nil {
+				return err
+			}
+			// A cache that cannot be written only costs a recompile next time
+			bc.SourceHash = hash
+			_ = cache.Store(bc, false)
+		}
+		bytecode = bc
+	}
+
+	// Run on VM
+	machine := vm.NewVM(bytecode)
+	machine.SetSourceFile(filename)
+	machine.SetCache(cache)
+	machine.SetOptLevel(level)
+	machine.SetTypeChecks(typeChecks)
+	if err := machine.Run(); err != nil {
+		return fmt.Errorf(i18n.T("cli.runtime_error_wrap"), err)
+	}
+
+	return nil
+}
+
+// recordProfile runs a program on the VM at -O0 with a profiler attached
+// and writes the profile, also when the program fails
+func recordProfile(filename, output string) error {
+	if strings.HasSuffix(filename, ".skyc") {
+		return fmt.Errorf(i18n.T("cli.cannot_profile"), filename)
+	}
 	content, err := os.ReadFile(filename)
 	if err != nil {
-		return fmt.Errorf("error reading file: %v", err)
+		return fmt.Errorf(i18n.T("cli.read_file_error"), err)
+	}
+	bytecode, err := compileSource(filename, content, 0, false)
+	if err != nil {
+		return err
 	}
 
+	profiler := optimizer.NewPGOProfiler()
+	profiler.SetSource(vm.HashSource(content))
+	machine := vm.NewVM(bytecode)
+	machine.SetSourceFile(filename)
+	machine.SetCache(vm.DefaultCache())
+	machine.SetProfiler(profiler)
+	runErr := machine.Run()
+
+	if err := profiler.SaveProfile(output); err != nil {
+		return fmt.Errorf(i18n.T("cli.write_profile"), err)
+	}
+	if runErr != nil {
+		return fmt.Errorf(i18n.T("cli.runtime_error_wrap"), runErr)
+	}
+	return nil
+}
+
+// applyProfile optimizes -O0 bytecode with a profile file and prints what
+// changed. Profiles of another version of the program are ignored.
+func applyProfile(bytecode *vm.Bytecode, path string) error {
+	profile, err := optimizer.LoadProfile(path)
+	if err != nil {
+		return fmt.Errorf(i18n.T("cli.read_profile"), path, err)
+	}
+	if profile.Source != "" && profile.Source != bytecode.SourceHash {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.stale_profile", path))
+		return nil
+	}
+
+	report := vm.ApplyProfile(bytecode, profile)
+	if len(report.Changes) == 0 {
+		fmt.Println(i18n.T("cli.profile_unchanged", path))
+		return nil
+	}
+	fmt.Println(i18n.T("cli.profile", path))
+	for _, change := range report.Changes {
+		fmt.Printf("  %s\n", change)
+	}
+	return nil
+}
+
+// compileSource parses, checks and compiles a SKY program to bytecode,
+// optimized at the given level and with runtime type checks if asked
+func compileSource(filename string, content []byte, level int, typeChecks bool) (*vm.Bytecode, error) {
+	program, err := checkSource(filename, content)
+	if err != nil {
+		return nil, err
+	}
+	return compileProgram(program, level, typeChecks)
+}
+
+// checkSource parses a SKY program and checks it with its imports
+func checkSource(filename string, content []byte) (*ast.Program, error) {
 	// Lex & Parse (use same API as main.go)
 	l := lexer.New(string(content), filename)
 	p := parser.New(l)
 	program := p.ParseProgram()
 
 	if len(p.Errors()) > 0 {
-		return fmt.Errorf("parse errors:\n%v", strings.Join(p.Errors(), "\n"))
+		return nil, fmt.Errorf(i18n.T("cli.parse_error_list"), strings.Join(p.Errors(), "\n"))
 	}
 
-	// Semantic check
+	// Semantic check, following imports like the interpreter does
 	checker := sema.NewChecker()
+	checker.SetSourceFile(filename)
+	checker.SetSource(string(content))
 	errors := checker.Check(program)
 	if len(errors) > 0 {
 		errMsgs := make([]string, len(errors))
 		for i, e := range errors {
-			errMsgs[i] = e.Error()
+			errMsgs[i] = diag.Annotate(e)
 		}
-		return fmt.Errorf("semantic errors:\n%v", strings.Join(errMsgs, "\n"))
+		return nil, fmt.Errorf(i18n.T("cli.semantic_error_list"), strings.Join(errMsgs, "\n"))
 	}
+	return program, nil
+}
 
-	// Compile to bytecode
+// compileProgram compiles a checked program to bytecode
+func compileProgram(program *ast.Program, level int, typeChecks bool) (*vm.Bytecode, error) {
 	compiler := vm.NewCompiler()
+	compiler.SetTypeChecks(typeChecks)
 	bytecode, err := compiler.Compile(program)
 	if err != nil {
-		return fmt.Errorf("compile error: %v", err)
+		return nil, fmt.Errorf(i18n.T("cli.compile_error"), err)
 	}
+	vm.Optimize(bytecode, level)
+	return bytecode, nil
+}
 
-	// Run on VM
-	machine := vm.NewVM(bytecode)
-	if err := machine.Run(); err != nil {
-		return fmt.Errorf("runtime error: %v", err)
+// optLevelFlag removes -O0, -O1 or -O2 from args and returns the level,
+// vm.DefaultOptLevel when no flag is given
+func optLevelFlag(args []string) (int, []string, error) {
+	level := vm.DefaultOptLevel
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-O") {
+			rest = append(rest, arg)
+			continue
+		}
+		n, err := strconv.Atoi(arg[2:])
+		if err != nil || n < 0 || n > vm.MaxOptLevel {
+			return 0, nil, fmt.Errorf(i18n.T("cli.invalid_opt_level"), arg, vm.MaxOptLevel)
+		}
+		level = n
 	}
-
-	return nil
+	return level, rest, nil
 }
 
-// dumpBytecode shows compiled bytecode
-func dumpBytecode(filename string) error {
+// dumpBytecode shows compiled bytecode after optimization at level
+func dumpBytecode(filename string, level int) error {
+	if strings.HasSuffix(filename, ".skyc") {
+		bytecode, err := vm.ReadSkyc(filename)
+		if err != nil {
+			return err
+		}
+		bytecode.Disassemble(filename)
+		return nil
+	}
+
 	// Read file
 	content, err := os.ReadFile(filename)
 	if err != nil {
@@ -79,6 +228,8 @@ func dumpBytecode(filename string) error {
 		return err
 	}
 
+	vm.Optimize(bytecode, level)
+
 	// Disassemble
 	bytecode.Disassemble(filename)
 
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunWithVMCheckedImports edits an imported module between two runs
+// of an unchanged program; the cached bytecode must not hide the new error
+func TestRunWithVMCheckedImports(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(dir, "cache")
+	t.Setenv("SKY_CACHE_DIR", cacheDir)
+	t.Setenv("SKY_NO_CACHE", "")
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	write("lib.sky", `function value(): int
+  return 1
+end`)
+	main := write("main.sky", `import lib
+let n: int = lib.value()`)
+
+	if err := runWithVM(main, 0, false); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if entries, _ := filepath.Glob(filepath.Join(cacheDir, "*-O0.skyc")); len(entries) != 1 {
+		t.Fatalf("cached %d programs, want 1", len(entries))
+	}
+
+	write("lib.sky", `function value(): string
+  return "one"
+end`)
+	err := runWithVM(main, 0, false)
+	if err == nil || !strings.Contains(err.Error(), "cannot assign string to int") {
+		t.Errorf("second run: error %v, want the changed return type", err)
+	}
+}
@@ -3,10 +3,11 @@ module github.com/mburakmmm/sky-lang
 go 1.25.3
 
 require (
-	github.com/BurntSushi/toml v1.5.0 // indirect
-	github.com/pelletier/go-toml/v2 v2.2.4 // indirect
-	golang.org/x/crypto v0.43.0 // indirect
-	golang.org/x/sys v0.37.0 // indirect
-	golang.org/x/text v0.30.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	github.com/BurntSushi/toml v1.5.0
+	github.com/pelletier/go-toml/v2 v2.2.4
+	golang.org/x/crypto v0.43.0
+	golang.org/x/text v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require golang.org/x/sys v0.37.0 // indirect
@@ -192,7 +192,8 @@ type FunctionStatement struct {
 	Token      lexer.Token // FUNCTION token
 	Name       *Identifier
 	Async      bool
-	Coop       bool // Coroutine/Generator flag
+	Coop       bool             // Coroutine/Generator flag
+	TypeParams []*TypeParameter // generic type parameters (function first[T])
 	Parameters []*FunctionParameter
 	ReturnType TypeAnnotation
 	Body       *BlockStatement
@@ -216,6 +217,7 @@ func (fs *FunctionStatement) String() string {
 	}
 	out.WriteString("function ")
 	out.WriteString(fs.Name.String())
+	out.WriteString(typeParamsString(fs.TypeParams))
 	out.WriteString("(")
 	params := []string{}
 	for _, p := range fs.Parameters {
@@ -347,8 +349,10 @@ func (fs *ForStatement) String() string {
 type ClassStatement struct {
 	Token        lexer.Token // CLASS token
 	Name         *Identifier
-	SuperClasses []*Identifier // parent classes (multiple inheritance)
-	Body         []Statement   // class members
+	TypeParams   []*TypeParameter // generic type parameters (class Box[T])
+	SuperClasses []*Identifier    // parent classes (multiple inheritance)
+	Interfaces   []*Identifier    // implemented interfaces (class Foo implements Bar)
+	Body         []Statement      // class members
 }
 
 func (cs *ClassStatement) statementNode()       {}
@@ -358,6 +362,7 @@ func (cs *ClassStatement) String() string {
 	var out strings.Builder
 	out.WriteString("class ")
 	out.WriteString(cs.Name.String())
+	out.WriteString(typeParamsString(cs.TypeParams))
 	if len(cs.SuperClasses) > 0 {
 		out.WriteString(" : ")
 		for i, superClass := range cs.SuperClasses {
@@ -367,6 +372,15 @@ func (cs *ClassStatement) String() string {
 			out.WriteString(superClass.String())
 		}
 	}
+	if len(cs.Interfaces) > 0 {
+		out.WriteString(" implements ")
+		for i, iface := range cs.Interfaces {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(iface.String())
+		}
+	}
 	out.WriteString("\n")
 	for _, stmt := range cs.Body {
 		out.WriteString("  ")
@@ -781,6 +795,7 @@ type MatchExpression struct {
 
 type MatchArm struct {
 	Pattern Expression
+	Guard   Expression // opsiyonel: Pattern if Guard => ...
 	Body    *BlockStatement
 }
 
@@ -808,9 +823,7 @@ func (me *MatchExpression) String() string {
 	out.WriteString(" {\n")
 	for _, arm := range me.Arms {
 		out.WriteString("  ")
-		out.WriteString(arm.Pattern.String())
-		out.WriteString(" => ")
-		out.WriteString(arm.Body.String())
+		out.WriteString(arm.String())
 		out.WriteString("\n")
 	}
 	out.WriteString("}")
@@ -820,6 +833,10 @@ func (me *MatchExpression) String() string {
 func (ma *MatchArm) String() string {
 	var out strings.Builder
 	out.WriteString(ma.Pattern.String())
+	if ma.Guard != nil {
+		out.WriteString(" if ")
+		out.WriteString(ma.Guard.String())
+	}
 	out.WriteString(" => ")
 	out.WriteString(ma.Body.String())
 	return out.String()
@@ -1012,3 +1029,29 @@ func (gt *GenericType) String() string {
 	}
 	return fmt.Sprintf("%s<%s>", gt.BaseName, strings.Join(args, ", "))
 }
+
+// TypeParameter generic tip parametresi [T] veya [T: Bound]
+type TypeParameter struct {
+	Token      lexer.Token    // IDENT token
+	Name       *Identifier    // parameter name
+	Constraint TypeAnnotation // bound (opsiyonel)
+}
+
+func (tp *TypeParameter) String() string {
+	if tp.Constraint != nil {
+		return tp.Name.String() + ": " + tp.Constraint.String()
+	}
+	return tp.Name.String()
+}
+
+// typeParamsString tip parametre listesini [T, U: Bound] olarak yazar
+func typeParamsString(params []*TypeParameter) string {
+	if len(params) == 0 {
+		return ""
+	}
+	names := []string{}
+	for _, tp := range params {
+		names = append(names, tp.String())
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
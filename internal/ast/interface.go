@@ -0,0 +1,67 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+)
+
+// InterfaceStatement represents an interface declaration
+type InterfaceStatement struct {
+	Token   lexer.Token        // INTERFACE token
+	Name    *Identifier        // interface name
+	Extends []*Identifier      // parent interfaces (interface ReadWriter : Reader, Writer)
+	Methods []*InterfaceMethod // method signatures
+}
+
+func (is *InterfaceStatement) statementNode()       {}
+func (is *InterfaceStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *InterfaceStatement) Pos() lexer.Token     { return is.Token }
+func (is *InterfaceStatement) String() string {
+	var out strings.Builder
+	out.WriteString("interface ")
+	out.WriteString(is.Name.String())
+	if len(is.Extends) > 0 {
+		out.WriteString(" : ")
+		for i, parent := range is.Extends {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(parent.String())
+		}
+	}
+	out.WriteString("\n")
+	for _, method := range is.Methods {
+		out.WriteString("  ")
+		out.WriteString(method.String())
+		out.WriteString("\n")
+	}
+	out.WriteString("end")
+	return out.String()
+}
+
+// InterfaceMethod represents a method signature inside an interface
+type InterfaceMethod struct {
+	Token      lexer.Token // FUNCTION token
+	Name       *Identifier
+	Parameters []*FunctionParameter
+	ReturnType TypeAnnotation
+}
+
+func (im *InterfaceMethod) String() string {
+	var out strings.Builder
+	out.WriteString("function ")
+	out.WriteString(im.Name.String())
+	out.WriteString("(")
+	params := []string{}
+	for _, p := range im.Parameters {
+		params = append(params, p.String())
+	}
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(")")
+	if im.ReturnType != nil {
+		out.WriteString(": ")
+		out.WriteString(im.ReturnType.String())
+	}
+	return out.String()
+}
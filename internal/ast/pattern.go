@@ -0,0 +1,103 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+)
+
+// Match pattern'leri
+//
+// Literal, identifier, variant (CallExpression) ve list pattern'leri
+// mevcut expression düğümlerini kullanır. Aşağıdaki düğümler yalnızca
+// match kollarında anlamlıdır.
+
+// OrPattern alternatif pattern'ler (A | B)
+type OrPattern struct {
+	Token        lexer.Token // PIPE token
+	Alternatives []Expression
+}
+
+func (op *OrPattern) expressionNode()      {}
+func (op *OrPattern) TokenLiteral() string { return op.Token.Literal }
+func (op *OrPattern) Pos() lexer.Token     { return op.Alternatives[0].Pos() }
+func (op *OrPattern) String() string {
+	alts := make([]string, len(op.Alternatives))
+	for i, alt := range op.Alternatives {
+		alts[i] = alt.String()
+	}
+	return strings.Join(alts, " | ")
+}
+
+// AsPattern eşlenen değeri bir isme bağlar (Circle(r) as shape)
+type AsPattern struct {
+	Token   lexer.Token // AS token
+	Pattern Expression
+	Name    *Identifier
+}
+
+func (ap *AsPattern) expressionNode()      {}
+func (ap *AsPattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *AsPattern) Pos() lexer.Token     { return ap.Pattern.Pos() }
+func (ap *AsPattern) String() string {
+	return ap.Pattern.String() + " as " + ap.Name.String()
+}
+
+// RestPattern list pattern'inde kalan elemanları eşler ([first, ...rest])
+type RestPattern struct {
+	Token lexer.Token // ELLIPSIS token
+	Name  *Identifier // nil ise kalan elemanlar bağlanmaz
+}
+
+func (rp *RestPattern) expressionNode()      {}
+func (rp *RestPattern) TokenLiteral() string { return rp.Token.Literal }
+func (rp *RestPattern) Pos() lexer.Token     { return rp.Token }
+func (rp *RestPattern) String() string {
+	if rp.Name == nil {
+		return "..."
+	}
+	return "..." + rp.Name.String()
+}
+
+// DictPattern anahtarları verilen dict'leri eşler ({"type": "x", "id": id})
+// Pattern'de olmayan anahtarlar yok sayılır
+type DictPattern struct {
+	Token  lexer.Token // LBRACE token
+	Keys   []Expression
+	Values []Expression
+}
+
+func (dp *DictPattern) expressionNode()      {}
+func (dp *DictPattern) TokenLiteral() string { return dp.Token.Literal }
+func (dp *DictPattern) Pos() lexer.Token     { return dp.Token }
+func (dp *DictPattern) String() string {
+	pairs := make([]string, len(dp.Keys))
+	for i := range dp.Keys {
+		pairs[i] = dp.Keys[i].String() + ": " + dp.Values[i].String()
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// ClassPattern sınıf instance'ını ve alanlarını eşler (Point(x: 0, y: y))
+type ClassPattern struct {
+	Token  lexer.Token // sınıf adı token'ı
+	Class  *Identifier
+	Fields []*FieldPattern
+}
+
+func (cp *ClassPattern) expressionNode()      {}
+func (cp *ClassPattern) TokenLiteral() string { return cp.Token.Literal }
+func (cp *ClassPattern) Pos() lexer.Token     { return cp.Token }
+func (cp *ClassPattern) String() string {
+	fields := make([]string, len(cp.Fields))
+	for i, f := range cp.Fields {
+		fields[i] = f.Name.String() + ": " + f.Pattern.String()
+	}
+	return cp.Class.String() + "(" + strings.Join(fields, ", ") + ")"
+}
+
+// FieldPattern class pattern'indeki tek bir alan
+type FieldPattern struct {
+	Name    *Identifier
+	Pattern Expression
+}
@@ -0,0 +1,114 @@
+// Package backendtest checks the native build backends against the
+// interpreter. The programs in testdata are shared by every backend: each
+// one is built, run, and must print what the interpreter prints.
+package backendtest
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+	"github.com/mburakmmm/sky-lang/internal/parser"
+)
+
+// Dir is the directory of the programs, relative to the package directory
+// of a backend, where its tests run
+const Dir = "../backendtest/testdata"
+
+// BuildFunc builds program, read from sourceFile, to a binary at outputPath
+type BuildFunc func(program *ast.Program, sourceFile, outputPath string) error
+
+// Conformance builds every program in Dir with build and checks that the
+// binary prints what the interpreter prints. Programs named in unsupported
+// use features the backend does not have and are skipped.
+func Conformance(t *testing.T, backend string, build BuildFunc, unsupported ...string) {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(Dir, "*.sky"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no programs in " + Dir)
+	}
+
+	skip := make(map[string]bool)
+	for _, name := range unsupported {
+		skip[name] = true
+	}
+	for _, file := range files {
+		file := file
+		name := strings.TrimSuffix(filepath.Base(file), ".sky")
+		t.Run(name, func(t *testing.T) {
+			if skip[name] {
+				t.Skip("not supported by the " + backend)
+			}
+			program := Parse(t, file)
+			want, wantErr := Interpret(t, program, file)
+
+			binary := filepath.Join(t.TempDir(), "prog")
+			if err := build(program, file, binary); err != nil {
+				t.Fatalf("build failed: %v", err)
+			}
+			cmd := exec.Command(binary)
+			var stdout bytes.Buffer
+			cmd.Stdout = &stdout
+			runErr := cmd.Run()
+			if _, ok := runErr.(*exec.ExitError); runErr != nil && !ok {
+				t.Fatalf("cannot run binary: %v", runErr)
+			}
+
+			if got := stdout.String(); got != want {
+				t.Errorf("output differs\n--- interpreter\n%s--- %s\n%s", want, backend, got)
+			}
+			if (runErr != nil) != (wantErr != nil) {
+				t.Errorf("interpreter error %v, binary exit %v", wantErr, runErr)
+			}
+		})
+	}
+}
+
+// Parse parses a program file
+func Parse(t *testing.T, file string) *ast.Program {
+	t.Helper()
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := parser.New(lexer.New(string(content), file))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parse errors: %v", p.Errors())
+	}
+	return program
+}
+
+// Interpret runs program in the interpreter and returns what it printed
+func Interpret(t *testing.T, program *ast.Program, file string) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		output <- buf.String()
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	interp := interpreter.New()
+	interp.SetSourceFile(file)
+	evalErr := interp.Eval(program)
+	os.Stdout = stdout
+	w.Close()
+	return <-output, evalErr
+}
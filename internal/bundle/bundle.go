@@ -0,0 +1,291 @@
+// Package bundle packs a SKY program with every module it imports into a
+// single executable. The executable is a prebuilt sky binary followed by
+// a zip archive of the sources and a trailer locating the archive; when
+// sky starts it looks for the trailer in its own file and, if present,
+// runs the bundled program instead of reading its command line.
+//
+// Modules are stored under their import path, std/json for import
+// std.json, so the bundled program finds them without SKY, the sources
+// or a dependencies/ directory on the machine it runs on.
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+	"github.com/mburakmmm/sky-lang/internal/parser"
+)
+
+// magic ends every bundled executable
+const magic = "SKYBNDL1"
+
+// trailerSize is the archive size followed by magic
+const trailerSize = 8 + len(magic)
+
+const (
+	manifestName = "bundle.json"
+	mainName     = "main.sky"
+	modulesDir   = "modules"
+)
+
+// ErrNoBundle is returned by Open for files without a bundle
+var ErrNoBundle = errors.New("no bundle")
+
+// Bundle is a program and the sources of the modules it imports
+type Bundle struct {
+	Main    string            // name of the main file, for messages
+	Source  []byte            // the main program
+	Modules map[string][]byte // module sources by import path
+}
+
+type manifest struct {
+	Main    string   `json:"main"`
+	Modules []string `json:"modules"`
+}
+
+// Collect reads filename and, transitively, every module it imports.
+// Modules are found like the interpreter finds them when filename runs
+// from the current directory.
+func Collect(filename string) (*Bundle, error) {
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	program, err := parse(source, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	currentDir, _ := os.Getwd()
+	b := &Bundle{Main: filepath.Base(filename), Source: source, Modules: make(map[string][]byte)}
+	pending := imports(program)
+	for len(pending) > 0 {
+		path := pending[0]
+		pending = pending[1:]
+		if _, ok := b.Modules[path]; ok {
+			continue
+		}
+		file := interpreter.ResolveModulePath(path, filename, currentDir)
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load module %s: %v", path, err)
+		}
+		module, err := parse(content, file)
+		if err != nil {
+			return nil, fmt.Errorf("module %s: %v", path, err)
+		}
+		b.Modules[path] = content
+		pending = append(pending, imports(module)...)
+	}
+	return b, nil
+}
+
+func parse(source []byte, filename string) (*ast.Program, error) {
+	p := parser.New(lexer.New(string(source), filename))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil, fmt.Errorf("parse errors:\n%s", strings.Join(p.Errors(), "\n"))
+	}
+	return program, nil
+}
+
+// imports lists the import paths of the program, including imports
+// inside functions and blocks
+func imports(program *ast.Program) []string {
+	var paths []string
+	walkImports(reflect.ValueOf(program), func(stmt *ast.ImportStatement) {
+		paths = append(paths, strings.Join(stmt.Path, "/"))
+	})
+	return paths
+}
+
+func walkImports(v reflect.Value, fn func(*ast.ImportStatement)) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if v.CanInterface() {
+			if stmt, ok := v.Interface().(*ast.ImportStatement); ok {
+				fn(stmt)
+				return
+			}
+		}
+		walkImports(v.Elem(), fn)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			walkImports(v.Field(i), fn)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkImports(v.Index(i), fn)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			walkImports(iter.Key(), fn)
+			walkImports(iter.Value(), fn)
+		}
+	}
+}
+
+// WriteArchive writes the bundle as a zip archive
+func (b *Bundle) WriteArchive(w io.Writer) error {
+	paths := make([]string, 0, len(b.Modules))
+	for path := range b.Modules {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	zw := zip.NewWriter(w)
+	m, err := json.MarshalIndent(manifest{Main: b.Main, Modules: paths}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(zw, manifestName, m); err != nil {
+		return err
+	}
+	if err := writeFile(zw, mainName, b.Source); err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := writeFile(zw, modulesDir+"/"+path+".sky", b.Modules[path]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Build writes an executable running the bundle to output. runtime is
+// the sky binary the bundle runs on; a bundle it already carries is
+// dropped.
+func Build(runtime string, b *Bundle, output string) error {
+	exe, err := os.ReadFile(runtime)
+	if err != nil {
+		return fmt.Errorf("cannot read runtime: %v", err)
+	}
+	if size, ok := archiveSize(exe); ok {
+		exe = exe[:len(exe)-trailerSize-int(size)]
+	}
+
+	var archive bytes.Buffer
+	if err := b.WriteArchive(&archive); err != nil {
+		return err
+	}
+	var trailer [trailerSize]byte
+	binary.LittleEndian.PutUint64(trailer[:8], uint64(archive.Len()))
+	copy(trailer[8:], magic)
+
+	out := make([]byte, 0, len(exe)+archive.Len()+trailerSize)
+	out = append(out, exe...)
+	out = append(out, archive.Bytes()...)
+	out = append(out, trailer[:]...)
+	return os.WriteFile(output, out, 0755)
+}
+
+// archiveSize returns the size of the archive data carries at its end
+func archiveSize(data []byte) (uint64, bool) {
+	if len(data) < trailerSize || string(data[len(data)-len(magic):]) != magic {
+		return 0, false
+	}
+	size := binary.LittleEndian.Uint64(data[len(data)-trailerSize:])
+	if size > uint64(len(data)-trailerSize) {
+		return 0, false
+	}
+	return size, true
+}
+
+// Open reads the bundle at the end of the executable exe; it returns
+// ErrNoBundle if there is none
+func Open(exe string) (*Bundle, error) {
+	f, err := os.Open(exe)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < int64(trailerSize) {
+		return nil, ErrNoBundle
+	}
+	trailer := make([]byte, trailerSize)
+	if _, err := f.ReadAt(trailer, info.Size()-int64(trailerSize)); err != nil {
+		return nil, err
+	}
+	if string(trailer[8:]) != magic {
+		return nil, ErrNoBundle
+	}
+	size := int64(binary.LittleEndian.Uint64(trailer[:8]))
+	if size > info.Size()-int64(trailerSize) {
+		return nil, fmt.Errorf("corrupt bundle in %s", exe)
+	}
+	archive := io.NewSectionReader(f, info.Size()-int64(trailerSize)-size, size)
+	zr, err := zip.NewReader(archive, size)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt bundle in %s: %v", exe, err)
+	}
+	return readArchive(zr)
+}
+
+func readArchive(fsys fs.FS) (*Bundle, error) {
+	data, err := fs.ReadFile(fsys, manifestName)
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("bad bundle manifest: %v", err)
+	}
+	source, err := fs.ReadFile(fsys, mainName)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{Main: m.Main, Source: source, Modules: make(map[string][]byte)}
+	for _, path := range m.Modules {
+		content, err := fs.ReadFile(fsys, modulesDir+"/"+path+".sky")
+		if err != nil {
+			return nil, err
+		}
+		b.Modules[path] = content
+	}
+	return b, nil
+}
+
+// FS returns the modules as files named by import path, like
+// std/json.sky, for interpreter.SetModuleFS
+func (b *Bundle) FS() (fs.FS, error) {
+	var archive bytes.Buffer
+	if err := b.WriteArchive(&archive); err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	if err != nil {
+		return nil, err
+	}
+	return fs.Sub(zr, modulesDir)
+}
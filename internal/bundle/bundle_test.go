@@ -0,0 +1,119 @@
+package bundle
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCollect(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "main.sky"), "import greet\nimport lib.util\nprint(greet.hello())\n")
+	write(t, filepath.Join(dir, "dependencies", "greet", "src", "greet.sky"),
+		"function hello(): any\n  import lib.util\n  return util.name()\n")
+	write(t, filepath.Join(dir, "lib", "util.sky"), "function name(): any\n  return \"sky\"\n")
+	t.Chdir(dir)
+
+	b, err := Collect("main.sky")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Main != "main.sky" {
+		t.Errorf("main is %q", b.Main)
+	}
+	var paths []string
+	for path := range b.Modules {
+		paths = append(paths, path)
+	}
+	if len(paths) != 2 || b.Modules["greet"] == nil || b.Modules["lib/util"] == nil {
+		t.Errorf("modules are %v, want greet and lib/util", paths)
+	}
+}
+
+func TestCollectMissingModule(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "main.sky"), "import missing\n")
+	t.Chdir(dir)
+
+	if _, err := Collect("main.sky"); err == nil {
+		t.Fatal("missing module not reported")
+	}
+}
+
+func TestBuildAndOpen(t *testing.T) {
+	dir := t.TempDir()
+	runtime := filepath.Join(dir, "sky")
+	write(t, runtime, "#!runtime binary\n")
+
+	b := &Bundle{
+		Main:    "app.sky",
+		Source:  []byte("print(1)\n"),
+		Modules: map[string][]byte{"std/json": []byte("let x = 1\n")},
+	}
+	app := filepath.Join(dir, "app")
+	if err := Build(runtime, b, app); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("#!runtime binary\n")) {
+		t.Error("executable does not start with the runtime")
+	}
+
+	got, err := Open(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, b) {
+		t.Errorf("opened %+v, want %+v", got, b)
+	}
+
+	fsys, err := got.FS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := fs.ReadFile(fsys, "std/json.sky")
+	if err != nil || string(content) != "let x = 1\n" {
+		t.Errorf("module file is %q, %v", content, err)
+	}
+
+	// A bundled executable used as the runtime loses its bundle
+	again := filepath.Join(dir, "again")
+	if err := Build(app, &Bundle{Main: "b.sky", Source: []byte("print(2)\n")}, again); err != nil {
+		t.Fatal(err)
+	}
+	data, err = os.ReadFile(again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("print(1)")) {
+		t.Error("old bundle kept")
+	}
+	if got, err := Open(again); err != nil || got.Main != "b.sky" {
+		t.Errorf("rebuilt bundle: %v, %v", got, err)
+	}
+}
+
+func TestOpenWithoutBundle(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "plain")
+	write(t, file, "just a program")
+	if _, err := Open(file); !errors.Is(err, ErrNoBundle) {
+		t.Errorf("got %v, want ErrNoBundle", err)
+	}
+}
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,190 @@
+package cgen
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+)
+
+// The runtime is compiled together with the generated code, so building
+// a program needs nothing but a C compiler
+//
+//go:embed skyrt/skyrt.h skyrt/skyrt.c skyrt/builtins.def
+var runtimeFiles embed.FS
+
+//go:embed skyrt/builtins.def
+var builtinsDef []byte
+
+// builtinNames are the built-ins compiled code can call directly
+var builtinNames = builtins()
+
+// cflags are the flags every C file is compiled with
+var cflags = []string{"-std=c99", "-O2"}
+
+// Build compiles program, read from sourceFile, to a native binary at
+// outputPath using the C compiler named by $CC, or cc. The binary is
+// linked statically where the platform allows it.
+func Build(program *ast.Program, sourceFile, outputPath string) error {
+	src, err := Generate(program, sourceFile)
+	if err != nil {
+		return err
+	}
+	cc, err := compiler("CC", "cc")
+	if err != nil {
+		return err
+	}
+	output, err := filepath.Abs(outputPath)
+	if err != nil {
+		return err
+	}
+
+	dir, err := writeSources(src)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	args := append(append([]string(nil), cflags...), "-o", output, "main.c", "skyrt.c", "-lm")
+	if !staticLinking() {
+		return run(dir, cc, args...)
+	}
+	err = run(dir, cc, append(args, "-static")...)
+	if err != nil && run(dir, cc, args...) == nil {
+		// Only the static C library is missing
+		os.Remove(output)
+		return fmt.Errorf("cannot link a static binary: %s has no static C library (such as glibc-static or musl); install one and build again", filepath.Base(cc))
+	}
+	return err
+}
+
+// staticLinking reports whether binaries are linked statically. macOS
+// has no static C library.
+func staticLinking() bool {
+	return runtime.GOOS != "darwin"
+}
+
+// BuildLibrary compiles program to a static library at outputPath, such
+// as libname.a, and writes next to it the header name.h declaring the
+// exported functions together with the runtime header it includes. The
+// functions are named name_function.
+func BuildLibrary(program *ast.Program, sourceFile, outputPath string) error {
+	prefix := LibraryPrefix(outputPath)
+	src, exports, err := GenerateLibrary(program, sourceFile, prefix)
+	if err != nil {
+		return err
+	}
+	cc, err := compiler("CC", "cc")
+	if err != nil {
+		return err
+	}
+	ar, err := compiler("AR", "ar")
+	if err != nil {
+		return err
+	}
+	output, err := filepath.Abs(outputPath)
+	if err != nil {
+		return err
+	}
+
+	dir, err := writeSources(src)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	args := append(append([]string(nil), cflags...), "-c", "main.c", "skyrt.c")
+	if err := run(dir, cc, args...); err != nil {
+		return err
+	}
+	os.Remove(output)
+	if err := run(dir, ar, "rcs", output, "main.o", "skyrt.o"); err != nil {
+		return err
+	}
+
+	// Headers for the host program
+	outDir := filepath.Dir(output)
+	if err := os.WriteFile(filepath.Join(outDir, prefix+".h"), Header(sourceFile, prefix, exports), 0644); err != nil {
+		return err
+	}
+	for _, name := range []string{"skyrt.h", "builtins.def"} {
+		data, err := runtimeFiles.ReadFile("skyrt/" + name)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(outDir, name), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LibraryPrefix returns the name of the library at path, which prefixes
+// its C functions: libmath.a is math
+func LibraryPrefix(path string) string {
+	name := filepath.Base(path)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	name = strings.TrimPrefix(name, "lib")
+	name = cName(name)
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "sky" + name
+	}
+	return name
+}
+
+// compiler finds the tool named by the environment variable env, or def
+func compiler(env, def string) (string, error) {
+	name := os.Getenv(env)
+	if name == "" {
+		name = def
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("the C backend needs %s: %v", name, err)
+	}
+	return path, nil
+}
+
+// writeSources writes the generated program and the runtime into a new
+// temporary directory
+func writeSources(src []byte) (string, error) {
+	dir, err := os.MkdirTemp("", "sky-build-")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.c"), src, 0644); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	entries, err := runtimeFiles.ReadDir("skyrt")
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	for _, entry := range entries {
+		data, err := runtimeFiles.ReadFile("skyrt/" + entry.Name())
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, entry.Name()), data, 0644)
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// run runs a build tool in dir
+func run(dir, tool string, args ...string) error {
+	cmd := exec.Command(tool, args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %v\n%s", filepath.Base(tool), err, out)
+	}
+	return nil
+}
@@ -0,0 +1,730 @@
+// Package cgen is the C build backend. It lowers a SKY program to C99
+// code running on the skyrt runtime, a small C library with a garbage
+// collector, and builds both with the system C compiler into a binary or,
+// for libraries, into a static archive with a header other languages can
+// call through.
+//
+// Names are resolved like in the Go backend: top-level names are globals,
+// every other variable is a local of the function that defines it. Each
+// SKY function becomes a C function. Functions that define nested
+// functions keep their locals in a heap environment the nested functions
+// share; all others use plain C locals.
+package cgen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+	"github.com/mburakmmm/sky-lang/internal/parser"
+)
+
+// generator lowers a program and the modules it imports
+type generator struct {
+	sourceFile  string
+	currentDir  string
+	units       []*unit
+	modules     map[string]*unit // imported modules by import path
+	unsupported map[string]bool  // interpreter built-ins skyrt does not provide
+	ids         int
+
+	funcs     []string       // C functions of SKY functions
+	constants []string       // string constants, K[i]
+	constIdx  map[string]int // string constant -> index in constants
+}
+
+// unit is the main program or an imported module
+type unit struct {
+	id     int
+	prefix string // prefix of the C names of its globals
+
+	variants map[string]string // enum variant name -> enum name (bare identifiers in patterns)
+	classes  map[string]bool   // class names (constructor-style patterns)
+	declared map[string]bool   // top-level names that shadow built-ins
+
+	globals map[string]bool // every global the code reads or writes
+	stored  map[string]bool // globals the code assigns, exported by modules
+	script  string
+}
+
+// builtins returns the names of the built-ins in the runtime
+func builtins() map[string]bool {
+	names := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(builtinsDef))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "SKY_BUILTIN(") && strings.HasSuffix(line, ")") {
+			names[strings.TrimSuffix(strings.TrimPrefix(line, "SKY_BUILTIN("), ")")] = true
+		}
+	}
+	return names
+}
+
+func newGenerator(sourceFile string) (*generator, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		sourceFile:  sourceFile,
+		currentDir:  cwd,
+		modules:     make(map[string]*unit),
+		unsupported: make(map[string]bool),
+		constIdx:    make(map[string]int),
+	}
+	for name, val := range interpreter.Builtins() {
+		if _, ok := val.(*interpreter.Function); ok && !builtinNames[name] {
+			g.unsupported[name] = true
+		}
+	}
+	return g, nil
+}
+
+// Generate returns the C source of program, read from sourceFile. Imports
+// are resolved like the VM does.
+func Generate(program *ast.Program, sourceFile string) ([]byte, error) {
+	g, err := newGenerator(sourceFile)
+	if err != nil {
+		return nil, err
+	}
+	main := g.newUnit()
+	if err := g.compileUnit(main, program, true); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	g.file(&buf)
+	buf.WriteString("int main(void) {\n    return sky_main(script);\n}\n")
+	return buf.Bytes(), nil
+}
+
+// Export is a function a library exports
+type Export struct {
+	Name   string
+	Params []string
+}
+
+// GenerateLibrary returns the C source of program as a library and the
+// functions it exports: the public top-level functions except main. The
+// C functions are named prefix_name; prefix_init runs the top-level code.
+func GenerateLibrary(program *ast.Program, sourceFile, prefix string) ([]byte, []Export, error) {
+	g, err := newGenerator(sourceFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	main := g.newUnit()
+	if err := g.compileUnit(main, program, false); err != nil {
+		return nil, nil, err
+	}
+
+	var exports []Export
+	for _, stmt := range program.Statements {
+		fn, ok := stmt.(*ast.FunctionStatement)
+		if !ok || fn.Name.Value == "main" || strings.HasPrefix(fn.Name.Value, "_") {
+			continue
+		}
+		export := Export{Name: fn.Name.Value}
+		for _, param := range fn.Parameters {
+			export.Params = append(export.Params, param.Name.Value)
+		}
+		exports = append(exports, export)
+	}
+
+	var buf bytes.Buffer
+	g.file(&buf)
+	fmt.Fprintf(&buf, "void %s_init(void) {\n    sky_lib_init(script);\n}\n", prefix)
+	for _, export := range exports {
+		args := make([]string, len(export.Params))
+		for i, param := range export.Params {
+			args[i] = cName(param)
+		}
+		fmt.Fprintf(&buf, "\n%s {\n    return sky_lib_call(script, &%s, %s, %s);\n}\n",
+			export.signature(prefix), main.prefix+cName(export.Name), cString(export.Name), argArray(args))
+	}
+	return buf.Bytes(), exports, nil
+}
+
+// signature is the C declaration of an exported function
+func (e Export) signature(prefix string) string {
+	params := make([]string, len(e.Params))
+	for i, param := range e.Params {
+		params[i] = "sky_value " + cName(param)
+	}
+	if len(params) == 0 {
+		params = []string{"void"}
+	}
+	return fmt.Sprintf("sky_value %s_%s(%s)", prefix, cName(e.Name), strings.Join(params, ", "))
+}
+
+// Header returns the C header declaring the functions of a library
+func Header(sourceFile, prefix string, exports []Export) []byte {
+	var buf bytes.Buffer
+	guard := strings.ToUpper(prefix) + "_H"
+	fmt.Fprintf(&buf, "/* Code generated by sky build from %s. DO NOT EDIT. */\n\n", commentText(sourceFile))
+	fmt.Fprintf(&buf, "#ifndef %s\n#define %s\n\n#include \"skyrt.h\"\n\n", guard, guard)
+	buf.WriteString("/* Runs the top-level code of the library; the first call of a\n * function does so too */\n")
+	fmt.Fprintf(&buf, "void %s_init(void);\n\n", prefix)
+	for _, export := range exports {
+		fmt.Fprintf(&buf, "%s;\n", export.signature(prefix))
+	}
+	fmt.Fprintf(&buf, "\n#endif\n")
+	return buf.Bytes()
+}
+
+func (g *generator) newUnit() *unit {
+	u := &unit{
+		id:       len(g.units),
+		prefix:   "g_",
+		variants: make(map[string]string),
+		classes:  make(map[string]bool),
+		declared: make(map[string]bool),
+		globals:  make(map[string]bool),
+		stored:   make(map[string]bool),
+	}
+	if u.id > 0 {
+		u.prefix = fmt.Sprintf("m%d_", u.id)
+	}
+	g.units = append(g.units, u)
+	return u
+}
+
+// newID returns a number for a unique C name
+func (g *generator) newID() int {
+	g.ids++
+	return g.ids
+}
+
+// constant returns the C expression of a string constant
+func (g *generator) constant(s string) string {
+	idx, ok := g.constIdx[s]
+	if !ok {
+		idx = len(g.constants)
+		g.constants = append(g.constants, s)
+		g.constIdx[s] = idx
+	}
+	return fmt.Sprintf("K[%d]", idx)
+}
+
+// scriptName is the C function running the top-level code of u
+func (u *unit) scriptName() string {
+	if u.id == 0 {
+		return "script"
+	}
+	return fmt.Sprintf("mod%d_script", u.id)
+}
+
+// compileUnit generates the script function of a program. callMain calls
+// main() after the top-level code, like the VM does for the main program.
+func (g *generator) compileUnit(u *unit, program *ast.Program, callMain bool) error {
+	u.declare(program.Statements)
+
+	f := &fn{g: g, u: u, scope: newScope(nil), class: "NULL", hasEnv: containsFunction(program.Statements)}
+	hasMain := false
+	for _, stmt := range program.Statements {
+		if fn, ok := stmt.(*ast.FunctionStatement); ok && fn.Name.Value == "main" {
+			hasMain = true
+		}
+		if err := f.statement(stmt); err != nil {
+			return err
+		}
+	}
+
+	// Call main; async main returns a promise which is awaited
+	if callMain && hasMain {
+		f.printf("sky_await(sky_call(\"main\", %s, 0, NULL));\n", f.get("main"))
+	}
+	f.printf("return sky_nil();\n")
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "static sky_value %s(void) {\n", u.scriptName())
+	if u.id == 0 {
+		buf.WriteString("setup();\n")
+	}
+	buf.WriteString(f.declarations("NULL"))
+	buf.WriteString(f.out.String())
+	buf.WriteString("}\n")
+	u.script = buf.String()
+	return nil
+}
+
+// declare records top-level names, enum variants and classes
+func (u *unit) declare(stmts []ast.Statement) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.FunctionStatement:
+			u.declared[s.Name.Value] = true
+		case *ast.LetStatement:
+			u.declared[s.Name.Value] = true
+		case *ast.ConstStatement:
+			u.declared[s.Name.Value] = true
+		case *ast.ClassStatement:
+			u.declared[s.Name.Value] = true
+			u.classes[s.Name.Value] = true
+		case *ast.AbstractClassStatement:
+			u.declared[s.Name.Value] = true
+			u.classes[s.Name.Value] = true
+		case *ast.ExpressionStatement:
+			// Assigning an undefined name creates a global, like let
+			if assign, ok := s.Expression.(*ast.InfixExpression); ok && assign.Operator == "=" {
+				if ident, ok := assign.Left.(*ast.Identifier); ok {
+					u.declared[ident.Value] = true
+				}
+			}
+		case *ast.EnumStatement:
+			u.declared[s.Name.Value] = true
+			for _, variant := range s.Variants {
+				u.declared[variant.Name.Value] = true
+				u.variants[variant.Name.Value] = s.Name.Value
+			}
+		}
+	}
+}
+
+// module returns the unit of an imported module, compiling it on first use
+func (g *generator) module(path string) (*unit, error) {
+	if u, ok := g.modules[path]; ok {
+		return u, nil
+	}
+
+	file := interpreter.ResolveModulePath(path, g.sourceFile, g.currentDir)
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load module %s: %v", path, err)
+	}
+	p := parser.New(lexer.New(string(content), file))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil, fmt.Errorf("parse errors in module %s: %v", path, p.Errors())
+	}
+
+	// Registered first so that import cycles end
+	u := g.newUnit()
+	g.modules[path] = u
+	if err := g.compileUnit(u, program, false); err != nil {
+		return nil, fmt.Errorf("error in module %s: %v", path, err)
+	}
+	return u, nil
+}
+
+// file writes the generated code, up to the entry points
+func (g *generator) file(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "/* Code generated by sky build from %s. DO NOT EDIT. */\n\n", commentText(g.sourceFile))
+	buf.WriteString("#include \"skyrt.h\"\n\n")
+
+	if len(g.constants) > 0 {
+		fmt.Fprintf(buf, "static sky_value K[%d];\n", len(g.constants))
+	}
+	// Globals start out undefined (zero) until the program assigns them
+	for _, u := range g.units {
+		for _, name := range sortedNames(u.globals) {
+			fmt.Fprintf(buf, "static sky_value %s%s;\n", u.prefix, cName(name))
+		}
+	}
+	for _, u := range g.units[1:] {
+		fmt.Fprintf(buf, "static int mod%d_loaded;\n", u.id)
+	}
+	buf.WriteString("\n")
+
+	for _, u := range g.units {
+		fmt.Fprintf(buf, "static sky_value %s(void);\n", u.scriptName())
+		if u.id > 0 {
+			fmt.Fprintf(buf, "static sky_value mod%d_exports(void);\n", u.id)
+		}
+	}
+	for _, code := range g.funcs {
+		buf.WriteString(code[:strings.Index(code, " {\n")] + ";\n")
+	}
+	buf.WriteString("\n")
+
+	// setup creates the constants and registers the globals with the
+	// collector before the main script runs
+	buf.WriteString("static void setup(void) {\n")
+	for i, s := range g.constants {
+		fmt.Fprintf(buf, "K[%d] = sky_const(%s, %d);\n", i, cString(s), len(s))
+	}
+	for _, u := range g.units {
+		for _, name := range sortedNames(u.globals) {
+			fmt.Fprintf(buf, "sky_root(&%s%s, 1);\n", u.prefix, cName(name))
+		}
+	}
+	buf.WriteString("}\n\n")
+
+	for _, code := range g.funcs {
+		buf.WriteString(code)
+		buf.WriteString("\n")
+	}
+	for _, u := range g.units {
+		buf.WriteString(u.script)
+		buf.WriteString("\n")
+		if u.id == 0 {
+			continue
+		}
+		fmt.Fprintf(buf, "static sky_value mod%d_exports(void) {\nsky_value ns = sky_namespace();\n", u.id)
+		for _, name := range sortedNames(u.stored) {
+			fmt.Fprintf(buf, "sky_export(ns, %s, %s%s);\n", cString(name), u.prefix, cName(name))
+		}
+		buf.WriteString("return ns;\n}\n\n")
+	}
+}
+
+func sortedNames(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cName turns a SKY identifier into a C identifier. Bytes C does not
+// allow are written as hex.
+func cName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "__x%02x", c)
+		}
+	}
+	return b.String()
+}
+
+// cString quotes s as a C string literal
+func cString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '?':
+			// Keeps trigraphs out of the literal
+			b.WriteString("\\?")
+		case c == '\n':
+			b.WriteString("\\n")
+		case c == '\t':
+			b.WriteString("\\t")
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// commentText makes s safe inside a C comment
+func commentText(s string) string {
+	return strings.ReplaceAll(s, "*/", "* /")
+}
+
+// containsFunction reports whether the AST below node defines a function
+func containsFunction(node interface{}) bool {
+	return walkForFunction(reflect.ValueOf(node))
+}
+
+func walkForFunction(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return false
+		}
+		if v.CanInterface() {
+			switch v.Interface().(type) {
+			case *ast.FunctionStatement, *ast.StaticMethodStatement, *ast.LambdaExpression:
+				return true
+			}
+		}
+		return walkForFunction(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if walkForFunction(v.Field(i)) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if walkForFunction(v.Index(i)) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if walkForFunction(iter.Key()) || walkForFunction(iter.Value()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scope maps the names defined in a block scope to variables
+type scope struct {
+	outer *scope
+	vars  map[string]*variable
+}
+
+// variable is a local: a C local, or slot of the environment of owner
+type variable struct {
+	owner *fn
+	name  string // C local
+	slot  int    // environment slot, if name is empty
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{outer: outer, vars: make(map[string]*variable)}
+}
+
+// lookup finds the variable of name in s or its outer scopes
+func (s *scope) lookup(name string) (*variable, bool) {
+	for ; s != nil; s = s.outer {
+		if v, ok := s.vars[name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// fn generates the body of a SKY function, or the script of a unit
+type fn struct {
+	g         *generator
+	u         *unit
+	enclosing *fn
+	scope     *scope
+	depth     int // block scope depth; the script's top level is 0
+	line      int // line of the statement being compiled
+
+	out    bytes.Buffer
+	locals []string // C locals, declared at the top of the function
+	temps  []string // temporaries holding values
+	flags  []string // temporaries holding C ints
+	slots  int      // size of the environment
+	hasEnv bool     // locals live in the environment E
+	hasTry bool     // locals must survive longjmp, so are volatile
+
+	loops []loop
+	tries []try // try handlers active around the code being compiled
+
+	method bool   // self is the receiver
+	class  string // C expression of the class defining the method, or NULL
+}
+
+// loop is an enclosing loop; tries counts the try handlers open outside it
+type loop struct {
+	tries int
+}
+
+// try is an active handler and the finally block leaving it must run
+type try struct {
+	handler string
+	finally *ast.BlockStatement
+}
+
+func (f *fn) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&f.out, format, args...)
+}
+
+func (f *fn) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", f.line, fmt.Sprintf(format, args...))
+}
+
+// declarations declares the environment, locals and temporaries.
+// parentEnv is the environment the function's environment links to.
+func (f *fn) declarations(parentEnv string) string {
+	var buf strings.Builder
+	if f.hasEnv {
+		fmt.Fprintf(&buf, "sky_env *E = sky_env_new(%s, %d);\n", parentEnv, f.slots)
+	}
+	qualifier := ""
+	if f.hasTry {
+		qualifier = "volatile "
+	}
+	for _, v := range append(append([]string(nil), f.locals...), f.temps...) {
+		fmt.Fprintf(&buf, "%ssky_value %s = sky_nil();\n", qualifier, v)
+	}
+	for _, v := range f.flags {
+		fmt.Fprintf(&buf, "%sint %s = 0;\n", qualifier, v)
+	}
+	return buf.String()
+}
+
+func (f *fn) enterScope() {
+	f.scope = newScope(f.scope)
+	f.depth++
+}
+
+func (f *fn) leaveScope() {
+	f.scope = f.scope.outer
+	f.depth--
+}
+
+// isGlobalScope reports whether definitions become globals
+func (f *fn) isGlobalScope() bool {
+	return f.enclosing == nil && f.depth == 0
+}
+
+// define adds a local to the current scope, reusing the variable if the
+// scope already has it
+func (f *fn) define(name string) string {
+	v, ok := f.scope.vars[name]
+	if !ok {
+		v = &variable{owner: f}
+		if f.hasEnv {
+			v.slot = f.slots
+			f.slots++
+		} else {
+			v.name = fmt.Sprintf("l%d_%s", f.g.newID(), cName(name))
+			f.locals = append(f.locals, v.name)
+		}
+		f.scope.vars[name] = v
+	}
+	return f.access(v)
+}
+
+// access returns the C lvalue of a variable from the code of f. The
+// environment of a function links to the environment of the function
+// that created it, so an enclosing function's slot is some parents up.
+func (f *fn) access(v *variable) string {
+	if v.owner == f && v.name != "" {
+		return v.name
+	}
+	hops := 0
+	for fn := f; fn != v.owner; fn = fn.enclosing {
+		hops++
+	}
+	env := "E"
+	if !f.hasEnv {
+		env = "F->env"
+		hops--
+	}
+	return env + strings.Repeat("->parent", hops) + fmt.Sprintf("->v[%d]", v.slot)
+}
+
+// env returns the environment closures created by f capture
+func (f *fn) env() string {
+	if f.hasEnv {
+		return "E"
+	}
+	return "NULL"
+}
+
+// temp returns a new temporary for a value
+func (f *fn) temp() string {
+	t := fmt.Sprintf("t%d", f.g.newID())
+	f.temps = append(f.temps, t)
+	return t
+}
+
+// flag returns a new temporary for a C int
+func (f *fn) flag() string {
+	t := fmt.Sprintf("t%d", f.g.newID())
+	f.flags = append(f.flags, t)
+	return t
+}
+
+// spill evaluates code into a temporary and returns its name
+func (f *fn) spill(code string) string {
+	t := f.temp()
+	f.printf("%s = %s;\n", t, code)
+	return t
+}
+
+// resolve finds a local of this or an enclosing function
+func (f *fn) resolve(name string) (string, bool) {
+	for fn := f; fn != nil; fn = fn.enclosing {
+		if v, ok := fn.scope.lookup(name); ok {
+			return f.access(v), true
+		}
+	}
+	return "", false
+}
+
+// isUndefined reports whether name is not a known variable
+func (f *fn) isUndefined(name string) bool {
+	if f.u.declared[name] {
+		return false
+	}
+	_, ok := f.resolve(name)
+	return !ok
+}
+
+// inMethod reports whether self is visible from the code being compiled
+func (f *fn) inMethod() bool {
+	for fn := f; fn != nil; fn = fn.enclosing {
+		if fn.method {
+			return true
+		}
+	}
+	return false
+}
+
+// global returns the C variable of a global
+func (f *fn) global(name string) string {
+	f.u.globals[name] = true
+	return f.u.prefix + cName(name)
+}
+
+// get returns the C expression reading a variable. Globals fall back to
+// the built-in of the same name while unassigned.
+func (f *fn) get(name string) string {
+	if v, ok := f.resolve(name); ok {
+		return v
+	}
+	if f.isUndefined(name) && (name == "nil" || name == "null") {
+		return "sky_nil()"
+	}
+	return fmt.Sprintf("sky_global(%s, %s)", f.global(name), cString(name))
+}
+
+// checkName rejects built-ins the C runtime does not provide
+func (f *fn) checkName(name string) error {
+	if f.g.unsupported[name] && f.isUndefined(name) {
+		return f.errorf("%s is not supported by the C backend", name)
+	}
+	return nil
+}
+
+// target returns the C variable an existing variable is assigned through
+func (f *fn) target(name string) string {
+	if v, ok := f.resolve(name); ok {
+		return v
+	}
+	f.u.stored[name] = true
+	return f.global(name)
+}
+
+// defineTarget returns the C variable of a new variable: a global at the
+// top level, a local elsewhere
+func (f *fn) defineTarget(name string) string {
+	if f.isGlobalScope() {
+		f.u.stored[name] = true
+		return f.global(name)
+	}
+	return f.define(name)
+}
+
+// defineVariable assigns code to a new variable
+func (f *fn) defineVariable(name, code string) {
+	f.printf("%s = %s;\n", f.defineTarget(name), code)
+}
+
+// argArray formats arguments as the argc, argv pair of a runtime call
+func argArray(args []string) string {
+	if len(args) == 0 {
+		return "0, NULL"
+	}
+	return strconv.Itoa(len(args)) + ", (sky_value[]){" + strings.Join(args, ", ") + "}"
+}
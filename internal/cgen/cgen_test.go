@@ -0,0 +1,101 @@
+package cgen
+
+import (
+	"debug/elf"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mburakmmm/sky-lang/internal/backendtest"
+)
+
+// TestConformance builds every shared test program with the C backend
+// and checks that the binary prints what the interpreter prints
+func TestConformance(t *testing.T) {
+	if _, err := compiler("CC", "cc"); err != nil {
+		t.Skip(err)
+	}
+	if testing.Short() {
+		t.Skip("builds binaries")
+	}
+	backendtest.Conformance(t, "c backend", Build, "coop")
+}
+
+// TestStaticBinary checks that programs are linked without a dynamic
+// loader, so they run on machines without the C library
+func TestStaticBinary(t *testing.T) {
+	if _, err := compiler("CC", "cc"); err != nil {
+		t.Skip(err)
+	}
+	if testing.Short() {
+		t.Skip("builds binaries")
+	}
+	if !staticLinking() {
+		t.Skip("no static linking on " + runtime.GOOS)
+	}
+
+	file := filepath.Join(backendtest.Dir, "basics.sky")
+	binary := filepath.Join(t.TempDir(), "prog")
+	if err := Build(backendtest.Parse(t, file), file, binary); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	f, err := elf.Open(binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for _, prog := range f.Progs {
+		if prog.Type == elf.PT_INTERP || prog.Type == elf.PT_DYNAMIC {
+			t.Errorf("binary has a %v segment", prog.Type)
+		}
+	}
+}
+
+// TestLibrary links a C program against a library built from a module
+func TestLibrary(t *testing.T) {
+	cc, err := compiler("CC", "cc")
+	if err != nil {
+		t.Skip(err)
+	}
+	if _, err := compiler("AR", "ar"); err != nil {
+		t.Skip(err)
+	}
+	if testing.Short() {
+		t.Skip("builds binaries")
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(backendtest.Dir, "lib", "mathx.sky")
+	if err := BuildLibrary(backendtest.Parse(t, file), file, filepath.Join(dir, "libmathx.a")); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+
+	host := `#include <stdio.h>
+#include "mathx.h"
+
+int main(void) {
+    sky_value v;
+    mathx_init();
+    v = mathx_square(sky_int(7));
+    printf("%s\n", sky_cstr(v));
+    v = mathx_square(sky_str("x"));
+    printf("%d %s\n", sky_error() != NULL, sky_error() ? "error" : sky_cstr(v));
+    return 0;
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "host.c"), []byte(host), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(dir, cc, "-std=c99", "-o", "host", "host.c", "libmathx.a", "-lm"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(filepath.Join(dir, "host")).CombinedOutput()
+	if err != nil {
+		t.Fatalf("host failed: %v\n%s", err, out)
+	}
+	if want := "49\n1 error\n"; string(out) != want {
+		t.Errorf("host printed %q, want %q", out, want)
+	}
+}
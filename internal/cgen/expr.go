@@ -0,0 +1,382 @@
+package cgen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+)
+
+// binaryOps maps infix and compound assignment operators to runtime functions
+var binaryOps = map[string]string{
+	"+": "sky_add", "-": "sky_sub", "*": "sky_mul", "/": "sky_div", "%": "sky_mod",
+	"==": "sky_eq", "!=": "sky_ne",
+	">": "sky_gt", ">=": "sky_ge", "<": "sky_lt", "<=": "sky_le",
+	"+=": "sky_add", "-=": "sky_sub", "*=": "sky_mul", "/=": "sky_div", "%=": "sky_mod",
+}
+
+// expr compiles an expression to a C expression. Statements it needs
+// first, like the arms of a match, are written to f.out.
+func (f *fn) expr(expr ast.Expression) (string, error) {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		return fmt.Sprintf("sky_int(INT64_C(%d))", e.Value), nil
+
+	case *ast.FloatLiteral:
+		return fmt.Sprintf("sky_float(%s)", floatLiteral(e.Value)), nil
+
+	case *ast.StringLiteral:
+		return f.g.constant(e.Value), nil
+
+	case *ast.BooleanLiteral:
+		if e.Value {
+			return "sky_bool(1)", nil
+		}
+		return "sky_bool(0)", nil
+
+	case *ast.Identifier:
+		// super inside a method refers to the defining class's superclass
+		if e.Value == "super" && f.inMethod() {
+			return fmt.Sprintf("sky_super(%s)", f.class), nil
+		}
+		if err := f.checkName(e.Value); err != nil {
+			return "", err
+		}
+		return f.get(e.Value), nil
+
+	case *ast.ListLiteral:
+		elements, err := f.exprs(e.Elements...)
+		if err != nil {
+			return "", err
+		}
+		return "sky_list(" + argArray(elements) + ")", nil
+
+	case *ast.DictLiteral:
+		// Pairs are evaluated in source order
+		keys := make([]ast.Expression, 0, len(e.Pairs))
+		for key := range e.Pairs {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			a, b := keys[i].Pos(), keys[j].Pos()
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Column < b.Column
+		})
+		operands := make([]ast.Expression, 0, 2*len(keys))
+		for _, key := range keys {
+			operands = append(operands, key, e.Pairs[key])
+		}
+		pairs, err := f.exprs(operands...)
+		if err != nil {
+			return "", err
+		}
+		return "sky_dict(" + argArray(pairs) + ")", nil
+
+	case *ast.IndexExpression:
+		operands, err := f.exprs(e.Left, e.Index)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("sky_index(%s, %s)", operands[0], operands[1]), nil
+
+	case *ast.MemberExpression:
+		object, err := f.expr(e.Object)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("sky_get_member(%s, %s)", object, cString(e.Member.Value)), nil
+
+	case *ast.InfixExpression:
+		return f.infixExpression(e)
+
+	case *ast.PrefixExpression:
+		right, err := f.expr(e.Right)
+		if err != nil {
+			return "", err
+		}
+		switch e.Operator {
+		case "!":
+			return fmt.Sprintf("sky_not(%s)", right), nil
+		case "-":
+			return fmt.Sprintf("sky_neg(%s)", right), nil
+		case "+":
+			// Unary plus is the identity
+			return right, nil
+		}
+		return "", f.errorf("unknown prefix operator: %s", e.Operator)
+
+	case *ast.CallExpression:
+		return f.callExpression(e)
+
+	case *ast.LambdaExpression:
+		// Lambdas print as <function lambda> like in the interpreter
+		return f.function("lambda", e.Parameters, e.Body, false, false, false)
+
+	case *ast.ArrowExpression:
+		return f.expr(e.Right)
+
+	case *ast.MatchExpression:
+		return f.matchExpression(e)
+
+	case *ast.AwaitExpression:
+		value, err := f.expr(e.Expression)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("sky_await(%s)", value), nil
+
+	case *ast.YieldExpression:
+		// Coop functions are rejected; elsewhere yield passes its value through
+		return f.value(e.Value)
+
+	default:
+		return "", f.errorf("unknown expression type: %T", expr)
+	}
+}
+
+// floatLiteral formats a float as a C double constant that reads back
+// exactly
+func floatLiteral(v float64) string {
+	s := strconv.FormatFloat(v, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// exprs compiles operands evaluated from left to right. C leaves the
+// order in which call arguments are evaluated open, so when an operand
+// has side effects every operand but the last is first evaluated into a
+// temporary, in order.
+func (f *fn) exprs(list ...ast.Expression) ([]string, error) {
+	ordered := false
+	for _, expr := range list {
+		if hasSideEffects(expr) {
+			ordered = true
+		}
+	}
+
+	codes := make([]string, len(list))
+	for i, expr := range list {
+		code, err := f.expr(expr)
+		if err != nil {
+			return nil, err
+		}
+		if ordered && i < len(list)-1 && !isConstant(expr) {
+			code = f.spill(code)
+		}
+		codes[i] = code
+	}
+	return codes, nil
+}
+
+// isConstant reports whether expr is a literal
+func isConstant(expr ast.Expression) bool {
+	switch expr.(type) {
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.BooleanLiteral:
+		return true
+	}
+	return false
+}
+
+// hasSideEffects reports whether evaluating expr may change variables
+func hasSideEffects(expr ast.Expression) bool {
+	switch e := expr.(type) {
+	case *ast.CallExpression, *ast.AwaitExpression, *ast.YieldExpression, *ast.MatchExpression:
+		return true
+	case *ast.InfixExpression:
+		switch e.Operator {
+		case "=", "+=", "-=", "*=", "/=", "%=":
+			return true
+		}
+		return hasSideEffects(e.Left) || hasSideEffects(e.Right)
+	case *ast.PrefixExpression:
+		return hasSideEffects(e.Right)
+	case *ast.IndexExpression:
+		return hasSideEffects(e.Left) || hasSideEffects(e.Index)
+	case *ast.MemberExpression:
+		return hasSideEffects(e.Object)
+	case *ast.ArrowExpression:
+		return hasSideEffects(e.Right)
+	case *ast.ListLiteral:
+		for _, elem := range e.Elements {
+			if hasSideEffects(elem) {
+				return true
+			}
+		}
+	case *ast.DictLiteral:
+		for key, val := range e.Pairs {
+			if hasSideEffects(key) || hasSideEffects(val) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f *fn) infixExpression(expr *ast.InfixExpression) (string, error) {
+	switch expr.Operator {
+	case "=", "+=", "-=", "*=", "/=", "%=":
+		return f.assignExpression(expr)
+	case "&&", "||":
+		// && and || short-circuit and yield a boolean, like the interpreter
+		return f.logicalExpression(expr)
+	}
+
+	op, ok := binaryOps[expr.Operator]
+	if !ok {
+		return "", f.errorf("unknown operator: %s", expr.Operator)
+	}
+	operands, err := f.exprs(expr.Left, expr.Right)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s(%s, %s)", op, operands[0], operands[1]), nil
+}
+
+func (f *fn) logicalExpression(expr *ast.InfixExpression) (string, error) {
+	left, err := f.expr(expr.Left)
+	if err != nil {
+		return "", err
+	}
+	mark := f.out.Len()
+	right, err := f.expr(expr.Right)
+	if err != nil {
+		return "", err
+	}
+	pre := f.cut(mark)
+	if len(pre) == 0 {
+		return fmt.Sprintf("sky_bool(sky_truthy(%s) %s sky_truthy(%s))", left, expr.Operator, right), nil
+	}
+
+	// The right operand needs statements, which only run when it is evaluated
+	result := f.temp()
+	if expr.Operator == "&&" {
+		f.printf("%s = sky_bool(0);\nif (sky_truthy(%s)) {\n", result, left)
+	} else {
+		f.printf("%s = sky_bool(1);\nif (!sky_truthy(%s)) {\n", result, left)
+	}
+	f.out.Write(pre)
+	f.printf("%s = sky_bool(sky_truthy(%s));\n}\n", result, right)
+	return result, nil
+}
+
+// assignTarget returns the C variable a plain assignment to name stores
+// into, defining the variable if the name is undefined
+func (f *fn) assignTarget(name string) string {
+	if f.isUndefined(name) {
+		return f.defineTarget(name)
+	}
+	return f.target(name)
+}
+
+// assignExpression compiles assignments to variables, members and
+// indexes. The assignment evaluates to the assigned value.
+func (f *fn) assignExpression(expr *ast.InfixExpression) (string, error) {
+	compound := expr.Operator != "="
+	op := binaryOps[expr.Operator]
+
+	switch target := expr.Left.(type) {
+	case *ast.Identifier:
+		var value string
+		if compound {
+			operands, err := f.exprs(target, expr.Right)
+			if err != nil {
+				return "", err
+			}
+			value = fmt.Sprintf("%s(%s, %s)", op, operands[0], operands[1])
+		} else {
+			code, err := f.expr(expr.Right)
+			if err != nil {
+				return "", err
+			}
+			value = code
+		}
+		return fmt.Sprintf("(%s = %s)", f.assignTarget(target.Value), value), nil
+
+	case *ast.MemberExpression:
+		member := cString(target.Member.Value)
+		if !compound {
+			operands, err := f.exprs(target.Object, expr.Right)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("sky_set_member(%s, %s, %s)", operands[0], member, operands[1]), nil
+		}
+		object, err := f.expr(target.Object)
+		if err != nil {
+			return "", err
+		}
+		object = f.spill(object)
+		current := f.spill(fmt.Sprintf("sky_get_member(%s, %s)", object, member))
+		right, err := f.expr(expr.Right)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("sky_set_member(%s, %s, %s(%s, %s))", object, member, op, current, right), nil
+
+	case *ast.IndexExpression:
+		if !compound {
+			operands, err := f.exprs(target.Left, target.Index, expr.Right)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("sky_set_index(%s, %s, %s)", operands[0], operands[1], operands[2]), nil
+		}
+		// The target is evaluated again for the current value
+		operands, err := f.exprs(target.Left, target.Index, target.Left, target.Index, expr.Right)
+		if err != nil {
+			return "", err
+		}
+		current := f.spill(fmt.Sprintf("sky_index(%s, %s)", operands[2], operands[3]))
+		return fmt.Sprintf("sky_set_index(%s, %s, %s(%s, %s))",
+			operands[0], operands[1], op, current, operands[4]), nil
+	}
+
+	return "", f.errorf("invalid assignment target")
+}
+
+func (f *fn) callExpression(expr *ast.CallExpression) (string, error) {
+	switch callee := expr.Function.(type) {
+	case *ast.Identifier:
+		// Built-ins are called directly unless the program redefines them
+		if f.isUndefined(callee.Value) && !f.g.unsupported[callee.Value] && builtinNames[callee.Value] {
+			args, err := f.exprs(expr.Arguments...)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("sky_b_%s(%s)", callee.Value, argArray(args)), nil
+		}
+
+	case *ast.MemberExpression:
+		// Methods are called on their receiver without a bound method
+		if ident, ok := callee.Object.(*ast.Identifier); ok && ident.Value == "super" && f.inMethod() {
+			args, err := f.exprs(expr.Arguments...)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("sky_super_invoke(%s, %s, %s, %s)", f.class, f.get("self"),
+				cString(callee.Member.Value), argArray(args)), nil
+		}
+		operands, err := f.exprs(append([]ast.Expression{callee.Object}, expr.Arguments...)...)
+		if err != nil {
+			return "", err
+		}
+		self := "sky_undef()"
+		if f.method {
+			self = f.get("self")
+		}
+		return fmt.Sprintf("sky_invoke(%s, %s, %s, %s)", self, operands[0], cString(callee.Member.Value),
+			argArray(operands[1:])), nil
+	}
+
+	operands, err := f.exprs(append([]ast.Expression{expr.Function}, expr.Arguments...)...)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sky_call(%s, %s, %s)", cString(expr.Function.String()), operands[0], argArray(operands[1:])), nil
+}
@@ -0,0 +1,3298 @@
+/*
+ * skyrt.c - runtime of SKY programs compiled to C.
+ *
+ * Standard C99 except for the file system and OS built-ins, which use
+ * POSIX where it is available.
+ */
+#if defined(__unix__) || defined(__APPLE__)
+#define _POSIX_C_SOURCE 200809L
+#define SKY_POSIX 1
+#endif
+
+#include "skyrt.h"
+
+#include <ctype.h>
+#include <math.h>
+#include <stdarg.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <time.h>
+
+#ifdef SKY_POSIX
+#include <dirent.h>
+#include <errno.h>
+#include <sys/resource.h>
+#include <sys/stat.h>
+#include <sys/types.h>
+#include <unistd.h>
+#endif
+
+/* Objects */
+
+typedef struct {
+    sky_obj obj;
+    size_t len;
+    char data[];
+} sky_string;
+
+typedef struct {
+    sky_obj obj;
+    size_t len, cap;
+    sky_value *items;
+} sky_list_obj;
+
+typedef struct {
+    char *key; /* NULL for a free slot, tomb for a deleted one */
+    size_t klen;
+    sky_value val;
+} sky_entry;
+
+/* Dicts are hash tables keyed by the printed form of the key value */
+typedef struct {
+    sky_obj obj;
+    size_t len, used, cap;
+    sky_entry *entries;
+} sky_dict_obj;
+
+struct sky_class {
+    sky_obj obj;
+    const char *name;
+    int abstract;
+    int nsupers;
+    sky_class **supers;
+    sky_value methods;    /* dict of functions */
+    sky_value interfaces; /* list */
+};
+
+typedef struct {
+    sky_obj obj;
+    sky_class *cls;
+    sky_value fields; /* dict */
+} sky_instance;
+
+typedef struct {
+    sky_obj obj;
+    const char *name;
+    sky_value extends; /* list of interfaces */
+    sky_value methods; /* dict of arities */
+} sky_iface;
+
+typedef struct {
+    sky_obj obj;
+    const char *name;
+    sky_value variants; /* dict of constructors */
+} sky_enum_obj;
+
+typedef struct {
+    sky_obj obj;
+    const char *enum_name;
+    const char *name;
+    int n;
+    sky_value payload[];
+} sky_variant_obj;
+
+typedef struct {
+    sky_obj obj;
+    sky_value value;
+    sky_value error; /* nil unless the promise was rejected */
+} sky_promise;
+
+#define STR(v) ((sky_string *)(v).as.o)
+#define LIST(v) ((sky_list_obj *)(v).as.o)
+#define DICT(v) ((sky_dict_obj *)(v).as.o)
+#define FUNC(v) ((sky_func *)(v).as.o)
+#define CLASS(v) ((sky_class *)(v).as.o)
+#define INST(v) ((sky_instance *)(v).as.o)
+#define IFACE(v) ((sky_iface *)(v).as.o)
+#define ENUM(v) ((sky_enum_obj *)(v).as.o)
+#define VARIANT(v) ((sky_variant_obj *)(v).as.o)
+#define PROMISE(v) ((sky_promise *)(v).as.o)
+
+static sky_value obj_value(sky_type type, void *o) {
+    sky_value v;
+    v.type = type;
+    v.as.o = (sky_obj *)o;
+    return v;
+}
+
+static int is_obj(sky_value v) {
+    return v.type >= SKY_STR && v.as.o != NULL;
+}
+
+static void fatal(const char *msg) {
+    fflush(stdout);
+    fprintf(stderr, "Runtime error: %s\n", msg);
+    exit(1);
+}
+
+static void *xmalloc(size_t size) {
+    void *p = malloc(size ? size : 1);
+    if (!p) {
+        fatal("out of memory");
+    }
+    return p;
+}
+
+static void *xrealloc(void *p, size_t size) {
+    p = realloc(p, size ? size : 1);
+    if (!p) {
+        fatal("out of memory");
+    }
+    return p;
+}
+
+/* Garbage collector. Objects are found from the globals and pinned
+ * values, and conservatively from every word of the C stack between the
+ * collector and the stack base recorded when the program started. */
+
+typedef struct {
+    sky_value *values;
+    size_t n;
+} root_range;
+
+static sky_obj **heap;
+static size_t heap_len, heap_cap;
+static size_t allocated, threshold = 8 << 20;
+
+static root_range *roots;
+static size_t roots_len, roots_cap;
+
+static sky_value *pinned;
+static size_t pinned_len, pinned_cap;
+
+static char *stack_base;
+static size_t stack_limit = 7 << 20;
+static int gc_enabled;
+
+static sky_obj **gray;
+static size_t gray_len, gray_cap;
+
+static sky_obj **sorted;
+
+static void gc(void);
+
+static void *alloc_obj(int kind, size_t size) {
+    sky_obj *o;
+    if (gc_enabled && allocated > threshold) {
+        gc();
+    }
+    o = xmalloc(size);
+    memset(o, 0, size);
+    o->size = size;
+    o->kind = (unsigned char)kind;
+    if (heap_len == heap_cap) {
+        heap_cap = heap_cap ? 2 * heap_cap : 1024;
+        heap = xrealloc(heap, heap_cap * sizeof *heap);
+    }
+    heap[heap_len++] = o;
+    allocated += size;
+    return o;
+}
+
+void sky_root(sky_value *globals, size_t n) {
+    if (roots_len == roots_cap) {
+        roots_cap = roots_cap ? 2 * roots_cap : 16;
+        roots = xrealloc(roots, roots_cap * sizeof *roots);
+    }
+    roots[roots_len].values = globals;
+    roots[roots_len].n = n;
+    roots_len++;
+}
+
+static void mark_obj(sky_obj *o) {
+    if (o == NULL || o->mark || o->perm) {
+        return;
+    }
+    o->mark = 1;
+    if (gray_len == gray_cap) {
+        gray_cap = gray_cap ? 2 * gray_cap : 1024;
+        gray = xrealloc(gray, gray_cap * sizeof *gray);
+    }
+    gray[gray_len++] = o;
+}
+
+static void mark_value(sky_value v) {
+    if (is_obj(v)) {
+        mark_obj(v.as.o);
+    }
+}
+
+static void mark_values(sky_value *values, size_t n) {
+    size_t i;
+    for (i = 0; i < n; i++) {
+        mark_value(values[i]);
+    }
+}
+
+static void trace(sky_obj *o) {
+    size_t i;
+    switch (o->kind) {
+    case SKY_LIST:
+        mark_values(((sky_list_obj *)o)->items, ((sky_list_obj *)o)->len);
+        break;
+    case SKY_DICT: {
+        sky_dict_obj *d = (sky_dict_obj *)o;
+        for (i = 0; i < d->cap; i++) {
+            if (d->entries[i].key != NULL) {
+                mark_value(d->entries[i].val);
+            }
+        }
+        break;
+    }
+    case SKY_FUNC: {
+        sky_func *f = (sky_func *)o;
+        mark_obj((sky_obj *)f->env);
+        mark_obj((sky_obj *)f->owner);
+        mark_obj((sky_obj *)f->target);
+        mark_value(f->recv);
+        break;
+    }
+    case SKY_CLASS: {
+        sky_class *c = (sky_class *)o;
+        for (i = 0; i < (size_t)c->nsupers; i++) {
+            mark_obj((sky_obj *)c->supers[i]);
+        }
+        mark_value(c->methods);
+        mark_value(c->interfaces);
+        break;
+    }
+    case SKY_INSTANCE:
+        mark_obj((sky_obj *)((sky_instance *)o)->cls);
+        mark_value(((sky_instance *)o)->fields);
+        break;
+    case SKY_INTERFACE:
+        mark_value(((sky_iface *)o)->extends);
+        mark_value(((sky_iface *)o)->methods);
+        break;
+    case SKY_ENUM:
+        mark_value(((sky_enum_obj *)o)->variants);
+        break;
+    case SKY_VARIANT:
+        mark_values(((sky_variant_obj *)o)->payload, (size_t)((sky_variant_obj *)o)->n);
+        break;
+    case SKY_PROMISE:
+        mark_value(((sky_promise *)o)->value);
+        mark_value(((sky_promise *)o)->error);
+        break;
+    case SKY_ENV_KIND:
+        mark_obj((sky_obj *)((sky_env *)o)->parent);
+        mark_values(((sky_env *)o)->v, (size_t)((sky_env *)o)->n);
+        break;
+    }
+}
+
+static int compare_ptr(const void *a, const void *b) {
+    uintptr_t x = (uintptr_t) * (sky_obj *const *)a;
+    uintptr_t y = (uintptr_t) * (sky_obj *const *)b;
+    return x < y ? -1 : x > y;
+}
+
+/* find_obj returns the object containing address w, or NULL */
+static sky_obj *find_obj(uintptr_t w) {
+    size_t lo = 0, hi = heap_len;
+    while (lo < hi) {
+        size_t mid = lo + (hi - lo) / 2;
+        if ((uintptr_t)sorted[mid] <= w) {
+            lo = mid + 1;
+        } else {
+            hi = mid;
+        }
+    }
+    if (lo == 0) {
+        return NULL;
+    }
+    if (w < (uintptr_t)sorted[lo - 1] + sorted[lo - 1]->size) {
+        return sorted[lo - 1];
+    }
+    return NULL;
+}
+
+static void scan_range(const char *lo, const char *hi) {
+    const char *p;
+    uintptr_t start = ((uintptr_t)lo + sizeof(void *) - 1) & ~(uintptr_t)(sizeof(void *) - 1);
+    for (p = (const char *)start; p + sizeof(void *) <= hi; p += sizeof(void *)) {
+        uintptr_t w;
+        memcpy(&w, p, sizeof w);
+        mark_obj(find_obj(w));
+    }
+}
+
+static void free_obj(sky_obj *o) {
+    size_t i;
+    switch (o->kind) {
+    case SKY_LIST:
+        free(((sky_list_obj *)o)->items);
+        break;
+    case SKY_DICT: {
+        sky_dict_obj *d = (sky_dict_obj *)o;
+        for (i = 0; i < d->cap; i++) {
+            if (d->entries[i].key != NULL && d->entries[i].klen != (size_t)-1) {
+                free(d->entries[i].key);
+            }
+        }
+        free(d->entries);
+        break;
+    }
+    case SKY_CLASS:
+        free(((sky_class *)o)->supers);
+        break;
+    }
+    free(o);
+}
+
+/* scan_stack marks what the C stack refers to; registers are spilled to
+ * the stack by setjmp first */
+static void scan_stack(void) {
+    jmp_buf regs;
+    char here;
+    const char *lo, *hi;
+    setjmp(regs);
+    scan_range((const char *)&regs, (const char *)&regs + sizeof regs);
+    lo = &here;
+    hi = stack_base;
+    if (lo > hi) {
+        const char *t = lo;
+        lo = hi;
+        hi = t;
+    }
+    scan_range(lo, hi + sizeof(void *));
+}
+
+static void gc(void) {
+    size_t i, live = 0, kept = 0;
+
+    sorted = xrealloc(sorted, (heap_len ? heap_len : 1) * sizeof *sorted);
+    memcpy(sorted, heap, heap_len * sizeof *heap);
+    qsort(sorted, heap_len, sizeof *sorted, compare_ptr);
+
+    for (i = 0; i < roots_len; i++) {
+        mark_values(roots[i].values, roots[i].n);
+    }
+    mark_values(pinned, pinned_len);
+    scan_stack();
+    while (gray_len > 0) {
+        trace(gray[--gray_len]);
+    }
+
+    for (i = 0; i < heap_len; i++) {
+        sky_obj *o = heap[i];
+        if (o->mark) {
+            o->mark = 0;
+            heap[kept++] = o;
+            live += o->size;
+        } else {
+            free_obj(o);
+        }
+    }
+    heap_len = kept;
+    allocated = live;
+    threshold = live * 2 > (8 << 20) ? live * 2 : (8 << 20);
+}
+
+void sky_pin(sky_value v) {
+    if (pinned_len == pinned_cap) {
+        pinned_cap = pinned_cap ? 2 * pinned_cap : 16;
+        pinned = xrealloc(pinned, pinned_cap * sizeof *pinned);
+    }
+    pinned[pinned_len++] = v;
+}
+
+void sky_unpin(sky_value v) {
+    size_t i;
+    for (i = pinned_len; i > 0; i--) {
+        if (pinned[i - 1].type == v.type && pinned[i - 1].as.o == v.as.o) {
+            pinned[i - 1] = pinned[--pinned_len];
+            return;
+        }
+    }
+}
+
+/* start records the stack base and the stack size the program may use */
+static void start(char *base) {
+    stack_base = base;
+#ifdef SKY_POSIX
+    {
+        struct rlimit rl;
+        if (getrlimit(RLIMIT_STACK, &rl) == 0 && rl.rlim_cur != RLIM_INFINITY && rl.rlim_cur > (1 << 20)) {
+            stack_limit = (size_t)rl.rlim_cur - (512 << 10);
+        }
+    }
+#endif
+    gc_enabled = 1;
+}
+
+/* Buffers for building strings */
+
+typedef struct {
+    char *data;
+    size_t len, cap;
+} buffer;
+
+static void buf_write(buffer *b, const char *s, size_t n) {
+    if (b->len + n + 1 > b->cap) {
+        b->cap = (b->len + n + 1) * 2;
+        b->data = xrealloc(b->data, b->cap);
+    }
+    memcpy(b->data + b->len, s, n);
+    b->len += n;
+    b->data[b->len] = '\0';
+}
+
+static void buf_puts(buffer *b, const char *s) {
+    buf_write(b, s, strlen(s));
+}
+
+static void buf_printf(buffer *b, const char *format, ...) {
+    char small[256];
+    int n;
+    va_list ap;
+    va_start(ap, format);
+    n = vsnprintf(small, sizeof small, format, ap);
+    va_end(ap);
+    if (n < 0) {
+        return;
+    }
+    if ((size_t)n < sizeof small) {
+        buf_write(b, small, (size_t)n);
+        return;
+    }
+    {
+        char *big = xmalloc((size_t)n + 1);
+        va_start(ap, format);
+        vsnprintf(big, (size_t)n + 1, format, ap);
+        va_end(ap);
+        buf_write(b, big, (size_t)n);
+        free(big);
+    }
+}
+
+/* Strings */
+
+sky_value sky_str_len(const char *s, size_t len) {
+    sky_string *str = alloc_obj(SKY_STR, sizeof(sky_string) + len + 1);
+    memcpy(str->data, s, len);
+    str->data[len] = '\0';
+    str->len = len;
+    return obj_value(SKY_STR, str);
+}
+
+sky_value sky_str(const char *s) {
+    return sky_str_len(s, strlen(s));
+}
+
+/* sky_const returns a string constant, which is never collected */
+sky_value sky_const(const char *s, size_t len) {
+    sky_string *str = xmalloc(sizeof(sky_string) + len + 1);
+    memset(str, 0, sizeof(sky_string));
+    memcpy(str->data, s, len);
+    str->data[len] = '\0';
+    str->len = len;
+    str->obj.size = sizeof(sky_string) + len + 1;
+    str->obj.kind = SKY_STR;
+    str->obj.perm = 1;
+    return obj_value(SKY_STR, str);
+}
+
+static sky_value buf_string(buffer *b) {
+    sky_value v = sky_str_len(b->data ? b->data : "", b->len);
+    free(b->data);
+    return v;
+}
+
+/* Errors */
+
+static sky_handler *handlers;
+static int depth;
+
+void sky_try(sky_handler *h) {
+    h->prev = handlers;
+    h->error = sky_nil();
+    h->depth = depth;
+    handlers = h;
+}
+
+void sky_untry(sky_handler *h) {
+    handlers = h->prev;
+}
+
+static void raise_error(sky_value msg) {
+    sky_handler *h = handlers;
+    if (h == NULL) {
+        fatal(STR(msg)->data);
+    }
+    handlers = h->prev;
+    h->error = msg;
+    depth = h->depth;
+    longjmp(h->jb, 1);
+}
+
+void sky_errorf(const char *format, ...) {
+    buffer b = {0};
+    char small[512];
+    int n;
+    va_list ap;
+    va_start(ap, format);
+    n = vsnprintf(small, sizeof small, format, ap);
+    va_end(ap);
+    if (n < 0) {
+        n = 0;
+    }
+    if ((size_t)n < sizeof small) {
+        buf_write(&b, small, (size_t)n);
+    } else {
+        char *big = xmalloc((size_t)n + 1);
+        va_start(ap, format);
+        vsnprintf(big, (size_t)n + 1, format, ap);
+        va_end(ap);
+        buf_write(&b, big, (size_t)n);
+        free(big);
+    }
+    raise_error(buf_string(&b));
+}
+
+static sky_value to_string(sky_value v);
+
+void sky_throw(sky_value v) {
+    raise_error(to_string(v));
+}
+
+void sky_rethrow(sky_handler *h) {
+    raise_error(h->error);
+}
+
+/* Printing values */
+
+/* type_name names the type of v the way interpreter error messages do */
+static const char *type_name(sky_value v) {
+    switch (v.type) {
+    case SKY_INT:
+        return "*interpreter.Integer";
+    case SKY_FLOAT:
+        return "*interpreter.Float";
+    case SKY_STR:
+        return "*interpreter.String";
+    case SKY_BOOL:
+        return "*interpreter.Boolean";
+    case SKY_NIL:
+    case SKY_UNDEF:
+        return "*interpreter.Nil";
+    case SKY_LIST:
+        return "*interpreter.List";
+    case SKY_DICT:
+        return "*interpreter.Dict";
+    case SKY_FUNC:
+        return "*interpreter.Function";
+    case SKY_CLASS:
+        return CLASS(v)->abstract ? "*interpreter.AbstractClass" : "*interpreter.Class";
+    case SKY_INSTANCE:
+        return "*interpreter.Instance";
+    case SKY_INTERFACE:
+        return "*interpreter.Interface";
+    case SKY_ENUM:
+        return "*interpreter.EnumType";
+    case SKY_VARIANT:
+        return "*interpreter.EnumInstance";
+    case SKY_PROMISE:
+        return "*interpreter.Promise";
+    }
+    return "unknown";
+}
+
+/* write_float writes f like Go's %f */
+static void write_float(buffer *b, double f) {
+    if (isnan(f)) {
+        buf_puts(b, "NaN");
+    } else if (isinf(f)) {
+        buf_puts(b, f > 0 ? "+Inf" : "-Inf");
+    } else {
+        buf_printf(b, "%f", f);
+    }
+}
+
+/* write_shortest writes f like Go's %g: the shortest digits that read
+ * back as f, with an exponent below 1e-4 and from 1e+06 on */
+static void write_shortest(buffer *b, double f) {
+    char digits[40], *mant, *e;
+    int prec, exp, nd;
+    if (isnan(f) || isinf(f)) {
+        write_float(b, f);
+        return;
+    }
+    if (f == 0) {
+        buf_puts(b, signbit(f) ? "-0" : "0");
+        return;
+    }
+    for (prec = 0; prec < 17; prec++) {
+        snprintf(digits, sizeof digits, "%.*e", prec, f);
+        if (strtod(digits, NULL) == f) {
+            break;
+        }
+    }
+    e = strchr(digits, 'e');
+    exp = atoi(e + 1);
+    nd = prec + 1;
+    if (exp < -4 || exp >= 6) {
+        *e = '\0';
+        mant = digits;
+        buf_puts(b, mant);
+        buf_printf(b, "e%c%02d", exp < 0 ? '-' : '+', exp < 0 ? -exp : exp);
+        return;
+    }
+    buf_printf(b, "%.*f", nd - (exp + 1) > 0 ? nd - (exp + 1) : 0, f);
+}
+
+static int compare_keys(const void *a, const void *b) {
+    const sky_entry *x = *(const sky_entry *const *)a;
+    const sky_entry *y = *(const sky_entry *const *)b;
+    size_t n = x->klen < y->klen ? x->klen : y->klen;
+    int c = memcmp(x->key, y->key, n);
+    if (c != 0) {
+        return c;
+    }
+    return x->klen < y->klen ? -1 : x->klen > y->klen;
+}
+
+static int is_entry(const sky_entry *e);
+
+/* sorted_entries returns the entries of a dict in key order */
+static sky_entry **sorted_entries(sky_dict_obj *d) {
+    sky_entry **list = xmalloc((d->len ? d->len : 1) * sizeof *list);
+    size_t i, n = 0;
+    for (i = 0; i < d->cap; i++) {
+        if (is_entry(&d->entries[i])) {
+            list[n++] = &d->entries[i];
+        }
+    }
+    qsort(list, n, sizeof *list, compare_keys);
+    return list;
+}
+
+static void write_value(buffer *b, sky_value v) {
+    size_t i;
+    switch (v.type) {
+    case SKY_UNDEF:
+    case SKY_NIL:
+        buf_puts(b, "nil");
+        break;
+    case SKY_BOOL:
+        buf_puts(b, v.as.b ? "true" : "false");
+        break;
+    case SKY_INT:
+        buf_printf(b, "%lld", (long long)v.as.i);
+        break;
+    case SKY_FLOAT:
+        write_float(b, v.as.f);
+        break;
+    case SKY_STR:
+        buf_write(b, STR(v)->data, STR(v)->len);
+        break;
+    case SKY_LIST:
+        buf_puts(b, "[");
+        for (i = 0; i < LIST(v)->len; i++) {
+            if (i > 0) {
+                buf_puts(b, ", ");
+            }
+            write_value(b, LIST(v)->items[i]);
+        }
+        buf_puts(b, "]");
+        break;
+    case SKY_DICT: {
+        /* Pairs print in key order; the interpreter uses map order,
+         * which any order matches */
+        sky_entry **entries = sorted_entries(DICT(v));
+        buf_puts(b, "{");
+        for (i = 0; i < DICT(v)->len; i++) {
+            if (i > 0) {
+                buf_puts(b, ", ");
+            }
+            buf_write(b, entries[i]->key, entries[i]->klen);
+            buf_puts(b, ": ");
+            write_value(b, entries[i]->val);
+        }
+        buf_puts(b, "}");
+        free(entries);
+        break;
+    }
+    case SKY_FUNC:
+        buf_printf(b, (FUNC(v)->flags & SKY_ASYNC) ? "<async function %s>" : "<function %s>", FUNC(v)->name);
+        break;
+    case SKY_CLASS:
+        buf_printf(b, CLASS(v)->abstract ? "AbstractClass(%s)" : "<class %s>", CLASS(v)->name);
+        break;
+    case SKY_INSTANCE:
+        buf_printf(b, "<instance of %s>", INST(v)->cls->name);
+        break;
+    case SKY_INTERFACE:
+        buf_printf(b, "<interface %s>", IFACE(v)->name);
+        break;
+    case SKY_ENUM:
+        buf_printf(b, "enum %s", ENUM(v)->name);
+        break;
+    case SKY_VARIANT:
+        buf_printf(b, "%s::%s", VARIANT(v)->enum_name, VARIANT(v)->name);
+        break;
+    case SKY_PROMISE:
+        if (PROMISE(v)->error.type != SKY_NIL) {
+            buf_puts(b, "<Promise rejected: ");
+            write_value(b, PROMISE(v)->error);
+        } else {
+            buf_puts(b, "<Promise resolved: ");
+            write_value(b, PROMISE(v)->value);
+        }
+        buf_puts(b, ">");
+        break;
+    }
+}
+
+static sky_value to_string(sky_value v) {
+    buffer b = {0};
+    if (v.type == SKY_STR) {
+        return v;
+    }
+    write_value(&b, v);
+    return buf_string(&b);
+}
+
+const char *sky_cstr(sky_value v) {
+    return STR(to_string(v))->data;
+}
+
+/* Truthiness */
+
+int sky_truthy(sky_value v) {
+    switch (v.type) {
+    case SKY_UNDEF:
+    case SKY_NIL:
+        return 0;
+    case SKY_BOOL:
+        return v.as.b;
+    case SKY_INT:
+        return v.as.i != 0;
+    case SKY_FLOAT:
+        return v.as.f != 0;
+    case SKY_STR:
+        return STR(v)->len > 0;
+    case SKY_LIST:
+        return LIST(v)->len > 0;
+    case SKY_DICT:
+        return DICT(v)->len > 0;
+    case SKY_PROMISE:
+        return PROMISE(v)->error.type == SKY_NIL;
+    default:
+        return 1;
+    }
+}
+
+/* Lists */
+
+static sky_list_obj *new_list(size_t cap) {
+    sky_list_obj *l = alloc_obj(SKY_LIST, sizeof(sky_list_obj));
+    l->cap = cap;
+    l->items = xmalloc((cap ? cap : 1) * sizeof(sky_value));
+    allocated += cap * sizeof(sky_value);
+    return l;
+}
+
+static void list_push(sky_list_obj *l, sky_value v) {
+    if (l->len == l->cap) {
+        l->cap = l->cap ? 2 * l->cap : 4;
+        l->items = xrealloc(l->items, l->cap * sizeof(sky_value));
+        allocated += l->cap / 2 * sizeof(sky_value);
+    }
+    l->items[l->len++] = v;
+}
+
+sky_value sky_list(int n, sky_value *items) {
+    sky_list_obj *l = new_list((size_t)n);
+    int i;
+    for (i = 0; i < n; i++) {
+        l->items[i] = items[i];
+    }
+    l->len = (size_t)n;
+    return obj_value(SKY_LIST, l);
+}
+
+/* Dicts */
+
+static char tomb_key;
+
+static int is_entry(const sky_entry *e) {
+    return e->key != NULL && e->key != &tomb_key;
+}
+
+static size_t hash_key(const char *key, size_t len) {
+    size_t h = 1469598103934665603ULL & (size_t)-1, i;
+    for (i = 0; i < len; i++) {
+        h ^= (unsigned char)key[i];
+        h *= (size_t)1099511628211ULL;
+    }
+    return h;
+}
+
+static sky_dict_obj *new_dict(void) {
+    sky_dict_obj *d = alloc_obj(SKY_DICT, sizeof(sky_dict_obj));
+    d->cap = 8;
+    d->entries = xmalloc(d->cap * sizeof(sky_entry));
+    memset(d->entries, 0, d->cap * sizeof(sky_entry));
+    return d;
+}
+
+/* dict_find returns the entry of key, or the free slot it would take */
+static sky_entry *dict_find(sky_dict_obj *d, const char *key, size_t len) {
+    size_t i = hash_key(key, len) & (d->cap - 1);
+    sky_entry *tomb = NULL;
+    for (;;) {
+        sky_entry *e = &d->entries[i];
+        if (e->key == NULL) {
+            return tomb ? tomb : e;
+        }
+        if (e->key == &tomb_key) {
+            if (!tomb) {
+                tomb = e;
+            }
+        } else if (e->klen == len && memcmp(e->key, key, len) == 0) {
+            return e;
+        }
+        i = (i + 1) & (d->cap - 1);
+    }
+}
+
+static sky_value *dict_lookup(sky_dict_obj *d, const char *key, size_t len) {
+    sky_entry *e = dict_find(d, key, len);
+    return is_entry(e) ? &e->val : NULL;
+}
+
+static void dict_put(sky_dict_obj *d, const char *key, size_t len, sky_value val);
+
+static void dict_grow(sky_dict_obj *d) {
+    sky_entry *old = d->entries;
+    size_t i, cap = d->cap;
+    d->cap *= 2;
+    d->entries = xmalloc(d->cap * sizeof(sky_entry));
+    memset(d->entries, 0, d->cap * sizeof(sky_entry));
+    allocated += cap * sizeof(sky_entry);
+    d->len = d->used = 0;
+    for (i = 0; i < cap; i++) {
+        if (is_entry(&old[i])) {
+            sky_entry *e = dict_find(d, old[i].key, old[i].klen);
+            *e = old[i];
+            d->len++;
+            d->used++;
+        }
+    }
+    free(old);
+}
+
+static void dict_put(sky_dict_obj *d, const char *key, size_t len, sky_value val) {
+    sky_entry *e = dict_find(d, key, len);
+    if (is_entry(e)) {
+        e->val = val;
+        return;
+    }
+    if (e->key == NULL) {
+        d->used++;
+    }
+    e->key = xmalloc(len + 1);
+    memcpy(e->key, key, len);
+    e->key[len] = '\0';
+    e->klen = len;
+    e->val = val;
+    d->len++;
+    if (2 * d->used >= d->cap) {
+        dict_grow(d);
+    }
+}
+
+static void dict_delete(sky_dict_obj *d, const char *key, size_t len) {
+    sky_entry *e = dict_find(d, key, len);
+    if (is_entry(e)) {
+        free(e->key);
+        e->key = &tomb_key;
+        e->klen = (size_t)-1;
+        e->val = sky_nil();
+        d->len--;
+    }
+}
+
+static void dict_clear(sky_dict_obj *d) {
+    size_t i;
+    for (i = 0; i < d->cap; i++) {
+        if (is_entry(&d->entries[i])) {
+            free(d->entries[i].key);
+        }
+    }
+    memset(d->entries, 0, d->cap * sizeof(sky_entry));
+    d->len = d->used = 0;
+}
+
+static sky_value *dict_get_cstr(sky_value d, const char *key) {
+    return dict_lookup(DICT(d), key, strlen(key));
+}
+
+static void dict_set_cstr(sky_value d, const char *key, sky_value val) {
+    dict_put(DICT(d), key, strlen(key), val);
+}
+
+sky_value sky_dict(int n, sky_value *kv) {
+    sky_value d = obj_value(SKY_DICT, new_dict());
+    int i;
+    for (i = 0; i + 1 < n; i += 2) {
+        sky_value key = to_string(kv[i]);
+        dict_put(DICT(d), STR(key)->data, STR(key)->len, kv[i + 1]);
+    }
+    return d;
+}
+
+/* dict_keys returns the keys of a dict in sorted order */
+static sky_value dict_keys(sky_dict_obj *d) {
+    sky_entry **entries = sorted_entries(d);
+    sky_list_obj *l = new_list(d->len);
+    size_t i;
+    for (i = 0; i < d->len; i++) {
+        l->items[l->len++] = sky_str_len(entries[i]->key, entries[i]->klen);
+    }
+    free(entries);
+    return obj_value(SKY_LIST, l);
+}
+
+/* Operators. Integers wrap around like Go's. */
+
+static sky_value binary_op(sky_value a, sky_value b, const char *op) {
+    int nil_a = a.type == SKY_NIL, nil_b = b.type == SKY_NIL;
+    if (nil_a || nil_b) {
+        if (strcmp(op, "==") == 0) {
+            return sky_bool(nil_a && nil_b);
+        }
+        if (strcmp(op, "!=") == 0) {
+            return sky_bool(!(nil_a && nil_b));
+        }
+    }
+    sky_errorf("unsupported operation: %s %s %s", type_name(a), op, type_name(b));
+    return sky_nil();
+}
+
+/* concat_string converts the other operand of a string concatenation */
+static void write_concat(buffer *b, sky_value v) {
+    if (v.type == SKY_FLOAT) {
+        write_shortest(b, v.as.f);
+    } else {
+        write_value(b, v);
+    }
+}
+
+sky_value sky_add(sky_value a, sky_value b) {
+    if (a.type == SKY_INT && b.type == SKY_INT) {
+        return sky_int((int64_t)((uint64_t)a.as.i + (uint64_t)b.as.i));
+    }
+    if (a.type == SKY_FLOAT && b.type == SKY_FLOAT) {
+        return sky_float(a.as.f + b.as.f);
+    }
+    if (a.type == SKY_STR || b.type == SKY_STR) {
+        buffer buf = {0};
+        write_concat(&buf, a);
+        write_concat(&buf, b);
+        return buf_string(&buf);
+    }
+    return binary_op(a, b, "+");
+}
+
+sky_value sky_sub(sky_value a, sky_value b) {
+    if (a.type == SKY_INT && b.type == SKY_INT) {
+        return sky_int((int64_t)((uint64_t)a.as.i - (uint64_t)b.as.i));
+    }
+    if (a.type == SKY_FLOAT && b.type == SKY_FLOAT) {
+        return sky_float(a.as.f - b.as.f);
+    }
+    return binary_op(a, b, "-");
+}
+
+sky_value sky_mul(sky_value a, sky_value b) {
+    if (a.type == SKY_INT && b.type == SKY_INT) {
+        return sky_int((int64_t)((uint64_t)a.as.i * (uint64_t)b.as.i));
+    }
+    if (a.type == SKY_FLOAT && b.type == SKY_FLOAT) {
+        return sky_float(a.as.f * b.as.f);
+    }
+    return binary_op(a, b, "*");
+}
+
+sky_value sky_div(sky_value a, sky_value b) {
+    if (a.type == SKY_INT && b.type == SKY_INT) {
+        if (b.as.i == 0) {
+            sky_errorf("division by zero");
+        }
+        if (b.as.i == -1) {
+            return sky_int((int64_t)(0 - (uint64_t)a.as.i));
+        }
+        return sky_int(a.as.i / b.as.i);
+    }
+    if (a.type == SKY_FLOAT && b.type == SKY_FLOAT) {
+        return sky_float(a.as.f / b.as.f);
+    }
+    return binary_op(a, b, "/");
+}
+
+sky_value sky_mod(sky_value a, sky_value b) {
+    if (a.type == SKY_INT && b.type == SKY_INT) {
+        if (b.as.i == 0) {
+            sky_errorf("division by zero");
+        }
+        if (b.as.i == -1) {
+            return sky_int(0);
+        }
+        return sky_int(a.as.i % b.as.i);
+    }
+    return binary_op(a, b, "%");
+}
+
+static int str_equal(sky_value a, sky_value b) {
+    return STR(a)->len == STR(b)->len && memcmp(STR(a)->data, STR(b)->data, STR(a)->len) == 0;
+}
+
+sky_value sky_eq(sky_value a, sky_value b) {
+    if (a.type == SKY_INT && b.type == SKY_INT) {
+        return sky_bool(a.as.i == b.as.i);
+    }
+    if (a.type == SKY_STR && b.type == SKY_STR) {
+        return sky_bool(str_equal(a, b));
+    }
+    return binary_op(a, b, "==");
+}
+
+sky_value sky_ne(sky_value a, sky_value b) {
+    if (a.type == SKY_INT && b.type == SKY_INT) {
+        return sky_bool(a.as.i != b.as.i);
+    }
+    if (a.type == SKY_STR && b.type == SKY_STR) {
+        return sky_bool(!str_equal(a, b));
+    }
+    return binary_op(a, b, "!=");
+}
+
+sky_value sky_lt(sky_value a, sky_value b) {
+    if (a.type == SKY_INT && b.type == SKY_INT) {
+        return sky_bool(a.as.i < b.as.i);
+    }
+    return binary_op(a, b, "<");
+}
+
+sky_value sky_le(sky_value a, sky_value b) {
+    if (a.type == SKY_INT && b.type == SKY_INT) {
+        return sky_bool(a.as.i <= b.as.i);
+    }
+    return binary_op(a, b, "<=");
+}
+
+sky_value sky_gt(sky_value a, sky_value b) {
+    if (a.type == SKY_INT && b.type == SKY_INT) {
+        return sky_bool(a.as.i > b.as.i);
+    }
+    return binary_op(a, b, ">");
+}
+
+sky_value sky_ge(sky_value a, sky_value b) {
+    if (a.type == SKY_INT && b.type == SKY_INT) {
+        return sky_bool(a.as.i >= b.as.i);
+    }
+    return binary_op(a, b, ">=");
+}
+
+sky_value sky_neg(sky_value v) {
+    if (v.type == SKY_INT) {
+        return sky_int((int64_t)(0 - (uint64_t)v.as.i));
+    }
+    if (v.type == SKY_FLOAT) {
+        return sky_float(-v.as.f);
+    }
+    sky_errorf("operator - can only be applied to numbers");
+    return sky_nil();
+}
+
+sky_value sky_not(sky_value v) {
+    return sky_bool(!sky_truthy(v));
+}
+
+sky_value sky_index(sky_value obj, sky_value index) {
+    if (obj.type == SKY_LIST && index.type == SKY_INT) {
+        if (index.as.i < 0 || (uint64_t)index.as.i >= LIST(obj)->len) {
+            sky_errorf("list index out of range");
+        }
+        return LIST(obj)->items[index.as.i];
+    }
+    if (obj.type == SKY_DICT) {
+        sky_value key = to_string(index);
+        sky_value *val = dict_lookup(DICT(obj), STR(key)->data, STR(key)->len);
+        return val ? *val : sky_nil();
+    }
+    sky_errorf("index operation not supported");
+    return sky_nil();
+}
+
+sky_value sky_set_index(sky_value obj, sky_value index, sky_value val) {
+    if (obj.type == SKY_LIST && index.type == SKY_INT) {
+        if (index.as.i < 0 || (uint64_t)index.as.i >= LIST(obj)->len) {
+            sky_errorf("list index out of range");
+        }
+        LIST(obj)->items[index.as.i] = val;
+        return val;
+    }
+    if (obj.type == SKY_DICT) {
+        sky_value key = to_string(index);
+        dict_put(DICT(obj), STR(key)->data, STR(key)->len, val);
+        return val;
+    }
+    sky_errorf("index assignment not supported");
+    return sky_nil();
+}
+
+/* Functions and calls */
+
+#define MAX_DEPTH (1 << 16)
+
+static sky_func *new_func(const char *name) {
+    sky_func *f = alloc_obj(SKY_FUNC, sizeof(sky_func));
+    f->name = name;
+    f->recv = sky_nil();
+    return f;
+}
+
+sky_value sky_closure(sky_code code, const char *name, int arity, int flags, sky_env *env) {
+    sky_func *f = new_func(name);
+    f->code = code;
+    f->arity = arity;
+    f->flags = flags;
+    f->env = env;
+    return obj_value(SKY_FUNC, f);
+}
+
+sky_env *sky_env_new(sky_env *parent, int n) {
+    sky_env *e = alloc_obj(SKY_ENV_KIND, sizeof(sky_env) + (size_t)n * sizeof(sky_value));
+    int i;
+    e->parent = parent;
+    e->n = n;
+    for (i = 0; i < n; i++) {
+        e->v[i] = sky_nil();
+    }
+    return e;
+}
+
+sky_value sky_arg(int argc, sky_value *argv, int i) {
+    return i < argc ? argv[i] : sky_nil();
+}
+
+sky_value sky_rest(int argc, sky_value *argv, int i) {
+    if (i >= argc) {
+        return sky_list(0, NULL);
+    }
+    return sky_list(argc - i, argv + i);
+}
+
+static sky_value call_func(sky_func *fn, sky_value self, int argc, sky_value *argv);
+static sky_value instantiate(sky_class *c, int argc, sky_value *argv);
+
+/* run_async runs an async function and returns its settled promise.
+ * Nothing in a compiled program waits on I/O in the background, so the
+ * body always runs to completion. */
+static sky_value run_async(sky_func *fn, sky_value self, int argc, sky_value *argv) {
+    sky_promise *p = alloc_obj(SKY_PROMISE, sizeof(sky_promise));
+    sky_handler h;
+    p->value = sky_nil();
+    p->error = sky_nil();
+    sky_try(&h);
+    if (setjmp(h.jb) == 0) {
+        p->value = fn->code(fn, self, argc, argv);
+        sky_untry(&h);
+    } else {
+        p->error = h.error;
+    }
+    return obj_value(SKY_PROMISE, p);
+}
+
+static size_t stack_used(const char *here) {
+    return here < stack_base ? (size_t)(stack_base - here) : (size_t)(here - stack_base);
+}
+
+static sky_value call_func(sky_func *fn, sky_value self, int argc, sky_value *argv) {
+    char here;
+    sky_value result;
+    if (depth >= MAX_DEPTH || (stack_base && stack_used(&here) > stack_limit)) {
+        sky_errorf("stack overflow: maximum call depth (%d) exceeded in function '%s'", MAX_DEPTH, fn->name);
+    }
+    depth++;
+    if (fn->target != NULL) {
+        if (fn->prepend) {
+            sky_value *args = xmalloc(((size_t)argc + 1) * sizeof(sky_value));
+            volatile sky_value list;
+            int i;
+            args[0] = fn->recv;
+            for (i = 0; i < argc; i++) {
+                args[i + 1] = argv[i];
+            }
+            /* The arguments are kept reachable in a list during the call */
+            list = sky_list(argc + 1, args);
+            free(args);
+            result = call_func(fn->target, sky_nil(), argc + 1, LIST(list)->items);
+        } else {
+            result = call_func(fn->target, fn->recv, argc, argv);
+        }
+    } else if (fn->builtin != NULL) {
+        result = fn->builtin(argc, argv);
+    } else if (fn->enum_name != NULL) {
+        sky_variant_obj *v;
+        int i;
+        if (argc != fn->payload) {
+            sky_errorf("%s expects %d arguments, got %d", fn->name, fn->payload, argc);
+        }
+        v = alloc_obj(SKY_VARIANT, sizeof(sky_variant_obj) + (size_t)argc * sizeof(sky_value));
+        v->enum_name = fn->enum_name;
+        v->name = fn->name;
+        v->n = argc;
+        for (i = 0; i < argc; i++) {
+            v->payload[i] = argv[i];
+        }
+        result = obj_value(SKY_VARIANT, v);
+    } else if (fn->flags & SKY_ASYNC) {
+        result = run_async(fn, self, argc, argv);
+    } else {
+        result = fn->code(fn, self, argc, argv);
+    }
+    depth--;
+    return result;
+}
+
+sky_value sky_call(const char *name, sky_value callee, int argc, sky_value *argv) {
+    if (callee.type == SKY_FUNC) {
+        return call_func(FUNC(callee), sky_nil(), argc, argv);
+    }
+    if (callee.type == SKY_CLASS && !CLASS(callee)->abstract) {
+        return instantiate(CLASS(callee), argc, argv);
+    }
+    if (name == NULL || name[0] == '\0') {
+        name = sky_cstr(callee);
+    }
+    sky_errorf("%s is not a function or class", name);
+    return sky_nil();
+}
+
+sky_value sky_await(sky_value v) {
+    if (v.type != SKY_PROMISE) {
+        return v;
+    }
+    if (PROMISE(v)->error.type != SKY_NIL) {
+        raise_error(PROMISE(v)->error);
+    }
+    return PROMISE(v)->value;
+}
+
+/* Built-ins */
+
+typedef struct {
+    const char *name;
+    sky_builtin_fn fn;
+} builtin_entry;
+
+#define SKY_BUILTIN(name) {#name, sky_b_##name},
+static const builtin_entry builtin_table[] = {
+#include "builtins.def"
+};
+#undef SKY_BUILTIN
+
+#define NBUILTINS (sizeof builtin_table / sizeof builtin_table[0])
+
+static sky_func *builtin_funcs[NBUILTINS];
+
+static int compare_builtin(const void *key, const void *entry) {
+    return strcmp((const char *)key, ((const builtin_entry *)entry)->name);
+}
+
+/* find_builtin returns the function of a built-in, or NULL */
+static sky_func *find_builtin(const char *name) {
+    const builtin_entry *e = bsearch(name, builtin_table, NBUILTINS, sizeof builtin_table[0], compare_builtin);
+    size_t i;
+    if (e == NULL) {
+        return NULL;
+    }
+    i = (size_t)(e - builtin_table);
+    if (builtin_funcs[i] == NULL) {
+        sky_func *f = xmalloc(sizeof(sky_func));
+        memset(f, 0, sizeof(sky_func));
+        f->obj.size = sizeof(sky_func);
+        f->obj.kind = SKY_FUNC;
+        f->obj.perm = 1;
+        f->name = e->name;
+        f->builtin = e->fn;
+        f->recv = sky_nil();
+        builtin_funcs[i] = f;
+    }
+    return builtin_funcs[i];
+}
+
+/* method_builtin returns the built-in implementing method name of
+ * strings, lists or dicts, named with prefix */
+static sky_func *method_builtin(const char *prefix, const char *name) {
+    char key[128];
+    if (strlen(prefix) + strlen(name) >= sizeof key) {
+        return NULL;
+    }
+    strcpy(key, prefix);
+    strcat(key, name);
+    return find_builtin(key);
+}
+
+sky_value sky_builtin(const char *name) {
+    sky_func *f = find_builtin(name);
+    if (f == NULL) {
+        sky_errorf("undefined: %s", name);
+    }
+    return obj_value(SKY_FUNC, f);
+}
+
+sky_value sky_global(sky_value v, const char *name) {
+    if (v.type != SKY_UNDEF) {
+        return v;
+    }
+    return sky_builtin(name);
+}
+
+/* Classes */
+
+sky_value sky_class_new(const char *name, int abstract, int nsupers, sky_value *supers) {
+    sky_class *c;
+    int i;
+    for (i = 0; i < nsupers; i++) {
+        if (supers[i].type != SKY_CLASS) {
+            sky_errorf("%s is not a class", sky_cstr(supers[i]));
+        }
+    }
+    c = alloc_obj(SKY_CLASS, sizeof(sky_class));
+    c->name = name;
+    c->abstract = abstract;
+    c->methods = sky_nil();
+    c->interfaces = sky_nil();
+    c->supers = xmalloc(((size_t)nsupers + 1) * sizeof(sky_class *));
+    for (i = 0; i < nsupers; i++) {
+        c->supers[i] = CLASS(supers[i]);
+    }
+    c->nsupers = nsupers;
+    c->methods = obj_value(SKY_DICT, new_dict());
+    c->interfaces = sky_list(0, NULL);
+    return obj_value(SKY_CLASS, c);
+}
+
+void sky_class_method(sky_value cls, const char *name, sky_value fn) {
+    FUNC(fn)->owner = CLASS(cls);
+    dict_set_cstr(CLASS(cls)->methods, name, fn);
+}
+
+/* find_method looks up a method on a class and its superclasses */
+static sky_func *find_method(sky_class *c, const char *name) {
+    sky_value *m = dict_get_cstr(c->methods, name);
+    int i;
+    if (m != NULL) {
+        return FUNC(*m);
+    }
+    for (i = 0; i < c->nsupers; i++) {
+        sky_func *f = find_method(c->supers[i], name);
+        if (f != NULL) {
+            return f;
+        }
+    }
+    return NULL;
+}
+
+static sky_func *own_method(sky_class *c, const char *name) {
+    sky_value *m = dict_get_cstr(c->methods, name);
+    return m ? FUNC(*m) : NULL;
+}
+
+/* is_subclass reports whether c is target or inherits from it */
+static int is_subclass(sky_class *c, sky_class *target) {
+    int i;
+    if (c == target || strcmp(c->name, target->name) == 0) {
+        return 1;
+    }
+    for (i = 0; i < c->nsupers; i++) {
+        if (is_subclass(c->supers[i], target)) {
+            return 1;
+        }
+    }
+    return 0;
+}
+
+/* instantiate creates an instance and runs the init methods of the
+ * direct superclasses, then its own */
+static sky_value instantiate(sky_class *c, int argc, sky_value *argv) {
+    sky_instance *inst = alloc_obj(SKY_INSTANCE, sizeof(sky_instance));
+    sky_value v = obj_value(SKY_INSTANCE, inst);
+    sky_func *init;
+    int i;
+    inst->cls = c;
+    inst->fields = sky_nil();
+    inst->fields = obj_value(SKY_DICT, new_dict());
+    for (i = 0; i < c->nsupers; i++) {
+        if ((init = own_method(c->supers[i], "init")) != NULL) {
+            call_func(init, v, argc, argv);
+        }
+    }
+    if ((init = own_method(c, "init")) != NULL) {
+        call_func(init, v, argc, argv);
+    }
+    return v;
+}
+
+/* Interfaces */
+
+sky_value sky_interface_new(const char *name, int nparents, sky_value *parents,
+                            int nmethods, const char **methods, const int *arities) {
+    sky_iface *it;
+    sky_value v;
+    int i;
+    for (i = 0; i < nparents; i++) {
+        if (parents[i].type != SKY_INTERFACE) {
+            sky_errorf("%s is not an interface", sky_cstr(parents[i]));
+        }
+    }
+    it = alloc_obj(SKY_INTERFACE, sizeof(sky_iface));
+    v = obj_value(SKY_INTERFACE, it);
+    it->name = name;
+    it->methods = sky_nil();
+    it->extends = sky_list(nparents, parents);
+    it->methods = obj_value(SKY_DICT, new_dict());
+    for (i = 0; i < nmethods; i++) {
+        dict_set_cstr(it->methods, methods[i], sky_int(arities[i]));
+    }
+    return v;
+}
+
+/* all_methods collects the required methods of an interface and its
+ * parents into a dict of arities */
+static void all_methods(sky_iface *it, sky_value into) {
+    size_t i;
+    for (i = 0; i < LIST(it->extends)->len; i++) {
+        all_methods(IFACE(LIST(it->extends)->items[i]), into);
+    }
+    for (i = 0; i < DICT(it->methods)->cap; i++) {
+        sky_entry *e = &DICT(it->methods)->entries[i];
+        if (is_entry(e)) {
+            dict_put(DICT(into), e->key, e->klen, e->val);
+        }
+    }
+}
+
+/* missing_methods returns the sorted names of the methods a class does
+ * not provide, joined with ", ", or an empty string */
+static sky_value missing_methods(sky_iface *it, sky_class *c) {
+    sky_value methods = obj_value(SKY_DICT, new_dict());
+    sky_value names;
+    buffer b = {0};
+    size_t i;
+    all_methods(it, methods);
+    names = dict_keys(DICT(methods));
+    for (i = 0; i < LIST(names)->len; i++) {
+        sky_value name = LIST(names)->items[i];
+        sky_func *m = find_method(c, STR(name)->data);
+        if (m == NULL || m->arity != dict_get_cstr(methods, STR(name)->data)->as.i) {
+            if (b.len > 0) {
+                buf_puts(&b, ", ");
+            }
+            buf_write(&b, STR(name)->data, STR(name)->len);
+        }
+    }
+    return buf_string(&b);
+}
+
+void sky_class_implements(sky_value cls, const char *name, sky_value iface) {
+    sky_value missing;
+    if (iface.type != SKY_INTERFACE) {
+        sky_errorf("%s is not an interface", name);
+    }
+    missing = missing_methods(IFACE(iface), CLASS(cls));
+    if (STR(missing)->len > 0) {
+        sky_errorf("class %s does not implement %s: missing method %s",
+                   CLASS(cls)->name, IFACE(iface)->name, STR(missing)->data);
+    }
+    list_push(LIST(CLASS(cls)->interfaces), iface);
+}
+
+/* Enums */
+
+sky_value sky_variant(const char *enum_name, const char *name, int payload) {
+    sky_func *f = new_func(name);
+    f->enum_name = enum_name;
+    f->payload = payload;
+    return obj_value(SKY_FUNC, f);
+}
+
+sky_value sky_enum_new(const char *name, int n, sky_value *ctors) {
+    sky_enum_obj *e = alloc_obj(SKY_ENUM, sizeof(sky_enum_obj));
+    sky_value v = obj_value(SKY_ENUM, e);
+    int i;
+    e->name = name;
+    e->variants = sky_nil();
+    e->variants = obj_value(SKY_DICT, new_dict());
+    for (i = 0; i < n; i++) {
+        dict_set_cstr(e->variants, FUNC(ctors[i])->name, ctors[i]);
+    }
+    return v;
+}
+
+/* Members and methods */
+
+/* bind returns a function calling target with recv, as self or as the
+ * first argument */
+static sky_value bind(sky_func *target, const char *name, sky_value recv, int prepend) {
+    sky_func *f = new_func(name);
+    f->arity = target->arity;
+    f->flags = target->flags & SKY_ASYNC;
+    f->target = target;
+    f->recv = recv;
+    f->prepend = prepend;
+    return obj_value(SKY_FUNC, f);
+}
+
+sky_value sky_get_member(sky_value obj, const char *name) {
+    sky_value *val;
+    sky_func *f;
+    switch (obj.type) {
+    case SKY_INSTANCE:
+        if ((val = dict_get_cstr(INST(obj)->fields, name)) != NULL) {
+            return *val;
+        }
+        if ((f = find_method(INST(obj)->cls, name)) != NULL) {
+            return bind(f, f->name, obj, 0);
+        }
+        sky_errorf("undefined property: %s", name);
+        break;
+    case SKY_CLASS:
+        if ((val = dict_get_cstr(CLASS(obj)->methods, name)) != NULL) {
+            return *val;
+        }
+        sky_errorf("undefined method: %s", name);
+        break;
+    case SKY_DICT:
+        if ((val = dict_get_cstr(obj, name)) != NULL) {
+            return *val;
+        }
+        if ((f = method_builtin("dict_", name)) != NULL) {
+            return bind(f, name, obj, 1);
+        }
+        return sky_nil();
+    case SKY_STR:
+        if ((f = method_builtin("str_", name)) != NULL) {
+            return bind(f, name, obj, 1);
+        }
+        break;
+    case SKY_LIST:
+        if ((f = method_builtin("list_", name)) != NULL) {
+            return bind(f, name, obj, 1);
+        }
+        break;
+    default:
+        break;
+    }
+    sky_errorf("cannot access member of %s", type_name(obj));
+    return sky_nil();
+}
+
+sky_value sky_set_member(sky_value obj, const char *name, sky_value val) {
+    if (obj.type != SKY_INSTANCE) {
+        sky_errorf("can only assign to instance members");
+    }
+    dict_set_cstr(INST(obj)->fields, name, val);
+    return val;
+}
+
+/* call_builtin_method calls a built-in with recv as first argument */
+static sky_value call_builtin_method(sky_func *f, sky_value recv, int argc, sky_value *argv) {
+    /* volatile keeps the list, and so its items, visible to the collector */
+    volatile sky_value args = sky_list(0, NULL);
+    int i;
+    list_push(LIST(args), recv);
+    for (i = 0; i < argc; i++) {
+        list_push(LIST(args), argv[i]);
+    }
+    return call_func(f, sky_nil(), argc + 1, LIST(args)->items);
+}
+
+sky_value sky_invoke(sky_value self, sky_value recv, const char *name, int argc, sky_value *argv) {
+    sky_value *val;
+    sky_func *f;
+    switch (recv.type) {
+    case SKY_INSTANCE:
+        /* Fields holding functions are called without self */
+        if ((val = dict_get_cstr(INST(recv)->fields, name)) != NULL) {
+            return sky_call(name, *val, argc, argv);
+        }
+        if ((f = find_method(INST(recv)->cls, name)) == NULL) {
+            sky_errorf("undefined method: %s", name);
+        }
+        return call_func(f, recv, argc, argv);
+    case SKY_CLASS:
+        if ((val = dict_get_cstr(CLASS(recv)->methods, name)) == NULL) {
+            sky_errorf("undefined method: %s", name);
+        }
+        if (self.type == SKY_UNDEF) {
+            self = sky_nil();
+        }
+        return call_func(FUNC(*val), self, argc, argv);
+    case SKY_DICT:
+        if ((val = dict_get_cstr(recv, name)) != NULL) {
+            return sky_call(name, *val, argc, argv);
+        }
+        if ((f = method_builtin("dict_", name)) != NULL) {
+            return call_builtin_method(f, recv, argc, argv);
+        }
+        break;
+    case SKY_STR:
+        if ((f = method_builtin("str_", name)) != NULL) {
+            return call_builtin_method(f, recv, argc, argv);
+        }
+        break;
+    case SKY_LIST:
+        if ((f = method_builtin("list_", name)) != NULL) {
+            return call_builtin_method(f, recv, argc, argv);
+        }
+        break;
+    default:
+        break;
+    }
+    return sky_call(name, sky_get_member(recv, name), argc, argv);
+}
+
+sky_value sky_super_invoke(sky_class *cls, sky_value self, const char *name, int argc, sky_value *argv) {
+    int i;
+    if (cls != NULL) {
+        for (i = 0; i < cls->nsupers; i++) {
+            sky_func *f = find_method(cls->supers[i], name);
+            if (f != NULL) {
+                return call_func(f, self, argc, argv);
+            }
+        }
+    }
+    sky_errorf("undefined method: %s", name);
+    return sky_nil();
+}
+
+sky_value sky_super(sky_class *cls) {
+    if (cls != NULL && cls->nsupers > 0) {
+        return obj_value(SKY_CLASS, cls->supers[0]);
+    }
+    return sky_nil();
+}
+
+/* Iteration. Lists are copied first, dicts yield their keys in sorted
+ * order and strings their characters. Instances iterate with __iter__
+ * and __next__; an error from __next__ ends the loop. */
+
+enum { ITER_LIST, ITER_STR, ITER_INSTANCE, ITER_DONE };
+
+void sky_iter_init(sky_iter *it, sky_value iterable) {
+    sky_func *f;
+    it->idx = 0;
+    it->value = sky_nil();
+    it->str = sky_nil();
+    it->items = sky_nil();
+    switch (iterable.type) {
+    case SKY_LIST:
+        it->kind = ITER_LIST;
+        it->items = sky_list((int)LIST(iterable)->len, LIST(iterable)->items);
+        return;
+    case SKY_DICT:
+        it->kind = ITER_LIST;
+        it->items = dict_keys(DICT(iterable));
+        return;
+    case SKY_STR:
+        it->kind = ITER_STR;
+        it->str = iterable;
+        return;
+    case SKY_INSTANCE:
+        if ((f = find_method(INST(iterable)->cls, "__iter__")) == NULL) {
+            break;
+        }
+        it->items = f->code(f, iterable, 0, NULL);
+        it->kind = it->items.type == SKY_INSTANCE ? ITER_INSTANCE : ITER_DONE;
+        return;
+    default:
+        break;
+    }
+    sky_errorf("%s is not iterable", type_name(iterable));
+}
+
+/* utf8_len returns the length of the UTF-8 sequence starting at s */
+static size_t utf8_len(const unsigned char *s, size_t n) {
+    size_t len = 1, i;
+    if (s[0] >= 0xf0) {
+        len = 4;
+    } else if (s[0] >= 0xe0) {
+        len = 3;
+    } else if (s[0] >= 0xc0) {
+        len = 2;
+    }
+    if (len > n) {
+        return 1;
+    }
+    for (i = 1; i < len; i++) {
+        if ((s[i] & 0xc0) != 0x80) {
+            return 1;
+        }
+    }
+    return len;
+}
+
+int sky_iter_next(sky_iter *it) {
+    switch (it->kind) {
+    case ITER_LIST:
+        if (it->idx >= LIST(it->items)->len) {
+            return 0;
+        }
+        it->value = LIST(it->items)->items[it->idx++];
+        return 1;
+    case ITER_STR: {
+        sky_string *s = STR(it->str);
+        size_t n;
+        if (it->idx >= s->len) {
+            return 0;
+        }
+        n = utf8_len((const unsigned char *)s->data + it->idx, s->len - it->idx);
+        it->value = sky_str_len(s->data + it->idx, n);
+        it->idx += n;
+        return 1;
+    }
+    case ITER_INSTANCE: {
+        sky_func *f = find_method(INST(it->items)->cls, "__next__");
+        sky_handler h;
+        if (f == NULL) {
+            return 0;
+        }
+        sky_try(&h);
+        if (setjmp(h.jb) != 0) {
+            it->kind = ITER_DONE;
+            return 0;
+        }
+        it->value = f->code(f, it->items, 0, NULL);
+        sky_untry(&h);
+        return 1;
+    }
+    }
+    return 0;
+}
+
+/* Pattern tests */
+
+int sky_pattern_equal(sky_value expected, sky_value value, int numeric) {
+    switch (expected.type) {
+    case SKY_INT:
+        if (value.type == SKY_INT) {
+            return expected.as.i == value.as.i;
+        }
+        return numeric && value.type == SKY_FLOAT && (double)expected.as.i == value.as.f;
+    case SKY_FLOAT:
+        if (value.type == SKY_FLOAT) {
+            return expected.as.f == value.as.f;
+        }
+        return numeric && value.type == SKY_INT && expected.as.f == (double)value.as.i;
+    case SKY_STR:
+        return value.type == SKY_STR && str_equal(expected, value);
+    case SKY_BOOL:
+        return value.type == SKY_BOOL && expected.as.b == value.as.b;
+    case SKY_NIL:
+        return value.type == SKY_NIL;
+    default:
+        return 0;
+    }
+}
+
+int sky_test_variant(sky_value v, const char *name, int payload, const char *enum_name) {
+    if (v.type != SKY_VARIANT || strcmp(VARIANT(v)->name, name) != 0) {
+        return 0;
+    }
+    if (payload >= 0 && VARIANT(v)->n != payload) {
+        return 0;
+    }
+    return enum_name[0] == '\0' || strcmp(VARIANT(v)->enum_name, enum_name) == 0;
+}
+
+int sky_test_list(sky_value v, int n, int rest) {
+    if (v.type != SKY_LIST) {
+        return 0;
+    }
+    return rest ? LIST(v)->len >= (size_t)n : LIST(v)->len == (size_t)n;
+}
+
+int sky_test_dict(sky_value v) {
+    return v.type == SKY_DICT;
+}
+
+int sky_test_key(sky_value v, sky_value key) {
+    sky_value k;
+    if (v.type != SKY_DICT) {
+        return 0;
+    }
+    k = to_string(key);
+    return dict_lookup(DICT(v), STR(k)->data, STR(k)->len) != NULL;
+}
+
+int sky_test_field(sky_value v, const char *name) {
+    return v.type == SKY_INSTANCE && dict_get_cstr(INST(v)->fields, name) != NULL;
+}
+
+int sky_test_instance(sky_value v, sky_value cls, const char *name) {
+    if (cls.type != SKY_CLASS) {
+        sky_errorf("%s is not a class", name);
+    }
+    return v.type == SKY_INSTANCE && is_subclass(INST(v)->cls, CLASS(cls));
+}
+
+sky_value sky_payload(sky_value v, int i) {
+    return VARIANT(v)->payload[i];
+}
+
+sky_value sky_element(sky_value v, int i) {
+    if (i < 0) {
+        i += (int)LIST(v)->len;
+    }
+    return LIST(v)->items[i];
+}
+
+sky_value sky_slice(sky_value v, int from, int tail) {
+    return sky_list((int)LIST(v)->len - from - tail, LIST(v)->items + from);
+}
+
+/* Programs and modules */
+
+int sky_main(sky_script script) {
+    char base;
+    sky_handler h;
+    start(&base);
+    srand((unsigned)time(NULL));
+    sky_try(&h);
+    if (setjmp(h.jb) == 0) {
+        script();
+        sky_untry(&h);
+        fflush(stdout);
+        return 0;
+    }
+    fflush(stdout);
+    fprintf(stderr, "Runtime error: %s\n", STR(h.error)->data);
+    return 1;
+}
+
+sky_value sky_import(const char *path, int *loaded, sky_script script, sky_value (*exports)(void)) {
+    if (!*loaded) {
+        sky_handler h;
+        sky_try(&h);
+        if (setjmp(h.jb) != 0) {
+            sky_errorf("error in module %s: %s", path, STR(h.error)->data);
+        }
+        script();
+        sky_untry(&h);
+        *loaded = 1;
+    }
+    return exports();
+}
+
+sky_value sky_namespace(void) {
+    return obj_value(SKY_DICT, new_dict());
+}
+
+/* sky_export adds a global to a module namespace; globals the script
+ * never assigned and private names are left out */
+void sky_export(sky_value ns, const char *name, sky_value v) {
+    if (v.type != SKY_UNDEF && name[0] != '_') {
+        dict_set_cstr(ns, name, v);
+    }
+}
+
+/* Libraries */
+
+static char *lib_error;
+static int lib_depth, lib_loaded;
+
+const char *sky_error(void) {
+    return lib_error;
+}
+
+static void lib_enter(char *base) {
+    if (lib_depth++ == 0) {
+        start(base);
+        free(lib_error);
+        lib_error = NULL;
+    }
+}
+
+static void lib_leave(sky_handler *h, int failed) {
+    if (failed) {
+        const char *msg = STR(h->error)->data;
+        free(lib_error);
+        lib_error = xmalloc(strlen(msg) + 1);
+        strcpy(lib_error, msg);
+    }
+    if (--lib_depth == 0) {
+        gc_enabled = 0;
+        fflush(stdout);
+    }
+}
+
+void sky_lib_init(sky_script script) {
+    char base;
+    sky_handler h;
+    lib_enter(&base);
+    sky_try(&h);
+    if (setjmp(h.jb) == 0) {
+        if (!lib_loaded) {
+            lib_loaded = 1;
+            script();
+        }
+        sky_untry(&h);
+        lib_leave(&h, 0);
+        return;
+    }
+    lib_leave(&h, 1);
+}
+
+sky_value sky_lib_call(sky_script script, sky_value *fn, const char *name, int argc, sky_value *argv) {
+    char base;
+    sky_handler h;
+    volatile sky_value result = sky_nil();
+    lib_enter(&base);
+    sky_root(argv, (size_t)argc);
+    sky_try(&h);
+    if (setjmp(h.jb) == 0) {
+        if (!lib_loaded) {
+            lib_loaded = 1;
+            script();
+        }
+        result = sky_call(name, sky_global(*fn, name), argc, argv);
+        sky_untry(&h);
+        roots_len--;
+        lib_leave(&h, 0);
+        return result;
+    }
+    roots_len--;
+    lib_leave(&h, 1);
+    return result;
+}
+
+/* Built-in functions. Each takes its arguments as a C array; string,
+ * list and dict methods get the receiver as first argument. */
+
+static int str_arg(int argc, sky_value *argv, int i) {
+    return i < argc && argv[i].type == SKY_STR;
+}
+
+/* str_args reports whether the first n arguments are strings */
+static int str_args(int argc, sky_value *argv, int n) {
+    int i;
+    if (argc < n) {
+        return 0;
+    }
+    for (i = 0; i < n; i++) {
+        if (argv[i].type != SKY_STR) {
+            return 0;
+        }
+    }
+    return 1;
+}
+
+#define S(i) (STR(argv[i])->data)
+#define SLEN(i) (STR(argv[i])->len)
+
+sky_value sky_b_print(int argc, sky_value *argv) {
+    int i;
+    for (i = 0; i < argc; i++) {
+        buffer b = {0};
+        if (i > 0) {
+            putchar(' ');
+        }
+        if (argv[i].type == SKY_STR) {
+            fwrite(STR(argv[i])->data, 1, STR(argv[i])->len, stdout);
+            continue;
+        }
+        write_value(&b, argv[i]);
+        if (b.len > 0) {
+            fwrite(b.data, 1, b.len, stdout);
+        }
+        free(b.data);
+    }
+    putchar('\n');
+    return sky_nil();
+}
+
+sky_value sky_b_len(int argc, sky_value *argv) {
+    if (argc > 0) {
+        switch (argv[0].type) {
+        case SKY_STR:
+            return sky_int((int64_t)STR(argv[0])->len);
+        case SKY_LIST:
+            return sky_int((int64_t)LIST(argv[0])->len);
+        case SKY_DICT:
+            return sky_int((int64_t)DICT(argv[0])->len);
+        default:
+            break;
+        }
+    }
+    return sky_int(0);
+}
+
+sky_value sky_b_range(int argc, sky_value *argv) {
+    sky_list_obj *l;
+    int64_t i;
+    if (argc == 0 || argv[0].type != SKY_INT || argv[0].as.i <= 0) {
+        return sky_list(0, NULL);
+    }
+    l = new_list((size_t)argv[0].as.i);
+    for (i = 0; i < argv[0].as.i; i++) {
+        l->items[i] = sky_int(i);
+    }
+    l->len = (size_t)argv[0].as.i;
+    return obj_value(SKY_LIST, l);
+}
+
+/* Type conversions */
+
+/* float_to_int truncates f, saturating like Go on amd64 */
+static int64_t float_to_int(double f) {
+    if (f != f || f >= 9223372036854775808.0 || f < -9223372036854775808.0) {
+        return INT64_MIN;
+    }
+    return (int64_t)f;
+}
+
+sky_value sky_b_int(int argc, sky_value *argv) {
+    if (argc == 0) {
+        return sky_int(0);
+    }
+    switch (argv[0].type) {
+    case SKY_INT:
+        return argv[0];
+    case SKY_FLOAT:
+        return sky_int(float_to_int(argv[0].as.f));
+    case SKY_STR: {
+        int base = 10;
+        const char *s = S(0);
+        char *end;
+        long long n;
+        if (argc >= 2 && argv[1].type == SKY_INT) {
+            base = (int)argv[1].as.i;
+        }
+        errno = 0;
+        n = (base >= 2 && base <= 36 && *s != '\0' && !isspace((unsigned char)*s)) ? strtoll(s, &end, base) : 0;
+        if (base < 2 || base > 36 || *s == '\0' || isspace((unsigned char)*s) || *end != '\0' ||
+            errno != 0 || (size_t)(end - s) != SLEN(0) || (base == 16 && strchr(s, 'x')) || (base == 16 && strchr(s, 'X'))) {
+            sky_errorf("invalid literal for int(): %s", s);
+        }
+        return sky_int(n);
+    }
+    case SKY_BOOL:
+        return sky_int(argv[0].as.b ? 1 : 0);
+    default:
+        return sky_int(0);
+    }
+}
+
+sky_value sky_b_float(int argc, sky_value *argv) {
+    if (argc == 0) {
+        return sky_float(0);
+    }
+    switch (argv[0].type) {
+    case SKY_FLOAT:
+        return argv[0];
+    case SKY_INT:
+        return sky_float((double)argv[0].as.i);
+    case SKY_STR: {
+        const char *s = S(0);
+        char *end;
+        double f;
+        f = strtod(s, &end);
+        if (*s == '\0' || isspace((unsigned char)*s) || *end != '\0' || (size_t)(end - s) != SLEN(0)) {
+            sky_errorf("invalid literal for float(): %s", s);
+        }
+        return sky_float(f);
+    }
+    case SKY_BOOL:
+        return sky_float(argv[0].as.b ? 1 : 0);
+    default:
+        return sky_float(0);
+    }
+}
+
+sky_value sky_b_bool(int argc, sky_value *argv) {
+    return sky_bool(argc > 0 && sky_truthy(argv[0]));
+}
+
+sky_value sky_b_str(int argc, sky_value *argv) {
+    if (argc == 0) {
+        return sky_const("", 0);
+    }
+    return to_string(argv[0]);
+}
+
+sky_value sky_b_list(int argc, sky_value *argv) {
+    sky_iter it;
+    sky_value l;
+    if (argc == 0) {
+        return sky_list(0, NULL);
+    }
+    switch (argv[0].type) {
+    case SKY_LIST:
+    case SKY_STR:
+        l = sky_list(0, NULL);
+        sky_iter_init(&it, argv[0]);
+        while (sky_iter_next(&it)) {
+            list_push(LIST(l), it.value);
+        }
+        return l;
+    case SKY_DICT:
+        return dict_keys(DICT(argv[0]));
+    default:
+        return sky_list(1, argv);
+    }
+}
+
+sky_value sky_b_dict(int argc, sky_value *argv) {
+    sky_value d = obj_value(SKY_DICT, new_dict());
+    size_t i;
+    if (argc == 0) {
+        return d;
+    }
+    if (argv[0].type == SKY_DICT) {
+        sky_dict_obj *src = DICT(argv[0]);
+        for (i = 0; i < src->cap; i++) {
+            if (is_entry(&src->entries[i])) {
+                dict_put(DICT(d), src->entries[i].key, src->entries[i].klen, src->entries[i].val);
+            }
+        }
+    } else if (argv[0].type == SKY_LIST) {
+        for (i = 0; i < LIST(argv[0])->len; i++) {
+            sky_value pair = LIST(argv[0])->items[i];
+            if (pair.type == SKY_LIST && LIST(pair)->len >= 2) {
+                sky_value key = to_string(LIST(pair)->items[0]);
+                dict_put(DICT(d), STR(key)->data, STR(key)->len, LIST(pair)->items[1]);
+            }
+        }
+    }
+    return d;
+}
+
+/* Numeric functions */
+
+sky_value sky_b_abs(int argc, sky_value *argv) {
+    if (argc == 0) {
+        sky_errorf("abs() requires argument");
+    }
+    if (argv[0].type == SKY_INT) {
+        return argv[0].as.i < 0 ? sky_neg(argv[0]) : argv[0];
+    }
+    if (argv[0].type == SKY_FLOAT) {
+        return sky_float(fabs(argv[0].as.f));
+    }
+    sky_errorf("abs() requires numeric argument");
+    return sky_nil();
+}
+
+/* extreme returns the argument for which better holds against the
+ * others; only arguments of the first argument's type are compared */
+static sky_value extreme(int argc, sky_value *argv, int sign) {
+    sky_value best = argv[0];
+    int i;
+    for (i = 1; i < argc; i++) {
+        if (best.type == SKY_INT && argv[i].type == SKY_INT) {
+            if ((sign < 0 && argv[i].as.i < best.as.i) || (sign > 0 && argv[i].as.i > best.as.i)) {
+                best = argv[i];
+            }
+        } else if (best.type == SKY_FLOAT && argv[i].type == SKY_FLOAT) {
+            if ((sign < 0 && argv[i].as.f < best.as.f) || (sign > 0 && argv[i].as.f > best.as.f)) {
+                best = argv[i];
+            }
+        }
+    }
+    return best;
+}
+
+sky_value sky_b_min(int argc, sky_value *argv) {
+    if (argc == 0) {
+        sky_errorf("min() requires at least one argument");
+    }
+    return extreme(argc, argv, -1);
+}
+
+sky_value sky_b_max(int argc, sky_value *argv) {
+    if (argc == 0) {
+        sky_errorf("max() requires at least one argument");
+    }
+    return extreme(argc, argv, 1);
+}
+
+/* round_half_away rounds half away from zero like Go's math.Round */
+static double round_half_away(double x) {
+    double t = trunc(x);
+    if (fabs(x - t) >= 0.5) {
+        return t + (x < 0 ? -1 : 1);
+    }
+    return t;
+}
+
+sky_value sky_b_round(int argc, sky_value *argv) {
+    if (argc > 0 && argv[0].type == SKY_FLOAT) {
+        int digits = 0;
+        double multiplier;
+        if (argc >= 2 && argv[1].type == SKY_INT) {
+            digits = (int)argv[1].as.i;
+        }
+        multiplier = pow(10, digits);
+        return sky_float(round_half_away(argv[0].as.f * multiplier) / multiplier);
+    }
+    if (argc > 0 && argv[0].type == SKY_INT) {
+        return argv[0];
+    }
+    sky_errorf("round() requires numeric argument");
+    return sky_nil();
+}
+
+static int number(sky_value v, double *out) {
+    if (v.type == SKY_INT) {
+        *out = (double)v.as.i;
+        return 1;
+    }
+    if (v.type == SKY_FLOAT) {
+        *out = v.as.f;
+        return 1;
+    }
+    return 0;
+}
+
+sky_value sky_b_pow(int argc, sky_value *argv) {
+    double x, y, result;
+    if (argc < 2) {
+        sky_errorf("pow() requires two arguments");
+    }
+    if (!number(argv[0], &x) || !number(argv[1], &y)) {
+        sky_errorf("pow() requires numeric arguments");
+    }
+    result = pow(x, y);
+    /* Integer arguments give an integer when the result is whole */
+    if (argv[0].type == SKY_INT && argv[1].type == SKY_INT && result == floor(result)) {
+        return sky_int(float_to_int(result));
+    }
+    return sky_float(result);
+}
+
+sky_value sky_b_sqrt(int argc, sky_value *argv) {
+    double x;
+    if (argc == 0) {
+        sky_errorf("sqrt() requires argument");
+    }
+    if (!number(argv[0], &x)) {
+        sky_errorf("sqrt() requires numeric argument");
+    }
+    if (x < 0) {
+        sky_errorf("sqrt() of negative number");
+    }
+    return sky_float(sqrt(x));
+}
+
+sky_value sky_b_floor(int argc, sky_value *argv) {
+    if (argc > 0 && argv[0].type == SKY_FLOAT) {
+        return sky_int(float_to_int(floor(argv[0].as.f)));
+    }
+    if (argc > 0 && argv[0].type == SKY_INT) {
+        return argv[0];
+    }
+    sky_errorf("floor() requires numeric argument");
+    return sky_nil();
+}
+
+sky_value sky_b_ceil(int argc, sky_value *argv) {
+    if (argc > 0 && argv[0].type == SKY_FLOAT) {
+        return sky_int(float_to_int(ceil(argv[0].as.f)));
+    }
+    if (argc > 0 && argv[0].type == SKY_INT) {
+        return argv[0];
+    }
+    sky_errorf("ceil() requires numeric argument");
+    return sky_nil();
+}
+
+sky_value sky_b_sum(int argc, sky_value *argv) {
+    int64_t int_sum = 0;
+    double float_sum = 0;
+    int has_float = 0;
+    size_t i;
+    if (argc == 0 || argv[0].type != SKY_LIST) {
+        return sky_int(0);
+    }
+    for (i = 0; i < LIST(argv[0])->len; i++) {
+        sky_value v = LIST(argv[0])->items[i];
+        if (v.type == SKY_INT) {
+            if (has_float) {
+                float_sum += (double)v.as.i;
+            } else {
+                int_sum = (int64_t)((uint64_t)int_sum + (uint64_t)v.as.i);
+            }
+        } else if (v.type == SKY_FLOAT) {
+            if (!has_float) {
+                float_sum = (double)int_sum;
+                has_float = 1;
+            }
+            float_sum += v.as.f;
+        }
+    }
+    return has_float ? sky_float(float_sum) : sky_int(int_sum);
+}
+
+/* Utility functions */
+
+sky_value sky_b_input(int argc, sky_value *argv) {
+    buffer b = {0};
+    int c;
+    if (str_arg(argc, argv, 0)) {
+        fwrite(S(0), 1, SLEN(0), stdout);
+    }
+    fflush(stdout);
+    while ((c = getchar()) != EOF && c != '\n') {
+        char ch = (char)c;
+        buf_write(&b, &ch, 1);
+    }
+    if (c == EOF) {
+        free(b.data);
+        return sky_const("", 0);
+    }
+    if (b.len > 0 && b.data[b.len - 1] == '\r') {
+        b.len--;
+    }
+    return buf_string(&b);
+}
+
+/* kind_name is the name type() gives to the type of v, NULL for others */
+static const char *kind_name(sky_value v) {
+    switch (v.type) {
+    case SKY_INT:
+        return "int";
+    case SKY_FLOAT:
+        return "float";
+    case SKY_STR:
+        return "string";
+    case SKY_BOOL:
+        return "bool";
+    case SKY_LIST:
+        return "list";
+    case SKY_DICT:
+        return "dict";
+    case SKY_FUNC:
+        return "function";
+    case SKY_CLASS:
+        return CLASS(v)->abstract ? NULL : "class";
+    case SKY_INSTANCE:
+        return "instance";
+    default:
+        return NULL;
+    }
+}
+
+sky_value sky_b_type(int argc, sky_value *argv) {
+    const char *name;
+    if (argc == 0) {
+        return sky_str("nil");
+    }
+    switch (argv[0].type) {
+    case SKY_INTERFACE:
+        return sky_str("interface");
+    case SKY_PROMISE:
+        return sky_str("promise");
+    case SKY_NIL:
+        return sky_str("nil");
+    default:
+        break;
+    }
+    name = kind_name(argv[0]);
+    return sky_str(name ? name : "unknown");
+}
+
+sky_value sky_b_isinstance(int argc, sky_value *argv) {
+    int is_inst;
+    if (argc < 2) {
+        return sky_bool(0);
+    }
+    is_inst = argv[0].type == SKY_INSTANCE;
+    switch (argv[1].type) {
+    case SKY_CLASS:
+        return sky_bool(is_inst && is_subclass(INST(argv[0])->cls, CLASS(argv[1])));
+    case SKY_INTERFACE:
+        return sky_bool(is_inst && STR(missing_methods(IFACE(argv[1]), INST(argv[0])->cls))->len == 0);
+    case SKY_STR: {
+        const char *name = kind_name(argv[0]);
+        return sky_bool(name != NULL && strcmp(name, S(1)) == 0);
+    }
+    default:
+        return sky_bool(0);
+    }
+}
+
+/* Functional programming */
+
+sky_value sky_b_map(int argc, sky_value *argv) {
+    sky_value results;
+    size_t i;
+    if (argc < 2 || argv[0].type != SKY_FUNC || argv[1].type != SKY_LIST) {
+        sky_errorf("map() requires function and iterable");
+    }
+    results = sky_list(0, NULL);
+    for (i = 0; i < LIST(argv[1])->len; i++) {
+        sky_value item = LIST(argv[1])->items[i];
+        list_push(LIST(results), call_func(FUNC(argv[0]), sky_nil(), 1, &item));
+    }
+    return results;
+}
+
+sky_value sky_b_filter(int argc, sky_value *argv) {
+    sky_value results;
+    size_t i;
+    if (argc < 2 || argv[0].type != SKY_FUNC || argv[1].type != SKY_LIST) {
+        sky_errorf("filter() requires function and iterable");
+    }
+    results = sky_list(0, NULL);
+    for (i = 0; i < LIST(argv[1])->len; i++) {
+        sky_value item = LIST(argv[1])->items[i];
+        if (sky_truthy(call_func(FUNC(argv[0]), sky_nil(), 1, &item))) {
+            list_push(LIST(results), item);
+        }
+    }
+    return results;
+}
+
+sky_value sky_b_any(int argc, sky_value *argv) {
+    size_t i;
+    if (argc > 0 && argv[0].type == SKY_LIST) {
+        for (i = 0; i < LIST(argv[0])->len; i++) {
+            if (sky_truthy(LIST(argv[0])->items[i])) {
+                return sky_bool(1);
+            }
+        }
+    }
+    return sky_bool(0);
+}
+
+sky_value sky_b_all(int argc, sky_value *argv) {
+    size_t i;
+    if (argc > 0 && argv[0].type == SKY_LIST) {
+        for (i = 0; i < LIST(argv[0])->len; i++) {
+            if (!sky_truthy(LIST(argv[0])->items[i])) {
+                return sky_bool(0);
+            }
+        }
+    }
+    return sky_bool(1);
+}
+
+/* String methods. Case conversion and stripping handle ASCII. */
+
+static sky_value map_chars(int argc, sky_value *argv, int (*conv)(int), const char *name) {
+    buffer b = {0};
+    size_t i;
+    if (!str_args(argc, argv, 1)) {
+        sky_errorf("%s() requires string argument", name);
+    }
+    for (i = 0; i < SLEN(0); i++) {
+        unsigned char c = (unsigned char)S(0)[i];
+        char out = (char)(c < 0x80 ? conv(c) : c);
+        buf_write(&b, &out, 1);
+    }
+    return buf_string(&b);
+}
+
+sky_value sky_b_str_upper(int argc, sky_value *argv) {
+    return map_chars(argc, argv, toupper, "upper");
+}
+
+sky_value sky_b_str_lower(int argc, sky_value *argv) {
+    return map_chars(argc, argv, tolower, "lower");
+}
+
+sky_value sky_b_str_capitalize(int argc, sky_value *argv) {
+    sky_value s;
+    if (!str_args(argc, argv, 1)) {
+        sky_errorf("capitalize() requires string argument");
+    }
+    s = map_chars(argc, argv, tolower, "capitalize");
+    if (STR(s)->len > 0 && (unsigned char)STR(s)->data[0] < 0x80) {
+        STR(s)->data[0] = (char)toupper((unsigned char)STR(s)->data[0]);
+    }
+    return s;
+}
+
+/* find_str returns the index of sub in s from start, or -1 */
+static long find_str(const char *s, size_t len, const char *sub, size_t sublen, size_t start) {
+    size_t i;
+    if (sublen > len) {
+        return -1;
+    }
+    for (i = start; i + sublen <= len; i++) {
+        if (memcmp(s + i, sub, sublen) == 0) {
+            return (long)i;
+        }
+    }
+    return -1;
+}
+
+sky_value sky_b_str_split(int argc, sky_value *argv) {
+    const char *sep = " ";
+    size_t seplen = 1, start = 0;
+    sky_value parts;
+    if (!str_args(argc, argv, 1)) {
+        sky_errorf("split() requires string argument");
+    }
+    if (str_arg(argc, argv, 1)) {
+        sep = S(1);
+        seplen = SLEN(1);
+    }
+    parts = sky_list(0, NULL);
+    if (seplen == 0) {
+        /* An empty separator splits into UTF-8 characters */
+        while (start < SLEN(0)) {
+            size_t n = utf8_len((const unsigned char *)S(0) + start, SLEN(0) - start);
+            list_push(LIST(parts), sky_str_len(S(0) + start, n));
+            start += n;
+        }
+        return parts;
+    }
+    for (;;) {
+        long at = find_str(S(0), SLEN(0), sep, seplen, start);
+        if (at < 0) {
+            list_push(LIST(parts), sky_str_len(S(0) + start, SLEN(0) - start));
+            return parts;
+        }
+        list_push(LIST(parts), sky_str_len(S(0) + start, (size_t)at - start));
+        start = (size_t)at + seplen;
+    }
+}
+
+/* join_list joins the elements of a list with the separator argv[0] */
+static sky_value join_list(int argc, sky_value *argv) {
+    buffer b = {0};
+    size_t i;
+    if (argc < 2 || argv[0].type != SKY_STR || argv[1].type != SKY_LIST) {
+        sky_errorf("join() requires string separator and list");
+    }
+    for (i = 0; i < LIST(argv[1])->len; i++) {
+        if (i > 0) {
+            buf_write(&b, S(0), SLEN(0));
+        }
+        write_value(&b, LIST(argv[1])->items[i]);
+    }
+    return buf_string(&b);
+}
+
+sky_value sky_b_str_join(int argc, sky_value *argv) {
+    return join_list(argc, argv);
+}
+
+sky_value sky_b_join(int argc, sky_value *argv) {
+    return join_list(argc, argv);
+}
+
+sky_value sky_b_str_replace(int argc, sky_value *argv) {
+    buffer b = {0};
+    size_t start = 0;
+    if (!str_args(argc, argv, 3)) {
+        sky_errorf("replace() requires string, old, new");
+    }
+    if (SLEN(1) == 0) {
+        /* Like Go, an empty old string matches before every character */
+        while (start < SLEN(0)) {
+            size_t n = utf8_len((const unsigned char *)S(0) + start, SLEN(0) - start);
+            buf_write(&b, S(2), SLEN(2));
+            buf_write(&b, S(0) + start, n);
+            start += n;
+        }
+        buf_write(&b, S(2), SLEN(2));
+        return buf_string(&b);
+    }
+    for (;;) {
+        long at = find_str(S(0), SLEN(0), S(1), SLEN(1), start);
+        if (at < 0) {
+            buf_write(&b, S(0) + start, SLEN(0) - start);
+            return buf_string(&b);
+        }
+        buf_write(&b, S(0) + start, (size_t)at - start);
+        buf_write(&b, S(2), SLEN(2));
+        start = (size_t)at + SLEN(1);
+    }
+}
+
+sky_value sky_b_str_strip(int argc, sky_value *argv) {
+    size_t start = 0, end;
+    if (!str_args(argc, argv, 1)) {
+        sky_errorf("strip() requires string argument");
+    }
+    end = SLEN(0);
+    while (start < end && isspace((unsigned char)S(0)[start])) {
+        start++;
+    }
+    while (end > start && isspace((unsigned char)S(0)[end - 1])) {
+        end--;
+    }
+    return sky_str_len(S(0) + start, end - start);
+}
+
+sky_value sky_b_str_startswith(int argc, sky_value *argv) {
+    if (!str_args(argc, argv, 2)) {
+        sky_errorf("startswith() requires string and prefix");
+    }
+    return sky_bool(SLEN(1) <= SLEN(0) && memcmp(S(0), S(1), SLEN(1)) == 0);
+}
+
+sky_value sky_b_str_endswith(int argc, sky_value *argv) {
+    if (!str_args(argc, argv, 2)) {
+        sky_errorf("endswith() requires string and suffix");
+    }
+    return sky_bool(SLEN(1) <= SLEN(0) && memcmp(S(0) + SLEN(0) - SLEN(1), S(1), SLEN(1)) == 0);
+}
+
+sky_value sky_b_str_find(int argc, sky_value *argv) {
+    if (!str_args(argc, argv, 2)) {
+        return sky_int(-1);
+    }
+    return sky_int(find_str(S(0), SLEN(0), S(1), SLEN(1), 0));
+}
+
+sky_value sky_b_str_count(int argc, sky_value *argv) {
+    size_t start = 0;
+    int64_t count = 0;
+    if (!str_args(argc, argv, 2)) {
+        return sky_int(0);
+    }
+    if (SLEN(1) == 0) {
+        /* Like Go, an empty string is counted between characters */
+        while (start < SLEN(0)) {
+            start += utf8_len((const unsigned char *)S(0) + start, SLEN(0) - start);
+            count++;
+        }
+        return sky_int(count + 1);
+    }
+    for (;;) {
+        long at = find_str(S(0), SLEN(0), S(1), SLEN(1), start);
+        if (at < 0) {
+            return sky_int(count);
+        }
+        count++;
+        start = (size_t)at + SLEN(1);
+    }
+}
+
+/* List methods */
+
+static int list_arg(int argc, sky_value *argv, int n) {
+    return argc >= n && argv[0].type == SKY_LIST;
+}
+
+static int same_string(sky_value a, sky_value b) {
+    return str_equal(to_string(a), to_string(b));
+}
+
+sky_value sky_b_list_append(int argc, sky_value *argv) {
+    if (!list_arg(argc, argv, 2)) {
+        sky_errorf("append() requires list and item");
+    }
+    list_push(LIST(argv[0]), argv[1]);
+    return sky_nil();
+}
+
+static void list_delete(sky_list_obj *l, size_t idx) {
+    memmove(l->items + idx, l->items + idx + 1, (l->len - idx - 1) * sizeof(sky_value));
+    l->len--;
+}
+
+sky_value sky_b_list_pop(int argc, sky_value *argv) {
+    sky_list_obj *l;
+    int64_t idx;
+    sky_value item;
+    if (!list_arg(argc, argv, 1)) {
+        sky_errorf("pop() requires list");
+    }
+    l = LIST(argv[0]);
+    if (l->len == 0) {
+        sky_errorf("pop from empty list");
+    }
+    idx = (int64_t)l->len - 1;
+    if (argc >= 2 && argv[1].type == SKY_INT) {
+        idx = argv[1].as.i;
+    }
+    if (idx < 0 || idx >= (int64_t)l->len) {
+        sky_errorf("pop index out of range");
+    }
+    item = l->items[idx];
+    list_delete(l, (size_t)idx);
+    return item;
+}
+
+sky_value sky_b_list_insert(int argc, sky_value *argv) {
+    sky_list_obj *l;
+    int64_t i;
+    if (!list_arg(argc, argv, 3) || argv[1].type != SKY_INT) {
+        sky_errorf("insert() requires list, index, item");
+    }
+    l = LIST(argv[0]);
+    i = argv[1].as.i;
+    if (i < 0) {
+        i = 0;
+    }
+    if (i > (int64_t)l->len) {
+        i = (int64_t)l->len;
+    }
+    list_push(l, sky_nil());
+    memmove(l->items + i + 1, l->items + i, (l->len - 1 - (size_t)i) * sizeof(sky_value));
+    l->items[i] = argv[2];
+    return sky_nil();
+}
+
+sky_value sky_b_list_remove(int argc, sky_value *argv) {
+    size_t i;
+    if (!list_arg(argc, argv, 2)) {
+        sky_errorf("remove() requires list and item");
+    }
+    for (i = 0; i < LIST(argv[0])->len; i++) {
+        if (same_string(LIST(argv[0])->items[i], argv[1])) {
+            list_delete(LIST(argv[0]), i);
+            return sky_nil();
+        }
+    }
+    sky_errorf("item not in list");
+    return sky_nil();
+}
+
+sky_value sky_b_list_clear(int argc, sky_value *argv) {
+    if (!list_arg(argc, argv, 1)) {
+        sky_errorf("clear() requires list");
+    }
+    LIST(argv[0])->len = 0;
+    return sky_nil();
+}
+
+sky_value sky_b_list_index(int argc, sky_value *argv) {
+    size_t i;
+    if (list_arg(argc, argv, 2)) {
+        for (i = 0; i < LIST(argv[0])->len; i++) {
+            if (same_string(LIST(argv[0])->items[i], argv[1])) {
+                return sky_int((int64_t)i);
+            }
+        }
+    }
+    return sky_int(-1);
+}
+
+sky_value sky_b_list_count(int argc, sky_value *argv) {
+    int64_t count = 0;
+    size_t i;
+    if (list_arg(argc, argv, 2)) {
+        for (i = 0; i < LIST(argv[0])->len; i++) {
+            if (same_string(LIST(argv[0])->items[i], argv[1])) {
+                count++;
+            }
+        }
+    }
+    return sky_int(count);
+}
+
+sky_value sky_b_list_reverse(int argc, sky_value *argv) {
+    sky_list_obj *l;
+    size_t i;
+    if (!list_arg(argc, argv, 1)) {
+        sky_errorf("reverse() requires list");
+    }
+    l = LIST(argv[0]);
+    for (i = 0; i < l->len / 2; i++) {
+        sky_value t = l->items[i];
+        l->items[i] = l->items[l->len - 1 - i];
+        l->items[l->len - 1 - i] = t;
+    }
+    return sky_nil();
+}
+
+sky_value sky_b_list_copy(int argc, sky_value *argv) {
+    if (!list_arg(argc, argv, 1)) {
+        sky_errorf("copy() requires list");
+    }
+    return sky_list((int)LIST(argv[0])->len, LIST(argv[0])->items);
+}
+
+sky_value sky_b_list_extend(int argc, sky_value *argv) {
+    size_t i, n;
+    if (!list_arg(argc, argv, 2) || argv[1].type != SKY_LIST) {
+        sky_errorf("extend() requires two lists");
+    }
+    n = LIST(argv[1])->len;
+    for (i = 0; i < n; i++) {
+        list_push(LIST(argv[0]), LIST(argv[1])->items[i]);
+    }
+    return sky_nil();
+}
+
+/* Dict methods */
+
+static int dict_arg(int argc, sky_value *argv, int n) {
+    return argc >= n && argv[0].type == SKY_DICT;
+}
+
+sky_value sky_b_dict_keys(int argc, sky_value *argv) {
+    if (!dict_arg(argc, argv, 1)) {
+        sky_errorf("keys() requires dict");
+    }
+    return dict_keys(DICT(argv[0]));
+}
+
+sky_value sky_b_dict_values(int argc, sky_value *argv) {
+    sky_entry **entries;
+    sky_value values;
+    size_t i;
+    if (!dict_arg(argc, argv, 1)) {
+        sky_errorf("values() requires dict");
+    }
+    values = sky_list(0, NULL);
+    entries = sorted_entries(DICT(argv[0]));
+    for (i = 0; i < DICT(argv[0])->len; i++) {
+        list_push(LIST(values), entries[i]->val);
+    }
+    free(entries);
+    return values;
+}
+
+sky_value sky_b_dict_get(int argc, sky_value *argv) {
+    sky_value *val;
+    if (!dict_arg(argc, argv, 2) || argv[1].type != SKY_STR) {
+        sky_errorf("get() requires dict and key");
+    }
+    if ((val = dict_lookup(DICT(argv[0]), S(1), SLEN(1))) != NULL) {
+        return *val;
+    }
+    return argc >= 3 ? argv[2] : sky_nil();
+}
+
+sky_value sky_b_dict_pop(int argc, sky_value *argv) {
+    sky_value *val;
+    if (!dict_arg(argc, argv, 2) || argv[1].type != SKY_STR) {
+        sky_errorf("pop() requires dict and key");
+    }
+    if ((val = dict_lookup(DICT(argv[0]), S(1), SLEN(1))) != NULL) {
+        sky_value v = *val;
+        dict_delete(DICT(argv[0]), S(1), SLEN(1));
+        return v;
+    }
+    if (argc >= 3) {
+        return argv[2];
+    }
+    sky_errorf("key not found");
+    return sky_nil();
+}
+
+sky_value sky_b_dict_clear(int argc, sky_value *argv) {
+    if (!dict_arg(argc, argv, 1)) {
+        sky_errorf("clear() requires dict");
+    }
+    dict_clear(DICT(argv[0]));
+    return sky_nil();
+}
+
+sky_value sky_b_dict_update(int argc, sky_value *argv) {
+    sky_dict_obj *src;
+    size_t i;
+    if (!dict_arg(argc, argv, 2) || argv[1].type != SKY_DICT) {
+        sky_errorf("update() requires two dicts");
+    }
+    src = DICT(argv[1]);
+    for (i = 0; i < src->cap; i++) {
+        if (is_entry(&src->entries[i])) {
+            dict_put(DICT(argv[0]), src->entries[i].key, src->entries[i].klen, src->entries[i].val);
+        }
+    }
+    return sky_nil();
+}
+
+/* Time functions; timestamps are milliseconds */
+
+sky_value sky_b_time_now(int argc, sky_value *argv) {
+    (void)argc;
+    (void)argv;
+#ifdef SKY_POSIX
+    {
+        struct timespec ts;
+        clock_gettime(CLOCK_REALTIME, &ts);
+        return sky_int((int64_t)ts.tv_sec * 1000 + ts.tv_nsec / 1000000);
+    }
+#else
+    return sky_int((int64_t)time(NULL) * 1000);
+#endif
+}
+
+sky_value sky_b_time_sleep(int argc, sky_value *argv) {
+    if (argc > 0 && argv[0].type == SKY_INT && argv[0].as.i > 0) {
+        fflush(stdout);
+#ifdef SKY_POSIX
+        {
+            struct timespec ts;
+            ts.tv_sec = (time_t)(argv[0].as.i / 1000);
+            ts.tv_nsec = (long)(argv[0].as.i % 1000) * 1000000;
+            while (nanosleep(&ts, &ts) != 0 && errno == EINTR) {
+            }
+        }
+#else
+        {
+            clock_t end = clock() + (clock_t)(argv[0].as.i * CLOCKS_PER_SEC / 1000);
+            while (clock() < end) {
+            }
+        }
+#endif
+    }
+    return sky_nil();
+}
+
+/* File system and OS functions */
+
+/* os_error raises "name error: reason" like Go's wrapped os errors */
+static void os_error(const char *name, const char *op, const char *path) {
+#ifdef SKY_POSIX
+    sky_errorf("%s error: %s %s: %s", name, op, path, strerror(errno));
+#else
+    sky_errorf("%s error: %s %s: failed", name, op, path);
+#endif
+}
+
+sky_value sky_b_fs_read_text(int argc, sky_value *argv) {
+    buffer b = {0};
+    char chunk[4096];
+    size_t n;
+    FILE *f;
+    if (!str_args(argc, argv, 1)) {
+        sky_errorf("fs_read_text() requires filename string");
+    }
+    if ((f = fopen(S(0), "rb")) == NULL) {
+        os_error("fs_read_text", "open", S(0));
+    }
+    while ((n = fread(chunk, 1, sizeof chunk, f)) > 0) {
+        buf_write(&b, chunk, n);
+    }
+    fclose(f);
+    return buf_string(&b);
+}
+
+sky_value sky_b_fs_write_text(int argc, sky_value *argv) {
+    FILE *f;
+    if (!str_args(argc, argv, 2)) {
+        sky_errorf("fs_write_text() requires filename and content strings");
+    }
+    if ((f = fopen(S(0), "wb")) == NULL) {
+        os_error("fs_write_text", "open", S(0));
+    }
+    fwrite(S(1), 1, SLEN(1), f);
+    fclose(f);
+    return sky_bool(1);
+}
+
+sky_value sky_b_fs_exists(int argc, sky_value *argv) {
+    if (!str_args(argc, argv, 1)) {
+        sky_errorf("fs_exists() requires filename string");
+    }
+#ifdef SKY_POSIX
+    {
+        struct stat st;
+        return sky_bool(stat(S(0), &st) == 0);
+    }
+#else
+    {
+        FILE *f = fopen(S(0), "rb");
+        if (f) {
+            fclose(f);
+        }
+        return sky_bool(f != NULL);
+    }
+#endif
+}
+
+sky_value sky_b_fs_mkdir(int argc, sky_value *argv) {
+    if (!str_args(argc, argv, 1)) {
+        sky_errorf("fs_mkdir() requires directory name string");
+    }
+#ifdef SKY_POSIX
+    {
+        /* Parents are created first, like os.MkdirAll */
+        char *path = xmalloc(SLEN(0) + 1), *p;
+        struct stat st;
+        strcpy(path, S(0));
+        for (p = path + 1; *p; p++) {
+            if (*p == '/') {
+                *p = '\0';
+                if (mkdir(path, 0755) != 0 && errno != EEXIST) {
+                    free(path);
+                    os_error("fs_mkdir", "mkdir", S(0));
+                }
+                *p = '/';
+            }
+        }
+        free(path);
+        if (mkdir(S(0), 0755) != 0 && (errno != EEXIST || stat(S(0), &st) != 0 || !S_ISDIR(st.st_mode))) {
+            os_error("fs_mkdir", "mkdir", S(0));
+        }
+        return sky_bool(1);
+    }
+#else
+    sky_errorf("fs_mkdir is not available on this platform");
+    return sky_nil();
+#endif
+}
+
+static int compare_cstr(const void *a, const void *b) {
+    return strcmp(*(char *const *)a, *(char *const *)b);
+}
+
+sky_value sky_b_fs_list_dir(int argc, sky_value *argv) {
+    if (!str_args(argc, argv, 1)) {
+        sky_errorf("fs_list_dir() requires directory name string");
+    }
+#ifdef SKY_POSIX
+    {
+        DIR *dir = opendir(S(0));
+        struct dirent *entry;
+        char **names = NULL;
+        size_t n = 0, cap = 0, i;
+        sky_value files;
+        if (dir == NULL) {
+            os_error("fs_list_dir", "open", S(0));
+        }
+        while ((entry = readdir(dir)) != NULL) {
+            if (strcmp(entry->d_name, ".") == 0 || strcmp(entry->d_name, "..") == 0) {
+                continue;
+            }
+            if (n == cap) {
+                cap = cap ? 2 * cap : 16;
+                names = xrealloc(names, cap * sizeof *names);
+            }
+            names[n] = xmalloc(strlen(entry->d_name) + 1);
+            strcpy(names[n++], entry->d_name);
+        }
+        closedir(dir);
+        /* Entries are sorted by name, like os.ReadDir */
+        qsort(names, n, sizeof *names, compare_cstr);
+        files = sky_list(0, NULL);
+        for (i = 0; i < n; i++) {
+            list_push(LIST(files), sky_str(names[i]));
+            free(names[i]);
+        }
+        free(names);
+        return files;
+    }
+#else
+    sky_errorf("fs_list_dir is not available on this platform");
+    return sky_nil();
+#endif
+}
+
+sky_value sky_b_os_platform(int argc, sky_value *argv) {
+    (void)argc;
+    (void)argv;
+#if defined(__linux__)
+    return sky_str("linux");
+#elif defined(__APPLE__)
+    return sky_str("darwin");
+#elif defined(_WIN32)
+    return sky_str("windows");
+#elif defined(__FreeBSD__)
+    return sky_str("freebsd");
+#else
+    return sky_str("unknown");
+#endif
+}
+
+sky_value sky_b_os_getcwd(int argc, sky_value *argv) {
+    (void)argc;
+    (void)argv;
+#ifdef SKY_POSIX
+    {
+        char dir[4096];
+        if (getcwd(dir, sizeof dir) == NULL) {
+            sky_errorf("os_getcwd error: %s", strerror(errno));
+        }
+        return sky_str(dir);
+    }
+#else
+    sky_errorf("os_getcwd is not available on this platform");
+    return sky_nil();
+#endif
+}
+
+sky_value sky_b_os_getenv(int argc, sky_value *argv) {
+    const char *val;
+    if (!str_args(argc, argv, 1)) {
+        sky_errorf("os_getenv() requires environment variable name string");
+    }
+    val = getenv(S(0));
+    return sky_str(val ? val : "");
+}
+
+sky_value sky_b_os_setenv(int argc, sky_value *argv) {
+    if (!str_args(argc, argv, 2)) {
+        sky_errorf("os_setenv() requires key and value strings");
+    }
+#ifdef SKY_POSIX
+    if (setenv(S(0), S(1), 1) != 0) {
+        sky_errorf("os_setenv error: %s", strerror(errno));
+    }
+    return sky_bool(1);
+#else
+    sky_errorf("os_setenv is not available on this platform");
+    return sky_nil();
+#endif
+}
+
+/* Hashes, returned as lowercase hex */
+
+static sky_value hex_digest(const unsigned char *digest, size_t n) {
+    static const char digits[] = "0123456789abcdef";
+    char out[64];
+    size_t i;
+    for (i = 0; i < n; i++) {
+        out[2 * i] = digits[digest[i] >> 4];
+        out[2 * i + 1] = digits[digest[i] & 15];
+    }
+    return sky_str_len(out, 2 * n);
+}
+
+/* padded returns the message padded to 64-byte blocks with its bit length
+ * appended, little-endian for MD5 and big-endian for SHA-256 */
+static unsigned char *padded(const char *msg, size_t len, int big_endian, size_t *total) {
+    uint64_t bits = (uint64_t)len * 8;
+    unsigned char *buf;
+    int i;
+    *total = (len + 8) / 64 * 64 + 64;
+    buf = xmalloc(*total);
+    memset(buf, 0, *total);
+    memcpy(buf, msg, len);
+    buf[len] = 0x80;
+    for (i = 0; i < 8; i++) {
+        buf[*total - 8 + i] = (unsigned char)(bits >> (big_endian ? 56 - 8 * i : 8 * i));
+    }
+    return buf;
+}
+
+#define ROTL(x, n) (((x) << (n)) | ((x) >> (32 - (n))))
+#define ROTR(x, n) (((x) >> (n)) | ((x) << (32 - (n))))
+
+sky_value sky_b_crypto_md5(int argc, sky_value *argv) {
+    static const uint32_t k[64] = {
+        0xd76aa478, 0xe8c7b756, 0x242070db, 0xc1bdceee, 0xf57c0faf, 0x4787c62a, 0xa8304613, 0xfd469501,
+        0x698098d8, 0x8b44f7af, 0xffff5bb1, 0x895cd7be, 0x6b901122, 0xfd987193, 0xa679438e, 0x49b40821,
+        0xf61e2562, 0xc040b340, 0x265e5a51, 0xe9b6c7aa, 0xd62f105d, 0x02441453, 0xd8a1e681, 0xe7d3fbc8,
+        0x21e1cde6, 0xc33707d6, 0xf4d50d87, 0x455a14ed, 0xa9e3e905, 0xfcefa3f8, 0x676f02d9, 0x8d2a4c8a,
+        0xfffa3942, 0x8771f681, 0x6d9d6122, 0xfde5380c, 0xa4beea44, 0x4bdecfa9, 0xf6bb4b60, 0xbebfbc70,
+        0x289b7ec6, 0xeaa127fa, 0xd4ef3085, 0x04881d05, 0xd9d4d039, 0xe6db99e5, 0x1fa27cf8, 0xc4ac5665,
+        0xf4292244, 0x432aff97, 0xab9423a7, 0xfc93a039, 0x655b59c3, 0x8f0ccc92, 0xffeff47d, 0x85845dd1,
+        0x6fa87e4f, 0xfe2ce6e0, 0xa3014314, 0x4e0811a1, 0xf7537e82, 0xbd3af235, 0x2ad7d2bb, 0xeb86d391};
+    static const int r[64] = {7, 12, 17, 22, 7, 12, 17, 22, 7, 12, 17, 22, 7, 12, 17, 22,
+                              5, 9, 14, 20, 5, 9, 14, 20, 5, 9, 14, 20, 5, 9, 14, 20,
+                              4, 11, 16, 23, 4, 11, 16, 23, 4, 11, 16, 23, 4, 11, 16, 23,
+                              6, 10, 15, 21, 6, 10, 15, 21, 6, 10, 15, 21, 6, 10, 15, 21};
+    uint32_t h[4] = {0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476};
+    unsigned char *msg, digest[16];
+    size_t total, off;
+    int i;
+    if (!str_args(argc, argv, 1)) {
+        sky_errorf("crypto_md5() requires data string");
+    }
+    msg = padded(S(0), SLEN(0), 0, &total);
+    for (off = 0; off < total; off += 64) {
+        uint32_t w[16], a = h[0], b = h[1], c = h[2], d = h[3];
+        for (i = 0; i < 16; i++) {
+            const unsigned char *p = msg + off + 4 * i;
+            w[i] = (uint32_t)p[0] | (uint32_t)p[1] << 8 | (uint32_t)p[2] << 16 | (uint32_t)p[3] << 24;
+        }
+        for (i = 0; i < 64; i++) {
+            uint32_t f, t;
+            int g;
+            if (i < 16) {
+                f = (b & c) | (~b & d);
+                g = i;
+            } else if (i < 32) {
+                f = (d & b) | (~d & c);
+                g = (5 * i + 1) % 16;
+            } else if (i < 48) {
+                f = b ^ c ^ d;
+                g = (3 * i + 5) % 16;
+            } else {
+                f = c ^ (b | ~d);
+                g = (7 * i) % 16;
+            }
+            t = d;
+            d = c;
+            c = b;
+            b = b + ROTL(a + f + k[i] + w[g], r[i]);
+            a = t;
+        }
+        h[0] += a;
+        h[1] += b;
+        h[2] += c;
+        h[3] += d;
+    }
+    free(msg);
+    for (i = 0; i < 16; i++) {
+        digest[i] = (unsigned char)(h[i / 4] >> (8 * (i % 4)));
+    }
+    return hex_digest(digest, 16);
+}
+
+sky_value sky_b_crypto_sha256(int argc, sky_value *argv) {
+    static const uint32_t k[64] = {
+        0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+        0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+        0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+        0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+        0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+        0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+        0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+        0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2};
+    uint32_t h[8] = {0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19};
+    unsigned char *msg, digest[32];
+    size_t total, off;
+    int i;
+    if (!str_args(argc, argv, 1)) {
+        sky_errorf("crypto_sha256() requires data string");
+    }
+    msg = padded(S(0), SLEN(0), 1, &total);
+    for (off = 0; off < total; off += 64) {
+        uint32_t w[64], v[8];
+        for (i = 0; i < 16; i++) {
+            const unsigned char *p = msg + off + 4 * i;
+            w[i] = (uint32_t)p[0] << 24 | (uint32_t)p[1] << 16 | (uint32_t)p[2] << 8 | (uint32_t)p[3];
+        }
+        for (i = 16; i < 64; i++) {
+            uint32_t s0 = ROTR(w[i - 15], 7) ^ ROTR(w[i - 15], 18) ^ (w[i - 15] >> 3);
+            uint32_t s1 = ROTR(w[i - 2], 17) ^ ROTR(w[i - 2], 19) ^ (w[i - 2] >> 10);
+            w[i] = w[i - 16] + s0 + w[i - 7] + s1;
+        }
+        memcpy(v, h, sizeof v);
+        for (i = 0; i < 64; i++) {
+            uint32_t s1 = ROTR(v[4], 6) ^ ROTR(v[4], 11) ^ ROTR(v[4], 25);
+            uint32_t ch = (v[4] & v[5]) ^ (~v[4] & v[6]);
+            uint32_t t1 = v[7] + s1 + ch + k[i] + w[i];
+            uint32_t s0 = ROTR(v[0], 2) ^ ROTR(v[0], 13) ^ ROTR(v[0], 22);
+            uint32_t maj = (v[0] & v[1]) ^ (v[0] & v[2]) ^ (v[1] & v[2]);
+            memmove(v + 1, v, 7 * sizeof v[0]);
+            v[4] += t1;
+            v[0] = t1 + s0 + maj;
+        }
+        for (i = 0; i < 8; i++) {
+            h[i] += v[i];
+        }
+    }
+    free(msg);
+    for (i = 0; i < 32; i++) {
+        digest[i] = (unsigned char)(h[i / 4] >> (24 - 8 * (i % 4)));
+    }
+    return hex_digest(digest, 32);
+}
+
+sky_value sky_b_rand_int(int argc, sky_value *argv) {
+    if (argc > 0 && argv[0].type == SKY_INT && argv[0].as.i > 0) {
+        uint64_t r = ((uint64_t)rand() << 32) ^ ((uint64_t)rand() << 16) ^ (uint64_t)rand();
+        return sky_int((int64_t)(r % (uint64_t)argv[0].as.i));
+    }
+    return sky_int(0);
+}
@@ -0,0 +1,263 @@
+/*
+ * skyrt.h - runtime of SKY programs compiled to C.
+ *
+ * The C backend writes a program as C99 code calling this runtime and
+ * builds both with the system C compiler. Values print, compare and fail
+ * like in the interpreter, so a compiled program writes the same output.
+ *
+ * Memory is garbage collected: every object is allocated by the runtime
+ * and freed by a mark-and-sweep collector once nothing refers to it. The
+ * collector finds the values held by running functions by scanning the C
+ * stack, so values may be kept in ordinary C variables.
+ *
+ * Programs built as libraries (sky build --target=c --lib) export their
+ * public top-level functions with the API at the end of this file.
+ */
+#ifndef SKYRT_H
+#define SKYRT_H
+
+#include <setjmp.h>
+#include <stddef.h>
+#include <stdint.h>
+
+typedef enum {
+    SKY_UNDEF, /* an unassigned global, never a SKY value */
+    SKY_NIL,
+    SKY_BOOL,
+    SKY_INT,
+    SKY_FLOAT,
+    SKY_STR,
+    SKY_LIST,
+    SKY_DICT,
+    SKY_FUNC,
+    SKY_CLASS,
+    SKY_INSTANCE,
+    SKY_INTERFACE,
+    SKY_ENUM,
+    SKY_VARIANT,
+    SKY_PROMISE
+} sky_type;
+
+typedef struct sky_obj sky_obj;
+
+/* sky_value is a SKY value: a scalar or a reference to an object */
+typedef struct {
+    sky_type type;
+    union {
+        int b;
+        int64_t i;
+        double f;
+        sky_obj *o;
+    } as;
+} sky_value;
+
+/* sky_obj is the header of every heap object */
+struct sky_obj {
+    size_t size;
+    unsigned char kind; /* sky_type of the object, or SKY_ENV_KIND */
+    unsigned char mark;
+    unsigned char perm; /* never collected */
+};
+
+/* Environments hold the variables of functions that define closures */
+#define SKY_ENV_KIND 0xff
+
+typedef struct sky_env {
+    sky_obj obj;
+    struct sky_env *parent;
+    int n;
+    sky_value v[];
+} sky_env;
+
+typedef struct sky_func sky_func;
+typedef struct sky_class sky_class;
+
+/* sky_code is a compiled SKY function; self is nil outside methods */
+typedef sky_value (*sky_code)(sky_func *fn, sky_value self, int argc, sky_value *argv);
+
+/* sky_builtin_fn is a built-in function */
+typedef sky_value (*sky_builtin_fn)(int argc, sky_value *argv);
+
+#define SKY_ASYNC 1
+#define SKY_METHOD 2
+
+struct sky_func {
+    sky_obj obj;
+    const char *name;
+    int arity;
+    int flags;
+    sky_code code;
+    sky_builtin_fn builtin;
+    sky_env *env;         /* variables of the enclosing function */
+    sky_class *owner;     /* class a method was defined in */
+    sky_func *target;     /* function a bound method calls */
+    sky_value recv;       /* receiver of a bound method */
+    int prepend;          /* pass recv as first argument instead of self */
+    const char *enum_name; /* set for enum variant constructors */
+    int payload;
+};
+
+/* Values */
+
+static inline sky_value sky_nil(void) {
+    sky_value v;
+    v.type = SKY_NIL;
+    v.as.i = 0;
+    return v;
+}
+
+static inline sky_value sky_undef(void) {
+    sky_value v;
+    v.type = SKY_UNDEF;
+    v.as.i = 0;
+    return v;
+}
+
+static inline sky_value sky_bool(int b) {
+    sky_value v;
+    v.type = SKY_BOOL;
+    v.as.i = 0;
+    v.as.b = b != 0;
+    return v;
+}
+
+static inline sky_value sky_int(int64_t i) {
+    sky_value v;
+    v.type = SKY_INT;
+    v.as.i = i;
+    return v;
+}
+
+static inline sky_value sky_float(double f) {
+    sky_value v;
+    v.type = SKY_FLOAT;
+    v.as.f = f;
+    return v;
+}
+
+sky_value sky_str(const char *s);
+sky_value sky_str_len(const char *s, size_t len);
+sky_value sky_const(const char *s, size_t len);
+int sky_truthy(sky_value v);
+
+/* Errors. A runtime error unwinds to the innermost try handler. */
+
+typedef struct sky_handler {
+    jmp_buf jb;
+    struct sky_handler *prev;
+    sky_value error; /* message of the error that was raised */
+    int depth;
+} sky_handler;
+
+void sky_try(sky_handler *h);
+void sky_untry(sky_handler *h);
+void sky_throw(sky_value v);
+void sky_rethrow(sky_handler *h);
+void sky_errorf(const char *format, ...);
+
+/* Operators */
+
+sky_value sky_add(sky_value a, sky_value b);
+sky_value sky_sub(sky_value a, sky_value b);
+sky_value sky_mul(sky_value a, sky_value b);
+sky_value sky_div(sky_value a, sky_value b);
+sky_value sky_mod(sky_value a, sky_value b);
+sky_value sky_eq(sky_value a, sky_value b);
+sky_value sky_ne(sky_value a, sky_value b);
+sky_value sky_lt(sky_value a, sky_value b);
+sky_value sky_le(sky_value a, sky_value b);
+sky_value sky_gt(sky_value a, sky_value b);
+sky_value sky_ge(sky_value a, sky_value b);
+sky_value sky_neg(sky_value v);
+sky_value sky_not(sky_value v);
+
+sky_value sky_index(sky_value obj, sky_value index);
+sky_value sky_set_index(sky_value obj, sky_value index, sky_value val);
+sky_value sky_get_member(sky_value obj, const char *name);
+sky_value sky_set_member(sky_value obj, const char *name, sky_value val);
+
+sky_value sky_list(int n, sky_value *items);
+sky_value sky_dict(int n, sky_value *kv);
+
+/* for-in loops */
+
+typedef struct {
+    sky_value items; /* list of the values to visit, or the instance */
+    sky_value str;
+    size_t idx;
+    sky_value value; /* current value */
+    int kind;
+} sky_iter;
+
+void sky_iter_init(sky_iter *it, sky_value iterable);
+int sky_iter_next(sky_iter *it);
+
+/* Functions and calls */
+
+sky_value sky_closure(sky_code code, const char *name, int arity, int flags, sky_env *env);
+sky_env *sky_env_new(sky_env *parent, int n);
+sky_value sky_arg(int argc, sky_value *argv, int i);
+sky_value sky_rest(int argc, sky_value *argv, int i);
+sky_value sky_call(const char *name, sky_value callee, int argc, sky_value *argv);
+sky_value sky_invoke(sky_value self, sky_value recv, const char *name, int argc, sky_value *argv);
+sky_value sky_super_invoke(sky_class *cls, sky_value self, const char *name, int argc, sky_value *argv);
+sky_value sky_super(sky_class *cls);
+sky_value sky_await(sky_value v);
+sky_value sky_global(sky_value v, const char *name);
+sky_value sky_builtin(const char *name);
+
+/* Classes, interfaces and enums */
+
+sky_value sky_class_new(const char *name, int abstract, int nsupers, sky_value *supers);
+void sky_class_method(sky_value cls, const char *name, sky_value fn);
+void sky_class_implements(sky_value cls, const char *name, sky_value iface);
+sky_value sky_interface_new(const char *name, int nparents, sky_value *parents,
+                            int nmethods, const char **methods, const int *arities);
+sky_value sky_variant(const char *enum_name, const char *name, int payload);
+sky_value sky_enum_new(const char *name, int n, sky_value *ctors);
+
+/* Pattern tests of match expressions */
+
+int sky_pattern_equal(sky_value expected, sky_value value, int numeric);
+int sky_test_variant(sky_value v, const char *name, int payload, const char *enum_name);
+int sky_test_list(sky_value v, int n, int rest);
+int sky_test_dict(sky_value v);
+int sky_test_key(sky_value v, sky_value key);
+int sky_test_field(sky_value v, const char *name);
+int sky_test_instance(sky_value v, sky_value cls, const char *name);
+sky_value sky_payload(sky_value v, int i);
+sky_value sky_element(sky_value v, int i);
+sky_value sky_slice(sky_value v, int from, int tail);
+
+/* Programs and modules */
+
+typedef sky_value (*sky_script)(void);
+
+void sky_root(sky_value *globals, size_t n);
+int sky_main(sky_script script);
+sky_value sky_import(const char *path, int *loaded, sky_script script, sky_value (*exports)(void));
+void sky_export(sky_value ns, const char *name, sky_value v);
+sky_value sky_namespace(void);
+
+/* Built-ins called directly by compiled code */
+
+#define SKY_BUILTIN(name) sky_value sky_b_##name(int argc, sky_value *argv);
+#include "builtins.def"
+#undef SKY_BUILTIN
+
+/*
+ * Library API. Every exported function of a library runs the program's
+ * top-level code on the first call. A runtime error makes it return nil
+ * and is reported by sky_error until the next call. Values returned to
+ * the host stay valid until the next call into the library unless they
+ * are pinned.
+ */
+
+const char *sky_error(void);
+sky_value sky_lib_call(sky_script script, sky_value *fn, const char *name, int argc, sky_value *argv);
+void sky_lib_init(sky_script script);
+const char *sky_cstr(sky_value v); /* the value as printed, valid until the next call */
+void sky_pin(sky_value v);
+void sky_unpin(sky_value v);
+
+#endif
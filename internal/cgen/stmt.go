@@ -0,0 +1,546 @@
+package cgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+)
+
+func (f *fn) statement(stmt ast.Statement) error {
+	if line := stmt.Pos().Line; line > 0 {
+		f.line = line
+	}
+
+	switch s := stmt.(type) {
+	case *ast.LetStatement:
+		return f.defineValue(s.Name.Value, s.Value)
+	case *ast.ConstStatement:
+		// Same as let (const checking done in sema phase)
+		return f.defineValue(s.Name.Value, s.Value)
+	case *ast.StaticPropertyStatement:
+		// Static properties are plain variables, like in the interpreter
+		return f.defineValue(s.Name.Value, s.Value)
+	case *ast.ReturnStatement:
+		return f.returnStatement(s)
+	case *ast.BreakStatement:
+		return f.jump("break")
+	case *ast.ContinueStatement:
+		return f.jump("continue")
+	case *ast.ExpressionStatement:
+		return f.expressionStatement(s.Expression)
+	case *ast.BlockStatement:
+		return f.block(s)
+	case *ast.IfStatement:
+		return f.ifStatement(s)
+	case *ast.WhileStatement:
+		return f.whileStatement(s)
+	case *ast.ForStatement:
+		return f.forStatement(s)
+	case *ast.FunctionStatement:
+		return f.functionStatement(s)
+	case *ast.StaticMethodStatement:
+		// Static methods are stored as plain functions, like in the interpreter
+		code, err := f.function(s.Name.Value, s.Parameters, s.Body, false, false, false)
+		if err != nil {
+			return err
+		}
+		f.defineVariable(s.Name.Value, code)
+		return nil
+	case *ast.ClassStatement:
+		return f.classStatement(s.Name, s.SuperClasses, s.Interfaces, s.Body, false)
+	case *ast.AbstractClassStatement:
+		return f.classStatement(s.Name, s.SuperClasses, nil, s.Body, true)
+	case *ast.AbstractMethodStatement:
+		// Abstract methods only declare a signature
+		return nil
+	case *ast.InterfaceStatement:
+		return f.interfaceStatement(s)
+	case *ast.EnumStatement:
+		return f.enumStatement(s)
+	case *ast.ImportStatement:
+		return f.importStatement(s)
+	case *ast.UnsafeStatement:
+		return f.block(s.Body)
+	case *ast.TryStatement:
+		return f.tryStatement(s)
+	case *ast.ThrowStatement:
+		code, err := f.expr(s.Value)
+		if err != nil {
+			return err
+		}
+		f.printf("sky_throw(%s);\n", code)
+		return nil
+	default:
+		return f.errorf("unknown statement type: %T", stmt)
+	}
+}
+
+func (f *fn) block(block *ast.BlockStatement) error {
+	if block == nil {
+		return nil
+	}
+	for _, s := range block.Statements {
+		if err := f.statement(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// value compiles an optional expression, nil if it is absent
+func (f *fn) value(expr ast.Expression) (string, error) {
+	if expr == nil {
+		return "sky_nil()", nil
+	}
+	return f.expr(expr)
+}
+
+// defineValue defines name with the value of an optional expression
+func (f *fn) defineValue(name string, expr ast.Expression) error {
+	code, err := f.value(expr)
+	if err != nil {
+		return err
+	}
+	f.defineVariable(name, code)
+	return nil
+}
+
+func (f *fn) expressionStatement(expr ast.Expression) error {
+	// Plain assignments are written as C assignments
+	if assign, ok := expr.(*ast.InfixExpression); ok && assign.Operator == "=" {
+		if ident, ok := assign.Left.(*ast.Identifier); ok {
+			code, err := f.expr(assign.Right)
+			if err != nil {
+				return err
+			}
+			f.printf("%s = %s;\n", f.assignTarget(ident.Value), code)
+			return nil
+		}
+	}
+
+	code, err := f.expr(expr)
+	if err != nil {
+		return err
+	}
+	f.printf("(void)%s;\n", code)
+	return nil
+}
+
+// unwind leaves the try handlers from index from on, innermost first,
+// running their finally blocks
+func (f *fn) unwind(from int) error {
+	tries := f.tries
+	defer func() { f.tries = tries }()
+	for i := len(tries) - 1; i >= from; i-- {
+		f.printf("sky_untry(&%s);\n", tries[i].handler)
+		if tries[i].finally != nil {
+			f.tries = tries[:i]
+			if err := f.finallyBlock(tries[i].finally); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fn) returnStatement(stmt *ast.ReturnStatement) error {
+	code, err := f.value(stmt.ReturnValue)
+	if err != nil {
+		return err
+	}
+	// The value is computed before finally blocks run
+	if len(f.tries) > 0 {
+		code = f.spill(code)
+		if err := f.unwind(0); err != nil {
+			return err
+		}
+	}
+	f.printf("return %s;\n", code)
+	return nil
+}
+
+// jump compiles break or continue. Leaving try blocks to reach the loop
+// runs their finally blocks.
+func (f *fn) jump(keyword string) error {
+	if len(f.loops) == 0 {
+		return f.errorf("%s outside loop", keyword)
+	}
+	if err := f.unwind(f.loops[len(f.loops)-1].tries); err != nil {
+		return err
+	}
+	f.printf("%s;\n", keyword)
+	return nil
+}
+
+// condition compiles a condition to a C int expression
+func (f *fn) condition(expr ast.Expression) (string, error) {
+	code, err := f.expr(expr)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sky_truthy(%s)", code), nil
+}
+
+func (f *fn) ifStatement(stmt *ast.IfStatement) error {
+	cond, err := f.condition(stmt.Condition)
+	if err != nil {
+		return err
+	}
+	f.printf("if (%s) {\n", cond)
+	if err := f.block(stmt.Consequence); err != nil {
+		return err
+	}
+
+	// An elif whose condition needs statements nests in the else branch
+	closing := 1
+	for _, elif := range stmt.Elif {
+		mark := f.out.Len()
+		cond, err := f.condition(elif.Condition)
+		if err != nil {
+			return err
+		}
+		if pre := f.cut(mark); len(pre) > 0 {
+			f.printf("} else {\n%s", pre)
+			f.printf("if (%s) {\n", cond)
+			closing++
+		} else {
+			f.printf("} else if (%s) {\n", cond)
+		}
+		if err := f.block(elif.Consequence); err != nil {
+			return err
+		}
+	}
+
+	if stmt.Alternative != nil {
+		f.printf("} else {\n")
+		if err := f.block(stmt.Alternative); err != nil {
+			return err
+		}
+	}
+	f.printf("%s", strings.Repeat("}\n", closing))
+	return nil
+}
+
+// cut removes and returns what was written since mark
+func (f *fn) cut(mark int) []byte {
+	pre := append([]byte(nil), f.out.Bytes()[mark:]...)
+	f.out.Truncate(mark)
+	return pre
+}
+
+func (f *fn) whileStatement(stmt *ast.WhileStatement) error {
+	mark := f.out.Len()
+	cond, err := f.condition(stmt.Condition)
+	if err != nil {
+		return err
+	}
+	if pre := f.cut(mark); len(pre) > 0 {
+		f.printf("for (;;) {\n%sif (!%s) {\nbreak;\n}\n", pre, cond)
+	} else {
+		f.printf("while (%s) {\n", cond)
+	}
+
+	f.loops = append(f.loops, loop{tries: len(f.tries)})
+	if err := f.block(stmt.Body); err != nil {
+		return err
+	}
+	f.loops = f.loops[:len(f.loops)-1]
+	f.printf("}\n")
+	return nil
+}
+
+func (f *fn) forStatement(stmt *ast.ForStatement) error {
+	iterable, err := f.expr(stmt.Iterable)
+	if err != nil {
+		return err
+	}
+
+	f.enterScope()
+	defer f.leaveScope()
+	v := f.define(stmt.Iterator.Value)
+
+	it := fmt.Sprintf("t%d", f.g.newID())
+	f.printf("{\nsky_iter %s;\nsky_iter_init(&%s, %s);\n", it, it, iterable)
+	f.printf("while (sky_iter_next(&%s)) {\n%s = %s.value;\n", it, v, it)
+
+	f.loops = append(f.loops, loop{tries: len(f.tries)})
+	if err := f.block(stmt.Body); err != nil {
+		return err
+	}
+	f.loops = f.loops[:len(f.loops)-1]
+	f.printf("}\n}\n")
+	return nil
+}
+
+// tryStatement compiles try/catch/finally to a handler set up with
+// setjmp. An error in the body jumps back to the setjmp, which then runs
+// the catch block; finally runs after the body or catch, and before an
+// error leaves the statement. Return, break and continue run finally
+// blocks themselves, see unwind.
+func (f *fn) tryStatement(stmt *ast.TryStatement) error {
+	f.hasTry = true
+	handler := fmt.Sprintf("t%d", f.g.newID())
+	f.printf("{\nsky_handler %s;\nsky_try(&%s);\nif (setjmp(%s.jb) == 0) {\n", handler, handler, handler)
+	f.tries = append(f.tries, try{handler: handler, finally: stmt.Finally})
+	if err := f.block(stmt.TryBlock); err != nil {
+		return err
+	}
+	f.tries = f.tries[:len(f.tries)-1]
+	f.printf("sky_untry(&%s);\n} else {\n", handler)
+
+	switch {
+	case stmt.CatchClause != nil && stmt.Finally != nil:
+		// An error in catch still runs finally
+		inner := fmt.Sprintf("t%d", f.g.newID())
+		f.printf("sky_handler %s;\nsky_try(&%s);\nif (setjmp(%s.jb) == 0) {\n", inner, inner, inner)
+		f.tries = append(f.tries, try{handler: inner, finally: stmt.Finally})
+		if err := f.catchBlock(stmt.CatchClause, handler); err != nil {
+			return err
+		}
+		f.tries = f.tries[:len(f.tries)-1]
+		f.printf("sky_untry(&%s);\n} else {\n", inner)
+		if err := f.finallyBlock(stmt.Finally); err != nil {
+			return err
+		}
+		f.printf("sky_rethrow(&%s);\n}\n", inner)
+	case stmt.CatchClause != nil:
+		if err := f.catchBlock(stmt.CatchClause, handler); err != nil {
+			return err
+		}
+	default:
+		if err := f.finallyBlock(stmt.Finally); err != nil {
+			return err
+		}
+		f.printf("sky_rethrow(&%s);\n", handler)
+	}
+	f.printf("}\n")
+
+	if err := f.finallyBlock(stmt.Finally); err != nil {
+		return err
+	}
+	f.printf("}\n")
+	return nil
+}
+
+// catchBlock compiles a catch clause; the error message is in handler
+func (f *fn) catchBlock(clause *ast.CatchClause, handler string) error {
+	f.enterScope()
+	defer f.leaveScope()
+	if clause.ErrorVar != nil {
+		f.printf("%s = %s.error;\n", f.define(clause.ErrorVar.Value), handler)
+	}
+	return f.block(clause.Body)
+}
+
+// finallyBlock compiles a copy of a finally block in its own scope
+func (f *fn) finallyBlock(block *ast.BlockStatement) error {
+	if block == nil {
+		return nil
+	}
+	f.enterScope()
+	defer f.leaveScope()
+	f.printf("{\n")
+	if err := f.block(block); err != nil {
+		return err
+	}
+	f.printf("}\n")
+	return nil
+}
+
+func (f *fn) functionStatement(stmt *ast.FunctionStatement) error {
+	funcName := stmt.Name.Value
+
+	// Local functions are defined first so they can call themselves
+	if !f.isGlobalScope() {
+		f.define(funcName)
+	}
+
+	// Decorators are read outermost first and applied innermost first
+	decorators := make([]string, len(stmt.Decorators))
+	for i, decorator := range stmt.Decorators {
+		if err := f.checkName(decorator.Name.Value); err != nil {
+			return err
+		}
+		decorators[i] = f.spill(f.get(decorator.Name.Value))
+	}
+
+	code, err := f.function(funcName, stmt.Parameters, stmt.Body, stmt.Async, stmt.Coop, false)
+	if err != nil {
+		return err
+	}
+
+	for j := len(stmt.Decorators) - 1; j >= 0; j-- {
+		decorator := stmt.Decorators[j]
+		code = f.spill(code)
+		args, err := f.exprs(decorator.Args...)
+		if err != nil {
+			return err
+		}
+		code = fmt.Sprintf("sky_call(%s, %s, %s)", cString(decorator.Name.Value), decorators[j],
+			argArray(append([]string{code}, args...)))
+	}
+
+	f.defineVariable(funcName, code)
+	return nil
+}
+
+// function generates a C function for a SKY function and returns the C
+// expression creating its closure. Methods take their receiver as self.
+func (f *fn) function(name string, params []*ast.FunctionParameter, body *ast.BlockStatement,
+	async, coop, method bool) (string, error) {
+	if coop {
+		return "", f.errorf("coop functions are not supported by the C backend")
+	}
+	inner := &fn{g: f.g, u: f.u, enclosing: f, scope: newScope(nil), depth: 1, line: f.line, class: "NULL",
+		hasEnv: containsFunction(params) || containsFunction(body)}
+	id := f.g.newID()
+
+	var prologue strings.Builder
+	if method {
+		inner.method = true
+		inner.class = "F->owner"
+		fmt.Fprintf(&prologue, "%s = self;\n", inner.define("self"))
+	}
+
+	// Parameters are locals; omitted arguments are nil
+	for idx, param := range params {
+		v := inner.define(param.Name.Value)
+		if param.Variadic {
+			fmt.Fprintf(&prologue, "%s = sky_rest(argc, argv, %d);\n", v, idx)
+		} else {
+			fmt.Fprintf(&prologue, "%s = sky_arg(argc, argv, %d);\n", v, idx)
+		}
+	}
+
+	// Default values are evaluated when the caller omitted the argument
+	for idx, param := range params {
+		if param.DefaultValue == nil || param.Variadic {
+			continue
+		}
+		inner.printf("if (argc <= %d) {\n", idx)
+		code, err := inner.expr(param.DefaultValue)
+		if err != nil {
+			return "", err
+		}
+		v, _ := inner.scope.lookup(param.Name.Value)
+		inner.printf("%s = %s;\n}\n", inner.access(v), code)
+	}
+
+	if err := inner.block(body); err != nil {
+		return "", err
+	}
+	inner.printf("return sky_nil();\n")
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "/* %s */\n", commentText(name))
+	fmt.Fprintf(&buf, "static sky_value f%d(sky_func *F, sky_value self, int argc, sky_value *argv) {\n", id)
+	buf.WriteString(inner.declarations("F->env"))
+	buf.WriteString("(void)F;\n(void)self;\n")
+	buf.WriteString(prologue.String())
+	buf.WriteString(inner.out.String())
+	buf.WriteString("}\n")
+	f.g.funcs = append(f.g.funcs, buf.String())
+
+	flags := "0"
+	switch {
+	case async && method:
+		flags = "SKY_ASYNC | SKY_METHOD"
+	case async:
+		flags = "SKY_ASYNC"
+	case method:
+		flags = "SKY_METHOD"
+	}
+	return fmt.Sprintf("sky_closure(f%d, %s, %d, %s, %s)", id, cString(name), len(params), flags, f.env()), nil
+}
+
+// classStatement compiles class and abstract class definitions
+func (f *fn) classStatement(name *ast.Identifier, superClasses, interfaces []*ast.Identifier,
+	body []ast.Statement, abstract bool) error {
+	if !f.isGlobalScope() {
+		f.define(name.Value)
+	}
+
+	supers := make([]string, len(superClasses))
+	for i, super := range superClasses {
+		supers[i] = f.get(super.Value)
+	}
+	isAbstract := 0
+	if abstract {
+		isAbstract = 1
+	}
+	class := f.spill(fmt.Sprintf("sky_class_new(%s, %d, %s)", cString(name.Value), isAbstract, argArray(supers)))
+
+	for _, member := range body {
+		switch m := member.(type) {
+		case *ast.FunctionStatement:
+			code, err := f.function(m.Name.Value, m.Parameters, m.Body, m.Async, m.Coop, true)
+			if err != nil {
+				return err
+			}
+			f.printf("sky_class_method(%s, %s, %s);\n", class, cString(m.Name.Value), code)
+		case *ast.AbstractMethodStatement:
+			if !abstract {
+				return f.errorf("unsupported class member: %T", member)
+			}
+		default:
+			return f.errorf("unsupported class member: %T", member)
+		}
+	}
+
+	for _, iface := range interfaces {
+		f.printf("sky_class_implements(%s, %s, %s);\n", class, cString(iface.Value), f.get(iface.Value))
+	}
+
+	f.defineVariable(name.Value, class)
+	return nil
+}
+
+func (f *fn) interfaceStatement(stmt *ast.InterfaceStatement) error {
+	parents := make([]string, len(stmt.Extends))
+	for i, parent := range stmt.Extends {
+		parents[i] = f.get(parent.Value)
+	}
+	methods, arities := "NULL", "NULL"
+	if len(stmt.Methods) > 0 {
+		names := make([]string, len(stmt.Methods))
+		counts := make([]string, len(stmt.Methods))
+		for i, method := range stmt.Methods {
+			names[i] = cString(method.Name.Value)
+			counts[i] = fmt.Sprint(len(method.Parameters))
+		}
+		methods = "(const char *[]){" + strings.Join(names, ", ") + "}"
+		arities = "(const int[]){" + strings.Join(counts, ", ") + "}"
+	}
+	f.defineVariable(stmt.Name.Value, fmt.Sprintf("sky_interface_new(%s, %s, %d, %s, %s)",
+		cString(stmt.Name.Value), argArray(parents), len(stmt.Methods), methods, arities))
+	return nil
+}
+
+func (f *fn) enumStatement(stmt *ast.EnumStatement) error {
+	ctors := make([]string, len(stmt.Variants))
+	for i, variant := range stmt.Variants {
+		ctors[i] = f.spill(fmt.Sprintf("sky_variant(%s, %s, %d)",
+			cString(stmt.Name.Value), cString(variant.Name.Value), len(variant.Payload)))
+		f.defineVariable(variant.Name.Value, ctors[i])
+	}
+	f.defineVariable(stmt.Name.Value, fmt.Sprintf("sky_enum_new(%s, %s)", cString(stmt.Name.Value), argArray(ctors)))
+	return nil
+}
+
+func (f *fn) importStatement(stmt *ast.ImportStatement) error {
+	path := strings.Join(stmt.Path, "/")
+	mod, err := f.g.module(path)
+	if err != nil {
+		return f.errorf("%v", err)
+	}
+
+	// import foo.bar binds "bar", import foo as f binds "f"
+	name := stmt.Path[len(stmt.Path)-1]
+	if stmt.Alias != nil {
+		name = stmt.Alias.Value
+	}
+	f.defineVariable(name, fmt.Sprintf("sky_import(%s, &mod%d_loaded, %s, mod%d_exports)",
+		cString(path), mod.id, mod.scriptName(), mod.id))
+	return nil
+}
@@ -0,0 +1,918 @@
+package diag
+
+// catalog holds every code with its long-form explanation. Explanations
+// end with an example that triggers the diagnostic and, where there is
+// one, the corrected code.
+var catalog = []*Entry{
+	// Syntax
+
+	{UnexpectedToken, "unexpected token", `
+The parser expected a particular token, such as a closing parenthesis,
+"end" or a name, and found something else. The message names both the
+expected and the actual token.
+
+Example:
+
+    function add(a, b
+      return a + b
+    end
+
+The parameter list is not closed. Fix:
+
+    function add(a, b)
+      return a + b
+    end
+`},
+
+	{ExpectedExpression, "expected expression", `
+An expression was expected, but the token found cannot start one. This
+usually means an operand is missing or an operator is doubled.
+
+Example:
+
+    let total = 1 + * 2
+
+Fix:
+
+    let total = 1 + 2
+`},
+
+	{InvalidNumber, "invalid number literal", `
+A number literal could not be read, for example because it does not fit
+in a 64-bit integer or mixes digits that are not valid for its base.
+
+Example:
+
+    let big = 99999999999999999999
+
+Fix: use a float for values outside the int range.
+
+    let big = 99999999999999999999.0
+`},
+
+	{InvalidSyntax, "invalid syntax", `
+The code does not follow the grammar of a statement or expression: a
+block is not closed, a match arm lacks "=>", a pattern is malformed or an
+interface contains something other than method signatures. The message
+describes what is wrong.
+
+Example:
+
+    match shape
+      Circle(r) print(r)
+    end
+
+Fix:
+
+    match shape
+      Circle(r) => print(r)
+    end
+`},
+
+	// Names and scopes
+
+	{DuplicateDefinition, "duplicate definition", `
+A name is defined twice in the same scope. Each variable, function,
+class or import needs a name of its own within a scope; an inner scope
+may reuse an outer name.
+
+Example:
+
+    let count = 1
+    let count = 2
+
+Fix: assign instead of declaring again.
+
+    let count = 1
+    count = 2
+`},
+
+	{UndefinedName, "undefined variable", `
+A name is used that is not defined in the current scope or any
+enclosing scope. It may be misspelled, defined later in a block that is
+not visible here, or belong to a module that was not imported.
+
+The runtime reports the same code when a name cannot be resolved while
+the program runs.
+
+Example:
+
+    function greet()
+      print(mesage)
+    end
+
+Fix:
+
+    function greet()
+      let message = "hello"
+      print(message)
+    end
+`},
+
+	{AssignToConst, "assignment to constant", `
+A const is bound once and cannot be assigned to afterwards.
+
+Example:
+
+    const limit = 10
+    limit = 20
+
+Fix: use let for values that change.
+
+    let limit = 10
+    limit = 20
+`},
+
+	{MissingConstValue, "constant without a value", `
+A const declaration needs a value; it cannot be assigned later. In
+source code the parser already stops at the missing "=" (E0001), so the
+checker reports this code for syntax trees built by other tools.
+
+Example:
+
+    const limit: int
+
+Fix:
+
+    const limit: int = 10
+`},
+
+	{UndefinedMember, "undefined member", `
+A field or method is accessed that the class, module or value does not
+have. For modules only exported members are visible: names starting with
+an underscore are private.
+
+The runtime reports the same code for undefined methods and properties.
+
+Example:
+
+    class Point
+      function init(x: int)
+        self.x = x
+      end
+    end
+
+    let p = Point(1)
+    print(p.y)
+
+Fix: access a member the class declares, or declare it.
+
+    print(p.x)
+`},
+
+	// Types
+
+	{TypeMismatch, "type mismatch", `
+A value is assigned to a variable, list element, dict entry or field
+whose type does not accept it.
+
+Example:
+
+    let count: int = "three"
+
+Fix:
+
+    let count: int = 3
+`},
+
+	{ReturnTypeMismatch, "return type mismatch", `
+A function returns a value that does not match its declared return type.
+
+Example:
+
+    function half(n: int): int
+      return n / 2.0
+    end
+
+Fix: change the value or the annotation.
+
+    function half(n: int): float
+      return n / 2.0
+    end
+`},
+
+	{ArgumentTypeMismatch, "argument type mismatch", `
+An argument passed to a function, method or constructor does not match
+the type of its parameter. For interface parameters the message lists
+the methods the argument is missing.
+
+Example:
+
+    function square(n: int): int
+      return n * n
+    end
+
+    square("4")
+
+Fix:
+
+    square(4)
+`},
+
+	{ArgumentCount, "wrong number of arguments", `
+A call passes more or fewer arguments than the function accepts.
+Parameters with default values may be omitted; a variadic parameter
+accepts any number of trailing arguments.
+
+Example:
+
+    function area(w: int, h: int): int
+      return w * h
+    end
+
+    area(3)
+
+Fix:
+
+    area(3, 4)
+`},
+
+	{NonBoolCondition, "condition is not bool", `
+The condition of if, elif, while and match guards must be a bool. Other
+values are not converted implicitly.
+
+Example:
+
+    let items = [1, 2]
+    if len(items)
+      print("not empty")
+    end
+
+Fix:
+
+    if len(items) > 0
+      print("not empty")
+    end
+`},
+
+	{InvalidOperand, "invalid operand", `
+An operator is applied to a value of a type it does not support, such as
+negating a string or using ! on a number.
+
+Example:
+
+    let name = "sky"
+    print(-name)
+
+Fix: apply the operator to a value of a supported type.
+
+    let n = 3
+    print(-n)
+`},
+
+	{InvalidIndex, "invalid index", `
+Lists are indexed with ints. Other index types are rejected.
+
+Example:
+
+    let items = [1, 2, 3]
+    print(items["0"])
+
+Fix:
+
+    print(items[0])
+`},
+
+	{PossiblyNil, "possibly nil value", `
+With check --strict-null, a value whose type includes nil is used where
+nil is not allowed: calling a method on it, accessing a member or
+passing it on. Check for nil first; the checker narrows the type inside
+the branch.
+
+Example:
+
+    function shout(name: string?)
+      print(name.upper())
+    end
+
+Fix:
+
+    function shout(name: string?)
+      if name != nil
+        print(name.upper())
+      end
+    end
+`},
+
+	{TypeArgumentCount, "wrong number of type arguments", `
+A generic class or function is given more or fewer type arguments than
+it declares type parameters.
+
+Example:
+
+    class Box[T]
+      function init(value: T)
+        self.value = value
+      end
+    end
+
+    let b: Box[int, string] = Box(1)
+
+Fix:
+
+    let b: Box[int] = Box(1)
+`},
+
+	{ConstraintNotSatisfied, "type constraint not satisfied", `
+A type argument does not satisfy the constraint of its type parameter:
+it lacks methods of the interface named in the constraint.
+
+Example:
+
+    interface Named
+      function name(): string
+    end
+
+    function loudest[T: Named](items: [T]): T
+      return items[0]
+    end
+
+    loudest([1, 2])
+
+Fix: pass values of a type that implements Named.
+`},
+
+	{DuplicateTypeParameter, "duplicate type parameter", `
+The same type parameter name is declared twice.
+
+Example:
+
+    function pair[T, T](a: T, b: T): [T]
+      return [a, b]
+    end
+
+Fix:
+
+    function pair[A, B](a: A, b: B): [any]
+      return [a, b]
+    end
+`},
+
+	{ExplicitTypeArguments, "explicit type arguments", `
+A generic class or function is called with type arguments in brackets.
+Type arguments are inferred from the call arguments; they can only be
+written in type annotations.
+
+Example:
+
+    let b = Box[int](3)
+
+Fix:
+
+    let b = Box(3)
+    let c: Box[int] = Box(3)
+`},
+
+	// Classes and interfaces
+
+	{InvalidSuperclass, "invalid superclass", `
+The superclass of a class is not defined or is not a class.
+
+Example:
+
+    class Dog : Animl
+      function bark()
+        print("woof")
+      end
+    end
+
+Fix: name a class that is defined before this one.
+
+    class Dog : Animal
+      function bark()
+        print("woof")
+      end
+    end
+`},
+
+	{InvalidInterface, "invalid interface", `
+A name used after implements or in an interface's extends list is not
+defined or is not an interface.
+
+Example:
+
+    class Circle
+      function init(r: float)
+        self.r = r
+      end
+    end
+
+    class Wheel implements Circle
+      function roll()
+        print("rolling")
+      end
+    end
+
+Fix: implement an interface, or inherit from the class instead.
+`},
+
+	{InterfaceNotImplemented, "interface not implemented", `
+A class declares that it implements an interface but lacks some of its
+methods, or has them with different signatures. The message lists what
+is missing.
+
+Example:
+
+    interface Shape
+      function area(): float
+    end
+
+    class Square implements Shape
+      function side(): float
+        return 1.0
+      end
+    end
+
+Fix: add the missing methods with matching signatures.
+
+    class Square implements Shape
+      function area(): float
+        return 1.0
+      end
+    end
+`},
+
+	{IncompatibleOverride, "incompatible override", `
+A method overrides a superclass method with a signature that does not
+fit: callers of the superclass method could not call it the same way.
+Parameters must accept what the original accepts and the return type
+must be assignable to the original's.
+
+Example:
+
+    class Animal
+      function speak(): string
+        return "..."
+      end
+    end
+
+    class Dog : Animal
+      function speak(): int
+        return 1
+      end
+    end
+
+Fix:
+
+    class Dog : Animal
+      function speak(): string
+        return "woof"
+      end
+    end
+`},
+
+	{InvalidSuper, "invalid use of super", `
+super refers to the superclass of the enclosing class. It cannot be used
+outside a class, in a class without a superclass, or to call a method the
+superclass does not have.
+
+Example:
+
+    class Animal
+      function speak()
+        super.speak()
+      end
+    end
+
+Fix: remove the call or give the class a superclass that has the method.
+`},
+
+	{InterfaceInstantiation, "cannot instantiate interface", `
+Interfaces describe methods and have no constructor.
+
+Example:
+
+    interface Shape
+      function area(): float
+    end
+
+    let s = Shape()
+
+Fix: instantiate a class that implements the interface.
+`},
+
+	{DuplicateInterfaceMethod, "duplicate interface method", `
+An interface declares the same method twice.
+
+Example:
+
+    interface Shape
+      function area(): float
+      function area(): int
+    end
+
+Fix: keep a single declaration.
+`},
+
+	// Modules
+
+	{ModuleNotLoaded, "module cannot be loaded", `
+An imported module was not found or contains syntax errors. Modules are
+resolved relative to the importing file, the working directory and the
+installed wing packages; import a.b loads a/b.sky.
+
+Example:
+
+    import utils.strngs
+
+Fix: check the module path and the file name.
+
+    import utils.strings
+`},
+
+	{ImportCycle, "import cycle", `
+Modules import each other in a cycle, so none of them can be loaded
+first. The message shows the chain of imports.
+
+Example:
+
+    # a.sky
+    import b
+
+    # b.sky
+    import a
+
+Fix: move the shared code into a third module that both import.
+`},
+
+	// Control flow
+
+	{ReturnOutsideFunction, "return outside function", `
+return ends a function and can only appear inside one. The linter
+reports the same code.
+
+Example:
+
+    let x = 1
+    return x
+
+Fix: put the code in a function.
+
+    function main
+      let x = 1
+      return x
+    end
+`},
+
+	{AwaitOutsideAsync, "await outside async function", `
+await suspends the current async function until a promise resolves. It
+cannot be used in ordinary functions or at the top level.
+
+Example:
+
+    function main
+      let data = await fetchData()
+    end
+
+Fix:
+
+    async function main
+      let data = await fetchData()
+    end
+`},
+
+	{YieldOutsideCoop, "yield outside coop function", `
+yield hands a value to the caller of a coroutine and can only be used in
+coop functions.
+
+Example:
+
+    yield 1
+
+    function numbers()
+      yield 1
+    end
+
+Fix:
+
+    coop function numbers()
+      yield 1
+    end
+`},
+
+	{UnawaitedPromise, "promise is not awaited", `
+Calling an async function returns a Promise[T] and runs the function in
+the background. When the promise is neither awaited nor stored, its
+result and any error it raises are lost, and the program may end before
+the call finishes.
+
+Example:
+
+    async function save(data: string): bool
+      return await fs_write_text_async("out.txt", data)
+    end
+
+    async function main
+      save("hello")
+    end
+
+Fix:
+
+    async function main
+      await save("hello")
+    end
+
+To start the call without waiting, keep the promise and await it later:
+
+    let pending = save("hello")
+`},
+
+	{BlockingCall, "blocking call in async function", `
+A builtin that blocks the thread, such as time_sleep, was called inside an
+async function. The call works, but it stops every other task until it
+returns. The message names the async version to await instead.
+
+Example:
+
+    async function poll()
+      time_sleep(100)
+    end
+
+Fix:
+
+    async function poll()
+      await time_sleep_async(100)
+    end
+`},
+
+	// Pattern matching
+
+	{NonExhaustiveMatch, "non-exhaustive match", `
+A match on an enum or bool does not cover every case, so some values
+would match no arm. The message lists the missing cases.
+
+Example:
+
+    enum Color
+      Red
+      Green
+      Blue
+    end
+
+    match c
+      Red() => print("red")
+      Green() => print("green")
+    end
+
+Fix: add the missing arms or a wildcard arm.
+
+    match c
+      Red() => print("red")
+      Green() => print("green")
+      _ => print("other")
+    end
+`},
+
+	{UnreachableArm, "unreachable match arm", `
+A match arm can never be chosen because earlier arms already cover every
+value it matches, for example an arm after a wildcard.
+
+Example:
+
+    match n
+      _ => print("any")
+      0 => print("zero")
+    end
+
+Fix: order arms from specific to general.
+
+    match n
+      0 => print("zero")
+      _ => print("any")
+    end
+`},
+
+	{DuplicateArm, "duplicate match arm", `
+Two arms of a match have the same pattern; the second is never chosen.
+
+Example:
+
+    match c
+      Red() => print("red")
+      Red() => print("still red")
+      _ => print("other")
+    end
+
+Fix: remove or merge the duplicate arm.
+`},
+
+	{InvalidPattern, "invalid pattern", `
+A pattern names something that is not an enum variant or a class, gives
+a variant the wrong number of payload patterns, or uses positional
+patterns for a class, whose fields are matched by name.
+
+Example:
+
+    enum Shape
+      Circle(int)
+    end
+
+    match s
+      Circle(r, extra) => print(r)
+    end
+
+Fix:
+
+    match s
+      Circle(r) => print(r)
+    end
+`},
+
+	{PatternTypeMismatch, "pattern type mismatch", `
+A pattern can never match the type of the value being matched, such as
+a string literal against an int or a variant of another enum.
+
+Example:
+
+    let n: int = 3
+    match n
+      "three" => print("3")
+      _ => print("?")
+    end
+
+Fix: use patterns of the matched value's type.
+
+    match n
+      3 => print("3")
+      _ => print("?")
+    end
+`},
+
+	{OrPatternBindings, "or-pattern binds different variables", `
+Every alternative of an or-pattern must bind the same variables, so the
+arm body can use them whichever alternative matched.
+
+Example:
+
+    match s
+      Circle(r) | Square(w) => print(r)
+    end
+
+Fix:
+
+    match s
+      Circle(r) | Square(r) => print(r)
+    end
+`},
+
+	// Runtime
+
+	{RuntimeTypeError, "runtime type error", `
+With sky run --runtime-typecheck, a value that crosses a type annotation
+while the program runs does not match it: an argument bound to a typed
+parameter, a value returned from a function with a return type or a
+value bound by a typed let. The message names the function or variable
+and, for arguments, where the call was made. The checker cannot see these
+values because they pass through code without annotations.
+
+Example:
+
+    function twice(n: int): int
+      return n * 2
+    end
+
+    function relay(value)
+      return twice(value)
+    end
+
+    relay("21")
+
+Fix: convert the value where it enters typed code.
+
+    function relay(value)
+      return twice(int(value))
+    end
+`},
+
+	{DivisionByZero, "division by zero", `
+An integer or float was divided by zero. The linter reports this code
+for division by a literal 0; at run time it is raised whenever the
+divisor is zero.
+
+Example:
+
+    let total = 10
+    let rate = total / 0
+
+Fix: check the divisor first.
+
+    if count != 0
+      let rate = total / count
+    end
+`},
+
+	{IndexOutOfRange, "index out of range", `
+A list or string was indexed past its end. Valid indices run from 0 to
+len - 1; negative indices count from the end.
+
+Example:
+
+    let items = [1, 2, 3]
+    print(items[3])
+
+Fix:
+
+    print(items[len(items) - 1])
+`},
+
+	{RecursionLimit, "maximum recursion depth exceeded", `
+Calls nested deeper than the interpreter allows, which almost always
+means a recursive function is missing its base case.
+
+Example:
+
+    function count(n)
+      return count(n + 1)
+    end
+
+    count(0)
+
+Fix:
+
+    function count(n)
+      if n >= 10
+        return n
+      end
+      return count(n + 1)
+    end
+`},
+
+	{NotCallable, "value is not callable", `
+A value that is neither a function nor a class was called.
+
+Example:
+
+    let greeting = "hello"
+    greeting()
+
+Fix: call a function, or remove the parentheses.
+
+    print(greeting)
+`},
+
+	{NotIterable, "value is not iterable", `
+A for loop was given a value it cannot iterate. Lists, dicts, strings,
+ranges and generators are iterable.
+
+Example:
+
+    for x in 42
+      print(x)
+    end
+
+Fix:
+
+    for x in range(42)
+      print(x)
+    end
+`},
+
+	// Lint
+
+	{UnusedVariable, "unused variable", `
+A variable is defined but never read. Remove it, or start its name with
+an underscore to mark it as intentionally unused.
+
+Example:
+
+    let result = compute()
+
+Fix:
+
+    let _result = compute()
+`},
+
+	{ShadowedVariable, "shadowed variable", `
+A variable is declared again with the name of an earlier declaration,
+which hides the earlier one and is easy to mistake for an assignment.
+
+Example:
+
+    let total = 0
+    let total = 10
+
+Fix: assign to the existing variable or choose another name.
+
+    let total = 0
+    total = 10
+`},
+
+	{UnsafeBlock, "unsafe block", `
+Code in an unsafe block bypasses the language's safety checks, such as
+bounds checks on raw pointers. The linter flags each block so it can be
+reviewed; suppress the warning once a block has been checked.
+
+Example:
+
+    unsafe
+      let p = alloc(16)
+    end
+
+Suppress after review:
+
+    # sky-ignore: W2003 reviewed: buffer is freed below
+    unsafe
+      let p = alloc(16)
+    end
+`},
+}
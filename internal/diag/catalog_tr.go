@@ -0,0 +1,918 @@
+package diag
+
+// catalogTR holds the Turkish titles and explanations of the catalog.
+// Examples are the same code as in English.
+var catalogTR = map[string]Translation{
+	// Syntax
+
+	UnexpectedToken: {"beklenmeyen token", `
+Parser belirli bir token bekliyordu, örneğin kapanan parantez, "end" ya
+da bir isim, ama başka bir şey buldu. Mesaj hem beklenen hem de bulunan
+token'ı belirtir.
+
+Örnek:
+
+    function add(a, b
+      return a + b
+    end
+
+Parametre listesi kapatılmamış. Düzeltme:
+
+    function add(a, b)
+      return a + b
+    end
+`},
+
+	ExpectedExpression: {"ifade bekleniyordu", `
+Bir ifade bekleniyordu, ama bulunan token bir ifade başlatamaz. Genellikle
+bir operand eksiktir ya da bir operatör iki kez yazılmıştır.
+
+Örnek:
+
+    let total = 1 + * 2
+
+Düzeltme:
+
+    let total = 1 + 2
+`},
+
+	InvalidNumber: {"geçersiz sayı", `
+Bir sayı okunamadı; örneğin 64 bitlik bir int'e sığmıyor ya da tabanında
+geçerli olmayan rakamlar içeriyor.
+
+Örnek:
+
+    let big = 99999999999999999999
+
+Düzeltme: int aralığının dışındaki değerler için float kullanın.
+
+    let big = 99999999999999999999.0
+`},
+
+	InvalidSyntax: {"geçersiz sözdizimi", `
+Kod bir deyimin ya da ifadenin gramerine uymuyor: bir blok kapatılmamış,
+bir match kolunda "=>" eksik, bir desen bozuk ya da bir interface metot
+imzalarından başka bir şey içeriyor. Mesaj neyin yanlış olduğunu anlatır.
+
+Örnek:
+
+    match shape
+      Circle(r) print(r)
+    end
+
+Düzeltme:
+
+    match shape
+      Circle(r) => print(r)
+    end
+`},
+
+	// Names and scopes
+
+	DuplicateDefinition: {"tekrarlanan tanım", `
+Bir isim aynı kapsamda iki kez tanımlanmış. Her değişken, fonksiyon,
+sınıf ya da import bir kapsam içinde kendi ismine sahip olmalıdır; iç
+kapsamlar dıştaki bir ismi yeniden kullanabilir.
+
+Örnek:
+
+    let count = 1
+    let count = 2
+
+Düzeltme: yeniden tanımlamak yerine atama yapın.
+
+    let count = 1
+    count = 2
+`},
+
+	UndefinedName: {"tanımsız değişken", `
+Geçerli kapsamda ya da onu çevreleyen kapsamlarda tanımlı olmayan bir
+isim kullanılmış. İsim yanlış yazılmış, burada görünmeyen bir blokta daha
+sonra tanımlanmış ya da import edilmemiş bir modüle ait olabilir.
+
+Program çalışırken bir isim çözülemediğinde runtime da aynı kodu
+bildirir.
+
+Örnek:
+
+    function greet()
+      print(mesage)
+    end
+
+Düzeltme:
+
+    function greet()
+      let message = "hello"
+      print(message)
+    end
+`},
+
+	AssignToConst: {"sabite atama", `
+Bir const bir kez bağlanır ve sonradan ona atama yapılamaz.
+
+Örnek:
+
+    const limit = 10
+    limit = 20
+
+Düzeltme: değişen değerler için let kullanın.
+
+    let limit = 10
+    limit = 20
+`},
+
+	MissingConstValue: {"değeri olmayan sabit", `
+Bir const tanımı bir değer gerektirir; değer sonradan atanamaz. Kaynak
+kodda parser eksik "=" işaretinde zaten durur (E0001), bu yüzden
+denetleyici bu kodu başka araçların kurduğu sözdizimi ağaçları için
+bildirir.
+
+Örnek:
+
+    const limit: int
+
+Düzeltme:
+
+    const limit: int = 10
+`},
+
+	UndefinedMember: {"tanımsız üye", `
+Sınıfın, modülün ya da değerin sahip olmadığı bir alana veya metoda
+erişilmiş. Modüllerde yalnızca dışa aktarılan üyeler görünür: alt çizgiyle
+başlayan isimler özeldir.
+
+Runtime tanımsız metotlar ve özellikler için aynı kodu bildirir.
+
+Örnek:
+
+    class Point
+      function init(x: int)
+        self.x = x
+      end
+    end
+
+    let p = Point(1)
+    print(p.y)
+
+Düzeltme: sınıfın tanımladığı bir üyeye erişin ya da üyeyi tanımlayın.
+
+    print(p.x)
+`},
+
+	// Types
+
+	TypeMismatch: {"tip uyuşmazlığı", `
+Bir değer, tipi onu kabul etmeyen bir değişkene, liste elemanına, dict
+girdisine ya da alana atanmış.
+
+Örnek:
+
+    let count: int = "three"
+
+Düzeltme:
+
+    let count: int = 3
+`},
+
+	ReturnTypeMismatch: {"dönüş tipi uyuşmazlığı", `
+Bir fonksiyon, bildirilen dönüş tipine uymayan bir değer döndürüyor.
+
+Örnek:
+
+    function half(n: int): int
+      return n / 2.0
+    end
+
+Düzeltme: değeri ya da tip notasyonunu değiştirin.
+
+    function half(n: int): float
+      return n / 2.0
+    end
+`},
+
+	ArgumentTypeMismatch: {"argüman tipi uyuşmazlığı", `
+Bir fonksiyona, metoda ya da constructor'a geçirilen argüman,
+parametresinin tipine uymuyor. Interface parametrelerinde mesaj
+argümanda eksik olan metotları listeler.
+
+Örnek:
+
+    function square(n: int): int
+      return n * n
+    end
+
+    square("4")
+
+Düzeltme:
+
+    square(4)
+`},
+
+	ArgumentCount: {"yanlış argüman sayısı", `
+Bir çağrı, fonksiyonun kabul ettiğinden daha fazla ya da daha az argüman
+geçiriyor. Varsayılan değeri olan parametreler atlanabilir; variadic bir
+parametre sondaki istenen sayıda argümanı kabul eder.
+
+Örnek:
+
+    function area(w: int, h: int): int
+      return w * h
+    end
+
+    area(3)
+
+Düzeltme:
+
+    area(3, 4)
+`},
+
+	NonBoolCondition: {"koşul bool değil", `
+if, elif, while ve match guard'larının koşulu bool olmalıdır. Diğer
+değerler örtük olarak dönüştürülmez.
+
+Örnek:
+
+    let items = [1, 2]
+    if len(items)
+      print("not empty")
+    end
+
+Düzeltme:
+
+    if len(items) > 0
+      print("not empty")
+    end
+`},
+
+	InvalidOperand: {"geçersiz operand", `
+Bir operatör desteklemediği tipte bir değere uygulanmış; örneğin bir
+string'in negatifi alınmış ya da bir sayıya ! uygulanmış.
+
+Örnek:
+
+    let name = "sky"
+    print(-name)
+
+Düzeltme: operatörü desteklenen tipte bir değere uygulayın.
+
+    let n = 3
+    print(-n)
+`},
+
+	InvalidIndex: {"geçersiz indeks", `
+Listeler int ile indekslenir. Diğer indeks tipleri reddedilir.
+
+Örnek:
+
+    let items = [1, 2, 3]
+    print(items["0"])
+
+Düzeltme:
+
+    print(items[0])
+`},
+
+	PossiblyNil: {"nil olabilecek değer", `
+check --strict-null ile, tipi nil içeren bir değer nil'e izin verilmeyen
+bir yerde kullanılmış: üzerinde metot çağrılmış, bir üyesine erişilmiş ya
+da başka bir yere geçirilmiş. Önce nil kontrolü yapın; denetleyici dalın
+içinde tipi daraltır.
+
+Örnek:
+
+    function shout(name: string?)
+      print(name.upper())
+    end
+
+Düzeltme:
+
+    function shout(name: string?)
+      if name != nil
+        print(name.upper())
+      end
+    end
+`},
+
+	TypeArgumentCount: {"yanlış tip argümanı sayısı", `
+Generic bir sınıfa ya da fonksiyona, tanımladığı tip parametrelerinden
+daha fazla ya da daha az tip argümanı verilmiş.
+
+Örnek:
+
+    class Box[T]
+      function init(value: T)
+        self.value = value
+      end
+    end
+
+    let b: Box[int, string] = Box(1)
+
+Düzeltme:
+
+    let b: Box[int] = Box(1)
+`},
+
+	ConstraintNotSatisfied: {"tip kısıtı sağlanmıyor", `
+Bir tip argümanı, tip parametresinin kısıtını sağlamıyor: kısıtta adı
+geçen interface'in metotlarından bazıları eksik.
+
+Örnek:
+
+    interface Named
+      function name(): string
+    end
+
+    function loudest[T: Named](items: [T]): T
+      return items[0]
+    end
+
+    loudest([1, 2])
+
+Düzeltme: Named'i uygulayan bir tipin değerlerini geçirin.
+`},
+
+	DuplicateTypeParameter: {"tekrarlanan tip parametresi", `
+Aynı tip parametresi ismi iki kez tanımlanmış.
+
+Örnek:
+
+    function pair[T, T](a: T, b: T): [T]
+      return [a, b]
+    end
+
+Düzeltme:
+
+    function pair[A, B](a: A, b: B): [any]
+      return [a, b]
+    end
+`},
+
+	ExplicitTypeArguments: {"açık tip argümanları", `
+Generic bir sınıf ya da fonksiyon köşeli parantez içinde tip
+argümanlarıyla çağrılmış. Tip argümanları çağrının argümanlarından
+çıkarılır; yalnızca tip notasyonlarında yazılabilirler.
+
+Örnek:
+
+    let b = Box[int](3)
+
+Düzeltme:
+
+    let b = Box(3)
+    let c: Box[int] = Box(3)
+`},
+
+	// Classes and interfaces
+
+	InvalidSuperclass: {"geçersiz üst sınıf", `
+Bir sınıfın üst sınıfı tanımlı değil ya da bir sınıf değil.
+
+Örnek:
+
+    class Dog : Animl
+      function bark()
+        print("woof")
+      end
+    end
+
+Düzeltme: bu sınıftan önce tanımlanmış bir sınıfı belirtin.
+
+    class Dog : Animal
+      function bark()
+        print("woof")
+      end
+    end
+`},
+
+	InvalidInterface: {"geçersiz interface", `
+implements'ten sonra ya da bir interface'in extends listesinde kullanılan
+isim tanımlı değil ya da bir interface değil.
+
+Örnek:
+
+    class Circle
+      function init(r: float)
+        self.r = r
+      end
+    end
+
+    class Wheel implements Circle
+      function roll()
+        print("rolling")
+      end
+    end
+
+Düzeltme: bir interface uygulayın ya da bunun yerine sınıftan kalıtım alın.
+`},
+
+	InterfaceNotImplemented: {"interface uygulanmamış", `
+Bir sınıf bir interface'i uyguladığını bildiriyor ama metotlarından
+bazıları eksik ya da farklı imzalara sahip. Mesaj eksik olanları
+listeler.
+
+Örnek:
+
+    interface Shape
+      function area(): float
+    end
+
+    class Square implements Shape
+      function side(): float
+        return 1.0
+      end
+    end
+
+Düzeltme: eksik metotları uyan imzalarla ekleyin.
+
+    class Square implements Shape
+      function area(): float
+        return 1.0
+      end
+    end
+`},
+
+	IncompatibleOverride: {"uyumsuz override", `
+Bir metot, üst sınıftaki bir metodu uymayan bir imzayla eziyor: üst sınıf
+metodunu çağıranlar onu aynı şekilde çağıramaz. Parametreler orijinalin
+kabul ettiğini kabul etmeli, dönüş tipi de orijinalinkine atanabilir
+olmalıdır.
+
+Örnek:
+
+    class Animal
+      function speak(): string
+        return "..."
+      end
+    end
+
+    class Dog : Animal
+      function speak(): int
+        return 1
+      end
+    end
+
+Düzeltme:
+
+    class Dog : Animal
+      function speak(): string
+        return "woof"
+      end
+    end
+`},
+
+	InvalidSuper: {"geçersiz super kullanımı", `
+super, çevreleyen sınıfın üst sınıfını gösterir. Bir sınıfın dışında,
+üst sınıfı olmayan bir sınıfta ya da üst sınıfın sahip olmadığı bir
+metodu çağırmak için kullanılamaz.
+
+Örnek:
+
+    class Animal
+      function speak()
+        super.speak()
+      end
+    end
+
+Düzeltme: çağrıyı kaldırın ya da sınıfa bu metoda sahip bir üst sınıf
+verin.
+`},
+
+	InterfaceInstantiation: {"interface örneklenemez", `
+Interface'ler metotları tanımlar ve constructor'ları yoktur.
+
+Örnek:
+
+    interface Shape
+      function area(): float
+    end
+
+    let s = Shape()
+
+Düzeltme: interface'i uygulayan bir sınıfı örnekleyin.
+`},
+
+	DuplicateInterfaceMethod: {"tekrarlanan interface metodu", `
+Bir interface aynı metodu iki kez tanımlıyor.
+
+Örnek:
+
+    interface Shape
+      function area(): float
+      function area(): int
+    end
+
+Düzeltme: tek bir tanım bırakın.
+`},
+
+	// Modules
+
+	ModuleNotLoaded: {"modül yüklenemiyor", `
+Import edilen bir modül bulunamadı ya da sözdizimi hataları içeriyor.
+Modüller import eden dosyaya, çalışma dizinine ve kurulu wing
+paketlerine göre çözülür; import a.b, a/b.sky dosyasını yükler.
+
+Örnek:
+
+    import utils.strngs
+
+Düzeltme: modül yolunu ve dosya adını kontrol edin.
+
+    import utils.strings
+`},
+
+	ImportCycle: {"import döngüsü", `
+Modüller birbirini döngüsel olarak import ediyor, bu yüzden hiçbiri önce
+yüklenemiyor. Mesaj import zincirini gösterir.
+
+Örnek:
+
+    # a.sky
+    import b
+
+    # b.sky
+    import a
+
+Düzeltme: ortak kodu ikisinin de import ettiği üçüncü bir modüle taşıyın.
+`},
+
+	// Control flow
+
+	ReturnOutsideFunction: {"fonksiyon dışında return", `
+return bir fonksiyonu bitirir ve yalnızca bir fonksiyonun içinde
+bulunabilir. Linter da aynı kodu bildirir.
+
+Örnek:
+
+    let x = 1
+    return x
+
+Düzeltme: kodu bir fonksiyona koyun.
+
+    function main
+      let x = 1
+      return x
+    end
+`},
+
+	AwaitOutsideAsync: {"async fonksiyon dışında await", `
+await, bir promise sonuçlanana kadar geçerli async fonksiyonu askıya
+alır. Sıradan fonksiyonlarda ya da en üst seviyede kullanılamaz.
+
+Örnek:
+
+    function main
+      let data = await fetchData()
+    end
+
+Düzeltme:
+
+    async function main
+      let data = await fetchData()
+    end
+`},
+
+	YieldOutsideCoop: {"coop fonksiyon dışında yield", `
+yield bir coroutine'i çağırana bir değer verir ve yalnızca coop
+fonksiyonlarda kullanılabilir.
+
+Örnek:
+
+    yield 1
+
+    function numbers()
+      yield 1
+    end
+
+Düzeltme:
+
+    coop function numbers()
+      yield 1
+    end
+`},
+
+	UnawaitedPromise: {"promise await edilmemiş", `
+Bir async fonksiyonu çağırmak bir Promise[T] döndürür ve fonksiyonu arka
+planda çalıştırır. Promise ne await edilir ne de saklanırsa sonucu ve
+fırlattığı hatalar kaybolur; program da çağrı bitmeden sona erebilir.
+
+Örnek:
+
+    async function save(data: string): bool
+      return await fs_write_text_async("out.txt", data)
+    end
+
+    async function main
+      save("hello")
+    end
+
+Düzeltme:
+
+    async function main
+      await save("hello")
+    end
+
+Çağrıyı beklemeden başlatmak için promise'i saklayıp sonra await edin:
+
+    let pending = save("hello")
+`},
+
+	BlockingCall: {"async fonksiyonda bloklayan çağrı", `
+time_sleep gibi thread'i bloklayan bir builtin bir async fonksiyonun
+içinde çağrılmış. Çağrı çalışır, ama dönene kadar diğer bütün görevleri
+durdurur. Mesaj bunun yerine await edilecek async sürümü belirtir.
+
+Örnek:
+
+    async function poll()
+      time_sleep(100)
+    end
+
+Düzeltme:
+
+    async function poll()
+      await time_sleep_async(100)
+    end
+`},
+
+	// Pattern matching
+
+	NonExhaustiveMatch: {"eksik match", `
+Bir enum ya da bool üzerindeki match her durumu kapsamıyor, bu yüzden
+bazı değerler hiçbir kola uymaz. Mesaj eksik durumları listeler.
+
+Örnek:
+
+    enum Color
+      Red
+      Green
+      Blue
+    end
+
+    match c
+      Red() => print("red")
+      Green() => print("green")
+    end
+
+Düzeltme: eksik kolları ya da bir wildcard kolu ekleyin.
+
+    match c
+      Red() => print("red")
+      Green() => print("green")
+      _ => print("other")
+    end
+`},
+
+	UnreachableArm: {"ulaşılamayan match kolu", `
+Bir match kolu hiçbir zaman seçilemez, çünkü önceki kollar onun uyduğu
+her değeri zaten kapsıyor; örneğin bir wildcard'dan sonraki kol.
+
+Örnek:
+
+    match n
+      _ => print("any")
+      0 => print("zero")
+    end
+
+Düzeltme: kolları özelden genele doğru sıralayın.
+
+    match n
+      0 => print("zero")
+      _ => print("any")
+    end
+`},
+
+	DuplicateArm: {"tekrarlanan match kolu", `
+Bir match'in iki kolu aynı desene sahip; ikincisi hiçbir zaman seçilmez.
+
+Örnek:
+
+    match c
+      Red() => print("red")
+      Red() => print("still red")
+      _ => print("other")
+    end
+
+Düzeltme: tekrarlanan kolu kaldırın ya da birleştirin.
+`},
+
+	InvalidPattern: {"geçersiz desen", `
+Bir desen enum varyantı ya da sınıf olmayan bir şeyi belirtiyor, bir
+varyanta yanlış sayıda içerik deseni veriyor ya da alanları isimle
+eşleşen bir sınıf için sıralı desenler kullanıyor.
+
+Örnek:
+
+    enum Shape
+      Circle(int)
+    end
+
+    match s
+      Circle(r, extra) => print(r)
+    end
+
+Düzeltme:
+
+    match s
+      Circle(r) => print(r)
+    end
+`},
+
+	PatternTypeMismatch: {"desen tipi uyuşmazlığı", `
+Bir desen eşleştirilen değerin tipine hiçbir zaman uyamaz; örneğin bir
+int'e karşı string literal ya da başka bir enum'un varyantı.
+
+Örnek:
+
+    let n: int = 3
+    match n
+      "three" => print("3")
+      _ => print("?")
+    end
+
+Düzeltme: eşleştirilen değerin tipinde desenler kullanın.
+
+    match n
+      3 => print("3")
+      _ => print("?")
+    end
+`},
+
+	OrPatternBindings: {"or-deseni farklı değişkenler bağlıyor", `
+Bir or-deseninin her alternatifi aynı değişkenleri bağlamalıdır, böylece
+kolun gövdesi hangi alternatif uyarsa uysun onları kullanabilir.
+
+Örnek:
+
+    match s
+      Circle(r) | Square(w) => print(r)
+    end
+
+Düzeltme:
+
+    match s
+      Circle(r) | Square(r) => print(r)
+    end
+`},
+
+	// Runtime
+
+	RuntimeTypeError: {"çalışma zamanı tip hatası", `
+sky run --runtime-typecheck ile, program çalışırken bir tip notasyonundan
+geçen bir değer ona uymuyor: tipli bir parametreye bağlanan argüman,
+dönüş tipi olan bir fonksiyondan dönen değer ya da tipli bir let ile
+bağlanan değer. Mesaj fonksiyonu ya da değişkeni, argümanlar için de
+çağrının yapıldığı yeri belirtir. Bu değerler notasyonsuz koddan geçtiği
+için denetleyici onları göremez.
+
+Örnek:
+
+    function twice(n: int): int
+      return n * 2
+    end
+
+    function relay(value)
+      return twice(value)
+    end
+
+    relay("21")
+
+Düzeltme: değeri tipli koda girdiği yerde dönüştürün.
+
+    function relay(value)
+      return twice(int(value))
+    end
+`},
+
+	DivisionByZero: {"sıfıra bölme", `
+Bir int ya da float sıfıra bölünmüş. Linter bu kodu literal 0 ile
+bölmeler için bildirir; çalışma zamanında bölen sıfır olduğunda her
+zaman fırlatılır.
+
+Örnek:
+
+    let total = 10
+    let rate = total / 0
+
+Düzeltme: önce böleni kontrol edin.
+
+    if count != 0
+      let rate = total / count
+    end
+`},
+
+	IndexOutOfRange: {"indeks aralık dışında", `
+Bir liste ya da string sonunun ötesinde indekslenmiş. Geçerli indeksler
+0'dan len - 1'e kadardır; negatif indeksler sondan sayar.
+
+Örnek:
+
+    let items = [1, 2, 3]
+    print(items[3])
+
+Düzeltme:
+
+    print(items[len(items) - 1])
+`},
+
+	RecursionLimit: {"en fazla özyineleme derinliği aşıldı", `
+Çağrılar interpreter'ın izin verdiğinden daha derine iç içe geçmiş; bu
+neredeyse her zaman özyinelemeli bir fonksiyonun temel durumunun eksik
+olduğu anlamına gelir.
+
+Örnek:
+
+    function count(n)
+      return count(n + 1)
+    end
+
+    count(0)
+
+Düzeltme:
+
+    function count(n)
+      if n >= 10
+        return n
+      end
+      return count(n + 1)
+    end
+`},
+
+	NotCallable: {"değer çağrılabilir değil", `
+Ne fonksiyon ne de sınıf olan bir değer çağrılmış.
+
+Örnek:
+
+    let greeting = "hello"
+    greeting()
+
+Düzeltme: bir fonksiyon çağırın ya da parantezleri kaldırın.
+
+    print(greeting)
+`},
+
+	NotIterable: {"değer üzerinde dolaşılamaz", `
+Bir for döngüsüne üzerinde dolaşamayacağı bir değer verilmiş. Listeler,
+dict'ler, string'ler, range'ler ve generator'lar üzerinde dolaşılabilir.
+
+Örnek:
+
+    for x in 42
+      print(x)
+    end
+
+Düzeltme:
+
+    for x in range(42)
+      print(x)
+    end
+`},
+
+	// Lint
+
+	UnusedVariable: {"kullanılmayan değişken", `
+Bir değişken tanımlanmış ama hiç okunmamış. Onu kaldırın ya da bilerek
+kullanılmadığını belirtmek için ismini alt çizgiyle başlatın.
+
+Örnek:
+
+    let result = compute()
+
+Düzeltme:
+
+    let _result = compute()
+`},
+
+	ShadowedVariable: {"gölgelenen değişken", `
+Bir değişken önceki bir tanımın ismiyle yeniden tanımlanmış; bu öncekini
+gizler ve kolayca bir atamayla karıştırılır.
+
+Örnek:
+
+    let total = 0
+    let total = 10
+
+Düzeltme: var olan değişkene atama yapın ya da başka bir isim seçin.
+
+    let total = 0
+    total = 10
+`},
+
+	UnsafeBlock: {"unsafe blok", `
+Bir unsafe bloktaki kod, ham pointer'lardaki sınır kontrolleri gibi dilin
+güvenlik kontrollerini atlar. Linter her bloğu incelenebilmesi için
+işaretler; bir blok kontrol edildikten sonra uyarıyı bastırın.
+
+Örnek:
+
+    unsafe
+      let p = alloc(16)
+    end
+
+İncelemeden sonra bastırın:
+
+    # sky-ignore: W2003 reviewed: buffer is freed below
+    unsafe
+      let p = alloc(16)
+    end
+`},
+}
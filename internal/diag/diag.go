@@ -0,0 +1,220 @@
+// Package diag is the catalog of diagnostic codes shared by the parser,
+// the semantic checker, the linter and the runtime.
+//
+// A code is a letter and four digits. E codes are errors and W codes are
+// warnings; the first two digits group codes by area:
+//
+//	E00xx  syntax (parser)
+//	E01xx  names and scopes
+//	E02xx  types
+//	E03xx  classes and interfaces
+//	E04xx  modules
+//	E05xx  control flow
+//	W05xx  control flow warnings
+//	E06xx  pattern matching
+//	E09xx  runtime errors
+//	W20xx  lint warnings
+//
+// Codes are stable: a code is never reused for a different problem, so it
+// can be searched for, explained with sky explain and suppressed with a
+// "# sky-ignore: CODE" comment.
+package diag
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Syntax errors
+const (
+	UnexpectedToken    = "E0001"
+	ExpectedExpression = "E0002"
+	InvalidNumber      = "E0003"
+	InvalidSyntax      = "E0004"
+)
+
+// Names and scopes
+const (
+	DuplicateDefinition = "E0101"
+	UndefinedName       = "E0102"
+	AssignToConst       = "E0103"
+	MissingConstValue   = "E0104"
+	UndefinedMember     = "E0105"
+)
+
+// Types
+const (
+	TypeMismatch           = "E0201"
+	ReturnTypeMismatch     = "E0202"
+	ArgumentTypeMismatch   = "E0203"
+	ArgumentCount          = "E0204"
+	NonBoolCondition       = "E0205"
+	InvalidOperand         = "E0206"
+	InvalidIndex           = "E0207"
+	PossiblyNil            = "E0208"
+	TypeArgumentCount      = "E0209"
+	ConstraintNotSatisfied = "E0210"
+	DuplicateTypeParameter = "E0211"
+	ExplicitTypeArguments  = "E0212"
+)
+
+// Classes and interfaces
+const (
+	InvalidSuperclass        = "E0301"
+	InvalidInterface         = "E0302"
+	InterfaceNotImplemented  = "E0303"
+	IncompatibleOverride     = "E0304"
+	InvalidSuper             = "E0305"
+	InterfaceInstantiation   = "E0306"
+	DuplicateInterfaceMethod = "E0307"
+)
+
+// Modules
+const (
+	ModuleNotLoaded = "E0401"
+	ImportCycle     = "E0402"
+)
+
+// Control flow
+const (
+	ReturnOutsideFunction = "E0501"
+	AwaitOutsideAsync     = "E0502"
+	YieldOutsideCoop      = "E0503"
+	UnawaitedPromise      = "E0504"
+)
+
+// Control flow warnings
+const (
+	BlockingCall = "W0505"
+)
+
+// Pattern matching
+const (
+	NonExhaustiveMatch  = "E0601"
+	UnreachableArm      = "E0602"
+	DuplicateArm        = "E0603"
+	InvalidPattern      = "E0604"
+	PatternTypeMismatch = "E0605"
+	OrPatternBindings   = "E0606"
+)
+
+// Runtime errors
+const (
+	RuntimeTypeError = "E0901"
+	DivisionByZero   = "E0902"
+	IndexOutOfRange  = "E0903"
+	RecursionLimit   = "E0904"
+	NotCallable      = "E0905"
+	NotIterable      = "E0906"
+)
+
+// Lint warnings
+const (
+	UnusedVariable   = "W2001"
+	ShadowedVariable = "W2002"
+	UnsafeBlock      = "W2003"
+)
+
+// Entry describes a diagnostic code
+type Entry struct {
+	Code        string
+	Title       string // short description, e.g. "undefined variable"
+	Explanation string // long form with examples, as printed by sky explain
+}
+
+// Translation is the title and explanation of an entry in another language
+type Translation struct {
+	Title       string
+	Explanation string
+}
+
+// translations holds the entries in languages other than English, by
+// language and code
+var translations = map[string]map[string]Translation{
+	"tr": catalogTR,
+}
+
+// Text returns the title and explanation of e in lang, such as "tr", and
+// in English when there is no translation
+func (e *Entry) Text(lang string) (title, explanation string) {
+	if t, ok := translations[lang][e.Code]; ok {
+		return t.Title, t.Explanation
+	}
+	return e.Title, e.Explanation
+}
+
+// Severity returns "error" for E codes and "warning" for W codes
+func (e *Entry) Severity() string {
+	if strings.HasPrefix(e.Code, "W") {
+		return "warning"
+	}
+	return "error"
+}
+
+var byCode = func() map[string]*Entry {
+	m := make(map[string]*Entry, len(catalog))
+	for _, e := range catalog {
+		m[e.Code] = e
+	}
+	return m
+}()
+
+// Lookup returns the entry for code; the letter may be given in lower case
+func Lookup(code string) (*Entry, bool) {
+	e, ok := byCode[strings.ToUpper(strings.TrimSpace(code))]
+	return e, ok
+}
+
+// Entries returns every entry ordered by code
+func Entries() []*Entry {
+	entries := make([]*Entry, len(catalog))
+	copy(entries, catalog)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Code < entries[j].Code })
+	return entries
+}
+
+// IsCode reports whether s has the form of a code: E or W and four digits
+func IsCode(s string) bool {
+	if len(s) != 5 || (s[0] != 'E' && s[0] != 'W') {
+		return false
+	}
+	for _, c := range s[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Coded is implemented by errors that carry a diagnostic code
+type Coded interface {
+	DiagnosticCode() string
+}
+
+// CodeOf returns the code of err or of an error it wraps, "" when it has none
+func CodeOf(err error) string {
+	var coded Coded
+	if errors.As(err, &coded) {
+		return coded.DiagnosticCode()
+	}
+	return ""
+}
+
+// Annotate returns the message of err followed by its code in brackets,
+// the way the command line prints diagnostics
+func Annotate(err error) string {
+	if code := CodeOf(err); code != "" {
+		return err.Error() + " [" + code + "]"
+	}
+	return err.Error()
+}
+
+// SplitCode separates a message that ends in a bracketed code, as parser
+// errors do, into the message and the code
+func SplitCode(msg string) (string, string) {
+	if n := len(msg); n >= 8 && msg[n-1] == ']' && msg[n-8:n-6] == " [" && IsCode(msg[n-6:n-1]) {
+		return msg[:n-8], msg[n-6 : n-1]
+	}
+	return msg, ""
+}
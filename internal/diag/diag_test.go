@@ -0,0 +1,175 @@
+package diag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, entry := range catalog {
+		if !IsCode(entry.Code) {
+			t.Errorf("%q is not a valid code", entry.Code)
+		}
+		if seen[entry.Code] {
+			t.Errorf("%s is listed twice", entry.Code)
+		}
+		seen[entry.Code] = true
+		if entry.Title == "" {
+			t.Errorf("%s has no title", entry.Code)
+		}
+		if !strings.Contains(entry.Explanation, "Example:") {
+			t.Errorf("%s has no example in its explanation", entry.Code)
+		}
+	}
+
+	entries := Entries()
+	for i := 1; i < len(entries); i++ {
+		if entries[i-1].Code >= entries[i].Code {
+			t.Errorf("entries not ordered: %s before %s", entries[i-1].Code, entries[i].Code)
+		}
+	}
+}
+
+// examples returns the indented code lines of an explanation
+func examples(explanation string) []string {
+	var lines []string
+	for _, line := range strings.Split(explanation, "\n") {
+		if strings.HasPrefix(line, "    ") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestCatalogTurkish(t *testing.T) {
+	for _, entry := range catalog {
+		tr, ok := catalogTR[entry.Code]
+		if !ok {
+			t.Errorf("%s has no Turkish translation", entry.Code)
+			continue
+		}
+		if tr.Title == "" || !strings.Contains(tr.Explanation, "Örnek:") {
+			t.Errorf("%s has no Turkish title or example", entry.Code)
+		}
+		if got, want := examples(tr.Explanation), examples(entry.Explanation); strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%s: Turkish examples differ from the English ones", entry.Code)
+		}
+	}
+	for code := range catalogTR {
+		if _, ok := byCode[code]; !ok {
+			t.Errorf("translation of %s, which is not in the catalog", code)
+		}
+	}
+
+	entry, _ := Lookup(UndefinedName)
+	if title, _ := entry.Text("tr"); title != "tanımsız değişken" {
+		t.Errorf("Turkish title is %q", title)
+	}
+	if title, _ := entry.Text("en"); title != entry.Title {
+		t.Errorf("English title is %q", title)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	entry, ok := Lookup("e0102")
+	if !ok || entry.Code != UndefinedName || entry.Title != "undefined variable" {
+		t.Fatalf("Lookup(e0102) = %v, %v", entry, ok)
+	}
+	if entry.Severity() != "error" {
+		t.Errorf("E0102 severity is %s", entry.Severity())
+	}
+	if entry, _ := Lookup(UnusedVariable); entry.Severity() != "warning" {
+		t.Errorf("W2001 severity is %s", entry.Severity())
+	}
+	if _, ok := Lookup("E9999"); ok {
+		t.Error("E9999 should not exist")
+	}
+}
+
+type codedError struct{ code string }
+
+func (e *codedError) Error() string          { return "boom" }
+func (e *codedError) DiagnosticCode() string { return e.code }
+
+func TestAnnotate(t *testing.T) {
+	err := &codedError{code: DivisionByZero}
+	if got := Annotate(err); got != "boom [E0902]" {
+		t.Errorf("Annotate = %q", got)
+	}
+	wrapped := fmt.Errorf("runtime error: %w", err)
+	if got := Annotate(wrapped); got != "runtime error: boom [E0902]" {
+		t.Errorf("Annotate of wrapped error = %q", got)
+	}
+	if got := Annotate(errors.New("plain")); got != "plain" {
+		t.Errorf("Annotate of plain error = %q", got)
+	}
+	if got := Annotate(&codedError{}); got != "boom" {
+		t.Errorf("Annotate without code = %q", got)
+	}
+}
+
+func TestSplitCode(t *testing.T) {
+	msg, code := SplitCode("unexpected ) at a.sky:1:2 [E0001]")
+	if msg != "unexpected ) at a.sky:1:2" || code != UnexpectedToken {
+		t.Errorf("SplitCode = %q, %q", msg, code)
+	}
+	msg, code = SplitCode("list [int]")
+	if msg != "list [int]" || code != "" {
+		t.Errorf("SplitCode without code = %q, %q", msg, code)
+	}
+}
+
+func TestParseSuppressions(t *testing.T) {
+	source := `let a = 1  # sky-ignore: W2001
+# sky-ignore: E0102, e0201 checked by hand
+let b = missing
+let c = "# sky-ignore: E0201"
+# sky-ignore: not a code E0105
+let d = 2
+# an ordinary comment
+let e = 3`
+
+	s := ParseSuppressions(source)
+	tests := []struct {
+		code string
+		line int
+		want bool
+	}{
+		{UnusedVariable, 1, true},
+		{UnusedVariable, 2, false},
+		{UndefinedName, 3, true},
+		{TypeMismatch, 3, true},
+		{UndefinedName, 2, false},
+		{TypeMismatch, 4, false},
+		{TypeMismatch, 5, false},
+		{UndefinedMember, 6, false},
+		{"", 3, false},
+	}
+	for _, tt := range tests {
+		if got := s.Suppressed(tt.code, tt.line); got != tt.want {
+			t.Errorf("Suppressed(%s, %d) = %v, want %v", tt.code, tt.line, got, tt.want)
+		}
+	}
+
+	if ParseSuppressions("let x = 1") != nil {
+		t.Error("source without suppressions should give nil")
+	}
+}
+
+func TestDirectives(t *testing.T) {
+	source := `let a = 1  # sky-lint: disable=unused-var
+# sky-lint: disable=shadowing
+let a = 2
+# sky-ignore: E0102`
+
+	d := Directives(source, "sky-lint:")
+	if len(d) != 2 || d[1][0] != " disable=unused-var" || d[3][0] != " disable=shadowing" {
+		t.Errorf("Directives = %q", d)
+	}
+	if Directives("let x = 1", "sky-lint:") != nil {
+		t.Error("source without directives should give nil")
+	}
+}
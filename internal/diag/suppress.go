@@ -0,0 +1,101 @@
+package diag
+
+import (
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+)
+
+// suppressPrefix starts a suppression comment:
+//
+//	let unused = 1  # sky-ignore: W2001
+//
+//	# sky-ignore: E0102, E0201
+//	print(missing)
+//
+// A comment after code on a line suppresses the listed codes on that
+// line; a comment on a line of its own suppresses them on the next line.
+// Anything after the codes is a free-form reason.
+const suppressPrefix = "sky-ignore:"
+
+// Suppressions maps source lines to the codes suppressed on them
+type Suppressions map[int]map[string]bool
+
+// ParseSuppressions collects the suppression comments of source
+func ParseSuppressions(source string) Suppressions {
+	directives := Directives(source, suppressPrefix)
+	if len(directives) == 0 {
+		return nil
+	}
+	s := make(Suppressions)
+	for line, texts := range directives {
+		for _, text := range texts {
+			for _, code := range suppressedCodes(text) {
+				if s[line] == nil {
+					s[line] = make(map[string]bool)
+				}
+				s[line][code] = true
+			}
+		}
+	}
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+// Directives collects the comments of source that start with prefix, such
+// as "sky-ignore:", keyed by the line they apply to: the line of the
+// comment when it follows code, else the next line. The text after the
+// prefix is returned.
+func Directives(source, prefix string) map[int][]string {
+	if !strings.Contains(source, prefix) {
+		return nil
+	}
+	directives := make(map[int][]string)
+	l := lexer.New(source, "")
+	codeOnLine := 0
+	for {
+		tok := l.NextToken()
+		switch tok.Type {
+		case lexer.EOF:
+			return directives
+		case lexer.NEWLINE, lexer.INDENT, lexer.DEDENT:
+			continue
+		case lexer.COMMENT:
+			text := strings.TrimSpace(strings.TrimPrefix(tok.Literal, "#"))
+			if !strings.HasPrefix(text, prefix) {
+				continue
+			}
+			line := tok.Line
+			if codeOnLine != tok.Line {
+				line++
+			}
+			directives[line] = append(directives[line], text[len(prefix):])
+		default:
+			codeOnLine = tok.Line
+		}
+	}
+}
+
+// suppressedCodes returns the codes listed after the prefix of a
+// suppression comment
+func suppressedCodes(text string) []string {
+	var codes []string
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	for _, field := range fields {
+		code := strings.ToUpper(field)
+		if !IsCode(code) {
+			break
+		}
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// Suppressed reports whether code is suppressed on line
+func (s Suppressions) Suppressed(code string, line int) bool {
+	return code != "" && s[line][code]
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/mburakmmm/sky-lang/internal/ast"
 	"github.com/mburakmmm/sky-lang/internal/lexer"
 	"github.com/mburakmmm/sky-lang/internal/parser"
+	"github.com/mburakmmm/sky-lang/internal/sema"
 )
 
 // DocEntry represents a documentation entry
@@ -24,6 +25,8 @@ type DocGenerator struct {
 	entries  []DocEntry
 	filename string
 	comments []string
+	// return types the checker inferred for unannotated functions
+	returns map[*ast.FunctionStatement]sema.Type
 }
 
 // NewDocGenerator creates a new doc generator
@@ -39,6 +42,17 @@ func (d *DocGenerator) Generate(program *ast.Program, filename string) []DocEntr
 	d.filename = filename
 	d.entries = []DocEntry{}
 
+	// Type errors do not stop the documentation
+	checker := sema.NewChecker()
+	checker.SetSourceFile(filename)
+	checker.Check(program)
+	d.returns = make(map[*ast.FunctionStatement]sema.Type)
+	for _, f := range checker.Functions() {
+		if f.ReturnInferred {
+			d.returns[f.Node] = f.Type.ReturnType
+		}
+	}
+
 	for _, stmt := range program.Statements {
 		d.processStatement(stmt)
 	}
@@ -140,6 +154,9 @@ func (d *DocGenerator) buildFunctionSignature(stmt *ast.FunctionStatement) strin
 	if stmt.ReturnType != nil {
 		sb.WriteString(": ")
 		sb.WriteString(d.typeToString(stmt.ReturnType))
+	} else if t, ok := d.returns[stmt]; ok && t != sema.VoidType {
+		sb.WriteString(": ")
+		sb.WriteString(t.String())
 	}
 
 	return sb.String()
@@ -53,6 +53,9 @@ func (f *Formatter) needsBlankLine(stmt1, stmt2 ast.Statement) bool {
 	if _, ok := stmt1.(*ast.ClassStatement); ok {
 		return true
 	}
+	if _, ok := stmt1.(*ast.InterfaceStatement); ok {
+		return true
+	}
 	return false
 }
 
@@ -67,6 +70,8 @@ func (f *Formatter) formatStatement(stmt ast.Statement) {
 		f.formatFunctionStatement(s)
 	case *ast.ClassStatement:
 		f.formatClassStatement(s)
+	case *ast.InterfaceStatement:
+		f.formatInterfaceStatement(s)
 	case *ast.ReturnStatement:
 		f.formatReturnStatement(s)
 	case *ast.BreakStatement:
@@ -131,6 +136,7 @@ func (f *Formatter) formatFunctionStatement(stmt *ast.FunctionStatement) {
 
 	f.output.WriteString("function ")
 	f.output.WriteString(stmt.Name.Value)
+	f.formatTypeParams(stmt.TypeParams)
 
 	// Parameters
 	if len(stmt.Parameters) > 0 {
@@ -171,6 +177,7 @@ func (f *Formatter) formatClassStatement(stmt *ast.ClassStatement) {
 	f.writeIndent()
 	f.output.WriteString("class ")
 	f.output.WriteString(stmt.Name.Value)
+	f.formatTypeParams(stmt.TypeParams)
 
 	if len(stmt.SuperClasses) > 0 {
 		f.output.WriteString(" : ")
@@ -182,6 +189,16 @@ func (f *Formatter) formatClassStatement(stmt *ast.ClassStatement) {
 		}
 	}
 
+	if len(stmt.Interfaces) > 0 {
+		f.output.WriteString(" implements ")
+		for i, iface := range stmt.Interfaces {
+			if i > 0 {
+				f.output.WriteString(", ")
+			}
+			f.output.WriteString(iface.Value)
+		}
+	}
+
 	f.output.WriteString("\n")
 
 	// Body
@@ -195,6 +212,55 @@ func (f *Formatter) formatClassStatement(stmt *ast.ClassStatement) {
 	f.output.WriteString("end\n")
 }
 
+func (f *Formatter) formatInterfaceStatement(stmt *ast.InterfaceStatement) {
+	f.writeIndent()
+	f.output.WriteString("interface ")
+	f.output.WriteString(stmt.Name.Value)
+
+	if len(stmt.Extends) > 0 {
+		f.output.WriteString(" : ")
+		for i, parent := range stmt.Extends {
+			if i > 0 {
+				f.output.WriteString(", ")
+			}
+			f.output.WriteString(parent.Value)
+		}
+	}
+
+	f.output.WriteString("\n")
+
+	// Method signatures
+	f.indentLevel++
+	for _, method := range stmt.Methods {
+		f.writeIndent()
+		f.output.WriteString("function ")
+		f.output.WriteString(method.Name.Value)
+		if len(method.Parameters) > 0 {
+			f.output.WriteString("(")
+			for i, param := range method.Parameters {
+				if i > 0 {
+					f.output.WriteString(", ")
+				}
+				f.output.WriteString(param.Name.Value)
+				if param.Type != nil {
+					f.output.WriteString(": ")
+					f.formatType(param.Type)
+				}
+			}
+			f.output.WriteString(")")
+		}
+		if method.ReturnType != nil {
+			f.output.WriteString(": ")
+			f.formatType(method.ReturnType)
+		}
+		f.output.WriteString("\n")
+	}
+	f.indentLevel--
+
+	f.writeIndent()
+	f.output.WriteString("end\n")
+}
+
 func (f *Formatter) formatIfStatement(stmt *ast.IfStatement) {
 	f.writeIndent()
 	f.output.WriteString("if ")
@@ -415,7 +481,35 @@ func (f *Formatter) formatType(typeAnn ast.TypeAnnotation) {
 		}
 		f.output.WriteString(") => ")
 		f.formatType(t.ReturnType)
+	case *ast.GenericType:
+		f.output.WriteString(t.BaseName)
+		f.output.WriteString("[")
+		for i, arg := range t.TypeArgs {
+			if i > 0 {
+				f.output.WriteString(", ")
+			}
+			f.formatType(arg)
+		}
+		f.output.WriteString("]")
+	}
+}
+
+func (f *Formatter) formatTypeParams(params []*ast.TypeParameter) {
+	if len(params) == 0 {
+		return
+	}
+	f.output.WriteString("[")
+	for i, param := range params {
+		if i > 0 {
+			f.output.WriteString(", ")
+		}
+		f.output.WriteString(param.Name.Value)
+		if param.Constraint != nil {
+			f.output.WriteString(": ")
+			f.formatType(param.Constraint)
+		}
 	}
+	f.output.WriteString("]")
 }
 
 func (f *Formatter) writeIndent() {
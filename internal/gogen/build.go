@@ -0,0 +1,89 @@
+package gogen
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+)
+
+// The runtime is copied next to the generated code, so building a program
+// needs nothing but the go toolchain
+//
+//go:embed skyrt/*.go
+var runtimeFiles embed.FS
+
+// goMod is the module of the generated program
+const goMod = "module skyapp\n\ngo 1.21\n"
+
+// Build compiles program, read from sourceFile, to a native binary at
+// outputPath using the go command found in PATH
+func Build(program *ast.Program, sourceFile, outputPath string) error {
+	src, err := Generate(program, sourceFile)
+	if err != nil {
+		return err
+	}
+
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		return fmt.Errorf("the Go backend needs the go toolchain: %v", err)
+	}
+	output, err := filepath.Abs(outputPath)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "sky-build-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := writePackage(dir, src); err != nil {
+		return err
+	}
+
+	// A static binary built with the local toolchain only
+	cmd := exec.Command(goTool, "build", "-trimpath", "-o", output, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOFLAGS=", "GOWORK=off", "GOTOOLCHAIN=local")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("go build failed: %v\n%s", err, out)
+	}
+	return nil
+}
+
+// writePackage writes the generated program and the runtime into dir
+func writePackage(dir string, src []byte) error {
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src, 0644); err != nil {
+		return err
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "skyrt"), 0755); err != nil {
+		return err
+	}
+	entries, err := runtimeFiles.ReadDir("skyrt")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		data, err := runtimeFiles.ReadFile("skyrt/" + entry.Name())
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, "skyrt", entry.Name()), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,392 @@
+package gogen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+)
+
+// binaryOps maps infix and compound assignment operators to runtime functions
+var binaryOps = map[string]string{
+	"+": "rt.Add", "-": "rt.Sub", "*": "rt.Mul", "/": "rt.Div", "%": "rt.Mod",
+	"==": "rt.Equal", "!=": "rt.NotEqual",
+	">": "rt.Greater", ">=": "rt.GreaterEqual", "<": "rt.Less", "<=": "rt.LessEqual",
+	"+=": "rt.Add", "-=": "rt.Sub", "*=": "rt.Mul", "/=": "rt.Div", "%=": "rt.Mod",
+}
+
+// expr compiles an expression to a Go expression. Statements it needs
+// first, like the arms of a match, are written to f.out.
+func (f *fn) expr(expr ast.Expression) (string, error) {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		return fmt.Sprintf("rt.Int(%d)", e.Value), nil
+
+	case *ast.FloatLiteral:
+		return fmt.Sprintf("rt.Float(%s)", strconv.FormatFloat(e.Value, 'g', -1, 64)), nil
+
+	case *ast.StringLiteral:
+		return fmt.Sprintf("rt.Str(%s)", strconv.Quote(e.Value)), nil
+
+	case *ast.BooleanLiteral:
+		return fmt.Sprintf("rt.Bool(%t)", e.Value), nil
+
+	case *ast.Identifier:
+		// super inside a method refers to the defining class's superclass
+		if e.Value == "super" && f.inMethod() {
+			return fmt.Sprintf("rt.Super(%s)", f.class), nil
+		}
+		if err := f.checkName(e.Value); err != nil {
+			return "", err
+		}
+		return f.get(e.Value), nil
+
+	case *ast.ListLiteral:
+		elements, err := f.exprs(e.Elements...)
+		if err != nil {
+			return "", err
+		}
+		return "rt.NewList(" + strings.Join(elements, ", ") + ")", nil
+
+	case *ast.DictLiteral:
+		// Pairs are evaluated in source order
+		keys := make([]ast.Expression, 0, len(e.Pairs))
+		for key := range e.Pairs {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			a, b := keys[i].Pos(), keys[j].Pos()
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Column < b.Column
+		})
+		operands := make([]ast.Expression, 0, 2*len(keys))
+		for _, key := range keys {
+			operands = append(operands, key, e.Pairs[key])
+		}
+		pairs, err := f.exprs(operands...)
+		if err != nil {
+			return "", err
+		}
+		return "rt.NewDict(" + strings.Join(pairs, ", ") + ")", nil
+
+	case *ast.IndexExpression:
+		operands, err := f.exprs(e.Left, e.Index)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("rt.Index(%s, %s)", operands[0], operands[1]), nil
+
+	case *ast.MemberExpression:
+		object, err := f.expr(e.Object)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("rt.GetMember(%s, %q)", object, e.Member.Value), nil
+
+	case *ast.InfixExpression:
+		return f.infixExpression(e)
+
+	case *ast.PrefixExpression:
+		right, err := f.expr(e.Right)
+		if err != nil {
+			return "", err
+		}
+		switch e.Operator {
+		case "!":
+			return fmt.Sprintf("rt.Not(%s)", right), nil
+		case "-":
+			return fmt.Sprintf("rt.Negate(%s)", right), nil
+		case "+":
+			// Unary plus is the identity
+			return right, nil
+		}
+		return "", f.errorf("unknown prefix operator: %s", e.Operator)
+
+	case *ast.CallExpression:
+		return f.callExpression(e)
+
+	case *ast.LambdaExpression:
+		// Lambdas print as <function lambda> like in the interpreter
+		return f.function("lambda", e.Parameters, e.Body, false, false, "")
+
+	case *ast.ArrowExpression:
+		return f.expr(e.Right)
+
+	case *ast.MatchExpression:
+		return f.matchExpression(e)
+
+	case *ast.AwaitExpression:
+		value, err := f.expr(e.Expression)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("rt.Await(%s)", value), nil
+
+	case *ast.YieldExpression:
+		// Coop functions suspend; elsewhere yield passes its value through
+		value, err := f.value(e.Value)
+		if err != nil {
+			return "", err
+		}
+		if f.gen == "" {
+			return value, nil
+		}
+		return fmt.Sprintf("%s.Yield(%s)", f.gen, value), nil
+
+	default:
+		return "", f.errorf("unknown expression type: %T", expr)
+	}
+}
+
+// exprs compiles operands evaluated from left to right. Go evaluates the
+// calls in an expression in order but may read a variable after a later
+// call, so a local read before an operand with side effects is copied
+// first; operands that need statements move everything before them into
+// temporaries.
+func (f *fn) exprs(list ...ast.Expression) ([]string, error) {
+	codes := make([]string, len(list))
+	for i, expr := range list {
+		mark := f.out.Len()
+		code, err := f.expr(expr)
+		if err != nil {
+			return nil, err
+		}
+		pre := f.cut(mark)
+		if len(pre) > 0 || hasSideEffects(expr) {
+			for j := 0; j < i; j++ {
+				if len(pre) > 0 && !isConstant(list[j]) || f.isLocal(codes[j]) {
+					codes[j] = f.spill(codes[j])
+				}
+			}
+		}
+		f.out.Write(pre)
+		codes[i] = code
+	}
+	return codes, nil
+}
+
+// isLocal reports whether code reads a local variable
+func (f *fn) isLocal(code string) bool {
+	if !strings.HasPrefix(code, "l") {
+		return false
+	}
+	_, err := strconv.Atoi(strings.SplitN(code[1:], "_", 2)[0])
+	return err == nil
+}
+
+// isConstant reports whether expr is a literal
+func isConstant(expr ast.Expression) bool {
+	switch expr.(type) {
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.BooleanLiteral:
+		return true
+	}
+	return false
+}
+
+// hasSideEffects reports whether evaluating expr may change variables
+func hasSideEffects(expr ast.Expression) bool {
+	switch e := expr.(type) {
+	case *ast.CallExpression, *ast.AwaitExpression, *ast.YieldExpression, *ast.MatchExpression:
+		return true
+	case *ast.InfixExpression:
+		switch e.Operator {
+		case "=", "+=", "-=", "*=", "/=", "%=":
+			return true
+		}
+		return hasSideEffects(e.Left) || hasSideEffects(e.Right)
+	case *ast.PrefixExpression:
+		return hasSideEffects(e.Right)
+	case *ast.IndexExpression:
+		return hasSideEffects(e.Left) || hasSideEffects(e.Index)
+	case *ast.MemberExpression:
+		return hasSideEffects(e.Object)
+	case *ast.ArrowExpression:
+		return hasSideEffects(e.Right)
+	case *ast.ListLiteral:
+		for _, elem := range e.Elements {
+			if hasSideEffects(elem) {
+				return true
+			}
+		}
+	case *ast.DictLiteral:
+		for key, val := range e.Pairs {
+			if hasSideEffects(key) || hasSideEffects(val) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f *fn) infixExpression(expr *ast.InfixExpression) (string, error) {
+	switch expr.Operator {
+	case "=", "+=", "-=", "*=", "/=", "%=":
+		return f.assignExpression(expr)
+	case "&&", "||":
+		// && and || short-circuit and yield a boolean, like the interpreter
+		return f.logicalExpression(expr)
+	}
+
+	op, ok := binaryOps[expr.Operator]
+	if !ok {
+		return "", f.errorf("unknown operator: %s", expr.Operator)
+	}
+	operands, err := f.exprs(expr.Left, expr.Right)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s(%s, %s)", op, operands[0], operands[1]), nil
+}
+
+func (f *fn) logicalExpression(expr *ast.InfixExpression) (string, error) {
+	left, err := f.expr(expr.Left)
+	if err != nil {
+		return "", err
+	}
+	mark := f.out.Len()
+	right, err := f.expr(expr.Right)
+	if err != nil {
+		return "", err
+	}
+	pre := f.cut(mark)
+	if len(pre) == 0 {
+		return fmt.Sprintf("rt.Bool(rt.Truthy(%s) %s rt.Truthy(%s))", left, expr.Operator, right), nil
+	}
+
+	// The right operand needs statements, which only run when it is evaluated
+	result := f.temp()
+	if expr.Operator == "&&" {
+		f.printf("%s := rt.Value(rt.Bool(false))\nif rt.Truthy(%s) {\n", result, left)
+	} else {
+		f.printf("%s := rt.Value(rt.Bool(true))\nif !rt.Truthy(%s) {\n", result, left)
+	}
+	f.out.Write(pre)
+	f.printf("%s = rt.Bool(rt.Truthy(%s))\n}\n", result, right)
+	return result, nil
+}
+
+// assignTarget returns the Go variable a plain assignment to name stores
+// into, defining the variable if the name is undefined
+func (f *fn) assignTarget(name string) string {
+	if f.isUndefined(name) {
+		return f.defineTarget(name)
+	}
+	return f.target(name)
+}
+
+// assignExpression compiles assignments to variables, members and
+// indexes. The assignment evaluates to the assigned value.
+func (f *fn) assignExpression(expr *ast.InfixExpression) (string, error) {
+	compound := expr.Operator != "="
+	op := binaryOps[expr.Operator]
+
+	switch target := expr.Left.(type) {
+	case *ast.Identifier:
+		var value string
+		if compound {
+			operands, err := f.exprs(target, expr.Right)
+			if err != nil {
+				return "", err
+			}
+			value = fmt.Sprintf("%s(%s, %s)", op, operands[0], operands[1])
+		} else {
+			code, err := f.expr(expr.Right)
+			if err != nil {
+				return "", err
+			}
+			value = code
+		}
+		return fmt.Sprintf("rt.Set(&%s, %s)", f.assignTarget(target.Value), value), nil
+
+	case *ast.MemberExpression:
+		if !compound {
+			operands, err := f.exprs(target.Object, expr.Right)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("rt.SetMember(%s, %q, %s)", operands[0], target.Member.Value, operands[1]), nil
+		}
+		object, err := f.expr(target.Object)
+		if err != nil {
+			return "", err
+		}
+		object = f.spill(object)
+		right, err := f.expr(expr.Right)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("rt.SetMember(%s, %q, %s(rt.GetMember(%s, %q), %s))",
+			object, target.Member.Value, op, object, target.Member.Value, right), nil
+
+	case *ast.IndexExpression:
+		if !compound {
+			operands, err := f.exprs(target.Left, target.Index, expr.Right)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("rt.SetIndex(%s, %s, %s)", operands[0], operands[1], operands[2]), nil
+		}
+		// The target is evaluated again for the current value
+		operands, err := f.exprs(target.Left, target.Index, target.Left, target.Index, expr.Right)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("rt.SetIndex(%s, %s, %s(rt.Index(%s, %s), %s))",
+			operands[0], operands[1], op, operands[2], operands[3], operands[4]), nil
+	}
+
+	return "", f.errorf("invalid assignment target")
+}
+
+func (f *fn) callExpression(expr *ast.CallExpression) (string, error) {
+	switch callee := expr.Function.(type) {
+	case *ast.Identifier:
+		// print and len call the built-ins directly unless the program
+		// redefines them
+		if f.isUndefined(callee.Value) {
+			switch {
+			case callee.Value == "print":
+				args, err := f.exprs(expr.Arguments...)
+				if err != nil {
+					return "", err
+				}
+				return "rt.BuiltinPrint(" + strings.Join(args, ", ") + ")", nil
+			case callee.Value == "len" && len(expr.Arguments) == 1:
+				arg, err := f.expr(expr.Arguments[0])
+				if err != nil {
+					return "", err
+				}
+				return "rt.BuiltinLen(" + arg + ")", nil
+			}
+		}
+
+	case *ast.MemberExpression:
+		// Methods are called on their receiver without a bound method
+		if ident, ok := callee.Object.(*ast.Identifier); ok && ident.Value == "super" && f.inMethod() {
+			args, err := f.exprs(expr.Arguments...)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("rt.SuperInvoke(%s, %s, %q%s)", f.class, f.get("self"), callee.Member.Value, joinArgs(args)), nil
+		}
+		operands, err := f.exprs(append([]ast.Expression{callee.Object}, expr.Arguments...)...)
+		if err != nil {
+			return "", err
+		}
+		self := "nil"
+		if f.method {
+			self = f.get("self")
+		}
+		return fmt.Sprintf("rt.Invoke(%s, %s, %q%s)", self, operands[0], callee.Member.Value, joinArgs(operands[1:])), nil
+	}
+
+	operands, err := f.exprs(append([]ast.Expression{expr.Function}, expr.Arguments...)...)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("rt.Call(%q, %s%s)", expr.Function.String(), operands[0], joinArgs(operands[1:])), nil
+}
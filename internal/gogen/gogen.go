@@ -0,0 +1,424 @@
+// Package gogen is the pure-Go build backend. It lowers a SKY program to
+// the Go source of a main package that runs on the skyrt support package,
+// then builds it with the local go toolchain into a self-contained binary.
+//
+// The generated code resolves names like the bytecode compiler: top-level
+// names are globals, every other variable is a local of the function that
+// defines it, and nested functions capture the locals of their enclosing
+// functions. Locals become Go variables declared at the top of their Go
+// function, so closures share them like the VM's upvalues.
+package gogen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/gogen/skyrt"
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+	"github.com/mburakmmm/sky-lang/internal/parser"
+)
+
+// generator lowers a program and the modules it imports
+type generator struct {
+	sourceFile  string
+	currentDir  string
+	units       []*unit
+	modules     map[string]*unit // imported modules by import path
+	unsupported map[string]bool  // interpreter built-ins skyrt does not provide
+	ids         int
+}
+
+// unit is the main program or an imported module
+type unit struct {
+	id     int
+	prefix string // prefix of the Go names of its globals
+
+	variants map[string]string // enum variant name -> enum name (bare identifiers in patterns)
+	classes  map[string]bool   // class names (constructor-style patterns)
+	declared map[string]bool   // top-level names that shadow built-ins
+
+	globals map[string]bool // every global the code reads or writes
+	stored  map[string]bool // globals the code assigns, exported by modules
+	script  string
+}
+
+// Generate returns the Go source of the main package for program, read
+// from sourceFile. Imports are resolved like the VM does.
+func Generate(program *ast.Program, sourceFile string) ([]byte, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		sourceFile:  sourceFile,
+		currentDir:  cwd,
+		modules:     make(map[string]*unit),
+		unsupported: make(map[string]bool),
+	}
+	for name, val := range interpreter.Builtins() {
+		if _, ok := val.(*interpreter.Function); ok && skyrt.Builtins[name] == nil {
+			g.unsupported[name] = true
+		}
+	}
+
+	main := g.newUnit()
+	if err := g.compileUnit(main, program, true); err != nil {
+		return nil, err
+	}
+	return g.file(sourceFile)
+}
+
+func (g *generator) newUnit() *unit {
+	u := &unit{
+		id:       len(g.units),
+		prefix:   "g_",
+		variants: make(map[string]string),
+		classes:  make(map[string]bool),
+		declared: make(map[string]bool),
+		globals:  make(map[string]bool),
+		stored:   make(map[string]bool),
+	}
+	if u.id > 0 {
+		u.prefix = fmt.Sprintf("m%d_", u.id)
+	}
+	g.units = append(g.units, u)
+	return u
+}
+
+// newID returns a number for a unique Go name
+func (g *generator) newID() int {
+	g.ids++
+	return g.ids
+}
+
+// scriptName is the Go function running the top-level code of u
+func (u *unit) scriptName() string {
+	if u.id == 0 {
+		return "script"
+	}
+	return fmt.Sprintf("mod%dScript", u.id)
+}
+
+// compileUnit generates the script function of a program. callMain calls
+// main() after the top-level code, like the VM does for the main program.
+func (g *generator) compileUnit(u *unit, program *ast.Program, callMain bool) error {
+	u.declare(program.Statements)
+
+	f := &fn{g: g, u: u, scope: newScope(nil), class: "nil"}
+	hasMain := false
+	for _, stmt := range program.Statements {
+		if fn, ok := stmt.(*ast.FunctionStatement); ok && fn.Name.Value == "main" {
+			hasMain = true
+		}
+		if err := f.statement(stmt); err != nil {
+			return err
+		}
+	}
+
+	// Call main; async main returns a promise which is awaited
+	if callMain && hasMain {
+		f.printf("rt.Await(rt.Call(\"main\", %s))\n", f.get("main"))
+	}
+	f.printf("return rt.Nil{}\n")
+
+	u.script = fmt.Sprintf("func %s() rt.Value {\n%s%s}\n", u.scriptName(), f.declarations(), f.out.String())
+	return nil
+}
+
+// declare records top-level names, enum variants and classes
+func (u *unit) declare(stmts []ast.Statement) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.FunctionStatement:
+			u.declared[s.Name.Value] = true
+		case *ast.LetStatement:
+			u.declared[s.Name.Value] = true
+		case *ast.ConstStatement:
+			u.declared[s.Name.Value] = true
+		case *ast.ClassStatement:
+			u.declared[s.Name.Value] = true
+			u.classes[s.Name.Value] = true
+		case *ast.AbstractClassStatement:
+			u.declared[s.Name.Value] = true
+			u.classes[s.Name.Value] = true
+		case *ast.ExpressionStatement:
+			// Assigning an undefined name creates a global, like let
+			if assign, ok := s.Expression.(*ast.InfixExpression); ok && assign.Operator == "=" {
+				if ident, ok := assign.Left.(*ast.Identifier); ok {
+					u.declared[ident.Value] = true
+				}
+			}
+		case *ast.EnumStatement:
+			u.declared[s.Name.Value] = true
+			for _, variant := range s.Variants {
+				u.declared[variant.Name.Value] = true
+				u.variants[variant.Name.Value] = s.Name.Value
+			}
+		}
+	}
+}
+
+// module returns the unit of an imported module, compiling it on first use
+func (g *generator) module(path string) (*unit, error) {
+	if u, ok := g.modules[path]; ok {
+		return u, nil
+	}
+
+	file := interpreter.ResolveModulePath(path, g.sourceFile, g.currentDir)
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load module %s: %v", path, err)
+	}
+	p := parser.New(lexer.New(string(content), file))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil, fmt.Errorf("parse errors in module %s: %v", path, p.Errors())
+	}
+
+	// Registered first so that import cycles end
+	u := g.newUnit()
+	g.modules[path] = u
+	if err := g.compileUnit(u, program, false); err != nil {
+		return nil, fmt.Errorf("error in module %s: %v", path, err)
+	}
+	return u, nil
+}
+
+// file assembles the generated package and formats it
+func (g *generator) file(sourceFile string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by sky build from %s. DO NOT EDIT.\n\n", sourceFile)
+	buf.WriteString("package main\n\nimport rt \"skyapp/skyrt\"\n\n")
+
+	for _, u := range g.units {
+		if len(u.globals) > 0 {
+			buf.WriteString("var (\n")
+			for _, name := range sortedNames(u.globals) {
+				fmt.Fprintf(&buf, "%s%s rt.Value\n", u.prefix, name)
+			}
+			buf.WriteString(")\n\n")
+		}
+	}
+	for _, u := range g.units[1:] {
+		fmt.Fprintf(&buf, "var mod%d rt.Module\n", u.id)
+	}
+
+	buf.WriteString("\nfunc main() {\n\trt.Main(script)\n}\n\n")
+	for _, u := range g.units {
+		buf.WriteString(u.script)
+		buf.WriteString("\n")
+		if u.id == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "func mod%dExports() map[string]rt.Value {\nreturn map[string]rt.Value{\n", u.id)
+		for _, name := range sortedNames(u.stored) {
+			fmt.Fprintf(&buf, "%q: %s%s,\n", name, u.prefix, name)
+		}
+		buf.WriteString("}\n}\n\n")
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid Go code: %v", err)
+	}
+	return src, nil
+}
+
+func sortedNames(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// scope maps the names defined in a block scope to Go variables
+type scope struct {
+	outer *scope
+	vars  map[string]string
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{outer: outer, vars: make(map[string]string)}
+}
+
+// lookup finds the Go variable of name in s or its outer scopes
+func (s *scope) lookup(name string) (string, bool) {
+	for ; s != nil; s = s.outer {
+		if v, ok := s.vars[name]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// fn generates the body of a SKY function, or the script of a unit
+type fn struct {
+	g         *generator
+	u         *unit
+	enclosing *fn
+	scope     *scope
+	depth     int // block scope depth; the script's top level is 0
+	line      int // line of the statement being compiled
+
+	out    bytes.Buffer
+	locals []string // Go variables declared at the top of the function
+	loops  []loop
+	tries  int // try blocks open around the code being compiled
+
+	method bool   // self is the receiver
+	class  string // Go expression of the class defining the method, or nil
+	gen    string // generator variable of coop functions
+}
+
+// loop is an enclosing loop; tries counts the try blocks open outside it
+type loop struct {
+	tries int
+}
+
+func (f *fn) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&f.out, format, args...)
+}
+
+func (f *fn) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", f.line, fmt.Sprintf(format, args...))
+}
+
+// declarations declares the locals of the function, initialized to nil
+func (f *fn) declarations() string {
+	if len(f.locals) == 0 {
+		return ""
+	}
+	var buf strings.Builder
+	for _, v := range f.locals {
+		fmt.Fprintf(&buf, "var %s rt.Value = rt.Nil{}\n", v)
+	}
+	blanks := strings.TrimSuffix(strings.Repeat("_, ", len(f.locals)), ", ")
+	fmt.Fprintf(&buf, "%s = %s\n", blanks, strings.Join(f.locals, ", "))
+	return buf.String()
+}
+
+func (f *fn) enterScope() {
+	f.scope = newScope(f.scope)
+	f.depth++
+}
+
+func (f *fn) leaveScope() {
+	f.scope = f.scope.outer
+	f.depth--
+}
+
+// isGlobalScope reports whether definitions become globals
+func (f *fn) isGlobalScope() bool {
+	return f.enclosing == nil && f.depth == 0
+}
+
+// define adds a local to the current scope, reusing the variable if the
+// scope already has it
+func (f *fn) define(name string) string {
+	if v, ok := f.scope.vars[name]; ok {
+		return v
+	}
+	v := fmt.Sprintf("l%d_%s", f.g.newID(), name)
+	f.scope.vars[name] = v
+	f.locals = append(f.locals, v)
+	return v
+}
+
+// temp returns a new Go name for a temporary
+func (f *fn) temp() string {
+	return fmt.Sprintf("t%d", f.g.newID())
+}
+
+// spill evaluates code into a temporary and returns its name
+func (f *fn) spill(code string) string {
+	t := f.temp()
+	f.printf("%s := %s\n", t, code)
+	return t
+}
+
+// resolve finds a local of this or an enclosing function
+func (f *fn) resolve(name string) (string, bool) {
+	for fn := f; fn != nil; fn = fn.enclosing {
+		if v, ok := fn.scope.lookup(name); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// isUndefined reports whether name is not a known variable
+func (f *fn) isUndefined(name string) bool {
+	if f.u.declared[name] {
+		return false
+	}
+	_, ok := f.resolve(name)
+	return !ok
+}
+
+// inMethod reports whether self is visible from the code being compiled
+func (f *fn) inMethod() bool {
+	for fn := f; fn != nil; fn = fn.enclosing {
+		if fn.method {
+			return true
+		}
+	}
+	return false
+}
+
+// global returns the Go variable of a global
+func (f *fn) global(name string) string {
+	f.u.globals[name] = true
+	return f.u.prefix + name
+}
+
+// get returns the Go expression reading a variable. Globals fall back to
+// the built-in of the same name while unassigned.
+func (f *fn) get(name string) string {
+	if v, ok := f.resolve(name); ok {
+		return v
+	}
+	if f.isUndefined(name) && (name == "nil" || name == "null") {
+		return "rt.Nil{}"
+	}
+	return fmt.Sprintf("rt.Global(%s, %q)", f.global(name), name)
+}
+
+// checkName rejects built-ins the Go runtime does not provide
+func (f *fn) checkName(name string) error {
+	if f.g.unsupported[name] && f.isUndefined(name) {
+		return f.errorf("%s is not supported by the Go backend", name)
+	}
+	return nil
+}
+
+// target returns the Go variable an existing variable is assigned through
+func (f *fn) target(name string) string {
+	if v, ok := f.resolve(name); ok {
+		return v
+	}
+	f.u.stored[name] = true
+	return f.global(name)
+}
+
+// defineTarget returns the Go variable of a new variable: a global at the
+// top level, a local elsewhere
+func (f *fn) defineTarget(name string) string {
+	if f.isGlobalScope() {
+		f.u.stored[name] = true
+		return f.global(name)
+	}
+	return f.define(name)
+}
+
+// defineVariable assigns code to a new variable
+func (f *fn) defineVariable(name, code string) {
+	f.printf("%s = %s\n", f.defineTarget(name), code)
+}
@@ -0,0 +1,20 @@
+package gogen
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/mburakmmm/sky-lang/internal/backendtest"
+)
+
+// TestConformance builds every shared test program with the Go backend
+// and checks that the binary prints what the interpreter prints
+func TestConformance(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not found")
+	}
+	if testing.Short() {
+		t.Skip("builds binaries")
+	}
+	backendtest.Conformance(t, "go backend", Build)
+}
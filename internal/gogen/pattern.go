@@ -0,0 +1,296 @@
+package gogen
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+)
+
+// matchExpression compiles a match expression to statements leaving its
+// value in a temporary. Each arm runs unless an earlier arm matched; its
+// pattern tests nest as ifs around the guard and the body.
+func (f *fn) matchExpression(expr *ast.MatchExpression) (string, error) {
+	value, err := f.expr(expr.Value)
+	if err != nil {
+		return "", err
+	}
+
+	f.enterScope()
+	defer f.leaveScope()
+	subject, result, matched := f.spill(value), f.temp(), f.temp()
+	f.printf("_ = %s\n%s := rt.Value(rt.Nil{})\n%s := false\n", subject, result, matched)
+
+	for _, arm := range expr.Arms {
+		arm := arm
+		f.enterScope()
+		f.printf("if !%s {\n", matched)
+		err := f.pattern(arm.Pattern, subject, func() error {
+			// A false guard falls through to the next arm
+			if arm.Guard != nil {
+				cond, err := f.condition(arm.Guard)
+				if err != nil {
+					return err
+				}
+				f.printf("if %s {\n", cond)
+			}
+			f.printf("%s = true\n", matched)
+			if err := f.armBody(arm.Body, result); err != nil {
+				return err
+			}
+			if arm.Guard != nil {
+				f.printf("}\n")
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		f.printf("}\n")
+		f.leaveScope()
+	}
+
+	f.printf("if !%s {\nrt.Throw(rt.Str(%q))\n}\n", matched, "non-exhaustive match: no pattern matched")
+	return result, nil
+}
+
+// armBody compiles an arm body; its value is the last expression statement
+func (f *fn) armBody(body *ast.BlockStatement, result string) error {
+	if body == nil || len(body.Statements) == 0 {
+		return nil
+	}
+
+	last := len(body.Statements) - 1
+	for _, stmt := range body.Statements[:last] {
+		if err := f.statement(stmt); err != nil {
+			return err
+		}
+	}
+	if exprStmt, ok := body.Statements[last].(*ast.ExpressionStatement); ok {
+		code, err := f.expr(exprStmt.Expression)
+		if err != nil {
+			return err
+		}
+		f.printf("%s = %s\n", result, code)
+		return nil
+	}
+	return f.statement(body.Statements[last])
+}
+
+// pattern emits the tests of pattern against the value in subject as
+// nested ifs, binding its variables, and compiles then where it matched
+func (f *fn) pattern(pattern ast.Expression, subject string, then func() error) error {
+	switch p := pattern.(type) {
+	case *ast.Identifier:
+		switch {
+		case p.Value == "_":
+			return then()
+		case p.Value == "nil" || p.Value == "null":
+			return f.test(fmt.Sprintf("rt.PatternEqual(rt.Nil{}, %s, false)", subject), then)
+		}
+		if enum, ok := f.u.variants[p.Value]; ok {
+			// Bare variant name matches the variant regardless of payload
+			return f.test(fmt.Sprintf("rt.TestVariant(%s, %q, -1, %q)", subject, p.Value, enum), then)
+		}
+		f.printf("%s = %s\n", f.define(p.Value), subject)
+		return then()
+
+	case *ast.IntegerLiteral, *ast.StringLiteral, *ast.BooleanLiteral:
+		return f.literalPattern(p, subject, false, then)
+
+	case *ast.FloatLiteral, *ast.PrefixExpression:
+		// Float and negative literals compare numerically
+		return f.literalPattern(p, subject, true, then)
+
+	case *ast.CallExpression:
+		ident, ok := p.Function.(*ast.Identifier)
+		if !ok {
+			// Never matches
+			return nil
+		}
+
+		// Class pattern without fields: Point()
+		if f.u.classes[ident.Value] {
+			if len(p.Arguments) > 0 {
+				f.throw(fmt.Sprintf("class pattern %s needs field names: %s(field: pattern)", ident.Value, ident.Value))
+				return then()
+			}
+			return f.test(fmt.Sprintf("rt.TestInstance(%s, %s, %q)", subject, f.get(ident.Value), ident.Value), then)
+		}
+
+		// Enum variant pattern: VariantName(args...)
+		test := fmt.Sprintf("rt.TestVariant(%s, %q, %d, \"\")", subject, ident.Value, len(p.Arguments))
+		return f.test(test, func() error {
+			return f.subPatterns(p.Arguments, func(idx int) string {
+				return fmt.Sprintf("rt.Payload(%s, %d)", subject, idx)
+			}, then)
+		})
+
+	case *ast.ListLiteral:
+		return f.listPattern(p, subject, then)
+
+	case *ast.DictPattern:
+		// Listed keys must exist and match, other keys are ignored
+		return f.test(fmt.Sprintf("rt.TestDict(%s)", subject), func() error {
+			return f.dictPattern(p, 0, subject, then)
+		})
+
+	case *ast.ClassPattern:
+		// Class pattern with fields: Point(x: 0, y: y)
+		test := fmt.Sprintf("rt.TestInstance(%s, %s, %q)", subject, f.get(p.Class.Value), p.Class.Value)
+		return f.test(test, func() error {
+			return f.fieldPatterns(p.Fields, subject, then)
+		})
+
+	case *ast.OrPattern:
+		// First matching alternative wins; alternatives share bindings
+		matched := f.temp()
+		f.printf("%s := false\n", matched)
+		for idx, alt := range p.Alternatives {
+			if idx > 0 {
+				f.printf("if !%s {\n", matched)
+			}
+			err := f.pattern(alt, subject, func() error {
+				f.printf("%s = true\n", matched)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if idx > 0 {
+				f.printf("}\n")
+			}
+		}
+		return f.test(matched, then)
+
+	case *ast.AsPattern:
+		return f.pattern(p.Pattern, subject, func() error {
+			f.printf("%s = %s\n", f.define(p.Name.Value), subject)
+			return then()
+		})
+
+	case *ast.RestPattern:
+		f.throw("rest pattern is only allowed inside a list pattern")
+		return then()
+
+	default:
+		f.throw(fmt.Sprintf("unsupported pattern type: %T", pattern))
+		return then()
+	}
+}
+
+// test compiles then inside an if on cond
+func (f *fn) test(cond string, then func() error) error {
+	f.printf("if %s {\n", cond)
+	if err := then(); err != nil {
+		return err
+	}
+	f.printf("}\n")
+	return nil
+}
+
+func (f *fn) literalPattern(literal ast.Expression, subject string, numeric bool, then func() error) error {
+	code, err := f.expr(literal)
+	if err != nil {
+		return err
+	}
+	return f.test(fmt.Sprintf("rt.PatternEqual(%s, %s, %t)", code, subject, numeric), then)
+}
+
+// throw raises a runtime error with msg
+func (f *fn) throw(msg string) {
+	f.printf("rt.Throw(rt.Str(%q))\n", msg)
+}
+
+// listPattern matches list elements; a rest element collects the
+// remaining elements and patterns after it match from the end of the list
+func (f *fn) listPattern(p *ast.ListLiteral, subject string, then func() error) error {
+	restIdx := -1
+	for idx, elem := range p.Elements {
+		if _, ok := elem.(*ast.RestPattern); ok {
+			restIdx = idx
+		}
+	}
+
+	test := fmt.Sprintf("rt.TestList(%s, %d, false)", subject, len(p.Elements))
+	if restIdx >= 0 {
+		test = fmt.Sprintf("rt.TestList(%s, %d, true)", subject, len(p.Elements)-1)
+	}
+	return f.test(test, func() error {
+		var elems []ast.Expression
+		var indexes []int
+		for idx, elem := range p.Elements {
+			if idx == restIdx {
+				rest := elem.(*ast.RestPattern)
+				if rest.Name != nil && rest.Name.Value != "_" {
+					f.printf("%s = rt.Slice(%s, %d, %d)\n", f.define(rest.Name.Value), subject, restIdx, len(p.Elements)-restIdx-1)
+				}
+				continue
+			}
+			index := idx
+			if restIdx >= 0 && idx > restIdx {
+				index = idx - len(p.Elements)
+			}
+			elems = append(elems, elem)
+			indexes = append(indexes, index)
+		}
+		return f.subPatterns(elems, func(i int) string {
+			return "rt.Element(" + subject + ", " + strconv.Itoa(indexes[i]) + ")"
+		}, then)
+	})
+}
+
+func (f *fn) dictPattern(p *ast.DictPattern, idx int, subject string, then func() error) error {
+	if idx == len(p.Keys) {
+		return then()
+	}
+	key, err := f.expr(p.Keys[idx])
+	if err != nil {
+		return err
+	}
+	return f.test(fmt.Sprintf("rt.TestKey(%s, %s)", subject, key), func() error {
+		key, err := f.expr(p.Keys[idx])
+		if err != nil {
+			return err
+		}
+		return f.subPattern(p.Values[idx], fmt.Sprintf("rt.Index(%s, %s)", subject, key), func() error {
+			return f.dictPattern(p, idx+1, subject, then)
+		})
+	})
+}
+
+func (f *fn) fieldPatterns(fields []*ast.FieldPattern, subject string, then func() error) error {
+	if len(fields) == 0 {
+		return then()
+	}
+	field := fields[0]
+	return f.test(fmt.Sprintf("rt.TestField(%s, %q)", subject, field.Name.Value), func() error {
+		value := fmt.Sprintf("rt.GetMember(%s, %q)", subject, field.Name.Value)
+		return f.subPattern(field.Pattern, value, func() error {
+			return f.fieldPatterns(fields[1:], subject, then)
+		})
+	})
+}
+
+// subPatterns matches patterns in order against the values value(i)
+func (f *fn) subPatterns(patterns []ast.Expression, value func(i int) string, then func() error) error {
+	var match func(i int) error
+	match = func(i int) error {
+		if i == len(patterns) {
+			return then()
+		}
+		return f.subPattern(patterns[i], value(i), func() error { return match(i + 1) })
+	}
+	return match(0)
+}
+
+// subPattern matches a value, storing it in a temporary unless the
+// pattern ignores it
+func (f *fn) subPattern(pattern ast.Expression, value string, then func() error) error {
+	if ident, ok := pattern.(*ast.Identifier); ok && ident.Value == "_" {
+		return then()
+	}
+	t := f.spill(value)
+	f.printf("_ = %s\n", t)
+	return f.pattern(pattern, t, then)
+}
@@ -0,0 +1,1225 @@
+package skyrt
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	mrand "math/rand"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Built-in functions. Each one is an exported Go function named after
+// the SKY built-in (str_upper is BuiltinStrUpper), which the generated
+// code calls directly when the program does not redefine the name, and
+// an entry in Builtins for uses as a value and for method calls on
+// strings, lists and dicts.
+
+// Builtins maps the names of the supported built-ins to their functions
+var Builtins = map[string]*Function{}
+
+func init() {
+	for name, fn := range map[string]func(...Value) Value{
+		"print":              BuiltinPrint,
+		"len":                BuiltinLen,
+		"range":              BuiltinRange,
+		"int":                BuiltinInt,
+		"float":              BuiltinFloat,
+		"bool":               BuiltinBool,
+		"str":                BuiltinStr,
+		"list":               BuiltinList,
+		"dict":               BuiltinDict,
+		"abs":                BuiltinAbs,
+		"min":                BuiltinMin,
+		"max":                BuiltinMax,
+		"round":              BuiltinRound,
+		"pow":                BuiltinPow,
+		"sqrt":               BuiltinSqrt,
+		"floor":              BuiltinFloor,
+		"ceil":               BuiltinCeil,
+		"sum":                BuiltinSum,
+		"input":              BuiltinInput,
+		"type":               BuiltinType,
+		"isinstance":         BuiltinIsinstance,
+		"map":                BuiltinMap,
+		"filter":             BuiltinFilter,
+		"any":                BuiltinAny,
+		"all":                BuiltinAll,
+		"str_upper":          BuiltinStrUpper,
+		"str_lower":          BuiltinStrLower,
+		"str_capitalize":     BuiltinStrCapitalize,
+		"str_split":          BuiltinStrSplit,
+		"str_join":           BuiltinStrJoin,
+		"str_replace":        BuiltinStrReplace,
+		"str_strip":          BuiltinStrStrip,
+		"str_startswith":     BuiltinStrStartswith,
+		"str_endswith":       BuiltinStrEndswith,
+		"str_find":           BuiltinStrFind,
+		"str_count":          BuiltinStrCount,
+		"join":               BuiltinJoin,
+		"list_append":        BuiltinListAppend,
+		"list_pop":           BuiltinListPop,
+		"list_insert":        BuiltinListInsert,
+		"list_remove":        BuiltinListRemove,
+		"list_clear":         BuiltinListClear,
+		"list_index":         BuiltinListIndex,
+		"list_count":         BuiltinListCount,
+		"list_reverse":       BuiltinListReverse,
+		"list_copy":          BuiltinListCopy,
+		"list_extend":        BuiltinListExtend,
+		"dict_keys":          BuiltinDictKeys,
+		"dict_values":        BuiltinDictValues,
+		"dict_get":           BuiltinDictGet,
+		"dict_pop":           BuiltinDictPop,
+		"dict_clear":         BuiltinDictClear,
+		"dict_update":        BuiltinDictUpdate,
+		"time_now":           BuiltinTimeNow,
+		"time_sleep":         BuiltinTimeSleep,
+		"time_format":        BuiltinTimeFormat,
+		"time_parse":         BuiltinTimeParse,
+		"time_add":           BuiltinTimeAdd,
+		"time_diff":          BuiltinTimeDiff,
+		"fs_read_text":       BuiltinFsReadText,
+		"fs_write_text":      BuiltinFsWriteText,
+		"fs_exists":          BuiltinFsExists,
+		"fs_mkdir":           BuiltinFsMkdir,
+		"fs_list_dir":        BuiltinFsListDir,
+		"os_platform":        BuiltinOsPlatform,
+		"os_getcwd":          BuiltinOsGetcwd,
+		"os_getenv":          BuiltinOsGetenv,
+		"os_setenv":          BuiltinOsSetenv,
+		"json_encode":        BuiltinJsonEncode,
+		"json_decode":        BuiltinJsonDecode,
+		"crypto_md5":         BuiltinCryptoMd5,
+		"crypto_sha256":      BuiltinCryptoSha256,
+		"rand_int":           BuiltinRandInt,
+		"rand_uuid":          BuiltinRandUuid,
+		"Promise_all":        BuiltinPromiseAll,
+		"Promise_allSettled": BuiltinPromiseAllSettled,
+	} {
+		fn := fn
+		Builtins[name] = &Function{Name: name, Fn: func(_ Value, args []Value) Value { return fn(args...) }}
+	}
+}
+
+// Output is buffered; it is flushed when the program exits or reads input
+var (
+	stdout = bufio.NewWriter(os.Stdout)
+	stdin  = bufio.NewReader(os.Stdin)
+)
+
+func BuiltinPrint(args ...Value) Value {
+	for i, arg := range args {
+		if i > 0 {
+			stdout.WriteByte(' ')
+		}
+		stdout.WriteString(arg.String())
+	}
+	stdout.WriteByte('\n')
+	return Nil{}
+}
+
+func BuiltinLen(args ...Value) Value {
+	if len(args) > 0 {
+		switch v := args[0].(type) {
+		case Str:
+			return Int(len(v))
+		case *List:
+			return Int(len(v.Elements))
+		case *Dict:
+			return Int(len(v.Pairs))
+		}
+	}
+	return Int(0)
+}
+
+func BuiltinRange(args ...Value) Value {
+	if len(args) > 0 {
+		if n, ok := args[0].(Int); ok && n > 0 {
+			elements := make([]Value, n)
+			for i := range elements {
+				elements[i] = Int(i)
+			}
+			return &List{Elements: elements}
+		}
+	}
+	return &List{Elements: []Value{}}
+}
+
+// Type conversions
+
+func BuiltinInt(args ...Value) Value {
+	if len(args) == 0 {
+		return Int(0)
+	}
+	switch v := args[0].(type) {
+	case Int:
+		return v
+	case Float:
+		return Int(int64(v))
+	case Str:
+		base := 10
+		if len(args) >= 2 {
+			if b, ok := args[1].(Int); ok {
+				base = int(b)
+			}
+		}
+		n, err := strconv.ParseInt(string(v), base, 64)
+		if err != nil {
+			panic(Errorf("invalid literal for int(): %s", string(v)))
+		}
+		return Int(n)
+	case Bool:
+		if v {
+			return Int(1)
+		}
+	}
+	return Int(0)
+}
+
+func BuiltinFloat(args ...Value) Value {
+	if len(args) == 0 {
+		return Float(0)
+	}
+	switch v := args[0].(type) {
+	case Float:
+		return v
+	case Int:
+		return Float(v)
+	case Str:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			panic(Errorf("invalid literal for float(): %s", string(v)))
+		}
+		return Float(f)
+	case Bool:
+		if v {
+			return Float(1)
+		}
+	}
+	return Float(0)
+}
+
+func BuiltinBool(args ...Value) Value {
+	if len(args) == 0 {
+		return Bool(false)
+	}
+	return Bool(args[0].Truthy())
+}
+
+func BuiltinStr(args ...Value) Value {
+	if len(args) == 0 {
+		return Str("")
+	}
+	return Str(args[0].String())
+}
+
+func BuiltinList(args ...Value) Value {
+	if len(args) == 0 {
+		return &List{Elements: []Value{}}
+	}
+	switch v := args[0].(type) {
+	case *List:
+		return &List{Elements: append([]Value{}, v.Elements...)}
+	case Str:
+		chars := []Value{}
+		for _, ch := range string(v) {
+			chars = append(chars, Str(string(ch)))
+		}
+		return &List{Elements: chars}
+	case *Dict:
+		keys := []Value{}
+		for _, key := range v.keys() {
+			keys = append(keys, Str(key))
+		}
+		return &List{Elements: keys}
+	}
+	return &List{Elements: []Value{args[0]}}
+}
+
+func BuiltinDict(args ...Value) Value {
+	pairs := make(map[string]Value)
+	if len(args) > 0 {
+		switch v := args[0].(type) {
+		case *Dict:
+			for key, val := range v.Pairs {
+				pairs[key] = val
+			}
+		case *List:
+			for _, elem := range v.Elements {
+				if pair, ok := elem.(*List); ok && len(pair.Elements) >= 2 {
+					pairs[pair.Elements[0].String()] = pair.Elements[1]
+				}
+			}
+		}
+	}
+	return &Dict{Pairs: pairs}
+}
+
+// Numeric functions
+
+func BuiltinAbs(args ...Value) Value {
+	if len(args) == 0 {
+		panic(Errorf("abs() requires argument"))
+	}
+	switch v := args[0].(type) {
+	case Int:
+		if v < 0 {
+			return -v
+		}
+		return v
+	case Float:
+		return Float(math.Abs(float64(v)))
+	}
+	panic(Errorf("abs() requires numeric argument"))
+}
+
+func BuiltinMin(args ...Value) Value {
+	if len(args) == 0 {
+		panic(Errorf("min() requires at least one argument"))
+	}
+	return extreme(args, func(a, b float64) bool { return a < b })
+}
+
+func BuiltinMax(args ...Value) Value {
+	if len(args) == 0 {
+		panic(Errorf("max() requires at least one argument"))
+	}
+	return extreme(args, func(a, b float64) bool { return a > b })
+}
+
+// extreme returns the argument for which better holds against the
+// others; only arguments of the first argument's type are compared
+func extreme(args []Value, better func(a, b float64) bool) Value {
+	best := args[0]
+	for _, arg := range args[1:] {
+		switch b := best.(type) {
+		case Int:
+			if v, ok := arg.(Int); ok && better(float64(v), float64(b)) {
+				best = v
+			}
+		case Float:
+			if v, ok := arg.(Float); ok && better(float64(v), float64(b)) {
+				best = v
+			}
+		}
+	}
+	return best
+}
+
+func BuiltinRound(args ...Value) Value {
+	if len(args) > 0 {
+		switch v := args[0].(type) {
+		case Float:
+			digits := 0
+			if len(args) >= 2 {
+				if d, ok := args[1].(Int); ok {
+					digits = int(d)
+				}
+			}
+			multiplier := math.Pow(10, float64(digits))
+			return Float(math.Round(float64(v)*multiplier) / multiplier)
+		case Int:
+			return v
+		}
+	}
+	panic(Errorf("round() requires numeric argument"))
+}
+
+// number returns the value of an int or float argument
+func number(v Value) (float64, bool) {
+	switch n := v.(type) {
+	case Int:
+		return float64(n), true
+	case Float:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func BuiltinPow(args ...Value) Value {
+	if len(args) < 2 {
+		panic(Errorf("pow() requires two arguments"))
+	}
+	x, okX := number(args[0])
+	y, okY := number(args[1])
+	if !okX || !okY {
+		panic(Errorf("pow() requires numeric arguments"))
+	}
+	result := math.Pow(x, y)
+
+	// Integer arguments give an integer when the result is whole
+	_, intX := args[0].(Int)
+	_, intY := args[1].(Int)
+	if intX && intY && result == math.Floor(result) {
+		return Int(int64(result))
+	}
+	return Float(result)
+}
+
+func BuiltinSqrt(args ...Value) Value {
+	if len(args) == 0 {
+		panic(Errorf("sqrt() requires argument"))
+	}
+	x, ok := number(args[0])
+	if !ok {
+		panic(Errorf("sqrt() requires numeric argument"))
+	}
+	if x < 0 {
+		panic(Errorf("sqrt() of negative number"))
+	}
+	return Float(math.Sqrt(x))
+}
+
+func BuiltinFloor(args ...Value) Value {
+	if len(args) > 0 {
+		switch v := args[0].(type) {
+		case Float:
+			return Int(int64(math.Floor(float64(v))))
+		case Int:
+			return v
+		}
+	}
+	panic(Errorf("floor() requires numeric argument"))
+}
+
+func BuiltinCeil(args ...Value) Value {
+	if len(args) > 0 {
+		switch v := args[0].(type) {
+		case Float:
+			return Int(int64(math.Ceil(float64(v))))
+		case Int:
+			return v
+		}
+	}
+	panic(Errorf("ceil() requires numeric argument"))
+}
+
+func BuiltinSum(args ...Value) Value {
+	if len(args) == 0 {
+		return Int(0)
+	}
+	items, ok := args[0].(*List)
+	if !ok {
+		return Int(0)
+	}
+	var intSum int64
+	var floatSum float64
+	hasFloat := false
+	for _, item := range items.Elements {
+		switch v := item.(type) {
+		case Int:
+			if hasFloat {
+				floatSum += float64(v)
+			} else {
+				intSum += int64(v)
+			}
+		case Float:
+			if !hasFloat {
+				floatSum = float64(intSum)
+				hasFloat = true
+			}
+			floatSum += float64(v)
+		}
+	}
+	if hasFloat {
+		return Float(floatSum)
+	}
+	return Int(intSum)
+}
+
+// Utility functions
+
+func BuiltinInput(args ...Value) Value {
+	if len(args) > 0 {
+		if prompt, ok := args[0].(Str); ok {
+			stdout.WriteString(string(prompt))
+		}
+	}
+	stdout.Flush()
+	line, err := stdin.ReadString('\n')
+	if err != nil {
+		return Str("")
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return Str(strings.TrimSuffix(line, "\r"))
+}
+
+// kindName is the name type() gives to the type of v, "" for others
+func kindName(v Value) string {
+	switch v := v.(type) {
+	case Int:
+		return "int"
+	case Float:
+		return "float"
+	case Str:
+		return "string"
+	case Bool:
+		return "bool"
+	case *List:
+		return "list"
+	case *Dict:
+		return "dict"
+	case *Function:
+		return "function"
+	case *Class:
+		if !v.Abstract {
+			return "class"
+		}
+	case *Instance:
+		return "instance"
+	}
+	return ""
+}
+
+func BuiltinType(args ...Value) Value {
+	if len(args) == 0 {
+		return Str("nil")
+	}
+	switch args[0].(type) {
+	case *Interface:
+		return Str("interface")
+	case *Promise:
+		return Str("promise")
+	case Nil:
+		return Str("nil")
+	}
+	if name := kindName(args[0]); name != "" {
+		return Str(name)
+	}
+	return Str("unknown")
+}
+
+func BuiltinIsinstance(args ...Value) Value {
+	if len(args) < 2 {
+		return Bool(false)
+	}
+	inst, isInst := args[0].(*Instance)
+	switch target := args[1].(type) {
+	case *Class:
+		return Bool(isInst && inst.Class.isSubclassOf(target))
+	case *Interface:
+		return Bool(isInst && len(target.missingMethods(inst.Class)) == 0)
+	case Str:
+		return Bool(kindName(args[0]) == string(target))
+	}
+	return Bool(false)
+}
+
+// Functional programming
+
+func BuiltinMap(args ...Value) Value {
+	if len(args) >= 2 {
+		fn, okFn := args[0].(*Function)
+		items, okItems := args[1].(*List)
+		if okFn && okItems {
+			results := make([]Value, len(items.Elements))
+			for i, item := range items.Elements {
+				results[i] = fn.call(Nil{}, []Value{item})
+			}
+			return &List{Elements: results}
+		}
+	}
+	panic(Errorf("map() requires function and iterable"))
+}
+
+func BuiltinFilter(args ...Value) Value {
+	if len(args) >= 2 {
+		fn, okFn := args[0].(*Function)
+		items, okItems := args[1].(*List)
+		if okFn && okItems {
+			results := make([]Value, 0, len(items.Elements))
+			for _, item := range items.Elements {
+				if fn.call(Nil{}, []Value{item}).Truthy() {
+					results = append(results, item)
+				}
+			}
+			return &List{Elements: results}
+		}
+	}
+	panic(Errorf("filter() requires function and iterable"))
+}
+
+func BuiltinAny(args ...Value) Value {
+	if len(args) > 0 {
+		if items, ok := args[0].(*List); ok {
+			for _, item := range items.Elements {
+				if item.Truthy() {
+					return Bool(true)
+				}
+			}
+		}
+	}
+	return Bool(false)
+}
+
+func BuiltinAll(args ...Value) Value {
+	if len(args) > 0 {
+		if items, ok := args[0].(*List); ok {
+			for _, item := range items.Elements {
+				if !item.Truthy() {
+					return Bool(false)
+				}
+			}
+		}
+	}
+	return Bool(true)
+}
+
+// String methods; the string is the first argument
+
+// strArgs returns the first n arguments as strings
+func strArgs(args []Value, n int) ([]string, bool) {
+	if len(args) < n {
+		return nil, false
+	}
+	strs := make([]string, n)
+	for i := range strs {
+		s, ok := args[i].(Str)
+		if !ok {
+			return nil, false
+		}
+		strs[i] = string(s)
+	}
+	return strs, true
+}
+
+func BuiltinStrUpper(args ...Value) Value {
+	if s, ok := strArgs(args, 1); ok {
+		return Str(strings.ToUpper(s[0]))
+	}
+	panic(Errorf("upper() requires string argument"))
+}
+
+func BuiltinStrLower(args ...Value) Value {
+	if s, ok := strArgs(args, 1); ok {
+		return Str(strings.ToLower(s[0]))
+	}
+	panic(Errorf("lower() requires string argument"))
+}
+
+func BuiltinStrCapitalize(args ...Value) Value {
+	if s, ok := strArgs(args, 1); ok {
+		if s[0] == "" {
+			return Str("")
+		}
+		return Str(strings.ToUpper(s[0][:1]) + strings.ToLower(s[0][1:]))
+	}
+	panic(Errorf("capitalize() requires string argument"))
+}
+
+func BuiltinStrSplit(args ...Value) Value {
+	if s, ok := strArgs(args, 1); ok {
+		sep := " "
+		if len(args) >= 2 {
+			if v, ok := args[1].(Str); ok {
+				sep = string(v)
+			}
+		}
+		parts := strings.Split(s[0], sep)
+		elements := make([]Value, len(parts))
+		for i, part := range parts {
+			elements[i] = Str(part)
+		}
+		return &List{Elements: elements}
+	}
+	panic(Errorf("split() requires string argument"))
+}
+
+// joinList joins the elements of a list with the separator in args[0]
+func joinList(args []Value) (Value, bool) {
+	if len(args) < 2 {
+		return nil, false
+	}
+	sep, okSep := args[0].(Str)
+	items, okItems := args[1].(*List)
+	if !okSep || !okItems {
+		return nil, false
+	}
+	parts := make([]string, len(items.Elements))
+	for i, item := range items.Elements {
+		parts[i] = item.String()
+	}
+	return Str(strings.Join(parts, string(sep))), true
+}
+
+func BuiltinStrJoin(args ...Value) Value {
+	if s, ok := joinList(args); ok {
+		return s
+	}
+	panic(Errorf("join() requires string separator and list"))
+}
+
+func BuiltinJoin(args ...Value) Value {
+	if s, ok := joinList(args); ok {
+		return s
+	}
+	panic(Errorf("join() requires string separator and list"))
+}
+
+func BuiltinStrReplace(args ...Value) Value {
+	if s, ok := strArgs(args, 3); ok {
+		return Str(strings.ReplaceAll(s[0], s[1], s[2]))
+	}
+	panic(Errorf("replace() requires string, old, new"))
+}
+
+func BuiltinStrStrip(args ...Value) Value {
+	if s, ok := strArgs(args, 1); ok {
+		return Str(strings.TrimSpace(s[0]))
+	}
+	panic(Errorf("strip() requires string argument"))
+}
+
+func BuiltinStrStartswith(args ...Value) Value {
+	if s, ok := strArgs(args, 2); ok {
+		return Bool(strings.HasPrefix(s[0], s[1]))
+	}
+	panic(Errorf("startswith() requires string and prefix"))
+}
+
+func BuiltinStrEndswith(args ...Value) Value {
+	if s, ok := strArgs(args, 2); ok {
+		return Bool(strings.HasSuffix(s[0], s[1]))
+	}
+	panic(Errorf("endswith() requires string and suffix"))
+}
+
+func BuiltinStrFind(args ...Value) Value {
+	if s, ok := strArgs(args, 2); ok {
+		return Int(strings.Index(s[0], s[1]))
+	}
+	return Int(-1)
+}
+
+func BuiltinStrCount(args ...Value) Value {
+	if s, ok := strArgs(args, 2); ok {
+		return Int(strings.Count(s[0], s[1]))
+	}
+	return Int(0)
+}
+
+// List methods; the list is the first argument
+
+func listArg(args []Value, n int) (*List, bool) {
+	if len(args) < n {
+		return nil, false
+	}
+	list, ok := args[0].(*List)
+	return list, ok
+}
+
+func BuiltinListAppend(args ...Value) Value {
+	if list, ok := listArg(args, 2); ok {
+		list.Elements = append(list.Elements, args[1])
+		return Nil{}
+	}
+	panic(Errorf("append() requires list and item"))
+}
+
+func BuiltinListPop(args ...Value) Value {
+	list, ok := listArg(args, 1)
+	if !ok {
+		panic(Errorf("pop() requires list"))
+	}
+	if len(list.Elements) == 0 {
+		panic(Errorf("pop from empty list"))
+	}
+	idx := len(list.Elements) - 1
+	if len(args) >= 2 {
+		if i, ok := args[1].(Int); ok {
+			idx = int(i)
+		}
+	}
+	if idx < 0 || idx >= len(list.Elements) {
+		panic(Errorf("pop index out of range"))
+	}
+	item := list.Elements[idx]
+	list.Elements = append(list.Elements[:idx], list.Elements[idx+1:]...)
+	return item
+}
+
+func BuiltinListInsert(args ...Value) Value {
+	if list, ok := listArg(args, 3); ok {
+		if idx, ok := args[1].(Int); ok {
+			i := int(idx)
+			if i < 0 {
+				i = 0
+			}
+			if i > len(list.Elements) {
+				i = len(list.Elements)
+			}
+			list.Elements = append(list.Elements[:i], append([]Value{args[2]}, list.Elements[i:]...)...)
+			return Nil{}
+		}
+	}
+	panic(Errorf("insert() requires list, index, item"))
+}
+
+func BuiltinListRemove(args ...Value) Value {
+	list, ok := listArg(args, 2)
+	if !ok {
+		panic(Errorf("remove() requires list and item"))
+	}
+	for i, elem := range list.Elements {
+		if elem.String() == args[1].String() {
+			list.Elements = append(list.Elements[:i], list.Elements[i+1:]...)
+			return Nil{}
+		}
+	}
+	panic(Errorf("item not in list"))
+}
+
+func BuiltinListClear(args ...Value) Value {
+	if list, ok := listArg(args, 1); ok {
+		list.Elements = []Value{}
+		return Nil{}
+	}
+	panic(Errorf("clear() requires list"))
+}
+
+func BuiltinListIndex(args ...Value) Value {
+	if list, ok := listArg(args, 2); ok {
+		for i, elem := range list.Elements {
+			if elem.String() == args[1].String() {
+				return Int(i)
+			}
+		}
+	}
+	return Int(-1)
+}
+
+func BuiltinListCount(args ...Value) Value {
+	count := 0
+	if list, ok := listArg(args, 2); ok {
+		for _, elem := range list.Elements {
+			if elem.String() == args[1].String() {
+				count++
+			}
+		}
+	}
+	return Int(count)
+}
+
+func BuiltinListReverse(args ...Value) Value {
+	if list, ok := listArg(args, 1); ok {
+		n := len(list.Elements)
+		for i := 0; i < n/2; i++ {
+			list.Elements[i], list.Elements[n-1-i] = list.Elements[n-1-i], list.Elements[i]
+		}
+		return Nil{}
+	}
+	panic(Errorf("reverse() requires list"))
+}
+
+func BuiltinListCopy(args ...Value) Value {
+	if list, ok := listArg(args, 1); ok {
+		return &List{Elements: append([]Value{}, list.Elements...)}
+	}
+	panic(Errorf("copy() requires list"))
+}
+
+func BuiltinListExtend(args ...Value) Value {
+	if list, ok := listArg(args, 2); ok {
+		if other, ok := args[1].(*List); ok {
+			list.Elements = append(list.Elements, other.Elements...)
+			return Nil{}
+		}
+	}
+	panic(Errorf("extend() requires two lists"))
+}
+
+// Dict methods; the dict is the first argument
+
+func dictArg(args []Value, n int) (*Dict, bool) {
+	if len(args) < n {
+		return nil, false
+	}
+	dict, ok := args[0].(*Dict)
+	return dict, ok
+}
+
+func BuiltinDictKeys(args ...Value) Value {
+	if dict, ok := dictArg(args, 1); ok {
+		keys := make([]Value, 0, len(dict.Pairs))
+		for _, key := range dict.keys() {
+			keys = append(keys, Str(key))
+		}
+		return &List{Elements: keys}
+	}
+	panic(Errorf("keys() requires dict"))
+}
+
+func BuiltinDictValues(args ...Value) Value {
+	if dict, ok := dictArg(args, 1); ok {
+		values := make([]Value, 0, len(dict.Pairs))
+		for _, key := range dict.keys() {
+			values = append(values, dict.Pairs[key])
+		}
+		return &List{Elements: values}
+	}
+	panic(Errorf("values() requires dict"))
+}
+
+func BuiltinDictGet(args ...Value) Value {
+	if dict, ok := dictArg(args, 2); ok {
+		if key, ok := args[1].(Str); ok {
+			if val, ok := dict.Pairs[string(key)]; ok {
+				return val
+			}
+			if len(args) >= 3 {
+				return args[2]
+			}
+			return Nil{}
+		}
+	}
+	panic(Errorf("get() requires dict and key"))
+}
+
+func BuiltinDictPop(args ...Value) Value {
+	if dict, ok := dictArg(args, 2); ok {
+		if key, ok := args[1].(Str); ok {
+			if val, ok := dict.Pairs[string(key)]; ok {
+				delete(dict.Pairs, string(key))
+				return val
+			}
+			if len(args) >= 3 {
+				return args[2]
+			}
+			panic(Errorf("key not found"))
+		}
+	}
+	panic(Errorf("pop() requires dict and key"))
+}
+
+func BuiltinDictClear(args ...Value) Value {
+	if dict, ok := dictArg(args, 1); ok {
+		dict.Pairs = make(map[string]Value)
+		return Nil{}
+	}
+	panic(Errorf("clear() requires dict"))
+}
+
+func BuiltinDictUpdate(args ...Value) Value {
+	if dict, ok := dictArg(args, 2); ok {
+		if other, ok := args[1].(*Dict); ok {
+			for key, val := range other.Pairs {
+				dict.Pairs[key] = val
+			}
+			return Nil{}
+		}
+	}
+	panic(Errorf("update() requires two dicts"))
+}
+
+// Time functions; timestamps are milliseconds
+
+func BuiltinTimeNow(args ...Value) Value {
+	return Int(time.Now().UnixNano() / 1000000)
+}
+
+func BuiltinTimeSleep(args ...Value) Value {
+	if len(args) > 0 {
+		if ms, ok := args[0].(Int); ok {
+			time.Sleep(time.Duration(ms) * time.Millisecond)
+		}
+	}
+	return Nil{}
+}
+
+func BuiltinTimeFormat(args ...Value) Value {
+	if len(args) >= 2 {
+		ts, okTs := args[0].(Int)
+		layout, okLayout := args[1].(Str)
+		if okTs && okLayout {
+			return Str(time.Unix(0, int64(ts)*1000000).Format(string(layout)))
+		}
+	}
+	panic(Errorf("time_format() requires timestamp (int) and format string"))
+}
+
+func BuiltinTimeParse(args ...Value) Value {
+	if s, ok := strArgs(args, 2); ok {
+		t, err := time.Parse(s[1], s[0])
+		if err != nil {
+			panic(Errorf("time_parse error: %v", err))
+		}
+		return Int(t.UnixNano() / 1000000)
+	}
+	panic(Errorf("time_parse() requires time string and format string"))
+}
+
+func BuiltinTimeAdd(args ...Value) Value {
+	if len(args) >= 3 {
+		ts, okTs := args[0].(Int)
+		n, okN := args[1].(Int)
+		unit, okUnit := args[2].(Str)
+		if okTs && okN && okUnit {
+			units := map[Str]time.Duration{
+				"ms": time.Millisecond, "s": time.Second, "m": time.Minute, "h": time.Hour, "d": 24 * time.Hour,
+			}
+			d, ok := units[unit]
+			if !ok {
+				panic(Errorf("time_add() unit must be ms, s, m, h, or d"))
+			}
+			t := time.Unix(0, int64(ts)*1000000).Add(time.Duration(n) * d)
+			return Int(t.UnixNano() / 1000000)
+		}
+	}
+	panic(Errorf("time_add() requires timestamp, duration, and unit (ms/s/m/h/d)"))
+}
+
+func BuiltinTimeDiff(args ...Value) Value {
+	if len(args) >= 2 {
+		t1, ok1 := args[0].(Int)
+		t2, ok2 := args[1].(Int)
+		if ok1 && ok2 {
+			return Int(time.Unix(0, int64(t2)*1000000).Sub(time.Unix(0, int64(t1)*1000000)).Milliseconds())
+		}
+	}
+	panic(Errorf("time_diff() requires two timestamps"))
+}
+
+// File system and OS functions
+
+func BuiltinFsReadText(args ...Value) Value {
+	if s, ok := strArgs(args, 1); ok {
+		content, err := os.ReadFile(s[0])
+		if err != nil {
+			panic(Errorf("fs_read_text error: %v", err))
+		}
+		return Str(content)
+	}
+	panic(Errorf("fs_read_text() requires filename string"))
+}
+
+func BuiltinFsWriteText(args ...Value) Value {
+	if s, ok := strArgs(args, 2); ok {
+		if err := os.WriteFile(s[0], []byte(s[1]), 0644); err != nil {
+			panic(Errorf("fs_write_text error: %v", err))
+		}
+		return Bool(true)
+	}
+	panic(Errorf("fs_write_text() requires filename and content strings"))
+}
+
+func BuiltinFsExists(args ...Value) Value {
+	if s, ok := strArgs(args, 1); ok {
+		_, err := os.Stat(s[0])
+		return Bool(err == nil)
+	}
+	panic(Errorf("fs_exists() requires filename string"))
+}
+
+func BuiltinFsMkdir(args ...Value) Value {
+	if s, ok := strArgs(args, 1); ok {
+		if err := os.MkdirAll(s[0], 0755); err != nil {
+			panic(Errorf("fs_mkdir error: %v", err))
+		}
+		return Bool(true)
+	}
+	panic(Errorf("fs_mkdir() requires directory name string"))
+}
+
+func BuiltinFsListDir(args ...Value) Value {
+	if s, ok := strArgs(args, 1); ok {
+		entries, err := os.ReadDir(s[0])
+		if err != nil {
+			panic(Errorf("fs_list_dir error: %v", err))
+		}
+		files := make([]Value, len(entries))
+		for i, entry := range entries {
+			files[i] = Str(entry.Name())
+		}
+		return &List{Elements: files}
+	}
+	panic(Errorf("fs_list_dir() requires directory name string"))
+}
+
+func BuiltinOsPlatform(args ...Value) Value {
+	return Str(runtime.GOOS)
+}
+
+func BuiltinOsGetcwd(args ...Value) Value {
+	dir, err := os.Getwd()
+	if err != nil {
+		panic(Errorf("os_getcwd error: %v", err))
+	}
+	return Str(dir)
+}
+
+func BuiltinOsGetenv(args ...Value) Value {
+	if s, ok := strArgs(args, 1); ok {
+		return Str(os.Getenv(s[0]))
+	}
+	panic(Errorf("os_getenv() requires environment variable name string"))
+}
+
+func BuiltinOsSetenv(args ...Value) Value {
+	if s, ok := strArgs(args, 2); ok {
+		if err := os.Setenv(s[0], s[1]); err != nil {
+			panic(Errorf("os_setenv error: %v", err))
+		}
+		return Bool(true)
+	}
+	panic(Errorf("os_setenv() requires key and value strings"))
+}
+
+// JSON, random and hash functions
+
+// toGo converts a value to the Go types encoding/json handles
+func toGo(v Value) interface{} {
+	switch v := v.(type) {
+	case Int:
+		return int64(v)
+	case Float:
+		return float64(v)
+	case Str:
+		return string(v)
+	case Bool:
+		return bool(v)
+	case *List:
+		arr := make([]interface{}, len(v.Elements))
+		for i, elem := range v.Elements {
+			arr[i] = toGo(elem)
+		}
+		return arr
+	case *Dict:
+		m := make(map[string]interface{}, len(v.Pairs))
+		for key, val := range v.Pairs {
+			m[key] = toGo(val)
+		}
+		return m
+	}
+	return nil
+}
+
+// fromGo converts a decoded JSON value
+func fromGo(obj interface{}) Value {
+	switch v := obj.(type) {
+	case float64:
+		return Float(v)
+	case string:
+		return Str(v)
+	case bool:
+		return Bool(v)
+	case []interface{}:
+		elements := make([]Value, len(v))
+		for i, elem := range v {
+			elements[i] = fromGo(elem)
+		}
+		return &List{Elements: elements}
+	case map[string]interface{}:
+		pairs := make(map[string]Value, len(v))
+		for key, val := range v {
+			pairs[key] = fromGo(val)
+		}
+		return &Dict{Pairs: pairs}
+	}
+	return Nil{}
+}
+
+func BuiltinJsonEncode(args ...Value) Value {
+	if len(args) == 0 {
+		return Str("")
+	}
+	data, err := json.Marshal(toGo(args[0]))
+	if err != nil {
+		panic(Errorf("%v", err))
+	}
+	return Str(data)
+}
+
+func BuiltinJsonDecode(args ...Value) Value {
+	if s, ok := strArgs(args, 1); ok {
+		var obj interface{}
+		if err := json.Unmarshal([]byte(s[0]), &obj); err != nil {
+			panic(Errorf("%v", err))
+		}
+		return fromGo(obj)
+	}
+	return Nil{}
+}
+
+var rng = mrand.New(mrand.NewSource(time.Now().UnixNano()))
+
+func BuiltinRandInt(args ...Value) Value {
+	if len(args) > 0 {
+		if n, ok := args[0].(Int); ok && n > 0 {
+			return Int(rng.Intn(int(n)))
+		}
+	}
+	return Int(0)
+}
+
+func BuiltinRandUuid(args ...Value) Value {
+	uuid := make([]byte, 16)
+	rand.Read(uuid)
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+	return Str(hex.EncodeToString(uuid[:4]) + "-" + hex.EncodeToString(uuid[4:6]) + "-" +
+		hex.EncodeToString(uuid[6:8]) + "-" + hex.EncodeToString(uuid[8:10]) + "-" + hex.EncodeToString(uuid[10:]))
+}
+
+func BuiltinCryptoMd5(args ...Value) Value {
+	if s, ok := strArgs(args, 1); ok {
+		return Str(fmt.Sprintf("%x", md5.Sum([]byte(s[0]))))
+	}
+	panic(Errorf("crypto_md5() requires data string"))
+}
+
+func BuiltinCryptoSha256(args ...Value) Value {
+	if s, ok := strArgs(args, 1); ok {
+		return Str(fmt.Sprintf("%x", sha256.Sum256([]byte(s[0]))))
+	}
+	panic(Errorf("crypto_sha256() requires data string"))
+}
+
+// Promises; functions in the list are called and values are kept
+
+func BuiltinPromiseAll(args ...Value) Value {
+	if len(args) > 0 {
+		if items, ok := args[0].(*List); ok {
+			results := make([]Value, len(items.Elements))
+			for i, item := range items.Elements {
+				if fn, ok := item.(*Function); ok {
+					item = fn.call(Nil{}, nil)
+				}
+				results[i] = item
+			}
+			return &List{Elements: results}
+		}
+	}
+	panic(Errorf("Promise_all() requires a list of promises"))
+}
+
+func BuiltinPromiseAllSettled(args ...Value) Value {
+	if len(args) > 0 {
+		if items, ok := args[0].(*List); ok {
+			results := make([]Value, len(items.Elements))
+			for i, item := range items.Elements {
+				fn, isFn := item.(*Function)
+				if !isFn {
+					results[i] = &Dict{Pairs: map[string]Value{"status": Str("fulfilled"), "value": item}}
+					continue
+				}
+				_, val, err := protect(func() (Control, Value) { return Normal, fn.call(Nil{}, nil) })
+				if err != nil {
+					results[i] = &Dict{Pairs: map[string]Value{"status": Str("rejected"), "reason": Str(err.Message)}}
+				} else {
+					results[i] = &Dict{Pairs: map[string]Value{"status": Str("fulfilled"), "value": val}}
+				}
+			}
+			return &List{Elements: results}
+		}
+	}
+	panic(Errorf("Promise_allSettled() requires a list of promises"))
+}
@@ -0,0 +1,169 @@
+package skyrt
+
+import "fmt"
+
+// Error is a SKY runtime error. It is raised with panic and caught by
+// try blocks or reported when the program exits.
+type Error struct {
+	Message string
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Errorf returns a runtime error with a formatted message
+func Errorf(format string, args ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...)}
+}
+
+// Throw raises v as an error
+func Throw(v Value) {
+	panic(&Error{Message: v.String()})
+}
+
+// Control tells how a try block was left
+type Control int
+
+const (
+	Normal Control = iota
+	Return
+	Break
+	Continue
+)
+
+// Block is a try, catch or finally body. Statements leaving the block
+// through return, break or continue report it with their Control; the
+// generated code repeats them after Try returns.
+type Block func() (Control, Value)
+
+// Try runs body, then catch with the error message if body raised an
+// error, then finally. finally also runs when body or catch return,
+// break or continue, and leaving finally that way takes precedence.
+// catch and finally may be nil.
+func Try(body Block, catch func(msg Value) (Control, Value), finally Block) (Control, Value) {
+	ctl, ret, err := protect(body)
+	if err != nil && catch != nil {
+		ctl, ret, err = protect(func() (Control, Value) { return catch(Str(err.Message)) })
+	}
+	if finally != nil {
+		if fctl, fret := finally(); fctl != Normal {
+			return fctl, fret
+		}
+	}
+	if err != nil {
+		panic(err)
+	}
+	return ctl, ret
+}
+
+// protect runs block and returns the runtime error it raised, if any
+func protect(block Block) (ctl Control, ret Value, err *Error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			ctl, ret, err = Normal, nil, e
+		}
+	}()
+	ctl, ret = block()
+	return ctl, ret, nil
+}
+
+// Generators. A coop function returns a generator: a dict whose next()
+// runs the body until its next yield and returns the yielded value, then
+// nil once the body has finished. The body runs on its own goroutine, but
+// only while next() waits for it.
+
+// Gen is the running body of a generator
+type Gen struct {
+	resume chan struct{}
+	out    chan genResult
+}
+
+type genResult struct {
+	value Value
+	done  bool
+	err   interface{}
+}
+
+// Yield hands v to next() and waits until it is called again
+func (g *Gen) Yield(v Value) Value {
+	g.out <- genResult{value: v}
+	<-g.resume
+	return Nil{}
+}
+
+// Generator returns the generator of a coop call running body
+func Generator(body func(g *Gen) Value) Value {
+	g := &Gen{resume: make(chan struct{}), out: make(chan genResult)}
+	started, done, running := false, false, false
+
+	next := &Function{Name: "next", Fn: func(Value, []Value) Value {
+		switch {
+		case done:
+			return Nil{}
+		case running:
+			panic(Errorf("generator already running"))
+		}
+		running = true
+		if !started {
+			started = true
+			go func() {
+				defer func() {
+					g.out <- genResult{done: true, err: recover()}
+				}()
+				body(g)
+			}()
+		} else {
+			g.resume <- struct{}{}
+		}
+		result := <-g.out
+		running = false
+		if result.done {
+			done = true
+			if result.err != nil {
+				panic(result.err)
+			}
+			return Nil{}
+		}
+		return result.value
+	}}
+	return &Dict{Pairs: map[string]Value{"next": next}}
+}
+
+// Promise is the result of an async call
+type Promise struct {
+	value Value
+	err   *Error
+}
+
+func (p *Promise) String() string {
+	if p.err != nil {
+		return fmt.Sprintf("<Promise rejected: %v>", p.err)
+	}
+	return fmt.Sprintf("<Promise resolved: %v>", p.value)
+}
+func (p *Promise) Truthy() bool { return p.err == nil }
+
+// Async runs the body of an async function and returns its settled
+// promise. Nothing in a compiled program waits on I/O in the background,
+// so the body always runs to completion.
+func Async(body func() Value) Value {
+	p := &Promise{}
+	_, p.value, p.err = protect(func() (Control, Value) { return Normal, body() })
+	return p
+}
+
+// Await returns the value of a promise, raising its error if it was
+// rejected. Other values are returned as they are.
+func Await(v Value) Value {
+	p, ok := v.(*Promise)
+	if !ok {
+		return v
+	}
+	if p.err != nil {
+		panic(p.err)
+	}
+	return p.value
+}
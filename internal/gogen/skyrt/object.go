@@ -0,0 +1,411 @@
+package skyrt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Function is a SKY function, method, lambda or built-in. self is the
+// receiver of methods and nil for everything else.
+type Function struct {
+	Name   string
+	Arity  int // declared parameters, checked against interfaces
+	Async  bool
+	Method bool
+	Fn     func(self Value, args []Value) Value
+
+	variant *variantInfo // set for enum variant constructors
+}
+
+func (f *Function) String() string {
+	if f.Async {
+		return fmt.Sprintf("<async function %s>", f.Name)
+	}
+	return fmt.Sprintf("<function %s>", f.Name)
+}
+func (f *Function) Truthy() bool { return true }
+
+// Arg returns argument i, or nil when the caller omitted it
+func Arg(args []Value, i int) Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return Nil{}
+}
+
+// Rest collects the arguments from i on for a variadic parameter
+func Rest(args []Value, i int) Value {
+	if i >= len(args) {
+		return &List{Elements: []Value{}}
+	}
+	return &List{Elements: append([]Value(nil), args[i:]...)}
+}
+
+// maxDepth limits nested calls, like the VM's frame limit
+const maxDepth = 1 << 16
+
+var depth int
+
+// Call calls callee with args. name is the callee as written in the
+// source, used in the error for values that cannot be called.
+func Call(name string, callee Value, args ...Value) Value {
+	switch fn := callee.(type) {
+	case *Function:
+		return fn.call(Nil{}, args)
+	case *Class:
+		if !fn.Abstract {
+			return fn.instantiate(args)
+		}
+	}
+	if name == "" {
+		name = callee.String()
+	}
+	panic(Errorf("%s is not a function or class", name))
+}
+
+// call runs fn with a call depth check
+func (f *Function) call(self Value, args []Value) Value {
+	if depth >= maxDepth {
+		panic(Errorf("stack overflow: maximum call depth (%d) exceeded in function '%s'", maxDepth, f.Name))
+	}
+	depth++
+	defer func() { depth-- }()
+	return f.Fn(self, args)
+}
+
+// Class is a class or abstract class
+type Class struct {
+	Name       string
+	Abstract   bool
+	Supers     []*Class
+	Methods    map[string]*Function
+	Interfaces []*Interface
+}
+
+// NewClass creates a class inheriting from supers, which must be classes
+func NewClass(name string, abstract bool, supers ...Value) *Class {
+	class := &Class{Name: name, Abstract: abstract, Methods: make(map[string]*Function)}
+	for _, val := range supers {
+		super, ok := val.(*Class)
+		if !ok {
+			panic(Errorf("%s is not a class", val.String()))
+		}
+		class.Supers = append(class.Supers, super)
+	}
+	return class
+}
+
+func (c *Class) String() string {
+	if c.Abstract {
+		return fmt.Sprintf("AbstractClass(%s)", c.Name)
+	}
+	return fmt.Sprintf("<class %s>", c.Name)
+}
+func (c *Class) Truthy() bool { return true }
+
+// Implements checks that the class provides the methods of iface
+func (c *Class) Implements(name string, iface Value) {
+	it, ok := iface.(*Interface)
+	if !ok {
+		panic(Errorf("%s is not an interface", name))
+	}
+	if missing := it.missingMethods(c); len(missing) > 0 {
+		panic(Errorf("class %s does not implement %s: missing method %s", c.Name, it.Name, strings.Join(missing, ", ")))
+	}
+	c.Interfaces = append(c.Interfaces, it)
+}
+
+// findMethod looks up a method on the class and its superclasses
+func (c *Class) findMethod(name string) (*Function, bool) {
+	if method, ok := c.Methods[name]; ok {
+		return method, true
+	}
+	for _, super := range c.Supers {
+		if method, ok := super.findMethod(name); ok {
+			return method, true
+		}
+	}
+	return nil, false
+}
+
+// isSubclassOf reports whether c is target or inherits from it
+func (c *Class) isSubclassOf(target *Class) bool {
+	if c == target || c.Name == target.Name {
+		return true
+	}
+	for _, super := range c.Supers {
+		if super.isSubclassOf(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// instantiate creates an instance and runs the init methods of the
+// direct superclasses, then its own
+func (c *Class) instantiate(args []Value) Value {
+	inst := &Instance{Class: c, Fields: make(map[string]Value)}
+	for _, super := range c.Supers {
+		if init, ok := super.Methods["init"]; ok {
+			init.call(inst, args)
+		}
+	}
+	if init, ok := c.Methods["init"]; ok {
+		init.call(inst, args)
+	}
+	return inst
+}
+
+// Instance is an instance of a class
+type Instance struct {
+	Class  *Class
+	Fields map[string]Value
+}
+
+func (i *Instance) String() string { return fmt.Sprintf("<instance of %s>", i.Class.Name) }
+func (i *Instance) Truthy() bool   { return true }
+
+// Interface is a set of method names and arities
+type Interface struct {
+	Name    string
+	Extends []*Interface
+	Methods map[string]int
+}
+
+// NewInterface creates an interface from parent interfaces and
+// alternating method names and arities
+func NewInterface(name string, parents []Value, methods ...interface{}) *Interface {
+	it := &Interface{Name: name, Methods: make(map[string]int)}
+	for _, val := range parents {
+		parent, ok := val.(*Interface)
+		if !ok {
+			panic(Errorf("%s is not an interface", val.String()))
+		}
+		it.Extends = append(it.Extends, parent)
+	}
+	for i := 0; i+1 < len(methods); i += 2 {
+		it.Methods[methods[i].(string)] = methods[i+1].(int)
+	}
+	return it
+}
+
+func (it *Interface) String() string { return fmt.Sprintf("<interface %s>", it.Name) }
+func (it *Interface) Truthy() bool   { return true }
+
+// allMethods returns the required methods including parent interfaces
+func (it *Interface) allMethods() map[string]int {
+	methods := make(map[string]int)
+	for _, parent := range it.Extends {
+		for name, arity := range parent.allMethods() {
+			methods[name] = arity
+		}
+	}
+	for name, arity := range it.Methods {
+		methods[name] = arity
+	}
+	return methods
+}
+
+// missingMethods returns the sorted names of methods class does not provide
+func (it *Interface) missingMethods(class *Class) []string {
+	var missing []string
+	for name, arity := range it.allMethods() {
+		method, ok := class.findMethod(name)
+		if !ok || method.Arity != arity {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Invoke calls method name on recv. self is the receiver of the calling
+// method, passed to methods called through their class.
+func Invoke(self, recv Value, name string, args ...Value) Value {
+	switch r := recv.(type) {
+	case *Instance:
+		// Fields holding functions are called without self
+		if field, ok := r.Fields[name]; ok {
+			return Call(name, field, args...)
+		}
+		method, ok := r.Class.findMethod(name)
+		if !ok {
+			panic(Errorf("undefined method: %s", name))
+		}
+		return method.call(r, args)
+
+	case *Class:
+		method, ok := r.Methods[name]
+		if !ok {
+			panic(Errorf("undefined method: %s", name))
+		}
+		if self == nil {
+			self = Nil{}
+		}
+		return method.call(self, args)
+
+	case *Dict:
+		if val, ok := r.Pairs[name]; ok {
+			return Call(name, val, args...)
+		}
+		if builtin, ok := Builtins["dict_"+name]; ok {
+			return builtin.call(Nil{}, append([]Value{r}, args...))
+		}
+
+	case Str:
+		if builtin, ok := Builtins["str_"+name]; ok {
+			return builtin.call(Nil{}, append([]Value{r}, args...))
+		}
+
+	case *List:
+		if builtin, ok := Builtins["list_"+name]; ok {
+			return builtin.call(Nil{}, append([]Value{r}, args...))
+		}
+	}
+	return Call(name, GetMember(recv, name), args...)
+}
+
+// SuperInvoke calls name on the superclasses of class, the class the
+// calling method was defined in. class is nil outside methods.
+func SuperInvoke(class *Class, self Value, name string, args ...Value) Value {
+	if class == nil {
+		panic(Errorf("undefined method: %s", name))
+	}
+	for _, super := range class.Supers {
+		if method, ok := super.findMethod(name); ok {
+			return method.call(self, args)
+		}
+	}
+	panic(Errorf("undefined method: %s", name))
+}
+
+// Super returns the first superclass of class, or nil
+func Super(class *Class) Value {
+	if class != nil && len(class.Supers) > 0 {
+		return class.Supers[0]
+	}
+	return Nil{}
+}
+
+// GetMember evaluates obj.name
+func GetMember(obj Value, name string) Value {
+	switch o := obj.(type) {
+	case *Instance:
+		if val, ok := o.Fields[name]; ok {
+			return val
+		}
+		if method, ok := o.Class.findMethod(name); ok {
+			return bind(method, o)
+		}
+		panic(Errorf("undefined property: %s", name))
+
+	case *Class:
+		if method, ok := o.Methods[name]; ok {
+			return method
+		}
+		panic(Errorf("undefined method: %s", name))
+
+	case *Dict:
+		if val, ok := o.Pairs[name]; ok {
+			return val
+		}
+		if builtin, ok := Builtins["dict_"+name]; ok {
+			return bindBuiltin(builtin, name, o)
+		}
+		return Nil{}
+
+	case Str:
+		if builtin, ok := Builtins["str_"+name]; ok {
+			return bindBuiltin(builtin, name, o)
+		}
+
+	case *List:
+		if builtin, ok := Builtins["list_"+name]; ok {
+			return bindBuiltin(builtin, name, o)
+		}
+	}
+	panic(Errorf("cannot access member of %s", typeName(obj)))
+}
+
+// SetMember evaluates obj.name = val
+func SetMember(obj Value, name string, val Value) Value {
+	inst, ok := obj.(*Instance)
+	if !ok {
+		panic(Errorf("can only assign to instance members"))
+	}
+	inst.Fields[name] = val
+	return val
+}
+
+// bind returns method bound to recv
+func bind(method *Function, recv Value) *Function {
+	return &Function{
+		Name:  method.Name,
+		Arity: method.Arity,
+		Async: method.Async,
+		Fn: func(_ Value, args []Value) Value {
+			return method.call(recv, args)
+		},
+	}
+}
+
+// bindBuiltin returns a built-in with recv injected as first argument
+func bindBuiltin(fn *Function, name string, recv Value) *Function {
+	return &Function{
+		Name: name,
+		Fn: func(_ Value, args []Value) Value {
+			return fn.call(Nil{}, append([]Value{recv}, args...))
+		},
+	}
+}
+
+// EnumType is an enum declaration
+type EnumType struct {
+	Name     string
+	Variants map[string]*variantInfo
+}
+
+func (e *EnumType) String() string { return "enum " + e.Name }
+func (e *EnumType) Truthy() bool   { return true }
+
+// variantInfo describes an enum variant
+type variantInfo struct {
+	enum    string
+	name    string
+	payload int
+}
+
+// Variant returns the constructor function of an enum variant
+func Variant(enum, name string, payload int) *Function {
+	info := &variantInfo{enum: enum, name: name, payload: payload}
+	return &Function{
+		Name:    name,
+		variant: info,
+		Fn: func(_ Value, args []Value) Value {
+			if len(args) != payload {
+				panic(Errorf("%s expects %d arguments, got %d", name, payload, len(args)))
+			}
+			return &EnumInstance{TypeName: enum, Variant: name, Payload: append([]Value(nil), args...)}
+		},
+	}
+}
+
+// NewEnum creates an enum type from its variant constructors
+func NewEnum(name string, ctors ...*Function) *EnumType {
+	enum := &EnumType{Name: name, Variants: make(map[string]*variantInfo)}
+	for _, ctor := range ctors {
+		enum.Variants[ctor.variant.name] = ctor.variant
+	}
+	return enum
+}
+
+// EnumInstance is a value of an enum variant
+type EnumInstance struct {
+	TypeName string
+	Variant  string
+	Payload  []Value
+}
+
+func (e *EnumInstance) String() string { return e.TypeName + "::" + e.Variant }
+func (e *EnumInstance) Truthy() bool   { return true }
@@ -0,0 +1,349 @@
+package skyrt
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Operators. Integers and floats are handled in place; everything else
+// follows the interpreter's coercions and errors.
+
+// Add evaluates a + b
+func Add(a, b Value) Value {
+	switch x := a.(type) {
+	case Int:
+		if y, ok := b.(Int); ok {
+			return x + y
+		}
+	case Float:
+		if y, ok := b.(Float); ok {
+			return x + y
+		}
+	case Str:
+		return x + Str(concatString(b))
+	}
+	if y, ok := b.(Str); ok {
+		return Str(concatString(a)) + y
+	}
+	return binaryOp(a, b, "+")
+}
+
+// concatString converts the other operand of a string concatenation
+func concatString(v Value) string {
+	if f, ok := v.(Float); ok {
+		return fmt.Sprintf("%g", float64(f))
+	}
+	return v.String()
+}
+
+// Sub evaluates a - b
+func Sub(a, b Value) Value {
+	switch x := a.(type) {
+	case Int:
+		if y, ok := b.(Int); ok {
+			return x - y
+		}
+	case Float:
+		if y, ok := b.(Float); ok {
+			return x - y
+		}
+	}
+	return binaryOp(a, b, "-")
+}
+
+// Mul evaluates a * b
+func Mul(a, b Value) Value {
+	switch x := a.(type) {
+	case Int:
+		if y, ok := b.(Int); ok {
+			return x * y
+		}
+	case Float:
+		if y, ok := b.(Float); ok {
+			return x * y
+		}
+	}
+	return binaryOp(a, b, "*")
+}
+
+// Div evaluates a / b
+func Div(a, b Value) Value {
+	switch x := a.(type) {
+	case Int:
+		if y, ok := b.(Int); ok {
+			if y == 0 {
+				panic(Errorf("division by zero"))
+			}
+			return x / y
+		}
+	case Float:
+		if y, ok := b.(Float); ok {
+			return x / y
+		}
+	}
+	return binaryOp(a, b, "/")
+}
+
+// Mod evaluates a % b
+func Mod(a, b Value) Value {
+	if x, ok := a.(Int); ok {
+		if y, ok := b.(Int); ok {
+			if y == 0 {
+				panic(Errorf("division by zero"))
+			}
+			return x % y
+		}
+	}
+	return binaryOp(a, b, "%")
+}
+
+// Equal evaluates a == b
+func Equal(a, b Value) Value {
+	switch x := a.(type) {
+	case Int:
+		if y, ok := b.(Int); ok {
+			return Bool(x == y)
+		}
+	case Str:
+		if y, ok := b.(Str); ok {
+			return Bool(x == y)
+		}
+	}
+	return binaryOp(a, b, "==")
+}
+
+// NotEqual evaluates a != b
+func NotEqual(a, b Value) Value {
+	switch x := a.(type) {
+	case Int:
+		if y, ok := b.(Int); ok {
+			return Bool(x != y)
+		}
+	case Str:
+		if y, ok := b.(Str); ok {
+			return Bool(x != y)
+		}
+	}
+	return binaryOp(a, b, "!=")
+}
+
+// Less evaluates a < b
+func Less(a, b Value) Value {
+	if x, ok := a.(Int); ok {
+		if y, ok := b.(Int); ok {
+			return Bool(x < y)
+		}
+	}
+	return binaryOp(a, b, "<")
+}
+
+// LessEqual evaluates a <= b
+func LessEqual(a, b Value) Value {
+	if x, ok := a.(Int); ok {
+		if y, ok := b.(Int); ok {
+			return Bool(x <= y)
+		}
+	}
+	return binaryOp(a, b, "<=")
+}
+
+// Greater evaluates a > b
+func Greater(a, b Value) Value {
+	if x, ok := a.(Int); ok {
+		if y, ok := b.(Int); ok {
+			return Bool(x > y)
+		}
+	}
+	return binaryOp(a, b, ">")
+}
+
+// GreaterEqual evaluates a >= b
+func GreaterEqual(a, b Value) Value {
+	if x, ok := a.(Int); ok {
+		if y, ok := b.(Int); ok {
+			return Bool(x >= y)
+		}
+	}
+	return binaryOp(a, b, ">=")
+}
+
+// binaryOp handles the operand types without a fast path: comparisons
+// with nil, and unsupported combinations
+func binaryOp(a, b Value, op string) Value {
+	_, nilA := a.(Nil)
+	_, nilB := b.(Nil)
+	if nilA || nilB {
+		switch op {
+		case "==":
+			return Bool(nilA && nilB)
+		case "!=":
+			return Bool(!(nilA && nilB))
+		}
+	}
+	panic(Errorf("unsupported operation: %s %s %s", typeName(a), op, typeName(b)))
+}
+
+// Negate evaluates -v
+func Negate(v Value) Value {
+	switch x := v.(type) {
+	case Int:
+		return -x
+	case Float:
+		return -x
+	}
+	panic(Errorf("operator - can only be applied to numbers"))
+}
+
+// Not evaluates !v
+func Not(v Value) Value {
+	return Bool(!v.Truthy())
+}
+
+// Truthy reports whether v counts as true in a condition
+func Truthy(v Value) bool {
+	return v.Truthy()
+}
+
+// Set stores v in the variable p points to and returns it, for
+// assignments used as expressions
+func Set(p *Value, v Value) Value {
+	*p = v
+	return v
+}
+
+// Index evaluates obj[index]
+func Index(obj, index Value) Value {
+	switch v := obj.(type) {
+	case *List:
+		if i, ok := index.(Int); ok {
+			if i < 0 || int64(i) >= int64(len(v.Elements)) {
+				panic(Errorf("list index out of range"))
+			}
+			return v.Elements[i]
+		}
+	case *Dict:
+		if val, ok := v.Pairs[index.String()]; ok {
+			return val
+		}
+		return Nil{}
+	}
+	panic(Errorf("index operation not supported"))
+}
+
+// SetIndex evaluates obj[index] = val
+func SetIndex(obj, index, val Value) Value {
+	switch v := obj.(type) {
+	case *List:
+		if i, ok := index.(Int); ok {
+			if i < 0 || int64(i) >= int64(len(v.Elements)) {
+				panic(Errorf("list index out of range"))
+			}
+			v.Elements[i] = val
+			return val
+		}
+	case *Dict:
+		v.Pairs[index.String()] = val
+		return val
+	}
+	panic(Errorf("index assignment not supported"))
+}
+
+// Iter returns the iterator of a for-in loop. Lists are copied first,
+// dicts yield their keys in sorted order and strings their characters.
+// Instances iterate with __iter__ and __next__; an error from __next__
+// ends the loop.
+func Iter(iterable Value) func() (Value, bool) {
+	idx := 0
+	switch v := iterable.(type) {
+	case *List:
+		elements := append([]Value(nil), v.Elements...)
+		return func() (Value, bool) {
+			if idx >= len(elements) {
+				return nil, false
+			}
+			idx++
+			return elements[idx-1], true
+		}
+
+	case *Dict:
+		keys := make([]string, 0, len(v.Pairs))
+		for key := range v.Pairs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return func() (Value, bool) {
+			if idx >= len(keys) {
+				return nil, false
+			}
+			idx++
+			return Str(keys[idx-1]), true
+		}
+
+	case Str:
+		runes := []rune(string(v))
+		return func() (Value, bool) {
+			if idx >= len(runes) {
+				return nil, false
+			}
+			idx++
+			return Str(string(runes[idx-1])), true
+		}
+
+	case *Instance:
+		iterMethod, ok := v.Class.findMethod("__iter__")
+		if !ok {
+			break
+		}
+		inst, ok := iterMethod.Fn(v, nil).(*Instance)
+		return func() (val Value, more bool) {
+			if !ok {
+				return nil, false
+			}
+			nextMethod, found := inst.Class.findMethod("__next__")
+			if !found {
+				return nil, false
+			}
+			defer func() {
+				if r := recover(); r != nil {
+					if _, isErr := r.(*Error); !isErr {
+						panic(r)
+					}
+					val, more = nil, false
+				}
+			}()
+			return nextMethod.Fn(inst, nil), true
+		}
+	}
+	panic(Errorf("%s is not iterable", typeName(iterable)))
+}
+
+// PatternEqual compares a literal pattern with a value. Numeric patterns
+// match integers and floats of equal value.
+func PatternEqual(expected, value Value, numeric bool) bool {
+	switch e := expected.(type) {
+	case Int:
+		switch v := value.(type) {
+		case Int:
+			return e == v
+		case Float:
+			return numeric && Float(e) == v
+		}
+	case Float:
+		switch v := value.(type) {
+		case Float:
+			return e == v
+		case Int:
+			return numeric && e == Float(v)
+		}
+	case Str:
+		v, ok := value.(Str)
+		return ok && e == v
+	case Bool:
+		v, ok := value.(Bool)
+		return ok && e == v
+	case Nil:
+		_, ok := value.(Nil)
+		return ok
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package skyrt
+
+import (
+	"fmt"
+	"os"
+)
+
+// Main runs the script of a compiled program. A runtime error is
+// reported like the interpreter does and exits with status 1.
+func Main(script func() Value) {
+	defer func() {
+		r := recover()
+		stdout.Flush()
+		if r == nil {
+			return
+		}
+		err, ok := r.(*Error)
+		if !ok {
+			panic(r)
+		}
+		fmt.Fprintf(os.Stderr, "Runtime error: %s\n", err.Message)
+		os.Exit(1)
+	}()
+	script()
+}
+
+// Global returns the value of a global variable. Until the program
+// assigns it, a built-in of the same name is used instead.
+func Global(v Value, name string) Value {
+	if v != nil {
+		return v
+	}
+	if builtin, ok := Builtins[name]; ok {
+		return builtin
+	}
+	panic(Errorf("undefined: %s", name))
+}
+
+// Undefined raises the error for a name that is never defined
+func Undefined(name string) Value {
+	panic(Errorf("undefined: %s", name))
+}
+
+// Module is an imported module; its script runs on the first import
+type Module struct {
+	loaded bool
+}
+
+// Import runs script unless the module was already imported and returns
+// the namespace of its public globals, which exports lists
+func Import(m *Module, path string, script func() Value, exports func() map[string]Value) Value {
+	if !m.loaded {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					if err, ok := r.(*Error); ok {
+						panic(Errorf("error in module %s: %s", path, err.Message))
+					}
+					panic(r)
+				}
+			}()
+			script()
+		}()
+		m.loaded = true
+	}
+
+	namespace := &Dict{Pairs: make(map[string]Value)}
+	for name, val := range exports() {
+		// Globals the script never assigned do not exist yet
+		if val != nil && len(name) > 0 && name[0] != '_' {
+			namespace.Pairs[name] = val
+		}
+	}
+	return namespace
+}
+
+// Pattern tests of match expressions
+
+// TestVariant reports whether v is the variant name of enum. payload is
+// the expected payload length, or -1 to accept any; enum may be empty.
+func TestVariant(v Value, name string, payload int, enum string) bool {
+	e, ok := v.(*EnumInstance)
+	if !ok || e.Variant != name {
+		return false
+	}
+	if payload >= 0 && len(e.Payload) != payload {
+		return false
+	}
+	return enum == "" || e.TypeName == enum
+}
+
+// TestList reports whether v is a list of n elements, or at least n if rest
+func TestList(v Value, n int, rest bool) bool {
+	list, ok := v.(*List)
+	if !ok {
+		return false
+	}
+	if rest {
+		return len(list.Elements) >= n
+	}
+	return len(list.Elements) == n
+}
+
+// TestDict reports whether v is a dict
+func TestDict(v Value) bool {
+	_, ok := v.(*Dict)
+	return ok
+}
+
+// TestKey reports whether v is a dict holding key
+func TestKey(v, key Value) bool {
+	dict, ok := v.(*Dict)
+	if !ok {
+		return false
+	}
+	_, found := dict.Pairs[key.String()]
+	return found
+}
+
+// TestField reports whether v is an instance with the field name
+func TestField(v Value, name string) bool {
+	inst, ok := v.(*Instance)
+	if !ok {
+		return false
+	}
+	_, found := inst.Fields[name]
+	return found
+}
+
+// TestInstance reports whether v is an instance of class, written as
+// name in the pattern
+func TestInstance(v, class Value, name string) bool {
+	c, ok := class.(*Class)
+	if !ok {
+		panic(Errorf("%s is not a class", name))
+	}
+	inst, ok := v.(*Instance)
+	return ok && inst.Class.isSubclassOf(c)
+}
+
+// Payload returns payload value i of an enum value
+func Payload(v Value, i int) Value {
+	return v.(*EnumInstance).Payload[i]
+}
+
+// Element returns element i of a list; negative indexes count from the end
+func Element(v Value, i int) Value {
+	elements := v.(*List).Elements
+	if i < 0 {
+		i += len(elements)
+	}
+	return elements[i]
+}
+
+// Slice returns the elements of a list after the first from and before
+// the last tail
+func Slice(v Value, from, tail int) Value {
+	elements := v.(*List).Elements
+	rest := make([]Value, len(elements)-from-tail)
+	copy(rest, elements[from:])
+	return &List{Elements: rest}
+}
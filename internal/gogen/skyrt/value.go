@@ -0,0 +1,141 @@
+// Package skyrt is the runtime of SKY programs compiled to Go.
+//
+// The package only depends on the Go standard library: the Go backend
+// copies it next to the generated main package and builds both with the
+// local go toolchain. Values print, compare and fail like in the
+// interpreter, so a compiled program writes the same output.
+package skyrt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Value is a SKY value
+type Value interface {
+	String() string
+	Truthy() bool
+}
+
+// Int is an integer value
+type Int int64
+
+func (i Int) String() string { return fmt.Sprintf("%d", int64(i)) }
+func (i Int) Truthy() bool   { return i != 0 }
+
+// Float is a floating point value
+type Float float64
+
+func (f Float) String() string { return fmt.Sprintf("%f", float64(f)) }
+func (f Float) Truthy() bool   { return f != 0 }
+
+// Str is a string value
+type Str string
+
+func (s Str) String() string { return string(s) }
+func (s Str) Truthy() bool   { return s != "" }
+
+// Bool is a boolean value
+type Bool bool
+
+func (b Bool) String() string { return fmt.Sprintf("%t", bool(b)) }
+func (b Bool) Truthy() bool   { return bool(b) }
+
+// Nil is the nil value
+type Nil struct{}
+
+func (Nil) String() string { return "nil" }
+func (Nil) Truthy() bool   { return false }
+
+// List is a mutable list
+type List struct {
+	Elements []Value
+}
+
+// NewList returns a list holding elements
+func NewList(elements ...Value) *List {
+	return &List{Elements: elements}
+}
+
+func (l *List) String() string {
+	parts := make([]string, len(l.Elements))
+	for i, elem := range l.Elements {
+		parts[i] = elem.String()
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+func (l *List) Truthy() bool { return len(l.Elements) > 0 }
+
+// Dict is a mutable dictionary. Keys are the String() of the key value.
+type Dict struct {
+	Pairs map[string]Value
+}
+
+// NewDict returns a dict of alternating keys and values
+func NewDict(kv ...Value) *Dict {
+	pairs := make(map[string]Value, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		pairs[kv[i].String()] = kv[i+1]
+	}
+	return &Dict{Pairs: pairs}
+}
+
+// String prints the pairs in key order; the interpreter uses map order,
+// which any order matches
+func (d *Dict) String() string {
+	parts := make([]string, 0, len(d.Pairs))
+	for _, key := range d.keys() {
+		parts = append(parts, key+": "+d.Pairs[key].String())
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+func (d *Dict) Truthy() bool { return len(d.Pairs) > 0 }
+
+// keys returns the keys of d in sorted order
+func (d *Dict) keys() []string {
+	keys := make([]string, 0, len(d.Pairs))
+	for key := range d.Pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// typeName names the type of v the way interpreter error messages do
+func typeName(v Value) string {
+	switch v.(type) {
+	case Int:
+		return "*interpreter.Integer"
+	case Float:
+		return "*interpreter.Float"
+	case Str:
+		return "*interpreter.String"
+	case Bool:
+		return "*interpreter.Boolean"
+	case Nil:
+		return "*interpreter.Nil"
+	case *List:
+		return "*interpreter.List"
+	case *Dict:
+		return "*interpreter.Dict"
+	case *Function:
+		return "*interpreter.Function"
+	case *Class:
+		if v.(*Class).Abstract {
+			return "*interpreter.AbstractClass"
+		}
+		return "*interpreter.Class"
+	case *Instance:
+		return "*interpreter.Instance"
+	case *Interface:
+		return "*interpreter.Interface"
+	case *EnumType:
+		return "*interpreter.EnumType"
+	case *EnumInstance:
+		return "*interpreter.EnumInstance"
+	case *Promise:
+		return "*interpreter.Promise"
+	}
+	return fmt.Sprintf("%T", v)
+}
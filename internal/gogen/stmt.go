@@ -0,0 +1,525 @@
+package gogen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+)
+
+func (f *fn) statement(stmt ast.Statement) error {
+	if line := stmt.Pos().Line; line > 0 {
+		f.line = line
+	}
+
+	switch s := stmt.(type) {
+	case *ast.LetStatement:
+		return f.defineValue(s.Name.Value, s.Value)
+	case *ast.ConstStatement:
+		// Same as let (const checking done in sema phase)
+		return f.defineValue(s.Name.Value, s.Value)
+	case *ast.StaticPropertyStatement:
+		// Static properties are plain variables, like in the interpreter
+		return f.defineValue(s.Name.Value, s.Value)
+	case *ast.ReturnStatement:
+		return f.returnStatement(s)
+	case *ast.BreakStatement:
+		return f.jump("break")
+	case *ast.ContinueStatement:
+		return f.jump("continue")
+	case *ast.ExpressionStatement:
+		return f.expressionStatement(s.Expression)
+	case *ast.BlockStatement:
+		return f.block(s)
+	case *ast.IfStatement:
+		return f.ifStatement(s)
+	case *ast.WhileStatement:
+		return f.whileStatement(s)
+	case *ast.ForStatement:
+		return f.forStatement(s)
+	case *ast.FunctionStatement:
+		return f.functionStatement(s)
+	case *ast.StaticMethodStatement:
+		// Static methods are stored as plain functions, like in the interpreter
+		code, err := f.function(s.Name.Value, s.Parameters, s.Body, false, false, "")
+		if err != nil {
+			return err
+		}
+		f.defineVariable(s.Name.Value, code)
+		return nil
+	case *ast.ClassStatement:
+		return f.classStatement(s.Name, s.SuperClasses, s.Interfaces, s.Body, false)
+	case *ast.AbstractClassStatement:
+		return f.classStatement(s.Name, s.SuperClasses, nil, s.Body, true)
+	case *ast.AbstractMethodStatement:
+		// Abstract methods only declare a signature
+		return nil
+	case *ast.InterfaceStatement:
+		return f.interfaceStatement(s)
+	case *ast.EnumStatement:
+		return f.enumStatement(s)
+	case *ast.ImportStatement:
+		return f.importStatement(s)
+	case *ast.UnsafeStatement:
+		return f.block(s.Body)
+	case *ast.TryStatement:
+		return f.tryStatement(s)
+	case *ast.ThrowStatement:
+		code, err := f.expr(s.Value)
+		if err != nil {
+			return err
+		}
+		f.printf("rt.Throw(%s)\n", code)
+		return nil
+	default:
+		return f.errorf("unknown statement type: %T", stmt)
+	}
+}
+
+func (f *fn) block(block *ast.BlockStatement) error {
+	if block == nil {
+		return nil
+	}
+	for _, s := range block.Statements {
+		if err := f.statement(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// value compiles an optional expression, nil if it is absent
+func (f *fn) value(expr ast.Expression) (string, error) {
+	if expr == nil {
+		return "rt.Nil{}", nil
+	}
+	return f.expr(expr)
+}
+
+// defineValue defines name with the value of an optional expression
+func (f *fn) defineValue(name string, expr ast.Expression) error {
+	code, err := f.value(expr)
+	if err != nil {
+		return err
+	}
+	f.defineVariable(name, code)
+	return nil
+}
+
+func (f *fn) expressionStatement(expr ast.Expression) error {
+	// Plain assignments are written as Go assignments
+	if assign, ok := expr.(*ast.InfixExpression); ok && assign.Operator == "=" {
+		if ident, ok := assign.Left.(*ast.Identifier); ok {
+			code, err := f.expr(assign.Right)
+			if err != nil {
+				return err
+			}
+			f.printf("%s = %s\n", f.assignTarget(ident.Value), code)
+			return nil
+		}
+	}
+
+	code, err := f.expr(expr)
+	if err != nil {
+		return err
+	}
+	f.printf("_ = %s\n", code)
+	return nil
+}
+
+// exit returns the Go statement leaving the function with code. Inside
+// try blocks it reports the return to rt.Try instead.
+func (f *fn) exit(code string) string {
+	if f.tries > 0 {
+		return fmt.Sprintf("return rt.Return, %s", code)
+	}
+	return "return " + code
+}
+
+func (f *fn) returnStatement(stmt *ast.ReturnStatement) error {
+	code, err := f.value(stmt.ReturnValue)
+	if err != nil {
+		return err
+	}
+	f.printf("%s\n", f.exit(code))
+	return nil
+}
+
+// jump compiles break or continue. Leaving a try block to reach the loop
+// goes through rt.Try so that finally runs.
+func (f *fn) jump(keyword string) error {
+	if len(f.loops) == 0 {
+		return f.errorf("%s outside loop", keyword)
+	}
+	if f.loops[len(f.loops)-1].tries < f.tries {
+		ctl := "rt.Break"
+		if keyword == "continue" {
+			ctl = "rt.Continue"
+		}
+		f.printf("return %s, nil\n", ctl)
+		return nil
+	}
+	f.printf("%s\n", keyword)
+	return nil
+}
+
+// condition compiles a condition to a Go bool expression
+func (f *fn) condition(expr ast.Expression) (string, error) {
+	code, err := f.expr(expr)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("rt.Truthy(%s)", code), nil
+}
+
+func (f *fn) ifStatement(stmt *ast.IfStatement) error {
+	cond, err := f.condition(stmt.Condition)
+	if err != nil {
+		return err
+	}
+	f.printf("if %s {\n", cond)
+	if err := f.block(stmt.Consequence); err != nil {
+		return err
+	}
+
+	// An elif whose condition needs statements nests in the else branch
+	closing := 1
+	for _, elif := range stmt.Elif {
+		mark := f.out.Len()
+		cond, err := f.condition(elif.Condition)
+		if err != nil {
+			return err
+		}
+		if pre := f.cut(mark); len(pre) > 0 {
+			f.printf("} else {\n%s", pre)
+			f.printf("if %s {\n", cond)
+			closing++
+		} else {
+			f.printf("} else if %s {\n", cond)
+		}
+		if err := f.block(elif.Consequence); err != nil {
+			return err
+		}
+	}
+
+	if stmt.Alternative != nil {
+		f.printf("} else {\n")
+		if err := f.block(stmt.Alternative); err != nil {
+			return err
+		}
+	}
+	f.printf("%s\n", strings.Repeat("}\n", closing))
+	return nil
+}
+
+// cut removes and returns what was written since mark
+func (f *fn) cut(mark int) []byte {
+	pre := append([]byte(nil), f.out.Bytes()[mark:]...)
+	f.out.Truncate(mark)
+	return pre
+}
+
+func (f *fn) whileStatement(stmt *ast.WhileStatement) error {
+	mark := f.out.Len()
+	cond, err := f.condition(stmt.Condition)
+	if err != nil {
+		return err
+	}
+	if pre := f.cut(mark); len(pre) > 0 {
+		f.printf("for {\n%sif !%s {\nbreak\n}\n", pre, cond)
+	} else {
+		f.printf("for %s {\n", cond)
+	}
+
+	f.loops = append(f.loops, loop{tries: f.tries})
+	if err := f.block(stmt.Body); err != nil {
+		return err
+	}
+	f.loops = f.loops[:len(f.loops)-1]
+	f.printf("}\n")
+	return nil
+}
+
+func (f *fn) forStatement(stmt *ast.ForStatement) error {
+	iterable, err := f.expr(stmt.Iterable)
+	if err != nil {
+		return err
+	}
+
+	f.enterScope()
+	defer f.leaveScope()
+	v := f.define(stmt.Iterator.Value)
+
+	it, item := f.temp(), f.temp()
+	f.printf("for %s := rt.Iter(%s); ; {\n", it, iterable)
+	f.printf("%s, ok := %s()\nif !ok {\nbreak\n}\n%s = %s\n", item, it, v, item)
+
+	f.loops = append(f.loops, loop{tries: f.tries})
+	if err := f.block(stmt.Body); err != nil {
+		return err
+	}
+	f.loops = f.loops[:len(f.loops)-1]
+	f.printf("}\n")
+	return nil
+}
+
+// tryStatement compiles try/catch/finally to a call of rt.Try with the
+// blocks as closures. Return, break and continue inside them are reported
+// back and repeated once Try has run finally.
+func (f *fn) tryStatement(stmt *ast.TryStatement) error {
+	f.tries++
+	body, err := f.tryBlock(func() error { return f.block(stmt.TryBlock) })
+	if err != nil {
+		return err
+	}
+
+	catch := "nil"
+	if stmt.CatchClause != nil {
+		msg := f.temp()
+		code, err := f.tryBlock(func() error {
+			f.enterScope()
+			defer f.leaveScope()
+			if stmt.CatchClause.ErrorVar != nil {
+				f.printf("%s = %s\n", f.define(stmt.CatchClause.ErrorVar.Value), msg)
+			}
+			return f.block(stmt.CatchClause.Body)
+		})
+		if err != nil {
+			return err
+		}
+		catch = fmt.Sprintf("func(%s rt.Value) (rt.Control, rt.Value) {\n%s}", msg, code)
+	}
+
+	finally := "nil"
+	if stmt.Finally != nil {
+		code, err := f.tryBlock(func() error { return f.block(stmt.Finally) })
+		if err != nil {
+			return err
+		}
+		finally = "func() (rt.Control, rt.Value) {\n" + code + "}"
+	}
+	f.tries--
+
+	f.printf("if ctl, ret := rt.Try(func() (rt.Control, rt.Value) {\n%s}, %s, %s); ctl != rt.Normal {\n", body, catch, finally)
+	f.printf("if ctl == rt.Return {\n%s\n}\n", f.exit("ret"))
+	if len(f.loops) > 0 {
+		f.printf("if ctl == rt.Break {\n")
+		if err := f.jump("break"); err != nil {
+			return err
+		}
+		f.printf("}\nif ctl == rt.Continue {\n")
+		if err := f.jump("continue"); err != nil {
+			return err
+		}
+		f.printf("}\n")
+	}
+	f.printf("}\n")
+	return nil
+}
+
+// tryBlock compiles the body of a try, catch or finally closure
+func (f *fn) tryBlock(compile func() error) (string, error) {
+	mark := f.out.Len()
+	if err := compile(); err != nil {
+		return "", err
+	}
+	code := f.cut(mark)
+	return string(code) + "return rt.Normal, nil\n", nil
+}
+
+func (f *fn) functionStatement(stmt *ast.FunctionStatement) error {
+	funcName := stmt.Name.Value
+
+	// Local functions are defined first so they can call themselves
+	if !f.isGlobalScope() {
+		f.define(funcName)
+	}
+
+	// Decorators are read outermost first and applied innermost first
+	decorators := make([]string, len(stmt.Decorators))
+	for i, decorator := range stmt.Decorators {
+		if err := f.checkName(decorator.Name.Value); err != nil {
+			return err
+		}
+		decorators[i] = f.get(decorator.Name.Value)
+	}
+
+	code, err := f.function(funcName, stmt.Parameters, stmt.Body, stmt.Async, stmt.Coop, "")
+	if err != nil {
+		return err
+	}
+
+	for j := len(stmt.Decorators) - 1; j >= 0; j-- {
+		decorator := stmt.Decorators[j]
+		args, err := f.exprs(decorator.Args...)
+		if err != nil {
+			return err
+		}
+		code = fmt.Sprintf("rt.Call(%q, %s)", decorator.Name.Value,
+			strings.Join(append([]string{decorators[j], code}, args...), ", "))
+	}
+
+	f.defineVariable(funcName, code)
+	return nil
+}
+
+// function generates a function value. class is the Go variable of the
+// class for methods, whose receiver is self, and empty otherwise.
+func (f *fn) function(name string, params []*ast.FunctionParameter, body *ast.BlockStatement,
+	async, coop bool, class string) (string, error) {
+	inner := &fn{g: f.g, u: f.u, enclosing: f, scope: newScope(nil), depth: 1, line: f.line, class: "nil"}
+
+	var prologue bytes.Buffer
+	if class != "" {
+		inner.method = true
+		inner.class = class
+		fmt.Fprintf(&prologue, "%s = self\n", inner.define("self"))
+	}
+
+	// Parameters are locals; omitted arguments are nil
+	for idx, param := range params {
+		v := inner.define(param.Name.Value)
+		if param.Variadic {
+			fmt.Fprintf(&prologue, "%s = rt.Rest(args, %d)\n", v, idx)
+		} else {
+			fmt.Fprintf(&prologue, "%s = rt.Arg(args, %d)\n", v, idx)
+		}
+	}
+
+	if coop {
+		inner.gen = inner.temp()
+	}
+
+	// Default values are evaluated when the caller omitted the argument
+	for idx, param := range params {
+		if param.DefaultValue == nil || param.Variadic {
+			continue
+		}
+		inner.printf("if len(args) <= %d {\n", idx)
+		code, err := inner.expr(param.DefaultValue)
+		if err != nil {
+			return "", err
+		}
+		v, _ := inner.scope.lookup(param.Name.Value)
+		inner.printf("%s = %s\n}\n", v, code)
+	}
+
+	if err := inner.block(body); err != nil {
+		return "", err
+	}
+	inner.printf("return rt.Nil{}\n")
+
+	// Coop functions return a generator running the body, async
+	// functions the promise of its result
+	code := inner.out.String()
+	switch {
+	case coop:
+		code = fmt.Sprintf("return rt.Generator(func(%s *rt.Gen) rt.Value {\n%s})\n", inner.gen, code)
+	case async:
+		code = fmt.Sprintf("return rt.Async(func() rt.Value {\n%s})\n", code)
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "&rt.Function{Name: %q, Arity: %d", name, len(params))
+	if async {
+		buf.WriteString(", Async: true")
+	}
+	if class != "" {
+		buf.WriteString(", Method: true")
+	}
+	buf.WriteString(", Fn: func(self rt.Value, args []rt.Value) rt.Value {\n")
+	buf.WriteString(inner.declarations())
+	buf.Write(prologue.Bytes())
+	buf.WriteString(code)
+	buf.WriteString("}}")
+	return buf.String(), nil
+}
+
+// classStatement compiles class and abstract class definitions
+func (f *fn) classStatement(name *ast.Identifier, superClasses, interfaces []*ast.Identifier,
+	body []ast.Statement, abstract bool) error {
+	if !f.isGlobalScope() {
+		f.define(name.Value)
+	}
+
+	supers := make([]string, len(superClasses))
+	for i, super := range superClasses {
+		supers[i] = f.get(super.Value)
+	}
+	class := f.temp()
+	f.printf("%s := rt.NewClass(%q, %t%s)\n", class, name.Value, abstract, joinArgs(supers))
+
+	for _, member := range body {
+		switch m := member.(type) {
+		case *ast.FunctionStatement:
+			code, err := f.function(m.Name.Value, m.Parameters, m.Body, m.Async, m.Coop, class)
+			if err != nil {
+				return err
+			}
+			f.printf("%s.Methods[%q] = %s\n", class, m.Name.Value, code)
+		case *ast.AbstractMethodStatement:
+			if !abstract {
+				return f.errorf("unsupported class member: %T", member)
+			}
+		default:
+			return f.errorf("unsupported class member: %T", member)
+		}
+	}
+
+	for _, iface := range interfaces {
+		f.printf("%s.Implements(%q, %s)\n", class, iface.Value, f.get(iface.Value))
+	}
+
+	f.defineVariable(name.Value, class)
+	return nil
+}
+
+func (f *fn) interfaceStatement(stmt *ast.InterfaceStatement) error {
+	parents := make([]string, len(stmt.Extends))
+	for i, parent := range stmt.Extends {
+		parents[i] = f.get(parent.Value)
+	}
+	methods := make([]string, 0, 2*len(stmt.Methods))
+	for _, method := range stmt.Methods {
+		methods = append(methods, fmt.Sprintf("%q", method.Name.Value), fmt.Sprint(len(method.Parameters)))
+	}
+	f.defineVariable(stmt.Name.Value, fmt.Sprintf("rt.NewInterface(%q, []rt.Value{%s}%s)",
+		stmt.Name.Value, strings.Join(parents, ", "), joinArgs(methods)))
+	return nil
+}
+
+func (f *fn) enumStatement(stmt *ast.EnumStatement) error {
+	ctors := make([]string, len(stmt.Variants))
+	for i, variant := range stmt.Variants {
+		ctors[i] = f.temp()
+		f.printf("%s := rt.Variant(%q, %q, %d)\n", ctors[i], stmt.Name.Value, variant.Name.Value, len(variant.Payload))
+		f.defineVariable(variant.Name.Value, ctors[i])
+	}
+	f.defineVariable(stmt.Name.Value, fmt.Sprintf("rt.NewEnum(%q%s)", stmt.Name.Value, joinArgs(ctors)))
+	return nil
+}
+
+func (f *fn) importStatement(stmt *ast.ImportStatement) error {
+	path := strings.Join(stmt.Path, "/")
+	mod, err := f.g.module(path)
+	if err != nil {
+		return f.errorf("%v", err)
+	}
+
+	// import foo.bar binds "bar", import foo as f binds "f"
+	name := stmt.Path[len(stmt.Path)-1]
+	if stmt.Alias != nil {
+		name = stmt.Alias.Value
+	}
+	f.defineVariable(name, fmt.Sprintf("rt.Import(&mod%d, %q, %s, mod%dExports)", mod.id, path, mod.scriptName(), mod.id))
+	return nil
+}
+
+// joinArgs formats trailing call arguments
+func joinArgs(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return ", " + strings.Join(args, ", ")
+}
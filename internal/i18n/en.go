@@ -0,0 +1,306 @@
+package i18n
+
+// en holds the English messages
+var en = map[string]string{
+	// Parser
+	"parser.expected_token":        "expected next token to be %s, got %s instead at %s",
+	"parser.at":                    "%s at %s",
+	"parser.no_prefix":             "no prefix parse function for %s found",
+	"parser.invalid_integer":       "could not parse %q as integer",
+	"parser.invalid_float":         "could not parse %q as float",
+	"parser.unexpected_type":       "unexpected type: %s",
+	"parser.enum_end":              "expected 'end' to close enum",
+	"parser.match_arrow":           "expected => in match arm",
+	"parser.match_brace":           "expected } in match expression",
+	"parser.rest_pattern":          "rest pattern is only allowed inside a list pattern",
+	"parser.unexpected_in_pattern": "unexpected %s in pattern",
+	"parser.mixed_patterns":        "cannot mix positional and field patterns in %s(...)",
+	"parser.multiple_rest":         "list pattern can have at most one rest element",
+	"parser.dict_pattern_keys":     "dict pattern keys must be literals",
+	"parser.interface_member":      "interface %s may only contain method signatures, got %s",
+
+	// Checker
+	"sema.type_mismatch":              "type mismatch: cannot assign %s to %s",
+	"sema.const_value":                "const declaration must have a value",
+	"sema.return_outside":             "return statement outside of function",
+	"sema.return_mismatch":            "return type mismatch: expected %s, got %s",
+	"sema.if_condition":               "if condition must be bool, got %s",
+	"sema.elif_condition":             "elif condition must be bool, got %s",
+	"sema.while_condition":            "while condition must be bool, got %s",
+	"sema.guard_condition":            "match guard must be bool, got %s",
+	"sema.not_a_class":                "%s is not a class",
+	"sema.undefined_superclass":       "undefined superclass: %s",
+	"sema.undefined":                  "undefined: %s",
+	"sema.list_element_mismatch":      "list element type mismatch: expected %s, got %s",
+	"sema.dict_key_mismatch":          "dict key type mismatch: expected %s, got %s",
+	"sema.dict_value_mismatch":        "dict value type mismatch: expected %s, got %s",
+	"sema.operator_not":               "operator ! cannot be applied to %s",
+	"sema.operator":                   "operator %s cannot be applied to %s",
+	"sema.assign_const":               "cannot assign to const variable '%s'",
+	"sema.instantiate_interface":      "cannot instantiate interface %s",
+	"sema.arg_count_min":              "wrong number of arguments: expected at least %d, got %d",
+	"sema.arg_count":                  "wrong number of arguments: expected %d, got %d",
+	"sema.arg_count_range":            "wrong number of arguments: expected %d-%d, got %d",
+	"sema.list_index":                 "list index must be int, got %s",
+	"sema.no_exported_member":         "%s has no exported member %s",
+	"sema.await_outside":              "await can only be used in async functions",
+	"sema.yield_outside":              "yield can only be used in coop functions",
+	"sema.incompatible_method":        "method %s.%s is incompatible with %s.%s: expected %s, got %s",
+	"sema.class_no_member":            "class %s has no member %s",
+	"sema.no_field_or_method":         "%s has no field or method %s",
+	"sema.no_field":                   "%s has no field %s",
+	"sema.super_outside":              "super used outside of a class",
+	"sema.super_no_superclass":        "super used in class %s, which has no superclass",
+	"sema.no_method":                  "%s has no method %s",
+	"sema.duplicate_type_param":       "duplicate type parameter %s",
+	"sema.type_arg_count":             "wrong number of type arguments for %s: expected %d, got %d",
+	"sema.constraint":                 "type %s does not satisfy %s (type parameter %s of %s)",
+	"sema.undefined_interface":        "undefined interface: %s",
+	"sema.not_interface":              "%s is not an interface",
+	"sema.duplicate_interface_method": "duplicate method %s in interface %s",
+	"sema.not_implemented":            "class %s does not implement %s: %s",
+	"sema.arg_not_satisfy":            "argument %d type mismatch: %s does not satisfy %s (%s)",
+	"sema.arg_mismatch":               "argument %d type mismatch: expected %s, got %s",
+	"sema.non_exhaustive":             "non-exhaustive match on %s: missing %s",
+	"sema.duplicate_arm":              "duplicate match arm: %s",
+	"sema.unreachable_arm":            "unreachable match arm: %s is already covered by previous arms",
+	"sema.class_pattern_fields":       "class pattern %s needs field names: %s(field: pattern)",
+	"sema.pattern_not_class":          "invalid pattern: %s is not a class",
+	"sema.pattern_mismatch":           "pattern type mismatch: %s pattern cannot match %s",
+	"sema.variant_mismatch":           "pattern type mismatch: %s is a variant of %s, not %s",
+	"sema.or_pattern_bindings":        "or-pattern alternatives must bind the same variables: %s binds [%s], %s binds [%s]",
+	"sema.pattern_not_variant":        "invalid pattern: %s is not an enum variant",
+	"sema.payload_count":              "variant %s has %d payload value(s), pattern has %d",
+	"sema.cannot_load_module":         "cannot load module %s: %v",
+	"sema.module_parse_errors":        "parse errors in module %s: %s",
+	"sema.nil_subject":                "%s of type %s",
+	"sema.possibly_nil":               "possibly nil value: cannot %s on %s (check for nil first)",
+	"sema.missing_method":             "missing method %s",
+	"sema.method_signature":           "method %s has signature %s, want %s",
+	"sema.in_call":                    "%s in call to %s",
+	"sema.in_constructor":             "%s in %s constructor",
+	"sema.import_cycle":               "import cycle: %s",
+	"sema.already_defined":            "symbol '%s' already defined in this scope",
+	"sema.nil_operator":               "use operator %s",
+	"sema.nil_call":                   "call",
+	"sema.nil_index":                  "index",
+	"sema.nil_access":                 "access member %s",
+	"sema.nil_assign":                 "assign member %s",
+	"sema.unawaited_promise":          "result of async call %s (%s) is discarded; await it or keep the promise",
+	"sema.blocking_call":              "%s blocks the async function %s; use await %s(...) instead",
+	"sema.explicit_type_args":         "explicit type arguments are not supported: %s infers them from its arguments",
+
+	// Linter
+	"lint.unused_var":       "variable '%s' is defined but never used",
+	"lint.return_outside":   "return statement outside of function",
+	"lint.shadowing":        "variable '%s' shadows previous declaration",
+	"lint.unsafe_block":     "unsafe block bypasses safety checks",
+	"lint.division_by_zero": "division by zero",
+	"lint.parse_errors":     "parse errors: %v",
+	"lint.plugin_error":     "cannot load lint plugin %s: %v",
+	"lint.unknown_rule":     "unknown rule %q (see sky lint --rules)",
+	"lint.invalid_severity": "rule %s: invalid severity %v (use error, warning, info or off)",
+	"lint.unknown_option":   "rule %s has no option %s",
+	"lint.option_type":      "rule %s: option %s must have the type of its default %#v",
+
+	// Runtime
+	"runtime.unknown_statement":             "unknown statement type: %T",
+	"runtime.recursion_depth":               "maximum recursion depth exceeded (1000) in function '%s'",
+	"runtime.stack_overflow":                "stack overflow: maximum recursion depth (%d) exceeded",
+	"runtime.call_depth":                    "stack overflow: maximum call depth (%d) exceeded in function '%s'",
+	"runtime.undefined_decorator":           "undefined decorator: %s",
+	"runtime.not_decorator":                 "%s is not a decorator function",
+	"runtime.decorator_result":              "decorator %s did not return a function",
+	"runtime.not_iterable":                  "%T is not iterable",
+	"runtime.undefined_self":                "undefined: self",
+	"runtime.undefined_super":               "undefined: super",
+	"runtime.undefined":                     "undefined: %s",
+	"runtime.undefined_variable":            "undefined variable: %s",
+	"runtime.unknown_expression":            "unknown expression type: %T",
+	"runtime.negate":                        "operator - can only be applied to numbers",
+	"runtime.unknown_prefix":                "unknown prefix operator: %s",
+	"runtime.undefined_property":            "undefined property: %s",
+	"runtime.assign_member":                 "can only assign to instance members",
+	"runtime.assign_target":                 "left side of assignment must be an identifier",
+	"runtime.division_by_zero":              "division by zero",
+	"runtime.unsupported_operation":         "unsupported operation: %T %s %T",
+	"runtime.undefined_method":              "undefined method: %s",
+	"runtime.not_callable":                  "%s is not a function or class",
+	"runtime.index_out_of_range":            "list index out of range",
+	"runtime.index_unsupported":             "index operation not supported",
+	"runtime.index_assign_unsupported":      "index assignment not supported",
+	"runtime.undefined_superclass":          "undefined superclass: %s",
+	"runtime.not_a_class":                   "%s is not a class",
+	"runtime.unsupported_member":            "unsupported class member: %T",
+	"runtime.member_access":                 "cannot access member of %T",
+	"runtime.invalid_int":                   "invalid literal for int(): %s",
+	"runtime.invalid_float":                 "invalid literal for float(): %s",
+	"runtime.cannot_load_module":            "cannot load module %s: %v",
+	"runtime.module_parse_errors":           "parse errors in module %s: %v",
+	"runtime.module_error":                  "error in module %s: %v",
+	"runtime.undefined_interface":           "undefined interface: %s",
+	"runtime.not_interface":                 "%s is not an interface",
+	"runtime.not_implemented":               "class %s does not implement %s: missing method %s",
+	"runtime.variant_args":                  "%s expects %d arguments, got %d",
+	"runtime.no_match":                      "non-exhaustive match: no pattern matched",
+	"runtime.class_pattern_fields":          "class pattern %s needs field names: %s(field: pattern)",
+	"runtime.rest_pattern":                  "rest pattern is only allowed inside a list pattern",
+	"runtime.unsupported_pattern":           "unsupported pattern type: %T",
+	"runtime.yield_limit":                   "generator yield limit exceeded",
+	"runtime.generator_running":             "generator already running",
+	"runtime.never_settles":                 "await on a promise that never settles",
+	"runtime.abs_numeric":                   "abs() requires numeric argument",
+	"runtime.abs_argument":                  "abs() requires argument",
+	"runtime.min_argument":                  "min() requires at least one argument",
+	"runtime.max_argument":                  "max() requires at least one argument",
+	"runtime.round_numeric":                 "round() requires numeric argument",
+	"runtime.pow_numeric":                   "pow() requires numeric arguments",
+	"runtime.pow_arguments":                 "pow() requires two arguments",
+	"runtime.sqrt_numeric":                  "sqrt() requires numeric argument",
+	"runtime.sqrt_negative":                 "sqrt() of negative number",
+	"runtime.sqrt_argument":                 "sqrt() requires argument",
+	"runtime.floor_numeric":                 "floor() requires numeric argument",
+	"runtime.ceil_numeric":                  "ceil() requires numeric argument",
+	"runtime.map_arguments":                 "map() requires function and iterable",
+	"runtime.filter_arguments":              "filter() requires function and iterable",
+	"runtime.upper_string":                  "upper() requires string argument",
+	"runtime.lower_string":                  "lower() requires string argument",
+	"runtime.capitalize_string":             "capitalize() requires string argument",
+	"runtime.split_string":                  "split() requires string argument",
+	"runtime.join_arguments":                "join() requires string separator and list",
+	"runtime.replace_arguments":             "replace() requires string, old, new",
+	"runtime.strip_string":                  "strip() requires string argument",
+	"runtime.startswith_arguments":          "startswith() requires string and prefix",
+	"runtime.endswith_arguments":            "endswith() requires string and suffix",
+	"runtime.fs_read_text_arguments":        "fs_read_text() requires filename string",
+	"runtime.fs_write_text_arguments":       "fs_write_text() requires filename and content strings",
+	"runtime.fs_exists_arguments":           "fs_exists() requires filename string",
+	"runtime.fs_mkdir_arguments":            "fs_mkdir() requires directory name string",
+	"runtime.fs_list_dir_arguments":         "fs_list_dir() requires directory name string",
+	"runtime.os_getenv_arguments":           "os_getenv() requires environment variable name string",
+	"runtime.os_setenv_arguments":           "os_setenv() requires key and value strings",
+	"runtime.time_format_arguments":         "time_format() requires timestamp (int) and format string",
+	"runtime.time_parse_arguments":          "time_parse() requires time string and format string",
+	"runtime.time_add_unit":                 "time_add() unit must be ms, s, m, h, or d",
+	"runtime.time_add_arguments":            "time_add() requires timestamp, duration, and unit (ms/s/m/h/d)",
+	"runtime.time_diff_arguments":           "time_diff() requires two timestamps",
+	"runtime.promise_all_arguments":         "Promise_all() requires a list of promises",
+	"runtime.promise_all_settled_arguments": "Promise_allSettled() requires a list of promises",
+	"runtime.http_get_arguments":            "http_get() requires URL string",
+	"runtime.http_post_arguments":           "http_post() requires URL and data strings",
+	"runtime.http_put_arguments":            "http_put() requires URL and data strings",
+	"runtime.http_delete_arguments":         "http_delete() requires URL string",
+	"runtime.crypto_md5_arguments":          "crypto_md5() requires data string",
+	"runtime.crypto_sha256_arguments":       "crypto_sha256() requires data string",
+	"runtime.crypto_aes_encrypt_arguments":  "crypto_aes_encrypt() requires data and key strings",
+	"runtime.append_arguments":              "append() requires list and item",
+	"runtime.pop_empty":                     "pop from empty list",
+	"runtime.pop_range":                     "pop index out of range",
+	"runtime.pop_list":                      "pop() requires list",
+	"runtime.insert_arguments":              "insert() requires list, index, item",
+	"runtime.not_in_list":                   "item not in list",
+	"runtime.remove_arguments":              "remove() requires list and item",
+	"runtime.clear_list":                    "clear() requires list",
+	"runtime.reverse_list":                  "reverse() requires list",
+	"runtime.copy_list":                     "copy() requires list",
+	"runtime.extend_lists":                  "extend() requires two lists",
+	"runtime.keys_dict":                     "keys() requires dict",
+	"runtime.values_dict":                   "values() requires dict",
+	"runtime.get_arguments":                 "get() requires dict and key",
+	"runtime.key_not_found":                 "key not found",
+	"runtime.pop_dict":                      "pop() requires dict and key",
+	"runtime.clear_dict":                    "clear() requires dict",
+	"runtime.update_dicts":                  "update() requires two dicts",
+	"runtime.builtin_error":                 "%s error: %v",
+	"runtime.builtin_read_error":            "%s read error: %v",
+	"runtime.argument_type":                 "argument %s of %s: expected %s, got %s",
+	"runtime.called_at":                     " (called at %s)",
+	"runtime.return_type":                   "return value of %s: expected %s, got %s",
+	"runtime.let_type":                      "let %s: expected %s, got %s",
+	"runtime.break_outside":                 "break outside loop",
+	"runtime.continue_outside":              "continue outside loop",
+	"runtime.invalid_assignment":            "invalid assignment target",
+	"runtime.unknown_operator":              "unknown operator: %s",
+	"runtime.tier_coroutine":                "async and coop functions are not supported",
+	"runtime.tier_instruction":              "%s is not supported",
+	"runtime.tier_self":                     "self is not supported",
+	"runtime.tier_nested":                   "nested function %s is not supported",
+	"runtime.tier_captures":                 "lambdas capturing variables are not supported",
+
+	// Command line
+	"cli.unknown_command":      "Unknown command: %s",
+	"cli.no_input":             "Error: no input file specified",
+	"cli.no_inputs":            "Error: no input files specified",
+	"cli.error":                "Error: %v",
+	"cli.runtime_typecheck":    "Error: --runtime-typecheck runs .sky sources on the interpreter or with --vm",
+	"cli.read_file":            "Error reading file: %v",
+	"cli.parse_errors":         "Parse errors:",
+	"cli.semantic_errors":      "Semantic errors:",
+	"cli.runtime_error":        "Runtime error: %s",
+	"cli.requires_value":       "%s requires a value",
+	"cli.runtime_needs_bundle": "Error: --runtime needs --bundle",
+	"cli.bundle_flags":         "Error: --bundle runs the program on the sky runtime; it takes no --target or --lib",
+	"cli.unknown_target":       "Error: unknown build target %q (use go, c or llvm)",
+	"cli.lib_needs_c":          "Error: --lib needs --target=c",
+	"cli.native_profiles":      "Error: the native backends do not use profiles; use sky compile --pgo to build optimized bytecode",
+	"cli.building":             "Building %s...",
+	"cli.build_error":          "Build error: %v",
+	"cli.built":                "Successfully built: %s",
+	"cli.running_tests_in":     "Running tests in %s/",
+	"cli.find_test_files":      "Error finding test files: %v",
+	"cli.no_test_files_in":     "No test files found in %s/",
+	"cli.no_test_files":        "No test files found in %s",
+	"cli.testing":              "Testing %s... ",
+	"cli.fail":                 "❌ FAIL: %v",
+	"cli.output_mismatch":      "❌ FAIL: output mismatch",
+	"cli.expected":             "  Expected: %s",
+	"cli.got":                  "  Got: %s",
+	"cli.pass":                 "✅ PASS",
+	"cli.test_counts":          "%d passed, %d failed",
+	"cli.parse_error":          "parse error: %v",
+	"cli.semantic_error":       "semantic error: %v",
+	"cli.checking":             "Checking %s...",
+	"cli.found_errors":         "❌ Found %d error(s)",
+	"cli.no_errors":            "✅ No errors found",
+	"cli.types":                "Types:",
+	"cli.read_file_error":      "error reading file: %v",
+	"cli.runtime_error_wrap":   "runtime error: %w",
+	"cli.cannot_profile":       "cannot profile %s: record the profile from the .sky source",
+	"cli.write_profile":        "error writing profile: %v",
+	"cli.read_profile":         "error reading profile %s: %v",
+	"cli.stale_profile":        "Warning: %s was recorded from another version of the program; ignoring it",
+	"cli.profile_unchanged":    "Profile %s: nothing to optimize",
+	"cli.profile":              "Profile %s:",
+	"cli.parse_error_list":     "parse errors:\n%v",
+	"cli.semantic_error_list":  "semantic errors:\n%v",
+	"cli.compile_error":        "compile error: %v",
+	"cli.invalid_opt_level":    "invalid optimization level %s (use -O0 to -O%d)",
+	"cli.running_tests":        "Running %d test(s)...",
+	"cli.run_tests_error":      "Error running tests: %v",
+	"cli.read_named":           "Error reading %s: %v",
+	"cli.write_named":          "Error writing %s: %v",
+	"cli.format_error":         "Error formatting %s: %v",
+	"cli.not_formatted":        "%s: not formatted",
+	"cli.formatted":            "Formatted: %s",
+	"cli.already_formatted":    "Already formatted: %s",
+	"cli.find_files":           "Error finding files: %v",
+	"cli.no_sky_files":         "No .sky files found",
+	"cli.formatting":           "Formatting %d files...",
+	"cli.output_flag":          "Error: -o requires an output file",
+	"cli.unexpected_argument":  "Error: unexpected argument: %s",
+	"cli.repl_hint":            "Type 'exit' or 'quit' to exit, 'help' for help",
+	"cli.goodbye":              "Goodbye!",
+	"cli.missing_runtime":      "cannot find the sky runtime: %v",
+	"cli.bundled":              "Bundled %d module(s)",
+	"cli.lint_error":           "Error linting %s: %v",
+	"cli.doc_error":            "Error generating docs for %s: %v",
+	"cli.unknown_code":         "Error: unknown diagnostic code %s",
+	"cli.list_codes":           "Run \"sky explain\" to list the codes",
+	"cli.explain_usage":        "Use \"sky explain <code>\" for details, e.g. sky explain E0102",
+	"cli.suppress_hint":        "Suppress on one line with: # sky-ignore: %s",
+	"cli.lint_config":          "Config: %s",
+	"cli.lint_no_config":       "No config found; add %s or a [lint] table to %s",
+	"cli.warnings":             "Warnings:",
+	"cli.severity_error":       "error",
+	"cli.severity_warning":     "warning",
+}
@@ -0,0 +1,106 @@
+// Package i18n holds the messages of the parser, the checker, the linter,
+// the runtime and the command line in English and Turkish.
+//
+// Messages are looked up by key and formatted like fmt.Sprintf. The
+// locale comes from SKY_LANG, then LANG: a value starting with "tr" (tr,
+// tr_TR.UTF-8) selects Turkish, anything else English. Translations may
+// reorder arguments with explicit indexes such as %[2]s.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Locale is a message language
+type Locale string
+
+// Supported locales
+const (
+	English Locale = "en"
+	Turkish Locale = "tr"
+)
+
+var catalogs = map[Locale]map[string]string{
+	English: en,
+	Turkish: tr,
+}
+
+var (
+	mu      sync.RWMutex
+	current Locale
+	once    sync.Once
+)
+
+// Current returns the locale in use, read from the environment on first use
+func Current() Locale {
+	once.Do(func() {
+		mu.Lock()
+		if current == "" {
+			current = FromEnv(os.Getenv("SKY_LANG"), os.Getenv("LANG"))
+		}
+		mu.Unlock()
+	})
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// SetLocale overrides the locale taken from the environment
+func SetLocale(l Locale) {
+	once.Do(func() {})
+	mu.Lock()
+	current = l
+	mu.Unlock()
+}
+
+// FromEnv picks the locale for the values of SKY_LANG and LANG; the
+// first one that is set wins
+func FromEnv(values ...string) Locale {
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		return Parse(v)
+	}
+	return English
+}
+
+// Parse maps a locale name such as "tr", "tr_TR.UTF-8" or "en-US" to a
+// supported locale, English when it is not one
+func Parse(name string) Locale {
+	lang := strings.ToLower(name)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := catalogs[Locale(lang)]; ok {
+		return Locale(lang)
+	}
+	return English
+}
+
+// T formats the message key in the current locale. A key missing from
+// the locale falls back to English, and an unknown key is returned as is.
+func T(key string, args ...interface{}) string {
+	format, ok := catalogs[Current()][key]
+	if !ok {
+		if format, ok = en[key]; !ok {
+			format = key
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Keys returns the keys of the catalog of l
+func Keys(l Locale) []string {
+	keys := make([]string, 0, len(catalogs[l]))
+	for key := range catalogs[l] {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,88 @@
+package i18n
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	for _, key := range Keys(English) {
+		if _, ok := tr[key]; !ok {
+			t.Errorf("%s is missing from the Turkish catalog", key)
+		}
+	}
+	for _, key := range Keys(Turkish) {
+		if _, ok := en[key]; !ok {
+			t.Errorf("%s is missing from the English catalog", key)
+		}
+	}
+}
+
+var verb = regexp.MustCompile(`%(\[\d+\])?[a-zA-Z]`)
+
+// verbs returns the verbs of format in argument order
+func verbs(format string) []string {
+	matches := verb.FindAllStringSubmatch(strings.ReplaceAll(format, "%%", ""), -1)
+	out := make([]string, len(matches))
+	next := 1
+	for _, m := range matches {
+		i := next
+		if m[1] != "" {
+			i = int(m[1][1] - '0')
+		}
+		for len(out) < i {
+			out = append(out, "")
+		}
+		out[i-1] = m[0][len(m[0])-1:]
+		next = i + 1
+	}
+	return out
+}
+
+func TestCatalogsHaveSameArguments(t *testing.T) {
+	for key, format := range en {
+		want, got := verbs(format), verbs(tr[key])
+		if strings.Join(want, ",") != strings.Join(got, ",") {
+			t.Errorf("%s: English takes %v, Turkish takes %v", key, want, got)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	defer SetLocale(Current())
+
+	SetLocale(English)
+	if got := T("runtime.undefined", "x"); got != "undefined: x" {
+		t.Errorf("English = %q", got)
+	}
+	SetLocale(Turkish)
+	if got := T("runtime.undefined", "x"); got != "tanımsız: x" {
+		t.Errorf("Turkish = %q", got)
+	}
+	if got := T("runtime.division_by_zero"); got != "sıfıra bölme" {
+		t.Errorf("without arguments = %q", got)
+	}
+	if got := T("no.such.key"); got != "no.such.key" {
+		t.Errorf("unknown key = %q", got)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	tests := []struct {
+		skyLang, lang string
+		want          Locale
+	}{
+		{"", "", English},
+		{"", "tr_TR.UTF-8", Turkish},
+		{"en", "tr_TR.UTF-8", English},
+		{"TR", "en_US.UTF-8", Turkish},
+		{"", "de_DE", English},
+		{"", "C", English},
+	}
+	for _, tt := range tests {
+		if got := FromEnv(tt.skyLang, tt.lang); got != tt.want {
+			t.Errorf("FromEnv(%q, %q) = %s, want %s", tt.skyLang, tt.lang, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package i18n
+
+// tr holds the Turkish messages
+var tr = map[string]string{
+	// Parser
+	"parser.expected_token":        "sonraki token %s olmalıydı, %s bulundu (%s)",
+	"parser.at":                    "%s (%s)",
+	"parser.no_prefix":             "%s ile başlayan bir ifade yok",
+	"parser.invalid_integer":       "%q tamsayı olarak okunamadı",
+	"parser.invalid_float":         "%q ondalık sayı olarak okunamadı",
+	"parser.unexpected_type":       "beklenmeyen tip: %s",
+	"parser.enum_end":              "enum'u kapatmak için 'end' bekleniyordu",
+	"parser.match_arrow":           "match kolunda => bekleniyordu",
+	"parser.match_brace":           "match ifadesinde } bekleniyordu",
+	"parser.rest_pattern":          "rest deseni yalnızca liste deseni içinde kullanılabilir",
+	"parser.unexpected_in_pattern": "desende beklenmeyen %s",
+	"parser.mixed_patterns":        "%s(...) içinde sıralı ve alan desenleri birlikte kullanılamaz",
+	"parser.multiple_rest":         "liste deseninde en fazla bir rest öğesi olabilir",
+	"parser.dict_pattern_keys":     "dict deseni anahtarları sabit değer olmalıdır",
+	"parser.interface_member":      "interface %s yalnızca metod imzaları içerebilir, %s bulundu",
+
+	// Checker
+	"sema.type_mismatch":              "tip uyuşmazlığı: %s, %s tipine atanamaz",
+	"sema.const_value":                "const bildiriminin bir değeri olmalıdır",
+	"sema.return_outside":             "fonksiyon dışında return ifadesi",
+	"sema.return_mismatch":            "dönüş tipi uyuşmazlığı: %s bekleniyordu, %s bulundu",
+	"sema.if_condition":               "if koşulu bool olmalıdır, %s bulundu",
+	"sema.elif_condition":             "elif koşulu bool olmalıdır, %s bulundu",
+	"sema.while_condition":            "while koşulu bool olmalıdır, %s bulundu",
+	"sema.guard_condition":            "match koruması bool olmalıdır, %s bulundu",
+	"sema.not_a_class":                "%s bir sınıf değil",
+	"sema.undefined_superclass":       "tanımsız üst sınıf: %s",
+	"sema.undefined":                  "tanımsız: %s",
+	"sema.list_element_mismatch":      "liste öğesi tip uyuşmazlığı: %s bekleniyordu, %s bulundu",
+	"sema.dict_key_mismatch":          "dict anahtarı tip uyuşmazlığı: %s bekleniyordu, %s bulundu",
+	"sema.dict_value_mismatch":        "dict değeri tip uyuşmazlığı: %s bekleniyordu, %s bulundu",
+	"sema.operator_not":               "! operatörü %s tipine uygulanamaz",
+	"sema.operator":                   "%s operatörü %s tipine uygulanamaz",
+	"sema.assign_const":               "const değişken '%s' değiştirilemez",
+	"sema.instantiate_interface":      "interface %s örneklenemez",
+	"sema.arg_count_min":              "yanlış argüman sayısı: en az %d bekleniyordu, %d verildi",
+	"sema.arg_count":                  "yanlış argüman sayısı: %d bekleniyordu, %d verildi",
+	"sema.arg_count_range":            "yanlış argüman sayısı: %d-%d bekleniyordu, %d verildi",
+	"sema.list_index":                 "liste indeksi int olmalıdır, %s bulundu",
+	"sema.no_exported_member":         "%s içinde dışa açık %s üyesi yok",
+	"sema.await_outside":              "await yalnızca async fonksiyonlarda kullanılabilir",
+	"sema.yield_outside":              "yield yalnızca coop fonksiyonlarda kullanılabilir",
+	"sema.incompatible_method":        "%s.%s metodu %s.%s ile uyumsuz: %s bekleniyordu, %s bulundu",
+	"sema.class_no_member":            "%s sınıfının %s üyesi yok",
+	"sema.no_field_or_method":         "%s tipinin %s adında alanı veya metodu yok",
+	"sema.no_field":                   "%s tipinin %s alanı yok",
+	"sema.super_outside":              "super bir sınıf dışında kullanıldı",
+	"sema.super_no_superclass":        "super, üst sınıfı olmayan %s sınıfında kullanıldı",
+	"sema.no_method":                  "%s tipinin %s metodu yok",
+	"sema.duplicate_type_param":       "tekrarlanan tip parametresi %s",
+	"sema.type_arg_count":             "%s için yanlış tip argümanı sayısı: %d bekleniyordu, %d verildi",
+	"sema.constraint":                 "%s tipi %s kısıtını karşılamıyor (%[4]s içindeki %[3]s tip parametresi)",
+	"sema.undefined_interface":        "tanımsız interface: %s",
+	"sema.not_interface":              "%s bir interface değil",
+	"sema.duplicate_interface_method": "%[2]s interface'inde tekrarlanan metod %[1]s",
+	"sema.not_implemented":            "%s sınıfı %s interface'ini karşılamıyor: %s",
+	"sema.arg_not_satisfy":            "%d. argüman tip uyuşmazlığı: %s, %s interface'ini karşılamıyor (%s)",
+	"sema.arg_mismatch":               "%d. argüman tip uyuşmazlığı: %s bekleniyordu, %s bulundu",
+	"sema.non_exhaustive":             "%s üzerinde eksik match: %s karşılanmıyor",
+	"sema.duplicate_arm":              "tekrarlanan match kolu: %s",
+	"sema.unreachable_arm":            "erişilemeyen match kolu: %s önceki kollarca zaten karşılanıyor",
+	"sema.class_pattern_fields":       "%s sınıf deseni alan adları gerektirir: %s(alan: desen)",
+	"sema.pattern_not_class":          "geçersiz desen: %s bir sınıf değil",
+	"sema.pattern_mismatch":           "desen tip uyuşmazlığı: %s deseni %s ile eşleşemez",
+	"sema.variant_mismatch":           "desen tip uyuşmazlığı: %[1]s, %[3]s değil %[2]s varyantı",
+	"sema.or_pattern_bindings":        "or-deseni alternatifleri aynı değişkenleri bağlamalıdır: %s [%s] bağlıyor, %s [%s] bağlıyor",
+	"sema.pattern_not_variant":        "geçersiz desen: %s bir enum varyantı değil",
+	"sema.payload_count":              "%s varyantı %d değer taşıyor, desende %d var",
+	"sema.cannot_load_module":         "%s modülü yüklenemiyor: %v",
+	"sema.module_parse_errors":        "%s modülünde ayrıştırma hataları: %s",
+	"sema.nil_subject":                "%s (tip %s)",
+	"sema.possibly_nil":               "değer nil olabilir: %[2]s için %[1]s (önce nil kontrolü yapın)",
+	"sema.missing_method":             "eksik metod %s",
+	"sema.method_signature":           "%s metodunun imzası %s, beklenen %s",
+	"sema.in_call":                    "%s (%s çağrısında)",
+	"sema.in_constructor":             "%s (%s constructor'ında)",
+	"sema.import_cycle":               "import döngüsü: %s",
+	"sema.already_defined":            "'%s' sembolü bu kapsamda zaten tanımlı",
+	"sema.nil_operator":               "%s operatörü kullanılamaz",
+	"sema.nil_call":                   "çağrı yapılamaz",
+	"sema.nil_index":                  "indekslenemez",
+	"sema.nil_access":                 "%s üyesine erişilemez",
+	"sema.nil_assign":                 "%s üyesine atama yapılamaz",
+	"sema.unawaited_promise":          "%s async çağrısının sonucu (%s) kullanılmıyor; await edin ya da promise'i saklayın",
+	"sema.blocking_call":              "%s, %s async fonksiyonunu bloklar; yerine await %s(...) kullanın",
+	"sema.explicit_type_args":         "açık tip argümanları desteklenmiyor: %s bunları argümanlarından çıkarır",
+
+	// Linter
+	"lint.unused_var":       "'%s' değişkeni tanımlanmış ama hiç kullanılmamış",
+	"lint.return_outside":   "fonksiyon dışında return ifadesi",
+	"lint.shadowing":        "'%s' değişkeni önceki bildirimi gölgeliyor",
+	"lint.unsafe_block":     "unsafe bloğu güvenlik kontrollerini atlar",
+	"lint.division_by_zero": "sıfıra bölme",
+	"lint.parse_errors":     "ayrıştırma hataları: %v",
+	"lint.plugin_error":     "%s lint eklentisi yüklenemiyor: %v",
+	"lint.unknown_rule":     "bilinmeyen kural %q (bkz. sky lint --rules)",
+	"lint.invalid_severity": "%s kuralı: geçersiz önem derecesi %v (error, warning, info veya off kullanın)",
+	"lint.unknown_option":   "%s kuralının %s seçeneği yok",
+	"lint.option_type":      "%s kuralı: %s seçeneği varsayılan değeri %#v ile aynı tipte olmalıdır",
+
+	// Runtime
+	"runtime.unknown_statement":             "bilinmeyen ifade türü: %T",
+	"runtime.recursion_depth":               "'%s' fonksiyonunda azami özyineleme derinliği (1000) aşıldı",
+	"runtime.stack_overflow":                "yığın taşması: azami özyineleme derinliği (%d) aşıldı",
+	"runtime.call_depth":                    "yığın taşması: '%[2]s' fonksiyonunda azami çağrı derinliği (%[1]d) aşıldı",
+	"runtime.undefined_decorator":           "tanımsız dekoratör: %s",
+	"runtime.not_decorator":                 "%s bir dekoratör fonksiyonu değil",
+	"runtime.decorator_result":              "%s dekoratörü bir fonksiyon döndürmedi",
+	"runtime.not_iterable":                  "%T üzerinde dolaşılamaz",
+	"runtime.undefined_self":                "tanımsız: self",
+	"runtime.undefined_super":               "tanımsız: super",
+	"runtime.undefined":                     "tanımsız: %s",
+	"runtime.undefined_variable":            "tanımsız değişken: %s",
+	"runtime.unknown_expression":            "bilinmeyen ifade türü: %T",
+	"runtime.negate":                        "- operatörü yalnızca sayılara uygulanabilir",
+	"runtime.unknown_prefix":                "bilinmeyen önek operatörü: %s",
+	"runtime.undefined_property":            "tanımsız özellik: %s",
+	"runtime.assign_member":                 "yalnızca nesne üyelerine atama yapılabilir",
+	"runtime.assign_target":                 "atamanın sol tarafı bir isim olmalıdır",
+	"runtime.division_by_zero":              "sıfıra bölme",
+	"runtime.unsupported_operation":         "desteklenmeyen işlem: %T %s %T",
+	"runtime.undefined_method":              "tanımsız metod: %s",
+	"runtime.not_callable":                  "%s bir fonksiyon veya sınıf değil",
+	"runtime.index_out_of_range":            "liste indeksi aralık dışında",
+	"runtime.index_unsupported":             "indeksleme desteklenmiyor",
+	"runtime.index_assign_unsupported":      "indekse atama desteklenmiyor",
+	"runtime.undefined_superclass":          "tanımsız üst sınıf: %s",
+	"runtime.not_a_class":                   "%s bir sınıf değil",
+	"runtime.unsupported_member":            "desteklenmeyen sınıf üyesi: %T",
+	"runtime.member_access":                 "%T üyesine erişilemez",
+	"runtime.invalid_int":                   "int() için geçersiz değer: %s",
+	"runtime.invalid_float":                 "float() için geçersiz değer: %s",
+	"runtime.cannot_load_module":            "%s modülü yüklenemiyor: %v",
+	"runtime.module_parse_errors":           "%s modülünde ayrıştırma hataları: %v",
+	"runtime.module_error":                  "%s modülünde hata: %v",
+	"runtime.undefined_interface":           "tanımsız interface: %s",
+	"runtime.not_interface":                 "%s bir interface değil",
+	"runtime.not_implemented":               "%s sınıfı %s interface'ini karşılamıyor: eksik metod %s",
+	"runtime.variant_args":                  "%s %d argüman bekliyor, %d verildi",
+	"runtime.no_match":                      "eksik match: hiçbir desen eşleşmedi",
+	"runtime.class_pattern_fields":          "%s sınıf deseni alan adları gerektirir: %s(alan: desen)",
+	"runtime.rest_pattern":                  "rest deseni yalnızca liste deseni içinde kullanılabilir",
+	"runtime.unsupported_pattern":           "desteklenmeyen desen türü: %T",
+	"runtime.yield_limit":                   "generator yield sınırı aşıldı",
+	"runtime.generator_running":             "generator zaten çalışıyor",
+	"runtime.never_settles":                 "hiç sonuçlanmayan bir promise bekleniyor",
+	"runtime.abs_numeric":                   "abs() sayısal bir argüman ister",
+	"runtime.abs_argument":                  "abs() bir argüman ister",
+	"runtime.min_argument":                  "min() en az bir argüman ister",
+	"runtime.max_argument":                  "max() en az bir argüman ister",
+	"runtime.round_numeric":                 "round() sayısal bir argüman ister",
+	"runtime.pow_numeric":                   "pow() sayısal argümanlar ister",
+	"runtime.pow_arguments":                 "pow() iki argüman ister",
+	"runtime.sqrt_numeric":                  "sqrt() sayısal bir argüman ister",
+	"runtime.sqrt_negative":                 "sqrt() negatif sayıya uygulanamaz",
+	"runtime.sqrt_argument":                 "sqrt() bir argüman ister",
+	"runtime.floor_numeric":                 "floor() sayısal bir argüman ister",
+	"runtime.ceil_numeric":                  "ceil() sayısal bir argüman ister",
+	"runtime.map_arguments":                 "map() bir fonksiyon ve dolaşılabilir bir değer ister",
+	"runtime.filter_arguments":              "filter() bir fonksiyon ve dolaşılabilir bir değer ister",
+	"runtime.upper_string":                  "upper() string bir argüman ister",
+	"runtime.lower_string":                  "lower() string bir argüman ister",
+	"runtime.capitalize_string":             "capitalize() string bir argüman ister",
+	"runtime.split_string":                  "split() string bir argüman ister",
+	"runtime.join_arguments":                "join() string bir ayraç ve bir liste ister",
+	"runtime.replace_arguments":             "replace() string, eski ve yeni değer ister",
+	"runtime.strip_string":                  "strip() string bir argüman ister",
+	"runtime.startswith_arguments":          "startswith() bir string ve önek ister",
+	"runtime.endswith_arguments":            "endswith() bir string ve sonek ister",
+	"runtime.fs_read_text_arguments":        "fs_read_text() string bir dosya adı ister",
+	"runtime.fs_write_text_arguments":       "fs_write_text() string dosya adı ve içerik ister",
+	"runtime.fs_exists_arguments":           "fs_exists() string bir dosya adı ister",
+	"runtime.fs_mkdir_arguments":            "fs_mkdir() string bir dizin adı ister",
+	"runtime.fs_list_dir_arguments":         "fs_list_dir() string bir dizin adı ister",
+	"runtime.os_getenv_arguments":           "os_getenv() string bir ortam değişkeni adı ister",
+	"runtime.os_setenv_arguments":           "os_setenv() string anahtar ve değer ister",
+	"runtime.time_format_arguments":         "time_format() bir zaman damgası (int) ve biçim string'i ister",
+	"runtime.time_parse_arguments":          "time_parse() bir zaman string'i ve biçim string'i ister",
+	"runtime.time_add_unit":                 "time_add() birimi ms, s, m, h veya d olmalıdır",
+	"runtime.time_add_arguments":            "time_add() zaman damgası, süre ve birim (ms/s/m/h/d) ister",
+	"runtime.time_diff_arguments":           "time_diff() iki zaman damgası ister",
+	"runtime.promise_all_arguments":         "Promise_all() bir promise listesi ister",
+	"runtime.promise_all_settled_arguments": "Promise_allSettled() bir promise listesi ister",
+	"runtime.http_get_arguments":            "http_get() string bir URL ister",
+	"runtime.http_post_arguments":           "http_post() string URL ve veri ister",
+	"runtime.http_put_arguments":            "http_put() string URL ve veri ister",
+	"runtime.http_delete_arguments":         "http_delete() string bir URL ister",
+	"runtime.crypto_md5_arguments":          "crypto_md5() string veri ister",
+	"runtime.crypto_sha256_arguments":       "crypto_sha256() string veri ister",
+	"runtime.crypto_aes_encrypt_arguments":  "crypto_aes_encrypt() string veri ve anahtar ister",
+	"runtime.append_arguments":              "append() bir liste ve öğe ister",
+	"runtime.pop_empty":                     "boş listeden pop yapılamaz",
+	"runtime.pop_range":                     "pop indeksi aralık dışında",
+	"runtime.pop_list":                      "pop() bir liste ister",
+	"runtime.insert_arguments":              "insert() liste, indeks ve öğe ister",
+	"runtime.not_in_list":                   "öğe listede yok",
+	"runtime.remove_arguments":              "remove() bir liste ve öğe ister",
+	"runtime.clear_list":                    "clear() bir liste ister",
+	"runtime.reverse_list":                  "reverse() bir liste ister",
+	"runtime.copy_list":                     "copy() bir liste ister",
+	"runtime.extend_lists":                  "extend() iki liste ister",
+	"runtime.keys_dict":                     "keys() bir dict ister",
+	"runtime.values_dict":                   "values() bir dict ister",
+	"runtime.get_arguments":                 "get() bir dict ve anahtar ister",
+	"runtime.key_not_found":                 "anahtar bulunamadı",
+	"runtime.pop_dict":                      "pop() bir dict ve anahtar ister",
+	"runtime.clear_dict":                    "clear() bir dict ister",
+	"runtime.update_dicts":                  "update() iki dict ister",
+	"runtime.builtin_error":                 "%s hatası: %v",
+	"runtime.builtin_read_error":            "%s okuma hatası: %v",
+	"runtime.argument_type":                 "%[2]s fonksiyonunun %[1]s argümanı: %[3]s bekleniyordu, %[4]s bulundu",
+	"runtime.called_at":                     " (%s konumunda çağrıldı)",
+	"runtime.return_type":                   "%s dönüş değeri: %s bekleniyordu, %s bulundu",
+	"runtime.let_type":                      "let %s: %s bekleniyordu, %s bulundu",
+	"runtime.break_outside":                 "döngü dışında break",
+	"runtime.continue_outside":              "döngü dışında continue",
+	"runtime.invalid_assignment":            "geçersiz atama hedefi",
+	"runtime.unknown_operator":              "bilinmeyen operatör: %s",
+	"runtime.tier_coroutine":                "async ve coop fonksiyonlar desteklenmiyor",
+	"runtime.tier_instruction":              "%s desteklenmiyor",
+	"runtime.tier_self":                     "self desteklenmiyor",
+	"runtime.tier_nested":                   "iç içe fonksiyon %s desteklenmiyor",
+	"runtime.tier_captures":                 "değişken yakalayan lambda'lar desteklenmiyor",
+
+	// Command line
+	"cli.unknown_command":      "Bilinmeyen komut: %s",
+	"cli.no_input":             "Hata: girdi dosyası belirtilmedi",
+	"cli.no_inputs":            "Hata: girdi dosyaları belirtilmedi",
+	"cli.error":                "Hata: %v",
+	"cli.runtime_typecheck":    "Hata: --runtime-typecheck .sky kaynaklarını yorumlayıcıda veya --vm ile çalıştırır",
+	"cli.read_file":            "Dosya okunurken hata: %v",
+	"cli.parse_errors":         "Ayrıştırma hataları:",
+	"cli.semantic_errors":      "Anlamsal hatalar:",
+	"cli.runtime_error":        "Çalışma zamanı hatası: %s",
+	"cli.requires_value":       "%s bir değer gerektirir",
+	"cli.runtime_needs_bundle": "Hata: --runtime, --bundle gerektirir",
+	"cli.bundle_flags":         "Hata: --bundle programı sky çalışma zamanında çalıştırır; --target veya --lib almaz",
+	"cli.unknown_target":       "Hata: bilinmeyen derleme hedefi %q (go, c veya llvm kullanın)",
+	"cli.lib_needs_c":          "Hata: --lib, --target=c gerektirir",
+	"cli.native_profiles":      "Hata: yerel arka uçlar profil kullanmaz; optimize edilmiş bytecode için sky compile --pgo kullanın",
+	"cli.building":             "%s derleniyor...",
+	"cli.build_error":          "Derleme hatası: %v",
+	"cli.built":                "Başarıyla derlendi: %s",
+	"cli.running_tests_in":     "%s/ içindeki testler çalıştırılıyor",
+	"cli.find_test_files":      "Test dosyaları aranırken hata: %v",
+	"cli.no_test_files_in":     "%s/ içinde test dosyası bulunamadı",
+	"cli.no_test_files":        "%s içinde test dosyası bulunamadı",
+	"cli.testing":              "%s test ediliyor... ",
+	"cli.fail":                 "❌ BAŞARISIZ: %v",
+	"cli.output_mismatch":      "❌ BAŞARISIZ: çıktı uyuşmuyor",
+	"cli.expected":             "  Beklenen: %s",
+	"cli.got":                  "  Alınan: %s",
+	"cli.pass":                 "✅ BAŞARILI",
+	"cli.test_counts":          "%d başarılı, %d başarısız",
+	"cli.parse_error":          "ayrıştırma hatası: %v",
+	"cli.semantic_error":       "anlamsal hata: %v",
+	"cli.checking":             "%s denetleniyor...",
+	"cli.found_errors":         "❌ %d hata bulundu",
+	"cli.no_errors":            "✅ Hata bulunamadı",
+	"cli.types":                "Tipler:",
+	"cli.read_file_error":      "dosya okunurken hata: %v",
+	"cli.runtime_error_wrap":   "çalışma zamanı hatası: %w",
+	"cli.cannot_profile":       "%s profillenemez: profili .sky kaynağından kaydedin",
+	"cli.write_profile":        "profil yazılırken hata: %v",
+	"cli.read_profile":         "%s profili okunurken hata: %v",
+	"cli.stale_profile":        "Uyarı: %s programın başka bir sürümünden kaydedilmiş; yok sayılıyor",
+	"cli.profile_unchanged":    "Profil %s: optimize edilecek bir şey yok",
+	"cli.profile":              "Profil %s:",
+	"cli.parse_error_list":     "ayrıştırma hataları:\n%v",
+	"cli.semantic_error_list":  "anlamsal hatalar:\n%v",
+	"cli.compile_error":        "derleme hatası: %v",
+	"cli.invalid_opt_level":    "geçersiz optimizasyon seviyesi %s (-O0 ile -O%d arası kullanın)",
+	"cli.running_tests":        "%d test çalıştırılıyor...",
+	"cli.run_tests_error":      "Testler çalıştırılırken hata: %v",
+	"cli.read_named":           "%s okunurken hata: %v",
+	"cli.write_named":          "%s yazılırken hata: %v",
+	"cli.format_error":         "%s biçimlendirilirken hata: %v",
+	"cli.not_formatted":        "%s: biçimlendirilmemiş",
+	"cli.formatted":            "Biçimlendirildi: %s",
+	"cli.already_formatted":    "Zaten biçimlendirilmiş: %s",
+	"cli.find_files":           "Dosyalar aranırken hata: %v",
+	"cli.no_sky_files":         ".sky dosyası bulunamadı",
+	"cli.formatting":           "%d dosya biçimlendiriliyor...",
+	"cli.output_flag":          "Hata: -o bir çıktı dosyası gerektirir",
+	"cli.unexpected_argument":  "Hata: beklenmeyen argüman: %s",
+	"cli.repl_hint":            "Çıkmak için 'exit' veya 'quit', yardım için 'help' yazın",
+	"cli.goodbye":              "Hoşça kalın!",
+	"cli.missing_runtime":      "sky çalışma zamanı bulunamadı: %v",
+	"cli.bundled":              "%d modül paketlendi",
+	"cli.lint_error":           "%s lint edilirken hata: %v",
+	"cli.doc_error":            "%s için belge üretilirken hata: %v",
+	"cli.unknown_code":         "Hata: bilinmeyen tanı kodu %s",
+	"cli.list_codes":           "Kodları listelemek için \"sky explain\" çalıştırın",
+	"cli.explain_usage":        "Ayrıntılar için \"sky explain <kod>\" kullanın, örn. sky explain E0102",
+	"cli.suppress_hint":        "Tek satırda susturmak için: # sky-ignore: %s",
+	"cli.lint_config":          "Yapılandırma: %s",
+	"cli.lint_no_config":       "Yapılandırma bulunamadı; %s ekleyin ya da %s dosyasına bir [lint] tablosu ekleyin",
+	"cli.warnings":             "Uyarılar:",
+	"cli.severity_error":       "hata",
+	"cli.severity_warning":     "uyarı",
+}
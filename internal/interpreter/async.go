@@ -0,0 +1,197 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+)
+
+// Async calls. Each call of an async function runs as a task on its own
+// goroutine, but only one goroutine runs interpreter code at a time: the
+// interpreter switches to a task and waits until it returns or awaits a
+// pending promise. Tasks share i.env and the other per-call fields, so a
+// switch saves them for the task left and restores them for the one
+// resumed.
+//
+// A task starts right away, like a call in the VM. When it awaits a
+// pending promise it is parked until the promise settles, then put on the
+// ready queue. Native async functions run the builtin on a goroutine that
+// never touches the interpreter and report the result over settled, which
+// is read when no task is ready.
+
+// scheduler runs the async tasks of an interpreter
+type scheduler struct {
+	current  *task                // task running, nil outside tasks
+	ready    []*task              // tasks whose promise settled
+	waiters  map[*Promise][]*task // tasks parked on a pending promise
+	settled  chan settlement      // results of native async calls
+	inflight int                  // native async calls not settled yet
+}
+
+// task is an async call running on its own goroutine
+type task struct {
+	resume chan struct{}
+	yield  chan struct{}
+	state  taskState
+
+	// value or error the task resumes with when it is ready
+	value Value
+	err   error
+}
+
+// taskState is the interpreter state of a task while it is switched out
+type taskState struct {
+	env            *Environment
+	hot            *hotFunction
+	recursionDepth int
+}
+
+// settlement is the result of a native async call
+type settlement struct {
+	promise *Promise
+	value   Value
+	err     error
+}
+
+// sched returns the scheduler, creating it on the first async call
+func (i *Interpreter) sched() *scheduler {
+	if i.tasks == nil {
+		i.tasks = &scheduler{
+			waiters: make(map[*Promise][]*task),
+			settled: make(chan settlement, 64),
+		}
+	}
+	return i.tasks
+}
+
+// newPromise returns a pending promise settled by the scheduler
+func (s *scheduler) newPromise() *Promise {
+	promise := &Promise{State: "pending"}
+	s.waiters[promise] = nil
+	return promise
+}
+
+// startAsync runs body as a task until it returns or suspends and
+// returns its promise
+func (i *Interpreter) startAsync(body func() (Value, error)) *Promise {
+	s := i.sched()
+	promise := s.newPromise()
+	t := &task{resume: make(chan struct{}), yield: make(chan struct{})}
+	t.state = i.saveState()
+
+	go func() {
+		<-t.resume
+		value, err := runTask(body)
+		s.settle(promise, value, err)
+		t.yield <- struct{}{}
+	}()
+	i.switchTo(t)
+	return promise
+}
+
+// runTask runs body, turning a panic into an error so the task still
+// hands control back
+func runTask(body func() (Value, error)) (value Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return body()
+}
+
+// switchTo runs t until it yields and restores the state of the caller
+func (i *Interpreter) switchTo(t *task) {
+	s := i.tasks
+	saved, previous := i.saveState(), s.current
+	s.current = t
+	i.restoreState(t.state)
+
+	t.resume <- struct{}{}
+	<-t.yield
+
+	t.state = i.saveState()
+	s.current = previous
+	i.restoreState(saved)
+}
+
+func (i *Interpreter) saveState() taskState {
+	return taskState{env: i.env, hot: i.hot, recursionDepth: i.recursionDepth}
+}
+
+func (i *Interpreter) restoreState(state taskState) {
+	i.env, i.hot, i.recursionDepth = state.env, state.hot, state.recursionDepth
+}
+
+// spawn runs a native async function on a goroutine and returns its
+// promise
+func (i *Interpreter) spawn(body func() (Value, error)) *Promise {
+	s := i.sched()
+	promise := s.newPromise()
+	s.inflight++
+
+	go func() {
+		value, err := runTask(body)
+		s.settled <- settlement{promise: promise, value: value, err: err}
+	}()
+	return promise
+}
+
+// settle resolves or rejects promise and readies the tasks awaiting it
+func (s *scheduler) settle(promise *Promise, value Value, err error) {
+	if err != nil {
+		promise.State, promise.Error = "rejected", err
+	} else {
+		promise.State, promise.Value = "fulfilled", value
+	}
+	for _, t := range s.waiters[promise] {
+		t.value, t.err = value, err
+		s.ready = append(s.ready, t)
+	}
+	delete(s.waiters, promise)
+}
+
+// await returns the result of promise. A task awaiting a pending promise
+// is parked; outside tasks the ready tasks run until promise settles.
+func (i *Interpreter) await(promise *Promise) (Value, error) {
+	if s := i.tasks; s != nil && promise.State == "pending" {
+		if _, ok := s.waiters[promise]; ok {
+			if t := s.current; t != nil {
+				s.waiters[promise] = append(s.waiters[promise], t)
+				t.yield <- struct{}{}
+				<-t.resume
+				return t.value, t.err
+			}
+			if err := i.runUntil(promise); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return promise.Await()
+}
+
+// runUntil runs ready tasks and settles native calls until promise
+// settles; with a nil promise it runs until no work is left
+func (i *Interpreter) runUntil(promise *Promise) error {
+	s := i.tasks
+	for promise == nil || promise.State == "pending" {
+		switch {
+		case len(s.ready) > 0:
+			t := s.ready[0]
+			s.ready = s.ready[1:]
+			i.switchTo(t)
+
+		case s.inflight > 0:
+			result := <-s.settled
+			s.inflight--
+			s.settle(result.promise, result.value, result.err)
+
+		case promise == nil:
+			return nil
+
+		default:
+			return &RuntimeError{Message: i18n.T("runtime.never_settles")}
+		}
+	}
+	return nil
+}
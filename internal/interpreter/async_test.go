@@ -0,0 +1,116 @@
+package interpreter
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+	"github.com/mburakmmm/sky-lang/internal/parser"
+)
+
+// run interprets source and returns what it printed to stdout
+func run(t *testing.T, source string) (string, error) {
+	t.Helper()
+	p := parser.New(lexer.New(source, "test.sky"))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parse errors: %v", p.Errors())
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		output <- buf.String()
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	interp := New()
+	interp.SetSourceFile("test.sky")
+	runErr := interp.Eval(program)
+	os.Stdout = stdout
+	w.Close()
+	return <-output, runErr
+}
+
+func TestAsyncInterleavedAwaits(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"overlapping calls", `async function work(x)
+  await time_sleep_async(10)
+  return x
+end
+async function main
+  let a = work(10)
+  let b = work(20)
+  print(await a, await b)
+end`, "10 20\n"},
+		{"order", `async function work(name, ms)
+  print("start " + name)
+  await time_sleep_async(ms)
+  print("end " + name)
+  return name
+end
+async function main
+  let slow = work("slow", 100)
+  let fast = work("fast", 1)
+  print(await slow, await fast)
+end`, "start slow\nstart fast\nend fast\nend slow\nslow fast\n"},
+		{"nested", `async function leaf(x)
+  await time_sleep_async(1)
+  return x * 2
+end
+async function branch(x)
+  let a = leaf(x)
+  let b = leaf(x + 1)
+  let sum = await a
+  sum += await b
+  return sum
+end
+async function main
+  let l = branch(1)
+  let r = branch(10)
+  print(await l, await r)
+end`, "6 42\n"},
+		{"rejected", `async function main
+  let ok = time_sleep_async(1)
+  let bad = fs_read_text_async("/no/such/file")
+  try
+    await bad
+  catch error
+    print("caught")
+  end
+  await ok
+  print("done")
+end`, "caught\ndone\n"},
+		{"not awaited", `async function work(x)
+  await time_sleep_async(1)
+  print(x)
+end
+function main
+  work(1)
+  print(0)
+end`, "0\n1\n"},
+	}
+
+	for _, tt := range tests {
+		// The interleaving must not depend on goroutine timing
+		for n := 0; n < 5; n++ {
+			got, err := run(t, tt.source)
+			if err != nil || got != tt.want {
+				t.Errorf("%s: printed %q (%v), want %q", tt.name, got, err, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -1,9 +1,8 @@
 package interpreter
 
 import (
-	"fmt"
-
 	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 )
 
 // EnumType stores enum type metadata
@@ -18,6 +17,7 @@ func (et *EnumType) IsTruthy() bool  { return true }
 
 // VariantInfo stores variant metadata
 type VariantInfo struct {
+	Enum         string
 	Name         string
 	PayloadCount int
 }
@@ -42,52 +42,52 @@ func (i *Interpreter) evalEnumStatement(stmt *ast.EnumStatement) (Value, error)
 
 	// Register all variants
 	for _, variant := range stmt.Variants {
-		enumType.Variants[variant.Name.Value] = &VariantInfo{
+		info := &VariantInfo{
+			Enum:         stmt.Name.Value,
 			Name:         variant.Name.Value,
 			PayloadCount: len(variant.Payload),
 		}
+		enumType.Variants[variant.Name.Value] = info
 
-		// Create constructor function for each variant
-		variantName := variant.Name.Value
-		payloadCount := len(variant.Payload)
-
-		constructor := &Function{
-			Name:       variantName,
-			Parameters: []string{},
-			Env:        i.env,
-			Body: func(callEnv *Environment) (Value, error) {
-				// Get arguments
-				args, _ := callEnv.Get("__args__")
-				argList, _ := args.(*List)
+		i.env.Set(variant.Name.Value, NewVariantConstructor(info, i.env))
+	}
 
-				if len(argList.Elements) != payloadCount {
-					return nil, &RuntimeError{
-						Message: fmt.Sprintf("%s expects %d arguments, got %d",
-							variantName, payloadCount, len(argList.Elements)),
-					}
-				}
+	// Store enum type
+	i.env.Set(stmt.Name.Value, enumType)
 
-				// Create enum instance
-				payload := make([]Value, len(argList.Elements))
-				copy(payload, argList.Elements)
+	return &Nil{}, nil
+}
 
-				instance := &EnumInstance{
-					TypeName: stmt.Name.Value,
-					Variant:  variantName,
-					Payload:  payload,
+// NewVariantConstructor returns the constructor function of an enum variant
+func NewVariantConstructor(info *VariantInfo, env *Environment) *Function {
+	return &Function{
+		Name:       info.Name,
+		Parameters: []string{},
+		Env:        env,
+		Variant:    info,
+		Body: func(callEnv *Environment) (Value, error) {
+			// Get arguments
+			args, _ := callEnv.Get("__args__")
+			argList, _ := args.(*List)
+
+			if len(argList.Elements) != info.PayloadCount {
+				return nil, &RuntimeError{
+					Message: i18n.T("runtime.variant_args",
+						info.Name, info.PayloadCount, len(argList.Elements)),
 				}
+			}
 
-				return instance, nil
-			},
-		}
+			// Create enum instance
+			payload := make([]Value, len(argList.Elements))
+			copy(payload, argList.Elements)
 
-		i.env.Set(variantName, constructor)
+			return &EnumInstance{
+				TypeName: info.Enum,
+				Variant:  info.Name,
+				Payload:  payload,
+			}, nil
+		},
 	}
-
-	// Store enum type
-	i.env.Set(stmt.Name.Value, enumType)
-
-	return &Nil{}, nil
 }
 
 // evalMatchExpression evaluates a match expression
@@ -118,6 +118,19 @@ func (i *Interpreter) evalMatchExpression(expr *ast.MatchExpression) (Value, err
 			oldEnv := i.env
 			i.env = matchEnv
 
+			// Guard false ise sonraki kola geç
+			if arm.Guard != nil {
+				guard, err := i.evalExpression(arm.Guard)
+				if err != nil {
+					i.env = oldEnv
+					return nil, err
+				}
+				if !guard.IsTruthy() {
+					i.env = oldEnv
+					continue
+				}
+			}
+
 			// Execute all statements in the body
 			var result Value = &Nil{}
 			for _, stmt := range arm.Body.Statements {
@@ -134,7 +147,7 @@ func (i *Interpreter) evalMatchExpression(expr *ast.MatchExpression) (Value, err
 		}
 	}
 
-	return nil, &RuntimeError{Message: "non-exhaustive match: no pattern matched"}
+	return nil, &RuntimeError{Message: i18n.T("runtime.no_match")}
 }
 
 // matchPattern checks if a pattern matches a value
@@ -147,6 +160,18 @@ func (i *Interpreter) matchPattern(pattern ast.Expression, value Value) (bool, m
 		if p.Value == "_" {
 			return true, bindings, nil
 		}
+		// nil pattern only matches nil
+		if p.Value == "nil" || p.Value == "null" {
+			_, isNil := value.(*Nil)
+			return isNil, bindings, nil
+		}
+		// Bare variant name matches the variant regardless of payload
+		if fn, ok := i.env.Get(p.Value); ok {
+			if ctor, ok := fn.(*Function); ok && ctor.Variant != nil {
+				enumVal, ok := value.(*EnumInstance)
+				return ok && enumVal.TypeName == ctor.Variant.Enum && enumVal.Variant == ctor.Variant.Name, bindings, nil
+			}
+		}
 		// Bind identifier to value
 		bindings[p.Value] = value
 		return true, bindings, nil
@@ -172,7 +197,27 @@ func (i *Interpreter) matchPattern(pattern ast.Expression, value Value) (bool, m
 		}
 		return false, nil, nil
 
+	case *ast.FloatLiteral, *ast.PrefixExpression:
+		// Match float and negative number literals
+		expected, err := i.evalExpression(p)
+		if err != nil {
+			return false, nil, err
+		}
+		return literalEquals(expected, value), bindings, nil
+
 	case *ast.CallExpression:
+		// Class pattern without fields: Point()
+		if ident, ok := p.Function.(*ast.Identifier); ok {
+			if class, ok := i.lookupPatternClass(ident.Value); ok {
+				if len(p.Arguments) > 0 {
+					return false, nil, &RuntimeError{Message: i18n.T(
+						"runtime.class_pattern_fields", ident.Value, ident.Value)}
+				}
+				inst, ok := value.(*Instance)
+				return ok && isSubclassOf(inst.Class, class), bindings, nil
+			}
+		}
+
 		// Enum variant pattern: VariantName(args...)
 		if ident, ok := p.Function.(*ast.Identifier); ok {
 			if enumVal, ok := value.(*EnumInstance); ok {
@@ -202,27 +247,163 @@ func (i *Interpreter) matchPattern(pattern ast.Expression, value Value) (bool, m
 		return false, nil, nil
 
 	case *ast.ListLiteral:
-		// List pattern
-		if listVal, ok := value.(*List); ok {
-			if len(p.Elements) == len(listVal.Elements) {
-				for idx, elem := range p.Elements {
-					matched, elemBindings, err := i.matchPattern(elem, listVal.Elements[idx])
-					if err != nil {
-						return false, nil, err
-					}
-					if !matched {
-						return false, nil, nil
-					}
-					for k, v := range elemBindings {
-						bindings[k] = v
-					}
-				}
-				return true, bindings, nil
+		// List pattern, optionally with a rest element: [first, ...rest]
+		listVal, ok := value.(*List)
+		if !ok {
+			return false, nil, nil
+		}
+		return i.matchListPattern(p, listVal, bindings)
+
+	case *ast.DictPattern:
+		// Dict pattern: listed keys must exist and match, other keys are ignored
+		dictVal, ok := value.(*Dict)
+		if !ok {
+			return false, nil, nil
+		}
+		for idx, keyExpr := range p.Keys {
+			key, err := i.evalExpression(keyExpr)
+			if err != nil {
+				return false, nil, err
+			}
+			field, ok := dictVal.Pairs[key.String()]
+			if !ok {
+				return false, nil, nil
+			}
+			matched, fieldBindings, err := i.matchPattern(p.Values[idx], field)
+			if err != nil || !matched {
+				return false, nil, err
+			}
+			mergeBindings(bindings, fieldBindings)
+		}
+		return true, bindings, nil
+
+	case *ast.ClassPattern:
+		// Class pattern with fields: Point(x: 0, y: y)
+		class, ok := i.lookupPatternClass(p.Class.Value)
+		if !ok {
+			return false, nil, &RuntimeError{Message: i18n.T("runtime.not_a_class", p.Class.Value)}
+		}
+		inst, ok := value.(*Instance)
+		if !ok || !isSubclassOf(inst.Class, class) {
+			return false, nil, nil
+		}
+		for _, field := range p.Fields {
+			fieldVal, ok := inst.Fields[field.Name.Value]
+			if !ok {
+				return false, nil, nil
+			}
+			matched, fieldBindings, err := i.matchPattern(field.Pattern, fieldVal)
+			if err != nil || !matched {
+				return false, nil, err
+			}
+			mergeBindings(bindings, fieldBindings)
+		}
+		return true, bindings, nil
+
+	case *ast.OrPattern:
+		// First matching alternative wins
+		for _, alt := range p.Alternatives {
+			matched, altBindings, err := i.matchPattern(alt, value)
+			if err != nil {
+				return false, nil, err
+			}
+			if matched {
+				return true, altBindings, nil
 			}
 		}
 		return false, nil, nil
 
+	case *ast.AsPattern:
+		matched, innerBindings, err := i.matchPattern(p.Pattern, value)
+		if err != nil || !matched {
+			return false, nil, err
+		}
+		innerBindings[p.Name.Value] = value
+		return true, innerBindings, nil
+
+	case *ast.RestPattern:
+		return false, nil, &RuntimeError{Message: i18n.T("runtime.rest_pattern")}
+
 	default:
-		return false, nil, &RuntimeError{Message: fmt.Sprintf("unsupported pattern type: %T", pattern)}
+		return false, nil, &RuntimeError{Message: i18n.T("runtime.unsupported_pattern", pattern)}
+	}
+}
+
+// matchListPattern list elemanlarını eşler; en fazla bir rest elemanı kalan elemanları toplar
+func (i *Interpreter) matchListPattern(p *ast.ListLiteral, list *List, bindings map[string]Value) (bool, map[string]Value, error) {
+	restIdx := -1
+	for idx, elem := range p.Elements {
+		if _, ok := elem.(*ast.RestPattern); ok {
+			restIdx = idx
+		}
+	}
+
+	if restIdx < 0 && len(list.Elements) != len(p.Elements) {
+		return false, nil, nil
+	}
+	if restIdx >= 0 && len(list.Elements) < len(p.Elements)-1 {
+		return false, nil, nil
+	}
+
+	// Rest'ten sonraki pattern'ler listenin sonundan eşlenir
+	tail := len(p.Elements) - restIdx - 1
+	for idx, elem := range p.Elements {
+		if idx == restIdx {
+			rest := elem.(*ast.RestPattern)
+			if rest.Name != nil && rest.Name.Value != "_" {
+				remaining := make([]Value, len(list.Elements)-len(p.Elements)+1)
+				copy(remaining, list.Elements[idx:idx+len(remaining)])
+				bindings[rest.Name.Value] = &List{Elements: remaining}
+			}
+			continue
+		}
+
+		valueIdx := idx
+		if restIdx >= 0 && idx > restIdx {
+			valueIdx = len(list.Elements) - tail + (idx - restIdx - 1)
+		}
+		matched, elemBindings, err := i.matchPattern(elem, list.Elements[valueIdx])
+		if err != nil || !matched {
+			return false, nil, err
+		}
+		mergeBindings(bindings, elemBindings)
+	}
+	return true, bindings, nil
+}
+
+// lookupPatternClass pattern'deki ismin bir sınıf olup olmadığını kontrol eder
+func (i *Interpreter) lookupPatternClass(name string) (*Class, bool) {
+	value, ok := i.env.Get(name)
+	if !ok {
+		return nil, false
+	}
+	class, ok := value.(*Class)
+	return class, ok
+}
+
+// literalEquals literal pattern değerini eşlenen değerle karşılaştırır
+func literalEquals(expected, value Value) bool {
+	switch e := expected.(type) {
+	case *Integer:
+		switch v := value.(type) {
+		case *Integer:
+			return e.Value == v.Value
+		case *Float:
+			return float64(e.Value) == v.Value
+		}
+	case *Float:
+		switch v := value.(type) {
+		case *Float:
+			return e.Value == v.Value
+		case *Integer:
+			return e.Value == float64(v.Value)
+		}
+	}
+	return false
+}
+
+func mergeBindings(dst, src map[string]Value) {
+	for k, v := range src {
+		dst[k] = v
 	}
 }
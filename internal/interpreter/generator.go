@@ -1,6 +1,10 @@
 package interpreter
 
-import "github.com/mburakmmm/sky-lang/internal/ast"
+import (
+	"github.com/mburakmmm/sky-lang/internal/ast"
+
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+)
 
 // YieldSignal represents a yield from a generator
 type YieldSignal struct {
@@ -103,7 +107,7 @@ func (yc *YieldCollector) executeAndCollectYields(block *ast.BlockStatement, env
 			if yieldSig, isYield := err.(*YieldSignal); isYield {
 				yc.count++
 				if yc.count > yc.maxYields {
-					return nil, &RuntimeError{Message: "generator yield limit exceeded"}
+					return nil, &RuntimeError{Message: i18n.T("runtime.yield_limit")}
 				}
 				*yc.yields = append(*yc.yields, yieldSig.Value)
 				continue // Continue executing
@@ -147,7 +151,7 @@ func (yc *YieldCollector) executeWhileLoop(stmt *ast.WhileStatement) (Value, err
 	for {
 		// Check yield limit
 		if yc.count > yc.maxYields {
-			return nil, &RuntimeError{Message: "generator yield limit exceeded"}
+			return nil, &RuntimeError{Message: i18n.T("runtime.yield_limit")}
 		}
 
 		condition, err := yc.interp.evalExpression(stmt.Condition)
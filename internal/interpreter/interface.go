@@ -0,0 +1,121 @@
+package interpreter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+)
+
+// Interface represents a runtime interface (a named set of method names)
+type Interface struct {
+	Name    string
+	Methods map[string]int // method name -> parameter count
+	Extends []*Interface
+}
+
+func (it *Interface) Kind() ValueKind { return InterfaceValue }
+func (it *Interface) String() string  { return fmt.Sprintf("<interface %s>", it.Name) }
+func (it *Interface) IsTruthy() bool  { return true }
+
+// AllMethods returns the required methods including parent interfaces
+func (it *Interface) AllMethods() map[string]int {
+	methods := make(map[string]int)
+	for _, parent := range it.Extends {
+		for name, arity := range parent.AllMethods() {
+			methods[name] = arity
+		}
+	}
+	for name, arity := range it.Methods {
+		methods[name] = arity
+	}
+	return methods
+}
+
+// MissingMethods returns the sorted names of methods the class does not provide
+func (it *Interface) MissingMethods(class *Class) []string {
+	var missing []string
+	for name, arity := range it.AllMethods() {
+		method, ok := findClassMethod(class, name)
+		if !ok || len(method.Parameters) != arity {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// findClassMethod looks up a method on the class and its superclasses
+func findClassMethod(class *Class, name string) (*Function, bool) {
+	if method, ok := class.Methods[name]; ok {
+		return method, true
+	}
+	for _, superClass := range class.SuperClasses {
+		if method, ok := findClassMethod(superClass, name); ok {
+			return method, true
+		}
+	}
+	return nil, false
+}
+
+// isSubclassOf reports whether class is target or inherits from it
+func isSubclassOf(class, target *Class) bool {
+	if class == target || class.Name == target.Name {
+		return true
+	}
+	for _, superClass := range class.SuperClasses {
+		if isSubclassOf(superClass, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// evalInterfaceStatement evaluates an interface declaration
+func (i *Interpreter) evalInterfaceStatement(stmt *ast.InterfaceStatement) (Value, error) {
+	iface := &Interface{
+		Name:    stmt.Name.Value,
+		Methods: make(map[string]int),
+	}
+
+	for _, parentIdent := range stmt.Extends {
+		parentVal, ok := i.env.Get(parentIdent.Value)
+		if !ok {
+			return nil, &RuntimeError{Message: i18n.T("runtime.undefined_interface", parentIdent.Value)}
+		}
+		parent, ok := parentVal.(*Interface)
+		if !ok {
+			return nil, &RuntimeError{Message: i18n.T("runtime.not_interface", parentIdent.Value)}
+		}
+		iface.Extends = append(iface.Extends, parent)
+	}
+
+	for _, method := range stmt.Methods {
+		iface.Methods[method.Name.Value] = len(method.Parameters)
+	}
+
+	i.env.Set(iface.Name, iface)
+	return nil, nil
+}
+
+// checkClassInterfaces verifies that a class provides every method of its declared interfaces
+func (i *Interpreter) checkClassInterfaces(class *Class, idents []*ast.Identifier) error {
+	for _, ident := range idents {
+		val, ok := i.env.Get(ident.Value)
+		if !ok {
+			return &RuntimeError{Message: i18n.T("runtime.undefined_interface", ident.Value)}
+		}
+		iface, ok := val.(*Interface)
+		if !ok {
+			return &RuntimeError{Message: i18n.T("runtime.not_interface", ident.Value)}
+		}
+		if missing := iface.MissingMethods(class); len(missing) > 0 {
+			return &RuntimeError{Message: i18n.T("runtime.not_implemented",
+				class.Name, iface.Name, strings.Join(missing, ", "))}
+		}
+		class.Interfaces = append(class.Interfaces, iface)
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"io/fs"
 	"math"
 	"net/http"
 	"os"
@@ -16,8 +17,11 @@ import (
 	"time"
 
 	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 	"github.com/mburakmmm/sky-lang/internal/lexer"
 	"github.com/mburakmmm/sky-lang/internal/parser"
+	"github.com/mburakmmm/sky-lang/internal/sema"
 )
 
 // Interpreter AST'yi yorumlar ve çalıştırır
@@ -28,7 +32,13 @@ type Interpreter struct {
 	moduleCache    map[string]*Environment // Cached loaded modules
 	currentDir     string                  // Current working directory for relative imports
 	sourceFile     string                  // Source file path for relative imports
+	moduleFS       fs.FS                   // Modules by import path, instead of the file system
 	recursionDepth int                     // Track recursion depth
+	tiering        *tiering                // Tiered execution, nil when off
+	hot            *hotFunction            // Function being interpreted, for loop counts
+	typeChecks     bool                    // Check values against annotations (see typecheck.go)
+	typeCache      map[ast.TypeAnnotation]*RuntimeType
+	tasks          *scheduler // Async calls (see async.go), nil before the first
 }
 
 // New yeni bir interpreter oluşturur
@@ -121,6 +131,9 @@ func New() *Interpreter {
 	// GLOBAL FUNCTIONS
 	addGlobalFunctions(env)
 
+	// ASYNC VARIANTS of blocking builtins
+	addAsyncBuiltins(env)
+
 	return &Interpreter{
 		env:         env,
 		output:      os.Stdout,
@@ -135,6 +148,12 @@ func (i *Interpreter) SetSourceFile(path string) {
 	i.sourceFile = path
 }
 
+// SetModuleFS makes imports read path/to/module.sky from fsys instead of
+// searching the file system, for programs bundled with their modules
+func (i *Interpreter) SetModuleFS(fsys fs.FS) {
+	i.moduleFS = fsys
+}
+
 // Eval programı çalıştırır
 func (i *Interpreter) Eval(program *ast.Program) error {
 	// main fonksiyonunu ara
@@ -170,19 +189,23 @@ func (i *Interpreter) Eval(program *ast.Program) error {
 
 			// If main is async, it returns a Promise - await it
 			if fn.Async {
-				promise := NewPromise(func() (Value, error) {
+				promise := i.startAsync(func() (Value, error) {
 					return fn.Body(newEnv)
 				})
-				_, err := promise.Await()
+				if _, err := i.await(promise); err != nil {
+					return err
+				}
+			} else if _, err := fn.Body(newEnv); err != nil {
+				// Synchronous main
 				return err
 			}
-
-			// Synchronous main
-			_, err := fn.Body(newEnv)
-			return err
 		}
 	}
 
+	// Async calls nobody awaited still run to the end
+	if i.tasks != nil {
+		return i.runUntil(nil)
+	}
 	return nil
 }
 
@@ -224,6 +247,8 @@ func (i *Interpreter) evalStatement(stmt ast.Statement) (Value, error) {
 		return i.evalUnsafeStatement(s)
 	case *ast.EnumStatement:
 		return i.evalEnumStatement(s)
+	case *ast.InterfaceStatement:
+		return i.evalInterfaceStatement(s)
 	case *ast.BlockStatement:
 		return i.evalBlockStatement(s, i.env)
 	case *ast.TryStatement:
@@ -231,7 +256,7 @@ func (i *Interpreter) evalStatement(stmt ast.Statement) (Value, error) {
 	case *ast.ThrowStatement:
 		return i.evalThrowStatement(s)
 	default:
-		return nil, &RuntimeError{Message: fmt.Sprintf("unknown statement type: %T", stmt)}
+		return nil, &RuntimeError{Message: i18n.T("runtime.unknown_statement", stmt)}
 	}
 }
 
@@ -240,6 +265,11 @@ func (i *Interpreter) evalLetStatement(stmt *ast.LetStatement) (Value, error) {
 	if err != nil {
 		return nil, err
 	}
+	if i.typeChecks && stmt.Type != nil {
+		if t := i.runtimeType(stmt.Type); !t.Matches(value, i.env.Get) {
+			return nil, LetError(stmt.Name.Value, t, value)
+		}
+	}
 	i.env.Set(stmt.Name.Value, value)
 	return value, nil
 }
@@ -276,12 +306,19 @@ func (i *Interpreter) evalFunctionStatement(stmt *ast.FunctionStatement) error {
 
 	// Fonksiyonu closure olarak sakla
 	capturedEnv := i.env
+	hot := i.newHotFunction(stmt, capturedEnv)
 	fn := &Function{
 		Name:       funcName,
 		Parameters: params,
 		Env:        capturedEnv,
 		Async:      stmt.Async, // Store async flag
 		Body: func(callEnv *Environment) (Value, error) {
+			// Arguments are checked before the cache, whose keys do not
+			// tell 2 and "2" apart
+			if err := i.checkArguments(funcName, capturedStmt.Parameters, capturedEnv, callEnv); err != nil {
+				return nil, err
+			}
+
 			// Check memoization cache first
 			args, _ := callEnv.Get("__args__")
 			if argList, ok := args.(*List); ok {
@@ -290,6 +327,14 @@ func (i *Interpreter) evalFunctionStatement(stmt *ast.FunctionStatement) error {
 				}
 			}
 
+			// Hot functions run on the tier they were promoted to
+			if hot != nil {
+				i.record(hot)
+				if hot.code != nil {
+					return i.callTiered(hot, args)
+				}
+			}
+
 			// Yeni environment oluştur
 			// callEnv'i parent olarak kullan (parametreler ve self için)
 			fnEnv := NewEnvironment(callEnv)
@@ -332,7 +377,7 @@ func (i *Interpreter) evalFunctionStatement(stmt *ast.FunctionStatement) error {
 
 			// Check recursion depth
 			if i.recursionDepth >= 1000 {
-				return nil, &RuntimeError{Message: fmt.Sprintf("maximum recursion depth exceeded (1000) in function '%s'", funcName)}
+				return nil, &RuntimeError{Code: diag.RecursionLimit, Message: i18n.T("runtime.recursion_depth", funcName)}
 			}
 			i.recursionDepth++
 			defer func() {
@@ -340,9 +385,9 @@ func (i *Interpreter) evalFunctionStatement(stmt *ast.FunctionStatement) error {
 			}()
 
 			// Fonksiyon body'sini çalıştır
-			oldEnv := i.env
-			i.env = fnEnv
-			defer func() { i.env = oldEnv }()
+			oldEnv, oldHot := i.env, i.hot
+			i.env, i.hot = fnEnv, hot
+			defer func() { i.env, i.hot = oldEnv, oldHot }()
 
 			result, err := i.evalBlockStatement(capturedStmt.Body, fnEnv)
 
@@ -351,6 +396,9 @@ func (i *Interpreter) evalFunctionStatement(stmt *ast.FunctionStatement) error {
 				result = retSignal.Value
 				err = nil
 			}
+			if err == nil {
+				err = i.checkReturn(funcName, capturedStmt.ReturnType, capturedStmt.Async, result, fnEnv)
+			}
 
 			// Cache successful results
 			if err == nil && result != nil {
@@ -371,12 +419,12 @@ func (i *Interpreter) evalFunctionStatement(stmt *ast.FunctionStatement) error {
 		// Get decorator function
 		decoratorVal, ok := i.env.Get(decorator.Name.Value)
 		if !ok {
-			return &RuntimeError{Message: fmt.Sprintf("undefined decorator: %s", decorator.Name.Value)}
+			return &RuntimeError{Message: i18n.T("runtime.undefined_decorator", decorator.Name.Value)}
 		}
 
 		decoratorFn, ok := decoratorVal.(*Function)
 		if !ok {
-			return &RuntimeError{Message: fmt.Sprintf("%s is not a decorator function", decorator.Name.Value)}
+			return &RuntimeError{Message: i18n.T("runtime.not_decorator", decorator.Name.Value)}
 		}
 
 		// Call decorator with function as argument
@@ -404,7 +452,7 @@ func (i *Interpreter) evalFunctionStatement(stmt *ast.FunctionStatement) error {
 		if resultFn, ok := result.(*Function); ok {
 			decoratedFn = resultFn
 		} else {
-			return &RuntimeError{Message: fmt.Sprintf("decorator %s did not return a function", decorator.Name.Value)}
+			return &RuntimeError{Message: i18n.T("runtime.decorator_result", decorator.Name.Value)}
 		}
 	}
 
@@ -437,6 +485,9 @@ func (i *Interpreter) evalFunctionStatement(stmt *ast.FunctionStatement) error {
 						}
 					}
 				}
+				if err := i.checkArguments(funcName, capturedStmt.Parameters, genEnv, callEnv); err != nil {
+					return nil, err
+				}
 
 				// Create generator by executing and collecting yields
 				gen, err := NewGenerator(i, genEnv, capturedStmt.Body)
@@ -532,6 +583,7 @@ func (i *Interpreter) evalWhileStatement(stmt *ast.WhileStatement) (Value, error
 			break
 		}
 
+		i.backEdge()
 		_, err = i.evalBlockStatement(stmt.Body, i.env)
 		if err != nil {
 			// Handle break/continue signals
@@ -573,6 +625,7 @@ func (i *Interpreter) evalForStatement(stmt *ast.ForStatement) (Value, error) {
 		// Iterate over list elements
 		for _, elem := range iter.Elements {
 			i.env.Set(stmt.Iterator.Value, elem)
+			i.backEdge()
 			_, err := i.evalBlockStatement(stmt.Body, i.env)
 			if err != nil {
 				if _, isBreak := err.(*BreakSignal); isBreak {
@@ -595,6 +648,7 @@ func (i *Interpreter) evalForStatement(stmt *ast.ForStatement) (Value, error) {
 		// Iterate over dict keys
 		for key := range iter.Pairs {
 			i.env.Set(stmt.Iterator.Value, &String{Value: key})
+			i.backEdge()
 			_, err := i.evalBlockStatement(stmt.Body, i.env)
 			if err != nil {
 				if _, isBreak := err.(*BreakSignal); isBreak {
@@ -617,6 +671,7 @@ func (i *Interpreter) evalForStatement(stmt *ast.ForStatement) (Value, error) {
 		// Iterate over string characters
 		for _, ch := range iter.Value {
 			i.env.Set(stmt.Iterator.Value, &String{Value: string(ch)})
+			i.backEdge()
 			_, err := i.evalBlockStatement(stmt.Body, i.env)
 			if err != nil {
 				if _, isBreak := err.(*BreakSignal); isBreak {
@@ -666,6 +721,7 @@ func (i *Interpreter) evalForStatement(stmt *ast.ForStatement) (Value, error) {
 								}
 
 								i.env.Set(stmt.Iterator.Value, value)
+								i.backEdge()
 								_, err = i.evalBlockStatement(stmt.Body, i.env)
 								if err != nil {
 									if _, isBreak := err.(*BreakSignal); isBreak {
@@ -684,11 +740,11 @@ func (i *Interpreter) evalForStatement(stmt *ast.ForStatement) (Value, error) {
 				}
 			}
 		} else {
-			return nil, &RuntimeError{Message: fmt.Sprintf("%T is not iterable", iterable)}
+			return nil, &RuntimeError{Code: diag.NotIterable, Message: i18n.T("runtime.not_iterable", iterable)}
 		}
 
 	default:
-		return nil, &RuntimeError{Message: fmt.Sprintf("%T is not iterable", iterable)}
+		return nil, &RuntimeError{Code: diag.NotIterable, Message: i18n.T("runtime.not_iterable", iterable)}
 	}
 
 	return &Nil{}, nil
@@ -749,7 +805,7 @@ func (i *Interpreter) evalExpression(expr ast.Expression) (Value, error) {
 				}
 				env = env.parent
 			}
-			return nil, &RuntimeError{Message: "undefined: self"}
+			return nil, &RuntimeError{Code: diag.UndefinedName, Message: i18n.T("runtime.undefined_self")}
 		}
 
 		// Special handling for 'super'
@@ -762,12 +818,12 @@ func (i *Interpreter) evalExpression(expr ast.Expression) (Value, error) {
 				}
 				env = env.parent
 			}
-			return nil, &RuntimeError{Message: "undefined: super"}
+			return nil, &RuntimeError{Code: diag.UndefinedName, Message: i18n.T("runtime.undefined_super")}
 		}
 
 		val, ok := i.env.Get(e.Value)
 		if !ok {
-			return nil, &RuntimeError{Message: fmt.Sprintf("undefined: %s", e.Value)}
+			return nil, &RuntimeError{Code: diag.UndefinedName, Message: i18n.T("runtime.undefined", e.Value)}
 		}
 		return val, nil
 
@@ -827,7 +883,7 @@ func (i *Interpreter) evalExpression(expr ast.Expression) (Value, error) {
 		return i.evalLambdaExpression(e)
 
 	default:
-		return nil, &RuntimeError{Message: fmt.Sprintf("unknown expression type: %T", expr)}
+		return nil, &RuntimeError{Message: i18n.T("runtime.unknown_expression", expr)}
 	}
 }
 
@@ -858,6 +914,9 @@ func (i *Interpreter) evalLambdaExpression(expr *ast.LambdaExpression) (Value, e
 					}
 				}
 			}
+			if err := i.checkArguments("lambda", expr.Parameters, fnEnv, callEnv); err != nil {
+				return nil, err
+			}
 
 			// Execute lambda body
 			oldEnv := i.env
@@ -867,9 +926,13 @@ func (i *Interpreter) evalLambdaExpression(expr *ast.LambdaExpression) (Value, e
 			result, err := i.evalBlockStatement(expr.Body, fnEnv)
 			if err != nil {
 				// Handle return signal
-				if returnSignal, isReturn := err.(*ReturnSignal); isReturn {
-					return returnSignal.Value, nil
+				returnSignal, isReturn := err.(*ReturnSignal)
+				if !isReturn {
+					return nil, err
 				}
+				result = returnSignal.Value
+			}
+			if err := i.checkReturn("lambda", expr.ReturnType, false, result, fnEnv); err != nil {
 				return nil, err
 			}
 			return result, nil
@@ -893,11 +956,11 @@ func (i *Interpreter) evalPrefixExpression(expr *ast.PrefixExpression) (Value, e
 		if floatVal, ok := right.(*Float); ok {
 			return &Float{Value: -floatVal.Value}, nil
 		}
-		return nil, &RuntimeError{Message: "operator - can only be applied to numbers"}
+		return nil, &RuntimeError{Message: i18n.T("runtime.negate")}
 	case "+":
 		return right, nil
 	default:
-		return nil, &RuntimeError{Message: fmt.Sprintf("unknown prefix operator: %s", expr.Operator)}
+		return nil, &RuntimeError{Message: i18n.T("runtime.unknown_prefix", expr.Operator)}
 	}
 }
 
@@ -916,7 +979,7 @@ func (i *Interpreter) evalInfixExpression(expr *ast.InfixExpression) (Value, err
 				if selfVal, found := i.env.Get("self"); found {
 					object = selfVal
 				} else {
-					return nil, &RuntimeError{Message: "undefined: self"}
+					return nil, &RuntimeError{Code: diag.UndefinedName, Message: i18n.T("runtime.undefined_self")}
 				}
 			} else {
 				object, err = i.evalExpression(memberExpr.Object)
@@ -937,7 +1000,7 @@ func (i *Interpreter) evalInfixExpression(expr *ast.InfixExpression) (Value, err
 					// Compound assignment
 					leftVal, found := instance.Get(memberName)
 					if !found {
-						return nil, &RuntimeError{Message: fmt.Sprintf("undefined property: %s", memberName)}
+						return nil, &RuntimeError{Code: diag.UndefinedMember, Message: i18n.T("runtime.undefined_property", memberName)}
 					}
 
 					var result Value
@@ -964,7 +1027,7 @@ func (i *Interpreter) evalInfixExpression(expr *ast.InfixExpression) (Value, err
 				return rightVal, nil
 			}
 
-			return nil, &RuntimeError{Message: "can only assign to instance members"}
+			return nil, &RuntimeError{Message: i18n.T("runtime.assign_member")}
 		}
 
 		// Handle identifier assignment
@@ -978,7 +1041,7 @@ func (i *Interpreter) evalInfixExpression(expr *ast.InfixExpression) (Value, err
 				// Compound assignment
 				leftVal, ok := i.env.Get(ident.Value)
 				if !ok {
-					return nil, &RuntimeError{Message: fmt.Sprintf("undefined: %s", ident.Value)}
+					return nil, &RuntimeError{Code: diag.UndefinedName, Message: i18n.T("runtime.undefined", ident.Value)}
 				}
 
 				// Operasyonu yap
@@ -1010,7 +1073,7 @@ func (i *Interpreter) evalInfixExpression(expr *ast.InfixExpression) (Value, err
 			return rightVal, nil
 		}
 
-		return nil, &RuntimeError{Message: "left side of assignment must be an identifier"}
+		return nil, &RuntimeError{Message: i18n.T("runtime.assign_target")}
 	}
 
 	// Diğer operatörler
@@ -1085,12 +1148,13 @@ func (i *Interpreter) evalBinaryOp(left, right Value, op string) (Value, error)
 				return &Integer{Value: intL.Value - intR.Value}, nil
 			case "*":
 				return &Integer{Value: intL.Value * intR.Value}, nil
-			case "/":
+			case "/", "%":
 				if intR.Value == 0 {
-					return nil, &RuntimeError{Message: "division by zero"}
+					return nil, &RuntimeError{Code: diag.DivisionByZero, Message: i18n.T("runtime.division_by_zero")}
+				}
+				if op == "/" {
+					return &Integer{Value: intL.Value / intR.Value}, nil
 				}
-				return &Integer{Value: intL.Value / intR.Value}, nil
-			case "%":
 				return &Integer{Value: intL.Value % intR.Value}, nil
 			case "==":
 				return &Boolean{Value: intL.Value == intR.Value}, nil
@@ -1174,7 +1238,7 @@ func (i *Interpreter) evalBinaryOp(left, right Value, op string) (Value, error)
 		return &Boolean{Value: left.IsTruthy() || right.IsTruthy()}, nil
 	}
 
-	return nil, &RuntimeError{Message: fmt.Sprintf("unsupported operation: %T %s %T", left, op, right)}
+	return nil, &RuntimeError{Message: i18n.T("runtime.unsupported_operation", left, op, right)}
 }
 
 func (i *Interpreter) evalCallExpression(expr *ast.CallExpression) (Value, error) {
@@ -1210,13 +1274,14 @@ func (i *Interpreter) evalCallExpression(expr *ast.CallExpression) (Value, error
 					// Create call environment
 					callEnv := NewEnvironment(method.Env)
 					callEnv.Set("__args__", &List{Elements: args})
+					i.withCallSite(callEnv, expr.Token)
 
 					// Call method (self is already bound in Instance.Get())
 					return method.Body(callEnv)
 				}
 			}
 
-			return nil, &RuntimeError{Message: fmt.Sprintf("undefined method: %s", methodName)}
+			return nil, &RuntimeError{Code: diag.UndefinedMember, Message: i18n.T("runtime.undefined_method", methodName)}
 		}
 
 		// Handle super.method() calls
@@ -1238,13 +1303,14 @@ func (i *Interpreter) evalCallExpression(expr *ast.CallExpression) (Value, error
 				// Get current self from environment
 				selfVal, found := i.env.Get("self")
 				if !found {
-					return nil, &RuntimeError{Message: "undefined: self"}
+					return nil, &RuntimeError{Code: diag.UndefinedName, Message: i18n.T("runtime.undefined_self")}
 				}
 
 				// Create call environment with self bound
 				callEnv := NewEnvironment(method.Env)
 				callEnv.Set("__args__", &List{Elements: args})
 				callEnv.Set("self", selfVal)
+				i.withCallSite(callEnv, expr.Token)
 
 				// Set super to parent class if exists
 				if len(superClass.SuperClasses) > 0 {
@@ -1255,7 +1321,7 @@ func (i *Interpreter) evalCallExpression(expr *ast.CallExpression) (Value, error
 				return method.Body(callEnv)
 			}
 
-			return nil, &RuntimeError{Message: fmt.Sprintf("undefined method: %s", methodName)}
+			return nil, &RuntimeError{Code: diag.UndefinedMember, Message: i18n.T("runtime.undefined_method", methodName)}
 		}
 	}
 
@@ -1297,6 +1363,7 @@ func (i *Interpreter) evalCallExpression(expr *ast.CallExpression) (Value, error
 			callEnv := NewEnvironment(constructor.Env)
 			callEnv.Set("__args__", &List{Elements: args})
 			callEnv.Set("self", instance)
+			i.withCallSite(callEnv, expr.Token)
 
 			// Set super to parent class if exists
 			if len(class.SuperClasses) > 0 {
@@ -1315,7 +1382,7 @@ func (i *Interpreter) evalCallExpression(expr *ast.CallExpression) (Value, error
 	// Regular function call
 	fn, ok := function.(*Function)
 	if !ok {
-		return nil, &RuntimeError{Message: fmt.Sprintf("%s is not a function or class", expr.Function.String())}
+		return nil, &RuntimeError{Code: diag.NotCallable, Message: i18n.T("runtime.not_callable", expr.Function.String())}
 	}
 
 	// Argümanları değerlendir
@@ -1330,16 +1397,22 @@ func (i *Interpreter) evalCallExpression(expr *ast.CallExpression) (Value, error
 
 	// If async function, return a Promise
 	if fn.Async {
-		return NewPromise(func() (Value, error) {
-			callEnv := NewEnvironment(fn.Env)
-			callEnv.Set("__args__", &List{Elements: args})
+		callEnv := NewEnvironment(fn.Env)
+		callEnv.Set("__args__", &List{Elements: args})
+		i.withCallSite(callEnv, expr.Token)
+		body := func() (Value, error) {
 			return fn.Body(callEnv)
-		}), nil
+		}
+		if fn.Native {
+			return i.spawn(body), nil
+		}
+		return i.startAsync(body), nil
 	}
 
 	// Synchronous function: execute immediately
 	callEnv := NewEnvironment(fn.Env)
 	callEnv.Set("__args__", &List{Elements: args})
+	i.withCallSite(callEnv, expr.Token)
 
 	return fn.Body(callEnv)
 }
@@ -1358,7 +1431,7 @@ func (i *Interpreter) evalIndexExpression(expr *ast.IndexExpression) (Value, err
 	if list, ok := left.(*List); ok {
 		if intIdx, ok := index.(*Integer); ok {
 			if intIdx.Value < 0 || intIdx.Value >= int64(len(list.Elements)) {
-				return nil, &RuntimeError{Message: "list index out of range"}
+				return nil, &RuntimeError{Code: diag.IndexOutOfRange, Message: i18n.T("runtime.index_out_of_range")}
 			}
 			return list.Elements[intIdx.Value], nil
 		}
@@ -1372,7 +1445,7 @@ func (i *Interpreter) evalIndexExpression(expr *ast.IndexExpression) (Value, err
 		return &Nil{}, nil
 	}
 
-	return nil, &RuntimeError{Message: "index operation not supported"}
+	return nil, &RuntimeError{Message: i18n.T("runtime.index_unsupported")}
 }
 
 // evalAwaitExpression waits for a promise to resolve and returns its value
@@ -1384,7 +1457,7 @@ func (i *Interpreter) evalAwaitExpression(expr *ast.AwaitExpression) (Value, err
 
 	// If it's a promise, await it
 	if promise, ok := value.(*Promise); ok {
-		return promise.Await()
+		return i.await(promise)
 	}
 
 	// If not a promise, return as-is (await on non-promise is identity)
@@ -1415,7 +1488,7 @@ func (i *Interpreter) evalClassStatement(stmt *ast.ClassStatement) error {
 	for _, superClassIdent := range stmt.SuperClasses {
 		superVal, ok := i.env.Get(superClassIdent.Value)
 		if !ok {
-			return &RuntimeError{Message: fmt.Sprintf("undefined superclass: %s", superClassIdent.Value)}
+			return &RuntimeError{Message: i18n.T("runtime.undefined_superclass", superClassIdent.Value)}
 		}
 		// Check if it's a regular class or abstract class
 		if superClass, okClass := superVal.(*Class); okClass {
@@ -1430,7 +1503,7 @@ func (i *Interpreter) evalClassStatement(stmt *ast.ClassStatement) error {
 			}
 			superClasses = append(superClasses, convertedClass)
 		} else {
-			return &RuntimeError{Message: fmt.Sprintf("%s is not a class", superClassIdent.Value)}
+			return &RuntimeError{Message: i18n.T("runtime.not_a_class", superClassIdent.Value)}
 		}
 	}
 
@@ -1483,6 +1556,9 @@ func (i *Interpreter) evalClassStatement(stmt *ast.ClassStatement) error {
 							}
 						}
 					}
+					if err := i.checkArguments(className+"."+funcName, capturedStmt.Parameters, fnEnv, callEnv); err != nil {
+						return nil, err
+					}
 
 					// Copy self and super if they exist
 					if self, ok := callEnv.Get("self"); ok {
@@ -1499,9 +1575,13 @@ func (i *Interpreter) evalClassStatement(stmt *ast.ClassStatement) error {
 					result, err := i.evalBlockStatement(capturedStmt.Body, fnEnv)
 					if err != nil {
 						// Handle return signal
-						if returnSignal, isReturn := err.(*ReturnSignal); isReturn {
-							return returnSignal.Value, nil
+						returnSignal, isReturn := err.(*ReturnSignal)
+						if !isReturn {
+							return nil, err
 						}
+						result = returnSignal.Value
+					}
+					if err := i.checkReturn(className+"."+funcName, capturedStmt.ReturnType, capturedStmt.Async, result, fnEnv); err != nil {
 						return nil, err
 					}
 					return result, nil
@@ -1512,12 +1592,18 @@ func (i *Interpreter) evalClassStatement(stmt *ast.ClassStatement) error {
 
 		default:
 			// For now, only methods are supported in class body
-			return &RuntimeError{Message: fmt.Sprintf("unsupported class member: %T", member)}
+			return &RuntimeError{Message: i18n.T("runtime.unsupported_member", member)}
 		}
 	}
 
 	// Register class in environment
 	i.env = oldEnv
+
+	// Verify declared interfaces
+	if err := i.checkClassInterfaces(class, stmt.Interfaces); err != nil {
+		return err
+	}
+
 	i.env.Set(className, class)
 
 	return nil
@@ -1540,14 +1626,14 @@ func (i *Interpreter) evalMemberExpression(expr *ast.MemberExpression) (Value, e
 			if selfVal, found := i.env.Get("self"); found {
 				object = selfVal
 			} else {
-				return nil, &RuntimeError{Message: "undefined: self"}
+				return nil, &RuntimeError{Code: diag.UndefinedName, Message: i18n.T("runtime.undefined_self")}
 			}
 		} else if ident.Value == "super" {
 			// Get 'super' directly from environment
 			if superVal, found := i.env.Get("super"); found {
 				object = superVal
 			} else {
-				return nil, &RuntimeError{Message: "undefined: super"}
+				return nil, &RuntimeError{Code: diag.UndefinedName, Message: i18n.T("runtime.undefined_super")}
 			}
 		} else {
 			object, err = i.evalExpression(expr.Object)
@@ -1569,7 +1655,7 @@ func (i *Interpreter) evalMemberExpression(expr *ast.MemberExpression) (Value, e
 		if value, found := instance.Get(memberName); found {
 			return value, nil
 		}
-		return nil, &RuntimeError{Message: fmt.Sprintf("undefined property: %s", memberName)}
+		return nil, &RuntimeError{Code: diag.UndefinedMember, Message: i18n.T("runtime.undefined_property", memberName)}
 	}
 
 	// Handle class member access (for super.method())
@@ -1577,7 +1663,7 @@ func (i *Interpreter) evalMemberExpression(expr *ast.MemberExpression) (Value, e
 		if method, found := class.Methods[memberName]; found {
 			return method, nil
 		}
-		return nil, &RuntimeError{Message: fmt.Sprintf("undefined method: %s", memberName)}
+		return nil, &RuntimeError{Code: diag.UndefinedMember, Message: i18n.T("runtime.undefined_method", memberName)}
 	}
 
 	// Handle dict member access (for backwards compatibility)
@@ -1700,7 +1786,7 @@ func (i *Interpreter) evalMemberExpression(expr *ast.MemberExpression) (Value, e
 		if value, found := class.Env.Get(memberName); found {
 			return value, nil
 		}
-		return nil, &RuntimeError{Message: fmt.Sprintf("undefined method: %s", memberName)}
+		return nil, &RuntimeError{Code: diag.UndefinedMember, Message: i18n.T("runtime.undefined_method", memberName)}
 	}
 
 	// Handle AbstractClass method access
@@ -1712,10 +1798,10 @@ func (i *Interpreter) evalMemberExpression(expr *ast.MemberExpression) (Value, e
 		if value, found := abstractClass.Env.Get(memberName); found {
 			return value, nil
 		}
-		return nil, &RuntimeError{Message: fmt.Sprintf("undefined method: %s", memberName)}
+		return nil, &RuntimeError{Code: diag.UndefinedMember, Message: i18n.T("runtime.undefined_method", memberName)}
 	}
 
-	return nil, &RuntimeError{Message: fmt.Sprintf("cannot access member of %T", object)}
+	return nil, &RuntimeError{Message: i18n.T("runtime.member_access", object)}
 }
 
 // addTypeConversionFunctions adds int(), float(), bool() converters
@@ -1742,7 +1828,7 @@ func addTypeConversionFunctions(env *Environment) {
 					}
 					val, err := strconv.ParseInt(v.Value, base, 64)
 					if err != nil {
-						return &Nil{}, &RuntimeError{Message: fmt.Sprintf("invalid literal for int(): %s", v.Value)}
+						return &Nil{}, &RuntimeError{Message: i18n.T("runtime.invalid_int", v.Value)}
 					}
 					return &Integer{Value: val}, nil
 				case *Boolean:
@@ -1774,7 +1860,7 @@ func addTypeConversionFunctions(env *Environment) {
 				case *String:
 					val, err := strconv.ParseFloat(v.Value, 64)
 					if err != nil {
-						return &Nil{}, &RuntimeError{Message: fmt.Sprintf("invalid literal for float(): %s", v.Value)}
+						return &Nil{}, &RuntimeError{Message: i18n.T("runtime.invalid_float", v.Value)}
 					}
 					return &Float{Value: val}, nil
 				case *Boolean:
@@ -1904,10 +1990,10 @@ func addNumericFunctions(env *Environment) {
 				case *Float:
 					return &Float{Value: math.Abs(v.Value)}, nil
 				default:
-					return &Nil{}, &RuntimeError{Message: "abs() requires numeric argument"}
+					return &Nil{}, &RuntimeError{Message: i18n.T("runtime.abs_numeric")}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "abs() requires argument"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.abs_argument")}
 		},
 	})
 
@@ -1937,7 +2023,7 @@ func addNumericFunctions(env *Environment) {
 				}
 				return minVal, nil
 			}
-			return &Nil{}, &RuntimeError{Message: "min() requires at least one argument"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.min_argument")}
 		},
 	})
 
@@ -1967,7 +2053,7 @@ func addNumericFunctions(env *Environment) {
 				}
 				return maxVal, nil
 			}
-			return &Nil{}, &RuntimeError{Message: "max() requires at least one argument"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.max_argument")}
 		},
 	})
 
@@ -1993,7 +2079,7 @@ func addNumericFunctions(env *Environment) {
 					return i, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "round() requires numeric argument"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.round_numeric")}
 		},
 	})
 
@@ -2011,7 +2097,7 @@ func addNumericFunctions(env *Environment) {
 				case *Float:
 					x = v.Value
 				default:
-					return &Nil{}, &RuntimeError{Message: "pow() requires numeric arguments"}
+					return &Nil{}, &RuntimeError{Message: i18n.T("runtime.pow_numeric")}
 				}
 
 				switch v := list.Elements[1].(type) {
@@ -2020,7 +2106,7 @@ func addNumericFunctions(env *Environment) {
 				case *Float:
 					y = v.Value
 				default:
-					return &Nil{}, &RuntimeError{Message: "pow() requires numeric arguments"}
+					return &Nil{}, &RuntimeError{Message: i18n.T("runtime.pow_numeric")}
 				}
 
 				result := math.Pow(x, y)
@@ -2036,7 +2122,7 @@ func addNumericFunctions(env *Environment) {
 
 				return &Float{Value: result}, nil
 			}
-			return &Nil{}, &RuntimeError{Message: "pow() requires two arguments"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.pow_arguments")}
 		},
 	})
 
@@ -2054,16 +2140,16 @@ func addNumericFunctions(env *Environment) {
 				case *Float:
 					x = v.Value
 				default:
-					return &Nil{}, &RuntimeError{Message: "sqrt() requires numeric argument"}
+					return &Nil{}, &RuntimeError{Message: i18n.T("runtime.sqrt_numeric")}
 				}
 
 				if x < 0 {
-					return &Nil{}, &RuntimeError{Message: "sqrt() of negative number"}
+					return &Nil{}, &RuntimeError{Message: i18n.T("runtime.sqrt_negative")}
 				}
 
 				return &Float{Value: math.Sqrt(x)}, nil
 			}
-			return &Nil{}, &RuntimeError{Message: "sqrt() requires argument"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.sqrt_argument")}
 		},
 	})
 
@@ -2080,7 +2166,7 @@ func addNumericFunctions(env *Environment) {
 					return i, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "floor() requires numeric argument"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.floor_numeric")}
 		},
 	})
 
@@ -2097,7 +2183,7 @@ func addNumericFunctions(env *Environment) {
 					return i, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "ceil() requires numeric argument"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.ceil_numeric")}
 		},
 	})
 
@@ -2195,6 +2281,8 @@ func addUtilityFunctions(env *Environment) {
 					return &String{Value: "function"}, nil
 				case *Class:
 					return &String{Value: "class"}, nil
+				case *Interface:
+					return &String{Value: "interface"}, nil
 				case *Instance:
 					return &String{Value: "instance"}, nil
 				case *Promise:
@@ -2216,6 +2304,21 @@ func addUtilityFunctions(env *Environment) {
 			args, _ := callEnv.Get("__args__")
 			if list, ok := args.(*List); ok && len(list.Elements) >= 2 {
 				obj := list.Elements[0]
+
+				// isinstance(obj, SomeClass) / isinstance(obj, SomeInterface)
+				switch target := list.Elements[1].(type) {
+				case *Class:
+					if inst, ok := obj.(*Instance); ok {
+						return &Boolean{Value: isSubclassOf(inst.Class, target)}, nil
+					}
+					return &Boolean{Value: false}, nil
+				case *Interface:
+					if inst, ok := obj.(*Instance); ok {
+						return &Boolean{Value: len(target.MissingMethods(inst.Class)) == 0}, nil
+					}
+					return &Boolean{Value: false}, nil
+				}
+
 				if typeName, ok := list.Elements[1].(*String); ok {
 					objType := ""
 
@@ -2276,7 +2379,7 @@ func addFunctionalFunctions(env *Environment) {
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "map() requires function and iterable"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.map_arguments")}
 		},
 	})
 
@@ -2310,7 +2413,7 @@ func addFunctionalFunctions(env *Environment) {
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "filter() requires function and iterable"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.filter_arguments")}
 		},
 	})
 
@@ -2365,7 +2468,7 @@ func addStringMethods(env *Environment) {
 					return &String{Value: strings.ToUpper(s.Value)}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "upper() requires string argument"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.upper_string")}
 		},
 	})
 
@@ -2379,7 +2482,7 @@ func addStringMethods(env *Environment) {
 					return &String{Value: strings.ToLower(s.Value)}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "lower() requires string argument"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.lower_string")}
 		},
 	})
 
@@ -2396,7 +2499,7 @@ func addStringMethods(env *Environment) {
 					return &String{Value: strings.ToUpper(s.Value[:1]) + strings.ToLower(s.Value[1:])}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "capitalize() requires string argument"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.capitalize_string")}
 		},
 	})
 
@@ -2421,7 +2524,7 @@ func addStringMethods(env *Environment) {
 					return &List{Elements: elements}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "split() requires string argument"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.split_string")}
 		},
 	})
 
@@ -2441,7 +2544,7 @@ func addStringMethods(env *Environment) {
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "join() requires string separator and list"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.join_arguments")}
 		},
 	})
 
@@ -2459,7 +2562,7 @@ func addStringMethods(env *Environment) {
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "replace() requires string, old, new"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.replace_arguments")}
 		},
 	})
 
@@ -2473,7 +2576,7 @@ func addStringMethods(env *Environment) {
 					return &String{Value: strings.TrimSpace(s.Value)}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "strip() requires string argument"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.strip_string")}
 		},
 	})
 
@@ -2489,7 +2592,7 @@ func addStringMethods(env *Environment) {
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "startswith() requires string and prefix"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.startswith_arguments")}
 		},
 	})
 
@@ -2505,7 +2608,7 @@ func addStringMethods(env *Environment) {
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "endswith() requires string and suffix"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.endswith_arguments")}
 		},
 	})
 
@@ -2562,7 +2665,7 @@ func addGlobalFunctions(env *Environment) {
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "join() requires string separator and list"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.join_arguments")}
 		},
 	})
 
@@ -2589,12 +2692,12 @@ func addGlobalFunctions(env *Environment) {
 				if filename, ok := list.Elements[0].(*String); ok {
 					content, err := os.ReadFile(filename.Value)
 					if err != nil {
-						return &Nil{}, &RuntimeError{Message: fmt.Sprintf("fs_read_text error: %v", err)}
+						return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_error", "fs_read_text", err)}
 					}
 					return &String{Value: string(content)}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "fs_read_text() requires filename string"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.fs_read_text_arguments")}
 		},
 	})
 
@@ -2607,13 +2710,13 @@ func addGlobalFunctions(env *Environment) {
 					if content, ok := list.Elements[1].(*String); ok {
 						err := os.WriteFile(filename.Value, []byte(content.Value), 0644)
 						if err != nil {
-							return &Nil{}, &RuntimeError{Message: fmt.Sprintf("fs_write_text error: %v", err)}
+							return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_error", "fs_write_text", err)}
 						}
 						return &Boolean{Value: true}, nil
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "fs_write_text() requires filename and content strings"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.fs_write_text_arguments")}
 		},
 	})
 
@@ -2627,7 +2730,7 @@ func addGlobalFunctions(env *Environment) {
 					return &Boolean{Value: err == nil}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "fs_exists() requires filename string"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.fs_exists_arguments")}
 		},
 	})
 
@@ -2639,12 +2742,12 @@ func addGlobalFunctions(env *Environment) {
 				if dirname, ok := list.Elements[0].(*String); ok {
 					err := os.MkdirAll(dirname.Value, 0755)
 					if err != nil {
-						return &Nil{}, &RuntimeError{Message: fmt.Sprintf("fs_mkdir error: %v", err)}
+						return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_error", "fs_mkdir", err)}
 					}
 					return &Boolean{Value: true}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "fs_mkdir() requires directory name string"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.fs_mkdir_arguments")}
 		},
 	})
 
@@ -2656,7 +2759,7 @@ func addGlobalFunctions(env *Environment) {
 				if dirname, ok := list.Elements[0].(*String); ok {
 					entries, err := os.ReadDir(dirname.Value)
 					if err != nil {
-						return &Nil{}, &RuntimeError{Message: fmt.Sprintf("fs_list_dir error: %v", err)}
+						return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_error", "fs_list_dir", err)}
 					}
 
 					files := make([]Value, len(entries))
@@ -2666,7 +2769,7 @@ func addGlobalFunctions(env *Environment) {
 					return &List{Elements: files}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "fs_list_dir() requires directory name string"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.fs_list_dir_arguments")}
 		},
 	})
 
@@ -2683,7 +2786,7 @@ func addGlobalFunctions(env *Environment) {
 		Body: func(callEnv *Environment) (Value, error) {
 			dir, err := os.Getwd()
 			if err != nil {
-				return &Nil{}, &RuntimeError{Message: fmt.Sprintf("os_getcwd error: %v", err)}
+				return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_error", "os_getcwd", err)}
 			}
 			return &String{Value: dir}, nil
 		},
@@ -2699,7 +2802,7 @@ func addGlobalFunctions(env *Environment) {
 					return &String{Value: value}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "os_getenv() requires environment variable name string"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.os_getenv_arguments")}
 		},
 	})
 
@@ -2712,13 +2815,13 @@ func addGlobalFunctions(env *Environment) {
 					if value, ok := list.Elements[1].(*String); ok {
 						err := os.Setenv(key.Value, value.Value)
 						if err != nil {
-							return &Nil{}, &RuntimeError{Message: fmt.Sprintf("os_setenv error: %v", err)}
+							return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_error", "os_setenv", err)}
 						}
 						return &Boolean{Value: true}, nil
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "os_setenv() requires key and value strings"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.os_setenv_arguments")}
 		},
 	})
 
@@ -2736,7 +2839,7 @@ func addGlobalFunctions(env *Environment) {
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "time_format() requires timestamp (int) and format string"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.time_format_arguments")}
 		},
 	})
 
@@ -2749,13 +2852,13 @@ func addGlobalFunctions(env *Environment) {
 					if format, ok := list.Elements[1].(*String); ok {
 						t, err := time.Parse(format.Value, timeStr.Value)
 						if err != nil {
-							return &Nil{}, &RuntimeError{Message: fmt.Sprintf("time_parse error: %v", err)}
+							return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_error", "time_parse", err)}
 						}
 						return &Integer{Value: t.UnixNano() / 1000000}, nil // Convert to milliseconds
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "time_parse() requires time string and format string"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.time_parse_arguments")}
 		},
 	})
 
@@ -2781,7 +2884,7 @@ func addGlobalFunctions(env *Environment) {
 							case "d":
 								d = time.Duration(duration.Value) * 24 * time.Hour
 							default:
-								return &Nil{}, &RuntimeError{Message: "time_add() unit must be ms, s, m, h, or d"}
+								return &Nil{}, &RuntimeError{Message: i18n.T("runtime.time_add_unit")}
 							}
 							newTime := t.Add(d)
 							return &Integer{Value: newTime.UnixNano() / 1000000}, nil
@@ -2789,7 +2892,7 @@ func addGlobalFunctions(env *Environment) {
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "time_add() requires timestamp, duration, and unit (ms/s/m/h/d)"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.time_add_arguments")}
 		},
 	})
 
@@ -2807,7 +2910,7 @@ func addGlobalFunctions(env *Environment) {
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "time_diff() requires two timestamps"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.time_diff_arguments")}
 		},
 	})
 
@@ -2846,7 +2949,7 @@ func addGlobalFunctions(env *Environment) {
 					return &List{Elements: results}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "Promise_all() requires a list of promises"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.promise_all_arguments")}
 		},
 	})
 
@@ -2893,7 +2996,7 @@ func addGlobalFunctions(env *Environment) {
 					return &List{Elements: results}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "Promise_allSettled() requires a list of promises"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.promise_all_settled_arguments")}
 		},
 	})
 
@@ -2907,19 +3010,19 @@ func addGlobalFunctions(env *Environment) {
 					// Simple HTTP GET implementation
 					resp, err := http.Get(url.Value)
 					if err != nil {
-						return &Nil{}, &RuntimeError{Message: fmt.Sprintf("http_get error: %v", err)}
+						return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_error", "http_get", err)}
 					}
 					defer resp.Body.Close()
 
 					body, err := io.ReadAll(resp.Body)
 					if err != nil {
-						return &Nil{}, &RuntimeError{Message: fmt.Sprintf("http_get read error: %v", err)}
+						return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_read_error", "http_get", err)}
 					}
 
 					return &String{Value: string(body)}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "http_get() requires URL string"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.http_get_arguments")}
 		},
 	})
 
@@ -2933,20 +3036,20 @@ func addGlobalFunctions(env *Environment) {
 						// Simple HTTP POST implementation
 						resp, err := http.Post(url.Value, "application/json", strings.NewReader(data.Value))
 						if err != nil {
-							return &Nil{}, &RuntimeError{Message: fmt.Sprintf("http_post error: %v", err)}
+							return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_error", "http_post", err)}
 						}
 						defer resp.Body.Close()
 
 						body, err := io.ReadAll(resp.Body)
 						if err != nil {
-							return &Nil{}, &RuntimeError{Message: fmt.Sprintf("http_post read error: %v", err)}
+							return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_read_error", "http_post", err)}
 						}
 
 						return &String{Value: string(body)}, nil
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "http_post() requires URL and data strings"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.http_post_arguments")}
 		},
 	})
 
@@ -2960,27 +3063,27 @@ func addGlobalFunctions(env *Environment) {
 						// Simple HTTP PUT implementation
 						req, err := http.NewRequest("PUT", url.Value, strings.NewReader(data.Value))
 						if err != nil {
-							return &Nil{}, &RuntimeError{Message: fmt.Sprintf("http_put error: %v", err)}
+							return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_error", "http_put", err)}
 						}
 						req.Header.Set("Content-Type", "application/json")
 
 						client := &http.Client{}
 						resp, err := client.Do(req)
 						if err != nil {
-							return &Nil{}, &RuntimeError{Message: fmt.Sprintf("http_put error: %v", err)}
+							return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_error", "http_put", err)}
 						}
 						defer resp.Body.Close()
 
 						body, err := io.ReadAll(resp.Body)
 						if err != nil {
-							return &Nil{}, &RuntimeError{Message: fmt.Sprintf("http_put read error: %v", err)}
+							return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_read_error", "http_put", err)}
 						}
 
 						return &String{Value: string(body)}, nil
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "http_put() requires URL and data strings"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.http_put_arguments")}
 		},
 	})
 
@@ -2993,25 +3096,25 @@ func addGlobalFunctions(env *Environment) {
 					// Simple HTTP DELETE implementation
 					req, err := http.NewRequest("DELETE", url.Value, nil)
 					if err != nil {
-						return &Nil{}, &RuntimeError{Message: fmt.Sprintf("http_delete error: %v", err)}
+						return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_error", "http_delete", err)}
 					}
 
 					client := &http.Client{}
 					resp, err := client.Do(req)
 					if err != nil {
-						return &Nil{}, &RuntimeError{Message: fmt.Sprintf("http_delete error: %v", err)}
+						return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_error", "http_delete", err)}
 					}
 					defer resp.Body.Close()
 
 					body, err := io.ReadAll(resp.Body)
 					if err != nil {
-						return &Nil{}, &RuntimeError{Message: fmt.Sprintf("http_delete read error: %v", err)}
+						return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_read_error", "http_delete", err)}
 					}
 
 					return &String{Value: string(body)}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "http_delete() requires URL string"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.http_delete_arguments")}
 		},
 	})
 
@@ -3026,7 +3129,7 @@ func addGlobalFunctions(env *Environment) {
 					return &String{Value: fmt.Sprintf("%x", hash)}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "crypto_md5() requires data string"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.crypto_md5_arguments")}
 		},
 	})
 
@@ -3040,7 +3143,7 @@ func addGlobalFunctions(env *Environment) {
 					return &String{Value: fmt.Sprintf("%x", hash)}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "crypto_sha256() requires data string"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.crypto_sha256_arguments")}
 		},
 	})
 
@@ -3054,7 +3157,7 @@ func addGlobalFunctions(env *Environment) {
 						// Simple AES encryption (for demo purposes)
 						block, err := aes.NewCipher([]byte(key.Value))
 						if err != nil {
-							return &Nil{}, &RuntimeError{Message: fmt.Sprintf("crypto_aes_encrypt error: %v", err)}
+							return &Nil{}, &RuntimeError{Message: i18n.T("runtime.builtin_error", "crypto_aes_encrypt", err)}
 						}
 
 						// Pad data to block size
@@ -3076,7 +3179,7 @@ func addGlobalFunctions(env *Environment) {
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "crypto_aes_encrypt() requires data and key strings"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.crypto_aes_encrypt_arguments")}
 		},
 	})
 }
@@ -3095,7 +3198,7 @@ func addListMethods(env *Environment) {
 					return &Nil{}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "append() requires list and item"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.append_arguments")}
 		},
 	})
 
@@ -3107,7 +3210,7 @@ func addListMethods(env *Environment) {
 			if list, ok := args.(*List); ok && len(list.Elements) >= 1 {
 				if targetList, ok := list.Elements[0].(*List); ok {
 					if len(targetList.Elements) == 0 {
-						return &Nil{}, &RuntimeError{Message: "pop from empty list"}
+						return &Nil{}, &RuntimeError{Message: i18n.T("runtime.pop_empty")}
 					}
 
 					idx := len(targetList.Elements) - 1
@@ -3118,7 +3221,7 @@ func addListMethods(env *Environment) {
 					}
 
 					if idx < 0 || idx >= len(targetList.Elements) {
-						return &Nil{}, &RuntimeError{Message: "pop index out of range"}
+						return &Nil{}, &RuntimeError{Message: i18n.T("runtime.pop_range")}
 					}
 
 					item := targetList.Elements[idx]
@@ -3126,7 +3229,7 @@ func addListMethods(env *Environment) {
 					return item, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "pop() requires list"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.pop_list")}
 		},
 	})
 
@@ -3153,7 +3256,7 @@ func addListMethods(env *Environment) {
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "insert() requires list, index, item"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.insert_arguments")}
 		},
 	})
 
@@ -3172,10 +3275,10 @@ func addListMethods(env *Environment) {
 							return &Nil{}, nil
 						}
 					}
-					return &Nil{}, &RuntimeError{Message: "item not in list"}
+					return &Nil{}, &RuntimeError{Message: i18n.T("runtime.not_in_list")}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "remove() requires list and item"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.remove_arguments")}
 		},
 	})
 
@@ -3190,7 +3293,7 @@ func addListMethods(env *Environment) {
 					return &Nil{}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "clear() requires list"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.clear_list")}
 		},
 	})
 
@@ -3251,7 +3354,7 @@ func addListMethods(env *Environment) {
 					return &Nil{}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "reverse() requires list"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.reverse_list")}
 		},
 	})
 
@@ -3267,7 +3370,7 @@ func addListMethods(env *Environment) {
 					return &List{Elements: copied}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "copy() requires list"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.copy_list")}
 		},
 	})
 
@@ -3284,7 +3387,7 @@ func addListMethods(env *Environment) {
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "extend() requires two lists"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.extend_lists")}
 		},
 	})
 }
@@ -3305,7 +3408,7 @@ func addDictMethods(env *Environment) {
 					return &List{Elements: keys}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "keys() requires dict"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.keys_dict")}
 		},
 	})
 
@@ -3323,7 +3426,7 @@ func addDictMethods(env *Environment) {
 					return &List{Elements: values}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "values() requires dict"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.values_dict")}
 		},
 	})
 
@@ -3346,7 +3449,7 @@ func addDictMethods(env *Environment) {
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "get() requires dict and key"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.get_arguments")}
 		},
 	})
 
@@ -3366,11 +3469,11 @@ func addDictMethods(env *Environment) {
 						if len(list.Elements) >= 3 {
 							return list.Elements[2], nil
 						}
-						return &Nil{}, &RuntimeError{Message: "key not found"}
+						return &Nil{}, &RuntimeError{Message: i18n.T("runtime.key_not_found")}
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "pop() requires dict and key"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.pop_dict")}
 		},
 	})
 
@@ -3385,7 +3488,7 @@ func addDictMethods(env *Environment) {
 					return &Nil{}, nil
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "clear() requires dict"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.clear_dict")}
 		},
 	})
 
@@ -3404,7 +3507,7 @@ func addDictMethods(env *Environment) {
 					}
 				}
 			}
-			return &Nil{}, &RuntimeError{Message: "update() requires two dicts"}
+			return &Nil{}, &RuntimeError{Message: i18n.T("runtime.update_dicts")}
 		},
 	})
 }
@@ -3427,12 +3530,18 @@ func (i *Interpreter) evalImportStatement(stmt *ast.ImportStatement) error {
 	}
 
 	// Load module file
-	moduleFilePath := i.resolveModulePath(modulePath)
-
-	// Read and parse module
-	content, err := os.ReadFile(moduleFilePath)
+	var moduleFilePath string
+	var content []byte
+	var err error
+	if i.moduleFS != nil {
+		moduleFilePath = modulePath + ".sky"
+		content, err = fs.ReadFile(i.moduleFS, moduleFilePath)
+	} else {
+		moduleFilePath = i.resolveModulePath(modulePath)
+		content, err = os.ReadFile(moduleFilePath)
+	}
 	if err != nil {
-		return &RuntimeError{Message: fmt.Sprintf("cannot load module %s: %v", modulePath, err)}
+		return &RuntimeError{Message: i18n.T("runtime.cannot_load_module", modulePath, err)}
 	}
 
 	// Parse module
@@ -3440,7 +3549,7 @@ func (i *Interpreter) evalImportStatement(stmt *ast.ImportStatement) error {
 	p := parser.New(l)
 	program := p.ParseProgram()
 	if len(p.Errors()) > 0 {
-		return &RuntimeError{Message: fmt.Sprintf("parse errors in module %s: %v", modulePath, p.Errors())}
+		return &RuntimeError{Message: i18n.T("runtime.module_parse_errors", modulePath, p.Errors())}
 	}
 
 	// Create module environment
@@ -3453,7 +3562,7 @@ func (i *Interpreter) evalImportStatement(stmt *ast.ImportStatement) error {
 		_, err := i.evalStatement(modStmt)
 		if err != nil {
 			i.env = oldEnv // Restore before returning
-			return &RuntimeError{Message: fmt.Sprintf("error in module %s: %v", modulePath, err)}
+			return &RuntimeError{Message: i18n.T("runtime.module_error", modulePath, err)}
 		}
 	}
 
@@ -3469,54 +3578,13 @@ func (i *Interpreter) evalImportStatement(stmt *ast.ImportStatement) error {
 
 // resolveModulePath resolves module path to file path
 func (i *Interpreter) resolveModulePath(modulePath string) string {
-	moduleFile := modulePath + ".sky"
-
-	// Try Wing dependencies first
-	dependencyPath := i.currentDir + "/dependencies/" + modulePath + "/src/" + moduleFile
-	if _, err := os.Stat(dependencyPath); err == nil {
-		return dependencyPath
-	}
-
-	// Try Wing dependencies with main.sky
-	dependencyMainPath := i.currentDir + "/dependencies/" + modulePath + "/src/main.sky"
-	if _, err := os.Stat(dependencyMainPath); err == nil {
-		return dependencyMainPath
-	}
-
-	// Try relative to source file directory
-	if i.sourceFile != "" {
-		sourceDir := ""
-		lastSlash := -1
-		for idx, ch := range i.sourceFile {
-			if ch == '/' || ch == '\\' {
-				lastSlash = idx
-			}
-		}
-		if lastSlash >= 0 {
-			sourceDir = i.sourceFile[:lastSlash]
-		}
-
-		if sourceDir != "" {
-			relToSource := sourceDir + "/" + moduleFile
-			if _, err := os.Stat(relToSource); err == nil {
-				return relToSource
-			}
-		}
-	}
-
-	// Try relative to current directory
-	relPath := i.currentDir + "/" + moduleFile
-	if _, err := os.Stat(relPath); err == nil {
-		return relPath
-	}
-
-	// Try as absolute path
-	if _, err := os.Stat(moduleFile); err == nil {
-		return moduleFile
-	}
+	return ResolveModulePath(modulePath, i.sourceFile, i.currentDir)
+}
 
-	// Default to relative path
-	return relPath
+// ResolveModulePath resolves an import path to a .sky file, trying Wing
+// dependencies, the source file's directory and the working directory
+func ResolveModulePath(modulePath, sourceFile, currentDir string) string {
+	return sema.ResolveModulePath(modulePath, sourceFile, currentDir)
 }
 
 // importSymbolsFromModule imports exported symbols from module
@@ -3707,6 +3775,9 @@ func (i *Interpreter) evalStaticMethodStatement(stmt *ast.StaticMethodStatement)
 					}
 				}
 			}
+			if err := i.checkArguments(funcName, capturedStmt.Parameters, fnEnv, callEnv); err != nil {
+				return nil, err
+			}
 
 			// Fonksiyon body'sini çalıştır
 			oldEnv := i.env
@@ -3720,6 +3791,9 @@ func (i *Interpreter) evalStaticMethodStatement(stmt *ast.StaticMethodStatement)
 				result = retSignal.Value
 				err = nil
 			}
+			if err == nil {
+				err = i.checkReturn(funcName, capturedStmt.ReturnType, false, result, fnEnv)
+			}
 
 			return result, err
 		},
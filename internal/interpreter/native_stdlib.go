@@ -146,6 +146,38 @@ func addNativeStdlib(env *Environment) {
 	}))
 }
 
+// blockingBuiltins are the builtins that block the calling thread. Each one
+// gets an async variant named <name>_async that returns a Promise.
+var blockingBuiltins = []string{
+	"time_sleep",
+	"fs_read_text",
+	"fs_write_text",
+	"http_get",
+	"http_post",
+	"http_put",
+	"http_delete",
+}
+
+// addAsyncBuiltins adds the async variants of the blocking builtins. Both
+// engines run the builtin on a goroutine of its own, off the caller.
+func addAsyncBuiltins(env *Environment) {
+	for _, name := range blockingBuiltins {
+		value, ok := env.Get(name)
+		if !ok {
+			continue
+		}
+		if fn, ok := value.(*Function); ok {
+			env.Set(name+"_async", &Function{
+				Name:   name + "_async",
+				Body:   fn.Body,
+				Env:    fn.Env,
+				Async:  true,
+				Native: true,
+			})
+		}
+	}
+}
+
 // createNativeFunc creates a native function wrapper
 func createNativeFunc(name string, fn func([]Value) (Value, error)) *Function {
 	return &Function{
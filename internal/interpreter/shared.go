@@ -0,0 +1,19 @@
+package interpreter
+
+// Bu dosyadaki yardımcılar bytecode VM'in tree-walker ile aynı
+// semantiği kullanabilmesi için dışa açılmıştır.
+
+// Builtins yerleşik fonksiyonların yeni bir kopyasını döndürür
+func Builtins() map[string]Value {
+	return New().env.GetAll()
+}
+
+// BinaryOp iki değer arasındaki ikili operatörü interpreter kurallarıyla uygular
+func BinaryOp(left, right Value, op string) (Value, error) {
+	return (&Interpreter{}).evalBinaryOp(left, right, op)
+}
+
+// IsSubclassOf class'ın target olup olmadığını ya da ondan türeyip türemediğini bildirir
+func IsSubclassOf(class, target *Class) bool {
+	return isSubclassOf(class, target)
+}
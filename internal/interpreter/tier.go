@@ -0,0 +1,137 @@
+package interpreter
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/optimizer"
+)
+
+// Tiered execution. Functions start in the interpreter; their calls and
+// loop iterations are counted with an optimizer.TieredJIT. When a function
+// reaches a new tier it is compiled by the Tier and later calls run the
+// compiled code. Functions the Tier cannot compile are deoptimized: they
+// stay in the interpreter and are not counted any more.
+
+// Executions (calls and loop iterations) before a function is promoted
+const (
+	TierBaselineThreshold  = 100
+	TierOptimizedThreshold = 5000
+)
+
+// Tier compiles hot functions to a faster engine
+type Tier interface {
+	// Compile compiles stmt, defined in env, at an optimization level.
+	// An error keeps the function in the interpreter.
+	Compile(stmt *ast.FunctionStatement, env *Environment, level int) (TierFunction, error)
+}
+
+// TierFunction calls a function compiled by a Tier
+type TierFunction func(args []Value) (Value, error)
+
+// tiering is the tier state of an interpreter
+type tiering struct {
+	tier  Tier
+	jit   *optimizer.TieredJIT
+	trace io.Writer // tier transitions are written here, nil for none
+}
+
+// hotFunction is the tier state of one function definition
+type hotFunction struct {
+	key   string // name in the TieredJIT
+	stmt  *ast.FunctionStatement
+	env   *Environment
+	tier  optimizer.ExecutionTier
+	code  TierFunction
+	deopt bool
+}
+
+// SetTier enables tiered execution of functions defined from now on.
+// Tier transitions are written to trace unless it is nil.
+func (i *Interpreter) SetTier(tier Tier, trace io.Writer) {
+	i.tiering = &tiering{
+		tier:  tier,
+		jit:   optimizer.NewTieredJIT(TierBaselineThreshold, TierOptimizedThreshold),
+		trace: trace,
+	}
+}
+
+// newHotFunction returns the tier state of a function, or nil when
+// tiered execution is off
+func (i *Interpreter) newHotFunction(stmt *ast.FunctionStatement, env *Environment) *hotFunction {
+	if i.tiering == nil {
+		return nil
+	}
+	return &hotFunction{
+		key:  fmt.Sprintf("%s:%d", stmt.Name.Value, stmt.Token.Line),
+		stmt: stmt,
+		env:  env,
+	}
+}
+
+// record counts an execution of fn and promotes it when it gets hot
+func (i *Interpreter) record(fn *hotFunction) {
+	if fn == nil || fn.deopt || fn.tier == optimizer.TierOptimized {
+		return
+	}
+	tier := i.tiering.jit.RecordExecution(fn.key)
+	if tier <= fn.tier {
+		return
+	}
+
+	// The optimization level matches the tier: -O1 for baseline, -O2 for optimized
+	code, err := i.tiering.tier.Compile(fn.stmt, fn.env, int(tier))
+	if err != nil {
+		fn.deopt = true
+		i.traceTier("%s: deoptimized, stays in %s: %v", fn.key, tierName(fn.tier), err)
+		return
+	}
+	i.traceTier("%s: %s -> %s", fn.key, tierName(fn.tier), tierName(tier))
+	fn.tier, fn.code = tier, code
+}
+
+// callTiered calls the compiled code of a promoted function, keeping the
+// recursion limit and memoization of interpreted calls
+func (i *Interpreter) callTiered(fn *hotFunction, args Value) (Value, error) {
+	var elements []Value
+	if list, ok := args.(*List); ok {
+		elements = list.Elements
+	}
+
+	name := fn.stmt.Name.Value
+	if i.recursionDepth >= 1000 {
+		return nil, &RuntimeError{Code: diag.RecursionLimit, Message: i18n.T("runtime.recursion_depth", name)}
+	}
+	i.recursionDepth++
+	defer func() { i.recursionDepth-- }()
+
+	result, err := fn.code(elements)
+	if err == nil && result != nil {
+		i.trampoline.SetCached(name, elements, result)
+	}
+	return result, err
+}
+
+// backEdge counts a loop iteration of the function being interpreted
+func (i *Interpreter) backEdge() {
+	i.record(i.hot)
+}
+
+func (i *Interpreter) traceTier(format string, args ...interface{}) {
+	if i.tiering.trace != nil {
+		fmt.Fprintf(i.tiering.trace, "[tier] "+format+"\n", args...)
+	}
+}
+
+func tierName(tier optimizer.ExecutionTier) string {
+	switch tier {
+	case optimizer.TierBaseline:
+		return "vm -O1"
+	case optimizer.TierOptimized:
+		return "vm -O2"
+	}
+	return "interpreter"
+}
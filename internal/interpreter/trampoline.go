@@ -1,6 +1,10 @@
 package interpreter
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+)
 
 // CallFrame represents a single function call in our custom stack
 type CallFrame struct {
@@ -30,7 +34,7 @@ func NewTrampolineStack(maxDepth int) *TrampolineStack {
 func (ts *TrampolineStack) Push(frame *CallFrame) error {
 	if len(ts.frames) >= ts.maxDepth {
 		return &RuntimeError{
-			Message: fmt.Sprintf("stack overflow: maximum recursion depth (%d) exceeded", ts.maxDepth),
+			Message: i18n.T("runtime.stack_overflow", ts.maxDepth),
 		}
 	}
 	ts.frames = append(ts.frames, frame)
@@ -55,6 +59,9 @@ func (ts *TrampolineStack) Depth() int {
 // GetCached checks if result is cached
 func (ts *TrampolineStack) GetCached(funcName string, args []Value) (Value, bool) {
 	key := ts.makeCacheKey(funcName, args)
+	if key == "" {
+		return nil, false
+	}
 	val, ok := ts.resultCache[key]
 	return val, ok
 }
@@ -62,6 +69,9 @@ func (ts *TrampolineStack) GetCached(funcName string, args []Value) (Value, bool
 // SetCached stores result in cache
 func (ts *TrampolineStack) SetCached(funcName string, args []Value, result Value) {
 	key := ts.makeCacheKey(funcName, args)
+	if key == "" {
+		return // Cache'lenemeyen argümanlar
+	}
 	ts.resultCache[key] = result
 }
 
@@ -0,0 +1,458 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+)
+
+// Runtime type checks. Type annotations are erased when a program runs;
+// with SetTypeChecks the interpreter checks values where they cross an
+// annotation: arguments bound to typed parameters, values returned from
+// functions with a return type and typed let bindings. The VM runs the
+// same checks through RuntimeType.
+//
+// nil passes every check, as in the checker without --strict-null. Names
+// that are not classes or enums when the check runs (interfaces, type
+// parameters) are not checked.
+
+// TypeError is raised when a value does not match its annotation
+type TypeError struct {
+	Message string
+}
+
+func (e *TypeError) Error() string {
+	return "TypeError: " + e.Message
+}
+
+// DiagnosticCode returns the code of runtime type errors
+func (e *TypeError) DiagnosticCode() string {
+	return diag.RuntimeTypeError
+}
+
+// RuntimeType is a parsed type annotation
+type RuntimeType struct {
+	Name string         // int, float, ..., a class name; "[]", "{}", "?", "|" and "function" for composite types
+	Args []*RuntimeType // element, key and value, base, members or parameter types
+	text string
+}
+
+// String returns the annotation as written
+func (t *RuntimeType) String() string {
+	return t.text
+}
+
+// NewRuntimeType converts an annotation; a missing annotation is any
+func NewRuntimeType(annotation ast.TypeAnnotation) *RuntimeType {
+	if annotation == nil {
+		return &RuntimeType{Name: "any", text: "any"}
+	}
+	t, err := ParseRuntimeType(annotation.String())
+	if err != nil {
+		return &RuntimeType{Name: "any", text: annotation.String()}
+	}
+	return t
+}
+
+// ParseRuntimeType parses an annotation in the form ast.TypeAnnotation
+// prints it, which is how compiled bytecode stores annotations
+func ParseRuntimeType(text string) (*RuntimeType, error) {
+	p := &typeParser{text: text}
+	t := p.union()
+	p.space()
+	if p.err == nil && p.pos < len(text) {
+		p.fail("unexpected %q", text[p.pos:])
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return t, nil
+}
+
+type typeParser struct {
+	text string
+	pos  int
+	err  error
+}
+
+func (p *typeParser) fail(format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = fmt.Errorf("invalid type %q: %s", p.text, fmt.Sprintf(format, args...))
+	}
+}
+
+func (p *typeParser) space() {
+	for p.pos < len(p.text) && p.text[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// accept consumes s if the input continues with it
+func (p *typeParser) accept(s string) bool {
+	p.space()
+	if strings.HasPrefix(p.text[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *typeParser) expect(s string) {
+	if !p.accept(s) {
+		p.fail("expected %q", s)
+	}
+}
+
+func (p *typeParser) union() *RuntimeType {
+	start := p.pos
+	t := p.optional()
+	if !p.accept("|") {
+		return t
+	}
+	members := []*RuntimeType{t}
+	for {
+		members = append(members, p.optional())
+		if !p.accept("|") {
+			break
+		}
+	}
+	return &RuntimeType{Name: "|", Args: members, text: p.slice(start)}
+}
+
+func (p *typeParser) optional() *RuntimeType {
+	start := p.pos
+	t := p.primary()
+	for p.accept("?") {
+		t = &RuntimeType{Name: "?", Args: []*RuntimeType{t}, text: p.slice(start)}
+	}
+	return t
+}
+
+func (p *typeParser) primary() *RuntimeType {
+	p.space()
+	start := p.pos
+	switch {
+	case p.accept("["):
+		elem := p.union()
+		p.expect("]")
+		return &RuntimeType{Name: "[]", Args: []*RuntimeType{elem}, text: p.slice(start)}
+	case p.accept("{"):
+		key := p.union()
+		p.expect(":")
+		value := p.union()
+		p.expect("}")
+		return &RuntimeType{Name: "{}", Args: []*RuntimeType{key, value}, text: p.slice(start)}
+	case p.accept("*"):
+		// Pointers only exist in unsafe code and are not checked
+		p.primary()
+		return &RuntimeType{Name: "any", text: p.slice(start)}
+	}
+
+	name := p.name()
+	if name == "" {
+		p.fail("expected a type at %d", p.pos)
+		return &RuntimeType{Name: "any"}
+	}
+	if name == "function" && p.accept("(") {
+		var params []*RuntimeType
+		for !p.accept(")") && p.err == nil {
+			params = append(params, p.union())
+			p.accept(",")
+		}
+		if p.accept("->") {
+			p.union()
+		}
+		return &RuntimeType{Name: "function", Args: params, text: p.slice(start)}
+	}
+	t := &RuntimeType{Name: name}
+	if p.accept("<") {
+		for {
+			t.Args = append(t.Args, p.union())
+			if !p.accept(",") {
+				break
+			}
+		}
+		p.expect(">")
+	}
+	t.text = p.slice(start)
+	return t
+}
+
+func (p *typeParser) name() string {
+	start := p.pos
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		if c != '_' && c != '.' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !('0' <= c && c <= '9') {
+			break
+		}
+		p.pos++
+	}
+	return p.text[start:p.pos]
+}
+
+func (p *typeParser) slice(start int) string {
+	return strings.TrimSpace(p.text[start:p.pos])
+}
+
+// Awaited returns T for Promise<T>: the body of an async function
+// returns the value its promise resolves to
+func (t *RuntimeType) Awaited() *RuntimeType {
+	if t.Name == "Promise" && len(t.Args) == 1 {
+		return t.Args[0]
+	}
+	return t
+}
+
+// Matches reports whether v has type t. lookup resolves class and enum
+// names where the check runs.
+func (t *RuntimeType) Matches(v Value, lookup func(name string) (Value, bool)) bool {
+	if _, ok := v.(*Nil); ok || v == nil {
+		return true
+	}
+	switch t.Name {
+	case "any", "void":
+		return true
+	case "int":
+		return v.Kind() == IntValue
+	case "float":
+		return v.Kind() == FloatValue || v.Kind() == IntValue
+	case "string":
+		return v.Kind() == StringValue
+	case "bool":
+		return v.Kind() == BoolValue
+	case "?":
+		return t.Args[0].Matches(v, lookup)
+	case "|":
+		for _, member := range t.Args {
+			if member.Matches(v, lookup) {
+				return true
+			}
+		}
+		return false
+	case "[]", "List":
+		list, ok := v.(*List)
+		if !ok {
+			return false
+		}
+		if len(t.Args) == 0 {
+			return true
+		}
+		for _, elem := range list.Elements {
+			if !t.Args[0].Matches(elem, lookup) {
+				return false
+			}
+		}
+		return true
+	case "{}", "Dict":
+		dict, ok := v.(*Dict)
+		if !ok {
+			return false
+		}
+		if len(t.Args) < 2 {
+			return true
+		}
+		for _, value := range dict.Pairs {
+			if !t.Args[1].Matches(value, lookup) {
+				return false
+			}
+		}
+		return true
+	case "function":
+		return v.Kind() == FunctionValue
+	case "Promise":
+		return v.Kind() == PromiseValue
+	}
+	return t.matchesNamed(v, lookup)
+}
+
+// matchesNamed checks instances against classes and enum values against enums
+func (t *RuntimeType) matchesNamed(v Value, lookup func(name string) (Value, bool)) bool {
+	declared, ok := lookup(t.Name)
+	if !ok {
+		return true
+	}
+	switch declared.(type) {
+	case *Class, *AbstractClass:
+		inst, ok := v.(*Instance)
+		return ok && instanceOf(inst.Class, t.Name)
+	case *EnumType:
+		switch e := v.(type) {
+		case *EnumInstance:
+			return e.TypeName == t.Name
+		case *Function:
+			return e.Variant != nil && e.Variant.Enum == t.Name
+		}
+		return false
+	}
+	return true
+}
+
+func instanceOf(class *Class, name string) bool {
+	if class.Name == name {
+		return true
+	}
+	for _, super := range class.SuperClasses {
+		if instanceOf(super, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// TypeName describes the type of a runtime value for type errors
+func TypeName(v Value) string {
+	switch v := v.(type) {
+	case nil, *Nil:
+		return "nil"
+	case *Integer:
+		return "int"
+	case *Float:
+		return "float"
+	case *String:
+		return "string"
+	case *Boolean:
+		return "bool"
+	case *List:
+		if len(v.Elements) == 0 {
+			return "[]"
+		}
+		elem := TypeName(v.Elements[0])
+		for _, e := range v.Elements[1:] {
+			if TypeName(e) != elem {
+				return "[any]"
+			}
+		}
+		return "[" + elem + "]"
+	case *Dict:
+		return "dict"
+	case *Instance:
+		return v.Class.Name
+	case *EnumInstance:
+		return v.TypeName
+	case *Class, *AbstractClass:
+		return "class"
+	case *Promise:
+		return "Promise"
+	}
+	if v.Kind() == FunctionValue {
+		return "function"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// ArgumentError is the error for argument param of function fn. site is
+// where the call was made, empty when it is not known.
+func ArgumentError(fn, param string, t *RuntimeType, v Value, site string) error {
+	msg := i18n.T("runtime.argument_type", param, fn, t, TypeName(v))
+	if site != "" {
+		msg += i18n.T("runtime.called_at", site)
+	}
+	return &TypeError{Message: msg}
+}
+
+// ReturnError is the error for a value returned from fn
+func ReturnError(fn string, t *RuntimeType, v Value) error {
+	return &TypeError{Message: i18n.T("runtime.return_type", fn, t, TypeName(v))}
+}
+
+// LetError is the error for a value bound to a typed let
+func LetError(name string, t *RuntimeType, v Value) error {
+	return &TypeError{Message: i18n.T("runtime.let_type", name, t, TypeName(v))}
+}
+
+// CallSite formats a source position as file:line
+func CallSite(file string, line int) string {
+	if file == "" {
+		return fmt.Sprintf("line %d", line)
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// SetTypeChecks turns runtime type checks on or off
+func (i *Interpreter) SetTypeChecks(on bool) {
+	i.typeChecks = on
+}
+
+// runtimeType returns the parsed form of an annotation, parsing it once
+func (i *Interpreter) runtimeType(annotation ast.TypeAnnotation) *RuntimeType {
+	if t, ok := i.typeCache[annotation]; ok {
+		return t
+	}
+	if i.typeCache == nil {
+		i.typeCache = make(map[ast.TypeAnnotation]*RuntimeType)
+	}
+	t := NewRuntimeType(annotation)
+	i.typeCache[annotation] = t
+	return t
+}
+
+// withCallSite records where a call is made, for argument type errors
+func (i *Interpreter) withCallSite(callEnv *Environment, pos lexer.Token) {
+	if i.typeChecks {
+		SetCallSite(callEnv, CallSite(pos.File, pos.Line))
+	}
+}
+
+// SetCallSite records site as the position of the call made with callEnv,
+// for engines calling interpreter functions
+func SetCallSite(callEnv *Environment, site string) {
+	if site != "" {
+		callEnv.Set("__site__", &String{Value: site})
+	}
+}
+
+// checkArguments checks the arguments of a call to fn against its
+// parameters. Omitted arguments are not checked; a default value has the
+// type of its parameter. Names are resolved in env.
+func (i *Interpreter) checkArguments(fn string, params []*ast.FunctionParameter, env, callEnv *Environment) error {
+	if !i.typeChecks {
+		return nil
+	}
+	args, _ := callEnv.Get("__args__")
+	argList, ok := args.(*List)
+	if !ok {
+		return nil
+	}
+	site := ""
+	if s, ok := callEnv.store["__site__"].(*String); ok {
+		site = s.Value
+	}
+	for idx, param := range params {
+		if idx >= len(argList.Elements) {
+			break
+		}
+		if param.Type == nil {
+			continue
+		}
+		values := argList.Elements[idx : idx+1]
+		if param.Variadic {
+			values = argList.Elements[idx:]
+		}
+		t := i.runtimeType(param.Type)
+		for _, v := range values {
+			if !t.Matches(v, env.Get) {
+				return ArgumentError(fn, param.Name.Value, t, v, site)
+			}
+		}
+	}
+	return nil
+}
+
+// checkReturn checks the value returned from fn against its return type
+func (i *Interpreter) checkReturn(fn string, annotation ast.TypeAnnotation, async bool, result Value, env *Environment) error {
+	if !i.typeChecks || annotation == nil || result == nil {
+		return nil
+	}
+	t := i.runtimeType(annotation)
+	if async {
+		t = t.Awaited()
+	}
+	if !t.Matches(result, env.Get) {
+		return ReturnError(fn, t, result)
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 )
 
 // ValueKind değer tiplerini belirtir
@@ -24,6 +26,7 @@ const (
 	GeneratorValue
 	AbstractClassValue
 	AbstractMethodValue
+	InterfaceValue
 )
 
 // Value runtime değerlerini temsil eder
@@ -122,7 +125,9 @@ type Function struct {
 	Parameters []string
 	Body       func(*Environment) (Value, error)
 	Env        *Environment
-	Async      bool // async function flag
+	Async      bool         // async function flag
+	Native     bool         // async builtin, runs off the interpreter
+	Variant    *VariantInfo // non-nil for enum variant constructors
 }
 
 func (f *Function) Kind() ValueKind { return FunctionValue }
@@ -136,6 +141,7 @@ func (f *Function) IsTruthy() bool { return true }
 
 // RuntimeError runtime hatalarını temsil eder
 type RuntimeError struct {
+	Code    string // diagnostic code, empty when the error has none
 	Message string
 }
 
@@ -143,6 +149,11 @@ func (e *RuntimeError) Error() string {
 	return e.Message
 }
 
+// DiagnosticCode returns the code of the error, see sky explain
+func (e *RuntimeError) DiagnosticCode() string {
+	return e.Code
+}
+
 // BreakSignal signals a break statement execution
 type BreakSignal struct{}
 
@@ -205,7 +216,7 @@ func (e *Environment) Update(name string, value Value) error {
 	if e.parent != nil {
 		return e.parent.Update(name, value)
 	}
-	return &RuntimeError{Message: fmt.Sprintf("undefined variable: %s", name)}
+	return &RuntimeError{Code: diag.UndefinedName, Message: i18n.T("runtime.undefined_variable", name)}
 }
 
 // GetAll returns all symbols in this environment (not including parent)
@@ -270,6 +281,7 @@ func (p *Promise) Await() (Value, error) {
 type Class struct {
 	Name         string
 	SuperClasses []*Class             // parent classes (multiple inheritance)
+	Interfaces   []*Interface         // declared interfaces (class Foo implements Bar)
 	Methods      map[string]*Function // method name -> function
 	Env          *Environment         // class environment
 }
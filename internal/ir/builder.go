@@ -1,3 +1,5 @@
+//go:build llvm
+
 package ir
 
 /*
@@ -0,0 +1,110 @@
+package ir
+
+// Dominators are computed with the iterative algorithm of Cooper, Harvey
+// and Kennedy, "A Simple, Fast Dominance Algorithm": immediate dominators
+// are refined in reverse postorder until nothing changes.
+
+// DomTree holds the immediate dominator of every reachable block
+type DomTree struct {
+	idom  map[*Block]*Block
+	order map[*Block]int // reverse postorder number
+}
+
+// ComputeDominators builds the dominator tree of f
+func ComputeDominators(f *Func) *DomTree {
+	post := postorder(f)
+	t := &DomTree{
+		idom:  make(map[*Block]*Block, len(post)),
+		order: make(map[*Block]int, len(post)),
+	}
+	for i, b := range post {
+		t.order[b] = len(post) - 1 - i
+	}
+
+	entry := f.Entry()
+	t.idom[entry] = entry
+	for changed := true; changed; {
+		changed = false
+		for i := len(post) - 1; i >= 0; i-- {
+			b := post[i]
+			if b == entry {
+				continue
+			}
+			var idom *Block
+			for _, p := range b.Preds {
+				if t.idom[p] == nil {
+					continue
+				}
+				if idom == nil {
+					idom = p
+				} else {
+					idom = t.intersect(p, idom)
+				}
+			}
+			if t.idom[b] != idom {
+				t.idom[b] = idom
+				changed = true
+			}
+		}
+	}
+	return t
+}
+
+func (t *DomTree) intersect(a, b *Block) *Block {
+	for a != b {
+		for t.order[a] > t.order[b] {
+			a = t.idom[a]
+		}
+		for t.order[b] > t.order[a] {
+			b = t.idom[b]
+		}
+	}
+	return a
+}
+
+// Idom returns the immediate dominator of b, nil for the entry and for
+// unreachable blocks
+func (t *DomTree) Idom(b *Block) *Block {
+	idom := t.idom[b]
+	if idom == b {
+		return nil
+	}
+	return idom
+}
+
+// Dominates reports whether every path from the entry to b passes a
+func (t *DomTree) Dominates(a, b *Block) bool {
+	if t.idom[b] == nil {
+		return false
+	}
+	for {
+		if a == b {
+			return true
+		}
+		next := t.idom[b]
+		if next == b {
+			return false
+		}
+		b = next
+	}
+}
+
+// postorder lists the blocks reachable from the entry in postorder.
+// Successors are visited last to first, so in reverse postorder the
+// first successor of a block comes first.
+func postorder(f *Func) []*Block {
+	var order []*Block
+	seen := make(map[*Block]bool)
+	var visit func(b *Block)
+	visit = func(b *Block) {
+		seen[b] = true
+		for i := len(b.Succs) - 1; i >= 0; i-- {
+			if succ := b.Succs[i]; !seen[succ] {
+				visit(succ)
+			}
+		}
+		order = append(order, b)
+	}
+	visit(f.Entry())
+	return order
+}
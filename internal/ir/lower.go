@@ -0,0 +1,666 @@
+package ir
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/sema"
+)
+
+// Lowering builds SSA directly from the AST with the algorithm of Braun
+// et al., "Simple and Efficient Construction of Static Single Assignment
+// Form": every block records the latest definition of each variable,
+// reads search the predecessors and place phis where definitions meet,
+// and phis whose arguments all agree are removed again. A block is sealed
+// once all its predecessors are known; reads in unsealed blocks (loop
+// headers) get placeholder phis completed on sealing.
+//
+// Scopes follow the other backends: functions, for loops, catch clauses
+// and match arms open scopes, other blocks do not, and definitions at
+// the top level of the script are globals.
+
+// Lower translates a checked program to SSA. types are the identifier
+// types recorded by the checker (sema.Checker.Types); they type the
+// globals the lowering cannot see defined. Without them only literals,
+// operators and annotations give types.
+func Lower(program *ast.Program, name string, types []sema.TypeInfo) (*Module, error) {
+	l := &lowerer{
+		types:    make(map[typeKey]sema.Type),
+		boxed:    make(map[ast.Node]bool),
+		declared: declaredGlobals(program.Statements),
+		classes:  make(map[string]bool),
+		variants: make(map[string]string),
+	}
+	for _, stmt := range program.Statements {
+		switch s := stmt.(type) {
+		case *ast.ClassStatement:
+			l.classes[s.Name.Value] = true
+		case *ast.AbstractClassStatement:
+			l.classes[s.Name.Value] = true
+		case *ast.EnumStatement:
+			for _, variant := range s.Variants {
+				l.variants[variant.Name.Value] = s.Name.Value
+			}
+		}
+	}
+	for _, info := range types {
+		l.types[typeKey{info.Pos.Line, info.Pos.Column}] = info.Type
+	}
+
+	// Variables found captured or assigned in try blocks only while
+	// lowering live in boxes, so the module is lowered again until no
+	// new box is needed
+	for {
+		l.again = false
+		l.names = make(map[string]int)
+		l.module = &Module{Name: name}
+		if err := l.script(program); err != nil {
+			return nil, err
+		}
+		if !l.again {
+			return l.module, nil
+		}
+	}
+}
+
+type typeKey struct {
+	line, column int
+}
+
+type lowerer struct {
+	module *Module
+	types  map[typeKey]sema.Type
+	boxed  map[ast.Node]bool // declarations living in boxes
+	again  bool              // a declaration became boxed during this pass
+	names  map[string]int    // functions per name, to number repeats
+
+	declared map[string]bool   // globals the script defines
+	classes  map[string]bool   // classes the script defines
+	variants map[string]string // enum of each variant the script defines
+}
+
+// variable is a local variable; decl is the AST node declaring it
+type variable struct {
+	name  string
+	decl  ast.Node
+	owner *builder
+	boxed bool
+	tries int // try blocks open at the declaration
+	typ   sema.Type
+}
+
+type scope struct {
+	vars  map[string]*variable
+	outer *scope
+}
+
+// loopTargets are the blocks break and continue jump to
+type loopTargets struct {
+	brk, cont *Block
+	tries     int
+}
+
+// tryFrame is an open try statement; handler is set while an error
+// handler is installed
+type tryFrame struct {
+	finally *ast.BlockStatement
+	handler bool
+}
+
+// builder lowers one function
+type builder struct {
+	l       *lowerer
+	f       *Func
+	parent  *builder
+	scope   *scope
+	depth   int    // scopes open; definitions at depth 0 of the script are globals
+	current *Block // block being built, nil after a jump
+	method  bool
+
+	defs       map[*variable]map[*Block]*Value
+	sealed     map[*Block]bool
+	incomplete map[*Block]map[*variable]*Value
+	free       map[*variable]*Value
+	loops      []loopTargets
+	tries      []tryFrame
+}
+
+func (l *lowerer) newFunc(name string, parent *Func) *Func {
+	l.names[name]++
+	if n := l.names[name]; n > 1 {
+		name = fmt.Sprintf("%s#%d", name, n)
+	}
+	f := &Func{Name: name, Module: l.module, Parent: parent}
+	l.module.Funcs = append(l.module.Funcs, f)
+	return f
+}
+
+func (l *lowerer) newBuilder(f *Func, parent *builder) *builder {
+	b := &builder{
+		l:          l,
+		f:          f,
+		parent:     parent,
+		scope:      &scope{vars: make(map[string]*variable)},
+		defs:       make(map[*variable]map[*Block]*Value),
+		sealed:     make(map[*Block]bool),
+		incomplete: make(map[*Block]map[*variable]*Value),
+		free:       make(map[*variable]*Value),
+	}
+	entry := f.NewBlock(BlockPlain)
+	b.seal(entry)
+	b.current = entry
+	return b
+}
+
+// script lowers the top-level code. A main function is awaited at the
+// end, like the interpreter does.
+func (l *lowerer) script(program *ast.Program) error {
+	b := l.newBuilder(l.newFunc("<script>", nil), nil)
+	if err := b.stmts(program.Statements); err != nil {
+		return err
+	}
+	for _, stmt := range program.Statements {
+		if fn, ok := stmt.(*ast.FunctionStatement); ok && fn.Name.Value == "main" {
+			main := b.emitAux(OpGlobal, "main", sema.AnyType)
+			b.emit(OpAwait, sema.AnyType, b.emit(OpCall, sema.AnyType, main))
+			break
+		}
+	}
+	b.finish()
+	return nil
+}
+
+// declaredGlobals returns the names the top-level statements define;
+// functions assigning them store the global rather than define a local
+func declaredGlobals(stmts []ast.Statement) map[string]bool {
+	declared := make(map[string]bool)
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.FunctionStatement:
+			declared[s.Name.Value] = true
+		case *ast.LetStatement:
+			declared[s.Name.Value] = true
+		case *ast.ConstStatement:
+			declared[s.Name.Value] = true
+		case *ast.ClassStatement:
+			declared[s.Name.Value] = true
+		case *ast.AbstractClassStatement:
+			declared[s.Name.Value] = true
+		case *ast.ExpressionStatement:
+			if assign, ok := s.Expression.(*ast.InfixExpression); ok && assign.Operator == "=" {
+				if ident, ok := assign.Left.(*ast.Identifier); ok {
+					declared[ident.Value] = true
+				}
+			}
+		case *ast.EnumStatement:
+			declared[s.Name.Value] = true
+			for _, variant := range s.Variants {
+				declared[variant.Name.Value] = true
+			}
+		}
+	}
+	return declared
+}
+
+// finish returns nil from the open block, drops unreachable blocks and
+// numbers the others in reverse postorder, so a block comes after the
+// blocks leading to it except along loop back edges
+func (b *builder) finish() {
+	if b.current != nil {
+		b.current.Kind = BlockReturn
+		b.current = nil
+	}
+	removeUnreachable(b.f)
+	post := postorder(b.f)
+	for i, blk := range post {
+		blk.ID = len(post) - 1 - i
+		b.f.Blocks[blk.ID] = blk
+	}
+	b.f.nextBlock = len(post)
+}
+
+// Blocks
+
+func (b *builder) newBlock() *Block {
+	return b.f.NewBlock(BlockPlain)
+}
+
+// cur returns the current block; code after a jump goes to a new block
+// without predecessors
+func (b *builder) cur() *Block {
+	if b.current == nil {
+		b.current = b.newBlock()
+		b.seal(b.current)
+	}
+	return b.current
+}
+
+func (b *builder) emit(op Op, t sema.Type, args ...*Value) *Value {
+	return b.cur().NewValue(op, t, args...)
+}
+
+func (b *builder) emitAux(op Op, aux interface{}, t sema.Type, args ...*Value) *Value {
+	v := b.emit(op, t, args...)
+	v.Aux = aux
+	return v
+}
+
+func (b *builder) constant(c interface{}) *Value {
+	return b.emitAux(OpConst, c, constType(c))
+}
+
+func constType(c interface{}) sema.Type {
+	switch c.(type) {
+	case int64:
+		return sema.IntType
+	case float64:
+		return sema.FloatType
+	case string:
+		return sema.StringType
+	case bool:
+		return sema.BoolType
+	}
+	return sema.NilType
+}
+
+// jump ends the current block with a jump to to
+func (b *builder) jump(to *Block) {
+	if b.current != nil {
+		b.current.Kind = BlockPlain
+		b.current.AddEdge(to)
+		b.current = nil
+	}
+}
+
+// branch ends the current block, going to yes if cond is truthy
+func (b *builder) branch(cond *Value, yes, no *Block) {
+	blk := b.cur()
+	blk.Kind = BlockIf
+	blk.Control = cond
+	blk.AddEdge(yes)
+	blk.AddEdge(no)
+	b.current = nil
+}
+
+// start continues in blk, or nowhere if nothing reaches it
+func (b *builder) start(blk *Block) {
+	if len(blk.Preds) == 0 && b.sealed[blk] {
+		b.current = nil
+		return
+	}
+	b.current = blk
+}
+
+// Variables
+
+func (b *builder) enterScope() {
+	b.scope = &scope{vars: make(map[string]*variable), outer: b.scope}
+	b.depth++
+}
+
+func (b *builder) leaveScope() {
+	b.scope = b.scope.outer
+	b.depth--
+}
+
+func (b *builder) isGlobalScope() bool {
+	return b.parent == nil && b.depth == 0
+}
+
+// lookup finds the local variable name in this function or the ones
+// enclosing it
+func (b *builder) lookup(name string) *variable {
+	for fb := b; fb != nil; fb = fb.parent {
+		for s := fb.scope; s != nil; s = s.outer {
+			if v, ok := s.vars[name]; ok {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// declare adds a local to the current scope, reusing the variable if the
+// scope already has it
+func (b *builder) declare(name string, decl ast.Node, t sema.Type) *variable {
+	if v, ok := b.scope.vars[name]; ok {
+		return v
+	}
+	v := &variable{name: name, decl: decl, owner: b, boxed: b.l.boxed[decl], tries: len(b.tries), typ: t}
+	b.scope.vars[name] = v
+	return v
+}
+
+// define declares name and gives it val
+func (b *builder) define(name string, decl ast.Node, val *Value) {
+	if b.isGlobalScope() {
+		b.emitAux(OpSetGlobal, name, sema.VoidType, val)
+		nameValue(val, name)
+		return
+	}
+	v := b.declare(name, decl, val.Type)
+	if v.boxed {
+		box := b.emitAux(OpAlloc, name, &sema.PointerType{PointeeType: v.typ})
+		b.emit(OpStore, sema.VoidType, box, val)
+		b.writeVariable(v, b.cur(), box)
+		return
+	}
+	nameValue(val, name)
+	b.writeVariable(v, b.cur(), val)
+}
+
+// nameValue records that val holds name unless another name came first
+func nameValue(val *Value, name string) {
+	if val.Name == "" && val.Op != OpConst {
+		val.Name = name
+	}
+}
+
+// get reads name
+func (b *builder) get(ident *ast.Identifier) *Value {
+	v := b.lookup(ident.Value)
+	if v == nil {
+		if ident.Value == "nil" || ident.Value == "null" {
+			return b.constant(nil)
+		}
+		t := b.l.types[typeKey{ident.Token.Line, ident.Token.Column}]
+		return b.emitAux(OpGlobal, ident.Value, t)
+	}
+	if v.owner != b {
+		return b.emit(OpLoad, v.typ, b.capture(v))
+	}
+	if v.boxed {
+		return b.emit(OpLoad, v.typ, b.readVariable(v, b.cur()))
+	}
+	return b.readVariable(v, b.cur())
+}
+
+// set assigns val to name, defining a local if the name is undefined
+func (b *builder) set(ident *ast.Identifier, val *Value) {
+	v := b.lookup(ident.Value)
+	if v == nil {
+		if b.l.declared[ident.Value] {
+			b.emitAux(OpSetGlobal, ident.Value, sema.VoidType, val)
+			return
+		}
+		b.define(ident.Value, ident, val)
+		return
+	}
+	if v.owner != b {
+		b.emit(OpStore, sema.VoidType, b.capture(v), val)
+		return
+	}
+	if !v.boxed && v.tries < len(b.tries) {
+		// The handler and the code after the try block must see it
+		b.box(v)
+	}
+	if v.boxed {
+		b.emit(OpStore, sema.VoidType, b.readVariable(v, b.cur()), val)
+		return
+	}
+	nameValue(val, v.name)
+	b.writeVariable(v, b.cur(), val)
+}
+
+// box moves v into a box from the next lowering on
+func (b *builder) box(v *variable) {
+	if !b.l.boxed[v.decl] {
+		b.l.boxed[v.decl] = true
+		b.l.again = true
+	}
+}
+
+// capture returns the box of the variable v of an enclosing function
+func (b *builder) capture(v *variable) *Value {
+	if !v.boxed {
+		b.box(v)
+		return b.entryValue(OpAlloc, v.name, &sema.PointerType{PointeeType: v.typ})
+	}
+	if box, ok := b.free[v]; ok {
+		return box
+	}
+	box := b.entryValue(OpFreeVar, v.name, &sema.PointerType{PointeeType: v.typ})
+	box.AuxInt = int64(len(b.f.FreeVars))
+	b.f.FreeVars = append(b.f.FreeVars, v.name)
+	b.free[v] = box
+	return box
+}
+
+// boxFor returns the box in this function of v, for closures capturing it
+func (b *builder) boxFor(v *variable) *Value {
+	if v.owner != b {
+		return b.capture(v)
+	}
+	return b.readVariable(v, b.cur())
+}
+
+// entryValue adds a value at the start of the entry block, after the
+// parameters
+func (b *builder) entryValue(op Op, aux interface{}, t sema.Type) *Value {
+	return b.f.entryValue(op, aux, t)
+}
+
+// undefined is the value of v where it is read before any definition:
+// nil, or a new box holding nil
+func (b *builder) undefined(v *variable) *Value {
+	if v.boxed {
+		return b.entryValue(OpAlloc, v.name, &sema.PointerType{PointeeType: v.typ})
+	}
+	return b.entryValue(OpConst, nil, sema.NilType)
+}
+
+func (b *builder) writeVariable(v *variable, blk *Block, val *Value) {
+	defs, ok := b.defs[v]
+	if !ok {
+		defs = make(map[*Block]*Value)
+		b.defs[v] = defs
+	}
+	defs[blk] = val
+}
+
+func (b *builder) readVariable(v *variable, blk *Block) *Value {
+	if val, ok := b.defs[v][blk]; ok {
+		return val
+	}
+	return b.readVariableRecursive(v, blk)
+}
+
+func (b *builder) readVariableRecursive(v *variable, blk *Block) *Value {
+	var val *Value
+	switch {
+	case !b.sealed[blk]:
+		phi := blk.NewPhi(v.typ)
+		if b.incomplete[blk] == nil {
+			b.incomplete[blk] = make(map[*variable]*Value)
+		}
+		b.incomplete[blk][v] = phi
+		val = phi
+	case len(blk.Preds) == 0:
+		val = b.undefined(v)
+	case len(blk.Preds) == 1:
+		val = b.readVariable(v, blk.Preds[0])
+	default:
+		phi := blk.NewPhi(v.typ)
+		b.writeVariable(v, blk, phi)
+		val = b.addPhiOperands(v, phi)
+	}
+	b.writeVariable(v, blk, val)
+	return val
+}
+
+func (b *builder) addPhiOperands(v *variable, phi *Value) *Value {
+	for _, pred := range phi.Block.Preds {
+		phi.Args = append(phi.Args, b.readVariable(v, pred))
+	}
+	phi.Type = mergeTypes(phi.Args, phi)
+	if !v.boxed && v.decl != nil {
+		nameValue(phi, v.name)
+	}
+	return b.removeTrivialPhi(phi)
+}
+
+// removeTrivialPhi replaces a phi whose arguments are all the same value
+// (or the phi itself) by that value
+func (b *builder) removeTrivialPhi(phi *Value) *Value {
+	var same *Value
+	for _, arg := range phi.Args {
+		if arg == same || arg == phi {
+			continue
+		}
+		if same != nil {
+			return phi
+		}
+		same = arg
+	}
+	if same == nil {
+		// Unreachable or read before any definition
+		same = b.entryValue(OpConst, nil, sema.NilType)
+	}
+
+	var users []*Value
+	for _, blk := range b.f.Blocks {
+		for _, w := range blk.Values {
+			if w.Op == OpPhi && w != phi {
+				for _, arg := range w.Args {
+					if arg == phi {
+						users = append(users, w)
+						break
+					}
+				}
+			}
+		}
+	}
+	b.f.ReplaceUses(phi, same)
+	phi.Block.removeValue(phi)
+	for _, defs := range b.defs {
+		for blk, val := range defs {
+			if val == phi {
+				defs[blk] = same
+			}
+		}
+	}
+	for _, phis := range b.incomplete {
+		for v, val := range phis {
+			if val == phi {
+				phis[v] = same
+			}
+		}
+	}
+
+	for _, user := range users {
+		if user.Block != nil && containsValue(user.Block.Values, user) {
+			b.removeTrivialPhi(user)
+		}
+	}
+	return same
+}
+
+func containsValue(values []*Value, v *Value) bool {
+	for _, w := range values {
+		if w == v {
+			return true
+		}
+	}
+	return false
+}
+
+// seal completes the phis of blk once all its predecessors are known
+func (b *builder) seal(blk *Block) {
+	if b.sealed[blk] {
+		return
+	}
+	b.sealed[blk] = true
+	phis := b.incomplete[blk]
+	delete(b.incomplete, blk)
+	vars := make([]*variable, 0, len(phis))
+	for v := range phis {
+		vars = append(vars, v)
+	}
+	sort.Slice(vars, func(i, j int) bool { return phis[vars[i]].ID < phis[vars[j]].ID })
+	for _, v := range vars {
+		b.addPhiOperands(v, phis[v])
+	}
+}
+
+// mergeTypes is the type of a phi of args: their type if they agree
+func mergeTypes(args []*Value, phi *Value) sema.Type {
+	var t sema.Type
+	for _, arg := range args {
+		if arg == phi {
+			continue
+		}
+		switch {
+		case t == nil:
+			t = arg.Type
+		case !t.Equals(arg.Type):
+			return sema.AnyType
+		}
+	}
+	if t == nil {
+		return sema.AnyType
+	}
+	return t
+}
+
+// temporary is a variable the lowering itself introduces, to merge the
+// value of an expression computed on several paths
+func (b *builder) temporary(name string) *variable {
+	return &variable{name: name, owner: b, typ: sema.AnyType}
+}
+
+// removeUnreachable drops the blocks nothing reaches from the entry
+func removeUnreachable(f *Func) {
+	reached := make(map[*Block]bool)
+	var visit func(b *Block)
+	visit = func(b *Block) {
+		if reached[b] {
+			return
+		}
+		reached[b] = true
+		for _, succ := range b.Succs {
+			visit(succ)
+		}
+	}
+	visit(f.Entry())
+
+	kept := f.Blocks[:0]
+	for _, b := range f.Blocks {
+		if reached[b] {
+			kept = append(kept, b)
+			continue
+		}
+		for _, succ := range b.Succs {
+			if reached[succ] {
+				succ.removePred(b)
+			}
+		}
+	}
+	f.Blocks = kept
+}
+
+// removePred drops the edge from pred, with the phi arguments for it
+func (b *Block) removePred(pred *Block) {
+	i := b.predIndex(pred)
+	if i < 0 {
+		return
+	}
+	b.Preds = append(b.Preds[:i], b.Preds[i+1:]...)
+	for _, v := range b.Values {
+		if v.Op == OpPhi && i < len(v.Args) {
+			v.Args = append(v.Args[:i], v.Args[i+1:]...)
+		}
+	}
+}
+
+// RemoveSucc drops the edge to the i-th successor of b
+func (b *Block) RemoveSucc(i int) {
+	succ := b.Succs[i]
+	b.Succs = append(b.Succs[:i], b.Succs[i+1:]...)
+	succ.removePred(b)
+}
+
+// RemoveUnreachable drops the blocks nothing reaches from the entry
+func (f *Func) RemoveUnreachable() {
+	removeUnreachable(f)
+}
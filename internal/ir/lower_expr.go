@@ -0,0 +1,375 @@
+package ir
+
+import (
+	"sort"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/sema"
+)
+
+// binaryOps maps operators to ops
+var binaryOps = map[string]Op{
+	"+":  OpAdd,
+	"-":  OpSub,
+	"*":  OpMul,
+	"/":  OpDiv,
+	"%":  OpMod,
+	"==": OpEq,
+	"!=": OpNe,
+	"<":  OpLt,
+	"<=": OpLe,
+	">":  OpGt,
+	">=": OpGe,
+}
+
+func (b *builder) expr(expr ast.Expression) (*Value, error) {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		return b.constant(e.Value), nil
+
+	case *ast.FloatLiteral:
+		return b.constant(e.Value), nil
+
+	case *ast.StringLiteral:
+		return b.constant(e.Value), nil
+
+	case *ast.BooleanLiteral:
+		return b.constant(e.Value), nil
+
+	case *ast.Identifier:
+		return b.get(e), nil
+
+	case *ast.ListLiteral:
+		elems, err := b.exprs(e.Elements)
+		if err != nil {
+			return nil, err
+		}
+		return b.emit(OpList, &sema.ListType{ElementType: commonType(elems)}, elems...), nil
+
+	case *ast.DictLiteral:
+		// Pairs are evaluated in source order
+		keys := make([]ast.Expression, 0, len(e.Pairs))
+		for key := range e.Pairs {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			a, b := keys[i].Pos(), keys[j].Pos()
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Column < b.Column
+		})
+		var pairs, values []*Value
+		for _, key := range keys {
+			k, err := b.expr(key)
+			if err != nil {
+				return nil, err
+			}
+			v, err := b.expr(e.Pairs[key])
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, k, v)
+			values = append(values, v)
+		}
+		t := &sema.DictType{KeyType: sema.StringType, ValueType: commonType(values)}
+		return b.emit(OpDict, t, pairs...), nil
+
+	case *ast.IndexExpression:
+		operands, err := b.exprs([]ast.Expression{e.Left, e.Index})
+		if err != nil {
+			return nil, err
+		}
+		return b.emit(OpGetIndex, elementType(operands[0].Type), operands...), nil
+
+	case *ast.MemberExpression:
+		object, err := b.expr(e.Object)
+		if err != nil {
+			return nil, err
+		}
+		return b.emitAux(OpGetMember, e.Member.Value, sema.AnyType, object), nil
+
+	case *ast.InfixExpression:
+		return b.infixExpression(e)
+
+	case *ast.PrefixExpression:
+		right, err := b.expr(e.Right)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Operator {
+		case "!":
+			return b.emit(OpNot, sema.BoolType, right), nil
+		case "-":
+			return b.emit(OpNeg, numericType(right.Type), right), nil
+		case "+":
+			return b.emit(OpPos, numericType(right.Type), right), nil
+		}
+		return nil, errorf(e, "unknown prefix operator: %s", e.Operator)
+
+	case *ast.CallExpression:
+		return b.callExpression(e)
+
+	case *ast.EnumConstructorExpression:
+		enum := b.get(&ast.Identifier{Token: e.Token, Value: e.EnumName})
+		ctor := b.emitAux(OpGetMember, e.Variant, sema.AnyType, enum)
+		args, err := b.exprs(e.Args)
+		if err != nil {
+			return nil, err
+		}
+		return b.emit(OpCall, sema.AnyType, append([]*Value{ctor}, args...)...), nil
+
+	case *ast.LambdaExpression:
+		return b.function("lambda", e.Parameters, e.Body, e.ReturnType, false, false, false)
+
+	case *ast.ArrowExpression:
+		return b.expr(e.Right)
+
+	case *ast.MatchExpression:
+		return b.match(e.Value, e.Arms)
+
+	case *ast.AwaitExpression:
+		value, err := b.expr(e.Expression)
+		if err != nil {
+			return nil, err
+		}
+		return b.emit(OpAwait, sema.AnyType, value), nil
+
+	case *ast.YieldExpression:
+		value, err := b.value(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		return b.emit(OpYield, sema.AnyType, value), nil
+	}
+	return nil, errorf(expr, "unsupported expression: %T", expr)
+}
+
+func (b *builder) exprs(exprs []ast.Expression) ([]*Value, error) {
+	values := make([]*Value, len(exprs))
+	for i, expr := range exprs {
+		v, err := b.expr(expr)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func (b *builder) infixExpression(expr *ast.InfixExpression) (*Value, error) {
+	switch expr.Operator {
+	case "=", "+=", "-=", "*=", "/=", "%=":
+		return b.assignExpression(expr)
+	case "&&", "||":
+		return b.logicalExpression(expr)
+	}
+
+	op, ok := binaryOps[expr.Operator]
+	if !ok {
+		return nil, errorf(expr, "unknown operator: %s", expr.Operator)
+	}
+	operands, err := b.exprs([]ast.Expression{expr.Left, expr.Right})
+	if err != nil {
+		return nil, err
+	}
+	return b.binary(op, operands[0], operands[1]), nil
+}
+
+func (b *builder) binary(op Op, x, y *Value) *Value {
+	return b.emit(op, binaryType(op, x.Type, y.Type), x, y)
+}
+
+// binaryType is the type of x op y, by the rules of the checker
+func binaryType(op Op, x, y sema.Type) sema.Type {
+	switch op {
+	case OpEq, OpNe, OpLt, OpLe, OpGt, OpGe:
+		return sema.BoolType
+	}
+	switch {
+	case x == sema.FloatType && isNumber(y), isNumber(x) && y == sema.FloatType:
+		return sema.FloatType
+	case x == sema.IntType && y == sema.IntType:
+		return sema.IntType
+	case op == OpAdd && x == sema.StringType && y == sema.StringType:
+		return sema.StringType
+	}
+	return sema.AnyType
+}
+
+func numericType(t sema.Type) sema.Type {
+	if isNumber(t) {
+		return t
+	}
+	return sema.AnyType
+}
+
+// commonType is the type all values have, or any
+func commonType(values []*Value) sema.Type {
+	if len(values) == 0 {
+		return sema.AnyType
+	}
+	t := values[0].Type
+	for _, v := range values[1:] {
+		if !t.Equals(v.Type) {
+			return sema.AnyType
+		}
+	}
+	return t
+}
+
+// elementType is the type of an element of a value of type t
+func elementType(t sema.Type) sema.Type {
+	switch t := t.(type) {
+	case *sema.ListType:
+		return t.ElementType
+	case *sema.DictType:
+		return t.ValueType
+	}
+	if t == sema.StringType {
+		return sema.StringType
+	}
+	return sema.AnyType
+}
+
+// logicalExpression lowers && and || to branches; the result is a bool,
+// like in the interpreter
+func (b *builder) logicalExpression(expr *ast.InfixExpression) (*Value, error) {
+	left, err := b.expr(expr.Left)
+	if err != nil {
+		return nil, err
+	}
+	result := b.temporary(expr.Operator)
+	b.writeVariable(result, b.cur(), b.emit(OpBool, sema.BoolType, left))
+
+	right, join := b.newBlock(), b.newBlock()
+	if expr.Operator == "&&" {
+		b.branch(left, right, join)
+	} else {
+		b.branch(left, join, right)
+	}
+	b.seal(right)
+	b.start(right)
+	value, err := b.expr(expr.Right)
+	if err != nil {
+		return nil, err
+	}
+	b.writeVariable(result, b.cur(), b.emit(OpBool, sema.BoolType, value))
+	b.jump(join)
+	b.seal(join)
+	b.start(join)
+	return b.readVariable(result, b.cur()), nil
+}
+
+// assignExpression lowers assignments to variables, members and indexes.
+// The assignment evaluates to the assigned value.
+func (b *builder) assignExpression(expr *ast.InfixExpression) (*Value, error) {
+	compound := expr.Operator != "="
+	var op Op
+	if compound {
+		op = binaryOps[expr.Operator[:1]]
+	}
+
+	switch target := expr.Left.(type) {
+	case *ast.Identifier:
+		var current *Value
+		if compound {
+			current = b.get(target)
+		}
+		value, err := b.expr(expr.Right)
+		if err != nil {
+			return nil, err
+		}
+		if compound {
+			value = b.binary(op, current, value)
+		}
+		b.set(target, value)
+		return value, nil
+
+	case *ast.MemberExpression:
+		object, err := b.expr(target.Object)
+		if err != nil {
+			return nil, err
+		}
+		var current *Value
+		if compound {
+			current = b.emitAux(OpGetMember, target.Member.Value, sema.AnyType, object)
+		}
+		value, err := b.expr(expr.Right)
+		if err != nil {
+			return nil, err
+		}
+		if compound {
+			value = b.binary(op, current, value)
+		}
+		b.emitAux(OpSetMember, target.Member.Value, sema.VoidType, object, value)
+		return value, nil
+
+	case *ast.IndexExpression:
+		operands, err := b.exprs([]ast.Expression{target.Left, target.Index})
+		if err != nil {
+			return nil, err
+		}
+		var current *Value
+		if compound {
+			current = b.emit(OpGetIndex, elementType(operands[0].Type), operands...)
+		}
+		value, err := b.expr(expr.Right)
+		if err != nil {
+			return nil, err
+		}
+		if compound {
+			value = b.binary(op, current, value)
+		}
+		b.emit(OpSetIndex, sema.VoidType, operands[0], operands[1], value)
+		return value, nil
+	}
+	return nil, errorf(expr, "invalid assignment target: %s", expr.Left.String())
+}
+
+func (b *builder) callExpression(expr *ast.CallExpression) (*Value, error) {
+	if member, ok := expr.Function.(*ast.MemberExpression); ok {
+		// Methods are called on their receiver without a bound method
+		var receiver *Value
+		op := OpInvoke
+		if ident, ok := member.Object.(*ast.Identifier); ok && ident.Value == "super" && b.inMethod() {
+			op = OpSuper
+			receiver = b.get(&ast.Identifier{Token: ident.Token, Value: "self"})
+		} else {
+			v, err := b.expr(member.Object)
+			if err != nil {
+				return nil, err
+			}
+			receiver = v
+		}
+		args, err := b.exprs(expr.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		return b.emitAux(op, member.Member.Value, sema.AnyType, append([]*Value{receiver}, args...)...), nil
+	}
+
+	callee, err := b.expr(expr.Function)
+	if err != nil {
+		return nil, err
+	}
+	args, err := b.exprs(expr.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	var result sema.Type = sema.AnyType
+	if ft, ok := callee.Type.(*sema.FunctionType); ok && ft.ReturnType != nil && ft.ReturnType != sema.VoidType {
+		result = ft.ReturnType
+	}
+	return b.emit(OpCall, result, append([]*Value{callee}, args...)...), nil
+}
+
+// inMethod reports whether self is visible from the code being lowered
+func (b *builder) inMethod() bool {
+	for fb := b; fb != nil; fb = fb.parent {
+		if fb.method {
+			return true
+		}
+	}
+	return false
+}
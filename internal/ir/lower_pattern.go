@@ -0,0 +1,276 @@
+package ir
+
+import (
+	"fmt"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/sema"
+)
+
+// match lowers a match to a chain of arms. Each arm tests its pattern
+// and guard, jumping to the next arm when one fails; the arm values meet
+// in a phi.
+func (b *builder) match(value ast.Expression, arms []*ast.MatchArm) (*Value, error) {
+	subject, err := b.expr(value)
+	if err != nil {
+		return nil, err
+	}
+	result := b.temporary("match")
+	join := b.newBlock()
+
+	for _, arm := range arms {
+		next := b.newBlock()
+		b.enterScope()
+		err := b.pattern(arm.Pattern, subject, next)
+		if err == nil && arm.Guard != nil {
+			var cond *Value
+			if cond, err = b.expr(arm.Guard); err == nil {
+				b.test(cond, next)
+			}
+		}
+		if err == nil {
+			err = b.armBody(arm.Body, result)
+		}
+		b.leaveScope()
+		if err != nil {
+			return nil, err
+		}
+		b.jump(join)
+		b.seal(next)
+		b.start(next)
+	}
+	if b.current != nil {
+		b.throw(b.constant("non-exhaustive match: no pattern matched"))
+	}
+
+	b.seal(join)
+	b.start(join)
+	if b.current == nil {
+		return b.constant(nil), nil
+	}
+	return b.readVariable(result, b.cur()), nil
+}
+
+// armBody lowers an arm body; its value is the last expression statement
+func (b *builder) armBody(body *ast.BlockStatement, result *variable) error {
+	var value *Value
+	if body != nil && len(body.Statements) > 0 {
+		last := len(body.Statements) - 1
+		if err := b.stmts(body.Statements[:last]); err != nil {
+			return err
+		}
+		if exprStmt, ok := body.Statements[last].(*ast.ExpressionStatement); ok {
+			v, err := b.expr(exprStmt.Expression)
+			if err != nil {
+				return err
+			}
+			value = v
+		} else if err := b.stmt(body.Statements[last]); err != nil {
+			return err
+		}
+	}
+	if b.current == nil {
+		return nil
+	}
+	if value == nil {
+		value = b.constant(nil)
+	}
+	b.writeVariable(result, b.cur(), value)
+	return nil
+}
+
+// test continues where cond holds and goes to fail otherwise
+func (b *builder) test(cond *Value, fail *Block) {
+	yes := b.newBlock()
+	b.branch(cond, yes, fail)
+	b.seal(yes)
+	b.start(yes)
+}
+
+// pattern lowers the tests of pattern against subject, binding its
+// variables; where a test fails it goes to fail
+func (b *builder) pattern(pattern ast.Expression, subject *Value, fail *Block) error {
+	switch p := pattern.(type) {
+	case *ast.Identifier:
+		switch {
+		case p.Value == "_":
+			return nil
+		case p.Value == "nil" || p.Value == "null":
+			b.test(b.emit(OpEq, sema.BoolType, subject, b.constant(nil)), fail)
+			return nil
+		}
+		if enum, ok := b.l.variants[p.Value]; ok {
+			// Bare variant name matches the variant regardless of payload
+			info := &VariantInfo{Enum: enum, Name: p.Value, Arity: -1}
+			b.test(b.emitAux(OpIsVariant, info, sema.BoolType, subject), fail)
+			return nil
+		}
+		b.define(p.Value, p, subject)
+		return nil
+
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.BooleanLiteral, *ast.PrefixExpression:
+		literal, err := b.expr(p)
+		if err != nil {
+			return err
+		}
+		b.test(b.emit(OpEq, sema.BoolType, literal, subject), fail)
+		return nil
+
+	case *ast.CallExpression:
+		ident, ok := p.Function.(*ast.Identifier)
+		if !ok {
+			// Never matches
+			b.jump(fail)
+			return nil
+		}
+
+		// Class pattern without fields: Point()
+		if b.l.classes[ident.Value] {
+			if len(p.Arguments) > 0 {
+				b.throw(b.constant(fmt.Sprintf("class pattern %s needs field names: %s(field: pattern)", ident.Value, ident.Value)))
+				return nil
+			}
+			b.test(b.emit(OpIsInstance, sema.BoolType, subject, b.get(ident)), fail)
+			return nil
+		}
+
+		// Enum variant pattern: VariantName(args...)
+		info := &VariantInfo{Name: ident.Value, Arity: len(p.Arguments)}
+		b.test(b.emitAux(OpIsVariant, info, sema.BoolType, subject), fail)
+		for idx, arg := range p.Arguments {
+			err := b.subPattern(arg, fail, func() *Value {
+				payload := b.emit(OpPayload, sema.AnyType, subject)
+				payload.AuxInt = int64(idx)
+				return payload
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *ast.ListLiteral:
+		return b.listPattern(p, subject, fail)
+
+	case *ast.DictPattern:
+		// Listed keys must exist and match, other keys are ignored
+		b.test(b.emit(OpIsDict, sema.BoolType, subject), fail)
+		for idx, keyExpr := range p.Keys {
+			key, err := b.expr(keyExpr)
+			if err != nil {
+				return err
+			}
+			b.test(b.emit(OpHasKey, sema.BoolType, subject, key), fail)
+			err = b.subPattern(p.Values[idx], fail, func() *Value {
+				return b.emit(OpGetIndex, sema.AnyType, subject, key)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *ast.ClassPattern:
+		// Class pattern with fields: Point(x: 0, y: y)
+		b.test(b.emit(OpIsInstance, sema.BoolType, subject, b.get(p.Class)), fail)
+		for _, field := range p.Fields {
+			name := field.Name.Value
+			b.test(b.emitAux(OpHasField, name, sema.BoolType, subject), fail)
+			err := b.subPattern(field.Pattern, fail, func() *Value {
+				return b.emitAux(OpGetMember, name, sema.AnyType, subject)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *ast.OrPattern:
+		// First matching alternative wins; alternatives share bindings
+		matched := b.newBlock()
+		for idx, alt := range p.Alternatives {
+			next := fail
+			if idx < len(p.Alternatives)-1 {
+				next = b.newBlock()
+			}
+			if err := b.pattern(alt, subject, next); err != nil {
+				return err
+			}
+			b.jump(matched)
+			if next != fail {
+				b.seal(next)
+				b.start(next)
+			}
+		}
+		b.seal(matched)
+		b.start(matched)
+		return nil
+
+	case *ast.AsPattern:
+		if err := b.pattern(p.Pattern, subject, fail); err != nil {
+			return err
+		}
+		b.define(p.Name.Value, p.Name, subject)
+		return nil
+
+	case *ast.RestPattern:
+		b.throw(b.constant("rest pattern is only allowed inside a list pattern"))
+		return nil
+	}
+
+	b.throw(b.constant(fmt.Sprintf("unsupported pattern type: %T", pattern)))
+	return nil
+}
+
+// listPattern matches list elements; a rest element collects the
+// remaining elements and patterns after it match from the end of the list
+func (b *builder) listPattern(p *ast.ListLiteral, subject *Value, fail *Block) error {
+	restIdx := -1
+	for idx, elem := range p.Elements {
+		if _, ok := elem.(*ast.RestPattern); ok {
+			restIdx = idx
+		}
+	}
+
+	test := b.emit(OpIsList, sema.BoolType, subject)
+	test.AuxInt = int64(len(p.Elements))
+	if restIdx >= 0 {
+		test.AuxInt--
+		test.Aux = true
+	}
+	b.test(test, fail)
+
+	for idx, elem := range p.Elements {
+		if idx == restIdx {
+			rest := elem.(*ast.RestPattern)
+			if rest.Name != nil && rest.Name.Value != "_" {
+				slice := b.emitAux(OpSlice, len(p.Elements)-restIdx-1, subject.Type, subject)
+				slice.AuxInt = int64(restIdx)
+				b.define(rest.Name.Value, rest.Name, slice)
+			}
+			continue
+		}
+		index := idx
+		if restIdx >= 0 && idx > restIdx {
+			index = idx - len(p.Elements)
+		}
+		err := b.subPattern(elem, fail, func() *Value {
+			element := b.emit(OpElement, elementType(subject.Type), subject)
+			element.AuxInt = int64(index)
+			return element
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subPattern matches the part of the subject value extracts, unless the
+// pattern ignores it
+func (b *builder) subPattern(pattern ast.Expression, fail *Block, value func() *Value) error {
+	if ident, ok := pattern.(*ast.Identifier); ok && ident.Value == "_" {
+		return nil
+	}
+	return b.pattern(pattern, value(), fail)
+}
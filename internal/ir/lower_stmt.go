@@ -0,0 +1,556 @@
+package ir
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/sema"
+)
+
+func (b *builder) stmts(stmts []ast.Statement) error {
+	for _, stmt := range stmts {
+		if err := b.stmt(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *builder) block(block *ast.BlockStatement) error {
+	if block == nil {
+		return nil
+	}
+	return b.stmts(block.Statements)
+}
+
+func (b *builder) stmt(stmt ast.Statement) error {
+	switch s := stmt.(type) {
+	case *ast.LetStatement:
+		return b.defineValue(s.Name, s.Type, s.Value)
+	case *ast.ConstStatement:
+		return b.defineValue(s.Name, s.Type, s.Value)
+	case *ast.StaticPropertyStatement:
+		// Static properties are plain variables, like in the interpreter
+		return b.defineValue(s.Name, s.Type, s.Value)
+	case *ast.ReturnStatement:
+		return b.returnStatement(s)
+	case *ast.BreakStatement:
+		return b.loopJump(stmt, true)
+	case *ast.ContinueStatement:
+		return b.loopJump(stmt, false)
+	case *ast.ExpressionStatement:
+		_, err := b.expr(s.Expression)
+		return err
+	case *ast.BlockStatement:
+		return b.block(s)
+	case *ast.IfStatement:
+		return b.ifStatement(s)
+	case *ast.WhileStatement:
+		return b.whileStatement(s)
+	case *ast.ForStatement:
+		return b.forStatement(s)
+	case *ast.FunctionStatement:
+		return b.functionStatement(s)
+	case *ast.StaticMethodStatement:
+		// Static methods are plain functions, like in the interpreter
+		fn, err := b.function(s.Name.Value, s.Parameters, s.Body, s.ReturnType, false, false, false)
+		if err != nil {
+			return err
+		}
+		b.define(s.Name.Value, s.Name, fn)
+		return nil
+	case *ast.ClassStatement:
+		return b.classStatement(s.Name, s.SuperClasses, s.Interfaces, s.Body, false)
+	case *ast.AbstractClassStatement:
+		return b.classStatement(s.Name, s.SuperClasses, nil, s.Body, true)
+	case *ast.AbstractMethodStatement:
+		// Abstract methods only declare a signature
+		return nil
+	case *ast.InterfaceStatement:
+		return b.interfaceStatement(s)
+	case *ast.EnumStatement:
+		return b.enumStatement(s)
+	case *ast.ImportStatement:
+		// import foo.bar binds "bar", import foo as f binds "f"
+		name := s.Path[len(s.Path)-1]
+		if s.Alias != nil {
+			name = s.Alias.Value
+		}
+		mod := b.emitAux(OpImport, strings.Join(s.Path, "."), sema.AnyType)
+		b.define(name, s, mod)
+		return nil
+	case *ast.UnsafeStatement:
+		return b.block(s.Body)
+	case *ast.TryStatement:
+		return b.tryStatement(s)
+	case *ast.ThrowStatement:
+		value, err := b.expr(s.Value)
+		if err != nil {
+			return err
+		}
+		b.throw(value)
+		return nil
+	case *ast.MatchStatement:
+		arms := make([]*ast.MatchArm, len(s.Cases))
+		for i, c := range s.Cases {
+			arms[i] = &ast.MatchArm{Pattern: c.Pattern, Guard: c.Guard, Body: c.Body}
+		}
+		_, err := b.match(s.Expression, arms)
+		return err
+	default:
+		return errorf(stmt, "unknown statement type: %T", stmt)
+	}
+}
+
+// errorf reports an error at the position of node
+func errorf(node ast.Node, format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", node.Pos().Line, fmt.Sprintf(format, args...))
+}
+
+// defineValue defines name with the value of an optional expression. An
+// annotation types a value the lowering could not type itself.
+func (b *builder) defineValue(name *ast.Identifier, annotation ast.TypeAnnotation, expr ast.Expression) error {
+	value, err := b.value(expr)
+	if err != nil {
+		return err
+	}
+	if annotation != nil && value.Type == sema.AnyType && value.Op != OpConst {
+		value.Type = sema.ResolveType(annotation)
+	}
+	b.define(name.Value, name, value)
+	return nil
+}
+
+// value lowers an optional expression, nil if it is absent
+func (b *builder) value(expr ast.Expression) (*Value, error) {
+	if expr == nil {
+		return b.constant(nil), nil
+	}
+	return b.expr(expr)
+}
+
+func (b *builder) throw(value *Value) {
+	blk := b.cur()
+	blk.Kind = BlockThrow
+	blk.Control = value
+	b.current = nil
+}
+
+func (b *builder) returnStatement(stmt *ast.ReturnStatement) error {
+	var value *Value
+	if stmt.ReturnValue != nil {
+		v, err := b.expr(stmt.ReturnValue)
+		if err != nil {
+			return err
+		}
+		value = v
+	}
+	if err := b.unwind(0); err != nil {
+		return err
+	}
+	blk := b.cur()
+	blk.Kind = BlockReturn
+	blk.Control = value
+	b.current = nil
+	return nil
+}
+
+// unwind leaves the try statements open above depth: their handlers are
+// removed and their finally blocks run
+func (b *builder) unwind(depth int) error {
+	saved := b.tries
+	defer func() { b.tries = saved }()
+	for i := len(saved) - 1; i >= depth; i-- {
+		b.tries = saved[:i]
+		if saved[i].handler {
+			b.emit(OpEndTry, sema.VoidType)
+		}
+		if err := b.block(saved[i].finally); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *builder) loopJump(stmt ast.Statement, isBreak bool) error {
+	if len(b.loops) == 0 {
+		return errorf(stmt, "%s outside loop", stmt.TokenLiteral())
+	}
+	loop := b.loops[len(b.loops)-1]
+	if err := b.unwind(loop.tries); err != nil {
+		return err
+	}
+	if isBreak {
+		b.jump(loop.brk)
+	} else {
+		b.jump(loop.cont)
+	}
+	return nil
+}
+
+func (b *builder) ifStatement(stmt *ast.IfStatement) error {
+	join := b.newBlock()
+	conds := []ast.Expression{stmt.Condition}
+	bodies := []*ast.BlockStatement{stmt.Consequence}
+	for _, elif := range stmt.Elif {
+		conds = append(conds, elif.Condition)
+		bodies = append(bodies, elif.Consequence)
+	}
+
+	for i, cond := range conds {
+		c, err := b.expr(cond)
+		if err != nil {
+			return err
+		}
+		then, next := b.newBlock(), b.newBlock()
+		b.branch(c, then, next)
+		b.seal(then)
+		b.seal(next)
+		b.start(then)
+		if err := b.block(bodies[i]); err != nil {
+			return err
+		}
+		b.jump(join)
+		b.start(next)
+	}
+	if err := b.block(stmt.Alternative); err != nil {
+		return err
+	}
+	b.jump(join)
+	b.seal(join)
+	b.start(join)
+	return nil
+}
+
+func (b *builder) whileStatement(stmt *ast.WhileStatement) error {
+	header, body, exit := b.newBlock(), b.newBlock(), b.newBlock()
+	b.jump(header)
+	b.start(header)
+	cond, err := b.expr(stmt.Condition)
+	if err != nil {
+		return err
+	}
+	b.branch(cond, body, exit)
+	b.seal(body)
+
+	b.start(body)
+	if err := b.loopBody(stmt.Body, exit, header); err != nil {
+		return err
+	}
+	b.jump(header)
+	b.seal(header)
+	b.seal(exit)
+	b.start(exit)
+	return nil
+}
+
+func (b *builder) loopBody(body *ast.BlockStatement, brk, cont *Block) error {
+	b.loops = append(b.loops, loopTargets{brk: brk, cont: cont, tries: len(b.tries)})
+	err := b.block(body)
+	b.loops = b.loops[:len(b.loops)-1]
+	return err
+}
+
+func (b *builder) forStatement(stmt *ast.ForStatement) error {
+	iterable, err := b.expr(stmt.Iterable)
+	if err != nil {
+		return err
+	}
+	var elem sema.Type = sema.AnyType
+	if list, ok := iterable.Type.(*sema.ListType); ok {
+		elem = list.ElementType
+	}
+	it := b.emit(OpIter, sema.AnyType, iterable)
+
+	header, body, exit := b.newBlock(), b.newBlock(), b.newBlock()
+	b.jump(header)
+	b.start(header)
+	b.branch(b.emit(OpNext, sema.BoolType, it), body, exit)
+	b.seal(body)
+
+	b.start(body)
+	b.enterScope()
+	b.define(stmt.Iterator.Value, stmt.Iterator, b.emit(OpItem, elem, it))
+	err = b.loopBody(stmt.Body, exit, header)
+	b.leaveScope()
+	if err != nil {
+		return err
+	}
+	b.jump(header)
+	b.seal(header)
+	b.seal(exit)
+	b.start(exit)
+	return nil
+}
+
+// tryStatement installs a handler around the try block. Finally runs on
+// each way out: after the try or catch block, before return, break and
+// continue, and before an error from the try or catch block is raised
+// again.
+func (b *builder) tryStatement(stmt *ast.TryStatement) error {
+	done := b.newBlock()
+
+	b.tries = append(b.tries, tryFrame{finally: stmt.Finally, handler: true})
+	handler, err := b.enter(func() error { return b.block(stmt.TryBlock) }, done)
+	b.tries = b.tries[:len(b.tries)-1]
+	if err != nil {
+		return err
+	}
+
+	b.start(handler)
+	caught := b.emit(OpCatch, sema.AnyType)
+	switch {
+	case stmt.CatchClause != nil && stmt.Finally != nil:
+		// Errors in the catch block run finally too
+		b.tries = append(b.tries, tryFrame{finally: stmt.Finally, handler: true})
+		rethrow, err := b.enter(func() error { return b.catchBlock(stmt.CatchClause, caught) }, done)
+		b.tries = b.tries[:len(b.tries)-1]
+		if err != nil {
+			return err
+		}
+		b.start(rethrow)
+		again := b.emit(OpCatch, sema.AnyType)
+		if err := b.block(stmt.Finally); err != nil {
+			return err
+		}
+		b.throw(again)
+	case stmt.CatchClause != nil:
+		if err := b.catchBlock(stmt.CatchClause, caught); err != nil {
+			return err
+		}
+		b.jump(done)
+	default:
+		if err := b.block(stmt.Finally); err != nil {
+			return err
+		}
+		b.throw(caught)
+	}
+
+	b.seal(done)
+	b.start(done)
+	return b.block(stmt.Finally)
+}
+
+// enter lowers body under a new handler and returns the handler block;
+// the body ends by removing the handler and jumping to done
+func (b *builder) enter(body func() error, done *Block) (*Block, error) {
+	blk := b.cur()
+	inside, handler := b.newBlock(), b.newBlock()
+	blk.Kind = BlockTry
+	blk.AddEdge(inside)
+	blk.AddEdge(handler)
+	b.current = nil
+	b.seal(inside)
+	b.seal(handler)
+
+	b.start(inside)
+	if err := body(); err != nil {
+		return nil, err
+	}
+	if b.current != nil {
+		b.emit(OpEndTry, sema.VoidType)
+	}
+	b.jump(done)
+	return handler, nil
+}
+
+func (b *builder) catchBlock(clause *ast.CatchClause, caught *Value) error {
+	b.enterScope()
+	defer b.leaveScope()
+	if clause.ErrorVar != nil {
+		b.define(clause.ErrorVar.Value, clause.ErrorVar, caught)
+	}
+	return b.block(clause.Body)
+}
+
+func (b *builder) functionStatement(stmt *ast.FunctionStatement) error {
+	name := stmt.Name.Value
+
+	// Local functions are declared first so they can call themselves
+	if !b.isGlobalScope() {
+		b.declare(name, stmt.Name, sema.AnyType)
+	}
+
+	// Decorators are read outermost first and applied innermost first
+	decorators := make([]*Value, len(stmt.Decorators))
+	for i, decorator := range stmt.Decorators {
+		decorators[i] = b.get(decorator.Name)
+	}
+
+	fn, err := b.function(name, stmt.Parameters, stmt.Body, stmt.ReturnType, stmt.Async, stmt.Coop, false)
+	if err != nil {
+		return err
+	}
+	for j := len(stmt.Decorators) - 1; j >= 0; j-- {
+		args := []*Value{decorators[j], fn}
+		for _, arg := range stmt.Decorators[j].Args {
+			v, err := b.expr(arg)
+			if err != nil {
+				return err
+			}
+			args = append(args, v)
+		}
+		fn = b.emit(OpCall, sema.AnyType, args...)
+	}
+
+	if !b.isGlobalScope() {
+		b.set(stmt.Name, fn)
+		return nil
+	}
+	b.define(name, stmt.Name, fn)
+	return nil
+}
+
+// function lowers a nested function and returns the closure creating it
+func (b *builder) function(name string, params []*ast.FunctionParameter, body *ast.BlockStatement,
+	result ast.TypeAnnotation, async, coop, method bool) (*Value, error) {
+	f := b.l.newFunc(name, b.f)
+	f.Async, f.Coop, f.Method = async, coop, method
+	if result != nil {
+		f.Result = sema.ResolveType(result)
+	}
+	inner := b.l.newBuilder(f, b)
+	inner.method = method
+
+	if method {
+		self := inner.emit(OpSelf, sema.AnyType)
+		inner.define("self", body, self)
+	}
+
+	// Parameters are locals; omitted arguments are nil
+	ftype := &sema.FunctionType{ReturnType: sema.AnyType, MinParams: len(params)}
+	if f.Result != nil {
+		ftype.ReturnType = f.Result
+	}
+	vars := make([]*ast.Identifier, len(params))
+	for idx, param := range params {
+		t := sema.ResolveType(param.Type)
+		op := OpParam
+		if param.Variadic {
+			op = OpRest
+			t = &sema.ListType{ElementType: t}
+			ftype.Variadic = true
+		}
+		v := inner.emit(op, t)
+		v.AuxInt = int64(idx)
+		v.Name = param.Name.Value
+		f.Params = append(f.Params, v)
+		ftype.Params = append(ftype.Params, t)
+		if (param.DefaultValue != nil || param.Variadic) && idx < ftype.MinParams {
+			ftype.MinParams = idx
+		}
+		inner.define(param.Name.Value, param.Name, v)
+		vars[idx] = param.Name
+	}
+
+	// Default values are evaluated when the caller omitted the argument
+	for idx, param := range params {
+		if param.DefaultValue == nil || param.Variadic {
+			continue
+		}
+		count := inner.emit(OpArgCount, sema.IntType)
+		missing := inner.emit(OpLe, sema.BoolType, count, inner.constant(int64(idx)))
+		dflt, join := inner.newBlock(), inner.newBlock()
+		inner.branch(missing, dflt, join)
+		inner.seal(dflt)
+		inner.start(dflt)
+		v, err := inner.expr(param.DefaultValue)
+		if err != nil {
+			return nil, err
+		}
+		inner.set(vars[idx], v)
+		inner.jump(join)
+		inner.seal(join)
+		inner.start(join)
+	}
+
+	if err := inner.block(body); err != nil {
+		return nil, err
+	}
+	inner.finish()
+
+	// The closure captures the boxes the function uses from enclosing ones
+	boxes := make([]*Value, 0, len(f.FreeVars))
+	for _, v := range inner.freeVars() {
+		boxes = append(boxes, b.boxFor(v))
+	}
+	return b.emitAux(OpClosure, f, ftype, boxes...), nil
+}
+
+// freeVars returns the captured variables in FreeVar order
+func (b *builder) freeVars() []*variable {
+	vars := make([]*variable, len(b.f.FreeVars))
+	for v, box := range b.free {
+		vars[box.AuxInt] = v
+	}
+	return vars
+}
+
+// classStatement lowers class and abstract class definitions
+func (b *builder) classStatement(name *ast.Identifier, superClasses, interfaces []*ast.Identifier,
+	body []ast.Statement, abstract bool) error {
+	if !b.isGlobalScope() {
+		b.declare(name.Value, name, sema.AnyType)
+	}
+
+	info := &ClassInfo{Name: name.Value, Supers: len(superClasses), Abstract: abstract}
+	var args []*Value
+	for _, super := range superClasses {
+		args = append(args, b.get(super))
+	}
+	for _, member := range body {
+		switch m := member.(type) {
+		case *ast.FunctionStatement:
+			method, err := b.function(name.Value+"."+m.Name.Value, m.Parameters, m.Body, m.ReturnType, m.Async, m.Coop, true)
+			if err != nil {
+				return err
+			}
+			info.Methods = append(info.Methods, m.Name.Value)
+			args = append(args, method)
+		case *ast.AbstractMethodStatement:
+			if !abstract {
+				return errorf(member, "unsupported class member: %T", member)
+			}
+		default:
+			return errorf(member, "unsupported class member: %T", member)
+		}
+	}
+	for _, iface := range interfaces {
+		info.Interfaces = append(info.Interfaces, iface.Value)
+		args = append(args, b.get(iface))
+	}
+
+	class := b.emitAux(OpClass, info, sema.AnyType, args...)
+	if !b.isGlobalScope() {
+		b.set(name, class)
+		return nil
+	}
+	b.define(name.Value, name, class)
+	return nil
+}
+
+func (b *builder) interfaceStatement(stmt *ast.InterfaceStatement) error {
+	info := &InterfaceInfo{Name: stmt.Name.Value}
+	parents := make([]*Value, len(stmt.Extends))
+	for i, parent := range stmt.Extends {
+		parents[i] = b.get(parent)
+	}
+	for _, method := range stmt.Methods {
+		info.Methods = append(info.Methods, method.Name.Value)
+		info.Arity = append(info.Arity, len(method.Parameters))
+	}
+	b.define(stmt.Name.Value, stmt.Name, b.emitAux(OpInterface, info, sema.AnyType, parents...))
+	return nil
+}
+
+func (b *builder) enumStatement(stmt *ast.EnumStatement) error {
+	ctors := make([]*Value, len(stmt.Variants))
+	for i, variant := range stmt.Variants {
+		info := &VariantInfo{Enum: stmt.Name.Value, Name: variant.Name.Value, Arity: len(variant.Payload)}
+		ctors[i] = b.emitAux(OpVariant, info, sema.AnyType)
+		b.define(variant.Name.Value, variant.Name, ctors[i])
+	}
+	b.define(stmt.Name.Value, stmt.Name, b.emitAux(OpEnum, stmt.Name.Value, sema.AnyType, ctors...))
+	return nil
+}
@@ -0,0 +1,181 @@
+package ir
+
+import "strconv"
+
+// Op is the operation computing a value
+type Op int
+
+const (
+	OpInvalid Op = iota
+
+	// Constants and function inputs
+	OpConst    // Aux: int64, float64, string, bool or nil
+	OpParam    // argument AuxInt, nil if it is missing
+	OpRest     // arguments from AuxInt on, as a list
+	OpArgCount // number of arguments passed
+	OpSelf     // receiver of a method
+	OpPhi      // argument i is the value coming from predecessor i
+
+	// Variables
+	OpGlobal    // global Aux
+	OpSetGlobal // global Aux = Args[0]
+	OpAlloc     // new box for variable Aux
+	OpLoad      // value in box Args[0]
+	OpStore     // box Args[0] = Args[1]
+	OpFreeVar   // box AuxInt captured by the closure
+
+	// Operators
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpMod
+	OpEq
+	OpNe
+	OpLt
+	OpLe
+	OpGt
+	OpGe
+	OpNeg
+	OpPos
+	OpNot
+	OpBool // truthiness of Args[0]
+
+	// Calls and objects
+	OpCall      // Args[0](Args[1:]...)
+	OpInvoke    // Args[0].Aux(Args[1:]...)
+	OpSuper     // superclass method Aux called on Args[0] with Args[1:]
+	OpGetMember // Args[0].Aux
+	OpSetMember // Args[0].Aux = Args[1]
+	OpGetIndex  // Args[0][Args[1]]
+	OpSetIndex  // Args[0][Args[1]] = Args[2]
+	OpList      // list of Args
+	OpDict      // dict of key, value pairs in Args
+	OpClosure   // function Aux capturing the boxes in Args
+	OpClass     // class Aux (*ClassInfo) of the superclasses and methods in Args
+	OpInterface // interface Aux (*InterfaceInfo) extending Args
+	OpVariant   // constructor of enum variant Aux (*VariantInfo)
+	OpEnum      // enum Aux of the variants in Args
+	OpImport    // module at path Aux
+
+	// Iteration
+	OpIter // iterator over Args[0]
+	OpNext // advances iterator Args[0], false at the end
+	OpItem // current item of iterator Args[0]
+
+	// Suspension
+	OpAwait
+	OpYield
+
+	// Errors
+	OpEndTry // leaves the innermost try block
+	OpCatch  // error caught by the handler the block starts
+
+	// Pattern tests
+	OpIsVariant  // Args[0] is variant Aux (*VariantInfo); arity -1 matches any
+	OpPayload    // payload AuxInt of variant Args[0]
+	OpIsList     // Args[0] is a list of AuxInt elements, or more if Aux is true
+	OpElement    // element AuxInt of list Args[0], from the end if negative
+	OpSlice      // elements of Args[0] from AuxInt up to Aux (int) from the end
+	OpIsDict     // Args[0] is a dict
+	OpHasKey     // dict Args[0] has key Args[1]
+	OpIsInstance // Args[0] is an instance of class Args[1]
+	OpHasField   // instance Args[0] has field Aux
+
+	opCount
+)
+
+// opInfo describes an op; pure ops have no side effects and cannot fail
+type opInfo struct {
+	name   string
+	pure   bool
+	auxInt bool // AuxInt is printed
+}
+
+var opInfos = [opCount]opInfo{
+	OpInvalid:    {name: "invalid"},
+	OpConst:      {name: "const", pure: true},
+	OpParam:      {name: "param", pure: true, auxInt: true},
+	OpRest:       {name: "rest", pure: true, auxInt: true},
+	OpArgCount:   {name: "argcount", pure: true},
+	OpSelf:       {name: "self", pure: true},
+	OpPhi:        {name: "phi", pure: true},
+	OpGlobal:     {name: "global"},
+	OpSetGlobal:  {name: "setglobal"},
+	OpAlloc:      {name: "alloc", pure: true},
+	OpLoad:       {name: "load", pure: true},
+	OpStore:      {name: "store"},
+	OpFreeVar:    {name: "freevar", pure: true, auxInt: true},
+	OpAdd:        {name: "add"},
+	OpSub:        {name: "sub"},
+	OpMul:        {name: "mul"},
+	OpDiv:        {name: "div"},
+	OpMod:        {name: "mod"},
+	OpEq:         {name: "eq"},
+	OpNe:         {name: "ne"},
+	OpLt:         {name: "lt"},
+	OpLe:         {name: "le"},
+	OpGt:         {name: "gt"},
+	OpGe:         {name: "ge"},
+	OpNeg:        {name: "neg"},
+	OpPos:        {name: "pos", pure: true},
+	OpNot:        {name: "not", pure: true},
+	OpBool:       {name: "bool", pure: true},
+	OpCall:       {name: "call"},
+	OpInvoke:     {name: "invoke"},
+	OpSuper:      {name: "super"},
+	OpGetMember:  {name: "getmember"},
+	OpSetMember:  {name: "setmember"},
+	OpGetIndex:   {name: "getindex"},
+	OpSetIndex:   {name: "setindex"},
+	OpList:       {name: "list", pure: true},
+	OpDict:       {name: "dict"},
+	OpClosure:    {name: "closure", pure: true},
+	OpClass:      {name: "class"},
+	OpInterface:  {name: "interface"},
+	OpVariant:    {name: "variant", pure: true},
+	OpEnum:       {name: "enum", pure: true},
+	OpImport:     {name: "import"},
+	OpIter:       {name: "iter"},
+	OpNext:       {name: "next"},
+	OpItem:       {name: "item", pure: true},
+	OpAwait:      {name: "await"},
+	OpYield:      {name: "yield"},
+	OpEndTry:     {name: "endtry"},
+	OpCatch:      {name: "catch", pure: true},
+	OpIsVariant:  {name: "isvariant", pure: true},
+	OpPayload:    {name: "payload", auxInt: true},
+	OpIsList:     {name: "islist", pure: true, auxInt: true},
+	OpElement:    {name: "element", auxInt: true},
+	OpSlice:      {name: "slice", auxInt: true},
+	OpIsDict:     {name: "isdict", pure: true},
+	OpHasKey:     {name: "haskey", pure: true},
+	OpIsInstance: {name: "isinstance", pure: true},
+	OpHasField:   {name: "hasfield", pure: true},
+}
+
+func (op Op) String() string {
+	if op >= 0 && op < opCount {
+		return opInfos[op].name
+	}
+	return "invalid"
+}
+
+// VariantInfo names an enum variant; Enum is empty when the variant is
+// matched by name alone
+type VariantInfo struct {
+	Enum  string
+	Name  string
+	Arity int
+}
+
+func (v *VariantInfo) String() string {
+	name := v.Name
+	if v.Enum != "" {
+		name = v.Enum + "." + name
+	}
+	if v.Arity < 0 {
+		return name
+	}
+	return name + "/" + strconv.Itoa(v.Arity)
+}
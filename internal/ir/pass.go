@@ -0,0 +1,224 @@
+package ir
+
+import "fmt"
+
+// Pass transforms one function
+type Pass interface {
+	Name() string
+	// Run transforms f and reports whether it changed anything
+	Run(f *Func) bool
+}
+
+// PassManager runs passes over every function of a module in order
+type PassManager struct {
+	passes []Pass
+	// Verify checks every function after each pass that changed it
+	Verify bool
+}
+
+// NewPassManager returns a manager running passes
+func NewPassManager(passes ...Pass) *PassManager {
+	return &PassManager{passes: passes}
+}
+
+// Add appends a pass
+func (pm *PassManager) Add(p Pass) {
+	pm.passes = append(pm.passes, p)
+}
+
+// Passes returns the passes in the order they run
+func (pm *PassManager) Passes() []Pass {
+	return pm.passes
+}
+
+// Run applies the passes to m
+func (pm *PassManager) Run(m *Module) error {
+	for _, p := range pm.passes {
+		for _, f := range m.Funcs {
+			if !p.Run(f) || !pm.Verify {
+				continue
+			}
+			if err := Verify(f); err != nil {
+				return fmt.Errorf("after pass %s: %v", p.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// DeadCode removes unreachable blocks, phis whose arguments agree and
+// values nothing uses whose removal cannot change the program
+type DeadCode struct{}
+
+func (DeadCode) Name() string { return "deadcode" }
+
+func (DeadCode) Run(f *Func) bool {
+	changed := false
+	n := len(f.Blocks)
+	removeUnreachable(f)
+	if len(f.Blocks) != n {
+		changed = true
+	}
+
+	for progress := true; progress; {
+		progress = false
+
+		for _, b := range f.Blocks {
+			for _, v := range b.Values {
+				if v.Op != OpPhi {
+					continue
+				}
+				if same := trivialPhi(v); same != nil {
+					f.ReplaceUses(v, same)
+					b.removeValue(v)
+					progress = true
+					break
+				}
+			}
+		}
+
+		uses := f.Uses()
+		for _, b := range f.Blocks {
+			kept := b.Values[:0]
+			for _, v := range b.Values {
+				if uses[v] == 0 && !v.HasSideEffects() {
+					progress = true
+					continue
+				}
+				kept = append(kept, v)
+			}
+			b.Values = kept
+		}
+		changed = changed || progress
+	}
+
+	// A phi using only itself is dead too
+	for _, b := range f.Blocks {
+		kept := b.Values[:0]
+		for _, v := range b.Values {
+			if v.Op == OpPhi && onlySelfUses(f, v) {
+				changed = true
+				continue
+			}
+			kept = append(kept, v)
+		}
+		b.Values = kept
+	}
+	return changed
+}
+
+// trivialPhi returns the one value other than itself phi merges, or nil
+func trivialPhi(phi *Value) *Value {
+	var same *Value
+	for _, arg := range phi.Args {
+		if arg == same || arg == phi {
+			continue
+		}
+		if same != nil {
+			return nil
+		}
+		same = arg
+	}
+	return same
+}
+
+func onlySelfUses(f *Func, phi *Value) bool {
+	for _, b := range f.Blocks {
+		if b.Control == phi {
+			return false
+		}
+		for _, v := range b.Values {
+			if v == phi {
+				continue
+			}
+			for _, arg := range v.Args {
+				if arg == phi {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// Fuse merges a block into its only predecessor when that jumps straight
+// to it, and routes jumps around blocks that do nothing but jump
+type Fuse struct{}
+
+func (Fuse) Name() string { return "fuse" }
+
+func (Fuse) Run(f *Func) bool {
+	changed := false
+	for progress := true; progress; {
+		progress = false
+		for _, b := range f.Blocks {
+			if fuseBlock(f, b) || skipBlock(f, b) {
+				progress = true
+				changed = true
+				break
+			}
+		}
+	}
+	return changed
+}
+
+// fuseBlock appends b to its predecessor
+func fuseBlock(f *Func, b *Block) bool {
+	if b == f.Entry() || len(b.Preds) != 1 {
+		return false
+	}
+	p := b.Preds[0]
+	if p.Kind != BlockPlain || p == b {
+		return false
+	}
+	for _, v := range b.Values {
+		if v.Op == OpPhi {
+			f.ReplaceUses(v, v.Args[0])
+		}
+	}
+	for _, v := range b.Values {
+		if v.Op != OpPhi {
+			v.Block = p
+			p.Values = append(p.Values, v)
+		}
+	}
+	p.Kind, p.Control, p.Succs = b.Kind, b.Control, b.Succs
+	for _, succ := range b.Succs {
+		for i, pred := range succ.Preds {
+			if pred == b {
+				succ.Preds[i] = p
+			}
+		}
+	}
+	f.removeBlock(b)
+	return true
+}
+
+// skipBlock sends the predecessors of an empty block to its successor
+func skipBlock(f *Func, b *Block) bool {
+	if b == f.Entry() || b.Kind != BlockPlain || len(b.Values) > 0 || len(b.Preds) != 1 {
+		return false
+	}
+	p, succ := b.Preds[0], b.Succs[0]
+	if succ == b || succ.predIndex(p) >= 0 {
+		// The successor could not tell the two edges from p apart
+		return false
+	}
+	for i, s := range p.Succs {
+		if s == b {
+			p.Succs[i] = succ
+		}
+	}
+	succ.Preds[succ.predIndex(b)] = p
+	f.removeBlock(b)
+	return true
+}
+
+func (f *Func) removeBlock(b *Block) {
+	for i, x := range f.Blocks {
+		if x == b {
+			f.Blocks = append(f.Blocks[:i], f.Blocks[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package ir
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The text format lists each function with its blocks in order:
+//
+//	func fact(n) : int
+//	b0:
+//	  v1 = param 0 : any ; n
+//	  v2 = const 1 : int
+//	  v3 = le v1 v2 : bool
+//	  if v3 -> b1 b2
+//
+// Phis list their arguments in the order of the block's predecessors,
+// which the block header shows after "<-".
+
+// String returns the module in the text format
+func (m *Module) String() string {
+	var buf bytes.Buffer
+	m.Fprint(&buf)
+	return buf.String()
+}
+
+// Fprint writes the module in the text format
+func (m *Module) Fprint(w io.Writer) {
+	for i, f := range m.Funcs {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		f.Fprint(w)
+	}
+}
+
+// String returns the function in the text format
+func (f *Func) String() string {
+	var buf bytes.Buffer
+	f.Fprint(&buf)
+	return buf.String()
+}
+
+// Fprint writes the function in the text format
+func (f *Func) Fprint(w io.Writer) {
+	params := make([]string, len(f.Params))
+	for i, p := range f.Params {
+		params[i] = p.Name
+		if p.Op == OpRest {
+			params[i] = "..." + p.Name
+		}
+	}
+	fmt.Fprintf(w, "func %s(%s)", f.Name, strings.Join(params, ", "))
+	if f.Result != nil {
+		fmt.Fprintf(w, " : %s", f.Result)
+	}
+	var flags []string
+	if f.Async {
+		flags = append(flags, "async")
+	}
+	if f.Coop {
+		flags = append(flags, "coop")
+	}
+	if f.Method {
+		flags = append(flags, "method")
+	}
+	if len(f.FreeVars) > 0 {
+		flags = append(flags, "free("+strings.Join(f.FreeVars, ", ")+")")
+	}
+	if len(flags) > 0 {
+		fmt.Fprintf(w, " [%s]", strings.Join(flags, " "))
+	}
+	fmt.Fprintln(w)
+
+	for _, b := range f.Blocks {
+		fmt.Fprintf(w, "%s:", b)
+		if len(b.Preds) > 0 {
+			fmt.Fprintf(w, " <-")
+			for _, p := range b.Preds {
+				fmt.Fprintf(w, " %s", p)
+			}
+		}
+		fmt.Fprintln(w)
+		for _, v := range b.Values {
+			fmt.Fprintf(w, "  %s\n", v.LongString())
+		}
+		fmt.Fprintf(w, "  %s\n", b.terminator())
+	}
+}
+
+// LongString returns the definition of v
+func (v *Value) LongString() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s = %s", v, v.Op)
+	if opInfos[v.Op].auxInt {
+		fmt.Fprintf(&buf, " %d", v.AuxInt)
+	}
+	if aux := v.auxString(); aux != "" {
+		buf.WriteString(" " + aux)
+	}
+	for _, arg := range v.Args {
+		if arg == nil {
+			buf.WriteString(" <nil>")
+			continue
+		}
+		buf.WriteString(" " + arg.String())
+	}
+	fmt.Fprintf(&buf, " : %s", v.Type)
+	if v.Name != "" {
+		buf.WriteString(" ; " + v.Name)
+	}
+	return buf.String()
+}
+
+func (v *Value) auxString() string {
+	if v.Op == OpConst {
+		return constString(v.Aux)
+	}
+	switch aux := v.Aux.(type) {
+	case nil:
+		return ""
+	case string:
+		return aux
+	case *Func:
+		return "@" + aux.Name
+	case bool:
+		if aux {
+			return "rest"
+		}
+		return ""
+	default:
+		return fmt.Sprint(aux)
+	}
+}
+
+// constString formats a constant like a SKY literal
+func constString(c interface{}) string {
+	switch c := c.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return strconv.Quote(c)
+	case float64:
+		s := strconv.FormatFloat(c, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eEnN") {
+			s += ".0"
+		}
+		return s
+	default:
+		return fmt.Sprint(c)
+	}
+}
+
+func (b *Block) terminator() string {
+	switch b.Kind {
+	case BlockPlain:
+		if len(b.Succs) == 0 {
+			return "plain"
+		}
+		return fmt.Sprintf("plain -> %s", b.Succs[0])
+	case BlockIf:
+		return fmt.Sprintf("if %s -> %s %s", b.Control, b.Succs[0], b.Succs[1])
+	case BlockReturn:
+		if b.Control == nil {
+			return "return"
+		}
+		return fmt.Sprintf("return %s", b.Control)
+	case BlockThrow:
+		return fmt.Sprintf("throw %s", b.Control)
+	case BlockTry:
+		return fmt.Sprintf("try -> %s catch %s", b.Succs[0], b.Succs[1])
+	}
+	return b.Kind.String()
+}
@@ -0,0 +1,302 @@
+package ir
+
+import (
+	"fmt"
+
+	"github.com/mburakmmm/sky-lang/internal/sema"
+)
+
+// The SSA form is independent of any backend. A Module holds the
+// functions of one source file; the top-level code is the function
+// "<script>". Each Func is a graph of basic blocks holding Values. A Value
+// is defined once; where control flow merges, phi values pick the
+// definition of the predecessor the block was entered from.
+//
+// Local variables become SSA values unless a nested function captures
+// them or a try block assigns them; those live in boxes created by
+// OpAlloc and are read and written with OpLoad and OpStore. Top-level
+// variables are globals, accessed with OpGlobal and OpSetGlobal.
+
+// Module is a lowered source file
+type Module struct {
+	Name  string
+	Funcs []*Func // <script> first, then every function in definition order
+}
+
+// Main returns the function running the top-level code
+func (m *Module) Main() *Func {
+	return m.Funcs[0]
+}
+
+// Func is a function in SSA form
+type Func struct {
+	Name     string
+	Module   *Module
+	Parent   *Func    // enclosing function, nil for <script>
+	Params   []*Value // OpParam and OpRest values in the entry block
+	FreeVars []string // names of the boxes captured from enclosing functions
+	Blocks   []*Block // entry first
+	Async    bool
+	Coop     bool
+	Method   bool
+	Result   sema.Type // declared return type
+
+	nextValue int
+	nextBlock int
+}
+
+// Entry returns the block the function starts in
+func (f *Func) Entry() *Block {
+	return f.Blocks[0]
+}
+
+// NewBlock adds an empty block of the given kind
+func (f *Func) NewBlock(kind BlockKind) *Block {
+	b := &Block{ID: f.nextBlock, Kind: kind, Func: f}
+	f.nextBlock++
+	f.Blocks = append(f.Blocks, b)
+	return b
+}
+
+// Values calls fn for every value of the function in block order
+func (f *Func) Values(fn func(v *Value)) {
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			fn(v)
+		}
+	}
+}
+
+// NewConst adds the constant c to the entry block, where it is
+// available to every block
+func (f *Func) NewConst(c interface{}) *Value {
+	return f.entryValue(OpConst, c, constType(c))
+}
+
+// entryValue adds a value at the start of the entry block, after the
+// parameters
+func (f *Func) entryValue(op Op, aux interface{}, t sema.Type) *Value {
+	entry := f.Entry()
+	v := f.newValue(op, t, nil)
+	v.Aux = aux
+	v.Block = entry
+	n := 0
+	for n < len(entry.Values) {
+		switch entry.Values[n].Op {
+		case OpParam, OpRest, OpSelf, OpFreeVar:
+			n++
+			continue
+		}
+		break
+	}
+	entry.Values = append(entry.Values, nil)
+	copy(entry.Values[n+1:], entry.Values[n:])
+	entry.Values[n] = v
+	return v
+}
+
+// Uses counts the uses of every value by other values and block controls
+func (f *Func) Uses() map[*Value]int {
+	uses := make(map[*Value]int)
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			for _, arg := range v.Args {
+				uses[arg]++
+			}
+		}
+		if b.Control != nil {
+			uses[b.Control]++
+		}
+	}
+	return uses
+}
+
+// BlockKind says how a block ends
+type BlockKind int
+
+const (
+	BlockPlain  BlockKind = iota // jumps to Succs[0]
+	BlockIf                      // Succs[0] if Control is truthy, else Succs[1]
+	BlockReturn                  // returns Control, nil if it is absent
+	BlockThrow                   // raises Control
+	BlockTry                     // enters Succs[0]; errors raised there go to Succs[1]
+)
+
+var blockKindNames = [...]string{
+	BlockPlain:  "plain",
+	BlockIf:     "if",
+	BlockReturn: "return",
+	BlockThrow:  "throw",
+	BlockTry:    "try",
+}
+
+func (k BlockKind) String() string {
+	return blockKindNames[k]
+}
+
+// Block is a basic block
+type Block struct {
+	ID      int
+	Kind    BlockKind
+	Values  []*Value // phis first
+	Control *Value
+	Succs   []*Block
+	Preds   []*Block
+	Func    *Func
+}
+
+func (b *Block) String() string {
+	return fmt.Sprintf("b%d", b.ID)
+}
+
+// AddEdge adds an edge from b to succ
+func (b *Block) AddEdge(succ *Block) {
+	b.Succs = append(b.Succs, succ)
+	succ.Preds = append(succ.Preds, b)
+}
+
+// NewValue adds a value computed by op to the end of the block
+func (b *Block) NewValue(op Op, t sema.Type, args ...*Value) *Value {
+	v := b.Func.newValue(op, t, args)
+	v.Block = b
+	b.Values = append(b.Values, v)
+	return v
+}
+
+// NewPhi adds a phi without arguments after the other phis of the block
+func (b *Block) NewPhi(t sema.Type) *Value {
+	v := b.Func.newValue(OpPhi, t, nil)
+	v.Block = b
+	n := 0
+	for n < len(b.Values) && b.Values[n].Op == OpPhi {
+		n++
+	}
+	b.Values = append(b.Values, nil)
+	copy(b.Values[n+1:], b.Values[n:])
+	b.Values[n] = v
+	return v
+}
+
+// removeValue drops v from the block
+func (b *Block) removeValue(v *Value) {
+	for i, w := range b.Values {
+		if w == v {
+			b.Values = append(b.Values[:i], b.Values[i+1:]...)
+			return
+		}
+	}
+}
+
+// predIndex returns the position of pred among the predecessors of b
+func (b *Block) predIndex(pred *Block) int {
+	for i, p := range b.Preds {
+		if p == pred {
+			return i
+		}
+	}
+	return -1
+}
+
+func (f *Func) newValue(op Op, t sema.Type, args []*Value) *Value {
+	if t == nil {
+		t = sema.AnyType
+	}
+	f.nextValue++
+	return &Value{ID: f.nextValue, Op: op, Type: t, Args: args}
+}
+
+// Value is an SSA value
+type Value struct {
+	ID     int
+	Op     Op
+	Type   sema.Type
+	Args   []*Value
+	Aux    interface{} // constant, name, function or description, by Op
+	AuxInt int64
+	Block  *Block
+	Name   string // source variable the value was assigned to, if any
+}
+
+func (v *Value) String() string {
+	return fmt.Sprintf("v%d", v.ID)
+}
+
+// IsConst reports whether v is a constant
+func (v *Value) IsConst() bool {
+	return v.Op == OpConst
+}
+
+// HasSideEffects reports whether removing v could change what the program
+// does, including whether it fails
+func (v *Value) HasSideEffects() bool {
+	if opInfos[v.Op].pure {
+		return false
+	}
+	// Operators fail on operands they do not support; these never do
+	switch v.Op {
+	case OpAdd:
+		x, y := v.Args[0].Type, v.Args[1].Type
+		return !(sameNumber(x, y) || x == sema.StringType || y == sema.StringType)
+	case OpSub, OpMul:
+		return !sameNumber(v.Args[0].Type, v.Args[1].Type)
+	case OpLt, OpLe, OpGt, OpGe:
+		return v.Args[0].Type != sema.IntType || v.Args[1].Type != sema.IntType
+	case OpEq, OpNe:
+		x, y := v.Args[0].Type, v.Args[1].Type
+		return !(x == sema.NilType || y == sema.NilType || x == y && (x == sema.IntType || x == sema.StringType))
+	case OpNeg:
+		return !isNumber(v.Args[0].Type)
+	}
+	return true
+}
+
+// sameNumber reports whether x and y are both int or both float
+func sameNumber(x, y sema.Type) bool {
+	return isNumber(x) && x == y
+}
+
+// ReplaceUses makes every use of old in f use v instead
+func (f *Func) ReplaceUses(old, v *Value) {
+	for _, b := range f.Blocks {
+		for _, w := range b.Values {
+			for i, arg := range w.Args {
+				if arg == old {
+					w.Args[i] = v
+				}
+			}
+		}
+		if b.Control == old {
+			b.Control = v
+		}
+	}
+}
+
+func isNumber(t sema.Type) bool {
+	return t == sema.IntType || t == sema.FloatType
+}
+
+// ClassInfo describes the class an OpClass value creates. Its arguments
+// are the superclasses followed by the methods.
+type ClassInfo struct {
+	Name       string
+	Supers     int
+	Methods    []string
+	Interfaces []string
+	Abstract   bool
+}
+
+func (c *ClassInfo) String() string {
+	return c.Name
+}
+
+// InterfaceInfo describes the interface an OpInterface value creates. Its
+// arguments are the interfaces it extends.
+type InterfaceInfo struct {
+	Name    string
+	Methods []string
+	Arity   []int
+}
+
+func (i *InterfaceInfo) String() string {
+	return i.Name
+}
@@ -0,0 +1,310 @@
+package ir
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+	"github.com/mburakmmm/sky-lang/internal/parser"
+	"github.com/mburakmmm/sky-lang/internal/sema"
+)
+
+func TestPrint(t *testing.T) {
+	m := lower(t, `function fact(n: int): int
+  if n <= 1
+    return 1
+  return n * fact(n - 1)`)
+
+	want := `func fact(n) : int
+b0:
+  v1 = param 0 : int ; n
+  v2 = const 1 : int
+  v3 = le v1 v2 : bool
+  if v3 -> b1 b2
+b1: <- b0
+  v4 = const 1 : int
+  return v4
+b2: <- b0
+  plain -> b3
+b3: <- b2
+  v5 = global fact : (int) => int
+  v6 = const 1 : int
+  v7 = sub v1 v6 : int
+  v8 = call v5 v7 : int
+  v9 = mul v1 v8 : int
+  return v9
+`
+	if got := function(t, m, "fact").String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestLowerLoopPhis(t *testing.T) {
+	m := lower(t, `function total_of(): any
+  let total = 0
+  let i = 0
+  while i < 10
+    total = total + i
+    i = i + 1
+  return total`)
+
+	f := function(t, m, "total_of")
+	header := f.Blocks[1]
+	if len(header.Preds) != 2 {
+		t.Fatalf("loop header %s has %d predecessors, want 2", header, len(header.Preds))
+	}
+	names := map[string]bool{}
+	for _, v := range header.Values {
+		if v.Op == OpPhi {
+			names[v.Name] = true
+			if v.Type != sema.IntType {
+				t.Errorf("phi %s has type %s, want int", v.Name, v.Type)
+			}
+		}
+	}
+	if !names["total"] || !names["i"] || len(names) != 2 {
+		t.Errorf("loop header phis are %v, want total and i", names)
+	}
+}
+
+func TestLowerBoxes(t *testing.T) {
+	m := lower(t, `function counter(): any
+  let n = 0
+  function inc(): any
+    n = n + 1
+    return n
+  return inc
+
+function safe(x: int): any
+  let r = 0
+  try
+    r = 10 / x
+  catch e
+    r = -1
+  return r`)
+
+	if !hasOp(function(t, m, "counter"), OpAlloc) {
+		t.Error("captured variable is not boxed")
+	}
+	inc := function(t, m, "inc")
+	if len(inc.FreeVars) != 1 || inc.FreeVars[0] != "n" || !hasOp(inc, OpFreeVar) {
+		t.Errorf("inc captures %v, want [n]", inc.FreeVars)
+	}
+	safe := function(t, m, "safe")
+	if !hasOp(safe, OpAlloc) || !hasOp(safe, OpCatch) {
+		t.Error("variable assigned in try is not boxed")
+	}
+	if hasOp(safe, OpPhi) {
+		t.Error("boxed variable has phis")
+	}
+}
+
+func TestLowerMatch(t *testing.T) {
+	m := lower(t, `function kind(v: any): any
+  return match v
+    0 => "zero"
+    [a, b] => "pair"
+    _ => "other"
+  end`)
+
+	f := function(t, m, "kind")
+	last := f.Blocks[len(f.Blocks)-1]
+	if last.Kind != BlockReturn || last.Control == nil || last.Control.Op != OpPhi {
+		t.Fatalf("match result is not a phi:\n%s", f)
+	}
+	if n := len(last.Control.Args); n != 3 {
+		t.Errorf("phi merges %d arms, want 3", n)
+	}
+	if last.Control.Type != sema.StringType {
+		t.Errorf("match type is %s, want string", last.Control.Type)
+	}
+}
+
+// TestLowerTestdata lowers the backend conformance programs
+func TestLowerTestdata(t *testing.T) {
+	var files []string
+	for _, dir := range []string{"../gogen/testdata", "../cgen/testdata"} {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.sky"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		t.Skip("no programs found")
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		program := parse(t, string(content))
+		checker := sema.NewChecker()
+		checker.Check(program)
+		m, err := Lower(program, file, checker.Types())
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		if err := m.Verify(); err != nil {
+			t.Errorf("%s: %v", file, err)
+		}
+		pm := NewPassManager(DeadCode{})
+		pm.Verify = true
+		if err := pm.Run(m); err != nil {
+			t.Errorf("%s: %v", file, err)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	build := func() (*Func, *Block, *Block, *Block) {
+		f := &Func{Name: "f"}
+		entry := f.NewBlock(BlockIf)
+		yes, no, join := f.NewBlock(BlockPlain), f.NewBlock(BlockPlain), f.NewBlock(BlockReturn)
+		entry.Control = entry.NewValue(OpParam, sema.BoolType)
+		entry.AddEdge(yes)
+		entry.AddEdge(no)
+		yes.AddEdge(join)
+		no.AddEdge(join)
+		return f, yes, no, join
+	}
+
+	f, _, _, _ := build()
+	if err := Verify(f); err != nil {
+		t.Fatalf("valid function rejected: %v", err)
+	}
+
+	f, yes, _, join := build()
+	join.Control = yes.NewValue(OpConst, sema.IntType)
+	if err := Verify(f); err == nil || !strings.Contains(err.Error(), "dominate") {
+		t.Errorf("use not dominated by its definition: got %v", err)
+	}
+
+	f, yes, no, join := build()
+	phi := join.NewPhi(sema.IntType)
+	phi.Args = []*Value{yes.NewValue(OpConst, sema.IntType)}
+	join.Control = phi
+	if err := Verify(f); err == nil || !strings.Contains(err.Error(), "arguments") {
+		t.Errorf("phi with too few arguments: got %v", err)
+	}
+	phi.Args = append(phi.Args, no.NewValue(OpConst, sema.IntType))
+	if err := Verify(f); err != nil {
+		t.Errorf("valid phi rejected: %v", err)
+	}
+
+	f, yes, _, _ = build()
+	yes.Succs = nil
+	if err := Verify(f); err == nil {
+		t.Error("plain block without successor accepted")
+	}
+}
+
+func TestDominators(t *testing.T) {
+	m := lower(t, `function f(x: int): any
+  let y = 0
+  while x > 0
+    if x > 5
+      y = y + 2
+    else
+      y = y + 1
+    x = x - 1
+  return y`)
+
+	f := function(t, m, "f")
+	dom := ComputeDominators(f)
+	entry := f.Entry()
+	for _, b := range f.Blocks {
+		if !dom.Dominates(entry, b) {
+			t.Errorf("entry does not dominate %s", b)
+		}
+	}
+	header := f.Blocks[1]
+	for _, b := range f.Blocks[2:] {
+		if !dom.Dominates(header, b) {
+			t.Errorf("loop header does not dominate %s", b)
+		}
+		if dom.Dominates(b, header) {
+			t.Errorf("%s dominates the loop header", b)
+		}
+	}
+}
+
+func TestDeadCode(t *testing.T) {
+	m := lower(t, `function f(a: int, b: any): any
+  let unused = a + 1
+  let fails = b + 1
+  return a`)
+
+	f := function(t, m, "f")
+	if !(DeadCode{}).Run(f) {
+		t.Fatal("nothing removed")
+	}
+	if err := Verify(f); err != nil {
+		t.Fatal(err)
+	}
+	var adds int
+	f.Values(func(v *Value) {
+		if v.Op == OpAdd {
+			adds++
+			if v.Args[0].Type == sema.IntType {
+				t.Error("unused int addition kept")
+			}
+		}
+	})
+	if adds != 1 {
+		t.Errorf("%d additions left, want the one that can fail", adds)
+	}
+}
+
+func lower(t *testing.T, input string) *Module {
+	t.Helper()
+	program := parse(t, input)
+	checker := sema.NewChecker()
+	if errs := checker.Check(program); len(errs) > 0 {
+		t.Fatalf("check errors: %v", errs)
+	}
+	m, err := Lower(program, "test", checker.Types())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Verify(); err != nil {
+		t.Fatalf("%v\n%s", err, m)
+	}
+	return m
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input, "test.sky"))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parse errors: %v", p.Errors())
+	}
+	return program
+}
+
+func function(t *testing.T, m *Module, name string) *Func {
+	t.Helper()
+	for _, f := range m.Funcs {
+		if f.Name == name {
+			return f
+		}
+	}
+	t.Fatalf("no function %s in\n%s", name, m)
+	return nil
+}
+
+func hasOp(f *Func, op Op) bool {
+	found := false
+	f.Values(func(v *Value) {
+		if v.Op == op {
+			found = true
+		}
+	})
+	return found
+}
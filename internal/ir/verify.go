@@ -0,0 +1,202 @@
+package ir
+
+import "fmt"
+
+// Verify checks that m is well formed
+func (m *Module) Verify() error {
+	for _, f := range m.Funcs {
+		if err := Verify(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify checks that f is well formed: blocks end with the number of
+// successors their kind needs, edges are recorded at both ends, every
+// block is reachable, phis come first with one argument per predecessor,
+// and every value is defined once and dominates its uses.
+func Verify(f *Func) error {
+	if len(f.Blocks) == 0 {
+		return fmt.Errorf("%s: no blocks", f.Name)
+	}
+	if len(f.Entry().Preds) > 0 {
+		return fmt.Errorf("%s: entry %s has predecessors", f.Name, f.Entry())
+	}
+
+	blocks := make(map[*Block]bool, len(f.Blocks))
+	blockIDs := make(map[int]bool, len(f.Blocks))
+	defined := make(map[*Value]bool)
+	valueIDs := make(map[int]bool)
+	for _, b := range f.Blocks {
+		if blockIDs[b.ID] {
+			return fmt.Errorf("%s: block id %s used twice", f.Name, b)
+		}
+		blockIDs[b.ID] = true
+		blocks[b] = true
+		for _, v := range b.Values {
+			if valueIDs[v.ID] {
+				return fmt.Errorf("%s: value id %s used twice", f.Name, v)
+			}
+			valueIDs[v.ID] = true
+			defined[v] = true
+		}
+	}
+
+	for _, b := range f.Blocks {
+		if err := verifyEdges(b, blocks); err != nil {
+			return fmt.Errorf("%s: %s: %v", f.Name, b, err)
+		}
+	}
+
+	dom := ComputeDominators(f)
+	for _, b := range f.Blocks {
+		if b != f.Entry() && dom.Idom(b) == nil {
+			return fmt.Errorf("%s: %s is unreachable", f.Name, b)
+		}
+	}
+
+	for _, b := range f.Blocks {
+		phis := true
+		for i, v := range b.Values {
+			if err := verifyValue(v, b, i, &phis, defined, dom); err != nil {
+				return fmt.Errorf("%s: %s: %v", f.Name, v, err)
+			}
+		}
+		if c := b.Control; c != nil {
+			if !defined[c] {
+				return fmt.Errorf("%s: %s: control %s is not defined in the function", f.Name, b, c)
+			}
+			if !dom.Dominates(c.Block, b) {
+				return fmt.Errorf("%s: %s: control %s does not dominate the block", f.Name, b, c)
+			}
+		}
+	}
+	return nil
+}
+
+// verifyEdges checks the successors of b against its kind and the
+// predecessor lists of both ends
+func verifyEdges(b *Block, blocks map[*Block]bool) error {
+	want := 0
+	switch b.Kind {
+	case BlockPlain:
+		want = 1
+	case BlockIf, BlockTry:
+		want = 2
+	}
+	if len(b.Succs) != want {
+		return fmt.Errorf("%s block has %d successors, want %d", b.Kind, len(b.Succs), want)
+	}
+	switch b.Kind {
+	case BlockIf, BlockThrow:
+		if b.Control == nil {
+			return fmt.Errorf("%s block has no control", b.Kind)
+		}
+	case BlockPlain, BlockTry:
+		if b.Control != nil {
+			return fmt.Errorf("%s block has a control", b.Kind)
+		}
+	}
+	if b.Kind == BlockTry && b.Succs[0] == b.Succs[1] {
+		return fmt.Errorf("try block enters its handler")
+	}
+
+	for _, succ := range b.Succs {
+		if !blocks[succ] {
+			return fmt.Errorf("successor %s is not in the function", succ)
+		}
+		if count(succ.Preds, b) != count(b.Succs, succ) {
+			return fmt.Errorf("edge to %s is missing from its predecessors", succ)
+		}
+	}
+	for _, pred := range b.Preds {
+		if !blocks[pred] {
+			return fmt.Errorf("predecessor %s is not in the function", pred)
+		}
+		if count(pred.Succs, b) != count(b.Preds, pred) {
+			return fmt.Errorf("edge from %s is missing from its successors", pred)
+		}
+	}
+	return nil
+}
+
+func count(blocks []*Block, b *Block) int {
+	n := 0
+	for _, x := range blocks {
+		if x == b {
+			n++
+		}
+	}
+	return n
+}
+
+// verifyValue checks v, the i-th value of b; phis is set while only phis
+// have been seen
+func verifyValue(v *Value, b *Block, i int, phis *bool, defined map[*Value]bool, dom *DomTree) error {
+	if v.Block != b {
+		return fmt.Errorf("is in %s but says %s", b, v.Block)
+	}
+	if v.Op <= OpInvalid || v.Op >= opCount {
+		return fmt.Errorf("invalid op %d", v.Op)
+	}
+	if v.Type == nil {
+		return fmt.Errorf("has no type")
+	}
+
+	switch v.Op {
+	case OpPhi:
+		if !*phis {
+			return fmt.Errorf("phi after other values")
+		}
+		if len(v.Args) != len(b.Preds) {
+			return fmt.Errorf("phi has %d arguments for %d predecessors", len(v.Args), len(b.Preds))
+		}
+	case OpParam, OpRest, OpSelf, OpFreeVar:
+		*phis = false
+		if b != b.Func.Entry() {
+			return fmt.Errorf("%s outside the entry block", v.Op)
+		}
+	case OpCatch:
+		*phis = false
+		if len(b.Preds) != 1 || b.Preds[0].Kind != BlockTry || b.Preds[0].Succs[1] != b {
+			return fmt.Errorf("catch outside a handler block")
+		}
+	default:
+		*phis = false
+	}
+
+	for idx, arg := range v.Args {
+		if arg == nil {
+			return fmt.Errorf("argument %d is nil", idx)
+		}
+		if !defined[arg] {
+			return fmt.Errorf("argument %s is not defined in the function", arg)
+		}
+		if v.Op == OpPhi {
+			// The argument must be available at the end of the predecessor
+			if !dom.Dominates(arg.Block, b.Preds[idx]) {
+				return fmt.Errorf("argument %s does not dominate predecessor %s", arg, b.Preds[idx])
+			}
+			continue
+		}
+		if arg.Block == b {
+			if !definedBefore(b, arg, i) {
+				return fmt.Errorf("argument %s is used before it is defined", arg)
+			}
+		} else if !dom.Dominates(arg.Block, b) {
+			return fmt.Errorf("argument %s does not dominate its use", arg)
+		}
+	}
+	return nil
+}
+
+// definedBefore reports whether arg comes before position i of b
+func definedBefore(b *Block, arg *Value, i int) bool {
+	for _, v := range b.Values[:i] {
+		if v == arg {
+			return true
+		}
+	}
+	return false
+}
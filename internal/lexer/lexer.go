@@ -105,7 +105,7 @@ func (l *Lexer) NextToken() Token {
 		tok = l.makeToken(NEWLINE, "\\n")
 		l.readChar()
 		l.line++
-		l.column = 0
+		l.column = 1 // yeni satırın ilk karakteri
 		l.atLineStart = true
 
 	case '#':
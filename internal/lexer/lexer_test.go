@@ -304,3 +304,17 @@ func TestPosition(t *testing.T) {
 		t.Errorf("wrong line for 'x': %d", tok.Line)
 	}
 }
+
+func TestPositionAfterNewline(t *testing.T) {
+	input := "let a = 1\nprint(name)"
+
+	l := New(input, "test.sky")
+	for tok := l.NextToken(); tok.Type != EOF; tok = l.NextToken() {
+		if tok.Literal == "print" && (tok.Line != 2 || tok.Column != 1) {
+			t.Errorf("wrong position for 'print': line=%d, col=%d", tok.Line, tok.Column)
+		}
+		if tok.Literal == "name" && (tok.Line != 2 || tok.Column != 7) {
+			t.Errorf("wrong position for 'name': line=%d, col=%d", tok.Line, tok.Column)
+		}
+	}
+}
@@ -58,6 +58,10 @@ const (
 	ABSTRACT // abstract
 	STATIC   // static
 
+	// Interface keywords
+	INTERFACE  // interface
+	IMPLEMENTS // implements
+
 	// Operators
 	PLUS    // +
 	MINUS   // -
@@ -147,6 +151,9 @@ var keywords = map[string]TokenType{
 	"throw":    THROW,
 	"abstract": ABSTRACT,
 	"static":   STATIC,
+
+	"interface":  INTERFACE,
+	"implements": IMPLEMENTS,
 }
 
 // LookupIdent identifier'ın keyword olup olmadığını kontrol eder
@@ -234,6 +241,9 @@ func (tt TokenType) String() string {
 		BREAK:    "BREAK",
 		CONTINUE: "CONTINUE",
 
+		INTERFACE:  "INTERFACE",
+		IMPLEMENTS: "IMPLEMENTS",
+
 		PLUS:    "PLUS",
 		MINUS:   "MINUS",
 		STAR:    "STAR",
@@ -288,7 +298,7 @@ func (tt TokenType) String() string {
 
 // IsKeyword token'ın keyword olup olmadığını kontrol eder
 func (tt TokenType) IsKeyword() bool {
-	return tt >= FUNCTION && tt <= STATIC
+	return tt >= FUNCTION && tt <= IMPLEMENTS
 }
 
 // IsOperator token'ın operator olup olmadığını kontrol eder
@@ -0,0 +1,194 @@
+package linter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"sort"
+
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Config file names. .skylint.toml holds the settings at its top level,
+// the project manifest under a [lint] table:
+//
+//	plugins = ["lint/rules.so"]
+//
+//	[rules]
+//	shadowing = "off"
+//	division-by-zero = "warning"
+//	unused-var = { severity = "error", ignore-prefix = "tmp_" }
+const (
+	ConfigFile   = ".skylint.toml"
+	ManifestFile = "sky.project.toml"
+)
+
+// Config selects the severity and options of rules
+type Config struct {
+	Path    string                // file the config was read from, "" for the defaults
+	Rules   map[string]RuleConfig // by rule ID
+	Plugins []string              // Go plugins that register rules, relative to Path
+}
+
+// RuleConfig is the configuration of one rule
+type RuleConfig struct {
+	Severity string // "" keeps the default, SeverityOff turns the rule off
+	Options  map[string]interface{}
+}
+
+// rawConfig is the TOML form of a config; a rule is either a severity or a
+// table of severity and options
+type rawConfig struct {
+	Plugins []string               `toml:"plugins"`
+	Rules   map[string]interface{} `toml:"rules"`
+}
+
+// FindConfig looks for .skylint.toml, then for a sky.project.toml with a
+// [lint] table, in dir and its parents. It returns nil when there is none.
+func FindConfig(dir string) (*Config, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		path := filepath.Join(dir, ConfigFile)
+		if _, err := os.Stat(path); err == nil {
+			return LoadConfig(path)
+		}
+		path = filepath.Join(dir, ManifestFile)
+		if _, err := os.Stat(path); err == nil {
+			config, err := LoadConfig(path)
+			if err != nil || config != nil {
+				return config, err
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// LoadConfig reads a config from a .skylint.toml file or from the [lint]
+// table of a project manifest. A manifest without one gives nil. Plugins
+// are loaded and every rule setting is checked against the registered
+// rules.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw rawConfig
+	if filepath.Base(path) == ManifestFile {
+		var manifest struct {
+			Lint *rawConfig `toml:"lint"`
+		}
+		if err := toml.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if manifest.Lint == nil {
+			return nil, nil
+		}
+		raw = *manifest.Lint
+	} else if err := toml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	config := &Config{Path: path, Plugins: raw.Plugins, Rules: make(map[string]RuleConfig)}
+	if err := config.loadPlugins(); err != nil {
+		return nil, err
+	}
+	if err := config.parseRules(raw.Rules); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return config, nil
+}
+
+// loadPlugins opens the Go plugins of the config; their init functions
+// register their rules with Register
+func (c *Config) loadPlugins() error {
+	for _, name := range c.Plugins {
+		path := name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(c.Path), path)
+		}
+		if _, err := plugin.Open(path); err != nil {
+			return errors.New(i18n.T("lint.plugin_error", path, err))
+		}
+	}
+	return nil
+}
+
+func (c *Config) parseRules(rules map[string]interface{}) error {
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		info, ok := LookupRule(id)
+		if !ok {
+			return errors.New(i18n.T("lint.unknown_rule", id))
+		}
+		var rc RuleConfig
+		switch v := rules[id].(type) {
+		case string:
+			rc.Severity = v
+		case map[string]interface{}:
+			for name, value := range v {
+				if name == "severity" {
+					s, ok := value.(string)
+					if !ok {
+						return errors.New(i18n.T("lint.invalid_severity", id, value))
+					}
+					rc.Severity = s
+					continue
+				}
+				def, ok := info.Options[name]
+				if !ok {
+					return errors.New(i18n.T("lint.unknown_option", id, name))
+				}
+				if !sameKind(def, value) {
+					return errors.New(i18n.T("lint.option_type", id, name, def))
+				}
+				if rc.Options == nil {
+					rc.Options = make(map[string]interface{})
+				}
+				rc.Options[name] = value
+			}
+		default:
+			return errors.New(i18n.T("lint.invalid_severity", id, v))
+		}
+		if rc.Severity != "" && !validSeverity(rc.Severity) {
+			return errors.New(i18n.T("lint.invalid_severity", id, rc.Severity))
+		}
+		c.Rules[id] = rc
+	}
+	return nil
+}
+
+// sameKind reports whether an option value has the type of its default;
+// TOML integers and floats both count as numbers
+func sameKind(def, value interface{}) bool {
+	switch def.(type) {
+	case string:
+		_, ok := value.(string)
+		return ok
+	case bool:
+		_, ok := value.(bool)
+		return ok
+	case int, int64, float64:
+		switch value.(type) {
+		case int64, float64:
+			return true
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to name in dir, creating its directories
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, ConfigFile, `[rules]
+shadowing = "off"
+division-by-zero = "warning"
+unused-var = { severity = "error", ignore-prefix = "tmp_" }
+`)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Path != path {
+		t.Errorf("Path is %q, want %q", config.Path, path)
+	}
+	if got := config.Rules["shadowing"].Severity; got != SeverityOff {
+		t.Errorf("shadowing severity is %q", got)
+	}
+	if got := config.Rules["division-by-zero"].Severity; got != SeverityWarning {
+		t.Errorf("division-by-zero severity is %q", got)
+	}
+	unused := config.Rules["unused-var"]
+	if unused.Severity != SeverityError || unused.Options["ignore-prefix"] != "tmp_" {
+		t.Errorf("unused-var is %+v", unused)
+	}
+	if _, ok := config.Rules["unsafe-block"]; ok {
+		t.Error("unsafe-block configured without a setting")
+	}
+}
+
+func TestLoadConfigManifest(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, ManifestFile, `[project]
+name = "app"
+
+[lint.rules]
+shadowing = "info"
+`)
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config == nil || config.Rules["shadowing"].Severity != SeverityInfo {
+		t.Errorf("manifest config is %+v", config)
+	}
+
+	path = writeFile(t, t.TempDir(), ManifestFile, "[project]\nname = \"app\"\n")
+	if config, err := LoadConfig(path); config != nil || err != nil {
+		t.Errorf("manifest without [lint]: got %+v, %v", config, err)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown rule", "[rules]\nno-such-rule = \"error\"\n", `unknown rule "no-such-rule"`},
+		{"invalid severity", "[rules]\nshadowing = \"fatal\"\n", "rule shadowing: invalid severity fatal"},
+		{"severity type", "[rules]\nshadowing = 1\n", "rule shadowing: invalid severity 1"},
+		{"severity in table", "[rules]\nshadowing = { severity = true }\n", "rule shadowing: invalid severity true"},
+		{"unknown option", "[rules]\nshadowing = { depth = 2 }\n", "rule shadowing has no option depth"},
+		{"option type", "[rules]\nunused-var = { ignore-prefix = 1 }\n", "rule unused-var: option ignore-prefix must have the type"},
+		{"syntax", "[rules\n", ConfigFile},
+	}
+
+	for _, tt := range tests {
+		path := writeFile(t, t.TempDir(), ConfigFile, tt.content)
+		config, err := LoadConfig(path)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %+v, %v, want error %q", tt.name, config, err, tt.want)
+		}
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "src", "pkg")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A manifest without [lint] is skipped on the way up
+	writeFile(t, root, filepath.Join("src", ManifestFile), "[project]\nname = \"app\"\n")
+	rootConfig := writeFile(t, root, ConfigFile, "[rules]\nshadowing = \"off\"\n")
+	config, err := FindConfig(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config == nil || config.Path != rootConfig {
+		t.Fatalf("found %+v, want %s", config, rootConfig)
+	}
+
+	// A manifest with [lint] is found before configs further up
+	manifest := writeFile(t, root, filepath.Join("src", ManifestFile), "[lint.rules]\nshadowing = \"error\"\n")
+	if config, err := FindConfig(nested); err != nil || config == nil || config.Path != manifest {
+		t.Fatalf("found %+v, %v, want %s", config, err, manifest)
+	}
+
+	// .skylint.toml comes before the manifest of the same directory
+	local := writeFile(t, root, filepath.Join("src", ConfigFile), "[rules]\nunsafe-block = \"info\"\n")
+	if config, err := FindConfig(nested); err != nil || config == nil || config.Path != local {
+		t.Fatalf("found %+v, %v, want %s", config, err, local)
+	}
+
+	// Errors in the config found are reported
+	writeFile(t, root, filepath.Join("src", "pkg", ConfigFile), "[rules]\nno-such-rule = \"off\"\n")
+	if _, err := FindConfig(nested); err == nil {
+		t.Error("invalid config found without error")
+	}
+}
@@ -1,10 +1,11 @@
 package linter
 
 import (
-	"fmt"
-	"strings"
+	"errors"
 
 	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 	"github.com/mburakmmm/sky-lang/internal/lexer"
 	"github.com/mburakmmm/sky-lang/internal/parser"
 )
@@ -16,26 +17,29 @@ type Issue struct {
 	Column   int
 	Severity string // "error", "warning", "info"
 	Rule     string
+	Code     string // diagnostic code, see sky explain
 	Message  string
 }
 
 // Linter checks code for issues
 type Linter struct {
-	issues      []Issue
-	filename    string
-	inUnsafe    bool
-	inFunction  bool
-	usedVars    map[string]bool
-	definedVars map[string]lexer.Token
+	issues   []Issue
+	filename string
+	config   *Config
+
+	// Codes suppressed by "# sky-ignore: CODE" comments and rules
+	// disabled by "# sky-lint: disable=rule" comments
+	suppressions diag.Suppressions
+	disabled     disabledRules
 }
 
-// NewLinter creates a new linter
-func NewLinter() *Linter {
-	return &Linter{
-		issues:      []Issue{},
-		usedVars:    make(map[string]bool),
-		definedVars: make(map[string]lexer.Token),
+// NewLinter creates a linter that runs the registered rules as configured
+// by config; a nil config runs every rule with its defaults
+func NewLinter(config *Config) *Linter {
+	if config == nil {
+		config = &Config{}
 	}
+	return &Linter{config: config}
 }
 
 // Lint checks a program for issues
@@ -43,173 +47,179 @@ func (l *Linter) Lint(program *ast.Program, filename string) []Issue {
 	l.filename = filename
 	l.issues = []Issue{}
 
-	for _, stmt := range program.Statements {
-		l.checkStatement(stmt)
+	var rules []*activeRule
+	for _, info := range Rules() {
+		rc := l.config.Rules[info.ID]
+		severity := info.Severity
+		if rc.Severity != "" {
+			severity = rc.Severity
+		}
+		if severity == SeverityOff {
+			continue
+		}
+		registryMu.RLock()
+		newRule := registry[info.ID].newRule
+		registryMu.RUnlock()
+		rules = append(rules, &activeRule{
+			info:     info,
+			severity: severity,
+			options:  rc.Options,
+			rule:     newRule(),
+		})
 	}
 
-	// Check for unused variables
-	for name, token := range l.definedVars {
-		if !l.usedVars[name] && !strings.HasPrefix(name, "_") {
-			l.addIssue(token.Line, token.Column, "warning", "unused-var",
-				fmt.Sprintf("variable '%s' is defined but never used", name))
-		}
+	w := &walker{linter: l, rules: rules}
+	for _, stmt := range program.Statements {
+		w.statement(stmt)
+	}
+	for _, r := range rules {
+		r.rule.Finish(w.context(r))
 	}
 
 	return l.issues
 }
 
-func (l *Linter) addIssue(line, col int, severity, rule, message string) {
+// SetSource gives the linter the source of the program, whose
+// suppression comments then apply to its issues
+func (l *Linter) SetSource(source string) {
+	l.suppressions = diag.ParseSuppressions(source)
+	l.disabled = parseDisabledRules(source)
+}
+
+func (l *Linter) addIssue(r *activeRule, line, col int, message string) {
+	if l.suppressions.Suppressed(r.info.Code, line) || l.disabled.disabled(r.info.ID, line) {
+		return
+	}
 	l.issues = append(l.issues, Issue{
 		File:     l.filename,
 		Line:     line,
 		Column:   col,
-		Severity: severity,
-		Rule:     rule,
+		Severity: r.severity,
+		Rule:     r.info.ID,
+		Code:     r.info.Code,
 		Message:  message,
 	})
 }
 
-func (l *Linter) checkStatement(stmt ast.Statement) {
+// walker visits the statements and expressions of a program for every
+// active rule, keeping track of the enclosing nodes
+type walker struct {
+	linter  *Linter
+	rules   []*activeRule
+	parents []ast.Node
+}
+
+func (w *walker) context(r *activeRule) *Context {
+	return &Context{File: w.linter.filename, linter: w.linter, rule: r, parents: w.parents}
+}
+
+func (w *walker) visit(node ast.Node) {
+	for _, r := range w.rules {
+		r.rule.Visit(w.context(r), node)
+	}
+}
+
+func (w *walker) enter(node ast.Node) { w.parents = append(w.parents, node) }
+func (w *walker) leave()              { w.parents = w.parents[:len(w.parents)-1] }
+
+func (w *walker) statement(stmt ast.Statement) {
+	if stmt == nil {
+		return
+	}
+	w.visit(stmt)
+	w.enter(stmt)
+	defer w.leave()
+
 	switch s := stmt.(type) {
 	case *ast.LetStatement:
-		l.checkLetStatement(s)
+		w.expression(s.Value)
 	case *ast.ConstStatement:
-		l.checkConstStatement(s)
+		w.expression(s.Value)
 	case *ast.FunctionStatement:
-		l.checkFunctionStatement(s)
+		w.block(s.Body)
 	case *ast.ReturnStatement:
-		if !l.inFunction {
-			l.addIssue(s.Token.Line, s.Token.Column, "error", "return-outside-function",
-				"return statement outside of function")
-		}
-		if s.ReturnValue != nil {
-			l.checkExpression(s.ReturnValue)
-		}
+		w.expression(s.ReturnValue)
 	case *ast.UnsafeStatement:
-		l.checkUnsafeStatement(s)
+		w.block(s.Body)
 	case *ast.IfStatement:
-		l.checkExpression(s.Condition)
-		l.checkBlock(s.Consequence)
+		w.expression(s.Condition)
+		w.block(s.Consequence)
 		for _, elif := range s.Elif {
-			l.checkExpression(elif.Condition)
-			l.checkBlock(elif.Consequence)
-		}
-		if s.Alternative != nil {
-			l.checkBlock(s.Alternative)
+			w.expression(elif.Condition)
+			w.block(elif.Consequence)
 		}
+		w.block(s.Alternative)
 	case *ast.WhileStatement:
-		l.checkExpression(s.Condition)
-		l.checkBlock(s.Body)
+		w.expression(s.Condition)
+		w.block(s.Body)
 	case *ast.ForStatement:
-		l.checkExpression(s.Iterable)
-		l.checkBlock(s.Body)
+		w.expression(s.Iterable)
+		w.block(s.Body)
 	case *ast.ExpressionStatement:
-		l.checkExpression(s.Expression)
+		w.expression(s.Expression)
 	}
 }
 
-func (l *Linter) checkLetStatement(stmt *ast.LetStatement) {
-	name := stmt.Name.Value
-
-	// Check shadowing
-	if _, exists := l.definedVars[name]; exists {
-		l.addIssue(stmt.Token.Line, stmt.Token.Column, "warning", "shadowing",
-			fmt.Sprintf("variable '%s' shadows previous declaration", name))
+func (w *walker) block(block *ast.BlockStatement) {
+	if block == nil {
+		return
 	}
-
-	l.definedVars[name] = stmt.Token
-	l.checkExpression(stmt.Value)
-}
-
-func (l *Linter) checkConstStatement(stmt *ast.ConstStatement) {
-	name := stmt.Name.Value
-	l.definedVars[name] = stmt.Token
-	l.checkExpression(stmt.Value)
-}
-
-func (l *Linter) checkFunctionStatement(stmt *ast.FunctionStatement) {
-	wasInFunction := l.inFunction
-	l.inFunction = true
-	defer func() { l.inFunction = wasInFunction }()
-
-	l.checkBlock(stmt.Body)
-}
-
-func (l *Linter) checkUnsafeStatement(stmt *ast.UnsafeStatement) {
-	wasInUnsafe := l.inUnsafe
-	l.inUnsafe = true
-	defer func() { l.inUnsafe = wasInUnsafe }()
-
-	l.addIssue(stmt.Token.Line, stmt.Token.Column, "warning", "unsafe-block",
-		"unsafe block bypasses safety checks")
-
-	l.checkBlock(stmt.Body)
-}
-
-func (l *Linter) checkBlock(block *ast.BlockStatement) {
 	for _, stmt := range block.Statements {
-		l.checkStatement(stmt)
+		w.statement(stmt)
 	}
 }
 
-func (l *Linter) checkExpression(expr ast.Expression) {
+func (w *walker) expression(expr ast.Expression) {
+	if expr == nil {
+		return
+	}
+	w.visit(expr)
+	w.enter(expr)
+	defer w.leave()
+
 	switch e := expr.(type) {
-	case *ast.Identifier:
-		l.usedVars[e.Value] = true
 	case *ast.InfixExpression:
-		l.checkExpression(e.Left)
-		l.checkExpression(e.Right)
-
-		// Check for dangerous operations
-		if e.Operator == "/" {
-			// Check division by zero
-			if lit, ok := e.Right.(*ast.IntegerLiteral); ok {
-				if lit.Value == 0 {
-					l.addIssue(e.Token.Line, e.Token.Column, "error", "division-by-zero",
-						"division by zero")
-				}
-			}
-		}
+		w.expression(e.Left)
+		w.expression(e.Right)
 	case *ast.PrefixExpression:
-		l.checkExpression(e.Right)
+		w.expression(e.Right)
 	case *ast.CallExpression:
-		l.checkExpression(e.Function)
+		w.expression(e.Function)
 		for _, arg := range e.Arguments {
-			l.checkExpression(arg)
+			w.expression(arg)
 		}
 	case *ast.IndexExpression:
-		l.checkExpression(e.Left)
-		l.checkExpression(e.Index)
+		w.expression(e.Left)
+		w.expression(e.Index)
 	case *ast.MemberExpression:
-		l.checkExpression(e.Object)
+		w.expression(e.Object)
 	case *ast.ListLiteral:
 		for _, elem := range e.Elements {
-			l.checkExpression(elem)
+			w.expression(elem)
 		}
 	case *ast.DictLiteral:
 		for key, val := range e.Pairs {
-			l.checkExpression(key)
-			l.checkExpression(val)
+			w.expression(key)
+			w.expression(val)
 		}
 	case *ast.AwaitExpression:
-		l.checkExpression(e.Expression)
+		w.expression(e.Expression)
 	case *ast.YieldExpression:
-		if e.Value != nil {
-			l.checkExpression(e.Value)
-		}
+		w.expression(e.Value)
 	}
 }
 
-// LintFile lints a source file
-func LintFile(filename, source string) ([]Issue, error) {
+// LintFile lints a source file with the given config, nil for the defaults
+func LintFile(filename, source string, config *Config) ([]Issue, error) {
 	l := lexer.New(source, filename)
 	p := parser.New(l)
 	program := p.ParseProgram()
 
 	if len(p.Errors()) > 0 {
-		return nil, fmt.Errorf("parse errors: %v", p.Errors())
+		return nil, errors.New(i18n.T("lint.parse_errors", p.Errors()))
 	}
 
-	linter := NewLinter()
+	linter := NewLinter(config)
+	linter.SetSource(source)
 	return linter.Lint(program, filename), nil
 }
@@ -0,0 +1,118 @@
+package linter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDisabledRules(t *testing.T) {
+	source := `let a = 1  # sky-lint: disable=unused-var
+# sky-lint: disable=shadowing, unused-var
+let a = 2
+let b = 3  # sky-lint: disable=all
+let c = 4  # sky-lint: enable=shadowing
+let d = 5  # sky-ignore: W2001`
+
+	d := parseDisabledRules(source)
+	want := disabledRules{
+		1: {"unused-var": true},
+		3: {"shadowing": true, "unused-var": true},
+		4: {"all": true},
+	}
+	if !reflect.DeepEqual(d, want) {
+		t.Fatalf("parseDisabledRules = %v, want %v", d, want)
+	}
+
+	tests := []struct {
+		rule string
+		line int
+		want bool
+	}{
+		{"unused-var", 1, true},
+		{"shadowing", 1, false},
+		{"shadowing", 3, true},
+		{"division-by-zero", 4, true},
+		{"shadowing", 5, false},
+		{"unused-var", 2, false},
+	}
+	for _, tt := range tests {
+		if got := d.disabled(tt.rule, tt.line); got != tt.want {
+			t.Errorf("disabled(%s, %d) = %v, want %v", tt.rule, tt.line, got, tt.want)
+		}
+	}
+
+	if d := parseDisabledRules("let a = 1\n"); d != nil {
+		t.Errorf("source without directives: %v", d)
+	}
+}
+
+// issueKey is the part of an issue the tests compare
+type issueKey struct {
+	Line     int
+	Rule     string
+	Severity string
+}
+
+func lint(t *testing.T, source string, config *Config) []issueKey {
+	t.Helper()
+	issues, err := LintFile("test.sky", source, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]issueKey, len(issues))
+	for i, issue := range issues {
+		keys[i] = issueKey{issue.Line, issue.Rule, issue.Severity}
+	}
+	return keys
+}
+
+func TestLintFileSeverities(t *testing.T) {
+	source := `let a = 1
+let a = a / 0
+let tmp_b = 2`
+
+	tests := []struct {
+		name   string
+		config *Config
+		want   []issueKey
+	}{
+		{"defaults", nil, []issueKey{
+			{2, "shadowing", SeverityWarning},
+			{2, "division-by-zero", SeverityError},
+			{3, "unused-var", SeverityWarning},
+		}},
+		{"overrides", &Config{Rules: map[string]RuleConfig{
+			"shadowing":        {Severity: SeverityOff},
+			"division-by-zero": {Severity: SeverityWarning},
+			"unused-var":       {Severity: SeverityError, Options: map[string]interface{}{"ignore-prefix": "tmp_"}},
+		}}, []issueKey{
+			{2, "division-by-zero", SeverityWarning},
+		}},
+		{"option only", &Config{Rules: map[string]RuleConfig{
+			"unused-var": {Options: map[string]interface{}{"ignore-prefix": ""}},
+		}}, []issueKey{
+			{2, "shadowing", SeverityWarning},
+			{2, "division-by-zero", SeverityError},
+			{3, "unused-var", SeverityWarning},
+		}},
+	}
+
+	for _, tt := range tests {
+		if got := lint(t, source, tt.config); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: issues %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLintFileDirectives(t *testing.T) {
+	source := `let a = 1
+let a = a / 0  # sky-lint: disable=shadowing
+# sky-lint: disable=all
+let b = 2
+let c = 3  # sky-ignore: W2001`
+
+	want := []issueKey{{2, "division-by-zero", SeverityError}}
+	if got := lint(t, source, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("issues %v, want %v", got, want)
+	}
+}
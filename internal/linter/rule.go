@@ -0,0 +1,168 @@
+package linter
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+)
+
+// Severities of issues. A rule configured as SeverityOff does not run.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+	SeverityOff     = "off"
+)
+
+// RuleInfo describes a rule
+type RuleInfo struct {
+	ID          string                 // e.g. "unused-var", used in config and sky-lint comments
+	Code        string                 // diagnostic code, see sky explain; may be empty
+	Severity    string                 // default severity
+	Description string                 // one line, shown by sky lint --rules
+	Options     map[string]interface{} // option names and their default values
+}
+
+// Rule is a lint check. A new Rule is made for every file, so a rule can
+// keep state between calls.
+type Rule interface {
+	// Visit is called for every statement and expression of the program
+	// in source order; ctx.Parents holds the nodes enclosing it.
+	Visit(ctx *Context, node ast.Node)
+	// Finish is called once the whole program has been visited
+	Finish(ctx *Context)
+}
+
+type registeredRule struct {
+	info    RuleInfo
+	newRule func() Rule
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]*registeredRule)
+)
+
+// Register adds a rule to the linter. newRule makes the rule for each
+// file. Project rules register themselves from the init function of a Go
+// plugin listed under plugins in the config. Register panics when the ID
+// is taken or the default severity is invalid.
+func Register(info RuleInfo, newRule func() Rule) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if info.ID == "" || newRule == nil {
+		panic("linter: Register needs a rule ID and a constructor")
+	}
+	if _, dup := registry[info.ID]; dup {
+		panic("linter: Register called twice for rule " + info.ID)
+	}
+	if !validSeverity(info.Severity) || info.Severity == SeverityOff {
+		panic(fmt.Sprintf("linter: rule %s has invalid severity %q", info.ID, info.Severity))
+	}
+	registry[info.ID] = &registeredRule{info: info, newRule: newRule}
+}
+
+// Rules returns the registered rules ordered by ID
+func Rules() []RuleInfo {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	rules := make([]RuleInfo, 0, len(registry))
+	for _, r := range registry {
+		rules = append(rules, r.info)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
+
+// LookupRule returns the rule with the given ID
+func LookupRule(id string) (RuleInfo, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	r, ok := registry[id]
+	if !ok {
+		return RuleInfo{}, false
+	}
+	return r.info, true
+}
+
+func validSeverity(severity string) bool {
+	switch severity {
+	case SeverityError, SeverityWarning, SeverityInfo, SeverityOff:
+		return true
+	}
+	return false
+}
+
+// Context is what a rule sees of the file being linted
+type Context struct {
+	File string
+
+	linter  *Linter
+	rule    *activeRule
+	parents []ast.Node
+}
+
+// Report adds an issue at pos with the severity configured for the rule
+func (c *Context) Report(pos lexer.Token, message string) {
+	c.linter.addIssue(c.rule, pos.Line, pos.Column, message)
+}
+
+// Parents returns the nodes enclosing the visited node, innermost last
+func (c *Context) Parents() []ast.Node {
+	return c.parents
+}
+
+// Inside reports whether the visited node is nested in a node for which
+// match returns true
+func (c *Context) Inside(match func(ast.Node) bool) bool {
+	for i := len(c.parents) - 1; i >= 0; i-- {
+		if match(c.parents[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Option returns the configured value of an option, or its default
+func (c *Context) Option(name string) interface{} {
+	if v, ok := c.rule.options[name]; ok {
+		return v
+	}
+	return c.rule.info.Options[name]
+}
+
+// StringOption returns a string option, "" when it is not a string
+func (c *Context) StringOption(name string) string {
+	s, _ := c.Option(name).(string)
+	return s
+}
+
+// IntOption returns an integer option, 0 when it is not a number
+func (c *Context) IntOption(name string) int {
+	switch v := c.Option(name).(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// BoolOption returns a boolean option
+func (c *Context) BoolOption(name string) bool {
+	b, _ := c.Option(name).(bool)
+	return b
+}
+
+// activeRule is a rule running on one file with its configuration
+type activeRule struct {
+	info     RuleInfo
+	severity string
+	options  map[string]interface{}
+	rule     Rule
+}
@@ -0,0 +1,151 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+)
+
+// Built-in rules. Rules that find a problem the checker or the runtime
+// also reports share its diagnostic code.
+func init() {
+	Register(RuleInfo{
+		ID:          "unused-var",
+		Code:        diag.UnusedVariable,
+		Severity:    SeverityWarning,
+		Description: "variable or constant is defined but never used",
+		Options:     map[string]interface{}{"ignore-prefix": "_"},
+	}, func() Rule { return &unusedVarRule{used: make(map[string]bool)} })
+
+	Register(RuleInfo{
+		ID:          "shadowing",
+		Code:        diag.ShadowedVariable,
+		Severity:    SeverityWarning,
+		Description: "let redefines a name that is already defined",
+	}, func() Rule { return &shadowingRule{defined: make(map[string]bool)} })
+
+	Register(RuleInfo{
+		ID:          "unsafe-block",
+		Code:        diag.UnsafeBlock,
+		Severity:    SeverityWarning,
+		Description: "unsafe block turns off safety checks",
+	}, func() Rule { return unsafeBlockRule{} })
+
+	Register(RuleInfo{
+		ID:          "return-outside-function",
+		Code:        diag.ReturnOutsideFunction,
+		Severity:    SeverityError,
+		Description: "return at the top level",
+	}, func() Rule { return returnOutsideRule{} })
+
+	Register(RuleInfo{
+		ID:          "division-by-zero",
+		Code:        diag.DivisionByZero,
+		Severity:    SeverityError,
+		Description: "division by the literal 0",
+	}, func() Rule { return divisionByZeroRule{} })
+}
+
+// unusedVarRule reports let and const names that are never read. Names
+// starting with the ignore-prefix option are skipped.
+type unusedVarRule struct {
+	defined []lexer.Token
+	names   []string
+	used    map[string]bool
+}
+
+func (r *unusedVarRule) Visit(ctx *Context, node ast.Node) {
+	switch n := node.(type) {
+	case *ast.LetStatement:
+		r.define(n.Name.Value, n.Token)
+	case *ast.ConstStatement:
+		r.define(n.Name.Value, n.Token)
+	case *ast.Identifier:
+		r.used[n.Value] = true
+	}
+}
+
+func (r *unusedVarRule) define(name string, tok lexer.Token) {
+	for i, existing := range r.names {
+		if existing == name {
+			r.defined[i] = tok
+			return
+		}
+	}
+	r.names = append(r.names, name)
+	r.defined = append(r.defined, tok)
+}
+
+func (r *unusedVarRule) Finish(ctx *Context) {
+	prefix := ctx.StringOption("ignore-prefix")
+	for i, name := range r.names {
+		if r.used[name] || (prefix != "" && strings.HasPrefix(name, prefix)) {
+			continue
+		}
+		ctx.Report(r.defined[i], i18n.T("lint.unused_var", name))
+	}
+}
+
+// shadowingRule reports let statements for names defined earlier
+type shadowingRule struct {
+	defined map[string]bool
+}
+
+func (r *shadowingRule) Visit(ctx *Context, node ast.Node) {
+	switch n := node.(type) {
+	case *ast.LetStatement:
+		if r.defined[n.Name.Value] {
+			ctx.Report(n.Token, i18n.T("lint.shadowing", n.Name.Value))
+		}
+		r.defined[n.Name.Value] = true
+	case *ast.ConstStatement:
+		r.defined[n.Name.Value] = true
+	}
+}
+
+func (r *shadowingRule) Finish(ctx *Context) {}
+
+type unsafeBlockRule struct{}
+
+func (unsafeBlockRule) Visit(ctx *Context, node ast.Node) {
+	if n, ok := node.(*ast.UnsafeStatement); ok {
+		ctx.Report(n.Token, i18n.T("lint.unsafe_block"))
+	}
+}
+
+func (unsafeBlockRule) Finish(ctx *Context) {}
+
+type returnOutsideRule struct{}
+
+func (returnOutsideRule) Visit(ctx *Context, node ast.Node) {
+	n, ok := node.(*ast.ReturnStatement)
+	if !ok {
+		return
+	}
+	inFunction := ctx.Inside(func(parent ast.Node) bool {
+		_, ok := parent.(*ast.FunctionStatement)
+		return ok
+	})
+	if !inFunction {
+		ctx.Report(n.Token, i18n.T("lint.return_outside"))
+	}
+}
+
+func (returnOutsideRule) Finish(ctx *Context) {}
+
+type divisionByZeroRule struct{}
+
+func (divisionByZeroRule) Visit(ctx *Context, node ast.Node) {
+	n, ok := node.(*ast.InfixExpression)
+	if !ok || n.Operator != "/" {
+		return
+	}
+	if lit, ok := n.Right.(*ast.IntegerLiteral); ok && lit.Value == 0 {
+		ctx.Report(n.Token, i18n.T("lint.division_by_zero"))
+	}
+}
+
+func (divisionByZeroRule) Finish(ctx *Context) {}
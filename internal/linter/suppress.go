@@ -0,0 +1,52 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/diag"
+)
+
+// directivePrefix starts a comment that turns rules off for one line:
+//
+//	let tmp = load()  # sky-lint: disable=unused-var
+//
+//	# sky-lint: disable=shadowing, unused-var
+//	let tmp = reload()
+//
+// As with sky-ignore, a comment after code applies to its own line and a
+// comment on a line of its own to the next line. disable=all turns every
+// rule off.
+const directivePrefix = "sky-lint:"
+
+// disabledRules maps source lines to the rules turned off on them
+type disabledRules map[int]map[string]bool
+
+func parseDisabledRules(source string) disabledRules {
+	directives := diag.Directives(source, directivePrefix)
+	if len(directives) == 0 {
+		return nil
+	}
+	d := make(disabledRules)
+	for line, texts := range directives {
+		for _, text := range texts {
+			rules, ok := strings.CutPrefix(strings.TrimSpace(text), "disable=")
+			if !ok {
+				continue
+			}
+			fields := strings.FieldsFunc(rules, func(r rune) bool {
+				return r == ',' || r == ' ' || r == '\t'
+			})
+			for _, rule := range fields {
+				if d[line] == nil {
+					d[line] = make(map[string]bool)
+				}
+				d[line][rule] = true
+			}
+		}
+	}
+	return d
+}
+
+func (d disabledRules) disabled(rule string, line int) bool {
+	return d[line][rule] || d[line]["all"]
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 
 	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
 	"github.com/mburakmmm/sky-lang/internal/lexer"
 	"github.com/mburakmmm/sky-lang/internal/parser"
 	"github.com/mburakmmm/sky-lang/internal/sema"
@@ -38,8 +39,11 @@ type Document struct {
 	Text    string
 	AST     *ast.Program
 	Symbols *sema.SymbolTable
-	Errors  []Diagnostic
-	mu      sync.RWMutex
+	Types   []sema.TypeInfo // identifier types (flow-narrowed) for hover
+	// Functions holds signatures with inferred return types for hover
+	Functions []sema.FunctionInfo
+	Errors    []Diagnostic
+	mu        sync.RWMutex
 }
 
 // NewServer yeni bir LSP server oluşturur
@@ -245,29 +249,44 @@ func (s *Server) analyzeDocument(doc *Document) {
 
 	// Parser errors
 	for _, err := range p.Errors() {
+		message, code := diag.SplitCode(err)
 		doc.Errors = append(doc.Errors, Diagnostic{
 			Range: Range{
 				Start: Position{Line: 0, Character: 0},
 				End:   Position{Line: 0, Character: 0},
 			},
 			Severity: SeverityError,
+			Code:     code,
 			Source:   "parser",
-			Message:  err,
+			Message:  message,
 		})
 	}
 
 	// Semantic analysis
 	checker := sema.NewChecker()
+	checker.SetSourceFile(strings.TrimPrefix(doc.URI, "file://"))
+	checker.SetSource(doc.Text)
 	semErrors := checker.Check(doc.AST)
+	doc.Types = checker.Types()
+	doc.Functions = checker.Functions()
 
-	for _, err := range semErrors {
+	for _, err := range append(semErrors, checker.Warnings()...) {
 		if semErr, ok := err.(*sema.SemanticError); ok {
+			// Errors inside imported modules belong to their own documents
+			if semErr.Pos.File != "" && semErr.Pos.File != doc.URI {
+				continue
+			}
+			severity := SeverityError
+			if entry, ok := diag.Lookup(semErr.Code); ok && entry.Severity() == "warning" {
+				severity = SeverityWarning
+			}
 			doc.Errors = append(doc.Errors, Diagnostic{
 				Range: Range{
 					Start: Position{Line: semErr.Pos.Line - 1, Character: semErr.Pos.Column - 1},
 					End:   Position{Line: semErr.Pos.Line - 1, Character: semErr.Pos.Column + 10},
 				},
-				Severity: SeverityError,
+				Severity: severity,
+				Code:     semErr.Code,
 				Source:   "semantic",
 				Message:  semErr.Message,
 			})
@@ -371,11 +390,36 @@ func (s *Server) handleHover(req *Request) {
 }
 
 // getHover hover bilgisi oluşturur
+// İmlecin altındaki identifier'ın o konumdaki (daraltılmış) tipini gösterir
 func (s *Server) getHover(doc *Document, pos Position) *Hover {
-	// TODO: Implement actual hover info from AST
-	return &Hover{
-		Contents: "SKY Language Hover Info",
+	doc.mu.RLock()
+	defer doc.mu.RUnlock()
+
+	for _, info := range doc.Types {
+		line := info.Pos.Line - 1
+		start := info.Pos.Column - 1
+		end := start + len(info.Name)
+		if line != pos.Line || pos.Character < start || pos.Character >= end {
+			continue
+		}
+		contents := fmt.Sprintf("%s: %s", info.Name, info.Type.String())
+		// Functions show their parameter names too
+		for _, f := range doc.Functions {
+			if info.Type == sema.Type(f.Type) {
+				contents = f.Signature()
+				break
+			}
+		}
+		return &Hover{
+			Contents: contents,
+			Range: &Range{
+				Start: Position{Line: line, Character: start},
+				End:   Position{Line: line, Character: end},
+			},
+		}
 	}
+
+	return nil
 }
 
 // handleDefinition definition request
@@ -0,0 +1,71 @@
+package lsp
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"testing"
+)
+
+func analyzed(t *testing.T, text string) (*Server, *Document) {
+	t.Helper()
+	s := NewServer(nil, &bytes.Buffer{}, log.New(io.Discard, "", 0))
+	doc := &Document{URI: "file:///test.sky", Text: text}
+	s.analyzeDocument(doc)
+	return s, doc
+}
+
+func TestHover(t *testing.T) {
+	s, doc := analyzed(t, "let name = \"sky\"\nprint(name)")
+
+	tests := []struct {
+		pos      Position
+		contents string // "" for no hover
+	}{
+		{Position{Line: 0, Character: 4}, "name: string"},
+		{Position{Line: 0, Character: 7}, "name: string"},
+		{Position{Line: 0, Character: 8}, ""},
+		{Position{Line: 1, Character: 5}, ""}, // the "("
+		{Position{Line: 1, Character: 6}, "name: string"},
+		{Position{Line: 1, Character: 9}, "name: string"},
+		{Position{Line: 1, Character: 10}, ""}, // the ")"
+	}
+
+	for _, tt := range tests {
+		hover := s.getHover(doc, tt.pos)
+		if tt.contents == "" {
+			if hover != nil {
+				t.Errorf("%d:%d: expected no hover, got %q", tt.pos.Line, tt.pos.Character, hover.Contents)
+			}
+			continue
+		}
+		if hover == nil {
+			t.Errorf("%d:%d: expected hover %q, got none", tt.pos.Line, tt.pos.Character, tt.contents)
+			continue
+		}
+		if hover.Contents != tt.contents {
+			t.Errorf("%d:%d: hover is %q, want %q", tt.pos.Line, tt.pos.Character, hover.Contents, tt.contents)
+		}
+		want := Range{
+			Start: Position{Line: tt.pos.Line, Character: 6},
+			End:   Position{Line: tt.pos.Line, Character: 10},
+		}
+		if tt.pos.Line == 0 {
+			want.Start.Character, want.End.Character = 4, 8
+		}
+		if *hover.Range != want {
+			t.Errorf("%d:%d: hover range is %+v, want %+v", tt.pos.Line, tt.pos.Character, *hover.Range, want)
+		}
+	}
+}
+
+func TestDiagnosticRange(t *testing.T) {
+	_, doc := analyzed(t, "let a = 1\nlet b: int = \"x\"")
+
+	if len(doc.Errors) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d: %+v", len(doc.Errors), doc.Errors)
+	}
+	if start := doc.Errors[0].Range.Start; start.Line != 1 || start.Character != 0 {
+		t.Errorf("diagnostic starts at %d:%d, want 1:0", start.Line, start.Character)
+	}
+}
@@ -0,0 +1,430 @@
+package optimizer
+
+import (
+	"fmt"
+
+	"github.com/mburakmmm/sky-lang/internal/ir"
+)
+
+// ConstProp is sparse conditional constant propagation over the SSA IR
+// (Wegman and Zadeck). Values start unknown and only get a constant or
+// become varying as the blocks computing them are found reachable, so
+// constants flowing around loops and through branches on constants are
+// found too. Constant values are replaced by OpConst and branches on a
+// constant become jumps; DeadCode removes what is left behind.
+//
+// Operators fold with the interpreter's rules. Operations that would
+// fail at run time, such as division by zero, are left alone.
+type ConstProp struct{}
+
+func (ConstProp) Name() string { return "constprop" }
+
+// lattice states
+const (
+	unknown = iota
+	constant
+	varying
+)
+
+type cell struct {
+	state int
+	value interface{} // the constant, if state is constant
+}
+
+type sccp struct {
+	f         *ir.Func
+	cells     map[*ir.Value]cell
+	reachable map[*ir.Block]bool
+	edges     map[[2]*ir.Block]bool // executable edges
+	users     map[*ir.Value][]*ir.Value
+	controls  map[*ir.Value][]*ir.Block
+	blocks    []*ir.Block // flow worklist
+	values    []*ir.Value // SSA worklist
+}
+
+func (ConstProp) Run(f *ir.Func) bool {
+	s := &sccp{
+		f:         f,
+		cells:     make(map[*ir.Value]cell),
+		reachable: make(map[*ir.Block]bool),
+		edges:     make(map[[2]*ir.Block]bool),
+		users:     make(map[*ir.Value][]*ir.Value),
+		controls:  make(map[*ir.Value][]*ir.Block),
+	}
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			for _, arg := range v.Args {
+				s.users[arg] = append(s.users[arg], v)
+			}
+		}
+		if b.Control != nil {
+			s.controls[b.Control] = append(s.controls[b.Control], b)
+		}
+	}
+	s.solve()
+	return s.rewrite()
+}
+
+func (s *sccp) solve() {
+	s.markReachable(s.f.Entry())
+	for len(s.blocks) > 0 || len(s.values) > 0 {
+		for len(s.blocks) > 0 {
+			b := s.blocks[len(s.blocks)-1]
+			s.blocks = s.blocks[:len(s.blocks)-1]
+			for _, v := range b.Values {
+				s.visit(v)
+			}
+			s.visitControl(b)
+		}
+		for len(s.values) > 0 {
+			v := s.values[len(s.values)-1]
+			s.values = s.values[:len(s.values)-1]
+			for _, user := range s.users[v] {
+				if s.reachable[user.Block] {
+					s.visit(user)
+				}
+			}
+			for _, b := range s.controls[v] {
+				if s.reachable[b] {
+					s.visitControl(b)
+				}
+			}
+		}
+	}
+}
+
+func (s *sccp) markReachable(b *ir.Block) {
+	if !s.reachable[b] {
+		s.reachable[b] = true
+		s.blocks = append(s.blocks, b)
+	}
+}
+
+func (s *sccp) markEdge(from, to *ir.Block) {
+	edge := [2]*ir.Block{from, to}
+	if s.edges[edge] {
+		return
+	}
+	s.edges[edge] = true
+	if s.reachable[to] {
+		// A new way in can change the phis
+		for _, v := range to.Values {
+			if v.Op == ir.OpPhi {
+				s.visit(v)
+			}
+		}
+		return
+	}
+	s.markReachable(to)
+}
+
+// visitControl marks the edges out of b that can be taken
+func (s *sccp) visitControl(b *ir.Block) {
+	if b.Kind != ir.BlockIf {
+		for _, succ := range b.Succs {
+			s.markEdge(b, succ)
+		}
+		return
+	}
+	c := s.cells[b.Control]
+	switch c.state {
+	case constant:
+		if truthy(c.value) {
+			s.markEdge(b, b.Succs[0])
+		} else {
+			s.markEdge(b, b.Succs[1])
+		}
+	case varying:
+		s.markEdge(b, b.Succs[0])
+		s.markEdge(b, b.Succs[1])
+	}
+}
+
+// visit recomputes the cell of v, queueing its users if it changed
+func (s *sccp) visit(v *ir.Value) {
+	old := s.cells[v]
+	if old.state == varying {
+		return
+	}
+	c := s.evaluate(v)
+	if c.state == old.state && (c.state != constant || equalConst(c.value, old.value)) {
+		return
+	}
+	s.cells[v] = c
+	s.values = append(s.values, v)
+}
+
+func (s *sccp) evaluate(v *ir.Value) cell {
+	switch v.Op {
+	case ir.OpConst:
+		return cell{state: constant, value: v.Aux}
+
+	case ir.OpPhi:
+		result := cell{state: unknown}
+		for i, arg := range v.Args {
+			if !s.edges[[2]*ir.Block{v.Block.Preds[i], v.Block}] {
+				continue
+			}
+			c := s.cells[arg]
+			switch {
+			case c.state == unknown:
+			case c.state == varying:
+				return c
+			case result.state == unknown:
+				result = c
+			case !equalConst(result.value, c.value):
+				return cell{state: varying}
+			}
+		}
+		return result
+	}
+
+	if !foldable(v.Op) {
+		return cell{state: varying}
+	}
+	args := make([]interface{}, len(v.Args))
+	result := cell{state: constant}
+	for i, arg := range v.Args {
+		c := s.cells[arg]
+		switch c.state {
+		case varying:
+			return c
+		case unknown:
+			result = c
+		}
+		args[i] = c.value
+	}
+	if result.state == unknown {
+		return result
+	}
+	if result, ok := fold(v.Op, args); ok {
+		return cell{state: constant, value: result}
+	}
+	return cell{state: varying}
+}
+
+// rewrite replaces constant values and folds constant branches
+func (s *sccp) rewrite() bool {
+	// Branches first: folding values replaces the controls
+	changed := false
+	for _, b := range s.f.Blocks {
+		if b.Kind != ir.BlockIf || !s.reachable[b] {
+			continue
+		}
+		c := s.cells[b.Control]
+		if c.state != constant {
+			continue
+		}
+		taken := 0
+		if !truthy(c.value) {
+			taken = 1
+		}
+		b.RemoveSucc(1 - taken)
+		b.Kind = ir.BlockPlain
+		b.Control = nil
+		changed = true
+	}
+	var folded []*ir.Value
+	uses := s.f.Uses()
+	for _, b := range s.f.Blocks {
+		for _, v := range b.Values {
+			if v.Op != ir.OpConst && s.cells[v].state == constant && uses[v] > 0 {
+				folded = append(folded, v)
+			}
+		}
+	}
+	changed = changed || len(folded) > 0
+
+	// Floats are not shared: 0.0 and -0.0 are equal map keys
+	consts := make(map[interface{}]*ir.Value)
+	for _, v := range folded {
+		c := s.cells[v].value
+		k, ok := consts[c]
+		if !ok {
+			k = s.f.NewConst(c)
+			if _, isFloat := c.(float64); !isFloat {
+				consts[c] = k
+			}
+		}
+		s.f.ReplaceUses(v, k)
+	}
+	if changed {
+		s.f.RemoveUnreachable()
+	}
+	return changed
+}
+
+// foldable reports whether fold knows op
+func foldable(op ir.Op) bool {
+	switch op {
+	case ir.OpAdd, ir.OpSub, ir.OpMul, ir.OpDiv, ir.OpMod,
+		ir.OpEq, ir.OpNe, ir.OpLt, ir.OpLe, ir.OpGt, ir.OpGe,
+		ir.OpNeg, ir.OpPos, ir.OpNot, ir.OpBool:
+		return true
+	}
+	return false
+}
+
+// fold computes op on constants; it fails where the operation would
+func fold(op ir.Op, args []interface{}) (interface{}, bool) {
+	switch op {
+	case ir.OpNot:
+		return !truthy(args[0]), true
+	case ir.OpBool:
+		return truthy(args[0]), true
+	case ir.OpPos:
+		return args[0], true
+	case ir.OpNeg:
+		switch x := args[0].(type) {
+		case int64:
+			return -x, true
+		case float64:
+			return -x, true
+		}
+		return nil, false
+	}
+
+	x, y := args[0], args[1]
+	if op == ir.OpAdd {
+		xs, xok := x.(string)
+		ys, yok := y.(string)
+		switch {
+		case xok && yok:
+			return xs + ys, true
+		case xok:
+			if s, ok := formatConst(y); ok {
+				return xs + s, true
+			}
+			return nil, false
+		case yok:
+			if s, ok := formatConst(x); ok {
+				return s + ys, true
+			}
+			return nil, false
+		}
+	}
+
+	switch x := x.(type) {
+	case int64:
+		if y, ok := y.(int64); ok {
+			return foldInt(op, x, y)
+		}
+	case float64:
+		if y, ok := y.(float64); ok {
+			switch op {
+			case ir.OpAdd:
+				return x + y, true
+			case ir.OpSub:
+				return x - y, true
+			case ir.OpMul:
+				return x * y, true
+			case ir.OpDiv:
+				return x / y, true
+			}
+		}
+	case string:
+		if y, ok := y.(string); ok {
+			switch op {
+			case ir.OpEq:
+				return x == y, true
+			case ir.OpNe:
+				return x != y, true
+			}
+		}
+	}
+
+	// nil equals only nil
+	if x == nil || y == nil {
+		switch op {
+		case ir.OpEq:
+			return x == nil && y == nil, true
+		case ir.OpNe:
+			return !(x == nil && y == nil), true
+		}
+	}
+	return nil, false
+}
+
+func foldInt(op ir.Op, x, y int64) (interface{}, bool) {
+	switch op {
+	case ir.OpAdd:
+		return x + y, true
+	case ir.OpSub:
+		return x - y, true
+	case ir.OpMul:
+		return x * y, true
+	case ir.OpDiv:
+		if y == 0 {
+			return nil, false
+		}
+		return x / y, true
+	case ir.OpMod:
+		if y == 0 {
+			return nil, false
+		}
+		return x % y, true
+	case ir.OpEq:
+		return x == y, true
+	case ir.OpNe:
+		return x != y, true
+	case ir.OpLt:
+		return x < y, true
+	case ir.OpLe:
+		return x <= y, true
+	case ir.OpGt:
+		return x > y, true
+	case ir.OpGe:
+		return x >= y, true
+	}
+	return nil, false
+}
+
+// formatConst formats a constant the way + formats it next to a string
+func formatConst(c interface{}) (string, bool) {
+	switch c := c.(type) {
+	case int64:
+		return fmt.Sprintf("%d", c), true
+	case float64:
+		return fmt.Sprintf("%g", c), true
+	case bool:
+		return fmt.Sprintf("%v", c), true
+	}
+	return "", false
+}
+
+// truthy follows the interpreter's IsTruthy rules for constants
+func truthy(c interface{}) bool {
+	switch c := c.(type) {
+	case int64:
+		return c != 0
+	case float64:
+		return c != 0
+	case string:
+		return c != ""
+	case bool:
+		return c
+	}
+	return false
+}
+
+// equalConst compares constants by type and value; NaN equals itself so
+// the lattice settles
+func equalConst(a, b interface{}) bool {
+	if x, ok := a.(float64); ok {
+		y, ok := b.(float64)
+		return ok && (x == y || x != x && y != y)
+	}
+	return a == b
+}
+
+// NewIRPasses returns the passes run on the SSA IR at optimization level
+// level: none at 0, constant propagation and cleanups above
+func NewIRPasses(level int) *ir.PassManager {
+	pm := ir.NewPassManager()
+	if level > 0 {
+		pm.Add(ConstProp{})
+		pm.Add(ir.DeadCode{})
+		pm.Add(ir.Fuse{})
+	}
+	return pm
+}
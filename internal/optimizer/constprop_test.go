@@ -0,0 +1,163 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/ir"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+	"github.com/mburakmmm/sky-lang/internal/parser"
+	"github.com/mburakmmm/sky-lang/internal/sema"
+)
+
+func TestConstPropFoldsBranches(t *testing.T) {
+	f := optimize(t, `function f(): any
+  let x = 2 * 3
+  if x > 5
+    return "big"
+  return "small"`, "f")
+
+	if len(f.Blocks) != 1 {
+		t.Fatalf("branch on a constant not folded:\n%s", f)
+	}
+	ret := f.Entry().Control
+	if ret == nil || !ret.IsConst() || ret.Aux != "big" {
+		t.Errorf("f returns %v, want \"big\":\n%s", ret, f)
+	}
+}
+
+func TestConstPropThroughLoops(t *testing.T) {
+	// a is 1 on every path into the loop, b is not
+	f := optimize(t, `function f(n: int): any
+  let a = 1
+  let b = 0
+  while n > 0
+    a = a * 1
+    b = b + 1
+    n = n - 1
+  return a + b`, "f")
+
+	var adds int
+	f.Values(func(v *ir.Value) {
+		if v.Op == ir.OpPhi && v.Name == "a" {
+			t.Errorf("phi of a constant kept:\n%s", f)
+		}
+		if v.Op == ir.OpAdd && v.Args[0].IsConst() && v.Args[0].Aux == int64(1) && v.Args[1].Name == "b" {
+			adds++
+		}
+	})
+	if adds != 1 {
+		t.Errorf("a not replaced by 1 in the result:\n%s", f)
+	}
+}
+
+func TestConstPropKeepsFailures(t *testing.T) {
+	f := optimize(t, `function f(): any
+  let zero = 0
+  return 10 / zero`, "f")
+
+	div := false
+	f.Values(func(v *ir.Value) {
+		if v.Op == ir.OpDiv {
+			div = true
+		}
+	})
+	if !div {
+		t.Errorf("division by zero folded away:\n%s", f)
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		op   ir.Op
+		args []interface{}
+		want interface{}
+		ok   bool
+	}{
+		{ir.OpAdd, []interface{}{int64(2), int64(3)}, int64(5), true},
+		{ir.OpDiv, []interface{}{int64(7), int64(2)}, int64(3), true},
+		{ir.OpMod, []interface{}{int64(7), int64(0)}, nil, false},
+		{ir.OpAdd, []interface{}{int64(1), 2.5}, nil, false},
+		{ir.OpLt, []interface{}{1.0, 2.0}, nil, false},
+		{ir.OpAdd, []interface{}{"n=", int64(4)}, "n=4", true},
+		{ir.OpAdd, []interface{}{1.5, "x"}, "1.5x", true},
+		{ir.OpEq, []interface{}{"a", "a"}, true, true},
+		{ir.OpEq, []interface{}{nil, int64(0)}, false, true},
+		{ir.OpEq, []interface{}{true, true}, nil, false},
+		{ir.OpNot, []interface{}{""}, true, true},
+		{ir.OpNeg, []interface{}{"x"}, nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := fold(tt.op, tt.args)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%s %v = %v, %v; want %v, %v", tt.op, tt.args, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestEscapeAnalysis(t *testing.T) {
+	program := parse(t, `function f(): any
+  let local = [1, 2]
+  let kept = [3]
+  let returned = [kept]
+  print(len(local))
+  return returned
+
+function g(): any
+  let passed = {"a": 1}
+  let quiet = {"b": 2}
+  print(passed)
+  return 0`)
+
+	ea := NewEscapeAnalyzer()
+	escapes := ea.Analyze(program)
+	for _, name := range []string{"returned", "kept", "passed"} {
+		if !escapes[name] {
+			t.Errorf("%s does not escape", name)
+		}
+	}
+	for _, name := range []string{"quiet"} {
+		if !ea.CanStackAllocate(name) {
+			t.Errorf("%s escapes", name)
+		}
+	}
+	// Passing local to len lets it escape too: calls are opaque
+	if ea.CanStackAllocate("local") {
+		t.Error("argument of a call does not escape")
+	}
+}
+
+func optimize(t *testing.T, input, name string) *ir.Func {
+	t.Helper()
+	program := parse(t, input)
+	checker := sema.NewChecker()
+	if errs := checker.Check(program); len(errs) > 0 {
+		t.Fatalf("check errors: %v", errs)
+	}
+	m, err := ir.Lower(program, "test", checker.Types())
+	if err != nil {
+		t.Fatal(err)
+	}
+	passes := NewIRPasses(1)
+	passes.Verify = true
+	if err := passes.Run(m); err != nil {
+		t.Fatalf("%v\n%s", err, m)
+	}
+	for _, f := range m.Funcs {
+		if f.Name == name {
+			return f
+		}
+	}
+	t.Fatalf("no function %s", name)
+	return nil
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input, "test.sky"))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parse errors: %v", p.Errors())
+	}
+	return program
+}
@@ -2,84 +2,127 @@ package optimizer
 
 import (
 	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/ir"
 )
 
-// EscapeAnalyzer analyzes variable escape behavior
+// EscapeAnalyzer analyzes variable escape behavior on the SSA IR. A value
+// escapes when it may outlive the function creating it or be seen by
+// other code: it is returned, thrown, yielded, stored in a global, a
+// member or a container, passed to a call or captured by a closure.
+// Escape flows backwards through phis, and a value stored in a box
+// escapes when the box is captured or what is loaded from it escapes.
 type EscapeAnalyzer struct {
-	escapes map[string]bool // variable name -> escapes?
+	escapes map[string]bool    // variable name -> escapes?
+	values  map[*ir.Value]bool // escaping values of the analyzed functions
 }
 
 // NewEscapeAnalyzer creates a new escape analyzer
 func NewEscapeAnalyzer() *EscapeAnalyzer {
 	return &EscapeAnalyzer{
 		escapes: make(map[string]bool),
+		values:  make(map[*ir.Value]bool),
 	}
 }
 
-// Analyze performs escape analysis on a program
+// Analyze performs escape analysis on a program. Variables are reported
+// by name; one escaping definition makes the name escape.
 func (ea *EscapeAnalyzer) Analyze(program *ast.Program) map[string]bool {
-	for _, stmt := range program.Statements {
-		ea.analyzeStatement(stmt)
+	module, err := ir.Lower(program, "", nil)
+	if err != nil {
+		return ea.escapes
 	}
+	ea.AnalyzeModule(module)
 	return ea.escapes
 }
 
-func (ea *EscapeAnalyzer) analyzeStatement(stmt ast.Statement) {
-	switch s := stmt.(type) {
-	case *ast.LetStatement:
-		// Check if variable escapes
-		if ea.valueEscapes(s.Value) {
-			ea.escapes[s.Name.Value] = true
+// AnalyzeModule performs escape analysis on every function of m
+func (ea *EscapeAnalyzer) AnalyzeModule(m *ir.Module) {
+	for _, f := range m.Funcs {
+		ea.AnalyzeFunc(f)
+	}
+}
+
+// AnalyzeFunc performs escape analysis on f
+func (ea *EscapeAnalyzer) AnalyzeFunc(f *ir.Func) {
+	var work []*ir.Value
+	mark := func(v *ir.Value) {
+		if v != nil && !ea.values[v] {
+			ea.values[v] = true
+			work = append(work, v)
 		}
-	case *ast.FunctionStatement:
-		// Analyze function body
-		for _, bodyStmt := range s.Body.Statements {
-			ea.analyzeStatement(bodyStmt)
+	}
+
+	stored := make(map[*ir.Value][]*ir.Value) // box -> values stored in it
+	for _, b := range f.Blocks {
+		switch b.Kind {
+		case ir.BlockReturn, ir.BlockThrow:
+			mark(b.Control)
 		}
-	case *ast.ReturnStatement:
-		// Returned values escape
-		if s.ReturnValue != nil {
-			ea.markEscape(s.ReturnValue)
+		for _, v := range b.Values {
+			switch v.Op {
+			case ir.OpStore:
+				stored[v.Args[0]] = append(stored[v.Args[0]], v.Args[1])
+			case ir.OpFreeVar:
+				// Boxes of enclosing functions are shared
+				mark(v)
+			}
+			for _, arg := range escapingArgs(v) {
+				mark(arg)
+			}
+		}
+	}
+	for len(work) > 0 {
+		v := work[len(work)-1]
+		work = work[:len(work)-1]
+		switch v.Op {
+		case ir.OpPhi:
+			for _, arg := range v.Args {
+				mark(arg)
+			}
+		case ir.OpLoad:
+			mark(v.Args[0])
+		}
+		for _, w := range stored[v] {
+			mark(w)
 		}
 	}
-}
 
-func (ea *EscapeAnalyzer) valueEscapes(expr ast.Expression) bool {
-	switch e := expr.(type) {
-	case *ast.CallExpression:
-		// Function calls may cause escape
-		return true
-	case *ast.ListLiteral:
-		// Lists may escape
-		return true
-	case *ast.DictLiteral:
-		// Dicts may escape
-		return true
-	case *ast.Identifier:
-		// Check if identifier escapes
-		return ea.escapes[e.Value]
-	default:
-		return false
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			if v.Name != "" && ea.values[v] {
+				ea.escapes[v.Name] = true
+			}
+		}
 	}
 }
 
-func (ea *EscapeAnalyzer) markEscape(expr ast.Expression) {
-	switch e := expr.(type) {
-	case *ast.Identifier:
-		ea.escapes[e.Value] = true
-	case *ast.ListLiteral:
-		for _, elem := range e.Elements {
-			ea.markEscape(elem)
-		}
-	case *ast.DictLiteral:
-		for _, val := range e.Pairs {
-			ea.markEscape(val)
-		}
+// escapingArgs returns the arguments v lets escape
+func escapingArgs(v *ir.Value) []*ir.Value {
+	switch v.Op {
+	case ir.OpSetGlobal, ir.OpYield, ir.OpAwait:
+		return v.Args
+	case ir.OpSetMember:
+		return v.Args[1:]
+	case ir.OpSetIndex:
+		return v.Args[2:]
+	case ir.OpCall:
+		// Calling a function does not leak it
+		return v.Args[1:]
+	case ir.OpInvoke, ir.OpSuper:
+		// The receiver becomes self of the method
+		return v.Args
+	case ir.OpList, ir.OpDict, ir.OpClosure, ir.OpClass, ir.OpEnum:
+		return v.Args
 	}
+	return nil
+}
+
+// Escapes reports whether v escapes; v must belong to an analyzed function
+func (ea *EscapeAnalyzer) Escapes(v *ir.Value) bool {
+	return ea.values[v]
 }
 
 // CanStackAllocate checks if a variable can be stack-allocated
 func (ea *EscapeAnalyzer) CanStackAllocate(varName string) bool {
 	return !ea.escapes[varName]
 }
-
@@ -11,7 +11,22 @@ type ProfileData struct {
 	HotFunctions map[string]int64            `json:"hot_functions"`
 	HotPaths     map[string]int64            `json:"hot_paths"`
 	TypeFeedback map[string]map[string]int64 `json:"type_feedback"`
-	BranchProbs  map[string]float64          `json:"branch_probs"`
+	Branches     map[string]BranchCount      `json:"branches"`
+	Source       string                      `json:"source,omitempty"` // hash of the profiled program
+}
+
+// BranchCount counts how often a conditional branch ran and was taken
+type BranchCount struct {
+	Taken int64 `json:"taken"`
+	Total int64 `json:"total"`
+}
+
+// Probability returns the share of runs that took the branch
+func (b BranchCount) Probability() float64 {
+	if b.Total == 0 {
+		return 0
+	}
+	return float64(b.Taken) / float64(b.Total)
 }
 
 // PGOProfiler collects profile data during execution
@@ -27,11 +42,19 @@ func NewPGOProfiler() *PGOProfiler {
 			HotFunctions: make(map[string]int64),
 			HotPaths:     make(map[string]int64),
 			TypeFeedback: make(map[string]map[string]int64),
-			BranchProbs:  make(map[string]float64),
+			Branches:     make(map[string]BranchCount),
 		},
 	}
 }
 
+// SetSource records which program the profile belongs to, so stale
+// profiles can be detected when they are used
+func (p *PGOProfiler) SetSource(hash string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.data.Source = hash
+}
+
 // RecordFunctionCall records a function call
 func (p *PGOProfiler) RecordFunctionCall(funcName string) {
 	p.mu.Lock()
@@ -62,12 +85,12 @@ func (p *PGOProfiler) RecordBranch(branchID string, taken bool) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	// Calculate running probability
+	count := p.data.Branches[branchID]
+	count.Total++
 	if taken {
-		p.data.BranchProbs[branchID] = (p.data.BranchProbs[branchID]*0.9 + 1.0*0.1)
-	} else {
-		p.data.BranchProbs[branchID] = p.data.BranchProbs[branchID] * 0.9
+		count.Taken++
 	}
+	p.data.Branches[branchID] = count
 }
 
 // SaveProfile saves profile data to file
@@ -1,10 +1,11 @@
 package parser
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 	"github.com/mburakmmm/sky-lang/internal/lexer"
 )
 
@@ -145,13 +146,17 @@ func (p *Parser) Errors() []string {
 }
 
 func (p *Parser) peekError(t lexer.TokenType) {
-	msg := fmt.Sprintf("expected next token to be %s, got %s instead at %s",
-		t, p.peekToken.Type, p.peekToken.Position())
-	p.errors = append(p.errors, msg)
+	msg := i18n.T("parser.expected_token", t, p.peekToken.Type, p.peekToken.Position())
+	p.report(diag.UnexpectedToken, msg)
 }
 
 func (p *Parser) addError(msg string) {
-	p.errors = append(p.errors, fmt.Sprintf("%s at %s", msg, p.curToken.Position()))
+	p.report(diag.InvalidSyntax, i18n.T("parser.at", msg, p.curToken.Position()))
+}
+
+// report records an error followed by its diagnostic code
+func (p *Parser) report(code, msg string) {
+	p.errors = append(p.errors, msg+" ["+code+"]")
 }
 
 func (p *Parser) nextToken() {
@@ -258,6 +263,8 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseUnsafeStatement()
 	case lexer.ENUM:
 		return p.parseEnumStatement()
+	case lexer.INTERFACE:
+		return p.parseInterfaceStatement()
 	case lexer.TRY:
 		return p.parseTryStatement()
 	case lexer.THROW:
@@ -407,8 +414,8 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 }
 
 func (p *Parser) noPrefixParseFnError(t lexer.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	msg := i18n.T("parser.no_prefix", t)
+	p.report(diag.ExpectedExpression, msg)
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
@@ -483,8 +490,8 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	}
 
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		msg := i18n.T("parser.invalid_integer", p.curToken.Literal)
+		p.report(diag.InvalidNumber, msg)
 		return nil
 	}
 
@@ -497,8 +504,8 @@ func (p *Parser) parseFloatLiteral() ast.Expression {
 
 	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		msg := i18n.T("parser.invalid_float", p.curToken.Literal)
+		p.report(diag.InvalidNumber, msg)
 		return nil
 	}
 
@@ -792,9 +799,13 @@ func (p *Parser) parseTypeAnnotation() ast.TypeAnnotation {
 		typeName := p.curToken.Literal
 		token := p.curToken
 
-		// Check for generic type: List<T>
-		if p.peekTokenIs(lexer.LT) {
-			p.nextToken() // <
+		// Check for generic type: List<T> or Box[T]
+		if p.peekTokenIs(lexer.LT) || p.peekTokenIs(lexer.LBRACK) {
+			closing := lexer.GT
+			if p.peekTokenIs(lexer.LBRACK) {
+				closing = lexer.RBRACK
+			}
+			p.nextToken() // < or [
 			p.nextToken() // first type arg
 
 			typeArgs := []ast.TypeAnnotation{}
@@ -806,7 +817,7 @@ func (p *Parser) parseTypeAnnotation() ast.TypeAnnotation {
 				typeArgs = append(typeArgs, p.parseTypeAnnotation())
 			}
 
-			if !p.expectPeek(lexer.GT) {
+			if !p.expectPeek(closing) {
 				return nil
 			}
 
@@ -850,7 +861,7 @@ func (p *Parser) parseTypeAnnotation() ast.TypeAnnotation {
 			ValueType: valueType,
 		}
 	default:
-		p.addError(fmt.Sprintf("unexpected type: %s", p.curToken.Literal))
+		p.addError(i18n.T("parser.unexpected_type", p.curToken.Literal))
 		return nil
 	}
 
@@ -958,7 +969,7 @@ func (p *Parser) parseEnumStatement() *ast.EnumStatement {
 
 	// Expect END
 	if !p.curTokenIs(lexer.END) {
-		p.addError("expected 'end' to close enum")
+		p.addError(i18n.T("parser.enum_end"))
 		return stmt
 	}
 
@@ -1006,12 +1017,14 @@ func (p *Parser) parseMatchExpression() ast.Expression {
 
 			arm := &ast.MatchArm{}
 
-			// Parse pattern
-			arm.Pattern = p.parseExpression(LOWEST)
+			// Parse pattern and optional guard
+			if !p.parseMatchArmHead(arm) {
+				return expr
+			}
 
 			// Expect ARROW =>
 			if !p.expectPeek(lexer.ARROW) {
-				p.addError("expected => in match arm")
+				p.addError(i18n.T("parser.match_arrow"))
 				return expr
 			}
 
@@ -1039,7 +1052,7 @@ func (p *Parser) parseMatchExpression() ast.Expression {
 		}
 
 		if !p.expectPeek(lexer.RBRACE) {
-			p.addError("expected } in match expression")
+			p.addError(i18n.T("parser.match_brace"))
 			return expr
 		}
 
@@ -1069,12 +1082,14 @@ func (p *Parser) parseMatchExpression() ast.Expression {
 
 		arm := &ast.MatchArm{}
 
-		// Parse pattern
-		arm.Pattern = p.parseExpression(LOWEST)
+		// Parse pattern and optional guard
+		if !p.parseMatchArmHead(arm) {
+			return expr
+		}
 
 		// Expect ARROW =>
 		if !p.expectPeek(lexer.ARROW) {
-			p.addError("expected => in match arm")
+			p.addError(i18n.T("parser.match_arrow"))
 			return expr
 		}
 
@@ -1097,6 +1112,11 @@ func (p *Parser) parseMatchExpression() ast.Expression {
 		p.nextToken()
 	}
 
+	// Kolların DEDENT'inden sonra match'i kapatan END'e geç
+	if p.curTokenIs(lexer.DEDENT) && p.peekTokenIs(lexer.END) {
+		p.nextToken()
+	}
+
 	return expr
 }
 
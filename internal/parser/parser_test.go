@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/mburakmmm/sky-lang/internal/ast"
@@ -409,6 +410,223 @@ func TestListLiteral(t *testing.T) {
 	}
 }
 
+func TestInterfaceStatement(t *testing.T) {
+	input := `interface Shape : Named
+  function area(): float
+  function scale(factor: float)
+end
+
+class Circle implements Shape, Named
+  function area(): float
+    return 1.0
+  end
+end`
+
+	l := lexer.New(input, "test.sky")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d",
+			len(program.Statements))
+	}
+
+	iface, ok := program.Statements[0].(*ast.InterfaceStatement)
+	if !ok {
+		t.Fatalf("stmt is not *ast.InterfaceStatement. got=%T", program.Statements[0])
+	}
+
+	if iface.Name.Value != "Shape" {
+		t.Fatalf("iface.Name.Value not 'Shape'. got=%s", iface.Name.Value)
+	}
+
+	if len(iface.Extends) != 1 || iface.Extends[0].Value != "Named" {
+		t.Fatalf("iface.Extends wrong. got=%v", iface.Extends)
+	}
+
+	if len(iface.Methods) != 2 {
+		t.Fatalf("iface.Methods has wrong length. got=%d", len(iface.Methods))
+	}
+
+	if len(iface.Methods[1].Parameters) != 1 {
+		t.Fatalf("scale should have 1 parameter. got=%d", len(iface.Methods[1].Parameters))
+	}
+
+	class, ok := program.Statements[1].(*ast.ClassStatement)
+	if !ok {
+		t.Fatalf("stmt is not *ast.ClassStatement. got=%T", program.Statements[1])
+	}
+
+	if len(class.Interfaces) != 2 {
+		t.Fatalf("class.Interfaces has wrong length. got=%d", len(class.Interfaces))
+	}
+}
+
+func TestGenericTypeParameters(t *testing.T) {
+	input := `function first[T, U: Named](xs: [T], y: U): Box[T]
+  return xs[0]
+end
+
+class Box[T]
+  function get(): T
+    return self.value
+  end
+end`
+
+	l := lexer.New(input, "test.sky")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	fn, ok := program.Statements[0].(*ast.FunctionStatement)
+	if !ok {
+		t.Fatalf("stmt is not *ast.FunctionStatement. got=%T", program.Statements[0])
+	}
+
+	if len(fn.TypeParams) != 2 {
+		t.Fatalf("fn.TypeParams has wrong length. got=%d", len(fn.TypeParams))
+	}
+
+	if fn.TypeParams[1].Constraint == nil || fn.TypeParams[1].Constraint.String() != "Named" {
+		t.Fatalf("U should be bounded by Named. got=%v", fn.TypeParams[1].Constraint)
+	}
+
+	generic, ok := fn.ReturnType.(*ast.GenericType)
+	if !ok {
+		t.Fatalf("return type is not *ast.GenericType. got=%T", fn.ReturnType)
+	}
+
+	if generic.BaseName != "Box" || len(generic.TypeArgs) != 1 {
+		t.Fatalf("return type wrong. got=%s", generic.String())
+	}
+
+	class, ok := program.Statements[1].(*ast.ClassStatement)
+	if !ok {
+		t.Fatalf("stmt is not *ast.ClassStatement. got=%T", program.Statements[1])
+	}
+
+	if len(class.TypeParams) != 1 || class.TypeParams[0].Name.Value != "T" {
+		t.Fatalf("class.TypeParams wrong. got=%v", class.TypeParams)
+	}
+}
+
+func TestMatchExpressionArms(t *testing.T) {
+	input := `let r = match value
+  nil => 0
+  Some(x) => x
+end`
+
+	l := lexer.New(input, "test.sky")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.LetStatement)
+	if !ok {
+		t.Fatalf("stmt is not *ast.LetStatement. got=%T", program.Statements[0])
+	}
+
+	match, ok := stmt.Value.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("value is not *ast.MatchExpression. got=%T", stmt.Value)
+	}
+
+	if len(match.Arms) != 2 {
+		t.Fatalf("match.Arms has wrong length. got=%d", len(match.Arms))
+	}
+
+	if _, ok := match.Arms[0].Pattern.(*ast.Identifier); !ok {
+		t.Fatalf("first pattern is not *ast.Identifier. got=%T", match.Arms[0].Pattern)
+	}
+
+	if _, ok := match.Arms[1].Pattern.(*ast.CallExpression); !ok {
+		t.Fatalf("second pattern is not *ast.CallExpression. got=%T", match.Arms[1].Pattern)
+	}
+}
+
+func TestMatchExpressionInsideFunction(t *testing.T) {
+	input := `function f(b)
+  match b
+    true => print(1)
+  end
+  print(2)
+end`
+
+	l := lexer.New(input, "test.sky")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements has wrong length. got=%d", len(program.Statements))
+	}
+
+	fn, ok := program.Statements[0].(*ast.FunctionStatement)
+	if !ok {
+		t.Fatalf("stmt is not *ast.FunctionStatement. got=%T", program.Statements[0])
+	}
+
+	if len(fn.Body.Statements) != 2 {
+		t.Fatalf("function body has wrong length. got=%d", len(fn.Body.Statements))
+	}
+}
+
+func TestMatchRichPatterns(t *testing.T) {
+	input := `match value
+  Some(Circle(r)) if r > 10 => 1
+  Red() | Green() => 2
+  Rect(w, h) as shape => 3
+  [first, ...rest] => 4
+  {"type": "click", "id": id} => 5
+  Point(x: 0, y: y) => 6
+  -1 => 7
+end`
+
+	l := lexer.New(input, "test.sky")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt is not *ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+	match, ok := stmt.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("expression is not *ast.MatchExpression. got=%T", stmt.Expression)
+	}
+	if len(match.Arms) != 7 {
+		t.Fatalf("match.Arms has wrong length. got=%d", len(match.Arms))
+	}
+
+	if match.Arms[0].Guard == nil || match.Arms[0].Guard.String() != "(r > 10)" {
+		t.Errorf("first arm guard wrong. got=%v", match.Arms[0].Guard)
+	}
+
+	expected := []string{
+		"Some(Circle(r))",
+		"Red() | Green()",
+		"Rect(w, h) as shape",
+		"[first, ...rest]",
+		`{"type": "click", "id": id}`,
+		"Point(x: 0, y: y)",
+		"(-1)",
+	}
+	for i, want := range expected {
+		if got := match.Arms[i].Pattern.String(); got != want {
+			t.Errorf("arm %d pattern wrong. want=%q, got=%q", i, want, got)
+		}
+	}
+
+	if _, ok := match.Arms[1].Pattern.(*ast.OrPattern); !ok {
+		t.Errorf("second pattern is not *ast.OrPattern. got=%T", match.Arms[1].Pattern)
+	}
+	if _, ok := match.Arms[5].Pattern.(*ast.ClassPattern); !ok {
+		t.Errorf("class pattern is not *ast.ClassPattern. got=%T", match.Arms[5].Pattern)
+	}
+}
+
 // Helper functions
 
 func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
@@ -450,3 +668,28 @@ func checkParserErrors(t *testing.T, p *Parser) {
 	t.FailNow()
 }
 
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		input string
+		code  string
+	}{
+		{"function add(a, b\nend", "[E0001]"},
+		{"let x = 1 + * 2", "[E0002]"},
+		{"let x = 99999999999999999999", "[E0003]"},
+		{"match x\n  ...rest => 1\nend", "[E0004]"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input, "test.sky"))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("%q: expected a parse error", tt.input)
+			continue
+		}
+		if !strings.HasSuffix(errors[0], tt.code) {
+			t.Errorf("%q: first error %q does not end in %s", tt.input, errors[0], tt.code)
+		}
+	}
+}
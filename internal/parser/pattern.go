@@ -0,0 +1,212 @@
+package parser
+
+import (
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+)
+
+// parsePattern match kolu pattern'ini parse eder
+//
+//	pattern := primary ("|" primary)* ["as" IDENT]
+//	primary := "_" | IDENT | literal | Variant(p, ...) | Class(field: p, ...)
+//	         | "[" p, ..., ...rest "]" | "{" literal: p, ... "}"
+func (p *Parser) parsePattern() ast.Expression {
+	pattern := p.parsePatternPrimary()
+	if pattern == nil {
+		return nil
+	}
+
+	if p.peekTokenIs(lexer.PIPE) {
+		or := &ast.OrPattern{Token: p.peekToken, Alternatives: []ast.Expression{pattern}}
+		for p.peekTokenIs(lexer.PIPE) {
+			p.nextToken() // |
+			p.nextToken()
+			alt := p.parsePatternPrimary()
+			if alt == nil {
+				return nil
+			}
+			or.Alternatives = append(or.Alternatives, alt)
+		}
+		pattern = or
+	}
+
+	if p.peekTokenIs(lexer.AS) {
+		p.nextToken()
+		as := &ast.AsPattern{Token: p.curToken, Pattern: pattern}
+		if !p.expectPeek(lexer.IDENT) {
+			return nil
+		}
+		as.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		pattern = as
+	}
+
+	return pattern
+}
+
+// parsePatternPrimary tek bir (alternatifsiz) pattern parse eder
+func (p *Parser) parsePatternPrimary() ast.Expression {
+	switch p.curToken.Type {
+	case lexer.IDENT:
+		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if !p.peekTokenIs(lexer.LPAREN) {
+			return ident
+		}
+		p.nextToken()
+		return p.parseConstructorPattern(ident)
+
+	case lexer.LBRACK:
+		return p.parseListPattern()
+
+	case lexer.LBRACE:
+		return p.parseDictPattern()
+
+	case lexer.INT, lexer.FLOAT, lexer.STRING, lexer.TRUE, lexer.FALSE, lexer.MINUS:
+		return p.parseExpression(PREFIX)
+
+	case lexer.ELLIPSIS:
+		p.addError(i18n.T("parser.rest_pattern"))
+		return nil
+	}
+
+	p.addError(i18n.T("parser.unexpected_in_pattern", p.curToken.Type))
+	return nil
+}
+
+// parseConstructorPattern Variant(p, ...) veya Class(field: p, ...) parse eder
+// curToken LPAREN
+func (p *Parser) parseConstructorPattern(name *ast.Identifier) ast.Expression {
+	call := &ast.CallExpression{Token: p.curToken, Function: name}
+	var fields []*ast.FieldPattern
+
+	for !p.peekTokenIs(lexer.RPAREN) {
+		p.nextToken()
+		if p.curTokenIs(lexer.IDENT) && p.peekTokenIs(lexer.COLON) {
+			field := &ast.FieldPattern{Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}}
+			p.nextToken() // :
+			p.nextToken()
+			if field.Pattern = p.parsePattern(); field.Pattern == nil {
+				return nil
+			}
+			fields = append(fields, field)
+		} else {
+			arg := p.parsePattern()
+			if arg == nil {
+				return nil
+			}
+			call.Arguments = append(call.Arguments, arg)
+		}
+
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(lexer.RPAREN) {
+		return nil
+	}
+
+	if len(fields) == 0 {
+		return call
+	}
+	if len(call.Arguments) > 0 {
+		p.addError(i18n.T("parser.mixed_patterns", name.Value))
+		return nil
+	}
+	return &ast.ClassPattern{Token: name.Token, Class: name, Fields: fields}
+}
+
+// parseListPattern [p, ..., ...rest] parse eder; curToken LBRACK
+func (p *Parser) parseListPattern() ast.Expression {
+	list := &ast.ListLiteral{Token: p.curToken}
+	hasRest := false
+
+	for !p.peekTokenIs(lexer.RBRACK) {
+		p.nextToken()
+
+		var elem ast.Expression
+		if p.curTokenIs(lexer.ELLIPSIS) {
+			if hasRest {
+				p.addError(i18n.T("parser.multiple_rest"))
+				return nil
+			}
+			hasRest = true
+			rest := &ast.RestPattern{Token: p.curToken}
+			if p.peekTokenIs(lexer.IDENT) {
+				p.nextToken()
+				rest.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			}
+			elem = rest
+		} else if elem = p.parsePattern(); elem == nil {
+			return nil
+		}
+		list.Elements = append(list.Elements, elem)
+
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(lexer.RBRACK) {
+		return nil
+	}
+	return list
+}
+
+// parseDictPattern {"key": p, ...} parse eder; curToken LBRACE
+func (p *Parser) parseDictPattern() ast.Expression {
+	dict := &ast.DictPattern{Token: p.curToken}
+
+	for !p.peekTokenIs(lexer.RBRACE) {
+		p.nextToken()
+
+		switch p.curToken.Type {
+		case lexer.STRING, lexer.INT, lexer.FLOAT, lexer.TRUE, lexer.FALSE, lexer.MINUS:
+		default:
+			p.addError(i18n.T("parser.dict_pattern_keys"))
+			return nil
+		}
+		key := p.parseExpression(PREFIX)
+
+		if !p.expectPeek(lexer.COLON) {
+			return nil
+		}
+		p.nextToken()
+
+		value := p.parsePattern()
+		if value == nil {
+			return nil
+		}
+		dict.Keys = append(dict.Keys, key)
+		dict.Values = append(dict.Values, value)
+
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(lexer.RBRACE) {
+		return nil
+	}
+	return dict
+}
+
+// parseMatchArmHead kolun pattern'ini ve opsiyonel guard'ını parse eder
+// Dönüşte curToken pattern'in (veya guard'ın) son token'ıdır
+func (p *Parser) parseMatchArmHead(arm *ast.MatchArm) bool {
+	arm.Pattern = p.parsePattern()
+	if arm.Pattern == nil {
+		return false
+	}
+
+	if p.peekTokenIs(lexer.IF) {
+		p.nextToken() // if
+		p.nextToken()
+		// => atamayla aynı önceliğe sahip, guard ondan önce durur
+		arm.Guard = p.parseExpression(ASSIGN)
+	}
+	return true
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 	"github.com/mburakmmm/sky-lang/internal/lexer"
 )
 
@@ -46,6 +47,15 @@ func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
 	}
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
+	// Generic tip parametreleri (opsiyonel): function first[T](xs: [T]): T
+	if p.peekTokenIs(lexer.LBRACK) {
+		p.nextToken()
+		stmt.TypeParams = p.parseTypeParameters()
+		if stmt.TypeParams == nil {
+			return nil
+		}
+	}
+
 	// Parametreler (opsiyonel)
 	if p.peekTokenIs(lexer.LPAREN) {
 		p.nextToken()
@@ -490,6 +500,15 @@ func (p *Parser) parseClassStatement() *ast.ClassStatement {
 	}
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
+	// Generic tip parametreleri (class Box[T])
+	if p.peekTokenIs(lexer.LBRACK) {
+		p.nextToken()
+		stmt.TypeParams = p.parseTypeParameters()
+		if stmt.TypeParams == nil {
+			return nil
+		}
+	}
+
 	// Multiple inheritance (class Duck : Flying, Swimming)
 	if p.peekTokenIs(lexer.COLON) {
 		p.nextToken() // :
@@ -512,6 +531,15 @@ func (p *Parser) parseClassStatement() *ast.ClassStatement {
 		}
 	}
 
+	// Implemented interfaces (class Circle implements Shape, Named)
+	if p.peekTokenIs(lexer.IMPLEMENTS) {
+		p.nextToken() // implements
+		stmt.Interfaces = p.parseIdentifierList()
+		if stmt.Interfaces == nil {
+			return nil
+		}
+	}
+
 	if !p.expectPeek(lexer.NEWLINE) {
 		return nil
 	}
@@ -633,3 +661,134 @@ func (p *Parser) parseUnsafeStatement() *ast.UnsafeStatement {
 
 	return stmt
 }
+
+// parseInterfaceStatement interface tanımlamasını parse eder
+func (p *Parser) parseInterfaceStatement() *ast.InterfaceStatement {
+	stmt := &ast.InterfaceStatement{Token: p.curToken}
+
+	// Interface adı
+	if !p.expectPeek(lexer.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	// Parent interface'ler (interface ReadWriter : Reader, Writer)
+	if p.peekTokenIs(lexer.COLON) {
+		p.nextToken() // :
+		stmt.Extends = p.parseIdentifierList()
+		if stmt.Extends == nil {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(lexer.NEWLINE) {
+		return nil
+	}
+
+	// Boş interface gövdesi olabilir
+	if p.peekTokenIs(lexer.END) {
+		p.nextToken()
+		return stmt
+	}
+
+	if !p.expectPeek(lexer.INDENT) {
+		return nil
+	}
+	p.nextToken()
+
+	// Metod imzaları
+	for !p.curTokenIs(lexer.DEDENT) && !p.curTokenIs(lexer.EOF) && !p.curTokenIs(lexer.END) {
+		if p.curTokenIs(lexer.NEWLINE) || p.curTokenIs(lexer.COMMENT) {
+			p.nextToken()
+			continue
+		}
+
+		if !p.curTokenIs(lexer.FUNCTION) {
+			p.addError(i18n.T("parser.interface_member", stmt.Name.Value, p.curToken.Literal))
+			return nil
+		}
+
+		method := &ast.InterfaceMethod{Token: p.curToken}
+		if !p.expectPeek(lexer.IDENT) {
+			return nil
+		}
+		method.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+		// Parametreler (opsiyonel)
+		if p.peekTokenIs(lexer.LPAREN) {
+			p.nextToken()
+			method.Parameters = p.parseFunctionParameters()
+		}
+
+		// Return type (opsiyonel)
+		if p.peekTokenIs(lexer.COLON) {
+			p.nextToken() // :
+			p.nextToken() // tip
+			method.ReturnType = p.parseTypeAnnotation()
+		}
+
+		stmt.Methods = append(stmt.Methods, method)
+		p.nextToken()
+	}
+
+	// END token
+	if p.curTokenIs(lexer.DEDENT) && p.peekTokenIs(lexer.END) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseIdentifierList virgülle ayrılmış identifier listesini parse eder
+func (p *Parser) parseIdentifierList() []*ast.Identifier {
+	if !p.expectPeek(lexer.IDENT) {
+		return nil
+	}
+	idents := []*ast.Identifier{{Token: p.curToken, Value: p.curToken.Literal}}
+
+	for p.peekTokenIs(lexer.COMMA) {
+		p.nextToken() // ,
+		if !p.expectPeek(lexer.IDENT) {
+			return nil
+		}
+		idents = append(idents, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+	}
+
+	return idents
+}
+
+// parseTypeParameters generic tip parametrelerini parse eder: [T, U: Bound]
+// curToken LBRACK olmalı; dönüşte curToken RBRACK olur
+func (p *Parser) parseTypeParameters() []*ast.TypeParameter {
+	params := []*ast.TypeParameter{}
+
+	for {
+		if !p.expectPeek(lexer.IDENT) {
+			return nil
+		}
+		param := &ast.TypeParameter{
+			Token: p.curToken,
+			Name:  &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal},
+		}
+
+		// Bound (opsiyonel): T: Comparable
+		if p.peekTokenIs(lexer.COLON) {
+			p.nextToken() // :
+			p.nextToken() // bound type
+			param.Constraint = p.parseTypeAnnotation()
+		}
+
+		params = append(params, param)
+
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken() // ,
+	}
+
+	if !p.expectPeek(lexer.RBRACK) {
+		return nil
+	}
+
+	return params
+}
@@ -0,0 +1,83 @@
+package sema
+
+import (
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+)
+
+// blockingBuiltins thread'i bloklayan builtin'leri await edilebilen
+// _async karşılıklarıyla eşler
+var blockingBuiltins = map[string]string{
+	"time_sleep":    "time_sleep_async",
+	"fs_read_text":  "fs_read_text_async",
+	"fs_write_text": "fs_write_text_async",
+	"http_get":      "http_get_async",
+	"http_post":     "http_post_async",
+	"http_put":      "http_put_async",
+	"http_delete":   "http_delete_async",
+}
+
+// defineAsyncBuiltins blocking builtin'lerin Promise döndüren karşılıklarını tanımlar
+func defineAsyncBuiltins(scope *Scope) {
+	for name, async := range blockingBuiltins {
+		symbol, ok := scope.symbols[name]
+		if !ok {
+			continue
+		}
+		blocking := symbol.Type.(*FunctionType)
+		scope.Define(&Symbol{
+			Name:    async,
+			Kind:    FunctionSymbol,
+			Type:    &FunctionType{Params: blocking.Params, ReturnType: promiseOf(blocking.ReturnType)},
+			IsAsync: true,
+		})
+	}
+}
+
+// inAsync async bir fonksiyonun body'sinde miyiz
+func (c *Checker) inAsync() bool {
+	return c.currentFunction != nil && c.currentFunction.IsAsync
+}
+
+// checkBlockingCall async fonksiyonda blocking builtin çağrılarını uyarı
+// olarak raporlar; çağrı doğru çalışır ama diğer görevleri bekletir
+// Aynı isimli kullanıcı tanımları builtin sayılmaz
+func (c *Checker) checkBlockingCall(expr *ast.CallExpression) {
+	if !c.inAsync() {
+		return
+	}
+	ident, ok := expr.Function.(*ast.Identifier)
+	if !ok {
+		return
+	}
+	async, ok := blockingBuiltins[ident.Value]
+	if !ok {
+		return
+	}
+	if symbol, ok := c.symTable.Resolve(ident.Value); !ok || symbol.Node != nil || symbol.Kind != FunctionSymbol {
+		return
+	}
+	c.addWarning(&SemanticError{
+		Code:    diag.BlockingCall,
+		Message: i18n.T("sema.blocking_call", ident.Value, c.currentFunction.Name, async),
+		Pos:     expr.Token,
+	})
+}
+
+// checkExpressionStatement ifadeyi kontrol eder; sonucu atılan async
+// çağrılar (await edilmemiş promise'lar) hata verir
+func (c *Checker) checkExpressionStatement(stmt *ast.ExpressionStatement) {
+	t := c.checkExpression(stmt.Expression)
+	call, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		return
+	}
+	if _, ok := t.(*PromiseType); ok {
+		c.addError(&SemanticError{
+			Code:    diag.UnawaitedPromise,
+			Message: i18n.T("sema.unawaited_promise", calleeName(call.Function), t.String()),
+			Pos:     call.Token,
+		})
+	}
+}
@@ -1,21 +1,52 @@
 package sema
 
 import (
-	"fmt"
+	"sort"
 
 	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 )
 
 // Checker semantik analiz yapar
 type Checker struct {
 	symTable *SymbolTable
 	errors   []error
+	warnings []error // programın çalışmasını engellemeyen bulgular
 
 	// Mevcut fonksiyon tipi (return type kontrolü için)
 	currentFunction *Symbol
 
 	// Loop içinde miyiz? (break/continue kontrolü için)
 	inLoop int
+
+	// Aktif generic tip parametreleri (isim -> parametre)
+	typeParams map[string]*TypeParam
+
+	// Akışa duyarlı tip daraltma (null-safety)
+	narrowing  []narrowFrame
+	strictNull bool
+	typeInfo   []TypeInfo
+
+	// Import edilen modüller (importlarla birlikte kontrol edilen modüller paylaşır)
+	modules *moduleLoader
+
+	// Anotasyonsuz fonksiyonlarda return tipleri toplanıp birleştirilir;
+	// recursed fonksiyonun kendini çağırdığını işaretler
+	returns     []Type
+	inferReturn bool
+	recursed    bool
+	functions   []FunctionInfo
+
+	// Kontrol edilen sınıf (self'in tipi) ve init'te tipi çıkarılan alanlar
+	currentClass   *ClassType
+	inInit         bool
+	initAssigned   map[string]bool
+	classes        []*ClassType
+	pendingMembers []pendingMember
+
+	// "# sky-ignore: KOD" yorumlarıyla bastırılan hatalar
+	suppressions diag.Suppressions
 }
 
 // NewChecker yeni bir checker oluşturur
@@ -32,18 +63,76 @@ func (c *Checker) Check(program *ast.Program) []error {
 	for _, stmt := range program.Statements {
 		c.checkStatement(stmt)
 	}
+	c.checkPendingMembers()
+	sortByPosition(c.errors)
+	sortByPosition(c.warnings)
 	return c.errors
 }
 
+// sortByPosition hataları her dosyada satır ve sütuna göre sıralar;
+// dosyalar ilk hatalarının sırasını korur, konumsuz hatalar kendi
+// gruplarının başına gelir
+func sortByPosition(errors []error) {
+	files := make(map[string]int)
+	position := func(err error) (file, line, column int) {
+		semErr, ok := err.(*SemanticError)
+		if !ok {
+			return files[""], 0, 0
+		}
+		return files[semErr.Pos.File], semErr.Pos.Line, semErr.Pos.Column
+	}
+	for _, err := range errors {
+		name := ""
+		if semErr, ok := err.(*SemanticError); ok {
+			name = semErr.Pos.File
+		}
+		if _, ok := files[name]; !ok {
+			files[name] = len(files)
+		}
+	}
+	sort.SliceStable(errors, func(i, j int) bool {
+		fi, li, ci := position(errors[i])
+		fj, lj, cj := position(errors[j])
+		if fi != fj {
+			return fi < fj
+		}
+		if li != lj {
+			return li < lj
+		}
+		return ci < cj
+	})
+}
+
 // Errors hataları döndürür
 func (c *Checker) Errors() []error {
 	return c.errors
 }
 
+// Warnings uyarıları döndürür; uyarılar programın çalışmasını engellemez
+func (c *Checker) Warnings() []error {
+	return c.warnings
+}
+
+// SetSource kontrol edilen programın kaynak kodunu belirtir
+// Kaynaktaki "# sky-ignore: KOD" yorumları o satırdaki hataları bastırır
+func (c *Checker) SetSource(source string) {
+	c.suppressions = diag.ParseSuppressions(source)
+}
+
 func (c *Checker) addError(err error) {
+	if semErr, ok := err.(*SemanticError); ok && c.suppressions.Suppressed(semErr.Code, semErr.Pos.Line) {
+		return
+	}
 	c.errors = append(c.errors, err)
 }
 
+func (c *Checker) addWarning(err *SemanticError) {
+	if c.suppressions.Suppressed(err.Code, err.Pos.Line) {
+		return
+	}
+	c.warnings = append(c.warnings, err)
+}
+
 func (c *Checker) checkStatement(stmt ast.Statement) {
 	if stmt == nil {
 		return
@@ -57,7 +146,7 @@ func (c *Checker) checkStatement(stmt ast.Statement) {
 	case *ast.ReturnStatement:
 		c.checkReturnStatement(s)
 	case *ast.ExpressionStatement:
-		c.checkExpression(s.Expression)
+		c.checkExpressionStatement(s)
 	case *ast.FunctionStatement:
 		c.checkFunctionStatement(s)
 	case *ast.IfStatement:
@@ -77,11 +166,13 @@ func (c *Checker) checkStatement(stmt ast.Statement) {
 	case *ast.StaticPropertyStatement:
 		c.checkStaticPropertyStatement(s)
 	case *ast.ImportStatement:
-		// Import statements are handled at a higher level
+		c.checkImportStatement(s)
 	case *ast.UnsafeStatement:
 		c.checkUnsafeStatement(s)
 	case *ast.EnumStatement:
 		c.checkEnumStatement(s)
+	case *ast.InterfaceStatement:
+		c.checkInterfaceStatement(s)
 	case *ast.BlockStatement:
 		c.checkBlockStatement(s)
 	}
@@ -97,15 +188,16 @@ func (c *Checker) checkLetStatement(stmt *ast.LetStatement) {
 	// Tip anotasyonu varsa kontrol et
 	var declaredType Type = AnyType
 	if stmt.Type != nil {
-		declaredType = ResolveType(stmt.Type)
+		declaredType = c.resolveType(stmt.Type)
 	} else {
 		declaredType = valueType
 	}
 
 	// Tip uyumluluğu kontrolü
-	if stmt.Type != nil && !valueType.IsAssignableTo(declaredType) {
+	if stmt.Type != nil && !c.isAssignable(valueType, declaredType) {
 		c.addError(&SemanticError{
-			Message: fmt.Sprintf("type mismatch: cannot assign %s to %s",
+			Code: diag.TypeMismatch,
+			Message: i18n.T("sema.type_mismatch",
 				valueType.String(), declaredType.String()),
 			Pos: stmt.Token,
 		})
@@ -123,14 +215,20 @@ func (c *Checker) checkLetStatement(stmt *ast.LetStatement) {
 
 	if err := c.symTable.Define(symbol); err != nil {
 		c.addError(err)
+		return
 	}
+
+	// let x: T? = <nil olmayan değer> -> x bu noktadan sonra T
+	c.invalidate(symbol, valueType)
+	c.recordType(stmt.Name.Value, stmt.Name.Token, c.currentType(symbol))
 }
 
 func (c *Checker) checkConstStatement(stmt *ast.ConstStatement) {
 	// Const değeri olmalı
 	if stmt.Value == nil {
 		c.addError(&SemanticError{
-			Message: "const declaration must have a value",
+			Code:    diag.MissingConstValue,
+			Message: i18n.T("sema.const_value"),
 			Pos:     stmt.Token,
 		})
 		return
@@ -142,15 +240,16 @@ func (c *Checker) checkConstStatement(stmt *ast.ConstStatement) {
 	// Tip anotasyonu varsa kontrol et
 	var declaredType Type = AnyType
 	if stmt.Type != nil {
-		declaredType = ResolveType(stmt.Type)
+		declaredType = c.resolveType(stmt.Type)
 	} else {
 		declaredType = valueType
 	}
 
 	// Tip uyumluluğu kontrolü
-	if stmt.Type != nil && !valueType.IsAssignableTo(declaredType) {
+	if stmt.Type != nil && !c.isAssignable(valueType, declaredType) {
 		c.addError(&SemanticError{
-			Message: fmt.Sprintf("type mismatch: cannot assign %s to %s",
+			Code: diag.TypeMismatch,
+			Message: i18n.T("sema.type_mismatch",
 				valueType.String(), declaredType.String()),
 			Pos: stmt.Token,
 		})
@@ -175,7 +274,8 @@ func (c *Checker) checkReturnStatement(stmt *ast.ReturnStatement) {
 	// Fonksiyon içinde miyiz kontrol et
 	if c.currentFunction == nil {
 		c.addError(&SemanticError{
-			Message: "return statement outside of function",
+			Code:    diag.ReturnOutsideFunction,
+			Message: i18n.T("sema.return_outside"),
 			Pos:     stmt.Token,
 		})
 		return
@@ -187,13 +287,25 @@ func (c *Checker) checkReturnStatement(stmt *ast.ReturnStatement) {
 		returnType = c.checkExpression(stmt.ReturnValue)
 	}
 
+	// Return tipi çıkarılıyorsa karşılaştırma yerine kaydet
+	if c.inferReturn {
+		c.returns = append(c.returns, returnType)
+		return
+	}
+
 	// Fonksiyonun return type'ı ile karşılaştır
+	// async fonksiyon Promise[T] döndürür, body'deki return T verir
 	funcType, ok := c.currentFunction.Type.(*FunctionType)
 	if ok {
-		if !returnType.IsAssignableTo(funcType.ReturnType) {
+		expected := funcType.ReturnType
+		if c.currentFunction.IsAsync {
+			expected = awaited(expected)
+		}
+		if !c.isAssignable(returnType, expected) {
 			c.addError(&SemanticError{
-				Message: fmt.Sprintf("return type mismatch: expected %s, got %s",
-					funcType.ReturnType.String(), returnType.String()),
+				Code: diag.ReturnTypeMismatch,
+				Message: i18n.T("sema.return_mismatch",
+					expected.String(), returnType.String()),
 				Pos: stmt.Token,
 			})
 		}
@@ -201,13 +313,18 @@ func (c *Checker) checkReturnStatement(stmt *ast.ReturnStatement) {
 }
 
 func (c *Checker) checkFunctionStatement(stmt *ast.FunctionStatement) {
+	// Generic tip parametreleri imza ve body boyunca geçerli
+	oldTypeParams := c.typeParams
+	typeParams := c.enterTypeParams(stmt.TypeParams)
+	defer func() { c.typeParams = oldTypeParams }()
+
 	// Parametrelerin tiplerini al
 	paramTypes := make([]Type, len(stmt.Parameters))
 	minParams := 0
 	hasVarargs := false
 	for i, param := range stmt.Parameters {
 		if param.Type != nil {
-			paramTypes[i] = ResolveType(param.Type)
+			paramTypes[i] = c.resolveType(param.Type)
 		} else {
 			paramTypes[i] = AnyType
 		}
@@ -221,10 +338,15 @@ func (c *Checker) checkFunctionStatement(stmt *ast.FunctionStatement) {
 		}
 	}
 
-	// Return type'ı al
-	var returnType Type = VoidType
-	if stmt.ReturnType != nil {
-		returnType = ResolveType(stmt.ReturnType)
+	// Return type'ı al; bildirilmemişse body'deki return'lerden çıkarılır
+	// (bkz. inferRecursive)
+	inferReturn := stmt.ReturnType == nil
+	var returnType Type = AnyType
+	if !inferReturn {
+		returnType = c.resolveType(stmt.ReturnType)
+	}
+	if stmt.Async {
+		returnType = promiseOf(returnType)
 	}
 
 	// Fonksiyon tipini oluştur
@@ -233,6 +355,7 @@ func (c *Checker) checkFunctionStatement(stmt *ast.FunctionStatement) {
 		ReturnType: returnType,
 		MinParams:  minParams,
 		Variadic:   hasVarargs,
+		TypeParams: typeParams,
 	}
 
 	// Fonksiyonu sembole ekle
@@ -242,6 +365,7 @@ func (c *Checker) checkFunctionStatement(stmt *ast.FunctionStatement) {
 		Type:    funcType,
 		Pos:     stmt.Token,
 		IsAsync: stmt.Async,
+		IsCoop:  stmt.Coop,
 		Node:    stmt,
 	}
 
@@ -250,32 +374,47 @@ func (c *Checker) checkFunctionStatement(stmt *ast.FunctionStatement) {
 		return
 	}
 
-	// Fonksiyon body'sini kontrol et (yeni scope)
-	c.symTable.EnterScope()
-	oldFunction := c.currentFunction
-	c.currentFunction = funcSymbol
-
-	// Parametreleri scope'a ekle
-	for i, param := range stmt.Parameters {
-		paramSymbol := &Symbol{
-			Name:    param.Name.Value,
-			Kind:    ParameterSymbol,
-			Type:    paramTypes[i],
-			Pos:     param.Token,
-			Mutable: true,
-		}
-		if err := c.symTable.Define(paramSymbol); err != nil {
-			c.addError(err)
+	// Fonksiyon body'sini parametreleriyle yeni bir scope'ta kontrol et
+	checkBody := func() {
+		c.symTable.EnterScope()
+		for i, param := range stmt.Parameters {
+			paramSymbol := &Symbol{
+				Name:    param.Name.Value,
+				Kind:    ParameterSymbol,
+				Type:    paramTypes[i],
+				Pos:     param.Token,
+				Mutable: true,
+			}
+			if err := c.symTable.Define(paramSymbol); err != nil {
+				c.addError(err)
+			}
+			c.recordType(param.Name.Value, param.Name.Token, paramTypes[i])
+		}
+		if stmt.Body != nil {
+			c.checkBlockStatement(stmt.Body)
 		}
+		c.symTable.ExitScope()
 	}
 
-	// Body'yi kontrol et
-	if stmt.Body != nil {
-		c.checkBlockStatement(stmt.Body)
+	oldFunction := c.currentFunction
+	c.currentFunction = funcSymbol
+	oldReturns, oldInfer, oldRecursed := c.returns, c.inferReturn, c.recursed
+	c.returns, c.inferReturn, c.recursed = nil, inferReturn, false
+	if inferReturn {
+		c.inferRecursive(funcType, stmt, checkBody)
+	} else {
+		checkBody()
 	}
-
+	c.returns, c.inferReturn, c.recursed = oldReturns, oldInfer, oldRecursed
 	c.currentFunction = oldFunction
-	c.symTable.ExitScope()
+
+	c.functions = append(c.functions, FunctionInfo{
+		Name:           stmt.Name.Value,
+		Node:           stmt,
+		Type:           funcType,
+		ReturnInferred: inferReturn,
+	})
+	c.recordType(stmt.Name.Value, stmt.Name.Token, funcType)
 }
 
 func (c *Checker) checkIfStatement(stmt *ast.IfStatement) {
@@ -283,29 +422,46 @@ func (c *Checker) checkIfStatement(stmt *ast.IfStatement) {
 	condType := c.checkExpression(stmt.Condition)
 	if condType != BoolType && condType != AnyType {
 		c.addError(&SemanticError{
-			Message: fmt.Sprintf("if condition must be bool, got %s", condType.String()),
+			Code:    diag.NonBoolCondition,
+			Message: i18n.T("sema.if_condition", condType.String()),
 			Pos:     stmt.Token,
 		})
 	}
 
+	// Koşuldan çıkan tip daraltmaları (x != nil, isinstance, ...)
+	whenTrue, whenFalse := c.conditionFacts(stmt.Condition)
+
 	// Consequence bloğunu kontrol et
+	c.pushNarrowing(whenTrue)
 	c.symTable.EnterScope()
 	c.checkBlockStatement(stmt.Consequence)
 	c.symTable.ExitScope()
+	c.popNarrowing()
+
+	// Sonraki dallar önceki koşulların yanlış olduğunu bilir
+	c.pushNarrowing(whenFalse)
+	pushed := 1
 
 	// Elif bloklarını kontrol et
 	for _, elif := range stmt.Elif {
 		elifCondType := c.checkExpression(elif.Condition)
 		if elifCondType != BoolType && elifCondType != AnyType {
 			c.addError(&SemanticError{
-				Message: fmt.Sprintf("elif condition must be bool, got %s", elifCondType.String()),
+				Code:    diag.NonBoolCondition,
+				Message: i18n.T("sema.elif_condition", elifCondType.String()),
 				Pos:     elif.Token,
 			})
 		}
 
+		elifTrue, elifFalse := c.conditionFacts(elif.Condition)
+		c.pushNarrowing(elifTrue)
 		c.symTable.EnterScope()
 		c.checkBlockStatement(elif.Consequence)
 		c.symTable.ExitScope()
+		c.popNarrowing()
+
+		c.pushNarrowing(elifFalse)
+		pushed++
 	}
 
 	// Alternative bloğunu kontrol et
@@ -314,6 +470,25 @@ func (c *Checker) checkIfStatement(stmt *ast.IfStatement) {
 		c.checkBlockStatement(stmt.Alternative)
 		c.symTable.ExitScope()
 	}
+
+	for ; pushed > 0; pushed-- {
+		c.popNarrowing()
+	}
+
+	// Erken çıkış: "if x == nil return end" sonrası x nil değildir
+	if len(stmt.Elif) == 0 {
+		consequenceExits := blockTerminates(stmt.Consequence)
+		alternativeExits := blockTerminates(stmt.Alternative)
+		if consequenceExits && !alternativeExits {
+			for sym, t := range whenFalse {
+				c.narrow(sym, t)
+			}
+		} else if alternativeExits && !consequenceExits {
+			for sym, t := range whenTrue {
+				c.narrow(sym, t)
+			}
+		}
+	}
 }
 
 func (c *Checker) checkWhileStatement(stmt *ast.WhileStatement) {
@@ -321,17 +496,21 @@ func (c *Checker) checkWhileStatement(stmt *ast.WhileStatement) {
 	condType := c.checkExpression(stmt.Condition)
 	if condType != BoolType && condType != AnyType {
 		c.addError(&SemanticError{
-			Message: fmt.Sprintf("while condition must be bool, got %s", condType.String()),
+			Code:    diag.NonBoolCondition,
+			Message: i18n.T("sema.while_condition", condType.String()),
 			Pos:     stmt.Token,
 		})
 	}
 
-	// Body'yi kontrol et (loop içinde)
+	// Body'yi kontrol et (loop içinde, koşul doğru kabul edilir)
+	whenTrue, _ := c.conditionFacts(stmt.Condition)
+	c.pushNarrowing(whenTrue)
 	c.inLoop++
 	c.symTable.EnterScope()
 	c.checkBlockStatement(stmt.Body)
 	c.symTable.ExitScope()
 	c.inLoop--
+	c.popNarrowing()
 }
 
 func (c *Checker) checkForStatement(stmt *ast.ForStatement) {
@@ -367,11 +546,17 @@ func (c *Checker) checkForStatement(stmt *ast.ForStatement) {
 }
 
 func (c *Checker) checkClassStatement(stmt *ast.ClassStatement) {
+	// Generic tip parametreleri tüm class body'sinde geçerli
+	oldTypeParams := c.typeParams
+	typeParams := c.enterTypeParams(stmt.TypeParams)
+	defer func() { c.typeParams = oldTypeParams }()
+
 	// Class tipini oluştur
 	classType := &ClassType{
-		Name:    stmt.Name.Value,
-		Methods: make(map[string]*FunctionType),
-		Fields:  make(map[string]Type),
+		Name:       stmt.Name.Value,
+		Methods:    make(map[string]*FunctionType),
+		Fields:     make(map[string]Type),
+		TypeParams: typeParams,
 	}
 
 	// Multiple inheritance - check all superclasses
@@ -381,13 +566,15 @@ func (c *Checker) checkClassStatement(stmt *ast.ClassStatement) {
 				classType.SuperClasses = append(classType.SuperClasses, superClass)
 			} else {
 				c.addError(&SemanticError{
-					Message: fmt.Sprintf("%s is not a class", superClassIdent.Value),
+					Code:    diag.InvalidSuperclass,
+					Message: i18n.T("sema.not_a_class", superClassIdent.Value),
 					Pos:     superClassIdent.Token,
 				})
 			}
 		} else {
 			c.addError(&SemanticError{
-				Message: fmt.Sprintf("undefined superclass: %s", superClassIdent.Value),
+				Code:    diag.InvalidSuperclass,
+				Message: i18n.T("sema.undefined_superclass", superClassIdent.Value),
 				Pos:     superClassIdent.Token,
 			})
 		}
@@ -407,13 +594,37 @@ func (c *Checker) checkClassStatement(stmt *ast.ClassStatement) {
 		return
 	}
 
+	// Metod imzalarını ve alanları topla, bildirilen interface'leri doğrula
+	c.collectMethods(stmt.Body, classType)
+	c.collectShape(stmt.Body, classType)
+	c.checkDeclaredInterfaces(stmt, classType)
+	c.checkOverrides(stmt.Body, classType)
+	c.classes = append(c.classes, classType)
+
 	// Class body'sini kontrol et (yeni scope)
+	// init önce kontrol edilir; diğer metodlar alanların çıkarılan tiplerini görür
 	c.symTable.EnterScope()
+	oldClass, oldInit, oldAssigned := c.currentClass, c.inInit, c.initAssigned
+	c.currentClass, c.initAssigned = classType, make(map[string]bool)
 
 	for _, member := range stmt.Body {
+		if fn, ok := member.(*ast.FunctionStatement); ok && fn.Name.Value == "init" {
+			c.inInit = true
+			c.checkMethod(fn, classType)
+			c.inInit = false
+		}
+	}
+	for _, member := range stmt.Body {
+		if fn, ok := member.(*ast.FunctionStatement); ok {
+			if fn.Name.Value != "init" {
+				c.checkMethod(fn, classType)
+			}
+			continue
+		}
 		c.checkStatement(member)
 	}
 
+	c.currentClass, c.inInit, c.initAssigned = oldClass, oldInit, oldAssigned
 	c.symTable.ExitScope()
 }
 
@@ -426,10 +637,11 @@ func (c *Checker) checkUnsafeStatement(stmt *ast.UnsafeStatement) {
 
 func (c *Checker) checkEnumStatement(stmt *ast.EnumStatement) {
 	// Enum tipini tanımla
+	enumType := &EnumType{Name: stmt.Name.Value}
 	enumSymbol := &Symbol{
 		Name: stmt.Name.Value,
 		Kind: ClassSymbol, // Enum'u class gibi ele al
-		Type: AnyType,
+		Type: enumType,
 		Pos:  stmt.Token,
 	}
 
@@ -442,12 +654,17 @@ func (c *Checker) checkEnumStatement(stmt *ast.EnumStatement) {
 	for _, variant := range stmt.Variants {
 		paramTypes := make([]Type, len(variant.Payload))
 		for i, payloadType := range variant.Payload {
-			paramTypes[i] = ResolveType(payloadType)
+			paramTypes[i] = c.resolveType(payloadType)
 		}
 
+		enumType.Variants = append(enumType.Variants, &EnumVariantType{
+			Name:    variant.Name.Value,
+			Payload: paramTypes,
+		})
+
 		constructorType := &FunctionType{
 			Params:     paramTypes,
-			ReturnType: AnyType, // Enum instance döndürür
+			ReturnType: enumType,
 		}
 
 		variantSymbol := &Symbol{
@@ -468,6 +685,10 @@ func (c *Checker) checkBlockStatement(block *ast.BlockStatement) {
 		return
 	}
 
+	// Blok içindeki erken çıkış daraltmaları blok sonunda biter
+	c.pushNarrowing(nil)
+	defer c.popNarrowing()
+
 	for _, stmt := range block.Statements {
 		c.checkStatement(stmt)
 	}
@@ -507,6 +728,10 @@ func (c *Checker) checkExpression(expr ast.Expression) Type {
 		return c.checkAwaitExpression(e)
 	case *ast.YieldExpression:
 		return c.checkYieldExpression(e)
+	case *ast.MatchExpression:
+		return c.checkMatchExpression(e)
+	case *ast.LambdaExpression:
+		return c.checkLambdaExpression(e, nil)
 	default:
 		return AnyType
 	}
@@ -515,8 +740,10 @@ func (c *Checker) checkExpression(expr ast.Expression) Type {
 func (c *Checker) checkIdentifier(expr *ast.Identifier) Type {
 	// Special handling for 'self' and 'super'
 	if expr.Value == "self" {
-		// 'self' is always valid in method context
-		// For now, return AnyType (could be improved with class type tracking)
+		// 'self' is the instance of the class being checked
+		if c.currentClass != nil {
+			return c.currentClass
+		}
 		return AnyType
 	}
 	if expr.Value == "super" {
@@ -527,13 +754,16 @@ func (c *Checker) checkIdentifier(expr *ast.Identifier) Type {
 	symbol, ok := c.symTable.Resolve(expr.Value)
 	if !ok {
 		c.addError(&SemanticError{
-			Message: fmt.Sprintf("undefined: %s", expr.Value),
+			Code:    diag.UndefinedName,
+			Message: i18n.T("sema.undefined", expr.Value),
 			Pos:     expr.Token,
 		})
 		return AnyType
 	}
 
-	return symbol.Type
+	t := c.currentType(symbol)
+	c.recordType(expr.Value, expr.Token, t)
+	return t
 }
 
 func (c *Checker) checkListLiteral(expr *ast.ListLiteral) Type {
@@ -547,7 +777,8 @@ func (c *Checker) checkListLiteral(expr *ast.ListLiteral) Type {
 		t := c.checkExpression(elem)
 		if !t.IsAssignableTo(elemType) {
 			c.addError(&SemanticError{
-				Message: fmt.Sprintf("list element type mismatch: expected %s, got %s",
+				Code: diag.TypeMismatch,
+				Message: i18n.T("sema.list_element_mismatch",
 					elemType.String(), t.String()),
 				Pos: expr.Token,
 			})
@@ -575,14 +806,16 @@ func (c *Checker) checkDictLiteral(expr *ast.DictLiteral) Type {
 		} else {
 			if !kt.IsAssignableTo(keyType) {
 				c.addError(&SemanticError{
-					Message: fmt.Sprintf("dict key type mismatch: expected %s, got %s",
+					Code: diag.TypeMismatch,
+					Message: i18n.T("sema.dict_key_mismatch",
 						keyType.String(), kt.String()),
 					Pos: expr.Token,
 				})
 			}
 			if !vt.IsAssignableTo(valueType) {
 				c.addError(&SemanticError{
-					Message: fmt.Sprintf("dict value type mismatch: expected %s, got %s",
+					Code: diag.TypeMismatch,
+					Message: i18n.T("sema.dict_value_mismatch",
 						valueType.String(), vt.String()),
 					Pos: expr.Token,
 				})
@@ -600,7 +833,8 @@ func (c *Checker) checkPrefixExpression(expr *ast.PrefixExpression) Type {
 	case "!":
 		if rightType != BoolType && rightType != AnyType {
 			c.addError(&SemanticError{
-				Message: fmt.Sprintf("operator ! cannot be applied to %s", rightType.String()),
+				Code:    diag.InvalidOperand,
+				Message: i18n.T("sema.operator_not", rightType.String()),
 				Pos:     expr.Token,
 			})
 		}
@@ -609,7 +843,8 @@ func (c *Checker) checkPrefixExpression(expr *ast.PrefixExpression) Type {
 	case "-", "+":
 		if rightType != IntType && rightType != FloatType && rightType != AnyType {
 			c.addError(&SemanticError{
-				Message: fmt.Sprintf("operator %s cannot be applied to %s", expr.Operator, rightType.String()),
+				Code:    diag.InvalidOperand,
+				Message: i18n.T("sema.operator", expr.Operator, rightType.String()),
 				Pos:     expr.Token,
 			})
 		}
@@ -621,35 +856,69 @@ func (c *Checker) checkPrefixExpression(expr *ast.PrefixExpression) Type {
 }
 
 func (c *Checker) checkInfixExpression(expr *ast.InfixExpression) Type {
-	leftType := c.checkExpression(expr.Left)
-	rightType := c.checkExpression(expr.Right)
+	// Atanan üye okunmaz; bildirilmiş tipi hedef tip olur
+	var leftType Type
+	memberTarget, isMemberTarget := expr.Left.(*ast.MemberExpression)
+	if isMemberTarget && isAssignment(expr.Operator) {
+		leftType = c.memberTarget(memberTarget)
+	} else {
+		leftType = c.checkExpression(expr.Left)
+	}
+
+	// && ve || sağ tarafı sol tarafın sonucuna göre daraltılmış olarak kontrol edilir
+	var rightType Type
+	switch expr.Operator {
+	case "&&", "||":
+		whenTrue, whenFalse := c.conditionFacts(expr.Left)
+		if expr.Operator == "&&" {
+			c.pushNarrowing(whenTrue)
+		} else {
+			c.pushNarrowing(whenFalse)
+		}
+		rightType = c.checkExpression(expr.Right)
+		c.popNarrowing()
+	default:
+		rightType = c.checkExpression(expr.Right)
+	}
 
 	// Assignment operatörleri
-	if expr.Operator == "=" || expr.Operator == "+=" || expr.Operator == "-=" ||
-		expr.Operator == "*=" || expr.Operator == "/=" || expr.Operator == "%=" {
+	if isAssignment(expr.Operator) {
 
 		// Sol taraf identifier olmalı
+		var target *Symbol
 		if ident, ok := expr.Left.(*ast.Identifier); ok {
 			if symbol, ok := c.symTable.Resolve(ident.Value); ok {
 				// Const değişkene atama yapılamaz
 				if !symbol.Mutable {
 					c.addError(&SemanticError{
-						Message: fmt.Sprintf("cannot assign to const variable '%s'", ident.Value),
+						Code:    diag.AssignToConst,
+						Message: i18n.T("sema.assign_const", ident.Value),
 						Pos:     expr.Token,
 					})
 				}
+				// Atama daraltılmış değil bildirilen tipe göre kontrol edilir
+				target = symbol
+				leftType = symbol.Type
 			}
 		}
 
 		// Tip uyumluluğu kontrolü
-		if !rightType.IsAssignableTo(leftType) {
+		if !c.isAssignable(rightType, leftType) {
 			c.addError(&SemanticError{
-				Message: fmt.Sprintf("type mismatch: cannot assign %s to %s",
+				Code: diag.TypeMismatch,
+				Message: i18n.T("sema.type_mismatch",
 					rightType.String(), leftType.String()),
 				Pos: expr.Token,
 			})
 		}
 
+		if target != nil && expr.Operator == "=" {
+			c.invalidate(target, rightType)
+		}
+		if isMemberTarget && expr.Operator == "=" {
+			c.inferField(memberTarget, rightType)
+		}
+
 		return leftType
 	}
 
@@ -666,6 +935,10 @@ func (c *Checker) checkInfixExpression(expr *ast.InfixExpression) Type {
 	}
 
 	// Arithmetic operatörler
+	if c.strictNull {
+		c.checkNotNil(expr.Left, leftType, i18n.T("sema.nil_operator", expr.Operator), expr.Token)
+		c.checkNotNil(expr.Right, rightType, i18n.T("sema.nil_operator", expr.Operator), expr.Token)
+	}
 	if leftType == FloatType || rightType == FloatType {
 		return FloatType
 	}
@@ -681,71 +954,148 @@ func (c *Checker) checkInfixExpression(expr *ast.InfixExpression) Type {
 
 func (c *Checker) checkCallExpression(expr *ast.CallExpression) Type {
 	funcType := c.checkExpression(expr.Function)
+	callee, _ := funcType.(*FunctionType)
+	c.checkBlockingCall(expr)
+	if c.inferReturn && c.currentFunction != nil && funcType == c.currentFunction.Type {
+		c.recursed = true
+	}
+
+	// Lambda argümanları diğer argümanlardan sonra, çağrı bağlamıyla kontrol edilir
+	argTypes := make([]Type, len(expr.Arguments))
+	for i, arg := range expr.Arguments {
+		if _, ok := arg.(*ast.LambdaExpression); !ok {
+			argTypes[i] = c.checkExpression(arg)
+		}
+	}
+	for i, arg := range expr.Arguments {
+		if lambda, ok := arg.(*ast.LambdaExpression); ok {
+			argTypes[i] = c.checkLambdaExpression(lambda, c.callbackType(expr, callee, i, argTypes))
+		}
+	}
+	if result := c.callbackResult(expr, argTypes); result != nil {
+		return result
+	}
+
+	c.checkNotNil(expr.Function, funcType, i18n.T("sema.nil_call"), expr.Token)
 
 	if ft, ok := funcType.(*FunctionType); ok {
-		// Parametre sayısı kontrolü
-		argCount := len(expr.Arguments)
-		minRequired := ft.MinParams
-		if minRequired == 0 {
-			minRequired = len(ft.Params) // Backward compatibility
+		// Generic fonksiyon: tip argümanlarını çıkar ve imzayı somutlaştır
+		instantiated := ""
+		if len(ft.TypeParams) > 0 {
+			ft, instantiated = c.instantiateCall(ft, argTypes, calleeName(expr.Function), expr.Token)
 		}
 
-		if ft.Variadic {
-			// Varargs function: check minimum required arguments
-			if argCount < minRequired {
+		c.checkArguments(ft, expr, argTypes, instantiated)
+
+		return ft.ReturnType
+	}
+
+	// Sınıf çağrısı bir instance üretir
+	if classType, ok := funcType.(*ClassType); ok {
+		if len(classType.TypeParams) > 0 {
+			return c.instantiateConstructor(classType, argTypes, expr.Token)
+		}
+		// Argümanlar init'e (yoksa parent sınıfınkine) verilir
+		init, ok := classType.LookupMethod("init")
+		if !ok {
+			init = &FunctionType{}
+		}
+		c.checkArguments(init, expr, argTypes, "")
+		return classType
+	}
+
+	if ifaceType, ok := funcType.(*InterfaceType); ok {
+		c.addError(&SemanticError{
+			Code:    diag.InterfaceInstantiation,
+			Message: i18n.T("sema.instantiate_interface", ifaceType.Name),
+			Pos:     expr.Token,
+		})
+	}
+
+	return AnyType
+}
+
+// checkArguments çağrının argüman sayısını ve tiplerini imzaya göre kontrol eder
+func (c *Checker) checkArguments(ft *FunctionType, expr *ast.CallExpression, argTypes []Type, instantiated string) {
+	// Parametre sayısı kontrolü
+	argCount := len(expr.Arguments)
+	minRequired := ft.MinParams
+	if minRequired == 0 {
+		minRequired = len(ft.Params) // Backward compatibility
+	}
+
+	if ft.Variadic {
+		// Varargs function: check minimum required arguments
+		if argCount < minRequired {
+			c.addError(&SemanticError{
+				Code: diag.ArgumentCount,
+				Message: i18n.T("sema.arg_count_min",
+					minRequired, argCount),
+				Pos: expr.Token,
+			})
+		}
+	} else {
+		// Non-varargs: check if argument count is within valid range
+		if argCount < minRequired || argCount > len(ft.Params) {
+			if minRequired == len(ft.Params) {
 				c.addError(&SemanticError{
-					Message: fmt.Sprintf("wrong number of arguments: expected at least %d, got %d",
-						minRequired, argCount),
+					Code: diag.ArgumentCount,
+					Message: i18n.T("sema.arg_count",
+						len(ft.Params), argCount),
+					Pos: expr.Token,
+				})
+			} else {
+				c.addError(&SemanticError{
+					Code: diag.ArgumentCount,
+					Message: i18n.T("sema.arg_count_range",
+						minRequired, len(ft.Params), argCount),
 					Pos: expr.Token,
 				})
-			}
-		} else {
-			// Non-varargs: check if argument count is within valid range
-			if argCount < minRequired || argCount > len(ft.Params) {
-				if minRequired == len(ft.Params) {
-					c.addError(&SemanticError{
-						Message: fmt.Sprintf("wrong number of arguments: expected %d, got %d",
-							len(ft.Params), argCount),
-						Pos: expr.Token,
-					})
-				} else {
-					c.addError(&SemanticError{
-						Message: fmt.Sprintf("wrong number of arguments: expected %d-%d, got %d",
-							minRequired, len(ft.Params), argCount),
-						Pos: expr.Token,
-					})
-				}
 			}
 		}
+	}
 
-		// Argüman tiplerini kontrol et
-		for i, arg := range expr.Arguments {
-			argType := c.checkExpression(arg)
-			if i < len(ft.Params) {
-				if !argType.IsAssignableTo(ft.Params[i]) {
-					c.addError(&SemanticError{
-						Message: fmt.Sprintf("argument %d type mismatch: expected %s, got %s",
-							i+1, ft.Params[i].String(), argType.String()),
-						Pos: expr.Token,
-					})
+	// Argüman tiplerini kontrol et
+	for i, argType := range argTypes {
+		if i < len(ft.Params) {
+			if !c.isAssignable(argType, ft.Params[i]) {
+				message := argumentMismatch(i+1, ft.Params[i], argType)
+				if instantiated != "" {
+					message = i18n.T("sema.in_call", message, instantiated)
 				}
+				c.addError(&SemanticError{
+					Code:    diag.ArgumentTypeMismatch,
+					Message: message,
+					Pos:     expr.Token,
+				})
 			}
 		}
-
-		return ft.ReturnType
 	}
-
-	return AnyType
 }
 
 func (c *Checker) checkIndexExpression(expr *ast.IndexExpression) Type {
+	// Box[int](3): tip argümanı bir ifade olarak kontrol edilmez
+	if name, ok := c.genericName(expr.Left); ok {
+		c.addError(&SemanticError{
+			Code:    diag.ExplicitTypeArguments,
+			Message: i18n.T("sema.explicit_type_args", name),
+			Pos:     expr.Token,
+		})
+		return c.checkExpression(expr.Left)
+	}
+
 	leftType := c.checkExpression(expr.Left)
 	indexType := c.checkExpression(expr.Index)
 
+	if c.strictNull {
+		c.checkNotNil(expr.Left, leftType, i18n.T("sema.nil_index"), expr.Token)
+	}
+
 	if listType, ok := leftType.(*ListType); ok {
 		if indexType != IntType && indexType != AnyType {
 			c.addError(&SemanticError{
-				Message: fmt.Sprintf("list index must be int, got %s", indexType.String()),
+				Code:    diag.InvalidIndex,
+				Message: i18n.T("sema.list_index", indexType.String()),
 				Pos:     expr.Token,
 			})
 		}
@@ -755,7 +1105,8 @@ func (c *Checker) checkIndexExpression(expr *ast.IndexExpression) Type {
 	if dictType, ok := leftType.(*DictType); ok {
 		if !indexType.IsAssignableTo(dictType.KeyType) {
 			c.addError(&SemanticError{
-				Message: fmt.Sprintf("dict key type mismatch: expected %s, got %s",
+				Code: diag.TypeMismatch,
+				Message: i18n.T("sema.dict_key_mismatch",
 					dictType.KeyType.String(), indexType.String()),
 				Pos: expr.Token,
 			})
@@ -767,28 +1118,48 @@ func (c *Checker) checkIndexExpression(expr *ast.IndexExpression) Type {
 }
 
 func (c *Checker) checkMemberExpression(expr *ast.MemberExpression) Type {
-	// Şimdilik basit implementasyon
-	c.checkExpression(expr.Object)
-	return AnyType
+	if ident, ok := expr.Object.(*ast.Identifier); ok && ident.Value == "super" {
+		return c.superMember(expr)
+	}
+
+	objectType := c.checkExpression(expr.Object)
+	c.checkNotNil(expr.Object, objectType, i18n.T("sema.nil_access", expr.Member.Value), expr.Token)
+
+	// Modül üyeleri export edilen tipleriyle çözülür
+	if moduleType, ok := objectType.(*ModuleType); ok {
+		member, ok := moduleType.Member(expr.Member.Value)
+		if !ok {
+			c.addError(&SemanticError{
+				Code:    diag.UndefinedMember,
+				Message: i18n.T("sema.no_exported_member", moduleType.String(), expr.Member.Value),
+				Pos:     expr.Member.Token,
+			})
+			return AnyType
+		}
+		return member.Type
+	}
+	return c.memberType(expr, objectType)
 }
 
 func (c *Checker) checkAwaitExpression(expr *ast.AwaitExpression) Type {
 	// Async fonksiyon içinde miyiz kontrol et
 	if c.currentFunction == nil || !c.currentFunction.IsAsync {
 		c.addError(&SemanticError{
-			Message: "await can only be used in async functions",
+			Code:    diag.AwaitOutsideAsync,
+			Message: i18n.T("sema.await_outside"),
 			Pos:     expr.Token,
 		})
 	}
 
-	return c.checkExpression(expr.Expression)
+	return awaited(c.checkExpression(expr.Expression))
 }
 
 func (c *Checker) checkYieldExpression(expr *ast.YieldExpression) Type {
-	// Coop fonksiyon içinde miyiz kontrol et (şimdilik basit kontrol)
-	if c.currentFunction == nil {
+	// Coop fonksiyon içinde miyiz kontrol et
+	if c.currentFunction == nil || !c.currentFunction.IsCoop {
 		c.addError(&SemanticError{
-			Message: "yield can only be used in coop functions",
+			Code:    diag.YieldOutsideCoop,
+			Message: i18n.T("sema.yield_outside"),
 			Pos:     expr.Token,
 		})
 	}
@@ -823,13 +1194,13 @@ func (c *Checker) checkStaticMethodStatement(stmt *ast.StaticMethodStatement) {
 	// Static methods are treated like regular functions
 	var returnType Type = AnyType
 	if stmt.ReturnType != nil {
-		returnType = ResolveType(stmt.ReturnType)
+		returnType = c.resolveType(stmt.ReturnType)
 	}
 
 	paramTypes := []Type{}
 	for _, param := range stmt.Parameters {
 		if param.Type != nil {
-			paramTypes = append(paramTypes, ResolveType(param.Type))
+			paramTypes = append(paramTypes, c.resolveType(param.Type))
 		} else {
 			paramTypes = append(paramTypes, AnyType)
 		}
@@ -860,7 +1231,7 @@ func (c *Checker) checkStaticPropertyStatement(stmt *ast.StaticPropertyStatement
 	// Tip anotasyonu varsa kontrol et
 	var declaredType Type = AnyType
 	if stmt.Type != nil {
-		declaredType = ResolveType(stmt.Type)
+		declaredType = c.resolveType(stmt.Type)
 	} else {
 		declaredType = valueType
 	}
@@ -868,7 +1239,8 @@ func (c *Checker) checkStaticPropertyStatement(stmt *ast.StaticPropertyStatement
 	// Tip uyumluluğu kontrolü
 	if stmt.Type != nil && !valueType.IsAssignableTo(declaredType) {
 		c.addError(&SemanticError{
-			Message: fmt.Sprintf("type mismatch: cannot assign %s to %s",
+			Code: diag.TypeMismatch,
+			Message: i18n.T("sema.type_mismatch",
 				valueType.String(), declaredType.String()),
 			Pos: stmt.Token,
 		})
@@ -1,13 +1,22 @@
 package sema
 
 import (
+	"os"
 	"testing"
 
 	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 	"github.com/mburakmmm/sky-lang/internal/lexer"
 	"github.com/mburakmmm/sky-lang/internal/parser"
 )
 
+// Testler İngilizce mesajları karşılaştırır; SKY_LANG=tr ile de geçsinler
+func TestMain(m *testing.M) {
+	i18n.SetLocale(i18n.English)
+	os.Exit(m.Run())
+}
+
 func TestCheckLetStatement(t *testing.T) {
 	input := `let x = 10
 let y: int = 20
@@ -235,8 +244,985 @@ end`
 	}
 }
 
+func TestCheckInterfaceImplemented(t *testing.T) {
+	input := `interface Shape
+  function area(): float
+end
+
+class Circle implements Shape
+  function area(): float
+    return 3.14
+  end
+end
+
+function describe(s: Shape)
+  print(s)
+end
+
+describe(Circle())`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 0 {
+		t.Fatalf("expected no errors, got %d: %v", len(errors), errors)
+	}
+}
+
+func TestCheckInterfaceMissingMethod(t *testing.T) {
+	input := `interface Shape
+  function area(): float
+end
+
+class Square implements Shape
+  function side(): float
+    return 1.0
+  end
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
+	}
+
+	if !contains(errors[0].Error(), "missing method area") {
+		t.Fatalf("expected missing method error, got: %v", errors[0])
+	}
+}
+
+func TestCheckInterfaceSignatureMismatch(t *testing.T) {
+	input := `interface Shape
+  function area(): float
+end
+
+class Blob implements Shape
+  function area(): string
+    return "big"
+  end
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
+	}
+
+	if !contains(errors[0].Error(), "method area has signature") {
+		t.Fatalf("expected signature mismatch error, got: %v", errors[0])
+	}
+}
+
+func TestCheckInterfaceStructural(t *testing.T) {
+	// implements olmadan da yapısal olarak uyumlu sınıf kabul edilir
+	input := `interface Named
+  function name(): string
+end
+
+class Dog
+  function name(): string
+    return "rex"
+  end
+end
+
+class Rock
+  function weight(): int
+    return 10
+  end
+end
+
+function greet(n: Named)
+  print(n)
+end
+
+greet(Dog())
+greet(Rock())`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
+	}
+
+	if !contains(errors[0].Error(), "Rock does not satisfy Named") {
+		t.Fatalf("expected structural mismatch error, got: %v", errors[0])
+	}
+}
+
+func TestCheckGenericFunctionInference(t *testing.T) {
+	input := `function first[T](xs: [T]): T
+  return xs[0]
+end
+
+let a: int = first([1, 2, 3])
+let b: string = first([1, 2])`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
+	}
+
+	if !contains(errors[0].Error(), "cannot assign int to string") {
+		t.Fatalf("expected inferred int result, got: %v", errors[0])
+	}
+}
+
+func TestCheckGenericArgumentMismatch(t *testing.T) {
+	input := `function pair[T](a: T, b: T): [T]
+  return [a, b]
+end
+
+pair(1, "x")`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
+	}
+
+	if !contains(errors[0].Error(), "expected int, got string in call to pair[int]") {
+		t.Fatalf("expected instantiated mismatch error, got: %v", errors[0])
+	}
+}
+
+func TestCheckGenericBodyUsesTypeParam(t *testing.T) {
+	input := `function wrong[T](x: T): T
+  return 5
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
+	}
+
+	if !contains(errors[0].Error(), "expected T, got int") {
+		t.Fatalf("expected type parameter mismatch, got: %v", errors[0])
+	}
+}
+
+func TestCheckGenericBound(t *testing.T) {
+	input := `interface Named
+  function name(): string
+end
+
+class Dog
+  function name(): string
+    return "rex"
+  end
+end
+
+function loudest[T: Named](items: [T]): T
+  return items[0]
+end
+
+loudest([Dog()])
+loudest([1, 2])`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
+	}
+
+	if !contains(errors[0].Error(), "type int does not satisfy Named") {
+		t.Fatalf("expected bound error, got: %v", errors[0])
+	}
+}
+
+func TestCheckGenericClass(t *testing.T) {
+	input := `class Box[T]
+  function init(value: T)
+    self.value = value
+  end
+end
+
+let a: Box[int] = Box(5)
+let b: Box[string] = Box(5)
+let c: Box[int, int] = Box(1)`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(errors), errors)
+	}
+
+	if !contains(errors[0].Error(), "cannot assign Box[int] to Box[string]") {
+		t.Fatalf("expected instantiated class mismatch, got: %v", errors[0])
+	}
+
+	if !contains(errors[1].Error(), "wrong number of type arguments for Box") {
+		t.Fatalf("expected type argument count error, got: %v", errors[1])
+	}
+}
+
+func TestCheckExplicitTypeArguments(t *testing.T) {
+	input := `class Box[T]
+  function init(value: T)
+    self.value = value
+  end
+end
+
+function first[T](xs: [T]): T
+  return xs[0]
+end
+
+let a = Box[string]("x")
+let b: Box[string] = Box[int](3)
+let c: string = first[int]([1])`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	// Çağrılar tip argümanları yazılmamış gibi kontrol edilir
+	want := []string{
+		"explicit type arguments are not supported: Box infers them from its arguments",
+		"cannot assign Box[int] to Box[string]",
+		"explicit type arguments are not supported: Box infers them from its arguments",
+		"cannot assign int to string",
+		"explicit type arguments are not supported: first infers them from its arguments",
+	}
+	if len(errors) != len(want) {
+		t.Fatalf("expected %d errors, got %d: %v", len(want), len(errors), errors)
+	}
+	for i, err := range errors {
+		if !contains(err.Error(), want[i]) {
+			t.Errorf("error %d is %q, want %q", i, err, want[i])
+		}
+		if contains(err.Error(), "undefined") {
+			t.Errorf("type argument checked as an expression: %v", err)
+		}
+	}
+}
+
+func TestCheckNullDereference(t *testing.T) {
+	input := `function greet(name: string?)
+  print(name.upper())
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
+	}
+
+	if !contains(errors[0].Error(), "possibly nil value") {
+		t.Fatalf("expected possibly nil error, got: %v", errors[0])
+	}
+}
+
+func TestCheckNullNarrowing(t *testing.T) {
+	input := `function greet(name: string?, other: string?)
+  if name != nil
+    print(name.upper())
+  end
+  if other == nil
+    return
+  end
+  print(other.upper())
+  if name != nil && name.upper() == "A"
+    print(name)
+  end
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 0 {
+		t.Fatalf("expected no errors, got %d: %v", len(errors), errors)
+	}
+}
+
+func TestCheckNullNarrowingInvalidatedByAssignment(t *testing.T) {
+	input := `function greet(name: string?)
+  if name != nil
+    name = nil
+    print(name.upper())
+  end
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
+	}
+}
+
+func TestCheckIsinstanceNarrowing(t *testing.T) {
+	input := `function show(value: int|string)
+  if isinstance(value, "int")
+    let n: int = value
+  else
+    let s: string = value
+  end
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	checker.SetStrictNull(true)
+	errors := checker.Check(program)
+
+	if len(errors) != 0 {
+		t.Fatalf("expected no errors, got %d: %v", len(errors), errors)
+	}
+}
+
+func TestCheckMatchNilArmNarrowing(t *testing.T) {
+	input := `function size(name: string?): int
+  return match name
+    nil => 0
+    s => len(s.upper())
+  end
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 0 {
+		t.Fatalf("expected no errors, got %d: %v", len(errors), errors)
+	}
+}
+
+func TestCheckStrictNull(t *testing.T) {
+	input := `function unwrap(name: string?): string
+  return name
+end`
+
+	program := parseProgram(t, input)
+	if errors := NewChecker().Check(program); len(errors) != 0 {
+		t.Fatalf("expected no errors without strict-null, got %d: %v", len(errors), errors)
+	}
+
+	checker := NewChecker()
+	checker.SetStrictNull(true)
+	errors := checker.Check(program)
+
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
+	}
+
+	if !contains(errors[0].Error(), "expected string, got string|nil") {
+		t.Fatalf("expected strict-null return error, got: %v", errors[0])
+	}
+}
+
+func TestCheckTypesRecordsNarrowedType(t *testing.T) {
+	input := `function greet(name: string?)
+  if name != nil
+    print(name)
+  end
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	checker.Check(program)
+
+	var seen []string
+	for _, info := range checker.Types() {
+		if info.Name == "name" {
+			seen = append(seen, info.Type.String())
+		}
+	}
+
+	// parametre, koşuldaki kullanım, daraltılmış kullanım
+	if len(seen) != 3 || seen[0] != "string|nil" || seen[2] != "string" {
+		t.Fatalf("unexpected recorded types: %v", seen)
+	}
+}
+
+func TestCheckMatchExhaustiveEnum(t *testing.T) {
+	input := `enum Shape
+  Circle(int)
+  Rect(int, int)
+  Empty
+end
+
+function area(s: Shape)
+  match s
+    Circle(r) => print(r * r)
+    Rect(w, h) => print(w * h)
+  end
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
+	}
+	if !contains(errors[0].Error(), "non-exhaustive match on Shape: missing Empty()") {
+		t.Errorf("unexpected error: %s", errors[0].Error())
+	}
+}
+
+func TestCheckMatchExhaustiveInferredEnum(t *testing.T) {
+	input := `enum Color
+  Red
+  Green
+  Blue
+end
+
+function name(c)
+  match c
+    Red() => print("red")
+    Green() => print("green")
+    Blue() => print("blue")
+  end
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 0 {
+		t.Fatalf("expected no errors, got %d: %v", len(errors), errors)
+	}
+}
+
+func TestCheckMatchExhaustiveBoolAndOptional(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`function f(b: bool)
+  match b
+    true => print(1)
+  end
+end`, "non-exhaustive match on bool: missing false"},
+		{`function f(o: int?)
+  match o
+    5 => print(5)
+  end
+end`, "non-exhaustive match on int|nil: missing nil"},
+	}
+
+	for _, tt := range tests {
+		program := parseProgram(t, tt.input)
+		errors := NewChecker().Check(program)
+		if len(errors) != 1 {
+			t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
+		}
+		if !contains(errors[0].Error(), tt.expected) {
+			t.Errorf("expected %q, got %q", tt.expected, errors[0].Error())
+		}
+	}
+}
+
+func TestCheckMatchUnreachableArms(t *testing.T) {
+	input := `enum Shape
+  Circle(int)
+  Empty
+end
+
+function f(s: Shape)
+  match s
+    Circle(r) => print(r)
+    Circle(2) => print("two")
+    Empty() => print("e")
+    Empty() => print("again")
+    _ => print("x")
+  end
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 3 {
+		t.Fatalf("expected 3 errors, got %d: %v", len(errors), errors)
+	}
+	if !contains(errors[0].Error(), "unreachable match arm: Circle(2)") {
+		t.Errorf("unexpected error: %s", errors[0].Error())
+	}
+	if !contains(errors[1].Error(), "duplicate match arm: Empty()") {
+		t.Errorf("unexpected error: %s", errors[1].Error())
+	}
+	if !contains(errors[2].Error(), "unreachable match arm: _") {
+		t.Errorf("unexpected error: %s", errors[2].Error())
+	}
+}
+
+func TestCheckMatchPayloadTypes(t *testing.T) {
+	input := `enum Shape
+  Circle(int)
+  Rect(int, int)
+end
+
+function f(s: Shape)
+  match s
+    Circle(r) => print(r.upper())
+    Rect(w) => print(w)
+  end
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	found := false
+	for _, err := range errors {
+		if contains(err.Error(), "variant Rect has 2 payload value(s), pattern has 1") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected payload count error, got %v", errors)
+	}
+
+	for _, info := range checker.Types() {
+		if info.Name == "r" && info.Type != IntType {
+			t.Errorf("expected r to be int, got %s", info.Type)
+		}
+	}
+}
+
+func TestCheckMatchGuardsAndOrPatterns(t *testing.T) {
+	input := `enum Color
+  Red
+  Green
+  Blue
+end
+
+function f(c: Color, n: int)
+  match c
+    Red() | Green() => print("warm")
+    Blue() if n > 0 => print("cold")
+  end
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	// Guard'lı kol kapsamlılığa sayılmaz
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
+	}
+	if !contains(errors[0].Error(), "non-exhaustive match on Color: missing Blue()") {
+		t.Errorf("unexpected error: %s", errors[0].Error())
+	}
+}
+
+func TestCheckMatchOrPatternBindings(t *testing.T) {
+	input := `enum Shape
+  Circle(int)
+  Square(int)
+end
+
+function f(s: Shape)
+  match s
+    Circle(a) | Square(b) => print(a)
+  end
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errors), errors)
+	}
+	if !contains(errors[0].Error(), "or-pattern alternatives must bind the same variables") {
+		t.Errorf("unexpected error: %s", errors[0].Error())
+	}
+}
+
+func TestCheckMatchRichPatternBindings(t *testing.T) {
+	input := `class Point
+  function init(x, y)
+    self.x = x
+    self.y = y
+  end
+end
+
+function f(xs: [int], p, e: {string: string})
+  match xs
+    [first, ...rest] as all => print(first, rest, all)
+    _ => print("empty")
+  end
+  match p
+    Point(x: 0, y: y) => print(y)
+    Point() => print("point")
+    _ => print("other")
+  end
+  match e
+    {"type": "click", "id": id} if id != "" => print(id)
+    _ => print("other")
+  end
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 0 {
+		t.Fatalf("expected no errors, got %d: %v", len(errors), errors)
+	}
+
+	expected := map[string]string{"first": "int", "rest": "[int]", "all": "[int]", "id": "string"}
+	for _, info := range checker.Types() {
+		if want, ok := expected[info.Name]; ok && info.Type.String() != want {
+			t.Errorf("expected %s to be %s, got %s", info.Name, want, info.Type)
+		}
+	}
+}
+
 // Helper functions
 
+func TestCheckInferredReturnTypes(t *testing.T) {
+	input := `function double(x: int)
+  return x * 2
+end
+
+function label(n: int)
+  if n > 0
+    return "positive"
+  end
+end
+
+function ratio(flag: bool)
+  if flag
+    return 1
+  else
+    return 0.5
+  end
+end
+
+function count(n: int)
+  if n == 0
+    return 0
+  end
+  return count(n - 1) + 1
+end
+
+function log(msg: string)
+  print(msg)
+end
+
+let s: string = double(2)`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	if len(errors) != 1 || !contains(errors[0].Error(), "cannot assign int to string") {
+		t.Fatalf("expected an int to string error, got %d: %v", len(errors), errors)
+	}
+
+	want := []string{
+		"function double(x: int): int",
+		"function label(n: int): string|nil",
+		"function ratio(flag: bool): float",
+		"function count(n: int): int",
+		"function log(msg: string): void",
+	}
+	functions := checker.Functions()
+	if len(functions) != len(want) {
+		t.Fatalf("expected %d functions, got %d", len(want), len(functions))
+	}
+	for i, f := range functions {
+		if !f.ReturnInferred {
+			t.Errorf("%s: return type not marked inferred", f.Name)
+		}
+		if got := f.Signature(); got != want[i] {
+			t.Errorf("signature is %q, want %q", got, want[i])
+		}
+	}
+}
+
+func TestCheckRecursiveReturnInference(t *testing.T) {
+	input := `function fact(n: int)
+  if n <= 1
+    return 1
+  end
+  return n * fact(n - 1)
+end
+
+function fib(n: int)
+  if n < 2
+    return n
+  end
+  let a = fib(n - 1)
+  let b: string = a
+  return a + fib(n - 2)
+end
+
+function forever(n)
+  return forever(n)
+end
+
+let s: string = fact(3)`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	// The error inside fib is reported once, with the inferred type
+	want := []string{
+		"test.sky:13:3: type mismatch: cannot assign int to string",
+		"test.sky:21:1: type mismatch: cannot assign int to string",
+	}
+	if len(errors) != len(want) {
+		t.Fatalf("expected %d errors, got %d: %v", len(want), len(errors), errors)
+	}
+	for i, err := range errors {
+		if !contains(err.Error(), want[i]) {
+			t.Errorf("error %d is %q, want %q", i, err, want[i])
+		}
+	}
+
+	signatures := []string{
+		"function fact(n: int): int",
+		"function fib(n: int): int",
+		"function forever(n: any): any",
+	}
+	functions := checker.Functions()
+	if len(functions) != len(signatures) {
+		t.Fatalf("expected %d functions, got %d", len(signatures), len(functions))
+	}
+	for i, f := range functions {
+		if got := f.Signature(); got != signatures[i] {
+			t.Errorf("signature is %q, want %q", got, signatures[i])
+		}
+	}
+}
+
+func TestCheckErrorOrder(t *testing.T) {
+	input := `class Animal
+  function init(name: string)
+    self.name = name
+    self.age = 1
+  end
+
+  function describe()
+    return self.color
+  end
+end
+
+let a = Animal("rex")
+a.age = "old"`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	// Sona bırakılan üye kontrolleri de kaynaktaki sıraya göre raporlanır
+	want := []string{
+		"test.sky:8:17: Animal has no field or method color",
+		"test.sky:13:7: type mismatch: cannot assign string to int",
+	}
+	if len(errors) != len(want) {
+		t.Fatalf("expected %d errors, got %d: %v", len(want), len(errors), errors)
+	}
+	for i, err := range errors {
+		if !contains(err.Error(), want[i]) {
+			t.Errorf("error %d is %q, want %q", i, err, want[i])
+		}
+	}
+}
+
+func TestCheckLambdaContext(t *testing.T) {
+	input := `function apply(f: (string) => int, s: string): int
+  return f(s)
+end
+
+function shout(s: string): string
+  return s
+end
+
+let doubled = map(function(x) x * 2 end, [1, 2, 3])
+let words: [string] = doubled
+apply(function(s) len(shout(s)) end, "sky")
+apply(function(n) shout(n) end, "sky")
+map(function(x) shout(x) end, [1])`
+
+	program := parseProgram(t, input)
+	errors := NewChecker().Check(program)
+
+	want := []string{
+		"cannot assign [int] to [string]",
+		"argument 1 type mismatch: expected (string) => int, got (string) => string",
+		"argument 1 type mismatch: expected string, got int",
+	}
+	if len(errors) != len(want) {
+		t.Fatalf("expected %d errors, got %d: %v", len(want), len(errors), errors)
+	}
+	for i, err := range errors {
+		if !contains(err.Error(), want[i]) {
+			t.Errorf("error %d is %q, want %q", i, err, want[i])
+		}
+	}
+}
+
+func TestCheckClassMembers(t *testing.T) {
+	input := `class Animal
+  let name: string = ""
+
+  function init(name: string)
+    self.name = name
+    self.age = 0
+  end
+
+  function speak(): string
+    return self.sound()
+  end
+
+  function rename(name: string)
+    self.name = name
+  end
+end
+
+class Dog : Animal
+  function sound(): string
+    return "woof"
+  end
+
+  function speak(): int
+    return 1
+  end
+
+  function fetch()
+    super.missing()
+  end
+end
+
+class Rock
+  function roll()
+    super.roll()
+  end
+end
+
+let a = Animal("rex")
+let n: int = a.age
+a.rename(1)
+a.color
+a.color = "red"
+a.name = 3
+a.age = "old"
+a.age = 4
+Animal()
+a.rename("max", "extra")`
+
+	program := parseProgram(t, input)
+	errors := NewChecker().Check(program)
+
+	want := []string{
+		"method Dog.speak is incompatible with Animal.speak",
+		"Animal has no method missing",
+		"super used in class Rock, which has no superclass",
+		"argument 1 type mismatch: expected string, got int",
+		"Animal has no field or method color",
+		"Animal has no field color",
+		"cannot assign int to string",
+		"cannot assign string to int",
+		"wrong number of arguments: expected 1, got 0",
+		"wrong number of arguments: expected 1, got 2",
+	}
+	if len(errors) != len(want) {
+		t.Fatalf("expected %d errors, got %d: %v", len(want), len(errors), errors)
+	}
+	for i, err := range errors {
+		if !contains(err.Error(), want[i]) {
+			t.Errorf("error %d is %q, want %q", i, err, want[i])
+		}
+	}
+}
+
+func TestCheckAsync(t *testing.T) {
+	input := `async function fetch(): int
+  return 42
+end
+
+async function total()
+  let a = await fetch()
+  return a + 1
+end
+
+async function poll(): int
+  time_sleep(10)
+  await time_sleep_async(10)
+  return "done"
+end
+
+function numbers()
+  yield 1
+end
+
+coop function counter()
+  yield 1
+end
+
+async function main
+  fetch()
+  let pending = fetch()
+  let n: int = await pending
+  let m: int = fetch()
+  let later = function() await fetch() end
+end`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	errors := checker.Check(program)
+
+	want := []string{
+		"return type mismatch: expected int, got string",
+		"yield can only be used in coop functions",
+		"result of async call fetch (Promise[int]) is discarded",
+		"cannot assign Promise[int] to int",
+		"await can only be used in async functions",
+	}
+	if len(errors) != len(want) {
+		t.Fatalf("expected %d errors, got %d: %v", len(want), len(errors), errors)
+	}
+	for i, err := range errors {
+		if !contains(err.Error(), want[i]) {
+			t.Errorf("error %d is %q, want %q", i, err, want[i])
+		}
+	}
+
+	// Blocking calls only slow the other tasks down
+	warnings := checker.Warnings()
+	if len(warnings) != 1 || !contains(warnings[0].Error(), "time_sleep blocks the async function poll; use await time_sleep_async(...) instead") {
+		t.Errorf("warnings are %v, want the blocking time_sleep", warnings)
+	}
+
+	signatures := map[string]string{
+		"fetch": "async function fetch(): Promise[int]",
+		"total": "async function total(): Promise[int]",
+	}
+	for _, f := range checker.Functions() {
+		if want, ok := signatures[f.Name]; ok && f.Signature() != want {
+			t.Errorf("signature of %s is %q, want %q", f.Name, f.Signature(), want)
+		}
+	}
+}
+
 func parseProgram(t *testing.T, input string) *ast.Program {
 	l := lexer.New(input, "test.sky")
 	p := parser.New(l)
@@ -263,3 +1249,45 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestErrorCodes(t *testing.T) {
+	input := `let x: int = "a"
+print(missing)
+return 1`
+
+	program := parseProgram(t, input)
+	errors := NewChecker().Check(program)
+
+	want := []string{diag.TypeMismatch, diag.UndefinedName, diag.ReturnOutsideFunction}
+	if len(errors) != len(want) {
+		t.Fatalf("expected %d errors, got %d: %v", len(want), len(errors), errors)
+	}
+	for i, err := range errors {
+		if code := diag.CodeOf(err); code != want[i] {
+			t.Errorf("error %d (%s) has code %q, want %s", i, err, code, want[i])
+		}
+	}
+}
+
+func TestSuppressedErrors(t *testing.T) {
+	input := `let x: int = "a"  # sky-ignore: E0201
+# sky-ignore: E0102
+print(missing)
+print(other)  # sky-ignore: E0201
+let y: int = "b"`
+
+	program := parseProgram(t, input)
+	checker := NewChecker()
+	checker.SetSource(input)
+	errors := checker.Check(program)
+
+	if len(errors) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(errors), errors)
+	}
+	if !contains(errors[0].Error(), "undefined: other") {
+		t.Errorf("unexpected error: %s", errors[0])
+	}
+	if !contains(errors[1].Error(), "cannot assign string to int") {
+		t.Errorf("unexpected error: %s", errors[1])
+	}
+}
@@ -0,0 +1,313 @@
+package sema
+
+import (
+	"reflect"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+)
+
+// pendingMember self üzerinden okunan ama sınıfta bulunamayan bir üyedir
+// Alt sınıflardan biri tanımlıyorsa (template method) hata değildir;
+// bu yüzden program sonunda, tüm sınıflar bilinirken raporlanır
+type pendingMember struct {
+	class *ClassType
+	name  string
+	pos   lexer.Token
+}
+
+// collectShape sınıfın alanlarını ve static üyelerini metod body'leri kontrol edilmeden toplar
+// Alanlar anotasyonlu let/const üyeleri ile metodlardaki self.x = ... atamalarıdır
+func (c *Checker) collectShape(body []ast.Statement, classType *ClassType) {
+	classType.Statics = make(map[string]Type)
+	classType.declared = make(map[string]bool)
+
+	for _, member := range body {
+		switch m := member.(type) {
+		case *ast.LetStatement:
+			c.declareField(classType, m.Name.Value, m.Type)
+		case *ast.ConstStatement:
+			c.declareField(classType, m.Name.Value, m.Type)
+		case *ast.StaticPropertyStatement:
+			var t Type = AnyType
+			if m.Type != nil {
+				t = c.resolveType(m.Type)
+			}
+			classType.Statics[m.Name.Value] = t
+		case *ast.StaticMethodStatement:
+			classType.Statics[m.Name.Value] = c.signatureOf(m.Parameters, m.ReturnType)
+		case *ast.AbstractMethodStatement:
+			classType.Methods[m.Name.Value] = c.signatureOf(m.Parameters, m.ReturnType)
+		case *ast.FunctionStatement:
+			walkSelfMembers(reflect.ValueOf(m.Body), func(target *ast.MemberExpression) {
+				if _, ok := classType.Fields[target.Member.Value]; !ok {
+					classType.Fields[target.Member.Value] = AnyType
+				}
+			})
+		}
+	}
+}
+
+func (c *Checker) declareField(classType *ClassType, name string, annotation ast.TypeAnnotation) {
+	if annotation == nil {
+		classType.Fields[name] = AnyType
+		return
+	}
+	classType.Fields[name] = c.resolveType(annotation)
+	classType.declared[name] = true
+}
+
+// walkSelfMembers atama hedefi olan self.x ifadelerini bulur
+// İç içe sınıflara inilmez; orada self başka bir nesnedir
+func walkSelfMembers(v reflect.Value, fn func(*ast.MemberExpression)) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if v.CanInterface() {
+			switch n := v.Interface().(type) {
+			case *ast.ClassStatement, *ast.AbstractClassStatement:
+				return
+			case *ast.InfixExpression:
+				if target, ok := n.Left.(*ast.MemberExpression); ok && isSelf(target.Object) && isAssignment(n.Operator) {
+					fn(target)
+				}
+			}
+		}
+		walkSelfMembers(v.Elem(), fn)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			walkSelfMembers(v.Field(i), fn)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkSelfMembers(v.Index(i), fn)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			walkSelfMembers(iter.Key(), fn)
+			walkSelfMembers(iter.Value(), fn)
+		}
+	}
+}
+
+func isSelf(expr ast.Expression) bool {
+	ident, ok := expr.(*ast.Identifier)
+	return ok && ident.Value == "self"
+}
+
+func isAssignment(operator string) bool {
+	switch operator {
+	case "=", "+=", "-=", "*=", "/=", "%=":
+		return true
+	}
+	return false
+}
+
+// checkOverrides override edilen metodların parent imzasıyla uyumlu olduğunu kontrol eder
+// Constructor'lar (init) her sınıfta farklı parametre alabilir
+func (c *Checker) checkOverrides(body []ast.Statement, classType *ClassType) {
+	for _, member := range body {
+		fn, ok := member.(*ast.FunctionStatement)
+		if !ok || fn.Name.Value == "init" {
+			continue
+		}
+		method := classType.Methods[fn.Name.Value]
+		for _, superClass := range classType.SuperClasses {
+			inherited, ok := superClass.LookupMethod(fn.Name.Value)
+			if !ok {
+				continue
+			}
+			if !methodSatisfies(method, inherited) {
+				c.addError(&SemanticError{
+					Code: diag.IncompatibleOverride,
+					Message: i18n.T("sema.incompatible_method",
+						classType.Name, fn.Name.Value, superClass.Name, fn.Name.Value,
+						inherited.String(), method.String()),
+					Pos: fn.Name.Token,
+				})
+			}
+			break
+		}
+	}
+}
+
+// checkMethod metodu kontrol eder; anotasyonsuz return tipi çıkarılınca
+// sınıfın metod imzasına da yazılır
+func (c *Checker) checkMethod(fn *ast.FunctionStatement, classType *ClassType) {
+	n := len(c.functions)
+	c.checkFunctionStatement(fn)
+	if len(c.functions) == n {
+		return
+	}
+	info := c.functions[len(c.functions)-1]
+	if method, ok := classType.Methods[fn.Name.Value]; ok && info.ReturnInferred {
+		method.ReturnType = info.Type.ReturnType
+	}
+}
+
+// memberType bir sınıf üyesine erişimin tipini döndürür
+func (c *Checker) memberType(expr *ast.MemberExpression, objectType Type) Type {
+	classType, ok := objectType.(*ClassType)
+	if !ok {
+		return AnyType
+	}
+	name := expr.Member.Value
+
+	// Sınıf adı üzerinden erişim: static üyeler ve metodlar
+	if c.isClassName(expr.Object) {
+		if t, ok := classType.LookupStatic(name); ok {
+			return t
+		}
+		if method, ok := classType.LookupMethod(name); ok {
+			return method
+		}
+		c.addError(&SemanticError{
+			Code:    diag.UndefinedMember,
+			Message: i18n.T("sema.class_no_member", classType.Name, name),
+			Pos:     expr.Member.Token,
+		})
+		return AnyType
+	}
+
+	if t, ok := classType.LookupField(name); ok {
+		return t
+	}
+	if method, ok := classType.LookupMethod(name); ok {
+		return method
+	}
+	if isSelf(expr.Object) {
+		c.pendingMembers = append(c.pendingMembers, pendingMember{class: classType, name: name, pos: expr.Member.Token})
+		return AnyType
+	}
+	c.addError(&SemanticError{
+		Code:    diag.UndefinedMember,
+		Message: i18n.T("sema.no_field_or_method", classType.Name, name),
+		Pos:     expr.Member.Token,
+	})
+	return AnyType
+}
+
+// memberTarget atanan bir üyenin tipini döndürür
+// Anotasyonlu alanlar ve tipi init'ten çıkarılan alanlar atamayı kısıtlar;
+// henüz çıkarılmamış alanların tipi any'dir
+func (c *Checker) memberTarget(expr *ast.MemberExpression) Type {
+	objectType := c.checkExpression(expr.Object)
+	c.checkNotNil(expr.Object, objectType, i18n.T("sema.nil_assign", expr.Member.Value), expr.Token)
+
+	classType, ok := objectType.(*ClassType)
+	if !ok || c.isClassName(expr.Object) {
+		return AnyType
+	}
+	name := expr.Member.Value
+	if t, ok := classType.LookupField(name); ok {
+		return t
+	}
+	if _, ok := classType.LookupMethod(name); ok {
+		return AnyType
+	}
+	c.addError(&SemanticError{
+		Code:    diag.UndefinedMember,
+		Message: i18n.T("sema.no_field", classType.Name, name),
+		Pos:     expr.Member.Token,
+	})
+	return AnyType
+}
+
+// inferField init'teki ilk atamadan anotasyonsuz alanın tipini çıkarır
+func (c *Checker) inferField(target *ast.MemberExpression, valueType Type) {
+	if !c.inInit || c.currentClass == nil || !isSelf(target.Object) {
+		return
+	}
+	name := target.Member.Value
+	if c.currentClass.declared[name] || c.initAssigned[name] {
+		return
+	}
+	c.initAssigned[name] = true
+	if valueType == NilType || valueType == VoidType {
+		valueType = AnyType
+	}
+	c.currentClass.Fields[name] = valueType
+}
+
+func (c *Checker) isClassName(expr ast.Expression) bool {
+	ident, ok := expr.(*ast.Identifier)
+	if !ok {
+		return false
+	}
+	symbol, ok := c.symTable.Resolve(ident.Value)
+	return ok && symbol.Kind == ClassSymbol
+}
+
+// superMember super.m erişimini ilk parent sınıfın kendi metodlarında çözer
+func (c *Checker) superMember(expr *ast.MemberExpression) Type {
+	if c.currentClass == nil {
+		c.addError(&SemanticError{
+			Code:    diag.InvalidSuper,
+			Message: i18n.T("sema.super_outside"),
+			Pos:     expr.Token,
+		})
+		return AnyType
+	}
+	if len(c.currentClass.SuperClasses) == 0 {
+		c.addError(&SemanticError{
+			Code:    diag.InvalidSuper,
+			Message: i18n.T("sema.super_no_superclass", c.currentClass.Name),
+			Pos:     expr.Token,
+		})
+		return AnyType
+	}
+	superClass := c.currentClass.SuperClasses[0]
+	if method, ok := superClass.Methods[expr.Member.Value]; ok {
+		return method
+	}
+	c.addError(&SemanticError{
+		Code:    diag.InvalidSuper,
+		Message: i18n.T("sema.no_method", superClass.Name, expr.Member.Value),
+		Pos:     expr.Member.Token,
+	})
+	return AnyType
+}
+
+// checkPendingMembers self üzerinden okunan bilinmeyen üyeleri alt sınıflarla birlikte kontrol eder
+func (c *Checker) checkPendingMembers() {
+	for _, p := range c.pendingMembers {
+		if c.definedInSubclass(p.class, p.name) {
+			continue
+		}
+		c.addError(&SemanticError{
+			Code:    diag.UndefinedMember,
+			Message: i18n.T("sema.no_field_or_method", p.class.Name, p.name),
+			Pos:     p.pos,
+		})
+	}
+	c.pendingMembers = nil
+}
+
+func (c *Checker) definedInSubclass(class *ClassType, name string) bool {
+	for _, sub := range c.classes {
+		if sub == class || !inherits(sub, class) {
+			continue
+		}
+		if _, ok := sub.LookupField(name); ok {
+			return true
+		}
+		if _, ok := sub.LookupMethod(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func inherits(class, ancestor *ClassType) bool {
+	for _, superClass := range class.SuperClasses {
+		if superClass == ancestor || inherits(superClass, ancestor) {
+			return true
+		}
+	}
+	return false
+}
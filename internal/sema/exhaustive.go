@@ -0,0 +1,407 @@
+package sema
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+)
+
+// Match kapsamlılık ve erişilebilirlik analizi
+//
+// Pattern'ler constructor/wildcard ağacına indirgenir ve klasik "usefulness"
+// algoritması uygulanır: bir pattern, önceki kolların eşlemediği en az bir
+// değeri eşliyorsa faydalıdır. Subject'i eşleyen joker bir satır hala faydalıysa
+// match kapsamlı değildir; faydasız bir kol ise erişilemezdir.
+
+// someCtor optional tiplerde nil olmayan değerleri temsil eden sanal constructor
+const someCtor = "?some"
+
+// space pattern'in kapsamlılık analizi için sadeleştirilmiş hali
+// ctor boşsa pattern her değeri eşler (wildcard veya değişken bağlama);
+// alts doluysa or-pattern'dir ve satırlar alternatiflere açılır
+type space struct {
+	ctor string
+	args []*space
+	alts []*space
+}
+
+var wildcard = &space{}
+
+// patternRow bir match kolunun pattern sütunları
+type patternRow []*space
+
+// ctorSig bir tipin constructor'ı ve argüman tipleri
+type ctorSig struct {
+	name string
+	args []Type
+}
+
+// patternSpace AST pattern'ini space'e dönüştürür
+func (c *Checker) patternSpace(pattern ast.Expression) *space {
+	switch p := pattern.(type) {
+	case *ast.Identifier:
+		if isNilExpression(p) {
+			return &space{ctor: "nil"}
+		}
+		if _, ok := c.variantEnum(p.Value); ok {
+			// Çıplak variant adı payload'dan bağımsız olarak variant'ı eşler
+			return &space{ctor: p.Value}
+		}
+		return wildcard
+
+	case *ast.BooleanLiteral:
+		if p.Value {
+			return &space{ctor: "true"}
+		}
+		return &space{ctor: "false"}
+
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.PrefixExpression:
+		return &space{ctor: "=" + p.String()}
+
+	case *ast.CallExpression:
+		s := &space{ctor: calleeName(p.Function)}
+		for _, arg := range p.Arguments {
+			s.args = append(s.args, c.patternSpace(arg))
+		}
+		return s
+
+	case *ast.ListLiteral:
+		// Rest içeren pattern'ler şekillerine göre ayrı bir constructor ailesidir
+		s := &space{ctor: "[" + strconv.Itoa(len(p.Elements)) + "]"}
+		shape := make([]string, len(p.Elements))
+		hasRest := false
+		for i, elem := range p.Elements {
+			if _, ok := elem.(*ast.RestPattern); ok {
+				shape[i] = "..."
+				hasRest = true
+				continue
+			}
+			shape[i] = "_"
+			s.args = append(s.args, c.patternSpace(elem))
+		}
+		if hasRest {
+			s.ctor = "[" + strings.Join(shape, ", ") + "]"
+		}
+		return s
+
+	case *ast.OrPattern:
+		s := &space{}
+		for _, alt := range p.Alternatives {
+			s.alts = append(s.alts, c.patternSpace(alt))
+		}
+		return s
+
+	case *ast.AsPattern:
+		return c.patternSpace(p.Pattern)
+
+	case *ast.DictPattern, *ast.ClassPattern:
+		// Kısmi eşleşme: yalnızca birebir aynı pattern'i kapsar
+		return &space{ctor: "=" + p.String()}
+	}
+
+	// Bilinmeyen pattern'ler hiçbir constructor ile örtüşmez
+	return &space{ctor: "=" + pattern.String()}
+}
+
+// signature sütun tipinin constructor kümesini döndürür
+// Tip bilinmiyorsa (any) pattern'lerdeki enum variant'ları veya bool literal'lerinden çıkarılır.
+// finite false ise tip sonsuz sayıda değere sahiptir (int, string, list...)
+func (c *Checker) signature(t Type, heads []string) (resolved Type, sigs []ctorSig, finite bool) {
+	if isOptional(t) {
+		return t, []ctorSig{{name: "nil"}, {name: someCtor, args: []Type{removeNil(t)}}}, true
+	}
+
+	if t == AnyType || t == nil {
+		for _, head := range heads {
+			if enum, ok := c.variantEnum(head); ok {
+				t = enum
+				break
+			}
+			if head == "true" || head == "false" {
+				t = BoolType
+				break
+			}
+		}
+	}
+
+	switch tt := t.(type) {
+	case *EnumType:
+		for _, v := range tt.Variants {
+			sigs = append(sigs, ctorSig{name: v.Name, args: v.Payload})
+		}
+		return tt, sigs, true
+	}
+
+	if t == BoolType {
+		return t, []ctorSig{{name: "true"}, {name: "false"}}, true
+	}
+
+	return t, nil, false
+}
+
+// variantEnum ismin bir enum variant constructor'ı olup olmadığını kontrol eder
+func (c *Checker) variantEnum(name string) (*EnumType, bool) {
+	symbol, ok := c.symTable.Resolve(name)
+	if !ok {
+		return nil, false
+	}
+	ft, ok := symbol.Type.(*FunctionType)
+	if !ok {
+		return nil, false
+	}
+	enum, ok := ft.ReturnType.(*EnumType)
+	if !ok {
+		return nil, false
+	}
+	if _, ok := enum.Variant(name); !ok {
+		return nil, false
+	}
+	return enum, true
+}
+
+// expandOr ilk sütunu or-pattern olan satırları alternatif başına bir satıra açar
+func expandOr(rows []patternRow) []patternRow {
+	var result []patternRow
+	for _, row := range rows {
+		if len(row) == 0 || row[0].alts == nil {
+			result = append(result, row)
+			continue
+		}
+		for _, alt := range row[0].alts {
+			next := append(patternRow{alt}, row[1:]...)
+			result = append(result, expandOr([]patternRow{next})...)
+		}
+	}
+	return result
+}
+
+// headCtor pattern'in sütun tipine göre constructor adını döndürür
+func headCtor(s *space, optional bool) string {
+	if optional && s.ctor != "" && s.ctor != "nil" {
+		return someCtor
+	}
+	return s.ctor
+}
+
+// columnHeads matrisin ilk sütunundaki constructor adları
+func columnHeads(rows []patternRow, optional bool) []string {
+	var heads []string
+	for _, row := range rows {
+		if name := headCtor(row[0], optional); name != "" {
+			heads = append(heads, name)
+		}
+	}
+	return heads
+}
+
+// specialize ilk sütunu ctor olan satırları constructor argümanlarıyla açar
+func specialize(rows []patternRow, ctor string, arity int, optional bool) []patternRow {
+	var result []patternRow
+	for _, row := range rows {
+		head := row[0]
+		var args []*space
+		switch {
+		case head.ctor == "":
+			args = make([]*space, arity)
+			for i := range args {
+				args[i] = wildcard
+			}
+		case headCtor(head, optional) != ctor:
+			continue
+		case ctor == someCtor:
+			args = []*space{head}
+		default:
+			args = head.args
+			if len(args) != arity {
+				// Yanlış sayıda argüman: hata bindPattern'de raporlanır
+				args = padArgs(args, arity)
+			}
+		}
+		next := make(patternRow, 0, len(args)+len(row)-1)
+		next = append(next, args...)
+		next = append(next, row[1:]...)
+		result = append(result, next)
+	}
+	return result
+}
+
+// defaultRows ilk sütunu wildcard olan satırların geri kalanı
+func defaultRows(rows []patternRow) []patternRow {
+	var result []patternRow
+	for _, row := range rows {
+		if row[0].ctor == "" {
+			result = append(result, row[1:])
+		}
+	}
+	return result
+}
+
+// useful q satırı rows'un eşlemediği bir değeri eşliyorsa true döner
+func (c *Checker) useful(rows []patternRow, q patternRow, types []Type) bool {
+	if len(q) == 0 {
+		return len(rows) == 0
+	}
+
+	// Or-pattern: alternatiflerden biri faydalıysa satır faydalıdır
+	if q[0].alts != nil {
+		for _, alt := range q[0].alts {
+			if c.useful(rows, append(patternRow{alt}, q[1:]...), types) {
+				return true
+			}
+		}
+		return false
+	}
+
+	rows = expandOr(rows)
+	optional := isOptional(types[0])
+	heads := columnHeads(rows, optional)
+	if q[0].ctor != "" {
+		heads = append(heads, headCtor(q[0], optional))
+	}
+	_, sigs, finite := c.signature(types[0], heads)
+
+	if q[0].ctor != "" {
+		name := headCtor(q[0], optional)
+		args := ctorArgs(sigs, name, len(q[0].args))
+		qs := specialize([]patternRow{q}, name, len(args), optional)[0]
+		return c.useful(specialize(rows, name, len(args), optional), qs, append(args, types[1:]...))
+	}
+
+	if finite && complete(sigs, heads) {
+		for _, sig := range sigs {
+			qs := specialize([]patternRow{q}, sig.name, len(sig.args), optional)[0]
+			if c.useful(specialize(rows, sig.name, len(sig.args), optional), qs, append(append([]Type{}, sig.args...), types[1:]...)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return c.useful(defaultRows(rows), q[1:], types[1:])
+}
+
+// missingPatterns rows tarafından eşlenmeyen değerlere örnek pattern'ler üretir
+func (c *Checker) missingPatterns(rows []patternRow, types []Type) [][]string {
+	if len(types) == 0 {
+		if len(rows) == 0 {
+			return [][]string{{}}
+		}
+		return nil
+	}
+
+	rows = expandOr(rows)
+	optional := isOptional(types[0])
+	heads := columnHeads(rows, optional)
+	_, sigs, finite := c.signature(types[0], heads)
+
+	var result [][]string
+	if finite && complete(sigs, heads) {
+		for _, sig := range sigs {
+			arity := len(sig.args)
+			sub := c.missingPatterns(specialize(rows, sig.name, arity, optional), append(append([]Type{}, sig.args...), types[1:]...))
+			for _, w := range sub {
+				head := renderCtor(sig.name, w[:arity])
+				result = append(result, append([]string{head}, w[arity:]...))
+			}
+		}
+		return result
+	}
+
+	rest := c.missingPatterns(defaultRows(rows), types[1:])
+	if len(rest) == 0 {
+		return nil
+	}
+
+	// Eksik constructor'ları tek tek listele; sonsuz tiplerde "_" kullan
+	var missingHeads []string
+	if finite {
+		for _, sig := range sigs {
+			if !containsString(heads, sig.name) {
+				missingHeads = append(missingHeads, renderCtor(sig.name, wildcards(len(sig.args))))
+			}
+		}
+	} else {
+		missingHeads = []string{"_"}
+	}
+	for _, head := range missingHeads {
+		for _, w := range rest {
+			result = append(result, append([]string{head}, w...))
+		}
+	}
+	return result
+}
+
+// complete sütundaki constructor'lar tipin tüm constructor'larını kapsıyor mu
+func complete(sigs []ctorSig, heads []string) bool {
+	if len(sigs) == 0 {
+		return false
+	}
+	for _, sig := range sigs {
+		if !containsString(heads, sig.name) {
+			return false
+		}
+	}
+	return true
+}
+
+// ctorArgs constructor'ın argüman tiplerini döndürür; bilinmiyorsa any kullanılır
+func ctorArgs(sigs []ctorSig, name string, arity int) []Type {
+	for _, sig := range sigs {
+		if sig.name == name {
+			return append([]Type{}, sig.args...)
+		}
+	}
+	args := make([]Type, arity)
+	for i := range args {
+		args[i] = AnyType
+	}
+	return args
+}
+
+// renderCtor constructor'ı kaynak koddaki pattern sözdizimiyle yazar
+func renderCtor(name string, args []string) string {
+	switch {
+	case name == someCtor:
+		if len(args) == 1 {
+			return args[0]
+		}
+		return "_"
+	case name == "nil" || name == "true" || name == "false":
+		return name
+	case name[0] == '=':
+		return name[1:]
+	case name[0] == '[':
+		return "[" + strings.Join(args, ", ") + "]"
+	}
+	return name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func padArgs(args []*space, arity int) []*space {
+	padded := make([]*space, arity)
+	for i := range padded {
+		if i < len(args) {
+			padded[i] = args[i]
+		} else {
+			padded[i] = wildcard
+		}
+	}
+	return padded
+}
+
+func wildcards(n int) []string {
+	result := make([]string, n)
+	for i := range result {
+		result[i] = "_"
+	}
+	return result
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,424 @@
+package sema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+)
+
+// TypeParam generic tip parametresini temsil eder (T veya T: Bound)
+type TypeParam struct {
+	Name       string
+	Constraint Type // nil ise sınırsız
+}
+
+func (t *TypeParam) String() string {
+	return t.Name
+}
+
+func (t *TypeParam) Equals(other Type) bool {
+	o, ok := other.(*TypeParam)
+	return ok && o == t
+}
+
+func (t *TypeParam) IsAssignableTo(target Type) bool {
+	if target == AnyType || t.Equals(target) {
+		return true
+	}
+
+	if unionTarget, ok := target.(*UnionType); ok {
+		for _, memberType := range unionTarget.Types {
+			if t.IsAssignableTo(memberType) {
+				return true
+			}
+		}
+	}
+
+	// Sınırlı parametre, sınırının atanabildiği yere atanabilir
+	if t.Constraint != nil {
+		return t.Constraint.IsAssignableTo(target)
+	}
+
+	return false
+}
+
+// typeBindings tip parametrelerinin somut tiplere bağlanmasını tutar
+type typeBindings map[*TypeParam]Type
+
+// bindings somutlaştırılmış sınıfın tip parametre bağlamalarını döndürür
+func (t *ClassType) bindings() typeBindings {
+	b := make(typeBindings)
+	if t.Generic == nil {
+		return b
+	}
+	for i, tp := range t.Generic.TypeParams {
+		if i < len(t.TypeArgs) {
+			b[tp] = t.TypeArgs[i]
+		}
+	}
+	return b
+}
+
+// instantiateClass generic sınıfı verilen tip argümanlarıyla somutlaştırır
+func instantiateClass(generic *ClassType, args []Type) *ClassType {
+	return &ClassType{
+		Name:         generic.Name,
+		SuperClasses: generic.SuperClasses,
+		Interfaces:   generic.Interfaces,
+		Fields:       generic.Fields,
+		TypeArgs:     args,
+		Generic:      generic,
+	}
+}
+
+// sameTypeArgs iki tip argüman listesinin uyumlu olup olmadığını kontrol eder
+// Argümansız kullanım (ham sınıf adı) ve any her şeyle uyumludur
+func sameTypeArgs(a, b []Type) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] == AnyType || b[i] == AnyType {
+			continue
+		}
+		if !a[i].Equals(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// typeParamList tip parametrelerini [T, U] olarak yazar
+func typeParamList(params []*TypeParam) string {
+	names := make([]string, len(params))
+	for i, tp := range params {
+		names[i] = tp.Name
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+// unify parametre tipini argüman tipiyle eşleştirip tip parametrelerini bağlar
+// İlk bağlama kazanır; çelişkiler daha sonra argüman kontrolünde raporlanır
+func unify(param, arg Type, b typeBindings) {
+	switch p := param.(type) {
+	case *TypeParam:
+		if _, bound := b[p]; !bound {
+			b[p] = arg
+		}
+
+	case *ListType:
+		if a, ok := arg.(*ListType); ok {
+			unify(p.ElementType, a.ElementType, b)
+		}
+
+	case *DictType:
+		if a, ok := arg.(*DictType); ok {
+			unify(p.KeyType, a.KeyType, b)
+			unify(p.ValueType, a.ValueType, b)
+		}
+
+	case *PromiseType:
+		if a, ok := arg.(*PromiseType); ok {
+			unify(p.ValueType, a.ValueType, b)
+		}
+
+	case *FunctionType:
+		if a, ok := arg.(*FunctionType); ok && len(a.Params) == len(p.Params) {
+			for i := range p.Params {
+				unify(p.Params[i], a.Params[i], b)
+			}
+			unify(p.ReturnType, a.ReturnType, b)
+		}
+
+	case *ClassType:
+		if a, ok := arg.(*ClassType); ok && a.Name == p.Name && len(a.TypeArgs) == len(p.TypeArgs) {
+			for i := range p.TypeArgs {
+				unify(p.TypeArgs[i], a.TypeArgs[i], b)
+			}
+		}
+
+	case *UnionType:
+		// T? parametresine nil olmayan argüman verilirse T'ye bağlanır
+		if arg == NilType {
+			return
+		}
+		if _, isUnion := arg.(*UnionType); isUnion {
+			return
+		}
+		for _, member := range p.Types {
+			if member != NilType {
+				unify(member, arg, b)
+				return
+			}
+		}
+	}
+}
+
+// substitute tip içindeki bağlanmış tip parametrelerini somut tiplerle değiştirir
+func substitute(t Type, b typeBindings) Type {
+	if len(b) == 0 {
+		return t
+	}
+
+	switch tt := t.(type) {
+	case *TypeParam:
+		if bound, ok := b[tt]; ok {
+			return bound
+		}
+		return tt
+
+	case *ListType:
+		return &ListType{ElementType: substitute(tt.ElementType, b)}
+
+	case *DictType:
+		return &DictType{KeyType: substitute(tt.KeyType, b), ValueType: substitute(tt.ValueType, b)}
+
+	case *PromiseType:
+		return &PromiseType{ValueType: substitute(tt.ValueType, b)}
+
+	case *FunctionType:
+		params := make([]Type, len(tt.Params))
+		for i, p := range tt.Params {
+			params[i] = substitute(p, b)
+		}
+		var free []*TypeParam
+		for _, tp := range tt.TypeParams {
+			if _, bound := b[tp]; !bound {
+				free = append(free, tp)
+			}
+		}
+		return &FunctionType{
+			Params:     params,
+			ReturnType: substitute(tt.ReturnType, b),
+			Variadic:   tt.Variadic,
+			MinParams:  tt.MinParams,
+			TypeParams: free,
+		}
+
+	case *UnionType:
+		types := make([]Type, len(tt.Types))
+		for i, member := range tt.Types {
+			types[i] = substitute(member, b)
+		}
+		return &UnionType{Types: types}
+
+	case *ClassType:
+		if tt.Generic == nil {
+			return tt
+		}
+		args := make([]Type, len(tt.TypeArgs))
+		for i, arg := range tt.TypeArgs {
+			args[i] = substitute(arg, b)
+		}
+		return instantiateClass(tt.Generic, args)
+
+	default:
+		return t
+	}
+}
+
+// enterTypeParams generic tip parametrelerini tanımlar ve aktif kapsama ekler
+// Çağıran, eski kapsamı (c.typeParams) kendisi geri yüklemelidir
+func (c *Checker) enterTypeParams(params []*ast.TypeParameter) []*TypeParam {
+	if len(params) == 0 {
+		return nil
+	}
+
+	scope := make(map[string]*TypeParam, len(c.typeParams)+len(params))
+	for name, tp := range c.typeParams {
+		scope[name] = tp
+	}
+
+	result := make([]*TypeParam, len(params))
+	seen := make(map[string]bool, len(params))
+	for i, param := range params {
+		if seen[param.Name.Value] {
+			c.addError(&SemanticError{
+				Code:    diag.DuplicateTypeParameter,
+				Message: i18n.T("sema.duplicate_type_param", param.Name.Value),
+				Pos:     param.Token,
+			})
+		}
+		seen[param.Name.Value] = true
+		result[i] = &TypeParam{Name: param.Name.Value}
+		scope[param.Name.Value] = result[i]
+	}
+	c.typeParams = scope
+
+	// Sınırlar tüm parametreler tanımlandıktan sonra çözülür (T: Comparable[T])
+	for i, param := range params {
+		if param.Constraint != nil {
+			result[i].Constraint = c.resolveType(param.Constraint)
+		}
+	}
+
+	return result
+}
+
+// resolveGenericType Name[Args] anotasyonunu çözer
+func (c *Checker) resolveGenericType(t *ast.GenericType) Type {
+	args := make([]Type, len(t.TypeArgs))
+	for i, arg := range t.TypeArgs {
+		args[i] = c.resolveType(arg)
+	}
+
+	switch {
+	case (t.BaseName == "List" || t.BaseName == "list") && len(args) == 1:
+		return &ListType{ElementType: args[0]}
+	case (t.BaseName == "Dict" || t.BaseName == "dict") && len(args) == 2:
+		return &DictType{KeyType: args[0], ValueType: args[1]}
+	case t.BaseName == "Promise" && len(args) == 1:
+		return &PromiseType{ValueType: args[0]}
+	}
+
+	symbol, ok := c.symTable.Resolve(t.BaseName)
+	if !ok {
+		return AnyType
+	}
+	class, ok := symbol.Type.(*ClassType)
+	if !ok || len(class.TypeParams) == 0 {
+		return AnyType
+	}
+
+	if len(args) != len(class.TypeParams) {
+		c.addError(&SemanticError{
+			Code: diag.TypeArgumentCount,
+			Message: i18n.T("sema.type_arg_count",
+				class.Name, len(class.TypeParams), len(args)),
+			Pos: t.Token,
+		})
+		return AnyType
+	}
+
+	inst := instantiateClass(class, args)
+	c.checkBounds(class.TypeParams, inst.bindings(), inst.String(), t.Token)
+	return inst
+}
+
+// genericName ifade generic bir sınıf ya da fonksiyon adıysa adını döndürür
+func (c *Checker) genericName(expr ast.Expression) (string, bool) {
+	ident, ok := expr.(*ast.Identifier)
+	if !ok {
+		return "", false
+	}
+	symbol, ok := c.symTable.Resolve(ident.Value)
+	if !ok {
+		return "", false
+	}
+	switch t := symbol.Type.(type) {
+	case *ClassType:
+		return ident.Value, len(t.TypeParams) > 0
+	case *FunctionType:
+		return ident.Value, len(t.TypeParams) > 0
+	}
+	return "", false
+}
+
+// checkBounds bağlanan tiplerin tip parametresi sınırlarını karşıladığını kontrol eder
+func (c *Checker) checkBounds(params []*TypeParam, b typeBindings, context string, pos lexer.Token) {
+	for _, tp := range params {
+		if tp.Constraint == nil {
+			continue
+		}
+		bound, ok := b[tp]
+		if !ok || bound == AnyType {
+			continue
+		}
+		constraint := substitute(tp.Constraint, b)
+		if !bound.IsAssignableTo(constraint) {
+			c.addError(&SemanticError{
+				Code: diag.ConstraintNotSatisfied,
+				Message: i18n.T("sema.constraint",
+					bound.String(), constraint.String(), tp.Name, context),
+				Pos: pos,
+			})
+		}
+	}
+}
+
+// inferTypeArgs argüman tiplerinden tip parametrelerini çıkarır
+// Çıkarılamayan parametreler any kabul edilir
+func inferTypeArgs(typeParams []*TypeParam, params, argTypes []Type) typeBindings {
+	b := make(typeBindings)
+	for i, argType := range argTypes {
+		if i < len(params) {
+			unify(params[i], argType, b)
+		}
+	}
+	for _, tp := range typeParams {
+		if _, ok := b[tp]; !ok {
+			b[tp] = AnyType
+		}
+	}
+	return b
+}
+
+// instantiateCall generic fonksiyon çağrısını somutlaştırır
+func (c *Checker) instantiateCall(ft *FunctionType, argTypes []Type, name string, pos lexer.Token) (*FunctionType, string) {
+	b := inferTypeArgs(ft.TypeParams, ft.Params, argTypes)
+	args := make([]Type, len(ft.TypeParams))
+	for i, tp := range ft.TypeParams {
+		args[i] = b[tp]
+	}
+	context := fmt.Sprintf("%s[%s]", name, joinTypes(args))
+	c.checkBounds(ft.TypeParams, b, context, pos)
+	return substitute(ft, b).(*FunctionType), context
+}
+
+// instantiateConstructor generic sınıfın constructor çağrısından tip argümanlarını çıkarır
+func (c *Checker) instantiateConstructor(class *ClassType, argTypes []Type, pos lexer.Token) *ClassType {
+	var params []Type
+	if init, ok := class.LookupMethod("init"); ok {
+		params = init.Params
+	}
+	b := inferTypeArgs(class.TypeParams, params, argTypes)
+	args := make([]Type, len(class.TypeParams))
+	for i, tp := range class.TypeParams {
+		args[i] = b[tp]
+	}
+	inst := instantiateClass(class, args)
+	c.checkBounds(class.TypeParams, b, inst.String(), pos)
+
+	// Somut constructor imzasına göre argümanları kontrol et
+	for i, argType := range argTypes {
+		if i < len(params) {
+			expected := substitute(params[i], b)
+			if !argType.IsAssignableTo(expected) {
+				c.addError(&SemanticError{
+					Code:    diag.ArgumentTypeMismatch,
+					Message: i18n.T("sema.in_constructor", argumentMismatch(i+1, expected, argType), inst.String()),
+					Pos:     pos,
+				})
+			}
+		}
+	}
+
+	return inst
+}
+
+func joinTypes(types []Type) string {
+	strs := make([]string, len(types))
+	for i, t := range types {
+		strs[i] = t.String()
+	}
+	return strings.Join(strs, ", ")
+}
+
+// calleeName çağrılan ifadenin hata mesajlarında kullanılacak adını döndürür
+func calleeName(expr ast.Expression) string {
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		return e.Value
+	case *ast.MemberExpression:
+		return e.Member.Value
+	default:
+		return "function"
+	}
+}
@@ -0,0 +1,294 @@
+package sema
+
+import (
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+)
+
+// FunctionInfo bir fonksiyonun kontrol sonrası bilinen imzasını tutar
+type FunctionInfo struct {
+	Name string
+	Node *ast.FunctionStatement
+	Type *FunctionType
+	// ReturnInferred return tipi anotasyondan değil body'den çıkarıldı
+	ReturnInferred bool
+}
+
+// Signature imzayı parametre isimleriyle yazar: function add(a: int, b: int): int
+func (f FunctionInfo) Signature() string {
+	var sb strings.Builder
+	if f.Node != nil && f.Node.Async {
+		sb.WriteString("async ")
+	}
+	sb.WriteString("function ")
+	sb.WriteString(f.Name)
+	if len(f.Type.TypeParams) > 0 {
+		sb.WriteString(typeParamList(f.Type.TypeParams))
+	}
+	sb.WriteString("(")
+	for i, t := range f.Type.Params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if f.Node != nil && i < len(f.Node.Parameters) {
+			param := f.Node.Parameters[i]
+			if param.Variadic {
+				sb.WriteString("...")
+			}
+			sb.WriteString(param.Name.Value)
+			sb.WriteString(": ")
+		}
+		sb.WriteString(t.String())
+	}
+	sb.WriteString("): ")
+	sb.WriteString(f.Type.ReturnType.String())
+	return sb.String()
+}
+
+// Functions kontrol edilen fonksiyonların imzalarını tanım sırasıyla döndürür
+func (c *Checker) Functions() []FunctionInfo {
+	return c.functions
+}
+
+// inferReturnType return ifadelerinin tiplerini tek bir tipte birleştirir
+// Hiç değer döndürmeyen fonksiyon void'dir; sonuna düşebilen ya da boş
+// return içeren fonksiyon nil de döndürebilir
+func inferReturnType(returns []Type, body *ast.BlockStatement) Type {
+	hasValue := false
+	for _, t := range returns {
+		if t != VoidType {
+			hasValue = true
+			break
+		}
+	}
+	if !hasValue {
+		return VoidType
+	}
+
+	types := make([]Type, 0, len(returns)+1)
+	for _, t := range returns {
+		if t == VoidType {
+			t = NilType
+		}
+		types = append(types, t)
+	}
+	if !alwaysReturns(body) {
+		types = append(types, NilType)
+	}
+	return unionOf(types)
+}
+
+// inferRecursive body'yi kontrol eder ve return tipini çıkarır. Kendini
+// çağıran fonksiyonda ilk geçişte özyinelemeli çağrılar any döndürür ve
+// birleşim any olur; bu durumda any olmayan return'lerden (özyinelemesiz
+// yollar) bir tip çıkıyorsa body o tiple ikinci kez kontrol edilir ve ilk
+// geçişin bulguları atılır. fact(n) gibi fonksiyonlar böylece int olur.
+func (c *Checker) inferRecursive(funcType *FunctionType, stmt *ast.FunctionStatement, checkBody func()) {
+	errors, warnings, functions := len(c.errors), len(c.warnings), len(c.functions)
+	typeInfo, classes, pending := len(c.typeInfo), len(c.classes), len(c.pendingMembers)
+	checkBody()
+	inferred := inferReturnType(c.returns, stmt.Body)
+
+	if c.recursed && inferred == AnyType {
+		var seeds []Type
+		for _, t := range c.returns {
+			if t != AnyType {
+				seeds = append(seeds, t)
+			}
+		}
+		if seed := inferReturnType(seeds, stmt.Body); len(seeds) > 0 && seed != VoidType {
+			if stmt.Async {
+				seed = promiseOf(seed)
+			}
+			funcType.ReturnType = seed
+			c.errors, c.warnings, c.functions = c.errors[:errors], c.warnings[:warnings], c.functions[:functions]
+			c.typeInfo, c.classes, c.pendingMembers = c.typeInfo[:typeInfo], c.classes[:classes], c.pendingMembers[:pending]
+			c.returns = nil
+			checkBody()
+			inferred = inferReturnType(c.returns, stmt.Body)
+		}
+	}
+	if stmt.Async {
+		inferred = promiseOf(inferred)
+	}
+	funcType.ReturnType = inferred
+}
+
+// unionOf tipleri tekrarsız bir union'da birleştirir; int ve float float olur
+func unionOf(types []Type) Type {
+	var members []Type
+	add := func(t Type) {
+		for _, m := range members {
+			if m.Equals(t) {
+				return
+			}
+		}
+		members = append(members, t)
+	}
+	for _, t := range types {
+		if t == AnyType {
+			return AnyType
+		}
+		if u, ok := t.(*UnionType); ok {
+			for _, m := range u.Types {
+				add(m)
+			}
+			continue
+		}
+		add(t)
+	}
+
+	hasFloat := false
+	for _, m := range members {
+		if m == FloatType {
+			hasFloat = true
+		}
+	}
+	if hasFloat {
+		kept := members[:0]
+		for _, m := range members {
+			if m != IntType {
+				kept = append(kept, m)
+			}
+		}
+		members = kept
+	}
+
+	if len(members) == 1 {
+		return members[0]
+	}
+	return &UnionType{Types: members}
+}
+
+// alwaysReturns bloğun her yolda return ya da throw ile bittiğini bildirir
+func alwaysReturns(block *ast.BlockStatement) bool {
+	if block == nil || len(block.Statements) == 0 {
+		return false
+	}
+	switch s := block.Statements[len(block.Statements)-1].(type) {
+	case *ast.ReturnStatement, *ast.ThrowStatement:
+		return true
+	case *ast.IfStatement:
+		if s.Alternative == nil || !alwaysReturns(s.Consequence) || !alwaysReturns(s.Alternative) {
+			return false
+		}
+		for _, elif := range s.Elif {
+			if !alwaysReturns(elif.Consequence) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (c *Checker) checkLambdaExpression(expr *ast.LambdaExpression, expected *FunctionType) Type {
+	// Anotasyonsuz parametreler çağrı bağlamından tip alır
+	paramTypes := make([]Type, len(expr.Parameters))
+	minParams := 0
+	hasVarargs := false
+	for i, param := range expr.Parameters {
+		switch {
+		case param.Type != nil:
+			paramTypes[i] = c.resolveType(param.Type)
+		case expected != nil && i < len(expected.Params):
+			paramTypes[i] = expected.Params[i]
+		default:
+			paramTypes[i] = AnyType
+		}
+		if param.Variadic {
+			hasVarargs = true
+		} else if param.DefaultValue == nil && !hasVarargs {
+			minParams = i + 1
+		}
+	}
+
+	inferReturn := expr.ReturnType == nil
+	var returnType Type = AnyType
+	if !inferReturn {
+		returnType = c.resolveType(expr.ReturnType)
+	}
+	funcType := &FunctionType{
+		Params:     paramTypes,
+		ReturnType: returnType,
+		MinParams:  minParams,
+		Variadic:   hasVarargs,
+	}
+
+	c.symTable.EnterScope()
+	for i, param := range expr.Parameters {
+		paramSymbol := &Symbol{
+			Name:    param.Name.Value,
+			Kind:    ParameterSymbol,
+			Type:    paramTypes[i],
+			Pos:     param.Token,
+			Mutable: true,
+		}
+		if err := c.symTable.Define(paramSymbol); err != nil {
+			c.addError(err)
+		}
+		c.recordType(param.Name.Value, param.Name.Token, paramTypes[i])
+	}
+
+	oldFunction := c.currentFunction
+	oldReturns, oldInfer := c.returns, c.inferReturn
+	c.currentFunction = &Symbol{Name: "lambda", Kind: FunctionSymbol, Type: funcType, Pos: expr.Token}
+	c.returns, c.inferReturn = nil, inferReturn
+
+	c.checkBlockStatement(expr.Body)
+	if inferReturn {
+		funcType.ReturnType = inferReturnType(c.returns, expr.Body)
+	}
+
+	c.currentFunction = oldFunction
+	c.returns, c.inferReturn = oldReturns, oldInfer
+	c.symTable.ExitScope()
+	return funcType
+}
+
+// callbackType i. argüman olarak verilen fonksiyonun beklenen tipini döndürür
+// map ve filter fonksiyonu listenin elemanlarıyla çağırır
+func (c *Checker) callbackType(call *ast.CallExpression, callee *FunctionType, i int, argTypes []Type) *FunctionType {
+	if name, ok := call.Function.(*ast.Identifier); ok && c.isBuiltin(name.Value) &&
+		(name.Value == "map" || name.Value == "filter") && i == 0 && len(argTypes) > 1 {
+		if list, ok := argTypes[1].(*ListType); ok {
+			return &FunctionType{Params: []Type{list.ElementType}, ReturnType: AnyType}
+		}
+		return nil
+	}
+	if callee == nil || len(callee.TypeParams) > 0 || i >= len(callee.Params) {
+		return nil
+	}
+	expected, _ := callee.Params[i].(*FunctionType)
+	return expected
+}
+
+// callbackResult map ve filter çağrılarının liste tipini verilen fonksiyondan çıkarır
+func (c *Checker) callbackResult(call *ast.CallExpression, argTypes []Type) Type {
+	name, ok := call.Function.(*ast.Identifier)
+	if !ok || !c.isBuiltin(name.Value) || len(argTypes) != 2 {
+		return nil
+	}
+	fn, ok := argTypes[0].(*FunctionType)
+	if !ok {
+		return nil
+	}
+	switch name.Value {
+	case "map":
+		if fn.ReturnType != AnyType && fn.ReturnType != VoidType {
+			return &ListType{ElementType: fn.ReturnType}
+		}
+	case "filter":
+		if list, ok := argTypes[1].(*ListType); ok {
+			return list
+		}
+	}
+	return nil
+}
+
+// isBuiltin ismin gölgelenmemiş bir builtin'e çözüldüğünü bildirir
+func (c *Checker) isBuiltin(name string) bool {
+	symbol, ok := c.symTable.Resolve(name)
+	return ok && symbol.Scope == c.symTable.GlobalScope() && symbol.Node == nil && symbol.Pos.Line == 0
+}
@@ -0,0 +1,197 @@
+package sema
+
+import (
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+)
+
+// checkInterfaceStatement interface tanımını kontrol eder ve sembol tablosuna ekler
+func (c *Checker) checkInterfaceStatement(stmt *ast.InterfaceStatement) {
+	ifaceType := &InterfaceType{
+		Name:    stmt.Name.Value,
+		Methods: make(map[string]*FunctionType),
+	}
+
+	// Parent interface'leri çöz
+	for _, parentIdent := range stmt.Extends {
+		parentSymbol, ok := c.symTable.Resolve(parentIdent.Value)
+		if !ok {
+			c.addError(&SemanticError{
+				Code:    diag.InvalidInterface,
+				Message: i18n.T("sema.undefined_interface", parentIdent.Value),
+				Pos:     parentIdent.Token,
+			})
+			continue
+		}
+		parent, ok := parentSymbol.Type.(*InterfaceType)
+		if !ok {
+			c.addError(&SemanticError{
+				Code:    diag.InvalidInterface,
+				Message: i18n.T("sema.not_interface", parentIdent.Value),
+				Pos:     parentIdent.Token,
+			})
+			continue
+		}
+		ifaceType.Extends = append(ifaceType.Extends, parent)
+	}
+
+	// Metod imzalarını topla
+	for _, method := range stmt.Methods {
+		if _, exists := ifaceType.Methods[method.Name.Value]; exists {
+			c.addError(&SemanticError{
+				Code:    diag.DuplicateInterfaceMethod,
+				Message: i18n.T("sema.duplicate_interface_method", method.Name.Value, stmt.Name.Value),
+				Pos:     method.Token,
+			})
+			continue
+		}
+		ifaceType.Methods[method.Name.Value] = c.signatureOf(method.Parameters, method.ReturnType)
+	}
+
+	ifaceSymbol := &Symbol{
+		Name: stmt.Name.Value,
+		Kind: InterfaceSymbol,
+		Type: ifaceType,
+		Pos:  stmt.Token,
+		Node: stmt,
+	}
+
+	if err := c.symTable.Define(ifaceSymbol); err != nil {
+		c.addError(err)
+	}
+}
+
+// checkDeclaredInterfaces "implements" ile bildirilen interface'lerin karşılandığını kontrol eder
+func (c *Checker) checkDeclaredInterfaces(stmt *ast.ClassStatement, classType *ClassType) {
+	for _, ifaceIdent := range stmt.Interfaces {
+		ifaceSymbol, ok := c.symTable.Resolve(ifaceIdent.Value)
+		if !ok {
+			c.addError(&SemanticError{
+				Code:    diag.InvalidInterface,
+				Message: i18n.T("sema.undefined_interface", ifaceIdent.Value),
+				Pos:     ifaceIdent.Token,
+			})
+			continue
+		}
+		iface, ok := ifaceSymbol.Type.(*InterfaceType)
+		if !ok {
+			c.addError(&SemanticError{
+				Code:    diag.InvalidInterface,
+				Message: i18n.T("sema.not_interface", ifaceIdent.Value),
+				Pos:     ifaceIdent.Token,
+			})
+			continue
+		}
+
+		classType.Interfaces = append(classType.Interfaces, iface)
+
+		if problems := iface.MissingMethods(classType); len(problems) > 0 {
+			c.addError(&SemanticError{
+				Code: diag.InterfaceNotImplemented,
+				Message: i18n.T("sema.not_implemented",
+					classType.Name, iface.Name, strings.Join(problems, ", ")),
+				Pos: ifaceIdent.Token,
+			})
+		}
+	}
+}
+
+// collectMethods class body'sindeki metodların imzalarını ClassType'a ekler
+func (c *Checker) collectMethods(body []ast.Statement, classType *ClassType) {
+	for _, member := range body {
+		if fn, ok := member.(*ast.FunctionStatement); ok {
+			method := c.signatureOf(fn.Parameters, fn.ReturnType)
+			if fn.Async {
+				method.ReturnType = promiseOf(method.ReturnType)
+			}
+			classType.Methods[fn.Name.Value] = method
+		}
+	}
+}
+
+// signatureOf metod imzasını oluşturur
+// Anotasyonu olmayan parametre ve dönüş tipleri any kabul edilir; açık self parametresi atlanır
+func (c *Checker) signatureOf(params []*ast.FunctionParameter, returnType ast.TypeAnnotation) *FunctionType {
+	if len(params) > 0 && params[0].Name.Value == "self" {
+		params = params[1:]
+	}
+	paramTypes := make([]Type, len(params))
+	minParams := 0
+	variadic := false
+	for i, param := range params {
+		paramTypes[i] = c.resolveType(param.Type)
+		if param.Variadic {
+			variadic = true
+		} else if param.DefaultValue == nil && !variadic {
+			minParams = i + 1
+		}
+	}
+	return &FunctionType{
+		Params:     paramTypes,
+		ReturnType: c.resolveType(returnType),
+		MinParams:  minParams,
+		Variadic:   variadic,
+	}
+}
+
+// resolveType tip anotasyonunu çözer; kullanıcı tanımlı sınıf ve interface isimlerini de tanır
+func (c *Checker) resolveType(typeAnnot ast.TypeAnnotation) Type {
+	switch t := typeAnnot.(type) {
+	case *ast.BasicType:
+		if tp, ok := c.typeParams[t.Name]; ok {
+			return tp
+		}
+		if symbol, ok := c.symTable.Resolve(t.Name); ok {
+			switch symbol.Type.(type) {
+			case *ClassType, *InterfaceType, *EnumType:
+				return symbol.Type
+			}
+		}
+		return ResolveType(t)
+
+	case *ast.ListType:
+		return &ListType{ElementType: c.resolveType(t.ElementType)}
+
+	case *ast.DictType:
+		return &DictType{KeyType: c.resolveType(t.KeyType), ValueType: c.resolveType(t.ValueType)}
+
+	case *ast.OptionalType:
+		return &UnionType{Types: []Type{c.resolveType(t.BaseType), NilType}}
+
+	case *ast.UnionType:
+		types := make([]Type, len(t.Types))
+		for i, tt := range t.Types {
+			types[i] = c.resolveType(tt)
+		}
+		return &UnionType{Types: types}
+
+	case *ast.FunctionType:
+		params := make([]Type, len(t.ParamTypes))
+		for i, p := range t.ParamTypes {
+			params[i] = c.resolveType(p)
+		}
+		return &FunctionType{Params: params, ReturnType: c.resolveType(t.ReturnType)}
+
+	case *ast.GenericType:
+		return c.resolveGenericType(t)
+
+	default:
+		return ResolveType(typeAnnot)
+	}
+}
+
+// argumentMismatch argüman tip uyuşmazlığı için hata mesajı üretir
+// Interface parametrelerinde eksik metodlar mesaja eklenir
+func argumentMismatch(index int, expected, got Type) string {
+	if iface, ok := expected.(*InterfaceType); ok {
+		if class, ok := got.(*ClassType); ok {
+			return i18n.T("sema.arg_not_satisfy",
+				index, class.Name, iface.Name, strings.Join(iface.MissingMethods(class), ", "))
+		}
+	}
+	return i18n.T("sema.arg_mismatch",
+		index, expected.String(), got.String())
+}
@@ -0,0 +1,368 @@
+package sema
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+)
+
+// maxMissingPatterns hata mesajında listelenecek en fazla eksik pattern sayısı
+const maxMissingPatterns = 5
+
+// checkMatchExpression match ifadesini kontrol eder
+// nil kolu, sonraki kollarda subject'i nil olmayan tipe daraltır.
+// Guard'lı kollar kapsamlılığa katkı sağlamaz; guard kolun gövdesini daraltır.
+func (c *Checker) checkMatchExpression(expr *ast.MatchExpression) Type {
+	subjectType := c.checkExpression(expr.Value)
+
+	var subject *Symbol
+	if ident, ok := expr.Value.(*ast.Identifier); ok {
+		subject, _ = c.symTable.Resolve(ident.Value)
+	}
+
+	var rows []patternRow
+	remaining := subjectType
+	for i, arm := range expr.Arms {
+		row := patternRow{c.patternSpace(arm.Pattern)}
+		if !c.useful(rows, row, []Type{subjectType}) {
+			c.reportUnreachableArm(expr.Arms[:i], arm)
+		}
+		if arm.Guard == nil {
+			rows = append(rows, row)
+		}
+
+		facts := narrowFrame{}
+		armType := remaining
+		if isNilExpression(arm.Pattern) {
+			armType = NilType
+		}
+		if subject != nil && armType != subjectType {
+			facts[subject] = armType
+		}
+
+		c.symTable.EnterScope()
+		c.bindPattern(arm.Pattern, armType)
+		c.pushNarrowing(facts)
+		if arm.Guard != nil {
+			c.pushNarrowing(c.checkGuard(arm.Guard))
+		}
+		c.checkBlockStatement(arm.Body)
+		if arm.Guard != nil {
+			c.popNarrowing()
+		}
+		c.popNarrowing()
+		c.symTable.ExitScope()
+
+		if isNilExpression(arm.Pattern) && arm.Guard == nil {
+			remaining = removeNil(remaining)
+		}
+	}
+
+	c.checkExhaustive(expr, rows, subjectType)
+
+	return AnyType
+}
+
+// checkExhaustive enum, bool ve optional subject'lerde eksik pattern'leri raporlar
+func (c *Checker) checkExhaustive(expr *ast.MatchExpression, rows []patternRow, subjectType Type) {
+	resolved, _, finite := c.signature(subjectType, columnHeads(rows, isOptional(subjectType)))
+	if !finite || !c.useful(rows, patternRow{wildcard}, []Type{subjectType}) {
+		return
+	}
+
+	var missing []string
+	for _, w := range c.missingPatterns(rows, []Type{subjectType}) {
+		missing = append(missing, w[0])
+	}
+	if len(missing) > maxMissingPatterns {
+		missing = append(missing[:maxMissingPatterns], "...")
+	}
+
+	c.addError(&SemanticError{
+		Code: diag.NonExhaustiveMatch,
+		Message: i18n.T("sema.non_exhaustive",
+			resolved.String(), strings.Join(missing, ", ")),
+		Pos: expr.Token,
+	})
+}
+
+// reportUnreachableArm önceki kollarca tamamen kapsanan kolu raporlar
+func (c *Checker) reportUnreachableArm(previous []*ast.MatchArm, arm *ast.MatchArm) {
+	pattern := arm.Pattern.String()
+	for _, prev := range previous {
+		if prev.Guard == nil && prev.Pattern.String() == pattern {
+			c.addError(&SemanticError{
+				Code:    diag.DuplicateArm,
+				Message: i18n.T("sema.duplicate_arm", pattern),
+				Pos:     arm.Pattern.Pos(),
+			})
+			return
+		}
+	}
+	c.addError(&SemanticError{
+		Code:    diag.UnreachableArm,
+		Message: i18n.T("sema.unreachable_arm", pattern),
+		Pos:     arm.Pattern.Pos(),
+	})
+}
+
+// checkGuard kol guard'ını kontrol eder ve doğru olduğunda geçerli daraltmaları döndürür
+func (c *Checker) checkGuard(guard ast.Expression) narrowFrame {
+	guardType := c.checkExpression(guard)
+	if guardType != BoolType && guardType != AnyType {
+		c.addError(&SemanticError{
+			Code:    diag.NonBoolCondition,
+			Message: i18n.T("sema.guard_condition", guardType.String()),
+			Pos:     guard.Pos(),
+		})
+	}
+	whenTrue, _ := c.conditionFacts(guard)
+	return whenTrue
+}
+
+// bindPattern pattern içindeki değişkenleri arm scope'una tanımlar
+func (c *Checker) bindPattern(pattern ast.Expression, t Type) {
+	switch p := pattern.(type) {
+	case *ast.Identifier:
+		if p.Value == "_" || isNilExpression(p) {
+			return
+		}
+		if enum, ok := c.variantEnum(p.Value); ok {
+			// Çıplak variant adı değişken bağlamaz, variant'ı eşler
+			c.checkVariantSubject(p.Value, enum, t, p.Token)
+			return
+		}
+		c.definePatternVariable(p, t)
+
+	case *ast.CallExpression:
+		if class, ok := c.patternClass(p.Function); ok {
+			// Alansız class pattern: Point()
+			if len(p.Arguments) > 0 {
+				c.addError(&SemanticError{
+					Code: diag.InvalidPattern,
+					Message: i18n.T("sema.class_pattern_fields",
+						class.Name, class.Name),
+					Pos: p.Token,
+				})
+			}
+			return
+		}
+		// Enum variant pattern: Variant(a, b)
+		c.bindVariantPattern(p, t)
+
+	case *ast.ClassPattern:
+		class, ok := c.patternClass(p.Class)
+		if !ok {
+			c.addError(&SemanticError{
+				Code:    diag.InvalidPattern,
+				Message: i18n.T("sema.pattern_not_class", p.Class.Value),
+				Pos:     p.Token,
+			})
+		}
+		for _, field := range p.Fields {
+			var fieldType Type = AnyType
+			if class != nil {
+				if ft, ok := class.LookupField(field.Name.Value); ok {
+					fieldType = ft
+				}
+			}
+			c.bindPattern(field.Pattern, fieldType)
+		}
+
+	case *ast.DictPattern:
+		var valueType Type = AnyType
+		if dt, ok := removeNil(t).(*DictType); ok {
+			valueType = dt.ValueType
+		}
+		for _, value := range p.Values {
+			c.bindPattern(value, valueType)
+		}
+
+	case *ast.RestPattern:
+		if p.Name == nil || p.Name.Value == "_" {
+			return
+		}
+		listType := removeNil(t)
+		if _, ok := listType.(*ListType); !ok {
+			listType = &ListType{ElementType: AnyType}
+		}
+		c.definePatternVariable(p.Name, listType)
+
+	case *ast.AsPattern:
+		c.bindPattern(p.Pattern, t)
+		c.definePatternVariable(p.Name, t)
+
+	case *ast.OrPattern:
+		c.bindOrPattern(p, t)
+
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.BooleanLiteral:
+		literalType := c.checkExpression(p)
+		if !c.isAssignable(literalType, t) && !c.isAssignable(literalType, removeNil(t)) {
+			c.addError(&SemanticError{
+				Code: diag.PatternTypeMismatch,
+				Message: i18n.T("sema.pattern_mismatch",
+					literalType.String(), t.String()),
+				Pos: p.Pos(),
+			})
+		}
+
+	case *ast.ListLiteral:
+		var elemType Type = AnyType
+		if lt, ok := removeNil(t).(*ListType); ok {
+			elemType = lt.ElementType
+		}
+		for _, elem := range p.Elements {
+			if _, ok := elem.(*ast.RestPattern); ok {
+				c.bindPattern(elem, t)
+				continue
+			}
+			c.bindPattern(elem, elemType)
+		}
+	}
+}
+
+// checkVariantSubject variant'ın subject'in enum tipine ait olduğunu kontrol eder
+func (c *Checker) checkVariantSubject(name string, enum *EnumType, t Type, pos lexer.Token) {
+	if expected, ok := removeNil(t).(*EnumType); ok && !expected.Equals(enum) {
+		c.addError(&SemanticError{
+			Code: diag.PatternTypeMismatch,
+			Message: i18n.T("sema.variant_mismatch",
+				name, enum.Name, expected.Name),
+			Pos: pos,
+		})
+	}
+}
+
+// definePatternVariable pattern değişkenini arm scope'una tanımlar
+func (c *Checker) definePatternVariable(ident *ast.Identifier, t Type) {
+	symbol := &Symbol{
+		Name:    ident.Value,
+		Kind:    VariableSymbol,
+		Type:    t,
+		Pos:     ident.Token,
+		Mutable: false,
+		Node:    ident,
+	}
+	if err := c.symTable.Define(symbol); err != nil {
+		c.addError(err)
+		return
+	}
+	c.recordType(ident.Value, ident.Token, t)
+}
+
+// bindOrPattern alternatiflerin aynı değişkenleri bağladığını kontrol eder
+// Değişkenler ilk alternatiften tanımlanır; diğerleri geçici scope'ta kontrol edilir
+func (c *Checker) bindOrPattern(p *ast.OrPattern, t Type) {
+	names := c.patternVariables(p.Alternatives[0])
+	for _, alt := range p.Alternatives[1:] {
+		if altNames := c.patternVariables(alt); strings.Join(altNames, ",") != strings.Join(names, ",") {
+			c.addError(&SemanticError{
+				Code: diag.OrPatternBindings,
+				Message: i18n.T("sema.or_pattern_bindings",
+					p.Alternatives[0].String(), strings.Join(names, ", "), alt.String(), strings.Join(altNames, ", ")),
+				Pos: alt.Pos(),
+			})
+		}
+		c.symTable.EnterScope()
+		c.bindPattern(alt, t)
+		c.symTable.ExitScope()
+	}
+	c.bindPattern(p.Alternatives[0], t)
+}
+
+// patternVariables pattern'in bağladığı değişken isimlerini sıralı döndürür
+func (c *Checker) patternVariables(pattern ast.Expression) []string {
+	var names []string
+	var walk func(ast.Expression)
+	walk = func(expr ast.Expression) {
+		switch p := expr.(type) {
+		case *ast.Identifier:
+			if _, isVariant := c.variantEnum(p.Value); !isVariant && p.Value != "_" && !isNilExpression(p) {
+				names = append(names, p.Value)
+			}
+		case *ast.CallExpression:
+			for _, arg := range p.Arguments {
+				walk(arg)
+			}
+		case *ast.ListLiteral:
+			for _, elem := range p.Elements {
+				walk(elem)
+			}
+		case *ast.RestPattern:
+			if p.Name != nil && p.Name.Value != "_" {
+				names = append(names, p.Name.Value)
+			}
+		case *ast.DictPattern:
+			for _, value := range p.Values {
+				walk(value)
+			}
+		case *ast.ClassPattern:
+			for _, field := range p.Fields {
+				walk(field.Pattern)
+			}
+		case *ast.AsPattern:
+			walk(p.Pattern)
+			names = append(names, p.Name.Value)
+		case *ast.OrPattern:
+			walk(p.Alternatives[0])
+		}
+	}
+	walk(pattern)
+	sort.Strings(names)
+	return names
+}
+
+// patternClass pattern'deki ismin bir sınıfa ait olup olmadığını kontrol eder
+func (c *Checker) patternClass(name ast.Expression) (*ClassType, bool) {
+	ident, ok := name.(*ast.Identifier)
+	if !ok {
+		return nil, false
+	}
+	symbol, ok := c.symTable.Resolve(ident.Value)
+	if !ok {
+		return nil, false
+	}
+	class, ok := symbol.Type.(*ClassType)
+	return class, ok
+}
+
+// bindVariantPattern variant pattern'ini subject tipine ve payload tiplerine göre kontrol eder
+func (c *Checker) bindVariantPattern(p *ast.CallExpression, t Type) {
+	name := calleeName(p.Function)
+	enum, ok := c.variantEnum(name)
+	if !ok {
+		c.addError(&SemanticError{
+			Code:    diag.InvalidPattern,
+			Message: i18n.T("sema.pattern_not_variant", name),
+			Pos:     p.Token,
+		})
+		for _, arg := range p.Arguments {
+			c.bindPattern(arg, AnyType)
+		}
+		return
+	}
+
+	c.checkVariantSubject(name, enum, t, p.Token)
+
+	variant, _ := enum.Variant(name)
+	if len(p.Arguments) != len(variant.Payload) {
+		c.addError(&SemanticError{
+			Code: diag.InvalidPattern,
+			Message: i18n.T("sema.payload_count",
+				name, len(variant.Payload), len(p.Arguments)),
+			Pos: p.Token,
+		})
+	}
+
+	for i, arg := range p.Arguments {
+		var payloadType Type = AnyType
+		if i < len(variant.Payload) {
+			payloadType = variant.Payload[i]
+		}
+		c.bindPattern(arg, payloadType)
+	}
+}
@@ -0,0 +1,258 @@
+package sema
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+	"github.com/mburakmmm/sky-lang/internal/parser"
+)
+
+// ModuleType import edilen bir modülün namespace tipini temsil eder
+// Members modülün public (_ ile başlamayan) sembollerini içerir
+type ModuleType struct {
+	Path    string // import yolu, örn. lib/util
+	Members map[string]*Symbol
+}
+
+func (t *ModuleType) String() string {
+	return "module " + strings.ReplaceAll(t.Path, "/", ".")
+}
+
+func (t *ModuleType) Equals(other Type) bool {
+	if o, ok := other.(*ModuleType); ok {
+		return t.Path == o.Path
+	}
+	return false
+}
+
+func (t *ModuleType) IsAssignableTo(target Type) bool {
+	return target == AnyType || t.Equals(target)
+}
+
+// Member modülün public bir sembolünü arar
+func (t *ModuleType) Member(name string) (*Symbol, bool) {
+	sym, ok := t.Members[name]
+	return sym, ok
+}
+
+// MemberNames public sembol isimlerini sıralı döndürür
+func (t *ModuleType) MemberNames() []string {
+	names := make([]string, 0, len(t.Members))
+	for name := range t.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// moduleLoader bir programın import ettiği modülleri bir kez yükleyip kontrol eder
+// Ana checker ve modül checker'ları aynı loader'ı paylaşır
+type moduleLoader struct {
+	sourceFile string
+	currentDir string
+	modules    map[string]*ModuleType // dosya yolu -> modül
+	loading    []loadingModule        // şu an kontrol edilen modül zinciri
+}
+
+type loadingModule struct {
+	file string
+	path string
+}
+
+func newModuleLoader(sourceFile string) *moduleLoader {
+	currentDir, _ := os.Getwd()
+	l := &moduleLoader{
+		sourceFile: sourceFile,
+		currentDir: currentDir,
+		modules:    make(map[string]*ModuleType),
+	}
+	if sourceFile != "" {
+		l.loading = append(l.loading, loadingModule{file: absPath(sourceFile), path: sourceFile})
+	}
+	return l
+}
+
+// SetSourceFile kontrol edilen programın dosyasını belirtir
+// Importlar interpreter'daki gibi bu dosyaya ve çalışma dizinine göre çözülür
+func (c *Checker) SetSourceFile(filename string) {
+	c.modules = newModuleLoader(filename)
+}
+
+func (c *Checker) checkImportStatement(stmt *ast.ImportStatement) {
+	if c.modules == nil {
+		c.modules = newModuleLoader("")
+	}
+	modulePath := strings.Join(stmt.Path, "/")
+
+	var moduleType Type = AnyType
+	if mt := c.loadModule(modulePath, stmt.Token); mt != nil {
+		moduleType = mt
+	}
+
+	// import a.b -> b, import a.b as x -> x
+	name := stmt.Path[len(stmt.Path)-1]
+	pos := stmt.Token
+	if stmt.Alias != nil {
+		name = stmt.Alias.Value
+		pos = stmt.Alias.Token
+	}
+
+	// Aynı modülü tekrar import etmek interpreter'da namespace'i yeniler
+	if existing, ok := c.symTable.CurrentScope().ResolveLocal(name); ok && existing.Kind == ModuleSymbol {
+		existing.Type = moduleType
+		return
+	}
+	symbol := &Symbol{
+		Name: name,
+		Kind: ModuleSymbol,
+		Type: moduleType,
+		Pos:  pos,
+		Node: stmt,
+	}
+	if err := c.symTable.Define(symbol); err != nil {
+		c.addError(err)
+	}
+	c.recordType(name, pos, moduleType)
+}
+
+// loadModule modülü çözer, ilk kullanımda kontrol eder ve tipini döndürür
+// Modül bulunamaz, parse edilemez veya bir import döngüsüne girerse
+// import konumunda hata raporlanır ve nil döner
+func (c *Checker) loadModule(modulePath string, pos lexer.Token) *ModuleType {
+	l := c.modules
+	file := ResolveModulePath(modulePath, l.sourceFile, l.currentDir)
+	key := absPath(file)
+
+	if mt, ok := l.modules[key]; ok {
+		return mt
+	}
+
+	for i, m := range l.loading {
+		if m.file != key {
+			continue
+		}
+		chain := make([]string, 0, len(l.loading)-i+1)
+		for _, m := range l.loading[i:] {
+			chain = append(chain, m.path)
+		}
+		chain = append(chain, modulePath)
+		c.addError(&SemanticError{
+			Code:    diag.ImportCycle,
+			Message: i18n.T("sema.import_cycle", strings.Join(chain, " -> ")),
+			Pos:     pos,
+		})
+		return nil
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		c.addError(&SemanticError{
+			Code:    diag.ModuleNotLoaded,
+			Message: i18n.T("sema.cannot_load_module", modulePath, err),
+			Pos:     pos,
+		})
+		return nil
+	}
+	p := parser.New(lexer.New(string(content), file))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		c.addError(&SemanticError{
+			Code:    diag.ModuleNotLoaded,
+			Message: i18n.T("sema.module_parse_errors", modulePath, strings.Join(p.Errors(), "; ")),
+			Pos:     pos,
+		})
+		return nil
+	}
+
+	// Modül kendi scope'unda, aynı loader ile kontrol edilir
+	module := NewChecker()
+	module.modules = l
+	module.SetSource(string(content))
+	module.strictNull = c.strictNull
+	builtins := make(map[*Symbol]bool)
+	for _, sym := range module.symTable.GlobalScope().Symbols() {
+		builtins[sym] = true
+	}
+
+	l.loading = append(l.loading, loadingModule{file: key, path: modulePath})
+	errs := module.Check(program)
+	l.loading = l.loading[:len(l.loading)-1]
+	c.errors = append(c.errors, errs...)
+	c.warnings = append(c.warnings, module.warnings...)
+
+	mt := &ModuleType{Path: modulePath, Members: make(map[string]*Symbol)}
+	for name, sym := range module.symTable.GlobalScope().Symbols() {
+		if builtins[sym] || strings.HasPrefix(name, "_") {
+			continue
+		}
+		mt.Members[name] = sym
+	}
+	l.modules[key] = mt
+	return mt
+}
+
+func absPath(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+	return file
+}
+
+// ResolveModulePath resolves an import path to a .sky file, trying Wing
+// dependencies, the source file's directory and the working directory
+func ResolveModulePath(modulePath, sourceFile, currentDir string) string {
+	moduleFile := modulePath + ".sky"
+
+	// Try Wing dependencies first
+	dependencyPath := currentDir + "/dependencies/" + modulePath + "/src/" + moduleFile
+	if _, err := os.Stat(dependencyPath); err == nil {
+		return dependencyPath
+	}
+
+	// Try Wing dependencies with main.sky
+	dependencyMainPath := currentDir + "/dependencies/" + modulePath + "/src/main.sky"
+	if _, err := os.Stat(dependencyMainPath); err == nil {
+		return dependencyMainPath
+	}
+
+	// Try relative to source file directory
+	if sourceFile != "" {
+		sourceDir := ""
+		lastSlash := -1
+		for idx, ch := range sourceFile {
+			if ch == '/' || ch == '\\' {
+				lastSlash = idx
+			}
+		}
+		if lastSlash >= 0 {
+			sourceDir = sourceFile[:lastSlash]
+		}
+
+		if sourceDir != "" {
+			relToSource := sourceDir + "/" + moduleFile
+			if _, err := os.Stat(relToSource); err == nil {
+				return relToSource
+			}
+		}
+	}
+
+	// Try relative to current directory
+	relPath := currentDir + "/" + moduleFile
+	if _, err := os.Stat(relPath); err == nil {
+		return relPath
+	}
+
+	// Try as absolute path
+	if _, err := os.Stat(moduleFile); err == nil {
+		return moduleFile
+	}
+
+	// Default to relative path
+	return relPath
+}
@@ -0,0 +1,119 @@
+package sema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+	"github.com/mburakmmm/sky-lang/internal/parser"
+)
+
+func TestCheckImportedCalls(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, filepath.Join(dir, "lib", "util.sky"), `function add(a: int, b: int): int
+  return a + b
+
+function _secret(): int
+  return 1
+
+const NAME = "util"`)
+	main := writeModule(t, filepath.Join(dir, "main.sky"), `import lib.util
+import lib.util as u
+let n: int = util.add(1, 2)
+let s: string = u.NAME
+util.add(1)
+u.add("x", 2)
+util._secret()
+util.missing`)
+
+	errors := checkFile(t, main)
+	want := []string{
+		"main.sky:5:9: wrong number of arguments: expected 2, got 1",
+		"main.sky:6:6: argument 1 type mismatch: expected int, got string",
+		"main.sky:7:6: module lib.util has no exported member _secret",
+		"main.sky:8:6: module lib.util has no exported member missing",
+	}
+	if len(errors) != len(want) {
+		t.Fatalf("expected %d errors, got %d: %v", len(want), len(errors), errors)
+	}
+	for i, err := range errors {
+		if got := relative(dir, err); got != want[i] {
+			t.Errorf("error %d is %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestCheckImportedModuleOnce(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, filepath.Join(dir, "broken.sky"), `let x: int = "no"`)
+	writeModule(t, filepath.Join(dir, "other.sky"), `import broken`)
+	main := writeModule(t, filepath.Join(dir, "main.sky"), `import broken
+import other
+function f(): int
+  import broken as b
+  return 0`)
+
+	errors := checkFile(t, main)
+	if len(errors) != 1 {
+		t.Fatalf("expected the module error once, got %d: %v", len(errors), errors)
+	}
+	if got := relative(dir, errors[0]); got != "broken.sky:1:1: type mismatch: cannot assign string to int" {
+		t.Errorf("unexpected error %q", got)
+	}
+}
+
+func TestCheckImportErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, filepath.Join(dir, "a.sky"), "import b\nlet x = 1")
+	writeModule(t, filepath.Join(dir, "b.sky"), "let y = 2\nimport a")
+	main := writeModule(t, filepath.Join(dir, "main.sky"), "import a\nimport nowhere")
+
+	errors := checkFile(t, main)
+	if len(errors) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(errors), errors)
+	}
+	if got := relative(dir, errors[0]); got != "b.sky:2:1: import cycle: a -> b -> a" {
+		t.Errorf("cycle reported as %q", got)
+	}
+	missing := errors[1].(*SemanticError)
+	if missing.Pos.Line != 2 || !contains(missing.Message, "cannot load module nowhere") {
+		t.Errorf("missing module reported as %q", relative(dir, errors[1]))
+	}
+}
+
+func checkFile(t *testing.T, file string) []error {
+	t.Helper()
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := parser.New(lexer.New(string(content), file))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parse errors: %v", p.Errors())
+	}
+	checker := NewChecker()
+	checker.SetSourceFile(file)
+	return checker.Check(program)
+}
+
+func writeModule(t *testing.T, path, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// relative strips dir from the file name in an error message
+func relative(dir string, err error) string {
+	msg := err.Error()
+	if len(msg) > len(dir)+1 && msg[:len(dir)] == dir {
+		return msg[len(dir)+1:]
+	}
+	return msg
+}
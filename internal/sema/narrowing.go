@@ -0,0 +1,376 @@
+package sema
+
+import (
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+)
+
+// narrowFrame bir akış bölgesinde daraltılmış sembol tiplerini tutar
+// Örn. "if x != nil" bloğunda x'in tipi T? yerine T olur
+type narrowFrame map[*Symbol]Type
+
+// TypeInfo bir identifier'ın kaynaktaki konumunda bilinen (daraltılmış) tipini kaydeder
+// LSP hover bu bilgiyi kullanır
+type TypeInfo struct {
+	Name string
+	Pos  lexer.Token
+	Type Type
+}
+
+// SetStrictNull strict-null modunu açar/kapatır
+// Strict modda nil içerebilen değerler nil kabul etmeyen hedeflere atanamaz,
+// indekslenemez ve aritmetikte kullanılamaz
+func (c *Checker) SetStrictNull(enabled bool) {
+	c.strictNull = enabled
+}
+
+// Types kontrol sırasında kaydedilen identifier tiplerini döndürür
+func (c *Checker) Types() []TypeInfo {
+	return c.typeInfo
+}
+
+func (c *Checker) recordType(name string, pos lexer.Token, t Type) {
+	c.typeInfo = append(c.typeInfo, TypeInfo{Name: name, Pos: pos, Type: t})
+}
+
+// pushNarrowing yeni bir akış bölgesi açar
+func (c *Checker) pushNarrowing(facts narrowFrame) {
+	frame := make(narrowFrame, len(facts))
+	for sym, t := range facts {
+		frame[sym] = t
+	}
+	c.narrowing = append(c.narrowing, frame)
+}
+
+// popNarrowing akış bölgesini kapatır
+func (c *Checker) popNarrowing() {
+	if len(c.narrowing) > 0 {
+		c.narrowing = c.narrowing[:len(c.narrowing)-1]
+	}
+}
+
+// narrowedType sembolün bu noktadaki daraltılmış tipini döndürür
+func (c *Checker) narrowedType(sym *Symbol) (Type, bool) {
+	for i := len(c.narrowing) - 1; i >= 0; i-- {
+		if t, ok := c.narrowing[i][sym]; ok {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// currentType sembolün bu noktadaki tipini (daraltma dahil) döndürür
+func (c *Checker) currentType(sym *Symbol) Type {
+	if t, ok := c.narrowedType(sym); ok {
+		return t
+	}
+	return sym.Type
+}
+
+// narrow sembolü mevcut akış bölgesinin geri kalanı için daraltır
+func (c *Checker) narrow(sym *Symbol, t Type) {
+	if len(c.narrowing) == 0 {
+		c.pushNarrowing(nil)
+	}
+	c.narrowing[len(c.narrowing)-1][sym] = t
+}
+
+// invalidate atama sonrası sembolün tüm daraltmalarını geçersiz kılar
+// Atanan değer nil değilse sembol bölgenin geri kalanında nil olmayan tipe daraltılır
+func (c *Checker) invalidate(sym *Symbol, assigned Type) {
+	for _, frame := range c.narrowing {
+		delete(frame, sym)
+	}
+	if !isOptional(sym.Type) {
+		return
+	}
+	switch {
+	case assigned == NilType:
+		c.narrow(sym, NilType)
+	case assigned != AnyType && !isOptional(assigned):
+		c.narrow(sym, removeNil(sym.Type))
+	}
+}
+
+// isOptional tipin nil içerip içermediğini kontrol eder (T? veya T|nil)
+func isOptional(t Type) bool {
+	if u, ok := t.(*UnionType); ok {
+		for _, member := range u.Types {
+			if member == NilType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// removeNil union tipten nil'i çıkarır
+func removeNil(t Type) Type {
+	return removeMembers(t, func(member Type) bool { return member == NilType })
+}
+
+// removeMembers union tipten koşulu sağlayan üyeleri çıkarır
+func removeMembers(t Type, drop func(Type) bool) Type {
+	u, ok := t.(*UnionType)
+	if !ok {
+		return t
+	}
+	var kept []Type
+	for _, member := range u.Types {
+		if !drop(member) {
+			kept = append(kept, member)
+		}
+	}
+	switch len(kept) {
+	case 0:
+		return t
+	case 1:
+		return kept[0]
+	default:
+		return &UnionType{Types: kept}
+	}
+}
+
+// acceptsNil hedef tipin nil kabul edip etmediğini kontrol eder
+func acceptsNil(t Type) bool {
+	switch tt := t.(type) {
+	case *BasicType:
+		return tt == AnyType || tt == NilType
+	case *TypeParam:
+		return tt.Constraint == nil
+	case *UnionType:
+		for _, member := range tt.Types {
+			if acceptsNil(member) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isAssignable strict-null modunu da hesaba katan atanabilirlik kontrolü
+func (c *Checker) isAssignable(from, to Type) bool {
+	if c.strictNull && isOptional(from) && !acceptsNil(to) {
+		return false
+	}
+	return from.IsAssignableTo(to)
+}
+
+// checkNotNil nil olabilecek bir değerin kullanımını raporlar
+func (c *Checker) checkNotNil(expr ast.Expression, t Type, what string, pos lexer.Token) {
+	if !isOptional(t) && t != NilType {
+		return
+	}
+	subject := t.String()
+	if ident, ok := expr.(*ast.Identifier); ok {
+		subject = i18n.T("sema.nil_subject", ident.Value, t.String())
+	}
+	c.addError(&SemanticError{
+		Code:    diag.PossiblyNil,
+		Message: i18n.T("sema.possibly_nil", what, subject),
+		Pos:     pos,
+	})
+}
+
+// conditionFacts bir koşulun doğru ve yanlış olduğu durumlarda geçerli daraltmaları çıkarır
+func (c *Checker) conditionFacts(cond ast.Expression) (whenTrue, whenFalse narrowFrame) {
+	whenTrue, whenFalse = narrowFrame{}, narrowFrame{}
+
+	switch e := cond.(type) {
+	case *ast.InfixExpression:
+		switch e.Operator {
+		case "==", "!=":
+			sym, other := c.narrowableOperand(e.Left, e.Right)
+			if sym == nil || !isNilExpression(other) {
+				return
+			}
+			current := c.currentType(sym)
+			if !isOptional(current) {
+				return
+			}
+			if e.Operator == "!=" {
+				whenTrue[sym] = removeNil(current)
+				whenFalse[sym] = NilType
+			} else {
+				whenTrue[sym] = NilType
+				whenFalse[sym] = removeNil(current)
+			}
+
+		case "&&":
+			leftTrue, _ := c.conditionFacts(e.Left)
+			c.pushNarrowing(leftTrue)
+			rightTrue, _ := c.conditionFacts(e.Right)
+			c.popNarrowing()
+			merge(whenTrue, leftTrue)
+			merge(whenTrue, rightTrue)
+
+		case "||":
+			_, leftFalse := c.conditionFacts(e.Left)
+			c.pushNarrowing(leftFalse)
+			_, rightFalse := c.conditionFacts(e.Right)
+			c.popNarrowing()
+			merge(whenFalse, leftFalse)
+			merge(whenFalse, rightFalse)
+		}
+
+	case *ast.PrefixExpression:
+		if e.Operator == "!" {
+			t, f := c.conditionFacts(e.Right)
+			return f, t
+		}
+
+	case *ast.Identifier:
+		// if x  -> x nil değil
+		if sym, ok := c.symTable.Resolve(e.Value); ok && isOptional(c.currentType(sym)) {
+			whenTrue[sym] = removeNil(c.currentType(sym))
+		}
+
+	case *ast.CallExpression:
+		c.isinstanceFacts(e, whenTrue, whenFalse)
+	}
+
+	return whenTrue, whenFalse
+}
+
+// isinstanceFacts isinstance(x, "int") / isinstance(x, Class) çağrılarından daraltma çıkarır
+func (c *Checker) isinstanceFacts(call *ast.CallExpression, whenTrue, whenFalse narrowFrame) {
+	fn, ok := call.Function.(*ast.Identifier)
+	if !ok || fn.Value != "isinstance" || len(call.Arguments) != 2 {
+		return
+	}
+	subject, ok := call.Arguments[0].(*ast.Identifier)
+	if !ok {
+		return
+	}
+	sym, ok := c.symTable.Resolve(subject.Value)
+	if !ok {
+		return
+	}
+	current := c.currentType(sym)
+
+	var target Type
+	var matches func(Type) bool
+	switch t := call.Arguments[1].(type) {
+	case *ast.StringLiteral:
+		name := t.Value
+		matches = func(member Type) bool { return runtimeTypeName(member) == name }
+		target = typeForName(name)
+	case *ast.Identifier:
+		targetSym, ok := c.symTable.Resolve(t.Value)
+		if !ok {
+			return
+		}
+		switch targetSym.Type.(type) {
+		case *ClassType, *InterfaceType:
+			target = targetSym.Type
+			matches = func(member Type) bool { return member.IsAssignableTo(target) }
+		default:
+			return
+		}
+	default:
+		return
+	}
+
+	if u, ok := current.(*UnionType); ok {
+		var kept []Type
+		for _, member := range u.Types {
+			if matches(member) {
+				kept = append(kept, member)
+			}
+		}
+		switch len(kept) {
+		case 0:
+		case 1:
+			whenTrue[sym] = kept[0]
+		default:
+			whenTrue[sym] = &UnionType{Types: kept}
+		}
+		whenFalse[sym] = removeMembers(current, matches)
+		return
+	}
+
+	if current == AnyType && target != nil {
+		whenTrue[sym] = target
+	}
+}
+
+// narrowableOperand karşılaştırmanın identifier tarafını ve diğer tarafı döndürür
+func (c *Checker) narrowableOperand(left, right ast.Expression) (*Symbol, ast.Expression) {
+	if ident, ok := left.(*ast.Identifier); ok && !isNilExpression(left) {
+		if sym, ok := c.symTable.Resolve(ident.Value); ok {
+			return sym, right
+		}
+	}
+	if ident, ok := right.(*ast.Identifier); ok && !isNilExpression(right) {
+		if sym, ok := c.symTable.Resolve(ident.Value); ok {
+			return sym, left
+		}
+	}
+	return nil, nil
+}
+
+// isNilExpression ifadenin nil literal olup olmadığını kontrol eder
+func isNilExpression(expr ast.Expression) bool {
+	ident, ok := expr.(*ast.Identifier)
+	return ok && (ident.Value == "nil" || ident.Value == "null")
+}
+
+// runtimeTypeName type()/isinstance() tarafından kullanılan tip adını döndürür
+func runtimeTypeName(t Type) string {
+	switch tt := t.(type) {
+	case *BasicType:
+		return tt.Name
+	case *ListType:
+		return "list"
+	case *DictType:
+		return "dict"
+	case *FunctionType:
+		return "function"
+	case *ClassType:
+		return "instance"
+	default:
+		return ""
+	}
+}
+
+// typeForName isinstance tip adını sema tipine çevirir
+func typeForName(name string) Type {
+	switch name {
+	case "int":
+		return IntType
+	case "float":
+		return FloatType
+	case "string":
+		return StringType
+	case "bool":
+		return BoolType
+	case "nil":
+		return NilType
+	case "list":
+		return &ListType{ElementType: AnyType}
+	case "dict":
+		return &DictType{KeyType: AnyType, ValueType: AnyType}
+	default:
+		return nil
+	}
+}
+
+// blockTerminates bloğun akışı sonlandırıp sonlandırmadığını kontrol eder (return/break/continue/throw)
+func blockTerminates(block *ast.BlockStatement) bool {
+	if block == nil || len(block.Statements) == 0 {
+		return false
+	}
+	switch block.Statements[len(block.Statements)-1].(type) {
+	case *ast.ReturnStatement, *ast.BreakStatement, *ast.ContinueStatement, *ast.ThrowStatement:
+		return true
+	}
+	return false
+}
+
+func merge(dst, src narrowFrame) {
+	for sym, t := range src {
+		dst[sym] = t
+	}
+}
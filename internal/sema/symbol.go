@@ -2,6 +2,8 @@ package sema
 
 import (
 	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 	"github.com/mburakmmm/sky-lang/internal/lexer"
 )
 
@@ -14,6 +16,8 @@ const (
 	FunctionSymbol
 	ParameterSymbol
 	ClassSymbol
+	InterfaceSymbol
+	ModuleSymbol
 )
 
 func (sk SymbolKind) String() string {
@@ -28,6 +32,10 @@ func (sk SymbolKind) String() string {
 		return "parameter"
 	case ClassSymbol:
 		return "class"
+	case InterfaceSymbol:
+		return "interface"
+	case ModuleSymbol:
+		return "module"
 	default:
 		return "unknown"
 	}
@@ -40,6 +48,7 @@ type Symbol struct {
 	Type    Type
 	Pos     lexer.Token
 	IsAsync bool // fonksiyonlar için
+	IsCoop  bool // fonksiyonlar için
 	Mutable bool // true = let, false = const
 	Scope   *Scope
 	Node    ast.Node // tanımın AST düğümü
@@ -72,7 +81,8 @@ func NewScope(parent *Scope) *Scope {
 func (s *Scope) Define(symbol *Symbol) error {
 	if _, exists := s.symbols[symbol.Name]; exists {
 		return &SemanticError{
-			Message: "symbol '" + symbol.Name + "' already defined in this scope",
+			Code:    diag.DuplicateDefinition,
+			Message: i18n.T("sema.already_defined", symbol.Name),
 			Pos:     symbol.Pos,
 		}
 	}
@@ -127,10 +137,16 @@ func (s *Scope) Depth() int {
 
 // SemanticError semantik analiz hatalarını temsil eder
 type SemanticError struct {
+	Code    string // diag kataloğundaki kod, örn. E0102
 	Message string
 	Pos     lexer.Token
 }
 
+// DiagnosticCode hatanın diag kodunu döndürür
+func (e *SemanticError) DiagnosticCode() string {
+	return e.Code
+}
+
 func (e *SemanticError) Error() string {
 	if e.Pos.File != "" {
 		return e.Pos.Position() + ": " + e.Message
@@ -255,6 +271,7 @@ func NewSymbolTable() *SymbolTable {
 			Type: builtin.typ,
 		})
 	}
+	defineAsyncBuiltins(globalScope)
 
 	return &SymbolTable{
 		globalScope:  globalScope,
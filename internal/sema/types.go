@@ -2,9 +2,11 @@ package sema
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 )
 
 // Type tipleri temsil eder
@@ -92,6 +94,10 @@ func (t *ListType) IsAssignableTo(target Type) bool {
 		return true
 	}
 
+	if unionTarget, ok := target.(*UnionType); ok {
+		return unionTarget.accepts(t)
+	}
+
 	if o, ok := target.(*ListType); ok {
 		return t.ElementType.IsAssignableTo(o.ElementType)
 	}
@@ -121,6 +127,10 @@ func (t *DictType) IsAssignableTo(target Type) bool {
 		return true
 	}
 
+	if unionTarget, ok := target.(*UnionType); ok {
+		return unionTarget.accepts(t)
+	}
+
 	if o, ok := target.(*DictType); ok {
 		return t.KeyType.IsAssignableTo(o.KeyType) && t.ValueType.IsAssignableTo(o.ValueType)
 	}
@@ -133,7 +143,8 @@ type FunctionType struct {
 	Params     []Type
 	ReturnType Type
 	Variadic   bool
-	MinParams  int // Minimum required parameters (for optional params)
+	MinParams  int          // Minimum required parameters (for optional params)
+	TypeParams []*TypeParam // generic parametreler (function first[T])
 }
 
 func (t *FunctionType) String() string {
@@ -147,6 +158,9 @@ func (t *FunctionType) String() string {
 	if t.Variadic {
 		params += "..."
 	}
+	if len(t.TypeParams) > 0 {
+		return fmt.Sprintf("%s(%s) => %s", typeParamList(t.TypeParams), params, t.ReturnType.String())
+	}
 	return fmt.Sprintf("(%s) => %s", params, t.ReturnType.String())
 }
 
@@ -170,6 +184,10 @@ func (t *FunctionType) IsAssignableTo(target Type) bool {
 		return true
 	}
 
+	if unionTarget, ok := target.(*UnionType); ok {
+		return unionTarget.accepts(t)
+	}
+
 	if o, ok := target.(*FunctionType); ok {
 		// Fonksiyon tiplerinin ataması için parametreler ve dönüş tipi uyumlu olmalı
 		if len(t.Params) != len(o.Params) {
@@ -207,6 +225,10 @@ func (t *PointerType) IsAssignableTo(target Type) bool {
 		return true
 	}
 
+	if unionTarget, ok := target.(*UnionType); ok {
+		return unionTarget.accepts(t)
+	}
+
 	if o, ok := target.(*PointerType); ok {
 		return t.PointeeType.IsAssignableTo(o.PointeeType)
 	}
@@ -214,21 +236,96 @@ func (t *PointerType) IsAssignableTo(target Type) bool {
 	return false
 }
 
+// PromiseType async fonksiyon çağrısının sonucunu temsil eder Promise[T]
+// await ifadesi T tipinde değer verir
+type PromiseType struct {
+	ValueType Type
+}
+
+func (t *PromiseType) String() string {
+	return fmt.Sprintf("Promise[%s]", t.ValueType.String())
+}
+
+func (t *PromiseType) Equals(other Type) bool {
+	if o, ok := other.(*PromiseType); ok {
+		return t.ValueType.Equals(o.ValueType)
+	}
+	return false
+}
+
+func (t *PromiseType) IsAssignableTo(target Type) bool {
+	if target == AnyType {
+		return true
+	}
+
+	if unionTarget, ok := target.(*UnionType); ok {
+		return unionTarget.accepts(t)
+	}
+
+	if o, ok := target.(*PromiseType); ok {
+		return t.ValueType.IsAssignableTo(o.ValueType)
+	}
+
+	return false
+}
+
+// awaited await edilen tipin sonucunu döndürür; promise olmayan değer olduğu gibi kalır
+func awaited(t Type) Type {
+	if p, ok := t.(*PromiseType); ok {
+		return p.ValueType
+	}
+	return t
+}
+
+// promiseOf async fonksiyonun dönüş tipini Promise[T] yapar
+// Anotasyon zaten Promise[T] ise tekrar sarılmaz
+func promiseOf(t Type) Type {
+	if _, ok := t.(*PromiseType); ok {
+		return t
+	}
+	return &PromiseType{ValueType: t}
+}
+
 // ClassType sınıf tipini temsil eder
 type ClassType struct {
 	Name         string
 	SuperClasses []*ClassType // multiple inheritance
+	Interfaces   []*InterfaceType
 	Methods      map[string]*FunctionType
-	Fields       map[string]Type
+	Fields       map[string]Type // init'te atanan ve anotasyonlu alanlar
+	Statics      map[string]Type // static property ve metodlar
+
+	// declared tipi anotasyonla bildirilmiş alanlar; atamalar bu tiple kontrol edilir
+	declared map[string]bool
+
+	// Generic sınıflar: tanımda TypeParams, somut kullanımda Generic + TypeArgs dolu olur
+	TypeParams []*TypeParam
+	TypeArgs   []Type
+	Generic    *ClassType
 }
 
 func (t *ClassType) String() string {
+	if len(t.TypeArgs) > 0 {
+		args := make([]string, len(t.TypeArgs))
+		for i, arg := range t.TypeArgs {
+			args[i] = arg.String()
+		}
+		return fmt.Sprintf("%s[%s]", t.Name, strings.Join(args, ", "))
+	}
 	return t.Name
 }
 
 func (t *ClassType) Equals(other Type) bool {
 	if o, ok := other.(*ClassType); ok {
-		return t.Name == o.Name
+		if t.Name != o.Name || len(t.TypeArgs) != len(o.TypeArgs) {
+			return false
+		}
+		for i := range t.TypeArgs {
+			if !t.TypeArgs[i].Equals(o.TypeArgs[i]) {
+				return false
+			}
+		}
+		return true
 	}
 	return false
 }
@@ -238,10 +335,14 @@ func (t *ClassType) IsAssignableTo(target Type) bool {
 		return true
 	}
 
+	if unionTarget, ok := target.(*UnionType); ok {
+		return unionTarget.accepts(t)
+	}
+
 	if o, ok := target.(*ClassType); ok {
-		// Aynı sınıf ise atanabilir
+		// Aynı sınıf ise atanabilir (generic ise tip argümanları da uyumlu olmalı)
 		if t.Name == o.Name {
-			return true
+			return sameTypeArgs(t.TypeArgs, o.TypeArgs)
 		}
 
 		// Parent sınıflardan birinde ise atanabilir (multiple inheritance)
@@ -252,9 +353,222 @@ func (t *ClassType) IsAssignableTo(target Type) bool {
 		}
 	}
 
+	// Interface'ler yapısal olarak kontrol edilir
+	if iface, ok := target.(*InterfaceType); ok {
+		return len(iface.MissingMethods(t)) == 0
+	}
+
 	return false
 }
 
+// LookupMethod sınıfın (ve parent sınıfların) metodunu arar
+// Somutlaştırılmış generic sınıflarda imza tip argümanlarıyla döndürülür
+func (t *ClassType) LookupMethod(name string) (*FunctionType, bool) {
+	if t.Generic != nil {
+		method, ok := t.Generic.LookupMethod(name)
+		if !ok {
+			return nil, false
+		}
+		return substitute(method, t.bindings()).(*FunctionType), true
+	}
+	if method, ok := t.Methods[name]; ok {
+		return method, true
+	}
+	for _, superClass := range t.SuperClasses {
+		if method, ok := superClass.LookupMethod(name); ok {
+			return method, true
+		}
+	}
+	return nil, false
+}
+
+// LookupField sınıfın (ve parent sınıfların) alanını arar
+func (t *ClassType) LookupField(name string) (Type, bool) {
+	if t.Generic != nil {
+		field, ok := t.Generic.LookupField(name)
+		if !ok {
+			return nil, false
+		}
+		return substitute(field, t.bindings()), true
+	}
+	if field, ok := t.Fields[name]; ok {
+		return field, true
+	}
+	for _, superClass := range t.SuperClasses {
+		if field, ok := superClass.LookupField(name); ok {
+			return field, true
+		}
+	}
+	return nil, false
+}
+
+// LookupStatic sınıfın (ve parent sınıfların) static üyesini arar
+func (t *ClassType) LookupStatic(name string) (Type, bool) {
+	if t.Generic != nil {
+		return t.Generic.LookupStatic(name)
+	}
+	if static, ok := t.Statics[name]; ok {
+		return static, true
+	}
+	for _, superClass := range t.SuperClasses {
+		if static, ok := superClass.LookupStatic(name); ok {
+			return static, true
+		}
+	}
+	return nil, false
+}
+
+// InterfaceType interface tipini temsil eder
+// Uyumluluk yapısaldır: gerekli tüm metodlara sahip her sınıf interface'i karşılar
+type InterfaceType struct {
+	Name    string
+	Extends []*InterfaceType
+	Methods map[string]*FunctionType
+}
+
+func (t *InterfaceType) String() string {
+	return t.Name
+}
+
+func (t *InterfaceType) Equals(other Type) bool {
+	if o, ok := other.(*InterfaceType); ok {
+		return t.Name == o.Name
+	}
+	return false
+}
+
+func (t *InterfaceType) IsAssignableTo(target Type) bool {
+	if target == AnyType {
+		return true
+	}
+
+	if unionTarget, ok := target.(*UnionType); ok {
+		return unionTarget.accepts(t)
+	}
+
+	if o, ok := target.(*InterfaceType); ok {
+		if t.Name == o.Name {
+			return true
+		}
+
+		// Parent interface'lerden birine atanabilir
+		for _, parent := range t.Extends {
+			if parent.IsAssignableTo(target) {
+				return true
+			}
+		}
+
+		// Yapısal olarak üst küme ise atanabilir
+		methods := t.AllMethods()
+		for name, required := range o.AllMethods() {
+			method, ok := methods[name]
+			if !ok || !methodSatisfies(method, required) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// AllMethods parent interface'ler dahil tüm metod imzalarını döndürür
+func (t *InterfaceType) AllMethods() map[string]*FunctionType {
+	methods := make(map[string]*FunctionType)
+	for _, parent := range t.Extends {
+		for name, method := range parent.AllMethods() {
+			methods[name] = method
+		}
+	}
+	for name, method := range t.Methods {
+		methods[name] = method
+	}
+	return methods
+}
+
+// MissingMethods sınıfın karşılamadığı metodları açıklamalarıyla döndürür
+// Boş liste sınıfın interface'i karşıladığı anlamına gelir
+func (t *InterfaceType) MissingMethods(class *ClassType) []string {
+	required := t.AllMethods()
+	names := make([]string, 0, len(required))
+	for name := range required {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		method, ok := class.LookupMethod(name)
+		if !ok {
+			problems = append(problems, i18n.T("sema.missing_method", name))
+			continue
+		}
+		if !methodSatisfies(method, required[name]) {
+			problems = append(problems, i18n.T("sema.method_signature",
+				name, method.String(), required[name].String()))
+		}
+	}
+	return problems
+}
+
+// methodSatisfies bir metodun interface imzasını karşılayıp karşılamadığını kontrol eder
+// Parametreler contravariant, dönüş tipi covariant kontrol edilir
+func methodSatisfies(method, required *FunctionType) bool {
+	if len(method.Params) != len(required.Params) {
+		return false
+	}
+	for i := range required.Params {
+		if !required.Params[i].IsAssignableTo(method.Params[i]) {
+			return false
+		}
+	}
+	return method.ReturnType.IsAssignableTo(required.ReturnType)
+}
+
+// EnumType enum tipini temsil eder
+// Variant'lar tanım sırasını korur; match kapsamlılık kontrolü bu sırayı kullanır
+type EnumType struct {
+	Name     string
+	Variants []*EnumVariantType
+}
+
+// EnumVariantType bir enum variant'ını ve payload tiplerini temsil eder
+type EnumVariantType struct {
+	Name    string
+	Payload []Type
+}
+
+func (t *EnumType) String() string {
+	return t.Name
+}
+
+func (t *EnumType) Equals(other Type) bool {
+	if o, ok := other.(*EnumType); ok {
+		return t.Name == o.Name
+	}
+	return false
+}
+
+func (t *EnumType) IsAssignableTo(target Type) bool {
+	if target == AnyType {
+		return true
+	}
+	if unionTarget, ok := target.(*UnionType); ok {
+		return unionTarget.accepts(t)
+	}
+	return t.Equals(target)
+}
+
+// Variant isimle variant arar
+func (t *EnumType) Variant(name string) (*EnumVariantType, bool) {
+	for _, v := range t.Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
 // ResolveType AST tip anotasyonunu gerçek Type'a dönüştürür
 func ResolveType(typeAnnot ast.TypeAnnotation) Type {
 	if typeAnnot == nil {
@@ -351,6 +665,16 @@ func (t *UnionType) Equals(other Type) bool {
 	return false
 }
 
+// accepts tipin union üyelerinden birine atanabilir olup olmadığını kontrol eder
+func (t *UnionType) accepts(other Type) bool {
+	for _, member := range t.Types {
+		if other.IsAssignableTo(member) {
+			return true
+		}
+	}
+	return false
+}
+
 func (t *UnionType) IsAssignableTo(target Type) bool {
 	if target == AnyType {
 		return true
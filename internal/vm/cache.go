@@ -0,0 +1,84 @@
+package vm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Cache keeps compiled bytecode on disk so unchanged sources are not
+// parsed and compiled again. Entries are keyed by the SHA-256 of the
+// source and the optimization level; editing a file changes its hash and
+// forces a recompile.
+type Cache struct {
+	Dir string
+}
+
+// DefaultCache returns the bytecode cache in $SKY_CACHE_DIR, or
+// ~/.sky/cache/bytecode. It returns nil if SKY_NO_CACHE is set.
+func DefaultCache() *Cache {
+	if os.Getenv("SKY_NO_CACHE") != "" {
+		return nil
+	}
+	if dir := os.Getenv("SKY_CACHE_DIR"); dir != "" {
+		return &Cache{Dir: dir}
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return &Cache{Dir: filepath.Join(homeDir, ".sky", "cache", "bytecode")}
+}
+
+// path returns the cache file of a source hash. Modules are compiled
+// without the call to main, so they are cached separately.
+func (c *Cache) path(hash string, level int, module bool) string {
+	name := fmt.Sprintf("%s-O%d", hash, level)
+	if module {
+		return filepath.Join(c.Dir, name+".mod.skyc")
+	}
+	return filepath.Join(c.Dir, name+".skyc")
+}
+
+// Load returns cached bytecode for a source hash built at an optimization
+// level. Missing, corrupt and outdated entries are reported as misses.
+func (c *Cache) Load(hash string, level int, module bool) (*Bytecode, bool) {
+	if c == nil {
+		return nil, false
+	}
+	bc, err := ReadSkyc(c.path(hash, level, module))
+	if err != nil || bc.SourceHash != hash || bc.OptLevel != level {
+		return nil, false
+	}
+	return bc, true
+}
+
+// Store writes bytecode to the cache. The file is written under a
+// temporary name first so concurrent runs never see a partial entry.
+func (c *Cache) Store(bc *Bytecode, module bool) error {
+	if c == nil {
+		return nil
+	}
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+	data, err := bc.Encode()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.Dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(bc.SourceHash, bc.OptLevel, module))
+}
@@ -0,0 +1,301 @@
+package vm
+
+import (
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+)
+
+// Classes and instances use the interpreter's types. Methods are stored in
+// Class.Methods as wrapper functions; vm.methods maps a wrapper back to its
+// closure so calls from bytecode stay inside the VM.
+
+// defineClass creates a class (or abstract class) from the superclasses on the stack
+func (vm *VM) defineClass(ins Instruction) error {
+	supers := make([]*interpreter.Class, 0, ins.Operand)
+	for _, val := range vm.boxed(vm.sp - ins.Operand) {
+		switch super := val.(type) {
+		case *interpreter.Class:
+			supers = append(supers, super)
+		case *interpreter.AbstractClass:
+			supers = append(supers, vm.abstractClass(super))
+		default:
+			return &interpreter.RuntimeError{Message: i18n.T("runtime.not_a_class", val.String())}
+		}
+	}
+	vm.sp -= ins.Operand
+
+	class := &interpreter.Class{
+		Name:         ins.Name,
+		SuperClasses: supers,
+		Methods:      make(map[string]*interpreter.Function),
+		Env:          interpreter.NewEnvironment(nil),
+	}
+	if ins.Operand2 == 1 {
+		abstract := &interpreter.AbstractClass{
+			Name:            class.Name,
+			SuperClasses:    class.SuperClasses,
+			Methods:         class.Methods,
+			AbstractMethods: make(map[string]*interpreter.AbstractMethod),
+			Env:             class.Env,
+		}
+		vm.abstracts[abstract] = class
+		vm.push(abstract)
+		return nil
+	}
+	vm.push(class)
+	return nil
+}
+
+// abstractClass returns the class used when inheriting from an abstract class
+func (vm *VM) abstractClass(abstract *interpreter.AbstractClass) *interpreter.Class {
+	if class, ok := vm.abstracts[abstract]; ok {
+		return class
+	}
+	class := &interpreter.Class{
+		Name:         abstract.Name,
+		SuperClasses: abstract.SuperClasses,
+		Methods:      abstract.Methods,
+		Env:          abstract.Env,
+	}
+	vm.abstracts[abstract] = class
+	return class
+}
+
+// addMethod attaches a compiled method to a class
+func (vm *VM) addMethod(target interpreter.Value, name string, method *Closure) {
+	var class *interpreter.Class
+	switch t := target.(type) {
+	case *interpreter.Class:
+		class = t
+	case *interpreter.AbstractClass:
+		class = vm.abstractClass(t)
+	}
+	method.Class = class
+	class.Methods[name] = vm.wrap(method)
+	vm.classEpoch++
+}
+
+// defineInterface creates an interface from parent interfaces and
+// name/arity pairs on the stack
+func (vm *VM) defineInterface(ins Instruction) error {
+	iface := &interpreter.Interface{Name: ins.Name, Methods: make(map[string]int)}
+	start := vm.sp - ins.Operand2 - 2*ins.Operand
+	for _, val := range vm.boxed(start)[:ins.Operand2] {
+		parent, ok := val.(*interpreter.Interface)
+		if !ok {
+			return &interpreter.RuntimeError{Message: i18n.T("runtime.not_interface", val.String())}
+		}
+		iface.Extends = append(iface.Extends, parent)
+	}
+	for i := start + ins.Operand2; i < vm.sp; i += 2 {
+		iface.Methods[vm.stack[i].box().String()] = int(vm.stack[i+1].int())
+	}
+	vm.sp = start
+	vm.push(iface)
+	return nil
+}
+
+// instantiate creates an instance and runs the init methods of the direct
+// superclasses, then its own
+func (vm *VM) instantiate(class *interpreter.Class, args []interpreter.Value) (interpreter.Value, error) {
+	inst := &interpreter.Instance{Class: class, Fields: make(map[string]interpreter.Value)}
+
+	var inits []*interpreter.Function
+	for _, super := range class.SuperClasses {
+		if init, ok := super.Methods["init"]; ok {
+			inits = append(inits, init)
+		}
+	}
+	if init, ok := class.Methods["init"]; ok {
+		inits = append(inits, init)
+	}
+
+	for _, init := range inits {
+		if _, err := vm.callMethod(init, inst, args); err != nil {
+			return nil, err
+		}
+	}
+	return inst, nil
+}
+
+// callMethod calls a method with self bound to receiver
+func (vm *VM) callMethod(method *interpreter.Function, receiver interpreter.Value, args []interpreter.Value) (interpreter.Value, error) {
+	if closure, ok := vm.methods[method]; ok {
+		return vm.callWith(closure, receiver, args)
+	}
+	callEnv := interpreter.NewEnvironment(method.Env)
+	callEnv.Set("__args__", &interpreter.List{Elements: args})
+	callEnv.Set("self", receiver)
+	vm.withCallSite(callEnv)
+	return method.Body(callEnv)
+}
+
+// invoke calls method name on the receiver below argc arguments
+func (vm *VM) invoke(ic *inlineCache, name string, argc int) error {
+	slot := vm.sp - argc - 1
+	receiver := vm.stack[slot].box()
+
+	switch recv := receiver.(type) {
+	case *interpreter.Instance:
+		// Fields holding functions are called without self
+		if field, ok := recv.Fields[name]; ok {
+			vm.stack[slot] = unbox(field)
+			return vm.callValue(field, argc, name)
+		}
+		method, closure, ok := vm.lookupMethod(ic, recv.Class, name)
+		if !ok {
+			return &interpreter.RuntimeError{Code: diag.UndefinedMember, Message: i18n.T("runtime.undefined_method", name)}
+		}
+		if closure != nil {
+			return vm.pushFrame(closure, argc)
+		}
+		result, err := vm.callMethod(method, recv, vm.args(slot+1))
+		return vm.complete(slot, result, err)
+
+	case *interpreter.Class:
+		method, ok := recv.Methods[name]
+		if !ok {
+			return &interpreter.RuntimeError{Code: diag.UndefinedMember, Message: i18n.T("runtime.undefined_method", name)}
+		}
+		result, err := vm.callMethod(method, vm.currentSelf(), vm.args(slot+1))
+		return vm.complete(slot, result, err)
+
+	case *interpreter.Dict:
+		if val, ok := recv.Pairs[name]; ok {
+			vm.stack[slot] = unbox(val)
+			return vm.callValue(val, argc, name)
+		}
+		if builtin, ok := vm.builtins["dict_"+name].(*interpreter.Function); ok {
+			result, err := vm.callNative(builtin, vm.args(slot))
+			return vm.complete(slot, result, err)
+		}
+
+	case *interpreter.String:
+		if builtin, ok := vm.builtins["str_"+name].(*interpreter.Function); ok {
+			result, err := vm.callNative(builtin, vm.args(slot))
+			return vm.complete(slot, result, err)
+		}
+
+	case *interpreter.List:
+		if builtin, ok := vm.builtins["list_"+name].(*interpreter.Function); ok {
+			result, err := vm.callNative(builtin, vm.args(slot))
+			return vm.complete(slot, result, err)
+		}
+	}
+
+	callee, err := vm.getMember(ic, receiver, name)
+	if err != nil {
+		return err
+	}
+	vm.stack[slot] = unbox(callee)
+	return vm.callValue(callee, argc, name)
+}
+
+// superInvoke calls name on the superclass of the method being executed
+func (vm *VM) superInvoke(frame *CallFrame, name string, argc int) error {
+	slot := vm.sp - argc - 1
+	for _, super := range superClasses(frame.closure.Class) {
+		method, ok := findMethod(super, name)
+		if !ok {
+			continue
+		}
+		if closure, ok := vm.methods[method]; ok {
+			return vm.pushFrame(closure, argc)
+		}
+		result, err := vm.callMethod(method, vm.stack[slot].box(), vm.args(slot+1))
+		return vm.complete(slot, result, err)
+	}
+	return &interpreter.RuntimeError{Code: diag.UndefinedMember, Message: i18n.T("runtime.undefined_method", name)}
+}
+
+// getMember evaluates obj.name; ic is the site's inline cache or nil
+func (vm *VM) getMember(ic *inlineCache, object interpreter.Value, name string) (interpreter.Value, error) {
+	switch obj := object.(type) {
+	case *interpreter.Instance:
+		if val, ok := obj.Fields[name]; ok {
+			return val, nil
+		}
+		if _, closure, ok := vm.lookupMethod(ic, obj.Class, name); ok && closure != nil {
+			return &BoundMethod{Receiver: obj, Method: closure}, nil
+		}
+		if val, ok := obj.Get(name); ok {
+			return val, nil
+		}
+		return nil, &interpreter.RuntimeError{Code: diag.UndefinedMember, Message: i18n.T("runtime.undefined_property", name)}
+
+	case *interpreter.Class:
+		if method, ok := obj.Methods[name]; ok {
+			return method, nil
+		}
+		return nil, &interpreter.RuntimeError{Code: diag.UndefinedMember, Message: i18n.T("runtime.undefined_method", name)}
+
+	case *interpreter.AbstractClass:
+		if method, ok := obj.Methods[name]; ok {
+			return method, nil
+		}
+		return nil, &interpreter.RuntimeError{Code: diag.UndefinedMember, Message: i18n.T("runtime.undefined_method", name)}
+
+	case *interpreter.Dict:
+		if val, ok := obj.Pairs[name]; ok {
+			return val, nil
+		}
+		if builtin, ok := vm.builtins["dict_"+name].(*interpreter.Function); ok {
+			return vm.bindBuiltin(builtin, name, obj), nil
+		}
+		return &interpreter.Nil{}, nil
+
+	case *interpreter.String:
+		if builtin, ok := vm.builtins["str_"+name].(*interpreter.Function); ok {
+			return vm.bindBuiltin(builtin, name, obj), nil
+		}
+
+	case *interpreter.List:
+		if builtin, ok := vm.builtins["list_"+name].(*interpreter.Function); ok {
+			return vm.bindBuiltin(builtin, name, obj), nil
+		}
+	}
+	return nil, &interpreter.RuntimeError{Message: i18n.T("runtime.member_access", object)}
+}
+
+// bindBuiltin returns a built-in with receiver injected as first argument
+func (vm *VM) bindBuiltin(fn *interpreter.Function, name string, receiver interpreter.Value) *interpreter.Function {
+	return &interpreter.Function{
+		Name:       name,
+		Parameters: fn.Parameters,
+		Env:        fn.Env,
+		Body: func(callEnv *interpreter.Environment) (interpreter.Value, error) {
+			args := append([]interpreter.Value{receiver}, envArgs(callEnv)...)
+			callEnv.Set("__args__", &interpreter.List{Elements: args})
+			return fn.Body(callEnv)
+		},
+	}
+}
+
+// findMethod looks up a method on the class and its superclasses
+func findMethod(class *interpreter.Class, name string) (*interpreter.Function, bool) {
+	if method, ok := class.Methods[name]; ok {
+		return method, true
+	}
+	for _, super := range class.SuperClasses {
+		if method, ok := findMethod(super, name); ok {
+			return method, true
+		}
+	}
+	return nil, false
+}
+
+// superOf returns the first superclass of class, or nil
+func superOf(class *interpreter.Class) interpreter.Value {
+	if supers := superClasses(class); len(supers) > 0 {
+		return supers[0]
+	}
+	return &interpreter.Nil{}
+}
+
+func superClasses(class *interpreter.Class) []*interpreter.Class {
+	if class == nil {
+		return nil
+	}
+	return class.SuperClasses
+}
@@ -1,41 +1,89 @@
 package vm
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
 )
 
 // Compiler compiles AST to bytecode
 type Compiler struct {
 	instructions []Instruction
+	lines        []int // source line of each instruction
+	line         int   // line of the statement being compiled
 	constants    []interface{}
 	symbolTable  *SymbolTable
 	scopeDepth   int
 	functions    map[string]*CompiledFunction // Compiled functions
+
+	enclosing *Compiler    // compiler of the enclosing function (nil at top level)
+	upvalues  []UpvalueRef // variables captured by the function being compiled
+	loops     []*loopContext
+	tries     []tryContext // active exception handlers, innermost last
+	program   *programInfo
+
+	typeChecks bool   // emit runtime type checks at annotations
+	returnType string // checked return type of the function being compiled, "" for none
+	name       string // name of the function being compiled, for type errors
+}
+
+// loopContext tracks jump targets of the innermost loop
+type loopContext struct {
+	start  int   // continue target
+	breaks []int // jumps to patch to the loop exit
+	tries  int   // active handlers when the loop was entered
+}
+
+// tryContext is an active exception handler; finally runs when leaving it
+// through return, break or continue
+type tryContext struct {
+	finally *ast.BlockStatement
+}
+
+// programInfo holds names declared anywhere in the program
+type programInfo struct {
+	variants map[string]string // enum variant name -> enum name (bare identifiers in patterns)
+	classes  map[string]bool   // class names (constructor-style patterns)
+	globals  map[string]bool   // top-level names that shadow built-ins
+	lambdas  int
+
+	outer func(name string) bool // names defined outside the program, if any (see Tier)
 }
 
 // SymbolTable tracks variables and their stack slots
 type SymbolTable struct {
 	outer   *SymbolTable
 	symbols map[string]int // name -> stack slot
-	numDefs int            // number of definitions in this scope
+	numDefs *int           // slots used by the function, shared by its block scopes
 }
 
-// NewSymbolTable creates a new symbol table
+// NewSymbolTable creates a new symbol table. A table with an outer table is a
+// block scope of the same function and continues its slot numbering.
 func NewSymbolTable(outer *SymbolTable) *SymbolTable {
+	numDefs := new(int)
+	if outer != nil {
+		numDefs = outer.numDefs
+	}
 	return &SymbolTable{
 		outer:   outer,
 		symbols: make(map[string]int),
-		numDefs: 0,
+		numDefs: numDefs,
 	}
 }
 
-// Define adds a new symbol
+// Define adds a new symbol, reusing the slot if the scope already has it
 func (st *SymbolTable) Define(name string) int {
-	slot := st.numDefs
+	if slot, ok := st.symbols[name]; ok {
+		return slot
+	}
+	slot := *st.numDefs
 	st.symbols[name] = slot
-	st.numDefs++
+	*st.numDefs++
 	return slot
 }
 
@@ -59,39 +107,66 @@ func NewCompiler() *Compiler {
 		symbolTable:  NewSymbolTable(nil),
 		scopeDepth:   0,
 		functions:    make(map[string]*CompiledFunction),
+		program: &programInfo{
+			variants: make(map[string]string),
+			classes:  make(map[string]bool),
+			globals:  make(map[string]bool),
+		},
 	}
 }
 
-// Compile compiles an AST program to bytecode
+// SetTypeChecks makes the compiler emit runtime type checks for annotated
+// parameters, return values and let bindings (see interpreter.RuntimeType)
+func (c *Compiler) SetTypeChecks(on bool) {
+	c.typeChecks = on
+}
+
+// Compile compiles an AST program to bytecode. Top-level statements run in
+// order, then main() is called if the program defines it.
 func (c *Compiler) Compile(program *ast.Program) (*Bytecode, error) {
-	// Find and compile main function first
-	var mainFunc *ast.FunctionStatement
+	return c.compileProgram(program, true)
+}
+
+// CompileModule compiles an imported module; its main() is not called
+func (c *Compiler) CompileModule(program *ast.Program) (*Bytecode, error) {
+	return c.compileProgram(program, false)
+}
+
+// CompileFunction compiles a function of program on its own, for calling it
+// outside compiled code. Names not local to the function are globals;
+// outer reports names defined where the function is called from.
+func (c *Compiler) CompileFunction(program *ast.Program, stmt *ast.FunctionStatement,
+	outer func(name string) bool) (*CompiledFunction, error) {
+	c.declare(program.Statements)
+	c.program.outer = outer
+	c.symbolTable.Define("")
+
+	if err := c.compileFunction(stmt.Name.Value, stmt.Parameters, stmt.ReturnType, stmt.Body, stmt.Async, stmt.Coop, nil); err != nil {
+		return nil, err
+	}
+	return c.constants[len(c.constants)-1].(*CompiledFunction), nil
+}
+
+func (c *Compiler) compileProgram(program *ast.Program, callMain bool) (*Bytecode, error) {
+	c.declare(program.Statements)
+	c.symbolTable.Define("") // slot 0 holds the script closure
+
+	hasMain := false
 	for _, stmt := range program.Statements {
-		if fn, ok := stmt.(*ast.FunctionStatement); ok {
-			if fn.Name.Value == "main" {
-				mainFunc = fn
-			} else {
-				// Compile other functions as separate bytecode chunks
-				if err := c.compileStatement(stmt); err != nil {
-					return nil, err
-				}
-			}
-		} else {
-			if err := c.compileStatement(stmt); err != nil {
-				return nil, err
-			}
+		if fn, ok := stmt.(*ast.FunctionStatement); ok && fn.Name.Value == "main" {
+			hasMain = true
+		}
+		if err := c.compileStatement(stmt); err != nil {
+			return nil, err
 		}
 	}
 
-	// Compile main function body
-	if mainFunc != nil {
-		c.enterScope()
-		for _, stmt := range mainFunc.Body.Statements {
-			if err := c.compileStatement(stmt); err != nil {
-				return nil, err
-			}
-		}
-		c.leaveScope()
+	// Call main; async main returns a promise which is awaited
+	if callMain && hasMain {
+		c.emit(Instruction{Op: OpGetGlobal, Name: "main"})
+		c.emit(Instruction{Op: OpCall, Operand: 0, Name: "main"})
+		c.emit(Instruction{Op: OpAwait})
+		c.emit(Instruction{Op: OpPop})
 	}
 
 	// Add halt at the end
@@ -99,12 +174,51 @@ func (c *Compiler) Compile(program *ast.Program) (*Bytecode, error) {
 
 	return &Bytecode{
 		Instructions: c.instructions,
+		Lines:        c.lines,
 		Constants:    c.constants,
 		Functions:    c.functions,
+		LocalCount:   *c.symbolTable.numDefs,
 	}, nil
 }
 
+// declare records top-level names, enum variants and classes
+func (c *Compiler) declare(stmts []ast.Statement) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.FunctionStatement:
+			c.program.globals[s.Name.Value] = true
+		case *ast.LetStatement:
+			c.program.globals[s.Name.Value] = true
+		case *ast.ConstStatement:
+			c.program.globals[s.Name.Value] = true
+		case *ast.ClassStatement:
+			c.program.globals[s.Name.Value] = true
+			c.program.classes[s.Name.Value] = true
+		case *ast.AbstractClassStatement:
+			c.program.globals[s.Name.Value] = true
+			c.program.classes[s.Name.Value] = true
+		case *ast.ExpressionStatement:
+			// Assigning an undefined name creates a global, like let
+			if assign, ok := s.Expression.(*ast.InfixExpression); ok && assign.Operator == "=" {
+				if ident, ok := assign.Left.(*ast.Identifier); ok {
+					c.program.globals[ident.Value] = true
+				}
+			}
+		case *ast.EnumStatement:
+			c.program.globals[s.Name.Value] = true
+			for _, variant := range s.Variants {
+				c.program.globals[variant.Name.Value] = true
+				c.program.variants[variant.Name.Value] = s.Name.Value
+			}
+		}
+	}
+}
+
 func (c *Compiler) compileStatement(stmt ast.Statement) error {
+	if line := stmt.Pos().Line; line > 0 {
+		c.line = line
+	}
+
 	switch s := stmt.(type) {
 	case *ast.LetStatement:
 		return c.compileLetStatement(s)
@@ -113,17 +227,17 @@ func (c *Compiler) compileStatement(stmt ast.Statement) error {
 	case *ast.ReturnStatement:
 		return c.compileReturnStatement(s)
 	case *ast.BreakStatement:
-		c.emit(Instruction{Op: OpBreak})
-		return nil
+		return c.compileBreakStatement()
 	case *ast.ContinueStatement:
-		c.emit(Instruction{Op: OpContinue})
-		return nil
+		return c.compileContinueStatement()
 	case *ast.ExpressionStatement:
 		if err := c.compileExpression(s.Expression); err != nil {
 			return err
 		}
 		c.emit(Instruction{Op: OpPop}) // Discard expression result
 		return nil
+	case *ast.BlockStatement:
+		return c.compileBlock(s)
 	case *ast.IfStatement:
 		return c.compileIfStatement(s)
 	case *ast.WhileStatement:
@@ -132,109 +246,179 @@ func (c *Compiler) compileStatement(stmt ast.Statement) error {
 		return c.compileForStatement(s)
 	case *ast.FunctionStatement:
 		return c.compileFunctionStatement(s)
+	case *ast.StaticMethodStatement:
+		return c.compileStaticMethodStatement(s)
+	case *ast.StaticPropertyStatement:
+		return c.compileStaticPropertyStatement(s)
+	case *ast.ClassStatement:
+		return c.compileClassStatement(s.Name, s.SuperClasses, s.Interfaces, s.Body, false)
+	case *ast.AbstractClassStatement:
+		return c.compileClassStatement(s.Name, s.SuperClasses, nil, s.Body, true)
+	case *ast.AbstractMethodStatement:
+		// Abstract methods only declare a signature
+		return nil
+	case *ast.InterfaceStatement:
+		return c.compileInterfaceStatement(s)
+	case *ast.EnumStatement:
+		return c.compileEnumStatement(s)
+	case *ast.ImportStatement:
+		return c.compileImportStatement(s)
+	case *ast.UnsafeStatement:
+		return c.compileBlock(s.Body)
+	case *ast.TryStatement:
+		return c.compileTryStatement(s)
+	case *ast.ThrowStatement:
+		if err := c.compileExpression(s.Value); err != nil {
+			return err
+		}
+		c.emit(Instruction{Op: OpThrow})
+		return nil
 	default:
-		return fmt.Errorf("unknown statement type: %T", stmt)
+		return errors.New(i18n.T("runtime.unknown_statement", stmt))
+	}
+}
+
+func (c *Compiler) compileBlock(block *ast.BlockStatement) error {
+	if block == nil {
+		return nil
+	}
+	for _, s := range block.Statements {
+		if err := c.compileStatement(s); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 func (c *Compiler) compileLetStatement(stmt *ast.LetStatement) error {
 	// Compile the value expression
-	if err := c.compileExpression(stmt.Value); err != nil {
+	if err := c.compileValue(stmt.Value); err != nil {
 		return err
 	}
+	if c.typeChecks && stmt.Type != nil {
+		c.emit(Instruction{Op: OpCheckType, Operand: c.addConstant(stmt.Type.String()), Operand2: checkLet, Name: stmt.Name.Value})
+	}
 
 	// Define the variable
-	slot := c.symbolTable.Define(stmt.Name.Value)
-	c.emit(Instruction{
-		Op:      OpSetLocal,
-		Operand: slot,
-		Name:    stmt.Name.Value,
-	})
-
+	c.defineVariable(stmt.Name.Value)
 	return nil
 }
 
 func (c *Compiler) compileConstStatement(stmt *ast.ConstStatement) error {
 	// Same as let for now (const checking done in sema phase)
-	if err := c.compileExpression(stmt.Value); err != nil {
+	if err := c.compileValue(stmt.Value); err != nil {
 		return err
 	}
+	c.defineVariable(stmt.Name.Value)
+	return nil
+}
 
-	slot := c.symbolTable.Define(stmt.Name.Value)
-	c.emit(Instruction{
-		Op:      OpSetLocal,
-		Operand: slot,
-		Name:    stmt.Name.Value,
-	})
-
+func (c *Compiler) compileStaticPropertyStatement(stmt *ast.StaticPropertyStatement) error {
+	// Static properties are plain variables, like in the interpreter
+	if err := c.compileValue(stmt.Value); err != nil {
+		return err
+	}
+	c.defineVariable(stmt.Name.Value)
 	return nil
 }
 
-func (c *Compiler) compileReturnStatement(stmt *ast.ReturnStatement) error {
-	if stmt.ReturnValue != nil {
-		if err := c.compileExpression(stmt.ReturnValue); err != nil {
-			return err
-		}
-	} else {
+// compileValue compiles an optional expression, pushing nil if it is absent
+func (c *Compiler) compileValue(expr ast.Expression) error {
+	if expr == nil {
 		c.emit(Instruction{Op: OpNil})
+		return nil
+	}
+	return c.compileExpression(expr)
+}
+
+func (c *Compiler) compileReturnStatement(stmt *ast.ReturnStatement) error {
+	if err := c.compileValue(stmt.ReturnValue); err != nil {
+		return err
+	}
+	if c.returnType != "" {
+		c.emit(Instruction{Op: OpCheckType, Operand: c.addConstant(c.returnType), Operand2: checkReturn, Name: c.name})
+	}
+	if err := c.unwindTries(0); err != nil {
+		return err
 	}
 	c.emit(Instruction{Op: OpReturn})
 	return nil
 }
 
-func (c *Compiler) compileIfStatement(stmt *ast.IfStatement) error {
-	// Compile condition
-	if err := c.compileExpression(stmt.Condition); err != nil {
+func (c *Compiler) compileBreakStatement() error {
+	if len(c.loops) == 0 {
+		return errors.New(i18n.T("runtime.break_outside"))
+	}
+	loop := c.loops[len(c.loops)-1]
+	if err := c.unwindTries(loop.tries); err != nil {
 		return err
 	}
+	loop.breaks = append(loop.breaks, c.emitJump(OpJump))
+	return nil
+}
 
-	// Jump if false (we'll patch this later)
-	jumpIfFalse := c.emitJump(OpJumpIfFalse)
+func (c *Compiler) compileContinueStatement() error {
+	if len(c.loops) == 0 {
+		return errors.New(i18n.T("runtime.continue_outside"))
+	}
+	loop := c.loops[len(c.loops)-1]
+	if err := c.unwindTries(loop.tries); err != nil {
+		return err
+	}
+	c.emitLoop(loop.start)
+	return nil
+}
 
-	// Compile consequence
-	for _, s := range stmt.Consequence.Statements {
-		if err := c.compileStatement(s); err != nil {
+// unwindTries pops handlers down to depth, running finally blocks on the way
+func (c *Compiler) unwindTries(depth int) error {
+	tries := c.tries
+	defer func() { c.tries = tries }()
+
+	for i := len(tries) - 1; i >= depth; i-- {
+		c.tries = tries[:i]
+		c.emit(Instruction{Op: OpEndTry})
+		if err := c.compileBlock(tries[i].finally); err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	// Jump over alternative (if exists)
-	jumpOver := c.emitJump(OpJump)
+func (c *Compiler) compileIfStatement(stmt *ast.IfStatement) error {
+	var endJumps []int
 
-	// Patch jump-if-false to here
-	c.patchJump(jumpIfFalse)
+	// Compile condition
+	if err := c.compileExpression(stmt.Condition); err != nil {
+		return err
+	}
+	next := c.emitJump(OpJumpIfFalse)
+	if err := c.compileBlock(stmt.Consequence); err != nil {
+		return err
+	}
+	endJumps = append(endJumps, c.emitJump(OpJump))
+	c.patchJump(next)
 
-	// Compile elif/else
-	if len(stmt.Elif) > 0 || stmt.Alternative != nil {
-		// Handle elif chains
-		for _, elif := range stmt.Elif {
-			if err := c.compileExpression(elif.Condition); err != nil {
-				return err
-			}
-			elifJumpIfFalse := c.emitJump(OpJumpIfFalse)
-			for _, s := range elif.Consequence.Statements {
-				if err := c.compileStatement(s); err != nil {
-					return err
-				}
-			}
-			elifJumpOver := c.emitJump(OpJump)
-			c.patchJump(elifJumpIfFalse)
-			defer c.patchJump(elifJumpOver)
+	// Handle elif chains
+	for _, elif := range stmt.Elif {
+		if err := c.compileExpression(elif.Condition); err != nil {
+			return err
 		}
-
-		// Compile else
-		if stmt.Alternative != nil {
-			for _, s := range stmt.Alternative.Statements {
-				if err := c.compileStatement(s); err != nil {
-					return err
-				}
-			}
+		next := c.emitJump(OpJumpIfFalse)
+		if err := c.compileBlock(elif.Consequence); err != nil {
+			return err
 		}
+		endJumps = append(endJumps, c.emitJump(OpJump))
+		c.patchJump(next)
 	}
 
-	// Patch jump-over to here
-	c.patchJump(jumpOver)
+	// Compile else
+	if err := c.compileBlock(stmt.Alternative); err != nil {
+		return err
+	}
 
+	for _, jump := range endJumps {
+		c.patchJump(jump)
+	}
 	return nil
 }
 
@@ -249,75 +433,242 @@ func (c *Compiler) compileWhileStatement(stmt *ast.WhileStatement) error {
 	// Jump if false (exit loop)
 	exitJump := c.emitJump(OpJumpIfFalse)
 
-	// Compile body
-	for _, s := range stmt.Body.Statements {
-		if err := c.compileStatement(s); err != nil {
-			return err
-		}
+	loop := c.enterLoop(loopStart)
+	if err := c.compileBlock(stmt.Body); err != nil {
+		return err
 	}
-
-	// Loop back to start
 	c.emitLoop(loopStart)
+	c.leaveLoop()
 
-	// Patch exit jump
 	c.patchJump(exitJump)
-
+	for _, jump := range loop.breaks {
+		c.patchJump(jump)
+	}
 	return nil
 }
 
 func (c *Compiler) compileForStatement(stmt *ast.ForStatement) error {
-	// Compile iterable expression
+	// Compile iterable expression and replace it with an iterator
 	if err := c.compileExpression(stmt.Iterable); err != nil {
 		return err
 	}
+	c.emit(Instruction{Op: OpIter})
 
-	// For now, assume range() returns a list
-	// TODO: proper iterator protocol
+	c.enterScope()
+	slot := c.symbolTable.Define(stmt.Iterator.Value)
 
-	loopStart := len(c.instructions)
+	loopStart := c.emitJump(OpIterNext)
+	c.emit(Instruction{Op: OpSetLocal, Operand: slot, Name: stmt.Iterator.Value})
 
-	// Get next element (simplified)
-	// TODO: proper iterator handling
+	loop := c.enterLoop(loopStart)
+	if err := c.compileBlock(stmt.Body); err != nil {
+		return err
+	}
+	c.emitLoop(loopStart)
+	c.leaveLoop()
 
-	// Define loop variable
-	slot := c.symbolTable.Define(stmt.Iterator.Value)
-	c.emit(Instruction{
-		Op:      OpSetLocal,
-		Operand: slot,
-		Name:    stmt.Iterator.Value,
-	})
+	// Exhausted iterator and break both land here; drop the iterator
+	c.patchJump(loopStart)
+	for _, jump := range loop.breaks {
+		c.patchJump(jump)
+	}
+	c.emit(Instruction{Op: OpPop})
+	c.leaveScope()
+	return nil
+}
 
-	// Compile body
-	for _, s := range stmt.Body.Statements {
-		if err := c.compileStatement(s); err != nil {
+func (c *Compiler) enterLoop(start int) *loopContext {
+	loop := &loopContext{start: start, tries: len(c.tries)}
+	c.loops = append(c.loops, loop)
+	return loop
+}
+
+func (c *Compiler) leaveLoop() {
+	c.loops = c.loops[:len(c.loops)-1]
+}
+
+// compileTryStatement compiles try/catch/finally. The error message is
+// pushed as a string when control reaches the catch block.
+func (c *Compiler) compileTryStatement(stmt *ast.TryStatement) error {
+	handler := c.emitJump(OpTry)
+	c.tries = append(c.tries, tryContext{finally: stmt.Finally})
+	if err := c.compileBlock(stmt.TryBlock); err != nil {
+		return err
+	}
+	c.tries = c.tries[:len(c.tries)-1]
+	c.emit(Instruction{Op: OpEndTry})
+	if err := c.compileBlock(stmt.Finally); err != nil {
+		return err
+	}
+	endJumps := []int{c.emitJump(OpJump)}
+
+	c.patchJump(handler)
+	c.enterScope()
+	defer c.leaveScope()
+
+	if stmt.CatchClause != nil {
+		// Errors raised in the catch block still run finally
+		var rethrow int
+		if stmt.Finally != nil {
+			rethrow = c.emitJump(OpTry)
+			c.tries = append(c.tries, tryContext{finally: stmt.Finally})
+		}
+
+		if stmt.CatchClause.ErrorVar != nil {
+			name := stmt.CatchClause.ErrorVar.Value
+			slot := c.symbolTable.Define(name)
+			c.emit(Instruction{Op: OpSetLocal, Operand: slot, Name: name})
+		} else {
+			c.emit(Instruction{Op: OpPop})
+		}
+		if err := c.compileBlock(stmt.CatchClause.Body); err != nil {
 			return err
 		}
+
+		if stmt.Finally == nil {
+			endJumps = append(endJumps, c.emitJump(OpJump))
+		} else {
+			c.tries = c.tries[:len(c.tries)-1]
+			c.emit(Instruction{Op: OpEndTry})
+			if err := c.compileBlock(stmt.Finally); err != nil {
+				return err
+			}
+			endJumps = append(endJumps, c.emitJump(OpJump))
+			c.patchJump(rethrow)
+			if err := c.compileRethrow(stmt.Finally); err != nil {
+				return err
+			}
+		}
+	} else if err := c.compileRethrow(stmt.Finally); err != nil {
+		return err
 	}
 
-	// Loop back
-	c.emitLoop(loopStart)
+	for _, jump := range endJumps {
+		c.patchJump(jump)
+	}
+	return nil
+}
 
+// compileRethrow runs finally with the pending error on the stack, then raises it again
+func (c *Compiler) compileRethrow(finally *ast.BlockStatement) error {
+	slot := c.symbolTable.Define("$error")
+	c.emit(Instruction{Op: OpSetLocal, Operand: slot, Name: "$error"})
+	if err := c.compileBlock(finally); err != nil {
+		return err
+	}
+	c.emit(Instruction{Op: OpGetLocal, Operand: slot, Name: "$error"})
+	c.emit(Instruction{Op: OpThrow})
 	return nil
 }
 
 func (c *Compiler) compileFunctionStatement(stmt *ast.FunctionStatement) error {
 	funcName := stmt.Name.Value
-	arity := len(stmt.Parameters)
 
+	// Local functions are defined first so they can call themselves
+	if !c.isGlobalScope() {
+		c.symbolTable.Define(funcName)
+	}
+
+	// Decorators are pushed outermost first and applied innermost first
+	for _, decorator := range stmt.Decorators {
+		c.emitGet(decorator.Name.Value)
+	}
+
+	if err := c.compileFunction(funcName, stmt.Parameters, stmt.ReturnType, stmt.Body, stmt.Async, stmt.Coop, nil); err != nil {
+		return err
+	}
+
+	for j := len(stmt.Decorators) - 1; j >= 0; j-- {
+		decorator := stmt.Decorators[j]
+		for _, arg := range decorator.Args {
+			if err := c.compileExpression(arg); err != nil {
+				return err
+			}
+		}
+		c.emit(Instruction{Op: OpCall, Operand: 1 + len(decorator.Args), Name: decorator.Name.Value})
+	}
+
+	c.defineVariable(funcName)
+	return nil
+}
+
+func (c *Compiler) compileStaticMethodStatement(stmt *ast.StaticMethodStatement) error {
+	// Static methods are stored as plain functions, like in the interpreter
+	if err := c.compileFunction(stmt.Name.Value, stmt.Parameters, stmt.ReturnType, stmt.Body, false, false, nil); err != nil {
+		return err
+	}
+	c.defineVariable(stmt.Name.Value)
+	return nil
+}
+
+// compileFunction compiles a function body and emits a closure for it.
+// class is non-nil for methods, whose slot 0 holds self.
+func (c *Compiler) compileFunction(name string, params []*ast.FunctionParameter, returnType ast.TypeAnnotation,
+	body *ast.BlockStatement, async, coop bool, class *ast.Identifier) error {
 	// Create a new compiler for this function
 	funcCompiler := NewCompiler()
-	funcCompiler.symbolTable = NewSymbolTable(nil)
+	funcCompiler.enclosing = c
+	funcCompiler.program = c.program
+	funcCompiler.functions = c.functions
+	funcCompiler.scopeDepth = 1
+	funcCompiler.line = c.line
+	funcCompiler.typeChecks = c.typeChecks
+	funcCompiler.name = name
+	if class != nil {
+		funcCompiler.name = class.Value + "." + name
+	} else if strings.HasPrefix(name, "lambda@") {
+		funcCompiler.name = "lambda"
+	}
+	if c.typeChecks && returnType != nil && !coop {
+		// An async body returns what its promise resolves to
+		t := interpreter.NewRuntimeType(returnType)
+		if async {
+			t = t.Awaited()
+		}
+		funcCompiler.returnType = t.String()
+	}
+
+	if class != nil {
+		funcCompiler.symbolTable.Define("self")
+	} else {
+		funcCompiler.symbolTable.Define("")
+	}
 
 	// Define parameters as locals
-	for _, param := range stmt.Parameters {
+	names := make([]string, len(params))
+	variadic := false
+	for idx, param := range params {
+		names[idx] = param.Name.Value
 		funcCompiler.symbolTable.Define(param.Name.Value)
+		variadic = variadic || param.Variadic
 	}
 
-	// Compile function body
-	for _, bodyStmt := range stmt.Body.Statements {
-		if err := funcCompiler.compileStatement(bodyStmt); err != nil {
+	// Arguments are checked before defaults fill the omitted ones
+	if c.typeChecks {
+		for idx, param := range params {
+			if param.Type != nil {
+				funcCompiler.emit(Instruction{Op: OpCheckArg, Operand: funcCompiler.addConstant(param.Type.String()),
+					Operand2: idx + 1, Name: param.Name.Value})
+			}
+		}
+	}
+
+	// Default values are evaluated when the caller omitted the argument
+	for idx, param := range params {
+		if param.DefaultValue == nil || param.Variadic {
+			continue
+		}
+		skip := funcCompiler.emit(Instruction{Op: OpJumpIfArg, Operand: 9999, Operand2: idx})
+		if err := funcCompiler.compileExpression(param.DefaultValue); err != nil {
 			return err
 		}
+		funcCompiler.emit(Instruction{Op: OpSetLocal, Operand: idx + 1, Name: param.Name.Value})
+		funcCompiler.patchJump(skip)
+	}
+
+	// Compile function body
+	if err := funcCompiler.compileBlock(body); err != nil {
+		return err
 	}
 
 	// Add implicit return nil if no explicit return
@@ -326,20 +677,109 @@ func (c *Compiler) compileFunctionStatement(stmt *ast.FunctionStatement) error {
 
 	// Create compiled function
 	compiledFunc := &CompiledFunction{
-		Name:         funcName,
-		Arity:        arity,
-		Async:        stmt.Async, // Store async flag
+		Name:         name,
+		Arity:        len(params),
+		Params:       names,
+		Async:        async,
+		Coop:         coop,
+		Variadic:     variadic,
+		Method:       class != nil,
 		Instructions: funcCompiler.instructions,
+		Lines:        funcCompiler.lines,
 		Constants:    funcCompiler.constants,
-		LocalCount:   funcCompiler.symbolTable.numDefs,
+		LocalCount:   *funcCompiler.symbolTable.numDefs,
+		Upvalues:     funcCompiler.upvalues,
 	}
 
 	// Store in compiler's function table
-	c.functions[funcName] = compiledFunc
+	key := name
+	if class != nil {
+		key = class.Value + "." + name
+	}
+	for n := 2; c.functions[key] != nil; n++ {
+		key = fmt.Sprintf("%s#%d", strings.SplitN(key, "#", 2)[0], n)
+	}
+	c.functions[key] = compiledFunc
+
+	c.emit(Instruction{Op: OpClosure, Operand: c.addConstant(compiledFunc), Name: name})
+	return nil
+}
 
-	// Define function name in current scope
-	c.symbolTable.Define(funcName)
+// compileClassStatement compiles class and abstract class definitions
+func (c *Compiler) compileClassStatement(name *ast.Identifier, superClasses, interfaces []*ast.Identifier,
+	body []ast.Statement, abstract bool) error {
+	if !c.isGlobalScope() {
+		c.symbolTable.Define(name.Value)
+	}
+
+	for _, superClass := range superClasses {
+		c.emitGet(superClass.Value)
+	}
+	flags := 0
+	if abstract {
+		flags = 1
+	}
+	c.emit(Instruction{Op: OpClass, Operand: len(superClasses), Operand2: flags, Name: name.Value})
+
+	for _, member := range body {
+		switch m := member.(type) {
+		case *ast.FunctionStatement:
+			if err := c.compileFunction(m.Name.Value, m.Parameters, m.ReturnType, m.Body, m.Async, m.Coop, name); err != nil {
+				return err
+			}
+			c.emit(Instruction{Op: OpMethod, Name: m.Name.Value})
+		case *ast.AbstractMethodStatement:
+			if !abstract {
+				return errors.New(i18n.T("runtime.unsupported_member", member))
+			}
+		default:
+			return errors.New(i18n.T("runtime.unsupported_member", member))
+		}
+	}
+
+	for _, iface := range interfaces {
+		c.emitGet(iface.Value)
+		c.emit(Instruction{Op: OpImplements, Name: iface.Value})
+	}
+
+	c.defineVariable(name.Value)
+	return nil
+}
+
+func (c *Compiler) compileInterfaceStatement(stmt *ast.InterfaceStatement) error {
+	for _, parent := range stmt.Extends {
+		c.emitGet(parent.Value)
+	}
+	for _, method := range stmt.Methods {
+		c.emit(Instruction{Op: OpConstant, Operand: c.addConstant(method.Name.Value)})
+		c.emit(Instruction{Op: OpConstant, Operand: c.addConstant(int64(len(method.Parameters)))})
+	}
+	c.emit(Instruction{Op: OpInterface, Operand: len(stmt.Methods), Operand2: len(stmt.Extends), Name: stmt.Name.Value})
+	c.defineVariable(stmt.Name.Value)
+	return nil
+}
+
+func (c *Compiler) compileEnumStatement(stmt *ast.EnumStatement) error {
+	enumName := c.addConstant(stmt.Name.Value)
+	for _, variant := range stmt.Variants {
+		c.emit(Instruction{Op: OpVariant, Operand: len(variant.Payload), Operand2: enumName, Name: variant.Name.Value})
+		c.emit(Instruction{Op: OpDup})
+		c.defineVariable(variant.Name.Value)
+	}
+	c.emit(Instruction{Op: OpEnum, Operand: len(stmt.Variants), Name: stmt.Name.Value})
+	c.defineVariable(stmt.Name.Value)
+	return nil
+}
+
+func (c *Compiler) compileImportStatement(stmt *ast.ImportStatement) error {
+	c.emit(Instruction{Op: OpImport, Name: strings.Join(stmt.Path, "/")})
 
+	// import foo.bar binds "bar", import foo as f binds "f"
+	name := stmt.Path[len(stmt.Path)-1]
+	if stmt.Alias != nil {
+		name = stmt.Alias.Value
+	}
+	c.defineVariable(name)
 	return nil
 }
 
@@ -369,19 +809,62 @@ func (c *Compiler) compileExpression(expr ast.Expression) error {
 		return nil
 
 	case *ast.Identifier:
-		// Variables and non-call identifiers
-		if slot, ok := c.symbolTable.Resolve(e.Value); ok {
-			c.emit(Instruction{
-				Op:      OpGetLocal,
-				Operand: slot,
-				Name:    e.Value,
-			})
-		} else {
-			c.emit(Instruction{
-				Op:   OpGetGlobal,
-				Name: e.Value,
-			})
+		// super inside a method refers to the defining class's superclass
+		if e.Value == "super" && c.inMethod() {
+			c.emit(Instruction{Op: OpGetSuper})
+			return nil
+		}
+		c.emitGet(e.Value)
+		return nil
+
+	case *ast.ListLiteral:
+		for _, elem := range e.Elements {
+			if err := c.compileExpression(elem); err != nil {
+				return err
+			}
+		}
+		c.emit(Instruction{Op: OpList, Operand: len(e.Elements)})
+		return nil
+
+	case *ast.DictLiteral:
+		// Pairs are compiled in source order so bytecode is deterministic
+		keys := make([]ast.Expression, 0, len(e.Pairs))
+		for key := range e.Pairs {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			a, b := keys[i].Pos(), keys[j].Pos()
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Column < b.Column
+		})
+		for _, key := range keys {
+			if err := c.compileExpression(key); err != nil {
+				return err
+			}
+			if err := c.compileExpression(e.Pairs[key]); err != nil {
+				return err
+			}
+		}
+		c.emit(Instruction{Op: OpDict, Operand: len(keys)})
+		return nil
+
+	case *ast.IndexExpression:
+		if err := c.compileExpression(e.Left); err != nil {
+			return err
+		}
+		if err := c.compileExpression(e.Index); err != nil {
+			return err
+		}
+		c.emit(Instruction{Op: OpIndex})
+		return nil
+
+	case *ast.MemberExpression:
+		if err := c.compileExpression(e.Object); err != nil {
+			return err
 		}
+		c.emit(Instruction{Op: OpGetMember, Name: e.Member.Value})
 		return nil
 
 	case *ast.InfixExpression:
@@ -393,6 +876,22 @@ func (c *Compiler) compileExpression(expr ast.Expression) error {
 	case *ast.CallExpression:
 		return c.compileCallExpression(e)
 
+	case *ast.LambdaExpression:
+		c.program.lambdas++
+		name := fmt.Sprintf("lambda@%d", c.program.lambdas)
+		if err := c.compileFunction(name, e.Parameters, e.ReturnType, e.Body, false, false, nil); err != nil {
+			return err
+		}
+		// Lambdas print as <function lambda> like in the interpreter
+		c.functions[name].Name = "lambda"
+		return nil
+
+	case *ast.ArrowExpression:
+		return c.compileExpression(e.Right)
+
+	case *ast.MatchExpression:
+		return c.compileMatchExpression(e)
+
 	case *ast.AwaitExpression:
 		return c.compileAwaitExpression(e)
 
@@ -400,7 +899,7 @@ func (c *Compiler) compileExpression(expr ast.Expression) error {
 		return c.compileYieldExpression(e)
 
 	default:
-		return fmt.Errorf("unknown expression type: %T", expr)
+		return errors.New(i18n.T("runtime.unknown_expression", expr))
 	}
 }
 
@@ -408,116 +907,136 @@ func (c *Compiler) compileInfixExpression(expr *ast.InfixExpression) error {
 	// Handle assignment operators
 	if expr.Operator == "=" || expr.Operator == "+=" || expr.Operator == "-=" ||
 		expr.Operator == "*=" || expr.Operator == "/=" || expr.Operator == "%=" {
-		// Compile right side
-		if err := c.compileExpression(expr.Right); err != nil {
-			return err
-		}
+		return c.compileAssignExpression(expr)
+	}
 
-		// Get left identifier
-		ident, ok := expr.Left.(*ast.Identifier)
-		if !ok {
-			return fmt.Errorf("invalid assignment target")
-		}
+	// && and || short-circuit and yield a boolean, like the interpreter
+	if expr.Operator == "&&" || expr.Operator == "||" {
+		return c.compileLogicalExpression(expr)
+	}
 
-		// For compound assignments (+=, -=, etc.), need to load current value first
-		if expr.Operator != "=" {
-			// Load current value
-			if slot, ok := c.symbolTable.Resolve(ident.Value); ok {
-				c.emit(Instruction{Op: OpGetLocal, Operand: slot, Name: ident.Value})
-			} else {
-				c.emit(Instruction{Op: OpGetGlobal, Name: ident.Value})
-			}
+	// Compile left and right operands
+	if err := c.compileExpression(expr.Left); err != nil {
+		return err
+	}
+	if err := c.compileExpression(expr.Right); err != nil {
+		return err
+	}
 
-			// Swap stack so we have: [current, new] instead of [new, current]
-			// Then emit operation
-			switch expr.Operator {
-			case "+=":
-				c.emit(Instruction{Op: OpAdd})
-			case "-=":
-				c.emit(Instruction{Op: OpSub})
-			case "*=":
-				c.emit(Instruction{Op: OpMul})
-			case "/=":
-				c.emit(Instruction{Op: OpDiv})
-			case "%=":
-				c.emit(Instruction{Op: OpMod})
-			}
-		}
+	op, ok := binaryOps[expr.Operator]
+	if !ok {
+		return errors.New(i18n.T("runtime.unknown_operator", expr.Operator))
+	}
+	c.emit(Instruction{Op: op})
+	return nil
+}
 
-		// Store value
-		if slot, ok := c.symbolTable.Resolve(ident.Value); ok {
-			c.emit(Instruction{Op: OpSetLocal, Operand: slot, Name: ident.Value})
-		} else {
-			c.emit(Instruction{Op: OpSetGlobal, Name: ident.Value})
-		}
+// binaryOps maps infix and compound assignment operators to opcodes
+var binaryOps = map[string]OpCode{
+	"+": OpAdd, "-": OpSub, "*": OpMul, "/": OpDiv, "%": OpMod,
+	"==": OpEqual, "!=": OpNotEqual,
+	">": OpGreaterThan, ">=": OpGreaterEqual, "<": OpLessThan, "<=": OpLessEqual,
+	"+=": OpAdd, "-=": OpSub, "*=": OpMul, "/=": OpDiv, "%=": OpMod,
+}
 
-		return nil
+func (c *Compiler) compileLogicalExpression(expr *ast.InfixExpression) error {
+	if err := c.compileExpression(expr.Left); err != nil {
+		return err
 	}
 
-	// Short-circuit for && and ||
+	var shortCircuit int
 	if expr.Operator == "&&" {
-		if err := c.compileExpression(expr.Left); err != nil {
-			return err
+		shortCircuit = c.emitJump(OpJumpIfFalse)
+	} else {
+		shortCircuit = c.emitJump(OpJumpIfTrue)
+	}
+	if err := c.compileExpression(expr.Right); err != nil {
+		return err
+	}
+	c.emit(Instruction{Op: OpNot})
+	c.emit(Instruction{Op: OpNot})
+	end := c.emitJump(OpJump)
+
+	c.patchJump(shortCircuit)
+	if expr.Operator == "&&" {
+		c.emit(Instruction{Op: OpFalse})
+	} else {
+		c.emit(Instruction{Op: OpTrue})
+	}
+	c.patchJump(end)
+	return nil
+}
+
+// compileAssignExpression compiles assignments to variables, members and
+// indexes. The assigned value is left on the stack.
+func (c *Compiler) compileAssignExpression(expr *ast.InfixExpression) error {
+	compound := expr.Operator != "="
+
+	switch target := expr.Left.(type) {
+	case *ast.Identifier:
+		if compound {
+			c.emitGet(target.Value)
 		}
-		endJump := c.emitJump(OpJumpIfFalse)
-		c.emit(Instruction{Op: OpPop}) // Pop left value
 		if err := c.compileExpression(expr.Right); err != nil {
 			return err
 		}
-		c.patchJump(endJump)
+		if compound {
+			c.emit(Instruction{Op: binaryOps[expr.Operator]})
+		}
+		c.emit(Instruction{Op: OpDup})
+		if c.isUndefined(target.Value) {
+			// Assigning an undefined name inside a function creates a local
+			c.defineVariable(target.Value)
+			return nil
+		}
+		c.emitSet(target.Value)
 		return nil
-	}
 
-	if expr.Operator == "||" {
-		if err := c.compileExpression(expr.Left); err != nil {
+	case *ast.MemberExpression:
+		if err := c.compileExpression(target.Object); err != nil {
 			return err
 		}
-		endJump := c.emitJump(OpJumpIfTrue)
-		c.emit(Instruction{Op: OpPop}) // Pop left value
+		if compound {
+			c.emit(Instruction{Op: OpDup})
+			c.emit(Instruction{Op: OpGetMember, Name: target.Member.Value})
+		}
 		if err := c.compileExpression(expr.Right); err != nil {
 			return err
 		}
-		c.patchJump(endJump)
+		if compound {
+			c.emit(Instruction{Op: binaryOps[expr.Operator]})
+		}
+		c.emit(Instruction{Op: OpSetMember, Name: target.Member.Value})
 		return nil
-	}
 
-	// Compile left and right operands
-	if err := c.compileExpression(expr.Left); err != nil {
-		return err
-	}
-	if err := c.compileExpression(expr.Right); err != nil {
-		return err
-	}
-
-	// Emit operator
-	switch expr.Operator {
-	case "+":
-		c.emit(Instruction{Op: OpAdd})
-	case "-":
-		c.emit(Instruction{Op: OpSub})
-	case "*":
-		c.emit(Instruction{Op: OpMul})
-	case "/":
-		c.emit(Instruction{Op: OpDiv})
-	case "%":
-		c.emit(Instruction{Op: OpMod})
-	case "==":
-		c.emit(Instruction{Op: OpEqual})
-	case "!=":
-		c.emit(Instruction{Op: OpNotEqual})
-	case ">":
-		c.emit(Instruction{Op: OpGreaterThan})
-	case ">=":
-		c.emit(Instruction{Op: OpGreaterEqual})
-	case "<":
-		c.emit(Instruction{Op: OpLessThan})
-	case "<=":
-		c.emit(Instruction{Op: OpLessEqual})
-	default:
-		return fmt.Errorf("unknown operator: %s", expr.Operator)
+	case *ast.IndexExpression:
+		if err := c.compileExpression(target.Left); err != nil {
+			return err
+		}
+		if err := c.compileExpression(target.Index); err != nil {
+			return err
+		}
+		if compound {
+			// Re-evaluate the target for the current value
+			if err := c.compileExpression(target.Left); err != nil {
+				return err
+			}
+			if err := c.compileExpression(target.Index); err != nil {
+				return err
+			}
+			c.emit(Instruction{Op: OpIndex})
+		}
+		if err := c.compileExpression(expr.Right); err != nil {
+			return err
+		}
+		if compound {
+			c.emit(Instruction{Op: binaryOps[expr.Operator]})
+		}
+		c.emit(Instruction{Op: OpSetIndex})
+		return nil
 	}
 
-	return nil
+	return errors.New(i18n.T("runtime.invalid_assignment"))
 }
 
 func (c *Compiler) compilePrefixExpression(expr *ast.PrefixExpression) error {
@@ -530,119 +1049,165 @@ func (c *Compiler) compilePrefixExpression(expr *ast.PrefixExpression) error {
 		c.emit(Instruction{Op: OpNot})
 	case "-":
 		c.emit(Instruction{Op: OpNegate})
+	case "+":
+		// Unary plus is the identity
 	default:
-		return fmt.Errorf("unknown prefix operator: %s", expr.Operator)
+		return errors.New(i18n.T("runtime.unknown_prefix", expr.Operator))
 	}
 
 	return nil
 }
 
 func (c *Compiler) compileCallExpression(expr *ast.CallExpression) error {
-	// Check for built-in functions
-	if ident, ok := expr.Function.(*ast.Identifier); ok {
-		switch ident.Value {
-		case "print":
-			// Compile arguments
-			for _, arg := range expr.Arguments {
-				if err := c.compileExpression(arg); err != nil {
-					return err
-				}
-			}
-			c.emit(Instruction{Op: OpPrint, Operand: len(expr.Arguments)})
-			return nil
-
-		case "len":
-			if len(expr.Arguments) > 0 {
-				if err := c.compileExpression(expr.Arguments[0]); err != nil {
+	switch fn := expr.Function.(type) {
+	case *ast.Identifier:
+		// print and len get dedicated opcodes unless the program redefines them
+		if c.isUndefined(fn.Value) {
+			switch {
+			case fn.Value == "print":
+				if err := c.compileArguments(expr.Arguments); err != nil {
 					return err
 				}
-			}
-			c.emit(Instruction{Op: OpLen})
-			return nil
-
-		case "range":
-			if len(expr.Arguments) > 0 {
+				c.emit(Instruction{Op: OpPrint, Operand: len(expr.Arguments)})
+				return nil
+			case fn.Value == "len" && len(expr.Arguments) == 1:
 				if err := c.compileExpression(expr.Arguments[0]); err != nil {
 					return err
 				}
+				c.emit(Instruction{Op: OpLen})
+				return nil
 			}
-			c.emit(Instruction{Op: OpRange})
-			return nil
 		}
 
-		// User-defined function call
-		// Push function name as constant (for OpCall to lookup)
-		idx := c.addConstant(ident.Value)
-		c.emit(Instruction{Op: OpConstant, Operand: idx})
-
-		// Compile arguments
-		for _, arg := range expr.Arguments {
-			if err := c.compileExpression(arg); err != nil {
-				return err
-			}
+	case *ast.MemberExpression:
+		// Method calls avoid allocating a bound method
+		op := OpInvoke
+		if ident, ok := fn.Object.(*ast.Identifier); ok && ident.Value == "super" && c.inMethod() {
+			op = OpSuperInvoke
+			c.emitGet("self")
+		} else if err := c.compileExpression(fn.Object); err != nil {
+			return err
 		}
-
-		c.emit(Instruction{
-			Op:      OpCall,
-			Operand: len(expr.Arguments),
-		})
-
+		if err := c.compileArguments(expr.Arguments); err != nil {
+			return err
+		}
+		c.emit(Instruction{Op: op, Operand: len(expr.Arguments), Name: fn.Member.Value})
 		return nil
 	}
 
-	// Non-identifier function (lambda, etc.)
 	if err := c.compileExpression(expr.Function); err != nil {
 		return err
 	}
-
-	// Compile arguments
-	for _, arg := range expr.Arguments {
-		if err := c.compileExpression(arg); err != nil {
-			return err
-		}
+	if err := c.compileArguments(expr.Arguments); err != nil {
+		return err
 	}
-
 	c.emit(Instruction{
 		Op:      OpCall,
 		Operand: len(expr.Arguments),
+		Name:    expr.Function.String(),
 	})
+	return nil
+}
 
+func (c *Compiler) compileArguments(args []ast.Expression) error {
+	for _, arg := range args {
+		if err := c.compileExpression(arg); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (c *Compiler) compileAssignExpression(expr *ast.InfixExpression) error {
-	// Compile right side
-	if err := c.compileExpression(expr.Right); err != nil {
-		return err
+// isUndefined reports whether name is not a known variable
+func (c *Compiler) isUndefined(name string) bool {
+	if c.program.globals[name] {
+		return false
+	}
+	if _, ok := c.symbolTable.Resolve(name); ok {
+		return false
+	}
+	if c.resolveUpvalue(name) >= 0 {
+		return false
 	}
+	return c.program.outer == nil || !c.program.outer(name)
+}
 
-	// Get left identifier
-	ident, ok := expr.Left.(*ast.Identifier)
-	if !ok {
-		return fmt.Errorf("invalid assignment target")
+// isGlobalScope reports whether definitions become globals
+func (c *Compiler) isGlobalScope() bool {
+	return c.enclosing == nil && c.scopeDepth == 0
+}
+
+// inMethod reports whether self is visible from the code being compiled
+func (c *Compiler) inMethod() bool {
+	for comp := c; comp != nil; comp = comp.enclosing {
+		if _, ok := comp.symbolTable.Resolve("self"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// defineVariable pops the top of the stack into a new variable
+func (c *Compiler) defineVariable(name string) {
+	if c.isGlobalScope() {
+		c.emit(Instruction{Op: OpSetGlobal, Name: name})
+		return
 	}
+	slot := c.symbolTable.Define(name)
+	c.emit(Instruction{Op: OpSetLocal, Operand: slot, Name: name})
+}
 
-	// Emit set instruction
-	if slot, ok := c.symbolTable.Resolve(ident.Value); ok {
-		c.emit(Instruction{
-			Op:      OpSetLocal,
-			Operand: slot,
-			Name:    ident.Value,
-		})
+// emitGet pushes a variable, resolving locals, then upvalues, then globals
+func (c *Compiler) emitGet(name string) {
+	if slot, ok := c.symbolTable.Resolve(name); ok {
+		c.emit(Instruction{Op: OpGetLocal, Operand: slot, Name: name})
+	} else if idx := c.resolveUpvalue(name); idx >= 0 {
+		c.emit(Instruction{Op: OpGetUpvalue, Operand: idx, Name: name})
 	} else {
-		c.emit(Instruction{
-			Op:   OpSetGlobal,
-			Name: ident.Value,
-		})
+		c.emit(Instruction{Op: OpGetGlobal, Name: name})
+	}
+}
+
+// emitSet pops the top of the stack into an existing variable
+func (c *Compiler) emitSet(name string) {
+	if slot, ok := c.symbolTable.Resolve(name); ok {
+		c.emit(Instruction{Op: OpSetLocal, Operand: slot, Name: name})
+	} else if idx := c.resolveUpvalue(name); idx >= 0 {
+		c.emit(Instruction{Op: OpSetUpvalue, Operand: idx, Name: name})
+	} else {
+		c.emit(Instruction{Op: OpSetGlobal, Name: name})
 	}
+}
 
-	return nil
+// resolveUpvalue finds name in an enclosing function and captures it
+func (c *Compiler) resolveUpvalue(name string) int {
+	if c.enclosing == nil {
+		return -1
+	}
+	if slot, ok := c.enclosing.symbolTable.Resolve(name); ok {
+		return c.addUpvalue(slot, true)
+	}
+	if idx := c.enclosing.resolveUpvalue(name); idx >= 0 {
+		return c.addUpvalue(idx, false)
+	}
+	return -1
+}
+
+func (c *Compiler) addUpvalue(index int, local bool) int {
+	for i, up := range c.upvalues {
+		if up.Index == index && up.Local == local {
+			return i
+		}
+	}
+	c.upvalues = append(c.upvalues, UpvalueRef{Index: index, Local: local})
+	return len(c.upvalues) - 1
 }
 
 // Helper methods
 func (c *Compiler) emit(ins Instruction) int {
 	pos := len(c.instructions)
 	c.instructions = append(c.instructions, ins)
+	c.lines = append(c.lines, c.line)
 	return pos
 }
 
@@ -658,10 +1223,9 @@ func (c *Compiler) emitJump(op OpCode) int {
 	})
 }
 
+// patchJump points the jump at offset to the next instruction
 func (c *Compiler) patchJump(offset int) {
-	jump := len(c.instructions) - offset
 	c.instructions[offset].Operand = len(c.instructions)
-	_ = jump // For backward compat
 }
 
 func (c *Compiler) emitLoop(loopStart int) {
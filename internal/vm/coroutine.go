@@ -0,0 +1,295 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+)
+
+// Coroutines. Calling a coop or async function does not run it on the
+// caller's frame: its arguments are moved into a coroutine, a heap object
+// that owns the frame while it is suspended. Resuming copies the frame
+// back onto the VM stack and runs it until it returns or suspends again,
+// so no goroutine is needed per call.
+//
+// A coop function suspends at every yield and is resumed by the next()
+// of its generator. An async function starts right away and suspends at
+// an await of a pending promise; it is put on the ready queue once that
+// promise settles. Like the states of ir.AsyncTransformer, each await is a
+// resume point, but the VM saves the frame instead of rewriting the
+// function into a state machine.
+//
+// Native async functions run on a goroutine each and report their result
+// back over vm.settled, which the VM reads when it has no
+// ready coroutines left.
+
+// coroutineState is the state of a coroutine
+type coroutineState int
+
+const (
+	coroutineSuspended coroutineState = iota
+	coroutineRunning
+	coroutineDone
+)
+
+// coroutine is a suspended coop or async call
+type coroutine struct {
+	closure *Closure
+	state   coroutineState
+	started bool
+	ip      int
+	argc    int
+
+	stack    []Value    // frame slots and operands, starting at the callee
+	handlers []handler  // try blocks of the frame, relative to the frame
+	upvalues []*Upvalue // captured variables pointing into stack
+
+	promise *interpreter.Promise // result of an async call
+
+	// value or error the coroutine resumes with when it is ready
+	resumeValue Value
+	resumeErr   error
+}
+
+// settlement is the result of a native async call
+type settlement struct {
+	promise *interpreter.Promise
+	value   interpreter.Value
+	err     error
+}
+
+// startCoroutine turns the call of a coop or async closure at base into a
+// coroutine and leaves a generator or promise in place of the callee
+func (vm *VM) startCoroutine(closure *Closure, base, argc int) error {
+	co := &coroutine{
+		closure: closure,
+		argc:    argc,
+		stack:   append([]Value(nil), vm.stack[base:vm.sp]...),
+	}
+	vm.sp = base
+
+	if closure.Fn.Coop {
+		vm.push(vm.generator(co))
+		return nil
+	}
+
+	co.promise = vm.newPromise()
+	vm.step(co)
+	vm.push(co.promise)
+	return nil
+}
+
+// generator returns the generator object of a coop call. Like the
+// interpreter's, it is a dict with a next() function returning the
+// yielded values and then nil.
+func (vm *VM) generator(co *coroutine) interpreter.Value {
+	return &interpreter.Dict{Pairs: map[string]interpreter.Value{
+		"next": &interpreter.Function{
+			Name: "next",
+			Body: func(*interpreter.Environment) (interpreter.Value, error) {
+				switch co.state {
+				case coroutineDone:
+					return &interpreter.Nil{}, nil
+				case coroutineRunning:
+					return nil, &interpreter.RuntimeError{Message: i18n.T("runtime.generator_running")}
+				}
+				result, err := vm.resume(co, Value{}, nil)
+				if err != nil || co.state == coroutineDone {
+					return &interpreter.Nil{}, err
+				}
+				return result, nil
+			},
+		},
+	}}
+}
+
+// step resumes a ready async coroutine and settles its promise once it
+// has finished
+func (vm *VM) step(co *coroutine) {
+	val, err := vm.resume(co, co.resumeValue, co.resumeErr)
+	co.resumeValue, co.resumeErr = Value{}, nil
+	if co.state == coroutineDone {
+		vm.settle(co.promise, val, err)
+	}
+}
+
+// resume runs a coroutine until it returns or suspends. It returns the
+// value yielded or returned; a coroutine that awaits returns nil.
+func (vm *VM) resume(co *coroutine, val Value, err error) (interpreter.Value, error) {
+	base := len(vm.frames)
+	slot := vm.sp
+	for _, v := range co.stack {
+		vm.pushValue(v)
+	}
+	for _, up := range co.upvalues {
+		up.stack = &vm.stack
+		up.index += slot
+		vm.openUpvalues = append(vm.openUpvalues, up)
+	}
+	for _, h := range co.handlers {
+		vm.handlers = append(vm.handlers, handler{frame: base, sp: h.sp + slot, catchIP: h.catchIP})
+	}
+	co.upvalues, co.handlers = co.upvalues[:0], co.handlers[:0]
+
+	frame := vm.enterFrame(co.closure, slot, co.argc)
+	frame.ip = co.ip
+	frame.co = co
+	co.state = coroutineRunning
+
+	// The value of the yield or await expression the coroutine stopped at
+	if co.started {
+		if err != nil {
+			if err = vm.recover(err, base); err != nil {
+				co.state = coroutineDone
+				return nil, err
+			}
+		} else {
+			vm.pushValue(val)
+		}
+	}
+	co.started = true
+
+	result, err := vm.run(base)
+	if err != nil {
+		co.state = coroutineDone
+	}
+	return result, err
+}
+
+// suspend saves the current frame, which belongs to a coroutine, and pops
+// it. result is left for the code that resumed the coroutine.
+func (vm *VM) suspend(frame *CallFrame, result Value) {
+	top := len(vm.frames) - 1
+	co := frame.co
+	co.ip = frame.ip
+	co.stack = append(co.stack[:0], vm.stack[frame.base:vm.sp]...)
+
+	n := len(vm.handlers)
+	for n > 0 && vm.handlers[n-1].frame == top {
+		n--
+	}
+	for _, h := range vm.handlers[n:] {
+		co.handlers = append(co.handlers, handler{sp: h.sp - frame.base, catchIP: h.catchIP})
+	}
+	vm.handlers = vm.handlers[:n]
+
+	open := vm.openUpvalues[:0]
+	for _, up := range vm.openUpvalues {
+		if up.index >= frame.base {
+			up.stack = &co.stack
+			up.index -= frame.base
+			co.upvalues = append(co.upvalues, up)
+		} else {
+			open = append(open, up)
+		}
+	}
+	vm.openUpvalues = open
+
+	co.state = coroutineSuspended
+	vm.sp = frame.base
+	vm.frames = vm.frames[:top]
+	vm.pushValue(result)
+}
+
+// await returns the value of a promise. Inside an async function a pending
+// promise suspends the coroutine; the caller then sees suspended as true.
+// Elsewhere the VM runs other coroutines until the promise settles.
+func (vm *VM) await(frame *CallFrame, promise *interpreter.Promise) (val Value, suspended bool, err error) {
+	if waiters, ok := vm.waiters[promise]; ok && promise.State == "pending" {
+		if frame.co != nil && frame.closure.Fn.Async {
+			vm.waiters[promise] = append(waiters, frame.co)
+			vm.suspend(frame, Value{})
+			return Value{}, true, nil
+		}
+		if err := vm.runUntil(promise); err != nil {
+			return Value{}, false, err
+		}
+	}
+
+	// Promises made outside the VM settle on their own goroutine
+	result, err := promise.Await()
+	if err != nil {
+		return Value{}, false, err
+	}
+	return unbox(result), false, nil
+}
+
+// newPromise returns a pending promise settled by the VM
+func (vm *VM) newPromise() *interpreter.Promise {
+	promise := &interpreter.Promise{State: "pending"}
+	vm.waiters[promise] = nil
+	return promise
+}
+
+// settle resolves or rejects a promise and readies the coroutines
+// awaiting it
+func (vm *VM) settle(promise *interpreter.Promise, val interpreter.Value, err error) {
+	if err != nil {
+		promise.State, promise.Error = "rejected", err
+	} else {
+		if val == nil {
+			val = &interpreter.Nil{}
+		}
+		promise.State, promise.Value = "fulfilled", val
+	}
+
+	for _, co := range vm.waiters[promise] {
+		if err != nil {
+			co.resumeErr = err
+		} else {
+			co.resumeValue = unbox(val)
+		}
+		vm.ready = append(vm.ready, co)
+	}
+	delete(vm.waiters, promise)
+}
+
+// runUntil runs ready coroutines until promise settles. A nil promise
+// runs until no coroutine or native call is left.
+func (vm *VM) runUntil(promise *interpreter.Promise) error {
+	for promise == nil || promise.State == "pending" {
+		switch {
+		case len(vm.ready) > 0:
+			co := vm.ready[0]
+			vm.ready = vm.ready[1:]
+			vm.step(co)
+
+		case vm.inflight > 0:
+			s := <-vm.settled
+			vm.inflight--
+			vm.settle(s.promise, s.value, s.err)
+
+		case promise == nil:
+			return nil
+
+		default:
+			return &interpreter.RuntimeError{Message: i18n.T("runtime.never_settles")}
+		}
+	}
+	return nil
+}
+
+// spawn runs a native async function on its own goroutine and returns
+// its promise. The builtins block, so a worker pool sized by the CPUs
+// would run them one after another on small machines.
+func (vm *VM) spawn(body func() (interpreter.Value, error)) *interpreter.Promise {
+	promise := vm.newPromise()
+	if vm.settled == nil {
+		vm.settled = make(chan settlement, 64)
+	}
+
+	vm.inflight++
+	go func() {
+		var value interpreter.Value
+		var err error
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+			vm.settled <- settlement{promise: promise, value: value, err: err}
+		}()
+		value, err = body()
+	}()
+	return promise
+}
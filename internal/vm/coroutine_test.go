@@ -0,0 +1,95 @@
+package vm
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestCoroutines(t *testing.T) {
+	file := strconv.Quote(filepath.Join(t.TempDir(), "note.txt"))
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"generator exhaustion", `coop function count(n)
+  let i = 0
+  while i < n
+    yield i
+    i += 1
+  end
+  return "done"
+end
+let g = count(2)
+print(g.next(), g.next(), g.next(), g.next())`, "0 1 nil nil\n"},
+		{"generator state", `coop function fib()
+  let a = 0
+  let b = 1
+  while true
+    yield a
+    let next = a + b
+    a = b
+    b = next
+  end
+end
+let f = fib()
+let g = fib()
+let s = ""
+for i in range(6)
+  s = s + str(f.next()) + " "
+end
+print(s, g.next())`, "0 1 1 2 3 5  0\n"},
+		{"interleaved awaits", `async function work(name, ms)
+  print("start " + name)
+  await time_sleep_async(ms)
+  print("end " + name)
+  return name
+end
+async function main
+  let slow = work("slow", 100)
+  let fast = work("fast", 1)
+  print(await slow, await fast)
+end`, "start slow\nstart fast\nend fast\nend slow\nslow fast\n"},
+		{"rejected await", `async function fail(ms)
+  await time_sleep_async(ms)
+  throw "boom"
+end
+async function guarded()
+  let n = 1
+  try
+    await fail(1)
+    n = 2
+  catch e
+    print("caught", e, n)
+  end
+  return n
+end
+async function main
+  print(await guarded())
+  try
+    await fs_read_text_async("/no/such/file")
+  catch e
+    print("native rejected")
+  end
+end`, "caught boom 1\n1\nnative rejected\n"},
+		{"native settlement", `async function main
+  let w = fs_write_text_async(` + file + `, "hello")
+  let s = time_sleep_async(1)
+  await s
+  await w
+  let r = fs_read_text_async(` + file + `)
+  let later = time_sleep_async(1)
+  print(await r, await r)
+end`, "hello hello\n"},
+	}
+
+	for _, tt := range tests {
+		for level := 0; level <= MaxOptLevel; level++ {
+			got, err := run(t, compile(t, tt.source, level), "test.sky")
+			if err != nil || got != tt.want {
+				t.Errorf("%s -O%d: printed %q (%v), want %q", tt.name, level, got, err, tt.want)
+			}
+		}
+	}
+}
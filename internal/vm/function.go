@@ -1,13 +1,33 @@
 package vm
 
+import "github.com/mburakmmm/sky-lang/internal/interpreter"
+
 // CompiledFunction represents a compiled function with its bytecode
 type CompiledFunction struct {
 	Name         string
-	Arity        int  // number of parameters
-	Async        bool // async function flag
+	Arity        int      // number of parameters
+	Params       []string // parameter names
+	Async        bool     // async function flag
+	Coop         bool     // coroutine/generator function flag
+	Variadic     bool     // last parameter collects remaining arguments
+	Method       bool     // slot 0 holds self
 	Instructions []Instruction
+	Lines        []int // source line of each instruction
 	Constants    []interface{}
-	LocalCount   int // number of local variables
+	LocalCount   int          // number of local slots, including slot 0 and parameters
+	Upvalues     []UpvalueRef // variables captured from enclosing functions
+
+	values []Value                    // constants converted to stack values
+	code   []uint32                   // Instructions in the dense form executed by the VM
+	names  []string                   // names referenced from code
+	caches []inlineCache              // inline caches of name lookups; index 0 is unused
+	types  []*interpreter.RuntimeType // parsed type constants of runtime type checks
+}
+
+// UpvalueRef describes where a closure captures a variable from
+type UpvalueRef struct {
+	Index int  // local slot in the enclosing function, or its upvalue index
+	Local bool // true if Index refers to an enclosing local
 }
 
 // NewCompiledFunction creates a new compiled function
@@ -20,3 +40,125 @@ func NewCompiledFunction(name string, arity int) *CompiledFunction {
 		LocalCount:   0,
 	}
 }
+
+// constant returns constant idx as a stack value
+func (fn *CompiledFunction) constant(idx int) Value {
+	if fn.values == nil {
+		fn.values = make([]Value, len(fn.Constants))
+		for i, c := range fn.Constants {
+			fn.values[i] = unbox(toValue(c))
+		}
+	}
+	return fn.values[idx]
+}
+
+// Dense instruction encoding. Each instruction starts with a word holding
+// the opcode in the low byte and flags for the operands that follow; zero
+// operands are omitted. A small non-negative Operand is stored in the top
+// bits of the first word. Jump targets are word offsets and names are
+// indexes into fn.names.
+const (
+	wordOperand   = 1 << 8  // Operand follows
+	wordOperand2  = 1 << 9  // Operand2 follows
+	wordName      = 1 << 10 // name index follows
+	wordInline    = 1 << 11 // Operand is stored in the top bits
+	inlineShift   = 12
+	maxInlineOper = 1<<(32-inlineShift) - 1
+)
+
+// assemble encodes Instructions into the dense form and allocates their
+// inline caches
+func (fn *CompiledFunction) assemble() {
+	// Cached instructions carry their cache index in Operand2. Index 0 is
+	// never used, so the index is always encoded.
+	instructions := make([]Instruction, len(fn.Instructions))
+	fn.caches = make([]inlineCache, 1)
+	for i, ins := range fn.Instructions {
+		if cacheable(ins.Op) {
+			ins.Operand2 = len(fn.caches)
+			fn.caches = append(fn.caches, inlineCache{})
+		}
+		instructions[i] = ins
+	}
+
+	// Word offset of every instruction, for jump targets
+	offsets := make([]int, len(instructions)+1)
+	size := 0
+	for i, ins := range instructions {
+		offsets[i] = size
+		size += encodedSize(ins)
+	}
+	offsets[len(instructions)] = size
+
+	nameIndex := make(map[string]uint32)
+	code := make([]uint32, 0, size)
+	for _, ins := range instructions {
+		if hasTarget(ins.Op) {
+			ins.Operand = offsets[ins.Operand]
+		}
+
+		word := uint32(ins.Op)
+		switch {
+		case ins.Operand > 0 && ins.Operand <= maxInlineOper && !hasTarget(ins.Op):
+			word |= wordInline | uint32(ins.Operand)<<inlineShift
+		case ins.Operand != 0:
+			word |= wordOperand
+		}
+		if ins.Operand2 != 0 {
+			word |= wordOperand2
+		}
+		if ins.Name != "" {
+			word |= wordName
+		}
+
+		code = append(code, word)
+		if word&wordOperand != 0 {
+			code = append(code, uint32(int32(ins.Operand)))
+		}
+		if word&wordOperand2 != 0 {
+			code = append(code, uint32(int32(ins.Operand2)))
+		}
+		if word&wordName != 0 {
+			idx, ok := nameIndex[ins.Name]
+			if !ok {
+				idx = uint32(len(fn.names))
+				nameIndex[ins.Name] = idx
+				fn.names = append(fn.names, ins.Name)
+			}
+			code = append(code, idx)
+		}
+	}
+	fn.code = code
+}
+
+// encodedSize returns the number of words ins occupies. Jump targets are
+// assumed to need their own word, since offsets are not known yet.
+func encodedSize(ins Instruction) int {
+	size := 1
+	if ins.Operand != 0 && (hasTarget(ins.Op) || ins.Operand < 0 || ins.Operand > maxInlineOper) {
+		size++
+	}
+	if ins.Operand2 != 0 {
+		size++
+	}
+	if ins.Name != "" {
+		size++
+	}
+	return size
+}
+
+// toValue converts a raw constant to a runtime value
+func toValue(c interface{}) interpreter.Value {
+	switch v := c.(type) {
+	case int64:
+		return &interpreter.Integer{Value: v}
+	case float64:
+		return &interpreter.Float{Value: v}
+	case string:
+		return &interpreter.String{Value: v}
+	case bool:
+		return &interpreter.Boolean{Value: v}
+	default:
+		return &interpreter.Nil{}
+	}
+}
@@ -0,0 +1,105 @@
+package vm
+
+import "testing"
+
+// TestAssemble decodes the dense form of every function of the
+// conformance programs the way the VM does and compares it with the
+// instructions it was assembled from
+func TestAssemble(t *testing.T) {
+	for _, file := range programs(t) {
+		for level := 0; level <= MaxOptLevel; level++ {
+			bc := compileFile(t, file, level)
+			for _, fn := range allFunctions(bc) {
+				checkAssembled(t, fn)
+			}
+		}
+	}
+
+	// Operands that do not fit in the first word
+	fn := &CompiledFunction{Name: "wide", Instructions: []Instruction{
+		{Op: OpConstant, Operand: maxInlineOper + 1},
+		{Op: OpGetLocal, Operand: maxInlineOper},
+		{Op: OpTestVariant, Operand: -1, Operand2: -1, Name: "Empty"},
+		{Op: OpJump, Operand: 0},
+		{Op: OpReturn},
+	}}
+	checkAssembled(t, fn)
+}
+
+func checkAssembled(t *testing.T, fn *CompiledFunction) {
+	t.Helper()
+	fn.code, fn.names, fn.caches = nil, nil, nil
+	fn.assemble()
+
+	offsets := make(map[int]int) // word offset -> instruction index
+	ip, caches := 0, 0
+	for i, want := range fn.Instructions {
+		offsets[ip] = i
+		word := fn.code[ip]
+		ip++
+		got := Instruction{Op: OpCode(word)}
+		if word&wordInline != 0 {
+			got.Operand = int(word >> inlineShift)
+		} else if word&wordOperand != 0 {
+			got.Operand = int(int32(fn.code[ip]))
+			ip++
+		}
+		if word&wordOperand2 != 0 {
+			got.Operand2 = int(int32(fn.code[ip]))
+			ip++
+		}
+		if word&wordName != 0 {
+			got.Name = fn.names[fn.code[ip]]
+			ip++
+		}
+
+		if cacheable(want.Op) {
+			caches++
+			if got.Operand2 != caches {
+				t.Errorf("%s@%d: cache index %d, want %d", fn.Name, i, got.Operand2, caches)
+			}
+			got.Operand2 = want.Operand2
+		}
+		if hasTarget(want.Op) {
+			// Targets are checked below, once every offset is known
+			got.Operand = want.Operand
+		}
+		if got != want {
+			t.Errorf("%s@%d: decoded %v, want %v", fn.Name, i, got, want)
+		}
+	}
+	if ip != len(fn.code) {
+		t.Errorf("%s: decoded %d words of %d", fn.Name, ip, len(fn.code))
+	}
+	if len(fn.caches) != caches+1 {
+		t.Errorf("%s: %d caches for %d cached instructions", fn.Name, len(fn.caches)-1, caches)
+	}
+
+	// Every jump lands on the first word of its target instruction
+	ip = 0
+	for _, ins := range fn.Instructions {
+		word := fn.code[ip]
+		if hasTarget(ins.Op) {
+			target := 0 // a jump to the first word has no operand
+			if word&wordOperand != 0 {
+				target = int(int32(fn.code[ip+1]))
+			}
+			if idx, ok := offsets[target]; !ok || idx != ins.Operand {
+				t.Errorf("%s: jump to word %d, want instruction %d", fn.Name, target, ins.Operand)
+			}
+		}
+		ip += encodedLength(word)
+	}
+}
+
+// encodedLength returns the number of words of the instruction starting
+// with word
+func encodedLength(word uint32) int {
+	n := 1
+	for _, flag := range []uint32{wordOperand, wordOperand2, wordName} {
+		if word&flag != 0 {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,144 @@
+package vm
+
+import "github.com/mburakmmm/sky-lang/internal/interpreter"
+
+// Inline caches. Instructions that look names up (GET_GLOBAL, SET_GLOBAL,
+// GET_MEMBER, INVOKE) get a cache when their function is assembled; its
+// index in fn.caches is stored in Operand2.
+//
+// Global sites cache the slot of the variable, so rebinding a global is
+// seen without a lookup. A site resolved to a built-in is invalidated when
+// a global of any name is first defined (vm.globalEpoch), since that
+// global may shadow the built-in.
+//
+// Member sites cache the method found for the receiver's class, for up to
+// maxPolymorphic classes; sites that see more classes stop caching.
+// Entries are invalidated when any class gains a method (vm.classEpoch),
+// which also covers subclasses inheriting from the changed class.
+
+// maxPolymorphic is the number of receiver classes cached per site
+const maxPolymorphic = 4
+
+// global is the storage of a module-level variable
+type global struct {
+	value Value
+}
+
+// inlineCache is the cache of one instruction
+type inlineCache struct {
+	// Global sites
+	module  *module
+	epoch   uint64
+	slot    *global // nil when the name resolved to a built-in
+	builtin Value
+
+	// Member sites
+	entries     []memberEntry
+	megamorphic bool
+}
+
+// memberEntry is the method a member site resolved for one class
+type memberEntry struct {
+	class   *interpreter.Class
+	epoch   uint64
+	method  *interpreter.Function
+	closure *Closure // compiled method, nil for interpreter methods
+}
+
+// cacheable reports whether op gets an inline cache
+func cacheable(op OpCode) bool {
+	switch op {
+	case OpGetGlobal, OpSetGlobal, OpGetMember, OpInvoke:
+		return true
+	}
+	return false
+}
+
+// loadGlobal reads a global or built-in through the site's cache
+func (vm *VM) loadGlobal(ic *inlineCache, mod *module, name string) (Value, error) {
+	if ic.module == mod {
+		if ic.slot != nil {
+			return ic.slot.value, nil
+		}
+		if ic.epoch == vm.globalEpoch {
+			return ic.builtin, nil
+		}
+	}
+
+	// Interpreter variables may change at any time and are not cached
+	if mod.env != nil {
+		val, err := vm.getGlobal(mod, name)
+		return unbox(val), err
+	}
+	if slot, ok := mod.globals[name]; ok {
+		*ic = inlineCache{module: mod, slot: slot}
+		return slot.value, nil
+	}
+	val, err := vm.getGlobal(mod, name)
+	if err != nil {
+		return Value{}, err
+	}
+	*ic = inlineCache{module: mod, epoch: vm.globalEpoch, builtin: unbox(val)}
+	return ic.builtin, nil
+}
+
+// storeGlobal assigns a global through the site's cache
+func (vm *VM) storeGlobal(ic *inlineCache, mod *module, name string, val Value) {
+	if ic.module == mod && ic.slot != nil {
+		ic.slot.value = val
+		return
+	}
+	if mod.env != nil {
+		exported := vm.export(val.box())
+		if mod.env.Update(name, exported) != nil {
+			mod.env.Set(name, exported)
+		}
+		return
+	}
+	*ic = inlineCache{module: mod, slot: vm.defineGlobal(mod, name)}
+	ic.slot.value = val
+}
+
+// defineGlobal returns the slot of a global, creating it if needed
+func (vm *VM) defineGlobal(mod *module, name string) *global {
+	slot, ok := mod.globals[name]
+	if !ok {
+		slot = &global{}
+		mod.globals[name] = slot
+		vm.globalEpoch++
+	}
+	return slot
+}
+
+// lookupMethod finds a method of class through the site's cache. ic may
+// be nil for lookups that do not come from an instruction.
+func (vm *VM) lookupMethod(ic *inlineCache, class *interpreter.Class, name string) (*interpreter.Function, *Closure, bool) {
+	if ic != nil {
+		for i := range ic.entries {
+			if e := &ic.entries[i]; e.class == class && e.epoch == vm.classEpoch {
+				return e.method, e.closure, true
+			}
+		}
+	}
+
+	method, ok := findMethod(class, name)
+	if !ok {
+		return nil, nil, false
+	}
+	closure := vm.methods[method]
+	if ic != nil && !ic.megamorphic {
+		// Drop entries made stale by class changes before adding this one
+		entries := ic.entries[:0]
+		for _, e := range ic.entries {
+			if e.epoch == vm.classEpoch {
+				entries = append(entries, e)
+			}
+		}
+		if len(entries) < maxPolymorphic {
+			ic.entries = append(entries, memberEntry{class: class, epoch: vm.classEpoch, method: method, closure: closure})
+		} else {
+			ic.entries, ic.megamorphic = nil, true
+		}
+	}
+	return method, closure, true
+}
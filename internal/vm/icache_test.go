@@ -0,0 +1,197 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+)
+
+// sites returns the caches of the instructions of function name with op;
+// assemble numbers the caches in instruction order
+func sites(t *testing.T, bc *Bytecode, name string, op OpCode) []*inlineCache {
+	t.Helper()
+	for _, fn := range allFunctions(bc) {
+		if fn.Name != name {
+			continue
+		}
+		var caches []*inlineCache
+		index := 0
+		for _, ins := range fn.Instructions {
+			if !cacheable(ins.Op) {
+				continue
+			}
+			index++
+			if ins.Op == op {
+				caches = append(caches, &fn.caches[index])
+			}
+		}
+		return caches
+	}
+	t.Fatalf("no function %s", name)
+	return nil
+}
+
+func TestGlobalSites(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"rebound global", `function one()
+  return 1
+end
+function two()
+  return 2
+end
+let pick = one
+function sum(n)
+  let total = 0
+  let i = 0
+  while i < n
+    if i == 5
+      pick = two
+    end
+    total = total + pick()
+    i += 1
+  end
+  return total
+end
+print(sum(10))`, "15\n"},
+		{"shadowed builtin", `function size(xs)
+  return len(xs)
+end
+print(size([1, 2, 3]))
+let len = function(xs) 99 end
+print(size([1]))`, "3\n99\n"},
+		{"builtin after other global", `function size(xs)
+  return len(xs)
+end
+print(size([1, 2]))
+let other = 1
+print(size([1, 2, 3]))`, "2\n3\n"},
+	}
+
+	for _, tt := range tests {
+		for level := 0; level <= MaxOptLevel; level++ {
+			got, err := run(t, compile(t, tt.source, level), "test.sky")
+			if err != nil || got != tt.want {
+				t.Errorf("%s -O%d: printed %q (%v), want %q", tt.name, level, got, err, tt.want)
+			}
+		}
+	}
+}
+
+func TestMemberSites(t *testing.T) {
+	classes := `class A
+  function name()
+    return "a"
+  end
+end
+class B
+  function name()
+    return "b"
+  end
+end
+class C
+  function name()
+    return "c"
+  end
+end
+class D
+  function name()
+    return "d"
+  end
+end
+class E : A
+  function kind()
+    return "e"
+  end
+end
+function names(objs)
+  let s = ""
+  for o in objs
+    s = s + o.name()
+  end
+  return s
+end
+`
+	tests := []struct {
+		name        string
+		objs        string
+		want        string
+		entries     int
+		megamorphic bool
+	}{
+		{"monomorphic", "[A(), A(), A()]", "aaa\n", 1, false},
+		{"polymorphic", "[A(), B(), C(), A(), B()]", "abcab\n", 3, false},
+		{"inherited", "[A(), E(), E()]", "aaa\n", 2, false},
+		{"megamorphic", "[A(), B(), C(), D(), E(), A(), E()]", "abcdaaa\n", 0, true},
+	}
+
+	for _, tt := range tests {
+		// Without inlining the site stays in names
+		bc := compile(t, classes+"print(names("+tt.objs+"))", 0)
+		got, err := run(t, bc, "test.sky")
+		if err != nil || got != tt.want {
+			t.Errorf("%s: printed %q (%v), want %q", tt.name, got, err, tt.want)
+		}
+		invokes := sites(t, bc, "names", OpInvoke)
+		if len(invokes) != 1 {
+			t.Fatalf("%s: %d call sites in names, want 1", tt.name, len(invokes))
+		}
+		ic := invokes[0]
+		if len(ic.entries) != tt.entries || ic.megamorphic != tt.megamorphic {
+			t.Errorf("%s: site has %d entries, megamorphic %v; want %d, %v",
+				tt.name, len(ic.entries), ic.megamorphic, tt.entries, tt.megamorphic)
+		}
+	}
+}
+
+// TestMemberSiteInvalidation checks a site that cached an inherited method
+// after the subclass gains a method of the same name. Programs attach
+// methods only while defining a class, so the method is added directly.
+func TestMemberSiteInvalidation(t *testing.T) {
+	machine := NewVM(compile(t, `class Base
+  function hello()
+    return "base"
+  end
+end
+class Child : Base
+  function other()
+    return 1
+  end
+end`, 0))
+	if _, err := capture(t, machine.Run); err != nil {
+		t.Fatal(err)
+	}
+	class := func(name string) *interpreter.Class {
+		val, err := machine.getGlobal(machine.main, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return val.(*interpreter.Class)
+	}
+	base, child := class("Base"), class("Child")
+
+	ic := &inlineCache{}
+	inherited, _, ok := machine.lookupMethod(ic, child, "hello")
+	if !ok || inherited != base.Methods["hello"] {
+		t.Fatal("inherited method not found")
+	}
+	if _, _, ok := machine.lookupMethod(ic, base, "hello"); !ok || len(ic.entries) != 2 {
+		t.Fatalf("site has %d entries, want 2", len(ic.entries))
+	}
+
+	own := &Closure{Fn: machine.methods[inherited].Fn, module: machine.main}
+	machine.addMethod(child, "hello", own)
+	method, closure, ok := machine.lookupMethod(ic, child, "hello")
+	if !ok || method == inherited || closure != own {
+		t.Error("site returned the method cached before the class changed")
+	}
+	if method, _, _ := machine.lookupMethod(ic, base, "hello"); method != inherited {
+		t.Error("site lost the method of the unchanged class")
+	}
+	if len(ic.entries) != 2 {
+		t.Errorf("site has %d entries after the change, want 2", len(ic.entries))
+	}
+}
@@ -1,6 +1,9 @@
 package vm
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 // OpCode represents a bytecode instruction
 type OpCode byte
@@ -43,8 +46,7 @@ const (
 	OpJumpIfFalse // Jump if top of stack is false
 	OpJumpIfTrue  // Jump if top of stack is true
 	OpLoop        // Jump backward (for loops)
-	OpBreak       // Break from loop
-	OpContinue    // Continue to next iteration
+	OpJumpIfArg   // Jump if the caller passed argument Operand2 (default parameters)
 
 	// Functions
 	OpCall      // Call function
@@ -55,10 +57,71 @@ const (
 	OpAwait // Await a promise
 	OpYield // Yield a value (for coroutines)
 
+	// Closures
+	OpClosure    // Create closure from function constant
+	OpGetUpvalue // Get captured variable
+	OpSetUpvalue // Set captured variable
+
+	// Collections
+	OpList     // Build list from N stack values
+	OpDict     // Build dict from N key/value pairs
+	OpIndex    // a[i]
+	OpSetIndex // a[i] = v
+
+	// Members and classes
+	OpGetMember   // obj.name
+	OpSetMember   // obj.name = v
+	OpInvoke      // obj.name(args) without creating a bound method
+	OpGetSuper    // Superclass of the enclosing method's class
+	OpSuperInvoke // super.name(args) with the current self
+	OpClass       // Create class from N superclasses
+	OpMethod      // Attach closure as method to class below it
+	OpImplements  // Verify class implements interface
+	OpInterface   // Create interface
+	OpEnum        // Create enum type from N variant constructors
+	OpVariant     // Create enum variant constructor
+
+	// Iteration
+	OpIter     // Replace iterable with iterator
+	OpIterNext // Push next element or jump when exhausted
+
+	// Exceptions
+	OpTry    // Push exception handler
+	OpEndTry // Pop exception handler
+	OpThrow  // Raise top of stack as error
+
+	// Modules
+	OpImport // Load module and push its namespace
+
+	// Pattern matching (each test pops its operands and pushes a bool)
+	OpTestEqual    // Literal pattern equality
+	OpTestKind     // Value kind check
+	OpTestVariant  // Enum variant (and payload count) check
+	OpTestList     // List length check
+	OpTestKey      // Dict key presence
+	OpTestField    // Instance field presence
+	OpTestInstance // Instance of class check
+	OpGetPayload   // Enum payload element
+	OpGetElement   // List element (negative counts from the end)
+	OpSlice        // List slice for rest patterns
+
+	// Superinstructions (emitted by the -O2 optimizer)
+	OpIncLocal    // local += constant Operand2
+	OpCompareJump // Compare with operator Operand2, jump if false
+	OpGetLocal2   // Push locals Operand and Operand2
+
+	// Profile-guided code (emitted by ApplyProfile)
+	OpInlineGuard // Unless the callee is function constant Operand2, jump to the call; else move the arguments to the slots after constant Operand2+1
+	OpIntOp       // Operator Operand2 specialised for integers
+	OpFloatOp     // Operator Operand2 specialised for floats
+
+	// Runtime type checks (emitted with Compiler.SetTypeChecks)
+	OpCheckArg  // Check parameter slot Operand2 against type constant Operand
+	OpCheckType // Check top of stack against type constant Operand (Operand2: checkLet or checkReturn)
+
 	// Built-ins
 	OpPrint // print() built-in
 	OpLen   // len() built-in
-	OpRange // range() built-in
 
 	// Special
 	OpTrue  // Push true
@@ -122,10 +185,8 @@ func (op OpCode) String() string {
 		return "JUMP_IF_TRUE"
 	case OpLoop:
 		return "LOOP"
-	case OpBreak:
-		return "BREAK"
-	case OpContinue:
-		return "CONTINUE"
+	case OpJumpIfArg:
+		return "JUMP_IF_ARG"
 	case OpCall:
 		return "CALL"
 	case OpCallAsync:
@@ -136,12 +197,94 @@ func (op OpCode) String() string {
 		return "AWAIT"
 	case OpYield:
 		return "YIELD"
+	case OpCheckArg:
+		return "CHECK_ARG"
+	case OpCheckType:
+		return "CHECK_TYPE"
 	case OpPrint:
 		return "PRINT"
 	case OpLen:
 		return "LEN"
-	case OpRange:
-		return "RANGE"
+	case OpClosure:
+		return "CLOSURE"
+	case OpGetUpvalue:
+		return "GET_UPVALUE"
+	case OpSetUpvalue:
+		return "SET_UPVALUE"
+	case OpList:
+		return "LIST"
+	case OpDict:
+		return "DICT"
+	case OpIndex:
+		return "INDEX"
+	case OpSetIndex:
+		return "SET_INDEX"
+	case OpGetMember:
+		return "GET_MEMBER"
+	case OpSetMember:
+		return "SET_MEMBER"
+	case OpInvoke:
+		return "INVOKE"
+	case OpGetSuper:
+		return "GET_SUPER"
+	case OpSuperInvoke:
+		return "SUPER_INVOKE"
+	case OpClass:
+		return "CLASS"
+	case OpMethod:
+		return "METHOD"
+	case OpImplements:
+		return "IMPLEMENTS"
+	case OpInterface:
+		return "INTERFACE"
+	case OpEnum:
+		return "ENUM"
+	case OpVariant:
+		return "VARIANT"
+	case OpIter:
+		return "ITER"
+	case OpIterNext:
+		return "ITER_NEXT"
+	case OpTry:
+		return "TRY"
+	case OpEndTry:
+		return "END_TRY"
+	case OpThrow:
+		return "THROW"
+	case OpImport:
+		return "IMPORT"
+	case OpTestEqual:
+		return "TEST_EQUAL"
+	case OpTestKind:
+		return "TEST_KIND"
+	case OpTestVariant:
+		return "TEST_VARIANT"
+	case OpTestList:
+		return "TEST_LIST"
+	case OpTestKey:
+		return "TEST_KEY"
+	case OpTestField:
+		return "TEST_FIELD"
+	case OpTestInstance:
+		return "TEST_INSTANCE"
+	case OpGetPayload:
+		return "GET_PAYLOAD"
+	case OpGetElement:
+		return "GET_ELEMENT"
+	case OpSlice:
+		return "SLICE"
+	case OpIncLocal:
+		return "INC_LOCAL"
+	case OpCompareJump:
+		return "COMPARE_JUMP"
+	case OpGetLocal2:
+		return "GET_LOCAL2"
+	case OpInlineGuard:
+		return "INLINE_GUARD"
+	case OpIntOp:
+		return "INT_OP"
+	case OpFloatOp:
+		return "FLOAT_OP"
 	case OpTrue:
 		return "TRUE"
 	case OpFalse:
@@ -172,10 +315,38 @@ func (ins Instruction) String() string {
 		return fmt.Sprintf("%-16s %d (%s)", ins.Op, ins.Operand, ins.Name)
 	case OpGetGlobal, OpSetGlobal:
 		return fmt.Sprintf("%-16s %s", ins.Op, ins.Name)
-	case OpJump, OpJumpIfFalse, OpJumpIfTrue, OpLoop:
+	case OpGetUpvalue, OpSetUpvalue:
+		return fmt.Sprintf("%-16s %d (%s)", ins.Op, ins.Operand, ins.Name)
+	case OpJump, OpJumpIfFalse, OpJumpIfTrue, OpLoop, OpIterNext, OpTry:
 		return fmt.Sprintf("%-16s -> %d", ins.Op, ins.Operand)
+	case OpJumpIfArg:
+		return fmt.Sprintf("%-16s %d -> %d", ins.Op, ins.Operand2, ins.Operand)
+	case OpCompareJump:
+		return fmt.Sprintf("%-16s %s -> %d", ins.Op, OpCode(ins.Operand2), ins.Operand)
+	case OpIncLocal:
+		return fmt.Sprintf("%-16s %d (%s) += CONSTANT %d", ins.Op, ins.Operand, ins.Name, ins.Operand2)
+	case OpGetLocal2:
+		return fmt.Sprintf("%-16s %d %d (%s)", ins.Op, ins.Operand, ins.Operand2, ins.Name)
+	case OpInlineGuard:
+		return fmt.Sprintf("%-16s %s -> %d", ins.Op, ins.Name, ins.Operand)
+	case OpIntOp, OpFloatOp:
+		return fmt.Sprintf("%-16s %s", ins.Op, OpCode(ins.Operand2))
 	case OpCall:
 		return fmt.Sprintf("%-16s %d args", ins.Op, ins.Operand)
+	case OpInvoke, OpSuperInvoke:
+		return fmt.Sprintf("%-16s %s, %d args", ins.Op, ins.Name, ins.Operand)
+	case OpClosure:
+		return fmt.Sprintf("%-16s %d", ins.Op, ins.Operand)
+	case OpList, OpDict, OpPrint, OpGetPayload, OpGetElement, OpTestKind:
+		return fmt.Sprintf("%-16s %d", ins.Op, ins.Operand)
+	case OpTestList, OpSlice, OpTestEqual:
+		return fmt.Sprintf("%-16s %d %d", ins.Op, ins.Operand, ins.Operand2)
+	case OpGetMember, OpSetMember, OpGetSuper, OpMethod, OpImplements, OpImport, OpTestField, OpTestInstance:
+		return fmt.Sprintf("%-16s %s", ins.Op, ins.Name)
+	case OpClass, OpEnum, OpInterface, OpVariant, OpTestVariant:
+		return fmt.Sprintf("%-16s %s %d", ins.Op, ins.Name, ins.Operand)
+	case OpCheckArg, OpCheckType:
+		return fmt.Sprintf("%-16s %s %d", ins.Op, ins.Name, ins.Operand)
 	default:
 		return ins.Op.String()
 	}
@@ -184,19 +355,33 @@ func (ins Instruction) String() string {
 // Bytecode represents compiled bytecode
 type Bytecode struct {
 	Instructions []Instruction
-	Constants    []interface{}                // int64, float64, string, bool
+	Lines        []int                        // source line of each instruction
+	Constants    []interface{}                // int64, float64, string, bool, *CompiledFunction
 	Functions    map[string]*CompiledFunction // Compiled functions
+	LocalCount   int                          // local slots used by top-level code (match, for)
+	SourceHash   string                       // SHA-256 of the source, set when loaded from or written to .skyc
+	OptLevel     int                          // optimization level the code was built with
 }
 
 // Disassemble prints bytecode in human-readable format
 func (bc *Bytecode) Disassemble(name string) {
-	fmt.Printf("== %s ==\n", name)
+	if bc.OptLevel > 0 {
+		fmt.Printf("== %s (-O%d) ==\n", name, bc.OptLevel)
+	} else {
+		fmt.Printf("== %s ==\n", name)
+	}
 
 	// Print compiled functions
 	if len(bc.Functions) > 0 {
 		fmt.Println("\n=== Compiled Functions ===")
-		for fname, fn := range bc.Functions {
-			fmt.Printf("\nFunction: %s (arity: %d, locals: %d)\n", fname, fn.Arity, fn.LocalCount)
+		names := make([]string, 0, len(bc.Functions))
+		for fname := range bc.Functions {
+			names = append(names, fname)
+		}
+		sort.Strings(names)
+		for _, fname := range names {
+			fn := bc.Functions[fname]
+			fmt.Printf("\nFunction: %s (arity: %d, locals: %d, upvalues: %d)\n", fname, fn.Arity, fn.LocalCount, len(fn.Upvalues))
 			for i, ins := range fn.Instructions {
 				fmt.Printf("  %04d  %s\n", i, ins)
 			}
@@ -0,0 +1,460 @@
+package vm
+
+import "github.com/mburakmmm/sky-lang/internal/interpreter"
+
+// Optimization levels:
+//
+//	-O0  code exactly as compiled
+//	-O1  constant folding, peephole rewrites, jump threading and
+//	     dead-code elimination
+//	-O2  -O1 plus superinstructions for hot patterns (INC_LOCAL,
+//	     COMPARE_JUMP, GET_LOCAL2)
+const (
+	MaxOptLevel     = 2
+	DefaultOptLevel = 1
+)
+
+// maxOptRounds bounds the passes run until the code stops changing
+const maxOptRounds = 16
+
+// Optimize rewrites bytecode in place at the given optimization level
+func Optimize(bc *Bytecode, level int) {
+	if level <= 0 {
+		return
+	}
+	if level > MaxOptLevel {
+		level = MaxOptLevel
+	}
+	bc.OptLevel = level
+
+	main := &optimizer{level: level, code: bc.Instructions, lines: bc.Lines, constants: bc.Constants}
+	main.run()
+	bc.Instructions, bc.Lines, bc.Constants = main.code, main.lines, main.constants
+
+	for _, fn := range allFunctions(bc) {
+		o := &optimizer{level: level, code: fn.Instructions, lines: fn.Lines, constants: fn.Constants}
+		o.run()
+		fn.Instructions, fn.Lines, fn.Constants = o.code, o.lines, o.constants
+		fn.values, fn.code, fn.names, fn.caches = nil, nil, nil, nil
+	}
+}
+
+// allFunctions returns every function reachable from the named table and
+// from constants, including lambdas and nested functions
+func allFunctions(bc *Bytecode) []*CompiledFunction {
+	seen := make(map[*CompiledFunction]bool)
+	var fns []*CompiledFunction
+	var visit func(constants []interface{})
+	add := func(fn *CompiledFunction) {
+		if !seen[fn] {
+			seen[fn] = true
+			fns = append(fns, fn)
+			visit(fn.Constants)
+		}
+	}
+	visit = func(constants []interface{}) {
+		for _, c := range constants {
+			if fn, ok := c.(*CompiledFunction); ok {
+				add(fn)
+			}
+		}
+	}
+	for _, fn := range bc.Functions {
+		add(fn)
+	}
+	visit(bc.Constants)
+	return fns
+}
+
+// optimizer rewrites one code unit: a function body or top-level code.
+// Each pass marks replaced instructions dead; compact then removes them
+// and remaps jump targets.
+type optimizer struct {
+	level     int
+	code      []Instruction
+	lines     []int
+	constants []interface{}
+
+	dead    []bool
+	targets map[int]bool
+	changed bool
+}
+
+func (o *optimizer) run() {
+	if len(o.lines) != len(o.code) {
+		o.lines = make([]int, len(o.code))
+	}
+	for round := 0; round < maxOptRounds; round++ {
+		o.changed = false
+		o.pass(o.foldConstants)
+		o.pass(o.peephole)
+		o.threadJumps()
+		o.eliminateDeadCode()
+		if o.level >= 2 {
+			o.pass(o.superinstructions)
+		}
+		if !o.changed {
+			return
+		}
+	}
+}
+
+// pass applies rewrite at every instruction. rewrite returns how many
+// instructions it consumed, or 0 to leave the instruction alone.
+func (o *optimizer) pass(rewrite func(i int) int) {
+	o.begin()
+	for i := 0; i < len(o.code); {
+		if n := rewrite(i); n > 0 {
+			i += n
+		} else {
+			i++
+		}
+	}
+	o.compact()
+}
+
+func (o *optimizer) begin() {
+	o.dead = make([]bool, len(o.code))
+	o.targets = make(map[int]bool)
+	for _, ins := range o.code {
+		if hasTarget(ins.Op) {
+			o.targets[ins.Operand] = true
+		}
+	}
+}
+
+// window reports whether n instructions starting at i can be rewritten as
+// a unit: none past the first may be entered by a jump
+func (o *optimizer) window(i, n int) bool {
+	if i+n > len(o.code) {
+		return false
+	}
+	for j := i + 1; j < i+n; j++ {
+		if o.targets[j] {
+			return false
+		}
+	}
+	return true
+}
+
+// replace rewrites n instructions at i with replacement, which must not be
+// longer; the remaining instructions of the window are removed
+func (o *optimizer) replace(i, n int, replacement ...Instruction) int {
+	for j := 0; j < n; j++ {
+		if j < len(replacement) {
+			o.code[i+j] = replacement[j]
+			o.lines[i+j] = o.lines[i]
+		} else {
+			o.dead[i+j] = true
+		}
+	}
+	o.changed = true
+	return n
+}
+
+// compact drops dead instructions. A jump to a removed instruction lands on
+// the next remaining one.
+func (o *optimizer) compact() {
+	newIndex := make([]int, len(o.code)+1)
+	live := 0
+	for i := range o.code {
+		newIndex[i] = live
+		if !o.dead[i] {
+			live++
+		}
+	}
+	newIndex[len(o.code)] = live
+	if live == len(o.code) {
+		return
+	}
+
+	code := make([]Instruction, 0, live)
+	lines := make([]int, 0, live)
+	for i, ins := range o.code {
+		if o.dead[i] {
+			continue
+		}
+		if hasTarget(ins.Op) {
+			ins.Operand = newIndex[ins.Operand]
+		}
+		code = append(code, ins)
+		lines = append(lines, o.lines[i])
+	}
+	o.code, o.lines = code, lines
+}
+
+// foldConstants evaluates operators whose operands are constants
+func (o *optimizer) foldConstants(i int) int {
+	ins := o.code[i]
+	switch {
+	case ins.Op == OpConstant && o.window(i, 3) && o.code[i+1].Op == OpConstant:
+		op, ok := binaryOperators[operator(o.code[i+2])]
+		if !ok {
+			return 0
+		}
+		if !isScalar(o.constants[ins.Operand]) || !isScalar(o.constants[o.code[i+1].Operand]) {
+			return 0
+		}
+		if (op == "/" || op == "%") && isZero(o.constants[o.code[i+1].Operand]) {
+			// Division by zero is left for run time, where try can catch it
+			return 0
+		}
+		a, b := toValue(o.constants[ins.Operand]), toValue(o.constants[o.code[i+1].Operand])
+		result, err := interpreter.BinaryOp(a, b, op)
+		if err != nil {
+			return 0
+		}
+		return o.foldTo(i, 3, result)
+
+	case ins.Op == OpConstant && o.window(i, 2) && o.code[i+1].Op == OpNegate:
+		switch v := o.constants[ins.Operand].(type) {
+		case int64:
+			return o.foldTo(i, 2, &interpreter.Integer{Value: -v})
+		case float64:
+			return o.foldTo(i, 2, &interpreter.Float{Value: -v})
+		}
+
+	case isLiteral(ins.Op) && o.window(i, 2) && o.code[i+1].Op == OpNot:
+		if truthy, ok := o.truthiness(ins); ok {
+			return o.foldTo(i, 2, &interpreter.Boolean{Value: !truthy})
+		}
+	}
+	return 0
+}
+
+// foldTo replaces n instructions at i with a constant holding result
+func (o *optimizer) foldTo(i, n int, result interpreter.Value) int {
+	var raw interface{}
+	switch v := result.(type) {
+	case *interpreter.Integer:
+		raw = v.Value
+	case *interpreter.Float:
+		raw = v.Value
+	case *interpreter.String:
+		raw = v.Value
+	case *interpreter.Boolean:
+		if v.Value {
+			return o.replace(i, n, Instruction{Op: OpTrue})
+		}
+		return o.replace(i, n, Instruction{Op: OpFalse})
+	default:
+		return 0
+	}
+	return o.replace(i, n, Instruction{Op: OpConstant, Operand: o.addConstant(raw)})
+}
+
+func (o *optimizer) addConstant(raw interface{}) int {
+	for idx, c := range o.constants {
+		if c == raw {
+			return idx
+		}
+	}
+	o.constants = append(o.constants, raw)
+	return len(o.constants) - 1
+}
+
+// peephole rewrites short instruction sequences
+func (o *optimizer) peephole(i int) int {
+	ins := o.code[i]
+	switch {
+	// Assignment used as a statement: DUP; SET x; POP -> SET x
+	case ins.Op == OpDup && o.window(i, 3) && isStore(o.code[i+1].Op) && o.code[i+2].Op == OpPop:
+		return o.replace(i, 3, o.code[i+1])
+
+	// Negated condition: NOT; JUMP_IF_FALSE -> JUMP_IF_TRUE
+	case ins.Op == OpNot && o.window(i, 2) && isConditional(o.code[i+1].Op):
+		jump := o.code[i+1]
+		if jump.Op == OpJumpIfFalse {
+			jump.Op = OpJumpIfTrue
+		} else {
+			jump.Op = OpJumpIfFalse
+		}
+		return o.replace(i, 2, jump)
+
+	// Constant condition: the jump is either always or never taken
+	case isLiteral(ins.Op) && o.window(i, 2) && isConditional(o.code[i+1].Op):
+		truthy, ok := o.truthiness(ins)
+		if !ok {
+			return 0
+		}
+		jump := o.code[i+1]
+		if truthy == (jump.Op == OpJumpIfTrue) {
+			return o.replace(i, 2, Instruction{Op: OpJump, Operand: jump.Operand})
+		}
+		return o.replace(i, 2)
+
+	// Pushing a value only to drop it
+	case isLiteral(ins.Op) && o.window(i, 2) && o.code[i+1].Op == OpPop:
+		return o.replace(i, 2)
+
+	// Jump to the next instruction
+	case ins.Op == OpJump && ins.Operand == i+1:
+		return o.replace(i, 1)
+	}
+	return 0
+}
+
+// threadJumps retargets jumps that land on an unconditional jump
+func (o *optimizer) threadJumps() {
+	for i, ins := range o.code {
+		if !isThreadable(ins.Op) {
+			continue
+		}
+		target := ins.Operand
+		for hops := 0; hops < len(o.code) && target < len(o.code); hops++ {
+			next := o.code[target]
+			if (next.Op != OpJump && next.Op != OpLoop) || next.Operand == target {
+				break
+			}
+			target = next.Operand
+		}
+		if target != ins.Operand {
+			o.code[i].Operand = target
+			o.changed = true
+		}
+	}
+}
+
+// eliminateDeadCode removes instructions no path reaches, such as code
+// after return, throw, break or continue
+func (o *optimizer) eliminateDeadCode() {
+	reachable := make([]bool, len(o.code))
+	work := []int{0}
+	for len(work) > 0 {
+		i := work[len(work)-1]
+		work = work[:len(work)-1]
+		for i < len(o.code) && !reachable[i] {
+			reachable[i] = true
+			ins := o.code[i]
+			if hasTarget(ins.Op) {
+				work = append(work, ins.Operand)
+			}
+			if endsBlock(ins.Op) {
+				break
+			}
+			i++
+		}
+	}
+
+	o.begin()
+	for i := range o.code {
+		if !reachable[i] {
+			o.dead[i] = true
+			o.changed = true
+		}
+	}
+	o.compact()
+}
+
+// superinstructions fuses hot sequences into single instructions
+func (o *optimizer) superinstructions(i int) int {
+	ins := o.code[i]
+	switch {
+	// i = i + k -> INC_LOCAL i k
+	case ins.Op == OpGetLocal && o.window(i, 4) && o.code[i+1].Op == OpConstant &&
+		operator(o.code[i+2]) == OpAdd && o.code[i+3].Op == OpSetLocal && o.code[i+3].Operand == ins.Operand:
+		return o.replace(i, 4, Instruction{Op: OpIncLocal, Operand: ins.Operand, Operand2: o.code[i+1].Operand, Name: ins.Name})
+
+	// Comparison feeding a branch
+	case isComparison(operator(ins)) && o.window(i, 2) && o.code[i+1].Op == OpJumpIfFalse:
+		return o.replace(i, 2, Instruction{Op: OpCompareJump, Operand: o.code[i+1].Operand, Operand2: int(operator(ins))})
+
+	// Two locals pushed back to back
+	case ins.Op == OpGetLocal && o.window(i, 2) && o.code[i+1].Op == OpGetLocal:
+		next := o.code[i+1]
+		return o.replace(i, 2, Instruction{Op: OpGetLocal2, Operand: ins.Operand, Operand2: next.Operand, Name: ins.Name + ", " + next.Name})
+	}
+	return 0
+}
+
+// truthiness reports the truth value pushed by a literal instruction
+func (o *optimizer) truthiness(ins Instruction) (bool, bool) {
+	switch ins.Op {
+	case OpTrue:
+		return true, true
+	case OpFalse, OpNil:
+		return false, true
+	case OpConstant:
+		if !isScalar(o.constants[ins.Operand]) {
+			return false, false
+		}
+		return toValue(o.constants[ins.Operand]).IsTruthy(), true
+	}
+	return false, false
+}
+
+// isScalar reports whether a raw constant is a number, string or bool
+func isScalar(c interface{}) bool {
+	switch c.(type) {
+	case int64, float64, string, bool:
+		return true
+	}
+	return false
+}
+
+func isZero(c interface{}) bool {
+	switch v := c.(type) {
+	case int64:
+		return v == 0
+	case float64:
+		return v == 0
+	}
+	return false
+}
+
+// isLiteral reports whether op pushes a constant value without side effects
+func isLiteral(op OpCode) bool {
+	return op == OpTrue || op == OpFalse || op == OpNil || op == OpConstant
+}
+
+func isStore(op OpCode) bool {
+	return op == OpSetLocal || op == OpSetGlobal || op == OpSetUpvalue
+}
+
+func isConditional(op OpCode) bool {
+	return op == OpJumpIfFalse || op == OpJumpIfTrue
+}
+
+// operator returns the operator of an arithmetic or comparison
+// instruction, looking through ones specialised by ApplyProfile
+func operator(ins Instruction) OpCode {
+	if ins.Op == OpIntOp || ins.Op == OpFloatOp {
+		return OpCode(ins.Operand2)
+	}
+	return ins.Op
+}
+
+func isComparison(op OpCode) bool {
+	switch op {
+	case OpEqual, OpNotEqual, OpGreaterThan, OpGreaterEqual, OpLessThan, OpLessEqual:
+		return true
+	}
+	return false
+}
+
+// isThreadable reports whether a jump can be retargeted past a chain of jumps
+func isThreadable(op OpCode) bool {
+	switch op {
+	case OpJump, OpLoop, OpJumpIfFalse, OpJumpIfTrue, OpJumpIfArg, OpCompareJump:
+		return true
+	}
+	return false
+}
+
+// hasTarget reports whether Operand of op is an instruction index
+func hasTarget(op OpCode) bool {
+	switch op {
+	case OpJump, OpJumpIfFalse, OpJumpIfTrue, OpLoop, OpJumpIfArg, OpIterNext, OpTry, OpCompareJump, OpInlineGuard:
+		return true
+	}
+	return false
+}
+
+// endsBlock reports whether execution never falls through op
+func endsBlock(op OpCode) bool {
+	switch op {
+	case OpJump, OpLoop, OpReturn, OpHalt, OpThrow:
+		return true
+	}
+	return false
+}
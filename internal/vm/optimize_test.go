@@ -0,0 +1,97 @@
+package vm
+
+import "testing"
+
+// ops returns the operators of the top-level code, looking through the
+// typed INT_OP and FLOAT_OP forms
+func ops(bc *Bytecode) map[OpCode]bool {
+	seen := make(map[OpCode]bool)
+	for _, ins := range bc.Instructions {
+		seen[operator(ins)] = true
+	}
+	return seen
+}
+
+func TestOptimizeFoldsConstants(t *testing.T) {
+	tests := []struct {
+		source string
+		want   interface{}
+	}{
+		{"print(2 * 3 + 1)", int64(7)},
+		{"print(7 % 3)", int64(1)},
+		{"print(9 / 2)", int64(4)},
+		{"print(1.5 * 2.0)", 3.0},
+		{`print("a" + "b")`, "ab"},
+	}
+
+	for _, tt := range tests {
+		bc := compile(t, tt.source, 1)
+		found := false
+		for _, c := range bc.Constants {
+			if c == tt.want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: constant %v not folded, constants are %v", tt.source, tt.want, bc.Constants)
+		}
+		for _, op := range []OpCode{OpAdd, OpMul, OpMod, OpDiv} {
+			if ops(bc)[op] {
+				t.Errorf("%s: %s left after folding", tt.source, op)
+			}
+		}
+	}
+}
+
+func TestOptimizeKeepsDivisionByZero(t *testing.T) {
+	tests := []struct {
+		source string
+		op     OpCode
+	}{
+		{"5 % 0", OpMod},
+		{"5 / 0", OpDiv},
+		{"5.0 / 0.0", OpDiv},
+	}
+
+	for _, tt := range tests {
+		source := "try\n  print(" + tt.source + ")\ncatch e\n  print(\"caught\")\nend"
+		for level := 0; level <= MaxOptLevel; level++ {
+			bc := compile(t, source, level)
+			if !ops(bc)[tt.op] {
+				t.Errorf("-O%d: %s was folded", level, tt.source)
+			}
+			got, err := run(t, bc, "test.sky")
+			if err != nil {
+				t.Fatalf("-O%d: %s: %v", level, tt.source, err)
+			}
+			if tt.op == OpMod || tt.source == "5 / 0" {
+				if got != "caught\n" {
+					t.Errorf("-O%d: %s printed %q, want the error caught", level, tt.source, got)
+				}
+			}
+		}
+	}
+}
+
+func TestOptimizeLevels(t *testing.T) {
+	source := "let i = 0\nwhile i < 10\n  i = i + 1\nend\nprint(i)"
+
+	plain := compile(t, source, 0)
+	if plain.OptLevel != 0 || !ops(plain)[OpLessThan] {
+		t.Errorf("-O0 changed the code")
+	}
+
+	bc := compile(t, source, MaxOptLevel+3)
+	if bc.OptLevel != MaxOptLevel {
+		t.Errorf("level clamped to %d, want %d", bc.OptLevel, MaxOptLevel)
+	}
+	if len(bc.Instructions) != len(bc.Lines) {
+		t.Errorf("%d instructions but %d lines", len(bc.Instructions), len(bc.Lines))
+	}
+	for level := 0; level <= MaxOptLevel; level++ {
+		got, err := run(t, compile(t, source, level), "test.sky")
+		if err != nil || got != "10\n" {
+			t.Errorf("-O%d printed %q, %v", level, got, err)
+		}
+	}
+}
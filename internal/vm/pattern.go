@@ -0,0 +1,330 @@
+package vm
+
+import (
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+)
+
+// compileMatchExpression compiles a match expression. The subject is kept in
+// a hidden local; each arm tests its pattern against it and falls through to
+// the next arm on the first failing test.
+func (c *Compiler) compileMatchExpression(expr *ast.MatchExpression) error {
+	if err := c.compileExpression(expr.Value); err != nil {
+		return err
+	}
+
+	c.enterScope()
+	defer c.leaveScope()
+	subject := c.tempSlot()
+	c.emit(Instruction{Op: OpSetLocal, Operand: subject, Name: "$match"})
+
+	var endJumps []int
+	for _, arm := range expr.Arms {
+		c.enterScope()
+
+		fails, err := c.compilePattern(arm.Pattern, subject)
+		if err != nil {
+			return err
+		}
+		// A false guard falls through to the next arm
+		if arm.Guard != nil {
+			if err := c.compileExpression(arm.Guard); err != nil {
+				return err
+			}
+			fails = append(fails, c.emitJump(OpJumpIfFalse))
+		}
+
+		if err := c.compileArmBody(arm.Body); err != nil {
+			return err
+		}
+		endJumps = append(endJumps, c.emitJump(OpJump))
+
+		for _, jump := range fails {
+			c.patchJump(jump)
+		}
+		c.leaveScope()
+	}
+
+	c.emitThrow("non-exhaustive match: no pattern matched")
+	for _, jump := range endJumps {
+		c.patchJump(jump)
+	}
+	return nil
+}
+
+// compileArmBody compiles an arm body; its value is the last expression statement
+func (c *Compiler) compileArmBody(body *ast.BlockStatement) error {
+	if body == nil || len(body.Statements) == 0 {
+		c.emit(Instruction{Op: OpNil})
+		return nil
+	}
+
+	last := len(body.Statements) - 1
+	for _, stmt := range body.Statements[:last] {
+		if err := c.compileStatement(stmt); err != nil {
+			return err
+		}
+	}
+	if exprStmt, ok := body.Statements[last].(*ast.ExpressionStatement); ok {
+		return c.compileExpression(exprStmt.Expression)
+	}
+	if err := c.compileStatement(body.Statements[last]); err != nil {
+		return err
+	}
+	c.emit(Instruction{Op: OpNil})
+	return nil
+}
+
+// compilePattern emits tests of pattern against the value in slot and binds
+// its variables. It returns the jumps taken when the pattern does not match.
+func (c *Compiler) compilePattern(pattern ast.Expression, slot int) ([]int, error) {
+	switch p := pattern.(type) {
+	case *ast.Identifier:
+		switch {
+		case p.Value == "_":
+			return nil, nil
+		case p.Value == "nil" || p.Value == "null":
+			c.emitGetSlot(slot)
+			c.emit(Instruction{Op: OpNil})
+			return c.emitTest(Instruction{Op: OpTestEqual}), nil
+		case c.isVariant(p.Value):
+			// Bare variant name matches the variant regardless of payload
+			c.emitGetSlot(slot)
+			enum := c.addConstant(c.program.variants[p.Value])
+			return c.emitTest(Instruction{Op: OpTestVariant, Operand: -1, Operand2: enum, Name: p.Value}), nil
+		}
+		c.emitGetSlot(slot)
+		c.bindPattern(p.Value)
+		return nil, nil
+
+	case *ast.IntegerLiteral, *ast.StringLiteral, *ast.BooleanLiteral:
+		c.emitGetSlot(slot)
+		if err := c.compileExpression(p); err != nil {
+			return nil, err
+		}
+		return c.emitTest(Instruction{Op: OpTestEqual}), nil
+
+	case *ast.FloatLiteral, *ast.PrefixExpression:
+		// Float and negative literals compare numerically
+		c.emitGetSlot(slot)
+		if err := c.compileExpression(p); err != nil {
+			return nil, err
+		}
+		return c.emitTest(Instruction{Op: OpTestEqual, Operand2: 1}), nil
+
+	case *ast.CallExpression:
+		ident, ok := p.Function.(*ast.Identifier)
+		if !ok {
+			return c.emitFail(), nil
+		}
+
+		// Class pattern without fields: Point()
+		if c.program.classes[ident.Value] {
+			if len(p.Arguments) > 0 {
+				c.emitThrow(i18n.T("runtime.class_pattern_fields", ident.Value, ident.Value))
+				return nil, nil
+			}
+			c.emitGetSlot(slot)
+			c.emitGet(ident.Value)
+			return c.emitTest(Instruction{Op: OpTestInstance, Name: ident.Value}), nil
+		}
+
+		// Enum variant pattern: VariantName(args...)
+		c.emitGetSlot(slot)
+		fails := c.emitTest(Instruction{Op: OpTestVariant, Operand: len(p.Arguments), Operand2: -1, Name: ident.Value})
+		for idx, arg := range p.Arguments {
+			c.emitGetSlot(slot)
+			c.emit(Instruction{Op: OpGetPayload, Operand: idx})
+			argFails, err := c.compileSubPattern(arg)
+			if err != nil {
+				return nil, err
+			}
+			fails = append(fails, argFails...)
+		}
+		return fails, nil
+
+	case *ast.ListLiteral:
+		return c.compileListPattern(p, slot)
+
+	case *ast.DictPattern:
+		// Listed keys must exist and match, other keys are ignored
+		c.emitGetSlot(slot)
+		fails := c.emitTest(Instruction{Op: OpTestKind, Operand: int(interpreter.DictValue)})
+		for idx, key := range p.Keys {
+			c.emitGetSlot(slot)
+			if err := c.compileExpression(key); err != nil {
+				return nil, err
+			}
+			fails = append(fails, c.emitTest(Instruction{Op: OpTestKey})...)
+
+			c.emitGetSlot(slot)
+			if err := c.compileExpression(key); err != nil {
+				return nil, err
+			}
+			c.emit(Instruction{Op: OpIndex})
+			valueFails, err := c.compileSubPattern(p.Values[idx])
+			if err != nil {
+				return nil, err
+			}
+			fails = append(fails, valueFails...)
+		}
+		return fails, nil
+
+	case *ast.ClassPattern:
+		// Class pattern with fields: Point(x: 0, y: y)
+		c.emitGetSlot(slot)
+		c.emitGet(p.Class.Value)
+		fails := c.emitTest(Instruction{Op: OpTestInstance, Name: p.Class.Value})
+		for _, field := range p.Fields {
+			c.emitGetSlot(slot)
+			fails = append(fails, c.emitTest(Instruction{Op: OpTestField, Name: field.Name.Value})...)
+
+			c.emitGetSlot(slot)
+			c.emit(Instruction{Op: OpGetMember, Name: field.Name.Value})
+			fieldFails, err := c.compileSubPattern(field.Pattern)
+			if err != nil {
+				return nil, err
+			}
+			fails = append(fails, fieldFails...)
+		}
+		return fails, nil
+
+	case *ast.OrPattern:
+		// First matching alternative wins; alternatives share binding slots
+		var matched []int
+		var fails []int
+		for idx, alt := range p.Alternatives {
+			altFails, err := c.compilePattern(alt, slot)
+			if err != nil {
+				return nil, err
+			}
+			if idx == len(p.Alternatives)-1 {
+				fails = altFails
+				break
+			}
+			matched = append(matched, c.emitJump(OpJump))
+			for _, jump := range altFails {
+				c.patchJump(jump)
+			}
+		}
+		for _, jump := range matched {
+			c.patchJump(jump)
+		}
+		return fails, nil
+
+	case *ast.AsPattern:
+		fails, err := c.compilePattern(p.Pattern, slot)
+		if err != nil {
+			return nil, err
+		}
+		c.emitGetSlot(slot)
+		c.bindPattern(p.Name.Value)
+		return fails, nil
+
+	case *ast.RestPattern:
+		c.emitThrow("rest pattern is only allowed inside a list pattern")
+		return nil, nil
+
+	default:
+		c.emitThrow(i18n.T("runtime.unsupported_pattern", pattern))
+		return nil, nil
+	}
+}
+
+// compileListPattern matches list elements; a rest element collects the
+// remaining elements and patterns after it match from the end of the list
+func (c *Compiler) compileListPattern(p *ast.ListLiteral, slot int) ([]int, error) {
+	restIdx := -1
+	for idx, elem := range p.Elements {
+		if _, ok := elem.(*ast.RestPattern); ok {
+			restIdx = idx
+		}
+	}
+
+	c.emitGetSlot(slot)
+	test := Instruction{Op: OpTestList, Operand: len(p.Elements)}
+	if restIdx >= 0 {
+		test.Operand--
+		test.Operand2 = 1
+	}
+	fails := c.emitTest(test)
+
+	for idx, elem := range p.Elements {
+		if idx == restIdx {
+			rest := elem.(*ast.RestPattern)
+			if rest.Name != nil && rest.Name.Value != "_" {
+				c.emitGetSlot(slot)
+				c.emit(Instruction{Op: OpSlice, Operand: restIdx, Operand2: len(p.Elements) - restIdx - 1})
+				c.bindPattern(rest.Name.Value)
+			}
+			continue
+		}
+
+		index := idx
+		if restIdx >= 0 && idx > restIdx {
+			index = idx - len(p.Elements)
+		}
+		c.emitGetSlot(slot)
+		c.emit(Instruction{Op: OpGetElement, Operand: index})
+		elemFails, err := c.compileSubPattern(elem)
+		if err != nil {
+			return nil, err
+		}
+		fails = append(fails, elemFails...)
+	}
+	return fails, nil
+}
+
+// compileSubPattern matches the value on top of the stack, storing it in a
+// temporary slot unless the pattern simply binds or ignores it
+func (c *Compiler) compileSubPattern(pattern ast.Expression) ([]int, error) {
+	if ident, ok := pattern.(*ast.Identifier); ok && ident.Value == "_" {
+		c.emit(Instruction{Op: OpPop})
+		return nil, nil
+	}
+	slot := c.tempSlot()
+	c.emit(Instruction{Op: OpSetLocal, Operand: slot, Name: "$pattern"})
+	return c.compilePattern(pattern, slot)
+}
+
+// isVariant reports whether name refers to an enum variant, not a binding
+func (c *Compiler) isVariant(name string) bool {
+	_, ok := c.program.variants[name]
+	return ok
+}
+
+// bindPattern pops the top of the stack into a pattern variable. Alternatives
+// of an or-pattern bind the same names, so the slot is reused.
+func (c *Compiler) bindPattern(name string) {
+	slot := c.symbolTable.Define(name)
+	c.emit(Instruction{Op: OpSetLocal, Operand: slot, Name: name})
+}
+
+// tempSlot reserves an anonymous local slot
+func (c *Compiler) tempSlot() int {
+	slot := *c.symbolTable.numDefs
+	*c.symbolTable.numDefs++
+	return slot
+}
+
+func (c *Compiler) emitGetSlot(slot int) {
+	c.emit(Instruction{Op: OpGetLocal, Operand: slot, Name: "$pattern"})
+}
+
+// emitTest emits a test instruction followed by a jump taken on failure
+func (c *Compiler) emitTest(test Instruction) []int {
+	c.emit(test)
+	return []int{c.emitJump(OpJumpIfFalse)}
+}
+
+// emitFail emits an unconditional pattern failure
+func (c *Compiler) emitFail() []int {
+	return []int{c.emitJump(OpJump)}
+}
+
+// emitThrow raises a runtime error with msg
+func (c *Compiler) emitThrow(msg string) {
+	c.emit(Instruction{Op: OpConstant, Operand: c.addConstant(msg)})
+	c.emit(Instruction{Op: OpThrow})
+}
@@ -0,0 +1,683 @@
+package vm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+	pgo "github.com/mburakmmm/sky-lang/internal/optimizer"
+)
+
+// Profile-guided optimization. A VM with a profiler attached records, for
+// code compiled at -O0, how often every function and call site runs, how
+// branches go and which operand types arithmetic sees. Sites are named
+// "function@index": the function's key in Bytecode.Functions (<script>
+// for top-level code) and the index of the instruction in -O0 code.
+//
+// ApplyProfile then rewrites freshly compiled -O0 bytecode of the same
+// program, before Optimize:
+//
+//   - arithmetic that only saw integers, or only floats, becomes INT_OP or
+//     FLOAT_OP, which falls back to the generic operator for other types
+//   - an if/else whose then-branch is hot is laid out so that branch falls
+//     through to the code after it, and hot while loops are rotated to
+//     test their condition at the bottom, saving a jump per iteration
+//   - hot calls of small top-level functions are inlined behind an
+//     INLINE_GUARD, which takes the original call when the global no
+//     longer holds the inlined function
+
+const (
+	scriptKey = "<script>" // profile name of top-level code
+
+	pgoHotCount   = 100  // executions before a site is optimized
+	pgoTypeShare  = 0.95 // share of observations a type pair needs to be specialised
+	pgoHotBranch  = 0.2  // taken probability under which a branch is laid out
+	maxInlineSize = 40   // instructions of a function that can be inlined
+)
+
+// profiler records a profile of a VM run
+type profiler struct {
+	rec   *pgo.PGOProfiler
+	names map[*CompiledFunction]string   // profile name of each function
+	sites map[*CompiledFunction][]string // site name of each word offset
+}
+
+// SetProfiler records a profile of the run into p. The bytecode must be
+// compiled at -O0, the code ApplyProfile works on; imported modules are
+// not profiled.
+func (vm *VM) SetProfiler(p *pgo.PGOProfiler) {
+	prof := &profiler{
+		rec:   p,
+		names: make(map[*CompiledFunction]string),
+		sites: make(map[*CompiledFunction][]string),
+	}
+	for key, fn := range vm.bytecode.Functions {
+		prof.names[fn] = key
+	}
+	vm.profile = prof
+}
+
+// enter counts a call of fn
+func (p *profiler) enter(fn *CompiledFunction) {
+	if name, ok := p.names[fn]; ok {
+		p.rec.RecordFunctionCall(name)
+	}
+}
+
+// observe records the instruction at word offset at, before it runs
+func (p *profiler) observe(vm *VM, fn *CompiledFunction, at int, op OpCode) {
+	_, arithmetic := binaryOperators[op]
+	if op != OpCall && !isConditional(op) && !arithmetic {
+		return
+	}
+
+	sites, ok := p.sites[fn]
+	if !ok {
+		if name, named := p.names[fn]; named {
+			sites = siteNames(fn, name)
+		}
+		p.sites[fn] = sites
+	}
+	if sites == nil {
+		return
+	}
+
+	site := sites[at]
+	switch {
+	case op == OpCall:
+		p.rec.RecordPathExecution(site)
+	case isConditional(op):
+		p.rec.RecordPathExecution(site)
+		p.rec.RecordBranch(site, vm.stack[vm.sp-1].truthy() == (op == OpJumpIfTrue))
+	default:
+		p.rec.RecordTypeFeedback(site, typeName(vm.stack[vm.sp-2])+","+typeName(vm.stack[vm.sp-1]))
+	}
+}
+
+// siteNames names the instructions of fn by word offset
+func siteNames(fn *CompiledFunction, name string) []string {
+	sites := make([]string, len(fn.code))
+	offset := 0
+	for i, ins := range fn.Instructions {
+		sites[offset] = fmt.Sprintf("%s@%d", name, i)
+		if cacheable(ins.Op) {
+			// assemble always encodes the cache index
+			ins.Operand2 = 1
+		}
+		offset += encodedSize(ins)
+	}
+	return sites
+}
+
+// typeName names the type of a value in type feedback
+func typeName(v Value) string {
+	switch v.tag {
+	case tagNil:
+		return "nil"
+	case tagBool:
+		return "bool"
+	case tagInt:
+		return "int"
+	case tagFloat:
+		return "float"
+	}
+	switch v.obj.Kind() {
+	case interpreter.StringValue:
+		return "string"
+	case interpreter.ListValue:
+		return "list"
+	case interpreter.DictValue:
+		return "dict"
+	case interpreter.InstanceValue:
+		return "instance"
+	}
+	return "object"
+}
+
+// intOp applies an operator to integers; ok is false when the generic
+// operator has to handle it, as for division by zero
+func intOp(op OpCode, x, y int64) (Value, bool) {
+	switch op {
+	case OpAdd:
+		return intValue(x + y), true
+	case OpSub:
+		return intValue(x - y), true
+	case OpMul:
+		return intValue(x * y), true
+	case OpDiv:
+		if y != 0 {
+			return intValue(x / y), true
+		}
+	case OpMod:
+		if y != 0 {
+			return intValue(x % y), true
+		}
+	case OpEqual:
+		return boolValue(x == y), true
+	case OpNotEqual:
+		return boolValue(x != y), true
+	case OpLessThan:
+		return boolValue(x < y), true
+	case OpLessEqual:
+		return boolValue(x <= y), true
+	case OpGreaterThan:
+		return boolValue(x > y), true
+	case OpGreaterEqual:
+		return boolValue(x >= y), true
+	}
+	return Value{}, false
+}
+
+// floatOp applies an arithmetic operator to floats
+func floatOp(op OpCode, x, y float64) (Value, bool) {
+	switch op {
+	case OpAdd:
+		return floatValue(x + y), true
+	case OpSub:
+		return floatValue(x - y), true
+	case OpMul:
+		return floatValue(x * y), true
+	case OpDiv:
+		return floatValue(x / y), true
+	}
+	return Value{}, false
+}
+
+// PGOReport lists the changes ApplyProfile made, one line each
+type PGOReport struct {
+	Changes []string
+}
+
+func (r *PGOReport) add(u *pgoUnit, line int, format string, args ...interface{}) {
+	r.Changes = append(r.Changes, fmt.Sprintf("%s:%d: %s", u.name, line, fmt.Sprintf(format, args...)))
+}
+
+// pgoUnit is a code unit being rewritten: a function or top-level code
+type pgoUnit struct {
+	name   string
+	fn     *CompiledFunction
+	origin []int // index of each instruction in the profiled code, -1 if new
+}
+
+// site returns the profile name of instruction i, or "" for new code
+func (u *pgoUnit) site(i int) string {
+	if u.origin[i] < 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s@%d", u.name, u.origin[i])
+}
+
+// ApplyProfile optimizes bytecode compiled at -O0 with a profile recorded
+// from a run of the same program
+func ApplyProfile(bc *Bytecode, profile *pgo.ProfileData) *PGOReport {
+	report := &PGOReport{}
+
+	main := &CompiledFunction{
+		Name:         scriptKey,
+		Instructions: bc.Instructions,
+		Lines:        bc.Lines,
+		Constants:    bc.Constants,
+		LocalCount:   bc.LocalCount,
+	}
+	units := []*pgoUnit{{name: scriptKey, fn: main}}
+	keys := make([]string, 0, len(bc.Functions))
+	for key := range bc.Functions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		units = append(units, &pgoUnit{name: key, fn: bc.Functions[key]})
+	}
+
+	for _, u := range units {
+		if len(u.fn.Lines) != len(u.fn.Instructions) {
+			u.fn.Lines = make([]int, len(u.fn.Instructions))
+		}
+		u.origin = make([]int, len(u.fn.Instructions))
+		for i := range u.origin {
+			u.origin[i] = i
+		}
+		u.specialise(profile, report)
+		u.layoutBranches(profile, report)
+	}
+
+	// Inlined bodies are taken before any inlining, so inlining never nests
+	inliner := newInliner(main, units)
+	for _, u := range units {
+		inliner.inlineCalls(u, profile, report)
+	}
+
+	bc.Instructions, bc.Lines, bc.Constants, bc.LocalCount = main.Instructions, main.Lines, main.Constants, main.LocalCount
+	for _, u := range units[1:] {
+		u.fn.values, u.fn.code, u.fn.names, u.fn.caches = nil, nil, nil, nil
+	}
+	return report
+}
+
+// specialise replaces arithmetic that saw a single numeric type
+func (u *pgoUnit) specialise(profile *pgo.ProfileData, report *PGOReport) {
+	for i, ins := range u.fn.Instructions {
+		if _, ok := binaryOperators[ins.Op]; !ok {
+			continue
+		}
+		seen := profile.TypeFeedback[u.site(i)]
+		var total, most int64
+		var types string
+		for t, n := range seen {
+			total += n
+			if n > most || (n == most && t < types) {
+				most, types = n, t
+			}
+		}
+		if total < pgoHotCount || float64(most) < pgoTypeShare*float64(total) {
+			continue
+		}
+
+		var op OpCode
+		switch types {
+		case "int,int":
+			op = OpIntOp
+		case "float,float":
+			if _, ok := floatOp(ins.Op, 1, 1); !ok {
+				continue
+			}
+			op = OpFloatOp
+		default:
+			continue
+		}
+		u.fn.Instructions[i] = Instruction{Op: op, Operand2: int(ins.Op)}
+		report.add(u, u.fn.Lines[i], "%s specialised for %s (%d runs)", ins.Op, types[:len(types)/2], total)
+	}
+}
+
+// layoutBranches reorders hot if/else statements and while loops
+func (u *pgoUnit) layoutBranches(profile *pgo.ProfileData, report *PGOReport) {
+	var branches []int
+	for i, ins := range u.fn.Instructions {
+		if ins.Op != OpJumpIfFalse {
+			continue
+		}
+		site := u.site(i)
+		branch := profile.Branches[site]
+		if branch.Total >= pgoHotCount && branch.Probability() < pgoHotBranch {
+			branches = append(branches, u.origin[i])
+		}
+	}
+
+	// Each rewrite moves code, so branches are found again by origin
+	for _, origin := range branches {
+		i := u.find(origin)
+		if i < 0 {
+			continue
+		}
+		site, line := u.site(i), u.fn.Lines[i]
+		if u.rotateLoop(i) {
+			report.add(u, line, "while loop rotated to test at the bottom (%d iterations)", profile.HotPaths[site])
+		} else if u.swapBranches(i) {
+			branch := profile.Branches[site]
+			report.add(u, line, "hot then-branch moved to fall through (else taken %d of %d times)", branch.Taken, branch.Total)
+		}
+	}
+}
+
+// find returns the current index of the instruction with an origin
+func (u *pgoUnit) find(origin int) int {
+	for i, o := range u.origin {
+		if o == origin {
+			return i
+		}
+	}
+	return -1
+}
+
+// rotateLoop turns the while loop exited by the branch at i
+//
+//	L: cond; JUMP_IF_FALSE X; body; LOOP L; X:
+//
+// into
+//
+//	JUMP C; B: body; C: cond; JUMP_IF_TRUE B; X:
+func (u *pgoUnit) rotateLoop(i int) bool {
+	code := u.fn.Instructions
+	x := code[i].Operand
+	if x < i+2 || x > len(code) || code[x-1].Op != OpLoop {
+		return false
+	}
+	l := code[x-1].Operand
+	if l > i {
+		return false
+	}
+
+	r := u.rewrite()
+	r.copy(0, l)
+	r.add(Instruction{Op: OpJump, Operand: l}, l, -1)
+	r.copy(i+1, x-1)
+	r.copy(l, i)
+	r.replace(i, Instruction{Op: OpJumpIfTrue, Operand: i + 1})
+	r.drop(x-1, l)
+	r.copy(x, len(code))
+	r.finish()
+	return true
+}
+
+// swapBranches lays out the if/else branching at i
+//
+//	cond; JUMP_IF_FALSE T; then; JUMP E; T: else; E:
+//
+// as
+//
+//	cond; JUMP_IF_TRUE S; else; JUMP E; S: then; E:
+func (u *pgoUnit) swapBranches(i int) bool {
+	code := u.fn.Instructions
+	t := code[i].Operand
+	if t < i+2 || t > len(code) || code[t-1].Op != OpJump {
+		return false
+	}
+	e := code[t-1].Operand
+	if e <= t || e > len(code) {
+		return false
+	}
+
+	r := u.rewrite()
+	r.copy(0, i)
+	r.replace(i, Instruction{Op: OpJumpIfTrue, Operand: i + 1})
+	r.copy(t, e)
+	r.add(Instruction{Op: OpJump, Operand: e}, e-1, -1)
+	r.copy(i+1, t-1)
+	r.drop(t-1, e)
+	r.copy(e, len(code))
+	r.finish()
+	return true
+}
+
+// rewriter builds a new layout of a unit's code. Jump targets of copied
+// and added instructions are old indexes unless added as fixed.
+type rewriter struct {
+	u      *pgoUnit
+	code   []Instruction
+	lines  []int
+	origin []int
+	fixed  []bool // target is already a new index
+	moved  map[int]int
+	alias  map[int]int // dropped old index -> old index execution continues at
+}
+
+func (u *pgoUnit) rewrite() *rewriter {
+	return &rewriter{u: u, moved: make(map[int]int), alias: make(map[int]int)}
+}
+
+// copy moves old instructions [from, to)
+func (r *rewriter) copy(from, to int) {
+	for i := from; i < to; i++ {
+		r.moved[i] = len(r.code)
+		r.emit(r.u.fn.Instructions[i], r.u.fn.Lines[i], r.u.origin[i], false)
+	}
+}
+
+// replace puts ins in place of old instruction i
+func (r *rewriter) replace(i int, ins Instruction) {
+	r.moved[i] = len(r.code)
+	r.emit(ins, r.u.fn.Lines[i], r.u.origin[i], false)
+}
+
+// add appends a new instruction with the line of old instruction at
+func (r *rewriter) add(ins Instruction, at, origin int) {
+	r.emit(ins, r.u.fn.Lines[at], origin, false)
+}
+
+// drop removes old instruction i; jumps to it go to old index next
+func (r *rewriter) drop(i, next int) {
+	r.alias[i] = next
+}
+
+func (r *rewriter) emit(ins Instruction, line, origin int, fixed bool) {
+	r.code = append(r.code, ins)
+	r.lines = append(r.lines, line)
+	r.origin = append(r.origin, origin)
+	r.fixed = append(r.fixed, fixed)
+}
+
+// finish remaps jump targets and stores the new code in the unit
+func (r *rewriter) finish() {
+	end := len(r.u.fn.Instructions)
+	target := func(old int) int {
+		for hops := 0; hops <= end; hops++ {
+			if old == end {
+				return len(r.code)
+			}
+			if next, ok := r.alias[old]; ok {
+				old = next
+				continue
+			}
+			return r.moved[old]
+		}
+		return r.moved[old]
+	}
+	for i := range r.code {
+		if hasTarget(r.code[i].Op) && !r.fixed[i] {
+			r.code[i].Operand = target(r.code[i].Operand)
+		}
+	}
+	r.u.fn.Instructions, r.u.fn.Lines, r.u.origin = r.code, r.lines, r.origin
+}
+
+// inliner inlines hot calls of small top-level functions
+type inliner struct {
+	protos map[string]*CompiledFunction // function each inlinable global holds
+	bodies map[string][]Instruction     // its code before any inlining
+	locals map[string]int               // its local slots before any inlining
+	guards map[string]int               // guard constant of each unit and function
+}
+
+// newInliner finds the functions that can be inlined: small functions
+// defined at top level and never assigned anywhere else
+func newInliner(main *CompiledFunction, units []*pgoUnit) *inliner {
+	in := &inliner{
+		protos: make(map[string]*CompiledFunction),
+		bodies: make(map[string][]Instruction),
+		locals: make(map[string]int),
+		guards: make(map[string]int),
+	}
+	stores := make(map[string]int)
+	for _, u := range units {
+		for _, ins := range u.fn.Instructions {
+			if ins.Op == OpSetGlobal {
+				stores[ins.Name]++
+			}
+		}
+	}
+
+	for i := 1; i < len(main.Instructions); i++ {
+		closure, set := main.Instructions[i-1], main.Instructions[i]
+		if closure.Op != OpClosure || set.Op != OpSetGlobal || stores[set.Name] != 1 {
+			continue
+		}
+		if fn, ok := main.Constants[closure.Operand].(*CompiledFunction); ok && inlinable(fn, set.Name) {
+			in.protos[set.Name] = fn
+			in.bodies[set.Name] = append([]Instruction(nil), fn.Instructions...)
+			in.locals[set.Name] = fn.LocalCount
+		}
+	}
+	return in
+}
+
+// inlinable reports whether fn, stored in global name, can be inlined:
+// a small plain function that does not call itself, capture variables or
+// use instructions that depend on having its own frame
+func inlinable(fn *CompiledFunction, name string) bool {
+	n := len(fn.Instructions)
+	if fn.Async || fn.Coop || fn.Variadic || fn.Method || len(fn.Upvalues) > 0 ||
+		n == 0 || n > maxInlineSize || fn.Instructions[n-1].Op != OpReturn {
+		return false
+	}
+	for _, ins := range fn.Instructions {
+		switch ins.Op {
+		case OpConstant, OpPop, OpDup, OpTrue, OpFalse, OpNil,
+			OpGetLocal, OpSetLocal, OpSetGlobal,
+			OpAdd, OpSub, OpMul, OpDiv, OpMod, OpNegate, OpIntOp, OpFloatOp,
+			OpEqual, OpNotEqual, OpGreaterThan, OpGreaterEqual, OpLessThan, OpLessEqual,
+			OpAnd, OpOr, OpNot, OpJump, OpJumpIfFalse, OpJumpIfTrue, OpLoop,
+			OpCall, OpInvoke, OpList, OpDict, OpIndex, OpSetIndex,
+			OpGetMember, OpSetMember, OpPrint, OpLen, OpReturn:
+		case OpGetGlobal:
+			if ins.Name == name {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// inlineCalls inlines the hot calls of u
+func (in *inliner) inlineCalls(u *pgoUnit, profile *pgo.ProfileData, report *PGOReport) {
+	var calls []int
+	for i, ins := range u.fn.Instructions {
+		if ins.Op == OpCall && profile.HotPaths[u.site(i)] >= pgoHotCount {
+			calls = append(calls, u.origin[i])
+		}
+	}
+
+	for _, origin := range calls {
+		i := u.find(origin)
+		if i < 0 {
+			continue
+		}
+		g := u.callee(i)
+		if g < 0 {
+			continue
+		}
+		name := u.fn.Instructions[g].Name
+		proto, ok := in.protos[name]
+		if !ok || proto == u.fn || proto.Arity != u.fn.Instructions[i].Operand {
+			continue
+		}
+		count, line := profile.HotPaths[u.site(i)], u.fn.Lines[i]
+		in.inline(u, i, name)
+		report.add(u, line, "call of %s inlined (%d calls)", name, count)
+	}
+}
+
+// inline replaces the call at i with the body of global name. The guard
+// moves the arguments to local slots of the body; returns continue after
+// the call, which moves out of line as the path taken when the guard fails.
+func (in *inliner) inline(u *pgoUnit, i int, name string) {
+	proto := in.protos[name]
+	base, guard := in.slots(u, name)
+
+	r := u.rewrite()
+	r.copy(0, i)
+	r.add(Instruction{Op: OpInlineGuard, Operand: i, Operand2: guard, Name: name}, i, -1)
+	start := len(r.code)
+	line := u.fn.Lines[i]
+	for _, ins := range in.bodies[name] {
+		fixed := false
+		switch {
+		case ins.Op == OpGetLocal || ins.Op == OpSetLocal:
+			ins.Operand += base
+		case ins.Op == OpConstant:
+			ins.Operand = u.addConstant(proto.Constants[ins.Operand])
+		case ins.Op == OpReturn:
+			ins = Instruction{Op: OpJump, Operand: i + 1}
+		case hasTarget(ins.Op):
+			ins.Operand += start
+			fixed = true
+		}
+		r.emit(ins, line, -1, fixed)
+	}
+	r.copy(i+1, len(u.fn.Instructions))
+	r.copy(i, i+1)
+	r.add(Instruction{Op: OpJump, Operand: i + 1}, i, -1)
+	r.finish()
+}
+
+// slots returns the first local slot of the body of global name inlined
+// into u, and the constant index of the function followed by that slot.
+// Every call of name in u shares the slots: inlined bodies do not nest and
+// cannot be reentered.
+func (in *inliner) slots(u *pgoUnit, name string) (base, guard int) {
+	key := u.name + "\x00" + name
+	if guard, ok := in.guards[key]; ok {
+		return int(u.fn.Constants[guard+1].(int64)), guard
+	}
+	base = u.fn.LocalCount
+	u.fn.LocalCount += in.locals[name]
+	guard = len(u.fn.Constants)
+	u.fn.Constants = append(u.fn.Constants, in.protos[name], int64(base))
+	in.guards[key] = guard
+	return base, guard
+}
+
+// callee returns the index of the GET_GLOBAL loading the function called
+// at i, or -1 unless every argument is a simple expression
+func (u *pgoUnit) callee(i int) int {
+	code := u.fn.Instructions
+	argc := code[i].Operand
+	targets := make(map[int]bool)
+	for _, ins := range code {
+		if hasTarget(ins.Op) {
+			targets[ins.Operand] = true
+		}
+	}
+
+	// Walk back until the instructions since j push exactly the arguments
+	j, depth := i, 0
+	for depth != argc || j == i && argc > 0 {
+		j--
+		if j < 0 || targets[j+1] {
+			return -1
+		}
+		pops, pushes, ok := stackEffect(code[j])
+		if !ok {
+			return -1
+		}
+		depth += pushes - pops
+	}
+	if j == 0 || targets[j] || code[j-1].Op != OpGetGlobal {
+		return -1
+	}
+
+	// The arguments must not consume anything pushed before them
+	depth = 0
+	for k := j; k < i; k++ {
+		pops, pushes, _ := stackEffect(code[k])
+		if depth -= pops; depth < 0 {
+			return -1
+		}
+		depth += pushes
+	}
+	return j - 1
+}
+
+// stackEffect returns how many values a side-effect free instruction pops
+// and pushes; ok is false for other instructions
+func stackEffect(ins Instruction) (pops, pushes int, ok bool) {
+	switch ins.Op {
+	case OpConstant, OpGetLocal, OpGetGlobal, OpGetUpvalue, OpTrue, OpFalse, OpNil:
+		return 0, 1, true
+	case OpNegate, OpNot, OpGetMember, OpLen:
+		return 1, 1, true
+	case OpIndex, OpIntOp, OpFloatOp:
+		return 2, 1, true
+	case OpList:
+		return ins.Operand, 1, true
+	case OpDict:
+		return 2 * ins.Operand, 1, true
+	}
+	if _, ok := binaryOperators[ins.Op]; ok {
+		return 2, 1, true
+	}
+	return 0, 0, false
+}
+
+// addConstant returns the index of a constant of u, adding it if needed
+func (u *pgoUnit) addConstant(c interface{}) int {
+	for idx, existing := range u.fn.Constants {
+		if existing == c {
+			return idx
+		}
+	}
+	u.fn.Constants = append(u.fn.Constants, c)
+	return len(u.fn.Constants) - 1
+}
@@ -0,0 +1,119 @@
+package vm
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pgo "github.com/mburakmmm/sky-lang/internal/optimizer"
+)
+
+// profile runs source at -O0 with a profiler and returns the saved profile
+func profile(t *testing.T, source string) *pgo.ProfileData {
+	t.Helper()
+	profiler := pgo.NewPGOProfiler()
+	if _, err := capture(t, func() error {
+		machine := NewVM(compile(t, source, 0))
+		machine.SetProfiler(profiler)
+		return machine.Run()
+	}); err != nil {
+		t.Fatalf("profiled run failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "profile.json")
+	if err := profiler.SaveProfile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := pgo.LoadProfile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestApplyProfile(t *testing.T) {
+	source := `function add(a, b)
+  return a + b
+end
+let total = 0
+let x = 0.5
+let i = 0
+while i < 500
+  if i % 10 == 0
+    total = add(total, 2)
+  else
+    total = add(total, 1)
+  end
+  x = x * 1.0
+  i += 1
+end
+print(total, x)`
+	want, err := run(t, compile(t, source, 0), "test.sky")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := profile(t, source)
+
+	for level := 0; level <= MaxOptLevel; level++ {
+		bc := compile(t, source, 0)
+		report := ApplyProfile(bc, data)
+		Optimize(bc, level)
+
+		changes := strings.Join(report.Changes, "\n")
+		for _, change := range []string{"ADD specialised for int", "MUL specialised for float", "call of add inlined"} {
+			if !strings.Contains(changes, change) {
+				t.Errorf("-O%d: no %q in the report:\n%s", level, change, changes)
+			}
+		}
+		if got, err := run(t, bc, "test.sky"); err != nil || got != want {
+			t.Errorf("-O%d: optimized program printed %q (%v), want %q", level, got, err, want)
+		}
+	}
+}
+
+// TestApplyProfileBranches checks that branches are laid out by how often
+// they were taken over the whole run, not by their last runs
+func TestApplyProfileBranches(t *testing.T) {
+	loop := func(cond string) string {
+		return `let a = 0
+let b = 0
+let i = 0
+while i < 3000
+  if ` + cond + `
+    a += 1
+  else
+    b += 1
+  end
+  i += 1
+end
+print(a, b)`
+	}
+	tests := []struct {
+		name   string
+		source string
+		want   string // printed output
+		change string // then-branch change in the report, "" for none
+	}{
+		{"hot else", loop("i >= 2975"), "25 2975\n", ""},
+		{"hot then", loop("i < 2975"), "2975 25\n", "hot then-branch moved to fall through (else taken 25 of 3000 times)"},
+	}
+
+	for _, tt := range tests {
+		data := profile(t, tt.source)
+		bc := compile(t, tt.source, 0)
+		report := ApplyProfile(bc, data)
+
+		var change string
+		for _, c := range report.Changes {
+			if strings.Contains(c, "then-branch") {
+				change = c
+			}
+		}
+		if tt.change == "" && change != "" || !strings.Contains(change, tt.change) {
+			t.Errorf("%s: then-branch change %q, want %q", tt.name, change, tt.change)
+		}
+		if got, err := run(t, bc, "test.sky"); err != nil || got != tt.want {
+			t.Errorf("%s: optimized program printed %q (%v), want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,550 @@
+package vm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// .skyc file format (all integers are varints, strings are length-prefixed):
+//
+//	magic "SKYC", format version
+//	source hash, optimization level
+//	function table: every compiled function, referenced by index
+//	named functions: name -> function index
+//	main code: local count, instructions, line table, constants
+//	CRC-32 (IEEE) of everything before it, 4 bytes big-endian
+//
+// A function is its name, arity, parameters, flags, local count, upvalues,
+// instructions, line table and constants. Function constants refer to the
+// function table by index.
+
+// SkycMagic identifies compiled SKY bytecode files
+const SkycMagic = "SKYC"
+
+// SkycVersion is bumped whenever the instruction set or file layout changes;
+// files with another version are rejected and cached files are recompiled
+const SkycVersion = 5
+
+// ErrSkycVersion is returned for .skyc files written by another format version
+var ErrSkycVersion = errors.New("unsupported .skyc version")
+
+// Constant tags
+const (
+	constNil byte = iota
+	constInt
+	constFloat
+	constString
+	constBool
+	constFunction
+)
+
+// Function flags
+const (
+	flagAsync = 1 << iota
+	flagCoop
+	flagVariadic
+	flagMethod
+)
+
+// HashSource returns the hex SHA-256 of source code
+func HashSource(source []byte) string {
+	sum := sha256.Sum256(source)
+	return hex.EncodeToString(sum[:])
+}
+
+// WriteSkyc writes bytecode to a .skyc file
+func WriteSkyc(path string, bc *Bytecode) error {
+	data, err := bc.Encode()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ReadSkyc loads bytecode from a .skyc file
+func ReadSkyc(path string) (*Bytecode, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	bc, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return bc, nil
+}
+
+// Encode serializes bytecode in the .skyc format
+func (bc *Bytecode) Encode() ([]byte, error) {
+	w := &skycWriter{index: make(map[*CompiledFunction]int)}
+
+	// Number functions reachable from the named table and from constants
+	names := make([]string, 0, len(bc.Functions))
+	for name := range bc.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		w.collect(bc.Functions[name])
+	}
+	for _, c := range bc.Constants {
+		if fn, ok := c.(*CompiledFunction); ok {
+			w.collect(fn)
+		}
+	}
+
+	w.buf.WriteString(SkycMagic)
+	w.uint(SkycVersion)
+	w.string(bc.SourceHash)
+	w.uint(uint64(bc.OptLevel))
+
+	w.uint(uint64(len(w.funcs)))
+	for _, fn := range w.funcs {
+		if err := w.function(fn); err != nil {
+			return nil, err
+		}
+	}
+
+	w.uint(uint64(len(names)))
+	for _, name := range names {
+		w.string(name)
+		w.uint(uint64(w.index[bc.Functions[name]]))
+	}
+
+	w.uint(uint64(bc.LocalCount))
+	if err := w.code(bc.Instructions, bc.Lines, bc.Constants); err != nil {
+		return nil, err
+	}
+	w.buf.Write(binary.BigEndian.AppendUint32(nil, crc32.ChecksumIEEE(w.buf.Bytes())))
+	return w.buf.Bytes(), nil
+}
+
+// Decode parses bytecode in the .skyc format. Files that fail the checksum
+// or whose operands point outside their function are rejected, so the VM
+// never runs a corrupt file.
+func Decode(data []byte) (*Bytecode, error) {
+	if !bytes.HasPrefix(data, []byte(SkycMagic)) {
+		return nil, errors.New("not a .skyc file")
+	}
+	r := &skycReader{r: bytes.NewReader(data[len(SkycMagic):])}
+
+	if version := r.uint(); version != SkycVersion {
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, fmt.Errorf("%w %d (expected %d)", ErrSkycVersion, version, SkycVersion)
+	}
+	if len(data) < len(SkycMagic)+4 {
+		return nil, errors.New("corrupt .skyc file: truncated")
+	}
+	payload := data[:len(data)-4]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(data[len(payload):]) {
+		return nil, errors.New("corrupt .skyc file: checksum mismatch")
+	}
+	r.r = bytes.NewReader(payload[len(SkycMagic):])
+	r.uint() // version
+	bc := &Bytecode{SourceHash: r.string(), Functions: make(map[string]*CompiledFunction)}
+	bc.OptLevel = int(r.uint())
+
+	// Functions are allocated first so constants can refer to any of them
+	r.funcs = make([]*CompiledFunction, r.count())
+	for i := range r.funcs {
+		r.funcs[i] = &CompiledFunction{}
+	}
+	for _, fn := range r.funcs {
+		r.function(fn)
+	}
+
+	for n := r.count(); n > 0 && r.err == nil; n-- {
+		name := r.string()
+		bc.Functions[name] = r.funcRef()
+	}
+
+	bc.LocalCount = int(r.uint())
+	bc.Instructions, bc.Lines, bc.Constants = r.code()
+	if r.err == nil && r.r.Len() != 0 {
+		r.err = fmt.Errorf("%d bytes of trailing data", r.r.Len())
+	}
+	if r.err == nil {
+		r.err = verify(bc, r.funcs)
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("corrupt .skyc file: %v", r.err)
+	}
+	return bc, nil
+}
+
+// verify checks that every operand of the decoded code refers to an
+// instruction, constant, local slot or upvalue that exists
+func verify(bc *Bytecode, funcs []*CompiledFunction) error {
+	script := &CompiledFunction{
+		Name:         "<script>",
+		Instructions: bc.Instructions,
+		Constants:    bc.Constants,
+		LocalCount:   bc.LocalCount,
+	}
+	for _, fn := range append([]*CompiledFunction{script}, funcs...) {
+		if err := verifyFunction(fn); err != nil {
+			return fmt.Errorf("%s: %v", fn.Name, err)
+		}
+	}
+	return nil
+}
+
+func verifyFunction(fn *CompiledFunction) error {
+	n := len(fn.Instructions)
+	if n == 0 || !endsBlock(fn.Instructions[n-1].Op) {
+		return errors.New("code does not end with a return")
+	}
+	constant := func(idx int) (interface{}, bool) {
+		if idx < 0 || idx >= len(fn.Constants) {
+			return nil, false
+		}
+		return fn.Constants[idx], true
+	}
+	local := func(slot int) bool { return slot >= 0 && slot < fn.LocalCount }
+
+	for ip, ins := range fn.Instructions {
+		ok := ins.Op <= OpHalt
+		if ok && hasTarget(ins.Op) {
+			ok = ins.Operand >= 0 && ins.Operand < n
+		}
+		if ok {
+			switch ins.Op {
+			case OpConstant:
+				_, ok = constant(ins.Operand)
+			case OpGetLocal, OpSetLocal:
+				ok = local(ins.Operand)
+			case OpGetLocal2:
+				ok = local(ins.Operand) && local(ins.Operand2)
+			case OpIncLocal:
+				_, isConst := constant(ins.Operand2)
+				ok = local(ins.Operand) && isConst
+			case OpGetUpvalue, OpSetUpvalue:
+				ok = ins.Operand >= 0 && ins.Operand < len(fn.Upvalues)
+			case OpClosure:
+				c, _ := constant(ins.Operand)
+				nested, isFn := c.(*CompiledFunction)
+				ok = isFn && nested != nil && capturesFrom(nested, fn)
+			case OpInlineGuard:
+				c, _ := constant(ins.Operand2)
+				slot, _ := constant(ins.Operand2 + 1)
+				proto, isFn := c.(*CompiledFunction)
+				s, isInt := slot.(int64)
+				ok = isFn && proto != nil && isInt && local(int(s)) && local(int(s)+proto.Arity)
+			case OpIntOp, OpFloatOp, OpCompareJump:
+				_, ok = binaryOperators[OpCode(ins.Operand2)]
+			case OpCheckArg:
+				c, _ := constant(ins.Operand)
+				_, ok = c.(string)
+				ok = ok && local(ins.Operand2)
+			case OpCheckType:
+				c, _ := constant(ins.Operand)
+				_, ok = c.(string)
+			case OpVariant:
+				c, _ := constant(ins.Operand2)
+				_, ok = c.(string)
+			case OpTestVariant:
+				if ins.Operand2 >= 0 {
+					c, _ := constant(ins.Operand2)
+					_, ok = c.(string)
+				}
+			}
+		}
+		if !ok {
+			return fmt.Errorf("invalid operands at %d: %s", ip, ins)
+		}
+	}
+	return nil
+}
+
+// capturesFrom reports whether the upvalues of nested refer to locals or
+// upvalues of the function creating its closures
+func capturesFrom(nested, outer *CompiledFunction) bool {
+	for _, ref := range nested.Upvalues {
+		if ref.Local && (ref.Index < 0 || ref.Index >= outer.LocalCount) {
+			return false
+		}
+		if !ref.Local && (ref.Index < 0 || ref.Index >= len(outer.Upvalues)) {
+			return false
+		}
+	}
+	return true
+}
+
+type skycWriter struct {
+	buf   bytes.Buffer
+	funcs []*CompiledFunction
+	index map[*CompiledFunction]int
+}
+
+// collect assigns table indexes to fn and the functions it references
+func (w *skycWriter) collect(fn *CompiledFunction) {
+	if _, ok := w.index[fn]; ok {
+		return
+	}
+	w.index[fn] = len(w.funcs)
+	w.funcs = append(w.funcs, fn)
+	for _, c := range fn.Constants {
+		if nested, ok := c.(*CompiledFunction); ok {
+			w.collect(nested)
+		}
+	}
+}
+
+func (w *skycWriter) uint(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	w.buf.Write(tmp[:binary.PutUvarint(tmp[:], v)])
+}
+
+func (w *skycWriter) int(v int64) {
+	var tmp [binary.MaxVarintLen64]byte
+	w.buf.Write(tmp[:binary.PutVarint(tmp[:], v)])
+}
+
+func (w *skycWriter) string(s string) {
+	w.uint(uint64(len(s)))
+	w.buf.WriteString(s)
+}
+
+func (w *skycWriter) function(fn *CompiledFunction) error {
+	w.string(fn.Name)
+	w.uint(uint64(fn.Arity))
+	w.uint(uint64(len(fn.Params)))
+	for _, param := range fn.Params {
+		w.string(param)
+	}
+
+	var flags uint64
+	if fn.Async {
+		flags |= flagAsync
+	}
+	if fn.Coop {
+		flags |= flagCoop
+	}
+	if fn.Variadic {
+		flags |= flagVariadic
+	}
+	if fn.Method {
+		flags |= flagMethod
+	}
+	w.uint(flags)
+	w.uint(uint64(fn.LocalCount))
+
+	w.uint(uint64(len(fn.Upvalues)))
+	for _, up := range fn.Upvalues {
+		w.uint(uint64(up.Index))
+		if up.Local {
+			w.uint(1)
+		} else {
+			w.uint(0)
+		}
+	}
+	return w.code(fn.Instructions, fn.Lines, fn.Constants)
+}
+
+// code writes instructions, the line table and constants
+func (w *skycWriter) code(instructions []Instruction, lines []int, constants []interface{}) error {
+	w.uint(uint64(len(instructions)))
+	for _, ins := range instructions {
+		w.uint(uint64(ins.Op))
+		w.int(int64(ins.Operand))
+		w.int(int64(ins.Operand2))
+		w.string(ins.Name)
+	}
+
+	// Line table: (first instruction, line) for each run of equal lines
+	type run struct{ ip, line int }
+	var runs []run
+	for ip, line := range lines {
+		if len(runs) == 0 || runs[len(runs)-1].line != line {
+			runs = append(runs, run{ip, line})
+		}
+	}
+	w.uint(uint64(len(runs)))
+	for _, r := range runs {
+		w.uint(uint64(r.ip))
+		w.uint(uint64(r.line))
+	}
+
+	w.uint(uint64(len(constants)))
+	for _, c := range constants {
+		switch v := c.(type) {
+		case nil:
+			w.buf.WriteByte(constNil)
+		case int64:
+			w.buf.WriteByte(constInt)
+			w.int(v)
+		case float64:
+			w.buf.WriteByte(constFloat)
+			w.uint(math.Float64bits(v))
+		case string:
+			w.buf.WriteByte(constString)
+			w.string(v)
+		case bool:
+			w.buf.WriteByte(constBool)
+			if v {
+				w.uint(1)
+			} else {
+				w.uint(0)
+			}
+		case *CompiledFunction:
+			w.buf.WriteByte(constFunction)
+			w.uint(uint64(w.index[v]))
+		default:
+			return fmt.Errorf("cannot encode constant of type %T", c)
+		}
+	}
+	return nil
+}
+
+type skycReader struct {
+	r     *bytes.Reader
+	funcs []*CompiledFunction
+	err   error
+}
+
+func (r *skycReader) uint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(r.r)
+	r.err = err
+	return v
+}
+
+func (r *skycReader) int() int {
+	return int(r.int64())
+}
+
+func (r *skycReader) int64() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(r.r)
+	r.err = err
+	return v
+}
+
+// count reads a length, rejecting values larger than the remaining data
+func (r *skycReader) count() int {
+	n := r.uint()
+	if r.err == nil && n > uint64(r.r.Len()) {
+		r.err = fmt.Errorf("length %d out of range", n)
+		return 0
+	}
+	return int(n)
+}
+
+func (r *skycReader) string() string {
+	n := r.count()
+	if r.err != nil {
+		return ""
+	}
+	buf := make([]byte, n)
+	_, r.err = io.ReadFull(r.r, buf)
+	return string(buf)
+}
+
+func (r *skycReader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	b, err := r.r.ReadByte()
+	r.err = err
+	return b
+}
+
+func (r *skycReader) funcRef() *CompiledFunction {
+	idx := r.uint()
+	if r.err == nil && idx >= uint64(len(r.funcs)) {
+		r.err = fmt.Errorf("function index %d out of range", idx)
+	}
+	if r.err != nil {
+		return nil
+	}
+	return r.funcs[idx]
+}
+
+func (r *skycReader) function(fn *CompiledFunction) {
+	fn.Name = r.string()
+	fn.Arity = int(r.uint())
+	for n := r.count(); n > 0 && r.err == nil; n-- {
+		fn.Params = append(fn.Params, r.string())
+	}
+
+	flags := r.uint()
+	fn.Async = flags&flagAsync != 0
+	fn.Coop = flags&flagCoop != 0
+	fn.Variadic = flags&flagVariadic != 0
+	fn.Method = flags&flagMethod != 0
+	fn.LocalCount = int(r.uint())
+
+	for n := r.count(); n > 0 && r.err == nil; n-- {
+		fn.Upvalues = append(fn.Upvalues, UpvalueRef{Index: int(r.uint()), Local: r.uint() == 1})
+	}
+	fn.Instructions, fn.Lines, fn.Constants = r.code()
+}
+
+func (r *skycReader) code() ([]Instruction, []int, []interface{}) {
+	instructions := make([]Instruction, r.count())
+	for i := range instructions {
+		instructions[i] = Instruction{
+			Op:       OpCode(r.uint()),
+			Operand:  r.int(),
+			Operand2: r.int(),
+			Name:     r.string(),
+		}
+	}
+
+	// Expand the line table back to one line per instruction
+	lines := make([]int, len(instructions))
+	runs := r.count()
+	prevIP, prevLine := 0, 0
+	for i := 0; i < runs && r.err == nil; i++ {
+		ip, line := int(r.uint()), int(r.uint())
+		if ip > len(lines) {
+			r.err = fmt.Errorf("line table entry %d out of range", ip)
+			break
+		}
+		for j := prevIP; j < ip; j++ {
+			lines[j] = prevLine
+		}
+		prevIP, prevLine = ip, line
+	}
+	for j := prevIP; j < len(lines); j++ {
+		lines[j] = prevLine
+	}
+
+	constants := make([]interface{}, r.count())
+	for i := range constants {
+		switch tag := r.byte(); tag {
+		case constNil:
+			constants[i] = nil
+		case constInt:
+			constants[i] = r.int64()
+		case constFloat:
+			constants[i] = math.Float64frombits(r.uint())
+		case constString:
+			constants[i] = r.string()
+		case constBool:
+			constants[i] = r.uint() == 1
+		case constFunction:
+			constants[i] = r.funcRef()
+		default:
+			if r.err == nil {
+				r.err = fmt.Errorf("unknown constant tag %d", tag)
+			}
+		}
+	}
+	return instructions, lines, constants
+}
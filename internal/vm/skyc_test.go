@@ -0,0 +1,170 @@
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mburakmmm/sky-lang/internal/backendtest"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	for _, file := range programs(t) {
+		for level := 0; level <= MaxOptLevel; level++ {
+			bc := compileFile(t, file, level)
+			bc.SourceHash = "hash"
+			data, err := bc.Encode()
+			if err != nil {
+				t.Fatalf("%s -O%d: encode failed: %v", file, level, err)
+			}
+
+			decoded, err := Decode(data)
+			if err != nil {
+				t.Fatalf("%s -O%d: decode failed: %v", file, level, err)
+			}
+			if decoded.SourceHash != "hash" || decoded.OptLevel != bc.OptLevel || decoded.LocalCount != bc.LocalCount {
+				t.Errorf("%s -O%d: header differs after decoding", file, level)
+			}
+			if len(decoded.Functions) != len(bc.Functions) {
+				t.Errorf("%s -O%d: %d functions decoded, want %d", file, level, len(decoded.Functions), len(bc.Functions))
+			}
+			again, err := decoded.Encode()
+			if err != nil || !bytes.Equal(again, data) {
+				t.Errorf("%s -O%d: encoding the decoded bytecode gives other bytes (%v)", file, level, err)
+			}
+
+			want, wantErr := run(t, bc, file)
+			got, gotErr := run(t, decoded, file)
+			if got != want || (gotErr == nil) != (wantErr == nil) {
+				t.Errorf("%s -O%d: decoded bytecode printed\n%s(%v)\nwant\n%s(%v)", file, level, got, gotErr, want, wantErr)
+			}
+		}
+	}
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	bc := compile(t, "function f(n)\n  return n * 2\nend\nprint(f(21))", 1)
+	data, err := bc.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := len(SkycMagic) + 1; i < len(data); i++ {
+		corrupt := append([]byte(nil), data...)
+		corrupt[i] ^= 0x5a
+		if _, err := Decode(corrupt); err == nil {
+			t.Errorf("byte %d flipped: decoded without error", i)
+		}
+	}
+	for n := 0; n < len(data); n++ {
+		if _, err := Decode(data[:n]); err == nil {
+			t.Errorf("truncated to %d bytes: decoded without error", n)
+		}
+	}
+
+	old := append([]byte(SkycMagic), byte(SkycVersion-1))
+	if _, err := Decode(old); !errors.Is(err, ErrSkycVersion) {
+		t.Errorf("old version: got %v, want ErrSkycVersion", err)
+	}
+}
+
+// TestDecodeInvalidOperands checks files whose checksum is right but whose
+// operands point outside their function
+func TestDecodeInvalidOperands(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(bc *Bytecode)
+	}{
+		{"constant", func(bc *Bytecode) {
+			bc.Instructions[0] = Instruction{Op: OpConstant, Operand: len(bc.Constants)}
+		}},
+		{"jump", func(bc *Bytecode) {
+			bc.Instructions[0] = Instruction{Op: OpJump, Operand: len(bc.Instructions)}
+		}},
+		{"local", func(bc *Bytecode) {
+			bc.Instructions[0] = Instruction{Op: OpGetLocal, Operand: bc.LocalCount}
+		}},
+		{"upvalue", func(bc *Bytecode) {
+			bc.Instructions[0] = Instruction{Op: OpGetUpvalue, Operand: 0}
+		}},
+		{"opcode", func(bc *Bytecode) {
+			bc.Instructions[0] = Instruction{Op: OpHalt + 1}
+		}},
+		{"closure", func(bc *Bytecode) {
+			bc.Instructions[0] = Instruction{Op: OpClosure, Operand: 0}
+		}},
+		{"end", func(bc *Bytecode) {
+			bc.Instructions = bc.Instructions[:len(bc.Instructions)-1]
+			bc.Lines = bc.Lines[:len(bc.Lines)-1]
+		}},
+	}
+
+	for _, tt := range tests {
+		bc := compile(t, `print("a", 1)`, 0)
+		tt.modify(bc)
+		data, err := bc.Encode()
+		if err != nil {
+			t.Fatalf("%s: encode failed: %v", tt.name, err)
+		}
+		if _, err := Decode(data); err == nil {
+			t.Errorf("%s: decoded without error", tt.name)
+		}
+	}
+}
+
+func TestCacheSkipsCorruptEntries(t *testing.T) {
+	cache := &Cache{Dir: t.TempDir()}
+	bc := compile(t, "print(1)", 1)
+	bc.SourceHash = "abc"
+	if err := cache.Store(bc, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Load("abc", 1, false); !ok {
+		t.Fatal("stored entry not found")
+	}
+	if _, ok := cache.Load("abc", 0, false); ok {
+		t.Error("entry found for another optimization level")
+	}
+
+	path := cache.path("abc", 1, false)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)/2] ^= 0xff
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Load("abc", 1, false); ok {
+		t.Error("corrupt entry loaded")
+	}
+}
+
+// programs returns the conformance programs shared with the native backends
+func programs(t *testing.T) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(backendtest.Dir, "*.sky"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no programs in " + backendtest.Dir)
+	}
+	return files
+}
+
+func compileFile(t *testing.T, file string, level int) *Bytecode {
+	t.Helper()
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bc, err := NewCompiler().Compile(parse(t, string(content), file))
+	if err != nil {
+		t.Fatalf("%s: compile failed: %v", file, err)
+	}
+	Optimize(bc, level)
+	return bc
+}
@@ -0,0 +1,119 @@
+package vm
+
+import (
+	"errors"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+)
+
+// Tier runs hot functions of the interpreter on the VM. A promoted
+// function is compiled on its own; its globals are the variables of the
+// interpreter environment it was defined in, so both engines see the same
+// state.
+type Tier struct {
+	program    *ast.Program
+	vm         *VM
+	modules    map[*interpreter.Environment]*module
+	typeChecks bool
+}
+
+// NewTier returns a tier for functions of program
+func NewTier(program *ast.Program) *Tier {
+	return &Tier{
+		program: program,
+		vm:      NewVM(&Bytecode{}),
+		modules: make(map[*interpreter.Environment]*module),
+	}
+}
+
+// SetSourceFile sets the script path used to resolve imports
+func (t *Tier) SetSourceFile(path string) {
+	t.vm.SetSourceFile(path)
+}
+
+// SetTypeChecks compiles promoted functions with runtime type checks, for
+// interpreters running with them
+func (t *Tier) SetTypeChecks(on bool) {
+	t.typeChecks = on
+	t.vm.SetTypeChecks(on)
+}
+
+// Compile implements interpreter.Tier. Functions using constructs that
+// need the interpreter's own state (classes, imports, self, super), that
+// suspend (yield, await) or whose nested functions the engines run
+// differently are rejected and stay interpreted.
+func (t *Tier) Compile(stmt *ast.FunctionStatement, env *interpreter.Environment, level int) (interpreter.TierFunction, error) {
+	if stmt.Async || stmt.Coop {
+		return nil, errors.New(i18n.T("runtime.tier_coroutine"))
+	}
+
+	outer := func(name string) bool {
+		_, ok := env.Get(name)
+		return ok
+	}
+	compiler := NewCompiler()
+	compiler.SetTypeChecks(t.typeChecks)
+	fn, err := compiler.CompileFunction(t.program, stmt, outer)
+	if err != nil {
+		return nil, err
+	}
+	if err := tierSupported(fn); err != nil {
+		return nil, err
+	}
+	Optimize(&Bytecode{Constants: []interface{}{fn}}, level)
+
+	closure := &Closure{Fn: fn, module: t.module(env)}
+	return func(args []interpreter.Value) (interpreter.Value, error) {
+		result, err := t.vm.call(closure, args)
+		if err != nil {
+			return nil, err
+		}
+		return t.vm.export(result), nil
+	}, nil
+}
+
+// module returns the module whose globals are the variables of env
+func (t *Tier) module(env *interpreter.Environment) *module {
+	mod, ok := t.modules[env]
+	if !ok {
+		mod = &module{path: "<tier>", globals: make(map[string]*global), env: env}
+		t.modules[env] = mod
+	}
+	return mod
+}
+
+// tierSupported reports the first instruction of fn or its nested
+// functions that a promoted function cannot run. Nested functions must
+// not change what the program prints once promoted: the interpreter
+// memoizes calls of named functions by name and arguments and resolves
+// the free variables of lambdas where they are called, so only lambdas
+// that capture nothing run the same on the VM.
+func tierSupported(fn *CompiledFunction) error {
+	for _, ins := range fn.Instructions {
+		switch ins.Op {
+		case OpYield, OpAwait, OpClass, OpMethod, OpInterface, OpImplements, OpEnum, OpVariant, OpImport,
+			OpGetSuper, OpSuperInvoke:
+			return errors.New(i18n.T("runtime.tier_instruction", ins.Op))
+		case OpGetGlobal, OpSetGlobal:
+			if ins.Name == "self" {
+				return errors.New(i18n.T("runtime.tier_self"))
+			}
+		}
+	}
+	for _, c := range fn.Constants {
+		if nested, ok := c.(*CompiledFunction); ok {
+			if nested.Name != "lambda" {
+				return errors.New(i18n.T("runtime.tier_nested", nested.Name))
+			}
+			if len(nested.Upvalues) > 0 {
+				return errors.New(i18n.T("runtime.tier_captures"))
+			}
+			if err := tierSupported(nested); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+)
+
+// interpretTiered runs source in the interpreter, with hot functions
+// promoted to the VM when tiered is set, and returns its output and the
+// tier trace
+func interpretTiered(t *testing.T, source string, tiered bool) (string, string, error) {
+	t.Helper()
+	program := parse(t, source, "tier.sky")
+	var trace bytes.Buffer
+	output, err := capture(t, func() error {
+		interp := interpreter.New()
+		interp.SetSourceFile("tier.sky")
+		if tiered {
+			interp.SetTier(NewTier(program), &trace)
+		}
+		return interp.Eval(program)
+	})
+	return output, trace.String(), err
+}
+
+// hotLoop calls call n times and prints the sum of the results
+func hotLoop(call string, n int) string {
+	return fmt.Sprintf("let total = 0\nlet i = 0\nwhile i < %d\n  total = total + %s\n  i += 1\nend\nprint(total)\n", n, call)
+}
+
+func TestTierPromotion(t *testing.T) {
+	source := "function square(n)\n  return n * n\nend\n" + hotLoop("square(i)", 6000)
+
+	want, _, wantErr := interpretTiered(t, source, false)
+	got, trace, err := interpretTiered(t, source, true)
+	if err != nil || wantErr != nil {
+		t.Fatalf("run failed: %v / %v", err, wantErr)
+	}
+	if got != want {
+		t.Errorf("tiered run printed %q, interpreter %q", got, want)
+	}
+	for _, line := range []string{
+		"[tier] square:1: interpreter -> vm -O1",
+		"[tier] square:1: vm -O1 -> vm -O2",
+	} {
+		if !strings.Contains(trace, line+"\n") {
+			t.Errorf("trace lacks %q:\n%s", line, trace)
+		}
+	}
+}
+
+func TestTierDeoptimization(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		reason string
+	}{
+		{"nested function", `function count_twice(k)
+  let c = 0
+  function bump()
+    c += 1
+  end
+  bump()
+  bump()
+  return c
+end
+` + hotLoop("count_twice(i)", 300), "nested function bump is not supported"},
+		{"capturing lambda", `function scaled(k)
+  let f = function(x) x * k end
+  return f(2)
+end
+` + hotLoop("scaled(i)", 300), "lambdas capturing variables are not supported"},
+		{"printing nested function", `function greet(k)
+  function say()
+    print("hi")
+  end
+  say()
+  return k
+end
+` + hotLoop("greet(i)", 300), "nested function say is not supported"},
+	}
+
+	for _, tt := range tests {
+		want, _, wantErr := interpretTiered(t, tt.source, false)
+		got, trace, err := interpretTiered(t, tt.source, true)
+		if (err != nil) != (wantErr != nil) || got != want {
+			t.Errorf("%s: tiered run printed %q (%v), interpreter %q (%v)", tt.name, got, err, want, wantErr)
+		}
+		if !strings.Contains(trace, "deoptimized, stays in interpreter: "+tt.reason) {
+			t.Errorf("%s: trace lacks the deoptimization:\n%s", tt.name, trace)
+		}
+		if strings.Contains(trace, "->") {
+			t.Errorf("%s: function was promoted:\n%s", tt.name, trace)
+		}
+	}
+}
+
+// TestTierKeepsOutput checks programs whose promoted functions must run
+// as they do in the interpreter
+func TestTierKeepsOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"unreached division by zero", `function safe(n)
+  if n < 0
+    return 5 % 0
+  end
+  return n
+end
+` + hotLoop("safe(i)", 300) + `print("done")`, "44850\ndone\n"},
+		{"caught division by zero", `function div(n)
+  try
+    return 10 / (n - n)
+  catch e
+    return 1
+  end
+end
+` + hotLoop("div(i)", 300), "300\n"},
+		{"plain lambda", `function apply(k)
+  let f = function(x) x + 1 end
+  return f(k)
+end
+` + hotLoop("apply(i)", 300), "45150\n"},
+	}
+
+	for _, tt := range tests {
+		got, trace, err := interpretTiered(t, tt.source, true)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: printed %q, want %q", tt.name, got, tt.want)
+		}
+		if !strings.Contains(trace, "interpreter -> vm -O1") {
+			t.Errorf("%s: function not promoted:\n%s", tt.name, trace)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package vm
+
+import (
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+)
+
+// Runtime type checks, the VM side of the interpreter's typecheck.go.
+// Annotations are stored as string constants and parsed on first use.
+
+// Kinds of OpCheckType
+const (
+	checkLet = iota
+	checkReturn
+)
+
+// SetTypeChecks makes imported modules compile with runtime type checks
+// and passes call sites to interpreter functions. Checked modules are not
+// cached.
+func (vm *VM) SetTypeChecks(on bool) {
+	vm.typeChecks = on
+}
+
+// runtimeType returns the parsed type constant idx of fn
+func (vm *VM) runtimeType(fn *CompiledFunction, idx int) (*interpreter.RuntimeType, error) {
+	if idx < len(fn.types) && fn.types[idx] != nil {
+		return fn.types[idx], nil
+	}
+	text, _ := fn.Constants[idx].(string)
+	t, err := interpreter.ParseRuntimeType(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(fn.types) < len(fn.Constants) {
+		fn.types = make([]*interpreter.RuntimeType, len(fn.Constants))
+	}
+	fn.types[idx] = t
+	return t, nil
+}
+
+// matchesUnboxed reports values that pass a check without boxing them:
+// nil and numbers or booleans of the annotated basic type
+func matchesUnboxed(t *interpreter.RuntimeType, v Value) bool {
+	switch v.tag {
+	case tagNil:
+		return true
+	case tagInt:
+		return t.Name == "int" || t.Name == "float" || t.Name == "any"
+	case tagFloat:
+		return t.Name == "float" || t.Name == "any"
+	case tagBool:
+		return t.Name == "bool" || t.Name == "any"
+	}
+	return false
+}
+
+// lookup resolves class and enum names of type checks in frame's module
+func (vm *VM) lookup(frame *CallFrame) func(string) (interpreter.Value, bool) {
+	return func(name string) (interpreter.Value, bool) {
+		val, err := vm.getGlobal(frame.closure.module, name)
+		return val, err == nil
+	}
+}
+
+// checkArg checks parameter slot ins.Operand2 of the current call; the
+// elements of a variadic parameter are checked one by one
+func (vm *VM) checkArg(frame *CallFrame, ins Instruction) error {
+	fn := frame.closure.Fn
+	t, err := vm.runtimeType(fn, ins.Operand)
+	if err != nil {
+		return err
+	}
+	slot := vm.stack[frame.base+ins.Operand2]
+	if matchesUnboxed(t, slot) {
+		return nil
+	}
+	value := slot.box()
+	values := []interpreter.Value{value}
+	if list, ok := value.(*interpreter.List); ok && fn.Variadic && ins.Operand2 == fn.Arity {
+		values = list.Elements
+	}
+	lookup := vm.lookup(frame)
+	for _, v := range values {
+		if !t.Matches(v, lookup) {
+			return interpreter.ArgumentError(vm.functionName(frame), ins.Name, t, v, vm.callSite())
+		}
+	}
+	return nil
+}
+
+// checkType checks the value on top of the stack
+func (vm *VM) checkType(frame *CallFrame, ins Instruction) error {
+	t, err := vm.runtimeType(frame.closure.Fn, ins.Operand)
+	if err != nil {
+		return err
+	}
+	if matchesUnboxed(t, vm.stack[vm.sp-1]) {
+		return nil
+	}
+	value := vm.peek(0)
+	if t.Matches(value, vm.lookup(frame)) {
+		return nil
+	}
+	if ins.Operand2 == checkReturn {
+		return interpreter.ReturnError(ins.Name, t, value)
+	}
+	return interpreter.LetError(ins.Name, t, value)
+}
+
+// functionName names the function of frame in type errors; methods are
+// qualified with the class of self
+func (vm *VM) functionName(frame *CallFrame) string {
+	fn := frame.closure.Fn
+	if fn.Method {
+		if inst, ok := vm.stack[frame.base].box().(*interpreter.Instance); ok {
+			return inst.Class.Name + "." + fn.Name
+		}
+	}
+	return fn.Name
+}
+
+// callSite returns the file and line of the call that entered the current
+// frame, "" when it was not called from bytecode with line information
+func (vm *VM) callSite() string {
+	if len(vm.frames) < 2 {
+		return ""
+	}
+	return vm.siteOf(vm.frames[len(vm.frames)-2])
+}
+
+// withCallSite records the call the current frame is making in callEnv,
+// so interpreter functions report it in argument type errors
+func (vm *VM) withCallSite(callEnv *interpreter.Environment) {
+	if vm.typeChecks && len(vm.frames) > 0 {
+		interpreter.SetCallSite(callEnv, vm.siteOf(vm.frames[len(vm.frames)-1]))
+	}
+}
+
+// siteOf returns the file and line of the instruction caller is executing
+func (vm *VM) siteOf(caller *CallFrame) string {
+	line := lineAt(caller.closure.Fn, caller.ip-1)
+	if line == 0 {
+		return ""
+	}
+	file := vm.sourceFile
+	if mod := caller.closure.module; mod != vm.main && mod.env == nil {
+		file = mod.path + ".sky"
+	}
+	return interpreter.CallSite(file, line)
+}
+
+// lineAt returns the source line of the instruction at word offset at,
+// 0 when it is not known
+func lineAt(fn *CompiledFunction, at int) int {
+	offset := 0
+	for i, ins := range fn.Instructions {
+		if cacheable(ins.Op) {
+			// assemble always encodes the cache index
+			ins.Operand2 = 1
+		}
+		offset += encodedSize(ins)
+		if at < offset {
+			if i < len(fn.Lines) {
+				return fn.Lines[i]
+			}
+			return 0
+		}
+	}
+	return 0
+}
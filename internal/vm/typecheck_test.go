@@ -0,0 +1,164 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+)
+
+// engine runs source with runtime type checks and returns its output
+type engine struct {
+	name string
+	run  func(t *testing.T, source string) (string, error)
+}
+
+func checkedEngines() []engine {
+	engines := []engine{
+		{"interpreter", func(t *testing.T, source string) (string, error) {
+			return interpretChecked(t, source, false)
+		}},
+		{"tiered", func(t *testing.T, source string) (string, error) {
+			return interpretChecked(t, source, true)
+		}},
+	}
+	for level := 0; level <= MaxOptLevel; level++ {
+		level := level
+		engines = append(engines, engine{fmt.Sprintf("vm -O%d", level), func(t *testing.T, source string) (string, error) {
+			compiler := NewCompiler()
+			compiler.SetTypeChecks(true)
+			bc, err := compiler.Compile(parse(t, source, "test.sky"))
+			if err != nil {
+				t.Fatalf("compile failed: %v", err)
+			}
+			Optimize(bc, level)
+			return run(t, bc, "test.sky")
+		}})
+	}
+	return engines
+}
+
+// interpretChecked runs source in the interpreter with runtime type
+// checks; tiered promotes hot functions to a VM checking them too
+func interpretChecked(t *testing.T, source string, tiered bool) (string, error) {
+	t.Helper()
+	program := parse(t, source, "test.sky")
+	return capture(t, func() error {
+		interp := interpreter.New()
+		interp.SetSourceFile("test.sky")
+		interp.SetTypeChecks(true)
+		if tiered {
+			tier := NewTier(program)
+			tier.SetSourceFile("test.sky")
+			tier.SetTypeChecks(true)
+			interp.SetTier(tier, nil)
+		}
+		return interp.Eval(program)
+	})
+}
+
+func TestRuntimeTypeChecks(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string // output before the error
+		err    string // "" when the program runs to the end
+	}{
+		{"argument", `function take(x: int): int
+  return x
+end
+print(take(1))
+take("s")`, "1\n", "TypeError: argument x of take: expected int, got string (called at test.sky:5)"},
+		{"return", `function name(): string
+  return 1
+end
+name()`, "", "TypeError: return value of name: expected string, got int"},
+		{"let", `let n: int = 1
+print(n)
+let s: string = n`, "1\n", "TypeError: let s: expected string, got int"},
+		{"variadic", `function add(...xs: int): int
+  let total = 0
+  for x in xs
+    total += x
+  end
+  return total
+end
+print(add(1, 2))
+add(1, "2")`, "3\n", "TypeError: argument xs of add: expected int, got string (called at test.sky:9)"},
+		{"method", `class Box
+  function put(x: int): int
+    return x
+  end
+end
+let b = Box()
+print(b.put(2))
+b.put(true)`, "2\n", "TypeError: argument x of Box.put: expected int, got bool (called at test.sky:8)"},
+		{"matching values", `class Point
+  function init(x: int)
+    self.x = x
+  end
+end
+function scale(f: float, p: Point, xs: [int], m: {string: int}, o: Point?): float
+  return f * p.x
+end
+let none: string = nil
+print(scale(2, Point(3), [1, 2], {"a": 1}, nil))`, "6\n", ""},
+	}
+
+	for _, e := range checkedEngines() {
+		for _, tt := range tests {
+			got, err := e.run(t, tt.source)
+			if got != tt.want {
+				t.Errorf("%s %s: printed %q, want %q", e.name, tt.name, got, tt.want)
+			}
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("%s %s: %v", e.name, tt.name, err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("%s %s: error %v, want %q", e.name, tt.name, err, tt.err)
+			}
+		}
+	}
+}
+
+// TestRuntimeTypeChecksPromoted checks that calls made by promoted
+// functions keep their call site
+func TestRuntimeTypeChecksPromoted(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		err    string
+	}{
+		{"function", `function take(x: int): int
+  return x
+end
+function caller(i, v)
+  if i == 300
+    return take(v)
+  end
+  return take(i)
+end
+` + hotLoop(`caller(i, "s")`, 400), "argument x of take: expected int, got string (called at test.sky:6)"},
+		{"method", `class Box
+  function put(x: int): int
+    return x
+  end
+end
+let b = Box()
+function caller(i, v)
+  if i == 300
+    return b.put(v)
+  end
+  return b.put(i)
+end
+` + hotLoop(`caller(i, "s")`, 400), "argument x of Box.put: expected int, got string (called at test.sky:9)"},
+	}
+
+	for _, tt := range tests {
+		_, err := interpretChecked(t, tt.source, true)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: error %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
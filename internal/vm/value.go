@@ -0,0 +1,217 @@
+package vm
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+)
+
+// Value is a stack slot. Integers, floats, booleans and nil are stored
+// unboxed so arithmetic and comparisons do not allocate; everything else
+// (strings, lists, dicts, classes, instances, closures, promises) is a
+// heap object using the interpreter's value types so built-ins can be
+// shared. The zero Value is nil.
+type Value struct {
+	tag  valueTag
+	bits uint64            // int64, float64 bits or bool
+	obj  interpreter.Value // heap object when tag is tagObject
+}
+
+type valueTag uint8
+
+const (
+	tagNil valueTag = iota
+	tagBool
+	tagInt
+	tagFloat
+	tagObject
+)
+
+func intValue(n int64) Value     { return Value{tag: tagInt, bits: uint64(n)} }
+func floatValue(f float64) Value { return Value{tag: tagFloat, bits: math.Float64bits(f)} }
+
+func boolValue(b bool) Value {
+	if b {
+		return Value{tag: tagBool, bits: 1}
+	}
+	return Value{tag: tagBool}
+}
+
+func (v Value) int() int64     { return int64(v.bits) }
+func (v Value) float() float64 { return math.Float64frombits(v.bits) }
+
+// unbox converts an interpreter value to a stack value
+func unbox(val interpreter.Value) Value {
+	switch v := val.(type) {
+	case nil, *interpreter.Nil:
+		return Value{}
+	case *interpreter.Integer:
+		return intValue(v.Value)
+	case *interpreter.Float:
+		return floatValue(v.Value)
+	case *interpreter.Boolean:
+		return boolValue(v.Value)
+	}
+	return Value{tag: tagObject, obj: val}
+}
+
+// box converts a stack value to an interpreter value, allocating for
+// unboxed numbers and booleans
+func (v Value) box() interpreter.Value {
+	switch v.tag {
+	case tagBool:
+		return &interpreter.Boolean{Value: v.bits != 0}
+	case tagInt:
+		return &interpreter.Integer{Value: v.int()}
+	case tagFloat:
+		return &interpreter.Float{Value: v.float()}
+	case tagObject:
+		return v.obj
+	}
+	return &interpreter.Nil{}
+}
+
+// truthy follows the interpreter's IsTruthy rules
+func (v Value) truthy() bool {
+	switch v.tag {
+	case tagBool, tagInt:
+		return v.bits != 0
+	case tagFloat:
+		return v.float() != 0
+	case tagObject:
+		return v.obj.IsTruthy()
+	}
+	return false
+}
+
+// binaryOp applies an arithmetic or comparison operator. Integer and float
+// operands are handled in place; other operands go through the
+// interpreter so both engines agree on coercions and errors.
+func binaryOp(op OpCode, a, b Value) (Value, error) {
+	if a.tag == tagInt && b.tag == tagInt {
+		x, y := a.int(), b.int()
+		switch op {
+		case OpAdd:
+			return intValue(x + y), nil
+		case OpSub:
+			return intValue(x - y), nil
+		case OpMul:
+			return intValue(x * y), nil
+		case OpDiv, OpMod:
+			if y == 0 {
+				return Value{}, &interpreter.RuntimeError{Code: diag.DivisionByZero, Message: i18n.T("runtime.division_by_zero")}
+			}
+			if op == OpDiv {
+				return intValue(x / y), nil
+			}
+			return intValue(x % y), nil
+		case OpEqual:
+			return boolValue(x == y), nil
+		case OpNotEqual:
+			return boolValue(x != y), nil
+		case OpLessThan:
+			return boolValue(x < y), nil
+		case OpLessEqual:
+			return boolValue(x <= y), nil
+		case OpGreaterThan:
+			return boolValue(x > y), nil
+		case OpGreaterEqual:
+			return boolValue(x >= y), nil
+		}
+	}
+	if a.tag == tagFloat && b.tag == tagFloat {
+		x, y := a.float(), b.float()
+		switch op {
+		case OpAdd:
+			return floatValue(x + y), nil
+		case OpSub:
+			return floatValue(x - y), nil
+		case OpMul:
+			return floatValue(x * y), nil
+		case OpDiv:
+			return floatValue(x / y), nil
+		}
+	}
+
+	result, err := interpreter.BinaryOp(a.box(), b.box(), binaryOperators[op])
+	if err != nil {
+		return Value{}, err
+	}
+	return unbox(result), nil
+}
+
+// VM-specific heap objects
+
+// Closure is a compiled function together with its captured variables
+type Closure struct {
+	Fn       *CompiledFunction
+	Upvalues []*Upvalue
+	Class    *interpreter.Class // class the method was defined in (for super)
+
+	module  *module
+	wrapper *interpreter.Function // cached wrapper for built-ins
+}
+
+func (c *Closure) Kind() interpreter.ValueKind { return interpreter.FunctionValue }
+func (c *Closure) String() string {
+	if c.Fn.Async {
+		return fmt.Sprintf("<async function %s>", c.Fn.Name)
+	}
+	return fmt.Sprintf("<function %s>", c.Fn.Name)
+}
+func (c *Closure) IsTruthy() bool { return true }
+
+// Upvalue is a captured variable. While the owning frame is alive it
+// points at a stack slot, on the VM stack or in a suspended coroutine;
+// once the frame returns the value is closed over.
+type Upvalue struct {
+	stack  *[]Value
+	index  int
+	open   bool
+	closed Value
+}
+
+func (u *Upvalue) get() Value {
+	if u.open {
+		return (*u.stack)[u.index]
+	}
+	return u.closed
+}
+
+func (u *Upvalue) set(v Value) {
+	if u.open {
+		(*u.stack)[u.index] = v
+		return
+	}
+	u.closed = v
+}
+
+// BoundMethod is a method closure bound to its receiver
+type BoundMethod struct {
+	Receiver interpreter.Value
+	Method   *Closure
+}
+
+func (b *BoundMethod) Kind() interpreter.ValueKind { return interpreter.FunctionValue }
+func (b *BoundMethod) String() string              { return b.Method.String() }
+func (b *BoundMethod) IsTruthy() bool              { return true }
+
+// iterator drives for-in loops over lists, dicts, strings and
+// instances implementing __iter__/__next__
+type iterator struct {
+	next func() (interpreter.Value, bool, error)
+}
+
+func (it *iterator) Kind() interpreter.ValueKind { return interpreter.GeneratorValue }
+func (it *iterator) String() string              { return "<iterator>" }
+func (it *iterator) IsTruthy() bool              { return true }
+
+// module holds the globals of the main program or an imported module
+type module struct {
+	path    string
+	globals map[string]*global
+	env     *interpreter.Environment // variables of interpreted code (see Tier), nil otherwise
+}
@@ -2,570 +2,1128 @@ package vm
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strings"
 
+	"github.com/mburakmmm/sky-lang/internal/diag"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
 	"github.com/mburakmmm/sky-lang/internal/interpreter"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+	"github.com/mburakmmm/sky-lang/internal/parser"
 )
 
+// maxFrames bounds the call depth; the VM keeps frames on its own stack so
+// recursion is limited by memory, not the Go stack
+const maxFrames = 1 << 16
+
 // VM is a stack-based virtual machine
 type VM struct {
 	bytecode *Bytecode
-	stack    []interface{}
+	stack    []Value
 	sp       int // stack pointer
-	globals  map[string]interface{}
-	ip       int // instruction pointer
 	frames   []*CallFrame
-	fp       int // frame pointer
+	handlers []handler
+
+	builtins     map[string]interpreter.Value
+	main         *module
+	modules      map[string]*module
+	methods      map[*interpreter.Function]*Closure // wrappers of compiled functions
+	abstracts    map[*interpreter.AbstractClass]*interpreter.Class
+	openUpvalues []*Upvalue
+	cache        *Cache // compiled modules, nil to always compile
+	globalEpoch  uint64 // bumped when a global name is first defined
+	classEpoch   uint64 // bumped when a class gains a method
+	optLevel     int    // optimization level for imported modules
+
+	// Coroutines (see coroutine.go)
+	ready    []*coroutine                          // async calls ready to resume
+	waiters  map[*interpreter.Promise][]*coroutine // pending promises of the VM
+	settled  chan settlement                       // results of native async calls
+	inflight int                                   // native async calls not settled yet
+
+	profile *profiler // records a profile for ApplyProfile, nil when off
+
+	typeChecks bool // compile modules with runtime type checks
+
+	sourceFile string
+	currentDir string
 }
 
 // CallFrame represents a function call frame
 type CallFrame struct {
-	function    string
-	returnAddr  int
-	basePointer int
-	localCount  int
+	closure *Closure
+	ip      int
+	base    int // stack slot of the callee (self for methods); locals follow
+	argc    int // arguments passed by the caller, for default parameters
+
+	co *coroutine // coroutine owning the frame, if any
+}
+
+// handler is an active try block
+type handler struct {
+	frame   int // frame index that owns the handler
+	sp      int // stack height to restore
+	catchIP int
 }
 
 // NewVM creates a new virtual machine
 func NewVM(bytecode *Bytecode) *VM {
-	vm := &VM{
-		bytecode: bytecode,
-		stack:    make([]interface{}, 0, 2048),
-		sp:       0,
-		globals:  make(map[string]interface{}),
-		ip:       0,
-		frames:   make([]*CallFrame, 0, 1024),
-		fp:       0,
+	currentDir, _ := os.Getwd()
+	return &VM{
+		bytecode:   bytecode,
+		stack:      make([]Value, 2048),
+		frames:     make([]*CallFrame, 0, 64),
+		builtins:   interpreter.Builtins(),
+		main:       &module{path: "<main>", globals: make(map[string]*global)},
+		modules:    make(map[string]*module),
+		methods:    make(map[*interpreter.Function]*Closure),
+		abstracts:  make(map[*interpreter.AbstractClass]*interpreter.Class),
+		waiters:    make(map[*interpreter.Promise][]*coroutine),
+		optLevel:   DefaultOptLevel,
+		currentDir: currentDir,
 	}
+}
 
-	// Load compiled functions into global namespace
-	for name, fn := range bytecode.Functions {
-		vm.globals[name] = fn
-	}
+// SetSourceFile sets the script path used to resolve imports
+func (vm *VM) SetSourceFile(path string) {
+	vm.sourceFile = path
+}
 
-	return vm
+// SetOptLevel sets the optimization level used to compile imported modules
+func (vm *VM) SetOptLevel(level int) {
+	vm.optLevel = level
+}
+
+// SetCache sets the cache used for compiled modules; nil disables it
+func (vm *VM) SetCache(cache *Cache) {
+	vm.cache = cache
 }
 
 // Run executes the bytecode
 func (vm *VM) Run() error {
-	for vm.ip < len(vm.bytecode.Instructions) {
-		ins := vm.bytecode.Instructions[vm.ip]
-		vm.ip++
+	if _, err := vm.runScript(vm.bytecode, vm.main); err != nil {
+		return err
+	}
+	// Finish async calls that were started but never awaited
+	return vm.runUntil(nil)
+}
+
+// runScript runs top-level code of the main program or a module
+func (vm *VM) runScript(bytecode *Bytecode, mod *module) (interpreter.Value, error) {
+	script := &Closure{
+		Fn: &CompiledFunction{
+			Name:         "<script>",
+			Instructions: bytecode.Instructions,
+			Lines:        bytecode.Lines,
+			Constants:    bytecode.Constants,
+			LocalCount:   bytecode.LocalCount,
+		},
+		module: mod,
+	}
+	if vm.profile != nil && bytecode == vm.bytecode {
+		vm.profile.names[script.Fn] = scriptKey
+	}
+	return vm.call(script, nil)
+}
 
+// call calls a value from Go code and runs it to completion
+func (vm *VM) call(callee interpreter.Value, args []interpreter.Value) (interpreter.Value, error) {
+	return vm.callWith(callee, callee, args)
+}
+
+// callWith calls callee with slot0 (self for methods) in the callee slot
+func (vm *VM) callWith(callee, slot0 interpreter.Value, args []interpreter.Value) (interpreter.Value, error) {
+	base := len(vm.frames)
+	vm.push(slot0)
+	for _, arg := range args {
+		vm.push(arg)
+	}
+
+	var err error
+	if closure, ok := callee.(*Closure); ok {
+		err = vm.pushFrame(closure, len(args))
+	} else {
+		err = vm.callValue(callee, len(args), "")
+	}
+	if err != nil {
+		vm.sp -= len(args) + 1
+		return nil, err
+	}
+	if len(vm.frames) == base {
+		return vm.pop(), nil
+	}
+	return vm.run(base)
+}
+
+// run executes instructions until the frame at index base returns
+func (vm *VM) run(base int) (interpreter.Value, error) {
+	for {
+		frame := vm.frames[len(vm.frames)-1]
+		fn := frame.closure.Fn
+
+		// Decode the next instruction (see CompiledFunction.assemble)
+		code := fn.code
+		at := frame.ip
+		word := code[at]
+		frame.ip++
+		ins := Instruction{Op: OpCode(word)}
+		if word&wordInline != 0 {
+			ins.Operand = int(word >> inlineShift)
+		} else if word&wordOperand != 0 {
+			ins.Operand = int(int32(code[frame.ip]))
+			frame.ip++
+		}
+		if word&wordOperand2 != 0 {
+			ins.Operand2 = int(int32(code[frame.ip]))
+			frame.ip++
+		}
+		if word&wordName != 0 {
+			ins.Name = fn.names[code[frame.ip]]
+			frame.ip++
+		}
+		if vm.profile != nil {
+			vm.profile.observe(vm, fn, at, ins.Op)
+		}
+
+		var err error
 		switch ins.Op {
 		case OpConstant:
-			constant := vm.bytecode.Constants[ins.Operand]
-			vm.push(constant)
+			vm.pushValue(fn.constant(ins.Operand))
 
 		case OpPop:
-			vm.pop()
+			vm.sp--
 
 		case OpDup:
-			val := vm.peek(0)
-			vm.push(val)
+			vm.pushValue(vm.stack[vm.sp-1])
 
 		case OpTrue:
-			vm.push(true)
+			vm.pushValue(boolValue(true))
 
 		case OpFalse:
-			vm.push(false)
+			vm.pushValue(boolValue(false))
 
 		case OpNil:
-			vm.push(nil)
-
-		case OpAdd:
-			if err := vm.binaryOp("+"); err != nil {
-				return err
-			}
+			vm.pushValue(Value{})
 
-		case OpSub:
-			if err := vm.binaryOp("-"); err != nil {
-				return err
-			}
+		case OpGetLocal:
+			vm.pushValue(vm.stack[frame.base+ins.Operand])
 
-		case OpMul:
-			if err := vm.binaryOp("*"); err != nil {
-				return err
+		case OpSetLocal:
+			vm.stack[frame.base+ins.Operand] = vm.popValue()
+
+		case OpGetLocal2:
+			vm.pushValue(vm.stack[frame.base+ins.Operand])
+			vm.pushValue(vm.stack[frame.base+ins.Operand2])
+
+		case OpIncLocal:
+			slot := frame.base + ins.Operand
+			var result Value
+			result, err = binaryOp(OpAdd, vm.stack[slot], fn.constant(ins.Operand2))
+			if err == nil {
+				vm.stack[slot] = result
 			}
 
-		case OpDiv:
-			if err := vm.binaryOp("/"); err != nil {
-				return err
+		case OpGetGlobal:
+			var val Value
+			val, err = vm.loadGlobal(&fn.caches[ins.Operand2], frame.closure.module, ins.Name)
+			if err == nil {
+				vm.pushValue(val)
 			}
 
-		case OpMod:
-			if err := vm.binaryOp("%"); err != nil {
-				return err
+		case OpSetGlobal:
+			vm.storeGlobal(&fn.caches[ins.Operand2], frame.closure.module, ins.Name, vm.popValue())
+
+		case OpGetUpvalue:
+			vm.pushValue(frame.closure.Upvalues[ins.Operand].get())
+
+		case OpSetUpvalue:
+			frame.closure.Upvalues[ins.Operand].set(vm.popValue())
+
+		case OpAdd, OpSub, OpMul, OpDiv, OpMod,
+			OpEqual, OpNotEqual, OpGreaterThan, OpGreaterEqual, OpLessThan, OpLessEqual:
+			b := vm.popValue()
+			a := vm.popValue()
+			var result Value
+			result, err = binaryOp(ins.Op, a, b)
+			if err == nil {
+				vm.pushValue(result)
 			}
 
 		case OpNegate:
-			val := vm.pop()
-			switch v := val.(type) {
-			case int64:
-				vm.push(-v)
-			case float64:
-				vm.push(-v)
+			switch v := vm.popValue(); v.tag {
+			case tagInt:
+				vm.pushValue(intValue(-v.int()))
+			case tagFloat:
+				vm.pushValue(floatValue(-v.float()))
 			default:
-				return fmt.Errorf("cannot negate %T", val)
+				err = &interpreter.RuntimeError{Message: i18n.T("runtime.negate")}
 			}
 
 		case OpNot:
-			val := vm.pop()
-			vm.push(!vm.isTruthy(val))
+			vm.pushValue(boolValue(!vm.popValue().truthy()))
 
-		case OpEqual:
-			b := vm.pop()
-			a := vm.pop()
-			vm.push(vm.isEqual(a, b))
+		case OpJump, OpLoop:
+			frame.ip = ins.Operand
 
-		case OpNotEqual:
-			b := vm.pop()
-			a := vm.pop()
-			vm.push(!vm.isEqual(a, b))
-
-		case OpGreaterThan:
-			if err := vm.comparison(">"); err != nil {
-				return err
+		case OpJumpIfFalse:
+			if !vm.popValue().truthy() {
+				frame.ip = ins.Operand
 			}
 
-		case OpGreaterEqual:
-			if err := vm.comparison(">="); err != nil {
-				return err
+		case OpJumpIfTrue:
+			if vm.popValue().truthy() {
+				frame.ip = ins.Operand
 			}
 
-		case OpLessThan:
-			if err := vm.comparison("<"); err != nil {
-				return err
+		case OpIntOp:
+			a, b := vm.stack[vm.sp-2], vm.stack[vm.sp-1]
+			if a.tag == tagInt && b.tag == tagInt {
+				if result, ok := intOp(OpCode(ins.Operand2), a.int(), b.int()); ok {
+					vm.sp--
+					vm.stack[vm.sp-1] = result
+					break
+				}
+			}
+			vm.sp -= 2
+			var result Value
+			result, err = binaryOp(OpCode(ins.Operand2), a, b)
+			if err == nil {
+				vm.pushValue(result)
 			}
 
-		case OpLessEqual:
-			if err := vm.comparison("<="); err != nil {
-				return err
+		case OpFloatOp:
+			a, b := vm.stack[vm.sp-2], vm.stack[vm.sp-1]
+			if a.tag == tagFloat && b.tag == tagFloat {
+				if result, ok := floatOp(OpCode(ins.Operand2), a.float(), b.float()); ok {
+					vm.sp--
+					vm.stack[vm.sp-1] = result
+					break
+				}
+			}
+			vm.sp -= 2
+			var result Value
+			result, err = binaryOp(OpCode(ins.Operand2), a, b)
+			if err == nil {
+				vm.pushValue(result)
 			}
 
-		case OpGetLocal:
-			// Get local variable from current frame
-			basePtr := vm.frameBase()
-			slot := basePtr + ins.Operand
+		case OpInlineGuard:
+			proto := fn.Constants[ins.Operand2].(*CompiledFunction)
+			callee := vm.sp - proto.Arity - 1
+			if closure, ok := vm.stack[callee].obj.(*Closure); !ok || closure.Fn != proto {
+				frame.ip = ins.Operand
+				break
+			}
+			// The arguments become the parameters of the inlined body
+			slot := frame.base + int(fn.constant(ins.Operand2+1).int())
+			copy(vm.stack[slot+1:], vm.stack[callee+1:vm.sp])
+			vm.sp = callee
+
+		case OpCompareJump:
+			b := vm.popValue()
+			a := vm.popValue()
+			var result Value
+			result, err = binaryOp(OpCode(ins.Operand2), a, b)
+			if err == nil && !result.truthy() {
+				frame.ip = ins.Operand
+			}
 
-			if slot >= len(vm.stack) {
-				return fmt.Errorf("stack underflow: trying to access slot %d (sp=%d)", slot, vm.sp)
+		case OpJumpIfArg:
+			if ins.Operand2 < frame.argc {
+				frame.ip = ins.Operand
 			}
 
-			val := vm.stack[slot]
-			vm.push(val)
+		case OpCall, OpCallAsync:
+			err = vm.callValue(vm.peek(ins.Operand), ins.Operand, ins.Name)
 
-		case OpSetLocal:
-			val := vm.peek(0)
-			basePtr := vm.frameBase()
-			slot := basePtr + ins.Operand
+		case OpInvoke:
+			err = vm.invoke(&fn.caches[ins.Operand2], ins.Name, ins.Operand)
 
-			// Extend stack if needed
-			for len(vm.stack) <= slot {
-				vm.stack = append(vm.stack, nil)
+		case OpSuperInvoke:
+			err = vm.superInvoke(frame, ins.Name, ins.Operand)
+
+		case OpReturn, OpHalt:
+			var result Value
+			if ins.Op == OpReturn {
+				result = vm.popValue()
+			}
+			vm.returnFrame(result)
+			if len(vm.frames) == base {
+				return vm.pop(), nil
 			}
-			vm.stack[slot] = val
 
-		case OpGetGlobal:
-			val, ok := vm.globals[ins.Name]
-			if !ok {
-				return fmt.Errorf("undefined variable: %s", ins.Name)
+		case OpAwait:
+			if promise, ok := vm.stack[vm.sp-1].obj.(*interpreter.Promise); ok {
+				vm.sp--
+				var val Value
+				var suspended bool
+				val, suspended, err = vm.await(frame, promise)
+				if suspended {
+					if len(vm.frames) == base {
+						return vm.pop(), nil
+					}
+				} else if err == nil {
+					vm.pushValue(val)
+				}
 			}
-			vm.push(val)
 
-		case OpSetGlobal:
-			val := vm.peek(0)
-			vm.globals[ins.Name] = val
+		case OpYield:
+			// Coop functions suspend; elsewhere yield passes its value through
+			if frame.co != nil && fn.Coop {
+				vm.suspend(frame, vm.popValue())
+				if len(vm.frames) == base {
+					return vm.pop(), nil
+				}
+			}
 
-		case OpJump:
-			vm.ip = ins.Operand
+		case OpClosure:
+			vm.push(vm.newClosure(frame, fn.Constants[ins.Operand].(*CompiledFunction)))
 
-		case OpJumpIfFalse:
-			condition := vm.peek(0)
-			if !vm.isTruthy(condition) {
-				vm.ip = ins.Operand
-			}
-			vm.pop()
+		case OpList:
+			elements := vm.boxed(vm.sp - ins.Operand)
+			vm.sp -= ins.Operand
+			vm.push(&interpreter.List{Elements: elements})
 
-		case OpJumpIfTrue:
-			condition := vm.peek(0)
-			if vm.isTruthy(condition) {
-				vm.ip = ins.Operand
+		case OpDict:
+			pairs := make(map[string]interpreter.Value, ins.Operand)
+			start := vm.sp - 2*ins.Operand
+			for i := start; i < vm.sp; i += 2 {
+				pairs[vm.stack[i].box().String()] = vm.stack[i+1].box()
+			}
+			vm.sp = start
+			vm.push(&interpreter.Dict{Pairs: pairs})
+
+		case OpIndex:
+			index := vm.pop()
+			var val interpreter.Value
+			val, err = indexValue(vm.pop(), index)
+			if err == nil {
+				vm.push(val)
 			}
-			vm.pop()
-
-		case OpLoop:
-			vm.ip = ins.Operand
 
-		case OpCall:
-			argCount := ins.Operand
+		case OpSetIndex:
+			val := vm.pop()
+			index := vm.pop()
+			err = setIndex(vm.pop(), index, val)
+			if err == nil {
+				vm.push(val)
+			}
 
-			// Arguments are on top of stack, function name is below them
-			// Stack layout: [funcName, arg1, arg2, ...]
+		case OpGetMember:
+			var val interpreter.Value
+			val, err = vm.getMember(&fn.caches[ins.Operand2], vm.pop(), ins.Name)
+			if err == nil {
+				vm.push(val)
+			}
 
-			// Pop arguments
-			args := make([]interface{}, argCount)
-			for i := argCount - 1; i >= 0; i-- {
-				args[i] = vm.pop()
+		case OpSetMember:
+			val := vm.pop()
+			inst, ok := vm.pop().(*interpreter.Instance)
+			if !ok {
+				err = &interpreter.RuntimeError{Message: i18n.T("runtime.assign_member")}
+				break
 			}
+			inst.Set(ins.Name, val)
+			vm.push(val)
 
-			// Pop function name/reference
-			funcVal := vm.pop()
+		case OpGetSuper:
+			vm.push(superOf(frame.closure.Class))
 
-			// Handle different function value types
-			var compiledFunc *CompiledFunction
-			var funcName string
+		case OpClass:
+			err = vm.defineClass(ins)
 
-			switch f := funcVal.(type) {
-			case string:
-				// Function name as string
-				funcName = f
-				var ok bool
-				compiledFunc, ok = vm.bytecode.Functions[funcName]
-				if !ok {
-					return fmt.Errorf("undefined function: %s", funcName)
-				}
-			case *CompiledFunction:
-				// Direct function reference
-				compiledFunc = f
-				funcName = f.Name
-			default:
-				return fmt.Errorf("expected function, got %T", funcVal)
+		case OpMethod:
+			method := vm.pop().(*Closure)
+			vm.addMethod(vm.peek(0), ins.Name, method)
+
+		case OpImplements:
+			iface, ok := vm.pop().(*interpreter.Interface)
+			if !ok {
+				err = &interpreter.RuntimeError{Message: i18n.T("runtime.not_interface", ins.Name)}
+				break
 			}
+			class := vm.peek(0).(*interpreter.Class)
+			if missing := iface.MissingMethods(class); len(missing) > 0 {
+				err = &interpreter.RuntimeError{Message: i18n.T("runtime.not_implemented",
+					class.Name, iface.Name, strings.Join(missing, ", "))}
+				break
+			}
+			class.Interfaces = append(class.Interfaces, iface)
+
+		case OpInterface:
+			err = vm.defineInterface(ins)
 
-			// Check arity
-			if argCount != compiledFunc.Arity {
-				return fmt.Errorf("function %s expects %d arguments, got %d", funcName, compiledFunc.Arity, argCount)
+		case OpVariant:
+			info := &interpreter.VariantInfo{
+				Enum:         fn.Constants[ins.Operand2].(string),
+				Name:         ins.Name,
+				PayloadCount: ins.Operand,
 			}
+			vm.push(interpreter.NewVariantConstructor(info, interpreter.NewEnvironment(nil)))
 
-			// Push arguments back onto stack (they'll be locals in the function)
-			for _, arg := range args {
-				vm.push(arg)
+		case OpEnum:
+			enum := &interpreter.EnumType{Name: ins.Name, Variants: make(map[string]*interpreter.VariantInfo)}
+			for _, val := range vm.stack[vm.sp-ins.Operand : vm.sp] {
+				ctor := val.obj.(*interpreter.Function)
+				enum.Variants[ctor.Variant.Name] = ctor.Variant
+			}
+			vm.sp -= ins.Operand
+			vm.push(enum)
+
+		case OpIter:
+			var it *iterator
+			it, err = vm.iterate(vm.pop())
+			if err == nil {
+				vm.push(it)
 			}
 
-			// Create new call frame
-			frame := &CallFrame{
-				function:    funcName,
-				returnAddr:  vm.ip,
-				basePointer: vm.sp - argCount,
-				localCount:  compiledFunc.LocalCount,
+		case OpIterNext:
+			it := vm.peek(0).(*iterator)
+			val, ok, nextErr := it.next()
+			switch {
+			case nextErr != nil:
+				err = nextErr
+			case !ok:
+				frame.ip = ins.Operand
+			default:
+				vm.push(val)
 			}
 
-			// Push frame
-			vm.frames = append(vm.frames, frame)
-			vm.fp = len(vm.frames)
+		case OpTry:
+			vm.handlers = append(vm.handlers, handler{frame: len(vm.frames) - 1, sp: vm.sp, catchIP: ins.Operand})
 
-			// Save current state and switch to function
-			savedIP := vm.ip
-			savedBytecode := vm.bytecode
+		case OpEndTry:
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
 
-			// Execute function bytecode
-			funcVM := &VM{
-				bytecode: &Bytecode{
-					Instructions: compiledFunc.Instructions,
-					Constants:    compiledFunc.Constants,
-					Functions:    vm.bytecode.Functions,
-				},
-				stack:   vm.stack,
-				sp:      vm.sp,
-				globals: vm.globals,
-				ip:      0,
-				frames:  vm.frames,
-				fp:      vm.fp,
-			}
+		case OpThrow:
+			err = &interpreter.RuntimeError{Message: vm.pop().String()}
 
-			if err := funcVM.Run(); err != nil {
-				return err
+		case OpImport:
+			var ns interpreter.Value
+			ns, err = vm.importModule(ins.Name)
+			if err == nil {
+				vm.push(ns)
 			}
 
-			// Restore state
-			vm.stack = funcVM.stack
-			vm.sp = funcVM.sp
-			vm.ip = savedIP
-			vm.bytecode = savedBytecode
+		case OpTestEqual:
+			expected := vm.pop()
+			vm.push(&interpreter.Boolean{Value: patternEquals(expected, vm.pop(), ins.Operand2 == 1)})
+
+		case OpTestKind:
+			vm.push(&interpreter.Boolean{Value: int(vm.pop().Kind()) == ins.Operand})
+
+		case OpTestVariant:
+			enumVal, ok := vm.pop().(*interpreter.EnumInstance)
+			matched := ok && enumVal.Variant == ins.Name
+			if matched && ins.Operand >= 0 {
+				matched = len(enumVal.Payload) == ins.Operand
+			}
+			if matched && ins.Operand2 >= 0 {
+				matched = enumVal.TypeName == fn.Constants[ins.Operand2].(string)
+			}
+			vm.push(&interpreter.Boolean{Value: matched})
 
-			// Pop frame
-			if len(vm.frames) > 0 {
-				vm.frames = vm.frames[:len(vm.frames)-1]
-				vm.fp = len(vm.frames)
+		case OpTestList:
+			list, ok := vm.pop().(*interpreter.List)
+			matched := ok && len(list.Elements) == ins.Operand
+			if ok && ins.Operand2 == 1 {
+				matched = len(list.Elements) >= ins.Operand
 			}
+			vm.push(&interpreter.Boolean{Value: matched})
+
+		case OpTestKey:
+			key := vm.pop()
+			dict, ok := vm.pop().(*interpreter.Dict)
+			found := false
+			if ok {
+				_, found = dict.Pairs[key.String()]
+			}
+			vm.push(&interpreter.Boolean{Value: found})
 
-		case OpReturn:
-			returnValue := vm.pop()
+		case OpTestField:
+			inst, ok := vm.pop().(*interpreter.Instance)
+			found := false
+			if ok {
+				_, found = inst.Fields[ins.Name]
+			}
+			vm.push(&interpreter.Boolean{Value: found})
 
-			// If we're in a function, return from it
-			if len(vm.frames) > 0 {
-				// Pop frame but leave return value
-				vm.frames = vm.frames[:len(vm.frames)-1]
-				vm.fp = len(vm.frames)
+		case OpTestInstance:
+			class, ok := vm.pop().(*interpreter.Class)
+			if !ok {
+				vm.sp--
+				err = &interpreter.RuntimeError{Message: i18n.T("runtime.not_a_class", ins.Name)}
+				break
+			}
+			inst, ok := vm.pop().(*interpreter.Instance)
+			vm.push(&interpreter.Boolean{Value: ok && interpreter.IsSubclassOf(inst.Class, class)})
 
-				// Push return value
-				vm.push(returnValue)
+		case OpGetPayload:
+			vm.push(vm.pop().(*interpreter.EnumInstance).Payload[ins.Operand])
 
-				// Exit this VM run (we're done with this function)
-				return nil
+		case OpGetElement:
+			elements := vm.pop().(*interpreter.List).Elements
+			idx := ins.Operand
+			if idx < 0 {
+				idx += len(elements)
 			}
+			vm.push(elements[idx])
 
-			// Top-level return
-			vm.push(returnValue)
+		case OpSlice:
+			elements := vm.pop().(*interpreter.List).Elements
+			rest := make([]interpreter.Value, len(elements)-ins.Operand-ins.Operand2)
+			copy(rest, elements[ins.Operand:])
+			vm.push(&interpreter.List{Elements: rest})
 
 		case OpPrint:
-			argCount := ins.Operand
-			args := make([]interface{}, argCount)
-			for i := argCount - 1; i >= 0; i-- {
-				args[i] = vm.pop()
-			}
-			for _, arg := range args {
-				fmt.Println(vm.valueToString(arg))
+			args := vm.boxed(vm.sp - ins.Operand)
+			for i, arg := range args {
+				if i > 0 {
+					fmt.Print(" ")
+				}
+				fmt.Print(arg.String())
 			}
-			vm.push(nil)
+			fmt.Println()
+			vm.sp -= ins.Operand
+			vm.pushValue(Value{})
 
 		case OpLen:
-			val := vm.pop()
-			switch v := val.(type) {
-			case string:
-				vm.push(int64(len(v)))
-			case []interface{}:
-				vm.push(int64(len(v)))
-			default:
-				return fmt.Errorf("len() not supported for %T", val)
+			var n int
+			switch v := vm.pop().(type) {
+			case *interpreter.String:
+				n = len(v.Value)
+			case *interpreter.List:
+				n = len(v.Elements)
+			case *interpreter.Dict:
+				n = len(v.Pairs)
 			}
+			vm.pushValue(intValue(int64(n)))
 
-		case OpRange:
-			val := vm.pop()
-			n, ok := val.(int64)
-			if !ok {
-				return fmt.Errorf("range() expects integer, got %T", val)
+		case OpCheckArg:
+			err = vm.checkArg(frame, ins)
+
+		case OpCheckType:
+			err = vm.checkType(frame, ins)
+
+		default:
+			err = fmt.Errorf("unknown opcode: %s", ins.Op)
+		}
+
+		if err != nil {
+			if err = vm.recover(err, base); err != nil {
+				return nil, err
 			}
-			list := make([]interface{}, n)
-			for i := int64(0); i < n; i++ {
-				list[i] = i
+			if len(vm.frames) == base {
+				return vm.pop(), nil
 			}
-			vm.push(list)
+		}
+	}
+}
 
-		case OpAwait:
-			// For now, await just passes through the value
-			// In a full implementation, this would suspend execution
-			// and wait for promise resolution
-			value := vm.pop()
-			// If it's a promise-like structure, resolve it
-			// For simplicity, we just push the value back
-			vm.push(value)
+// binaryOperators maps opcodes to interpreter operators
+var binaryOperators = map[OpCode]string{
+	OpAdd: "+", OpSub: "-", OpMul: "*", OpDiv: "/", OpMod: "%",
+	OpEqual: "==", OpNotEqual: "!=",
+	OpGreaterThan: ">", OpGreaterEqual: ">=", OpLessThan: "<", OpLessEqual: "<=",
+}
 
-		case OpYield:
-			// For now, yield just returns the value
-			// In a full implementation, this would suspend the coroutine
-			// and yield control back to the caller
-			value := vm.pop()
-			vm.push(value)
+// recover unwinds to the innermost handler. Unhandled errors are returned.
+func (vm *VM) recover(err error, base int) error {
+	for len(vm.frames) > base {
+		top := len(vm.frames) - 1
+		if n := len(vm.handlers); n > 0 && vm.handlers[n-1].frame == top {
+			h := vm.handlers[n-1]
+			vm.handlers = vm.handlers[:n-1]
+			vm.sp = h.sp
+			vm.push(&interpreter.String{Value: err.Error()})
+			vm.frames[top].ip = h.catchIP
+			return nil
+		}
 
-		case OpBreak:
-			// Signal break to loop (will be caught by loop compilation)
-			return fmt.Errorf("__break__")
+		frame := vm.frames[top]
+		vm.closeUpvalues(frame.base)
+		vm.sp = frame.base
+		vm.frames = vm.frames[:top]
+	}
+	return err
+}
 
-		case OpContinue:
-			// Signal continue to loop (will be caught by loop compilation)
-			return fmt.Errorf("__continue__")
+// returnFrame pops the current frame and pushes its result for the caller
+func (vm *VM) returnFrame(result Value) {
+	top := len(vm.frames) - 1
+	frame := vm.frames[top]
+	vm.closeUpvalues(frame.base)
+	for len(vm.handlers) > 0 && vm.handlers[len(vm.handlers)-1].frame >= top {
+		vm.handlers = vm.handlers[:len(vm.handlers)-1]
+	}
+	vm.sp = frame.base
+	vm.frames = vm.frames[:top]
+	if frame.co != nil {
+		frame.co.state = coroutineDone
+	}
+	vm.pushValue(result)
+}
 
-		case OpHalt:
-			return nil
+// pushFrame enters closure; the callee and argc arguments are on the stack
+func (vm *VM) pushFrame(closure *Closure, argc int) error {
+	if len(vm.frames) >= maxFrames {
+		return &interpreter.RuntimeError{Code: diag.RecursionLimit, Message: i18n.T(
+			"runtime.call_depth", maxFrames, closure.Fn.Name)}
+	}
 
-		default:
-			return fmt.Errorf("unknown opcode: %s", ins.Op)
+	fn := closure.Fn
+	base := vm.sp - argc - 1
+	params := fn.Arity
+	if vm.profile != nil {
+		vm.profile.enter(fn)
+	}
+
+	// Missing arguments are nil, extra arguments are dropped
+	if fn.Variadic {
+		fixed := params - 1
+		for argc < fixed {
+			vm.pushValue(Value{})
+			argc++
 		}
+		rest := vm.boxed(base + 1 + fixed)
+		vm.sp = base + 1 + fixed
+		vm.push(&interpreter.List{Elements: rest})
+		argc = params
+	} else if argc > params {
+		vm.sp = base + 1 + params
+		argc = params
 	}
 
+	for vm.sp < base+fn.LocalCount {
+		vm.pushValue(Value{})
+	}
+	if fn.Coop || fn.Async {
+		return vm.startCoroutine(closure, base, argc)
+	}
+	vm.enterFrame(closure, base, argc)
 	return nil
 }
 
-// Stack operations
-func (vm *VM) push(val interface{}) {
-	if vm.sp >= len(vm.stack) {
-		vm.stack = append(vm.stack, val)
-	} else {
-		vm.stack[vm.sp] = val
+// enterFrame pushes the frame of closure whose slots start at base
+func (vm *VM) enterFrame(closure *Closure, base, argc int) *CallFrame {
+	if closure.Fn.code == nil {
+		closure.Fn.assemble()
 	}
-	vm.sp++
-}
 
-func (vm *VM) pop() interface{} {
-	if vm.sp == 0 {
-		return nil
+	// Frames popped earlier are reused to avoid an allocation per call
+	n := len(vm.frames)
+	if n < cap(vm.frames) && vm.frames[:n+1][n] != nil {
+		vm.frames = vm.frames[:n+1]
+		*vm.frames[n] = CallFrame{closure: closure, base: base, argc: argc}
+		return vm.frames[n]
 	}
-	vm.sp--
-	return vm.stack[vm.sp]
+	frame := &CallFrame{closure: closure, base: base, argc: argc}
+	vm.frames = append(vm.frames, frame)
+	return frame
 }
 
-func (vm *VM) peek(distance int) interface{} {
-	return vm.stack[vm.sp-1-distance]
+// callValue calls the value below argc arguments on the stack. Compiled
+// functions get a new frame; everything else completes immediately and
+// leaves its result in place of the callee.
+func (vm *VM) callValue(callee interpreter.Value, argc int, name string) error {
+	slot := vm.sp - argc - 1
+
+	switch fn := callee.(type) {
+	case *Closure:
+		return vm.pushFrame(fn, argc)
+
+	case *BoundMethod:
+		vm.stack[slot] = unbox(fn.Receiver)
+		return vm.pushFrame(fn.Method, argc)
+
+	case *interpreter.Function:
+		if closure, ok := vm.methods[fn]; ok {
+			if closure.Fn.Method {
+				vm.stack[slot] = unbox(vm.currentSelf())
+			}
+			return vm.pushFrame(closure, argc)
+		}
+		result, err := vm.callNative(fn, vm.args(slot+1))
+		return vm.complete(slot, result, err)
+
+	case *interpreter.Class:
+		result, err := vm.instantiate(fn, vm.args(slot+1))
+		return vm.complete(slot, result, err)
+	}
+
+	if name == "" {
+		name = callee.String()
+	}
+	return &interpreter.RuntimeError{Code: diag.NotCallable, Message: i18n.T("runtime.not_callable", name)}
 }
 
-func (vm *VM) frameBase() int {
-	if len(vm.frames) == 0 {
-		return 0
+// complete replaces the callee and its arguments with the call result
+func (vm *VM) complete(slot int, result interpreter.Value, err error) error {
+	if err != nil {
+		return err
 	}
-	frame := vm.frames[len(vm.frames)-1]
-	// Base pointer points to where arguments start
-	return frame.basePointer
+	vm.sp = slot
+	vm.push(result)
+	return nil
+}
+
+// args copies the arguments from slot to the top of the stack
+func (vm *VM) args(slot int) []interpreter.Value {
+	return vm.boxed(slot)
 }
 
-// Helper methods
-func (vm *VM) binaryOp(op string) error {
-	b := vm.pop()
-	a := vm.pop()
+// boxed returns the stack values from slot to the top as interpreter values
+func (vm *VM) boxed(slot int) []interpreter.Value {
+	values := make([]interpreter.Value, vm.sp-slot)
+	for i, v := range vm.stack[slot:vm.sp] {
+		values[i] = v.box()
+	}
+	return values
+}
 
-	// String concatenation with type coercion
-	if op == "+" {
-		aStr, aIsStr := a.(string)
-		bStr, bIsStr := b.(string)
+// callNative calls a built-in or interpreter function
+func (vm *VM) callNative(fn *interpreter.Function, args []interpreter.Value) (interpreter.Value, error) {
+	for i, arg := range args {
+		args[i] = vm.export(arg)
+	}
+	callEnv := interpreter.NewEnvironment(fn.Env)
+	callEnv.Set("__args__", &interpreter.List{Elements: args})
+	vm.withCallSite(callEnv)
+	if fn.Async {
+		return vm.spawn(func() (interpreter.Value, error) {
+			return fn.Body(callEnv)
+		}), nil
+	}
+	return fn.Body(callEnv)
+}
 
-		// String + anything
-		if aIsStr || bIsStr {
-			if !aIsStr {
-				aStr = vm.valueToString(a)
-			}
-			if !bIsStr {
-				bStr = vm.valueToString(b)
-			}
-			vm.push(aStr + bStr)
-			return nil
+// export wraps compiled functions so built-ins can call them
+func (vm *VM) export(val interpreter.Value) interpreter.Value {
+	switch v := val.(type) {
+	case *Closure:
+		return vm.wrap(v)
+	case *BoundMethod:
+		return &interpreter.Function{
+			Name:       v.Method.Fn.Name,
+			Parameters: v.Method.Fn.Params,
+			Body: func(callEnv *interpreter.Environment) (interpreter.Value, error) {
+				return vm.callWith(v.Method, v.Receiver, envArgs(callEnv))
+			},
 		}
 	}
+	return val
+}
 
-	// Integer arithmetic
-	if aInt, ok := a.(int64); ok {
-		if bInt, ok := b.(int64); ok {
-			switch op {
-			case "+":
-				vm.push(aInt + bInt)
-			case "-":
-				vm.push(aInt - bInt)
-			case "*":
-				vm.push(aInt * bInt)
-			case "/":
-				if bInt == 0 {
-					return fmt.Errorf("division by zero")
+// wrap returns the interpreter function wrapping closure
+func (vm *VM) wrap(closure *Closure) *interpreter.Function {
+	if closure.wrapper == nil {
+		closure.wrapper = &interpreter.Function{
+			Name:       closure.Fn.Name,
+			Parameters: closure.Fn.Params,
+			Env:        interpreter.NewEnvironment(nil),
+			Body: func(callEnv *interpreter.Environment) (interpreter.Value, error) {
+				if closure.Fn.Method {
+					self, _ := callEnv.Get("self")
+					if self == nil {
+						self = &interpreter.Nil{}
+					}
+					return vm.callWith(closure, self, envArgs(callEnv))
 				}
-				vm.push(aInt / bInt)
-			case "%":
-				vm.push(aInt % bInt)
-			default:
-				return fmt.Errorf("unknown operator: %s", op)
-			}
-			return nil
+				return vm.call(closure, envArgs(callEnv))
+			},
 		}
+		vm.methods[closure.wrapper] = closure
 	}
+	return closure.wrapper
+}
 
-	// Float arithmetic
-	if aFloat, ok := a.(float64); ok {
-		if bFloat, ok := b.(float64); ok {
-			switch op {
-			case "+":
-				vm.push(aFloat + bFloat)
-			case "-":
-				vm.push(aFloat - bFloat)
-			case "*":
-				vm.push(aFloat * bFloat)
-			case "/":
-				vm.push(aFloat / bFloat)
-			default:
-				return fmt.Errorf("unknown operator: %s", op)
-			}
-			return nil
-		}
+// envArgs returns the arguments of a native call
+func envArgs(callEnv *interpreter.Environment) []interpreter.Value {
+	args, _ := callEnv.Get("__args__")
+	if list, ok := args.(*interpreter.List); ok {
+		return list.Elements
 	}
-
-	return fmt.Errorf("unsupported operands for %s: %T and %T", op, a, b)
+	return nil
 }
 
-func (vm *VM) comparison(op string) error {
-	b := vm.pop()
-	a := vm.pop()
+// currentSelf returns self of the innermost method frame, or nil
+func (vm *VM) currentSelf() interpreter.Value {
+	frame := vm.frames[len(vm.frames)-1]
+	if frame.closure.Fn.Method {
+		return vm.stack[frame.base].box()
+	}
+	return &interpreter.Nil{}
+}
 
-	aInt, aOk := a.(int64)
-	bInt, bOk := b.(int64)
+// newClosure creates a closure, capturing variables of the current frame
+func (vm *VM) newClosure(frame *CallFrame, fn *CompiledFunction) *Closure {
+	closure := &Closure{
+		Fn:       fn,
+		Upvalues: make([]*Upvalue, len(fn.Upvalues)),
+		Class:    frame.closure.Class,
+		module:   frame.closure.module,
+	}
+	for i, ref := range fn.Upvalues {
+		if ref.Local {
+			closure.Upvalues[i] = vm.captureUpvalue(frame.base + ref.Index)
+		} else {
+			closure.Upvalues[i] = frame.closure.Upvalues[ref.Index]
+		}
+	}
+	return closure
+}
 
-	if aOk && bOk {
-		switch op {
-		case ">":
-			vm.push(aInt > bInt)
-		case ">=":
-			vm.push(aInt >= bInt)
-		case "<":
-			vm.push(aInt < bInt)
-		case "<=":
-			vm.push(aInt <= bInt)
-		default:
-			return fmt.Errorf("unknown comparison: %s", op)
+func (vm *VM) captureUpvalue(index int) *Upvalue {
+	for _, up := range vm.openUpvalues {
+		if up.index == index {
+			return up
 		}
-		return nil
 	}
+	up := &Upvalue{stack: &vm.stack, index: index, open: true}
+	vm.openUpvalues = append(vm.openUpvalues, up)
+	return up
+}
 
-	return fmt.Errorf("unsupported operands for %s", op)
+// closeUpvalues moves captured variables at or above slot off the stack
+func (vm *VM) closeUpvalues(slot int) {
+	open := vm.openUpvalues[:0]
+	for _, up := range vm.openUpvalues {
+		if up.index >= slot {
+			up.closed = (*up.stack)[up.index]
+			up.open = false
+		} else {
+			open = append(open, up)
+		}
+	}
+	vm.openUpvalues = open
 }
 
-func (vm *VM) isTruthy(val interface{}) bool {
-	if val == nil {
-		return false
+func (vm *VM) getGlobal(mod *module, name string) (interpreter.Value, error) {
+	if slot, ok := mod.globals[name]; ok {
+		return slot.value.box(), nil
 	}
-	if b, ok := val.(bool); ok {
-		return b
+	if mod.env != nil {
+		if val, ok := mod.env.Get(name); ok {
+			return val, nil
+		}
 	}
-	if i, ok := val.(int64); ok {
-		return i != 0
+	if val, ok := vm.builtins[name]; ok {
+		return val, nil
 	}
-	return true
+	return nil, &interpreter.RuntimeError{Code: diag.UndefinedName, Message: i18n.T("runtime.undefined", name)}
 }
 
-func (vm *VM) isEqual(a, b interface{}) bool {
-	if a == nil && b == nil {
-		return true
+// Stack operations. pushValue and popValue move stack values as they are;
+// push, pop and peek convert from and to interpreter values.
+func (vm *VM) pushValue(val Value) {
+	if vm.sp >= len(vm.stack) {
+		vm.stack = append(vm.stack, make([]Value, len(vm.stack))...)
 	}
-	if a == nil || b == nil {
-		return false
+	vm.stack[vm.sp] = val
+	vm.sp++
+}
+
+func (vm *VM) popValue() Value {
+	vm.sp--
+	return vm.stack[vm.sp]
+}
+
+func (vm *VM) push(val interpreter.Value) {
+	vm.pushValue(unbox(val))
+}
+
+func (vm *VM) pop() interpreter.Value {
+	return vm.popValue().box()
+}
+
+func (vm *VM) peek(distance int) interpreter.Value {
+	return vm.stack[vm.sp-1-distance].box()
+}
+
+// importModule loads a module once and returns its namespace
+func (vm *VM) importModule(path string) (interpreter.Value, error) {
+	mod, ok := vm.modules[path]
+	if !ok {
+		file := interpreter.ResolveModulePath(path, vm.sourceFile, vm.currentDir)
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, &interpreter.RuntimeError{Message: i18n.T("runtime.cannot_load_module", path, err)}
+		}
+
+		hash := HashSource(content)
+		cache := vm.cache
+		if vm.typeChecks {
+			cache = nil
+		}
+		bytecode, ok := cache.Load(hash, vm.optLevel, true)
+		if !ok {
+			p := parser.New(lexer.New(string(content), file))
+			program := p.ParseProgram()
+			if len(p.Errors()) > 0 {
+				return nil, &interpreter.RuntimeError{Message: i18n.T("runtime.module_parse_errors", path, p.Errors())}
+			}
+			compiler := NewCompiler()
+			compiler.SetTypeChecks(vm.typeChecks)
+			bytecode, err = compiler.CompileModule(program)
+			if err != nil {
+				return nil, &interpreter.RuntimeError{Message: i18n.T("runtime.module_error", path, err)}
+			}
+			Optimize(bytecode, vm.optLevel)
+			// A cache that cannot be written only costs a recompile next time
+			bytecode.SourceHash = hash
+			_ = cache.Store(bytecode, true)
+		}
+
+		mod = &module{path: path, globals: make(map[string]*global)}
+		if _, err := vm.runScript(bytecode, mod); err != nil {
+			return nil, &interpreter.RuntimeError{Message: i18n.T("runtime.module_error", path, err)}
+		}
+		vm.modules[path] = mod
 	}
 
-	switch aVal := a.(type) {
-	case int64:
-		if bVal, ok := b.(int64); ok {
-			return aVal == bVal
+	// Only public names (not starting with _) are exported
+	namespace := &interpreter.Dict{Pairs: make(map[string]interpreter.Value)}
+	for name, slot := range mod.globals {
+		if len(name) > 0 && name[0] != '_' {
+			namespace.Pairs[name] = slot.value.box()
 		}
-	case float64:
-		if bVal, ok := b.(float64); ok {
-			return aVal == bVal
+	}
+	return namespace, nil
+}
+
+// iterate returns an iterator for a for-in loop
+func (vm *VM) iterate(iterable interpreter.Value) (*iterator, error) {
+	idx := 0
+	switch v := iterable.(type) {
+	case *interpreter.List:
+		elements := append([]interpreter.Value(nil), v.Elements...)
+		return &iterator{next: func() (interpreter.Value, bool, error) {
+			if idx >= len(elements) {
+				return nil, false, nil
+			}
+			idx++
+			return elements[idx-1], true, nil
+		}}, nil
+
+	case *interpreter.Dict:
+		keys := make([]string, 0, len(v.Pairs))
+		for key := range v.Pairs {
+			keys = append(keys, key)
 		}
-	case string:
-		if bVal, ok := b.(string); ok {
-			return aVal == bVal
+		sort.Strings(keys)
+		return &iterator{next: func() (interpreter.Value, bool, error) {
+			if idx >= len(keys) {
+				return nil, false, nil
+			}
+			idx++
+			return &interpreter.String{Value: keys[idx-1]}, true, nil
+		}}, nil
+
+	case *interpreter.String:
+		runes := []rune(v.Value)
+		return &iterator{next: func() (interpreter.Value, bool, error) {
+			if idx >= len(runes) {
+				return nil, false, nil
+			}
+			idx++
+			return &interpreter.String{Value: string(runes[idx-1])}, true, nil
+		}}, nil
+
+	case *interpreter.Instance:
+		// Iterator protocol: __iter__ returns an instance whose __next__
+		// produces values until it raises an error
+		iterMethod, ok := findMethod(v.Class, "__iter__")
+		if !ok {
+			break
 		}
-	case bool:
-		if bVal, ok := b.(bool); ok {
-			return aVal == bVal
+		it, err := vm.callMethod(iterMethod, v, nil)
+		if err != nil {
+			return nil, err
 		}
+		inst, ok := it.(*interpreter.Instance)
+		return &iterator{next: func() (interpreter.Value, bool, error) {
+			if !ok {
+				return nil, false, nil
+			}
+			nextMethod, found := findMethod(inst.Class, "__next__")
+			if !found {
+				return nil, false, nil
+			}
+			val, err := vm.callMethod(nextMethod, inst, nil)
+			if err != nil {
+				return nil, false, nil
+			}
+			return val, true, nil
+		}}, nil
 	}
+	return nil, &interpreter.RuntimeError{Code: diag.NotIterable, Message: i18n.T("runtime.not_iterable", iterable)}
+}
 
-	return false
+// indexValue evaluates a[i]
+func indexValue(left, index interpreter.Value) (interpreter.Value, error) {
+	switch v := left.(type) {
+	case *interpreter.List:
+		if i, ok := index.(*interpreter.Integer); ok {
+			if i.Value < 0 || i.Value >= int64(len(v.Elements)) {
+				return nil, &interpreter.RuntimeError{Code: diag.IndexOutOfRange, Message: i18n.T("runtime.index_out_of_range")}
+			}
+			return v.Elements[i.Value], nil
+		}
+	case *interpreter.Dict:
+		if val, ok := v.Pairs[index.String()]; ok {
+			return val, nil
+		}
+		return &interpreter.Nil{}, nil
+	}
+	return nil, &interpreter.RuntimeError{Message: i18n.T("runtime.index_unsupported")}
 }
 
-func (vm *VM) valueToString(val interface{}) string {
-	if val == nil {
-		return "nil"
+// setIndex evaluates a[i] = v for lists and dicts
+func setIndex(left, index, val interpreter.Value) error {
+	switch v := left.(type) {
+	case *interpreter.List:
+		if i, ok := index.(*interpreter.Integer); ok {
+			if i.Value < 0 || i.Value >= int64(len(v.Elements)) {
+				return &interpreter.RuntimeError{Code: diag.IndexOutOfRange, Message: i18n.T("runtime.index_out_of_range")}
+			}
+			v.Elements[i.Value] = val
+			return nil
+		}
+	case *interpreter.Dict:
+		v.Pairs[index.String()] = val
+		return nil
 	}
-	switch v := val.(type) {
-	case int64:
-		return fmt.Sprintf("%d", v)
-	case float64:
-		return fmt.Sprintf("%g", v)
-	case string:
-		return v
-	case bool:
-		return fmt.Sprintf("%t", v)
+	return &interpreter.RuntimeError{Message: i18n.T("runtime.index_assign_unsupported")}
+}
+
+// patternEquals compares a literal pattern with a value. Numeric patterns
+// match integers and floats of equal value.
+func patternEquals(expected, value interpreter.Value, numeric bool) bool {
+	switch e := expected.(type) {
 	case *interpreter.Integer:
-		return fmt.Sprintf("%d", v.Value)
+		switch v := value.(type) {
+		case *interpreter.Integer:
+			return e.Value == v.Value
+		case *interpreter.Float:
+			return numeric && float64(e.Value) == v.Value
+		}
+	case *interpreter.Float:
+		switch v := value.(type) {
+		case *interpreter.Float:
+			return e.Value == v.Value
+		case *interpreter.Integer:
+			return numeric && e.Value == float64(v.Value)
+		}
 	case *interpreter.String:
-		return v.Value
+		v, ok := value.(*interpreter.String)
+		return ok && e.Value == v.Value
 	case *interpreter.Boolean:
-		return fmt.Sprintf("%t", v.Value)
-	default:
-		return fmt.Sprintf("%v", val)
+		v, ok := value.(*interpreter.Boolean)
+		return ok && e.Value == v.Value
+	case *interpreter.Nil:
+		_, ok := value.(*interpreter.Nil)
+		return ok
 	}
+	return false
 }
@@ -0,0 +1,256 @@
+package vm
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mburakmmm/sky-lang/internal/ast"
+	"github.com/mburakmmm/sky-lang/internal/i18n"
+	"github.com/mburakmmm/sky-lang/internal/interpreter"
+	"github.com/mburakmmm/sky-lang/internal/lexer"
+	"github.com/mburakmmm/sky-lang/internal/parser"
+)
+
+// TestConformance runs every shared backend test program on the VM at each
+// optimization level and checks that it prints what the interpreter prints
+func TestConformance(t *testing.T) {
+	for _, file := range programs(t) {
+		file := file
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".sky"), func(t *testing.T) {
+			want, wantErr := interpret(t, file)
+			for level := 0; level <= MaxOptLevel; level++ {
+				got, err := run(t, compileFile(t, file, level), file)
+				if got != want {
+					t.Errorf("-O%d: output differs\n--- interpreter\n%s--- vm\n%s", level, want, got)
+				}
+				if (err != nil) != (wantErr != nil) {
+					t.Errorf("-O%d: interpreter error %v, vm error %v", level, wantErr, err)
+				}
+			}
+		})
+	}
+}
+
+func parse(t *testing.T, source, file string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(source, file))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parse errors: %v", p.Errors())
+	}
+	return program
+}
+
+// compile compiles source and optimizes it at level
+func compile(t *testing.T, source string, level int) *Bytecode {
+	t.Helper()
+	bc, err := NewCompiler().Compile(parse(t, source, "test.sky"))
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	Optimize(bc, level)
+	return bc
+}
+
+// capture runs f and returns what it printed to stdout
+func capture(t *testing.T, f func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		output <- buf.String()
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	runErr := f()
+	os.Stdout = stdout
+	w.Close()
+	return <-output, runErr
+}
+
+// interpret runs a file in the interpreter and returns its output
+func interpret(t *testing.T, file string) (string, error) {
+	t.Helper()
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	program := parse(t, string(content), file)
+	return capture(t, func() error {
+		interp := interpreter.New()
+		interp.SetSourceFile(file)
+		return interp.Eval(program)
+	})
+}
+
+// run runs bytecode on a new VM and returns its output
+func run(t *testing.T, bc *Bytecode, file string) (string, error) {
+	t.Helper()
+	return capture(t, func() error {
+		machine := NewVM(bc)
+		machine.SetSourceFile(file)
+		return machine.Run()
+	})
+}
+
+// TestPrograms checks VM behaviour the conformance programs do not reach,
+// at every optimization level
+func TestPrograms(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"shared upvalue", `function pair(): any
+  let n = 0
+  let inc = function() n = n + 1 end
+  let get = function() n end
+  return [inc, get]
+end
+let p = pair()
+p[0]()
+p[0]()
+print(p[1]())`, "2\n"},
+		{"closed upvalue", `function counter(): any
+  let n = 0
+  function next(): int
+    n += 1
+    return n
+  end
+  return next
+end
+let a = counter()
+let b = counter()
+a()
+a()
+print(a(), b())`, "3 1\n"},
+		{"rebound global", `function one()
+  return 1
+end
+function two()
+  return 2
+end
+let pick = one
+function callPick()
+  return pick()
+end
+print(callPick())
+pick = two
+print(callPick())`, "1\n2\n"},
+		{"megamorphic member", `class A
+  function name()
+    return "a"
+  end
+end
+class B
+  function name()
+    return "b"
+  end
+end
+class C
+  function name()
+    return "c"
+  end
+end
+class D
+  function name()
+    return "d"
+  end
+end
+class E
+  function name()
+    return "e"
+  end
+end
+let objs = [A(), B(), C(), D(), E(), A(), E()]
+let s = ""
+for o in objs
+  s = s + o.name()
+end
+print(s)`, "abcdeae\n"},
+		{"inherited method", `class Base
+  function hello()
+    return "base"
+  end
+end
+class Child : Base
+  function kind()
+    return "child"
+  end
+end
+let c = Child()
+print(c.hello())
+class Other : Base
+  function hello()
+    return "other"
+  end
+end
+print(c.hello(), Other().hello())`, "base\nbase other\n"},
+		{"deep recursion", `function depth(n)
+  if n == 0
+    return 0
+  end
+  return 1 + depth(n - 1)
+end
+print(depth(5000))`, "5000\n"},
+		{"nested try", `function inner()
+  try
+    throw "inner"
+  finally
+    print("inner finally")
+  end
+end
+try
+  inner()
+catch e
+  print("outer caught", e)
+end`, "inner finally\nouter caught inner\n"},
+		{"coroutine", `coop function count(n)
+  let i = 0
+  while i < n
+    yield i
+    i += 1
+  end
+end
+let g = count(3)
+print(g.next(), g.next(), g.next())`, "0 1 2\n"},
+		{"async order", `async function value(x)
+  return x * 10
+end
+async function main
+  let a = value(1)
+  let b = value(2)
+  print(await b, await a)
+end`, "20 10\n"},
+	}
+
+	for _, tt := range tests {
+		for level := 0; level <= MaxOptLevel; level++ {
+			got, _ := run(t, compile(t, tt.source, level), "test.sky")
+			if got != tt.want {
+				t.Errorf("%s -O%d: printed %q, want %q", tt.name, level, got, tt.want)
+			}
+		}
+	}
+}
+
+// TestCompileErrorTurkish checks that compile errors follow the locale
+func TestCompileErrorTurkish(t *testing.T) {
+	defer i18n.SetLocale(i18n.Current())
+	i18n.SetLocale(i18n.Turkish)
+
+	_, err := NewCompiler().Compile(parse(t, "print(2 ** 3)", "test.sky"))
+	if err == nil || err.Error() != "bilinmeyen operatör: **" {
+		t.Errorf("error %v, want the Turkish message", err)
+	}
+}